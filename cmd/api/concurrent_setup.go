@@ -4,8 +4,10 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"restaurant-booking/internal/config"
 	"restaurant-booking/internal/repository"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/metrics"
 	"syscall"
 	"time"
 )
@@ -16,20 +18,31 @@ type ConcurrentServices struct {
 }
 
 func SetupConcurrentServices(
+	cfg *config.Config,
 	refreshTokenRepo repository.RefreshTokenRepository,
 	bookingRepo repository.BookingRepository,
 	tableRepo repository.TableRepository,
 	restaurantRepo repository.RestaurantRepository,
+	paymentRepo repository.PaymentRepository,
+	authz service.RestaurantAuthorizer,
+	audit service.AuditRecorder,
 ) *ConcurrentServices {
 	log.Println("Setting up concurrent services...")
 
-	notificationSvc := service.NewNotificationService(5, 100)
+	notificationSvc := service.NewElasticNotificationService(service.NotificationPoolConfig{
+		MinWorkers: cfg.NotificationMinWorkers,
+		MaxWorkers: cfg.NotificationMaxWorkers,
+	}, 100)
 
 	bookingSvc := service.NewBookingService(
 		bookingRepo,
 		tableRepo,
 		restaurantRepo,
+		paymentRepo,
+		authz,
+		audit,
 		notificationSvc,
+		cfg.PhoneDefaultCountryCode,
 	)
 
 	log.Println("All concurrent services initialized successfully")
@@ -40,6 +53,30 @@ func SetupConcurrentServices(
 	}
 }
 
+// RegisterNotificationMetrics exposes the notification queue on /metrics.
+func RegisterNotificationMetrics(registry *metrics.Registry, notificationSvc *service.NotificationService) {
+	registry.Gauge("notification_queue_depth", "Notifications waiting for a worker.", func() float64 {
+		return float64(notificationSvc.QueueDepth())
+	})
+	registry.Gauge("notification_queue_oldest_age_seconds", "How long the oldest queued or in-flight notification has waited.", func() float64 {
+		return notificationSvc.QueueStats().OldestAge.Seconds()
+	})
+	registry.Gauge("notification_queue_latency_p95_seconds", "95th percentile enqueue-to-done latency over the last 5 minutes.", func() float64 {
+		return notificationSvc.QueueStats().P95Latency.Seconds()
+	})
+	registry.Gauge("notification_workers", "Running notification workers.", func() float64 {
+		return float64(notificationSvc.WorkerCount())
+	})
+	registry.Gauge("notifications_sent", "Notifications sent since start.", func() float64 {
+		sent, _ := notificationSvc.GetStats()
+		return float64(sent)
+	})
+	registry.Gauge("notifications_failed", "Notifications that failed since start.", func() float64 {
+		_, failed := notificationSvc.GetStats()
+		return float64(failed)
+	})
+}
+
 func StartGracefulShutdown(services *ConcurrentServices) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -85,6 +122,7 @@ func DemoConcurrentFeatures(services *ConcurrentServices) {
 	go func() {
 		time.Sleep(5 * time.Second)
 		sent, failed := services.NotificationSvc.GetStats()
-		log.Printf("\nDemo 2: Current Stats - Sent: %d, Failed: %d", sent, failed)
+		log.Printf("\nDemo 2: Current Stats - Sent: %d, Failed: %d, Workers: %d",
+			sent, failed, services.NotificationSvc.WorkerCount())
 	}()
 }
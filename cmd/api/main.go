@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"restaurant-booking/internal/config"
 	"restaurant-booking/internal/database"
+	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/handler"
 	"restaurant-booking/internal/middleware"
 	"restaurant-booking/internal/repository"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/googleauth"
+	"restaurant-booking/pkg/imagestorage"
 	"restaurant-booking/pkg/jwt"
+	"restaurant-booking/pkg/metrics"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -18,6 +23,7 @@ import (
 	"restaurant-booking/pkg/logger"
 
 	_ "restaurant-booking/docs"
+	_ "time/tzdata"
 )
 
 // @title Restaurant Booking API
@@ -39,49 +45,200 @@ func main() {
 		log.Fatal("Failed to connect to database:", zap.Error(err))
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", zap.Error(err))
+	}
+
 	log.Info("Successfully connected to database!")
 
 	jwtManager := jwt.NewManager(cfg.JWTSecret, cfg.JWTAccessExpire, cfg.JWTRefreshExpire)
 
 	userRepo := repository.NewUserRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	verificationTokenRepo := repository.NewEmailVerificationTokenRepository(db)
 	restaurantRepo := repository.NewRestaurantRepository(db)
+	restaurantConfigVersionRepo := repository.NewRestaurantConfigVersionRepository(db)
 	tableRepo := repository.NewTableRepository(db)
+	tableBlockRepo := repository.NewTableBlockRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
 	reviewRepo := repository.NewReviewRepository(db)
 	restaurantManagerRepo := repository.NewRestaurantManagerRepository(db)
 	walletRepo := repository.NewWalletRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
+	rebookingOfferRepo := repository.NewRebookingOfferRepository(db)
 
-	authService := service.NewAuthService(userRepo, refreshTokenRepo, jwtManager, log)
-	userService := service.NewUserService(userRepo, log)
-	restaurantService := service.NewRestaurantService(restaurantRepo, db, log)
-	walletService := service.NewWalletService(walletRepo, db, log)
-	paymentService := service.NewPaymentService(paymentRepo, walletService, db, log)
-
-	managerService := service.NewManagerService(restaurantManagerRepo, restaurantRepo, userRepo, log)
-
-	authHandler := handler.NewAuthHandler(authService, userService)
-	userHandler := handler.NewUserHandler(userRepo)
-	restaurantHandler := handler.NewRestaurantHandler(restaurantService)
-	tableHandler := handler.NewTableHandler(tableRepo)
-	bookingHandler := handler.NewBookingHandler(bookingRepo, tableRepo)
-	reviewHandler := handler.NewReviewHandler(reviewRepo, restaurantRepo)
-	managerHandler := handler.NewManagerHandler(managerService)
-	walletHandler := handler.NewWalletHandler(walletService)
-	paymentHandler := handler.NewPaymentHandler(paymentService)
-
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo)
+	auditRepo := repository.NewAuditRepository(db)
+	auditRecorder := service.NewRepositoryAuditRecorder(auditRepo)
+	restaurantAuthorizer := service.NewRestaurantAuthorizer(restaurantManagerRepo, auditRecorder)
 
 	concurrentServices := SetupConcurrentServices(
+		cfg,
 		refreshTokenRepo,
 		bookingRepo,
 		tableRepo,
 		restaurantRepo,
+		paymentRepo,
+		restaurantAuthorizer,
+		auditRecorder,
 	)
 
 	StartGracefulShutdown(concurrentServices)
 
+	metricsRegistry := metrics.NewRegistry()
+	RegisterNotificationMetrics(metricsRegistry, concurrentServices.NotificationSvc)
+
+	var googleVerifier googleauth.Verifier
+	if cfg.GoogleClientID != "" {
+		googleVerifier = googleauth.NewVerifier(cfg.GoogleClientID)
+	}
+
+	var imageStorage imagestorage.ImageStorage
+	if cfg.CloudinaryCloudName != "" {
+		imageStorage = imagestorage.NewCloudinary(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, cfg.CloudinaryFolder)
+	} else {
+		imageStorage, err = imagestorage.NewLocalDisk(cfg.ImageUploadDir, "/uploads")
+		if err != nil {
+			log.Fatal("failed to prepare image upload directory", zap.Error(err))
+		}
+		log.Info("CLOUDINARY_CLOUD_NAME not set, storing images on local disk", zap.String("dir", cfg.ImageUploadDir))
+	}
+
+	tokenBlacklist := service.NewInMemoryTokenBlacklist()
+
+	authService := service.NewAuthService(
+		userRepo,
+		refreshTokenRepo,
+		verificationTokenRepo,
+		repository.NewTwoFactorRecoveryCodeRepository(db),
+		concurrentServices.NotificationSvc,
+		service.NewInMemoryLoginAttemptStore(),
+		service.LoginPolicy{
+			MaxAttempts:      cfg.LoginMaxAttempts,
+			MaxAttemptsPerIP: cfg.LoginMaxAttemptsPerIP,
+			Window:           cfg.LoginAttemptWindow,
+			LockoutDuration:  cfg.LoginLockoutDuration,
+		},
+		tokenBlacklist,
+		auditRecorder,
+		googleVerifier,
+		cfg.PhoneDefaultCountryCode,
+		jwtManager,
+		log,
+	)
+	userService := service.NewUserService(userRepo, bookingRepo, authService, auditRecorder, concurrentServices.NotificationSvc, cfg.PhoneDefaultCountryCode, log)
+	restaurantService := service.NewRestaurantService(restaurantRepo, restaurantConfigVersionRepo, restaurantAuthorizer, imageStorage, db, log)
+	tableService := service.NewTableService(tableRepo, tableBlockRepo, bookingRepo, restaurantRepo, restaurantAuthorizer, concurrentServices.NotificationSvc, db, log)
+	walletService := service.NewWalletService(walletRepo, auditRecorder, db, log)
+	paymentService := service.NewPaymentService(
+		paymentRepo,
+		bookingRepo,
+		walletService,
+		service.ServiceFeePolicy{
+			domain.PaymentMethodHalyk: {Percent: cfg.PaymentFeePercentHalyk, Min: cfg.PaymentFeeMin, Max: cfg.PaymentFeeMax},
+			domain.PaymentMethodKaspi: {Percent: cfg.PaymentFeePercentKaspi, Min: cfg.PaymentFeeMin, Max: cfg.PaymentFeeMax},
+		},
+		auditRecorder,
+		db,
+		log,
+	)
+
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), restaurantRepo, restaurantAuthorizer, auditRecorder, log)
+	reconciliationService := service.NewReconciliationService(repository.NewReconciliationRepository(db), restaurantRepo, restaurantAuthorizer)
+	restaurantStatsService := service.NewRestaurantStatsService(bookingRepo, paymentRepo, restaurantRepo, restaurantAuthorizer)
+	managerService := service.NewManagerService(
+		restaurantManagerRepo,
+		repository.NewManagerInvitationRepository(db),
+		restaurantRepo,
+		userRepo,
+		restaurantAuthorizer,
+		concurrentServices.NotificationSvc,
+		log,
+	)
+
+	authHandler := handler.NewAuthHandler(authService, userService, managerService)
+	userHandler := handler.NewUserHandler(userRepo, userService)
+	busynessService := service.NewBusynessService(
+		repository.NewBusynessRepository(db),
+		bookingRepo,
+		tableRepo,
+		restaurantRepo,
+		service.BusynessThresholds{Moderate: cfg.BusynessModerateThreshold, Busy: cfg.BusynessBusyThreshold},
+		log,
+	)
+	service.NewAnalyticsJob(busynessService, cfg.AnalyticsJobHour, log).Start(context.Background())
+
+	favoriteService := service.NewFavoriteService(repository.NewFavoriteRepository(db), restaurantRepo)
+	sponsoredService := service.NewSponsoredPlacementService(repository.NewSponsoredPlacementRepository(db), restaurantRepo, auditRecorder, log)
+	sponsoredService.Start(context.Background())
+	availabilityService := service.NewAvailabilityService(tableRepo, bookingRepo, tableBlockRepo)
+	restaurantHandler := handler.NewRestaurantHandler(restaurantService, availabilityService, busynessService, favoriteService, sponsoredService)
+	tableHandler := handler.NewTableHandler(tableService, availabilityService)
+	tableQRHandler := handler.NewTableQRHandler(service.NewTableQRService(tableRepo, restaurantRepo, restaurantAuthorizer, cfg.TableQRURLTemplate))
+	rebookingService := service.NewRebookingService(rebookingOfferRepo, bookingRepo, tableRepo, restaurantRepo, paymentRepo, concurrentServices.NotificationSvc, db, log)
+	waitlistService := service.NewWaitlistService(repository.NewWaitlistRepository(db), bookingRepo, tableRepo, restaurantRepo, concurrentServices.NotificationSvc, db, log)
+	waitlistService.Start(context.Background())
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+	bookingHandler := handler.NewBookingHandler(bookingRepo, tableRepo, restaurantRepo, rebookingService, paymentService, concurrentServices.BookingSvc, waitlistService)
+	recurringBookingService := service.NewRecurringBookingService(repository.NewRecurringBookingRepository(db), bookingRepo, restaurantRepo, concurrentServices.BookingSvc, db, cfg.RecurringBookingWeeks, log)
+	recurringBookingService.Start(context.Background())
+	recurringBookingHandler := handler.NewRecurringBookingHandler(recurringBookingService, waitlistService)
+	reviewHandler := handler.NewReviewHandler(service.NewReviewService(reviewRepo, restaurantRepo, db, log), reviewRepo, restaurantRepo)
+	managerHandler := handler.NewManagerHandler(managerService)
+	ownershipService := service.NewOwnershipService(restaurantRepo, userRepo, restaurantManagerRepo,
+		repository.NewRestaurantOwnershipTransferRepository(db), restaurantAuthorizer, auditRecorder, db, log)
+	ownershipHandler := handler.NewOwnershipHandler(ownershipService)
+	restaurantApprovalService := service.NewRestaurantApprovalService(restaurantRepo, userRepo, concurrentServices.NotificationSvc, auditRecorder, log)
+	restaurantApprovalHandler := handler.NewRestaurantApprovalHandler(restaurantApprovalService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	reconciliationHandler := handler.NewReconciliationHandler(reconciliationService)
+	restaurantStatsHandler := handler.NewRestaurantStatsHandler(restaurantStatsService)
+	walletHandler := handler.NewWalletHandler(walletService)
+	walletAdjustmentService := service.NewWalletAdjustmentService(repository.NewWalletAdjustmentRepository(db), auditRecorder, db, log,
+		cfg.WalletAdjustmentApprovalThreshold, cfg.WalletAdjustmentApprovalTTL)
+	walletAdjustmentHandler := handler.NewWalletAdjustmentHandler(walletAdjustmentService)
+	paymentHandler := handler.NewPaymentHandler(paymentService, bookingRepo, map[domain.PaymentMethod]string{
+		domain.PaymentMethodHalyk: cfg.HalykWebhookSecret,
+		domain.PaymentMethodKaspi: cfg.KaspiWebhookSecret,
+	})
+	rebookingHandler := handler.NewRebookingHandler(rebookingService)
+	rescheduleService := service.NewRescheduleService(repository.NewBookingRescheduleTokenRepository(db), bookingRepo, tableRepo, tableBlockRepo, concurrentServices.NotificationSvc, db, log)
+	rescheduleHandler := handler.NewRescheduleHandler(rescheduleService)
+	reminderService := service.NewReminderService(repository.NewBookingReminderRepository(db), rescheduleService,
+		concurrentServices.NotificationSvc, cfg.BookingReminderLeads, log)
+	reminderService.Start(context.Background())
+	reminderHandler := handler.NewReminderHandler(reminderService)
+	noShowService := service.NewNoShowService(bookingRepo, repository.NewNoShowChargeRepository(db), walletService,
+		restaurantAuthorizer, concurrentServices.NotificationSvc, db, log)
+	noShowHandler := handler.NewNoShowHandler(noShowService)
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	menuService := service.NewMenuService(repository.NewMenuRepository(db), restaurantRepo, restaurantAuthorizer, db)
+	menuHandler := handler.NewMenuHandler(menuService)
+	sponsoredHandler := handler.NewSponsoredPlacementHandler(sponsoredService)
+	requestSampleRepo := repository.NewRequestSampleRepository(db)
+	requestSampleService := service.NewRequestSampleService(requestSampleRepo, log)
+	service.NewPurgeJob(requestSampleService, cfg.PurgeJobHour, log).Start(context.Background())
+	service.NewPendingExpiryJob(bookingRepo, paymentRepo, concurrentServices.NotificationSvc, waitlistService, cfg.PendingBookingTTL, log).Start(context.Background())
+	jobService := service.NewJobService(repository.NewBackgroundJobRepository(db), []service.Backfill{
+		service.NewRatingBackfill(reviewRepo),
+		service.NewSlugBackfill(restaurantRepo),
+	}, auditRecorder, log, cfg.BackfillBatchDelay)
+	jobService.Resume(context.Background())
+	jobHandler := handler.NewJobHandler(jobService)
+	adminHandler := handler.NewAdminHandler(userService, auditRepo, requestSampleRepo)
+	staffPinService := service.NewStaffPinService(userRepo, restaurantManagerRepo, restaurantRepo, service.NewInMemoryLoginAttemptStore(), auditRecorder, log)
+	staffPinHandler := handler.NewStaffPinHandler(staffPinService)
+
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, userRepo, tokenBlacklist)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(apiKeyService)
+	staffPinMiddleware := middleware.NewStaffPinMiddleware(apiKeyService, staffPinService)
+	// Booking and payment mutations are sampled so support can look up what
+	// a customer sent when something went wrong.
+	sampleRequest := middleware.NewRequestSampler(requestSampleService, cfg.RequestSampleRateSuccess, cfg.RequestSampleRateError).Sample()
+	requireOwner := middleware.RequireRole(domain.UserRoleOwner, domain.UserRoleAdmin)
+	requireStaff := middleware.RequireRole(domain.UserRoleManager, domain.UserRoleOwner, domain.UserRoleAdmin)
+	requireAdmin := middleware.RequireRole(domain.UserRoleAdmin)
+
 	concurrentDemoHandler := handler.NewConcurrentDemoHandler(
 		concurrentServices.NotificationSvc,
 		concurrentServices.BookingSvc,
@@ -92,112 +249,244 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.APIKeyHeader, middleware.SharedDeviceHeader, middleware.StaffPinHeader},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
+	r.Use(middleware.RequestInfo())
+
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "Server is running",
-		})
-	})
+	healthHandler := handler.NewHealthHandler(sqlDB, concurrentServices.NotificationSvc, cfg.NotificationMaxQueueAge)
+	r.GET("/health", healthHandler.Health)
+	r.GET("/metrics", gin.WrapH(metricsRegistry))
+	if cfg.CloudinaryCloudName == "" {
+		r.Static("/uploads", cfg.ImageUploadDir)
+	}
 
-	api := r.Group("/api")
+	// Mutations from a shared restaurant tablet are attributed to the staff
+	// member whose PIN came with them.
+	api := r.Group("/api", staffPinMiddleware.Attribute())
 	{
 
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/oauth/google", authHandler.GoogleLogin)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authMiddleware.Authenticate(), authHandler.ResendVerification)
 			auth.POST("/logout", authMiddleware.Authenticate(), authHandler.Logout)
+			auth.POST("/logout-all", authMiddleware.Authenticate(), authHandler.LogoutAll)
 			auth.GET("/me", authMiddleware.Authenticate(), authHandler.GetMe)
+			auth.POST("/2fa/setup", authMiddleware.Authenticate(), authHandler.SetupTwoFactor)
+			auth.POST("/2fa/enable", authMiddleware.Authenticate(), authHandler.EnableTwoFactor)
+			auth.POST("/2fa/verify", authHandler.VerifyTwoFactor)
 		}
 
 		users := api.Group("/users")
 		{
-			users.POST("", userHandler.CreateUser)
+			users.PUT("/me", authMiddleware.Authenticate(), userHandler.UpdateMe)
+			users.POST("/me/password", authMiddleware.Authenticate(), userHandler.ChangePassword)
+			users.DELETE("/me", authMiddleware.Authenticate(), userHandler.DeleteMe)
+			users.POST("/me/phone/code", authMiddleware.Authenticate(), userHandler.SendPhoneCode)
+			users.POST("/me/phone/verify", authMiddleware.Authenticate(), userHandler.VerifyPhone)
+			users.POST("/me/guest-bookings/claim", authMiddleware.Authenticate(), userHandler.ClaimGuestBookings)
+			users.PUT("/me/staff-pin", authMiddleware.Authenticate(), requireStaff, staffPinHandler.SetPin)
+			users.GET("/me/favorites", authMiddleware.Authenticate(), favoriteHandler.ListMyFavorites)
+			users.GET("/me/bookings.ics", authMiddleware.Authenticate(), bookingHandler.GetMyBookingsCalendar)
+			users.GET("/me/restaurants", authMiddleware.Authenticate(), requireOwner, restaurantHandler.ListMyRestaurants)
+			users.GET("/me/managed-restaurants", authMiddleware.Authenticate(), managerHandler.ListManagedRestaurants)
 
 			users.GET("/:id", userHandler.GetUser)
-			users.GET("/:id/bookings", bookingHandler.GetUserBookings)
+			users.GET("/:id/bookings", authMiddleware.Authenticate(), bookingHandler.GetUserBookings)
 			users.GET("/:id/reviews", reviewHandler.GetUserReviews)
 		}
 
 		restaurants := api.Group("/restaurants")
 		{
-			restaurants.POST("", restaurantHandler.CreateRestaurant)
+			restaurants.POST("", authMiddleware.Authenticate(), requireOwner, restaurantHandler.CreateRestaurant)
 			restaurants.GET("", restaurantHandler.ListRestaurants)
-
-			restaurants.GET("/:id/tables", tableHandler.GetRestaurantTables)
-			restaurants.GET("/:id/bookings", bookingHandler.GetRestaurantBookings)
+			restaurants.GET("/search", restaurantHandler.SearchRestaurants)
+			restaurants.GET("/nearby", restaurantHandler.NearbyRestaurants)
+			restaurants.GET("/cuisines", restaurantHandler.ListCuisines)
+
+			restaurants.GET("/:id/tables", apiKeyMiddleware.Authenticate(), tableHandler.GetRestaurantTables)
+			restaurants.POST("/:id/tables/bulk", authMiddleware.Authenticate(), requireOwner, tableHandler.BulkCreateTables)
+			restaurants.POST("/:id/tables/import", authMiddleware.Authenticate(), requireOwner, tableHandler.ImportTables)
+			restaurants.PUT("/:id/tables/positions", authMiddleware.Authenticate(), requireOwner, tableHandler.UpdateTablePositions)
+			restaurants.GET("/:id/tables/qr.zip", authMiddleware.Authenticate(), requireStaff, tableQRHandler.GetRestaurantQRCodes)
+			restaurants.GET("/:id/availability", restaurantHandler.GetAvailabilityCalendar)
+			restaurants.GET("/:id/floor-plan", tableHandler.GetFloorPlan)
+			restaurants.GET("/:id/bookings", apiKeyMiddleware.AuthenticateOr(authMiddleware.Authenticate()), bookingHandler.GetRestaurantBookings)
+			restaurants.POST("/:id/bookings/guest", authMiddleware.Authenticate(), bookingHandler.CreateGuestBooking)
+			restaurants.POST("/:id/waitlist", sampleRequest, authMiddleware.Authenticate(), waitlistHandler.JoinWaitlist)
 			restaurants.GET("/:id/reviews", reviewHandler.GetRestaurantReviews)
-
-			restaurants.POST("/:id/managers", managerHandler.AddManager)
-			restaurants.GET("/:id/managers", managerHandler.GetManagers)
-			restaurants.DELETE("/:id/managers/:user_id", managerHandler.RemoveManager)
-
-			restaurants.POST("/:id/images", restaurantHandler.AddImage)
-			restaurants.DELETE("/:id/images/:image_id", restaurantHandler.DeleteImage)
-
-			restaurants.GET("/:id", restaurantHandler.GetRestaurant)
-			restaurants.PUT("/:id", restaurantHandler.UpdateRestaurant)
-			restaurants.DELETE("/:id", restaurantHandler.DeleteRestaurant)
+			restaurants.GET("/:id/menu", menuHandler.GetMenu)
+			restaurants.PUT("/:id/my-review", authMiddleware.Authenticate(), reviewHandler.UpsertMyReview)
+			restaurants.POST("/:id/favorite", authMiddleware.Authenticate(), favoriteHandler.AddFavorite)
+			restaurants.DELETE("/:id/favorite", authMiddleware.Authenticate(), favoriteHandler.RemoveFavorite)
+
+			restaurants.POST("/:id/managers", authMiddleware.Authenticate(), requireOwner, managerHandler.AddManager)
+			restaurants.POST("/:id/managers/invite", authMiddleware.Authenticate(), requireOwner, managerHandler.InviteManager)
+			restaurants.GET("/:id/managers", authMiddleware.Authenticate(), requireOwner, managerHandler.GetManagers)
+			restaurants.DELETE("/:id/managers/:user_id", authMiddleware.Authenticate(), requireOwner, managerHandler.RemoveManager)
+			restaurants.POST("/:id/transfer-ownership", authMiddleware.Authenticate(), requireOwner, ownershipHandler.TransferOwnership)
+
+			restaurants.POST("/:id/images", authMiddleware.Authenticate(), requireOwner, restaurantHandler.AddImage)
+			restaurants.POST("/:id/images/bulk", authMiddleware.Authenticate(), requireOwner, restaurantHandler.AddImages)
+			restaurants.DELETE("/:id/images/:image_id", authMiddleware.Authenticate(), requireOwner, restaurantHandler.DeleteImage)
+			restaurants.PATCH("/:id/images/:image_id/main", authMiddleware.Authenticate(), requireOwner, restaurantHandler.SetMainImage)
+			restaurants.PUT("/:id/images/order", authMiddleware.Authenticate(), requireOwner, restaurantHandler.ReorderImages)
+
+			restaurants.POST("/:id/menu/categories", authMiddleware.Authenticate(), requireStaff, menuHandler.CreateCategory)
+			restaurants.PUT("/:id/menu/categories/order", authMiddleware.Authenticate(), requireStaff, menuHandler.ReorderCategories)
+			restaurants.PUT("/:id/menu/categories/:category_id", authMiddleware.Authenticate(), requireStaff, menuHandler.UpdateCategory)
+			restaurants.DELETE("/:id/menu/categories/:category_id", authMiddleware.Authenticate(), requireStaff, menuHandler.DeleteCategory)
+			restaurants.POST("/:id/menu/categories/:category_id/items", authMiddleware.Authenticate(), requireStaff, menuHandler.CreateItem)
+			restaurants.PUT("/:id/menu/categories/:category_id/items/order", authMiddleware.Authenticate(), requireStaff, menuHandler.ReorderItems)
+			restaurants.PUT("/:id/menu/items/:item_id", authMiddleware.Authenticate(), requireStaff, menuHandler.UpdateItem)
+			restaurants.DELETE("/:id/menu/items/:item_id", authMiddleware.Authenticate(), requireStaff, menuHandler.DeleteItem)
+
+			restaurants.POST("/:id/api-keys", authMiddleware.Authenticate(), requireOwner, apiKeyHandler.CreateAPIKey)
+			restaurants.GET("/:id/api-keys", authMiddleware.Authenticate(), requireOwner, apiKeyHandler.ListAPIKeys)
+			restaurants.DELETE("/:id/api-keys/:key_id", authMiddleware.Authenticate(), requireOwner, apiKeyHandler.RevokeAPIKey)
+
+			restaurants.GET("/:id/reconciliation", authMiddleware.Authenticate(), requireOwner, reconciliationHandler.GetReconciliation)
+			restaurants.GET("/:id/stats", authMiddleware.Authenticate(), requireStaff, restaurantStatsHandler.GetStats)
+
+			restaurants.GET("/:id/config-versions", authMiddleware.Authenticate(), requireOwner, restaurantHandler.ListConfigVersions)
+			restaurants.POST("/:id/config-versions/:version/rollback", authMiddleware.Authenticate(), requireOwner, restaurantHandler.RollbackConfig)
+
+			restaurants.GET("/:id", authMiddleware.OptionalAuthenticate(), restaurantHandler.GetRestaurant)
+			restaurants.PUT("/:id", authMiddleware.Authenticate(), requireOwner, restaurantHandler.UpdateRestaurant)
+			restaurants.DELETE("/:id", authMiddleware.Authenticate(), requireOwner, restaurantHandler.DeleteRestaurant)
+			restaurants.POST("/:id/restore", authMiddleware.Authenticate(), requireOwner, restaurantHandler.RestoreRestaurant)
 		}
 
 		tables := api.Group("/tables")
 		{
-			tables.POST("", tableHandler.CreateTable)
+			tables.POST("", authMiddleware.Authenticate(), requireOwner, tableHandler.CreateTable)
 			tables.GET("/available", tableHandler.GetAvailableTables)
+			tables.POST("/check-availability", tableHandler.CheckAvailability)
 			tables.GET("/:id", tableHandler.GetTable)
-			tables.PUT("/:id", tableHandler.UpdateTable)
-			tables.DELETE("/:id", tableHandler.DeleteTable)
+			tables.PUT("/:id", authMiddleware.Authenticate(), requireOwner, tableHandler.UpdateTable)
+			tables.DELETE("/:id", authMiddleware.Authenticate(), requireOwner, tableHandler.DeleteTable)
+			tables.GET("/:id/qr", authMiddleware.Authenticate(), requireStaff, tableQRHandler.GetTableQRCode)
+
+			tables.GET("/:id/availability", authMiddleware.Authenticate(), requireStaff, tableHandler.GetTableSchedule)
+			tables.GET("/:id/blocks", authMiddleware.Authenticate(), requireStaff, tableHandler.ListBlocks)
+			tables.POST("/:id/blocks", authMiddleware.Authenticate(), requireStaff, tableHandler.BlockTable)
+			tables.DELETE("/:id/blocks/:block_id", authMiddleware.Authenticate(), requireStaff, tableHandler.UnblockTable)
 		}
 
 		bookings := api.Group("/bookings")
 		{
-			bookings.POST("", bookingHandler.CreateBooking)
+			bookings.POST("", sampleRequest, authMiddleware.Authenticate(), bookingHandler.CreateBooking)
 			bookings.GET("/check-availability", bookingHandler.CheckTableAvailability)
-			bookings.GET("/:id", bookingHandler.GetBooking)
-			bookings.PATCH("/:id/status", bookingHandler.UpdateBookingStatus)
-			bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
+			bookings.GET("/:id", authMiddleware.OptionalAuthenticate(), bookingHandler.GetBooking)
+			bookings.GET("/:id/ics", authMiddleware.Authenticate(), bookingHandler.GetBookingCalendar)
+			bookings.PUT("/:id", sampleRequest, authMiddleware.Authenticate(), bookingHandler.ModifyBooking)
+			bookings.PATCH("/:id/status", sampleRequest, authMiddleware.Authenticate(), bookingHandler.UpdateBookingStatus)
+			bookings.POST("/:id/cancel", sampleRequest, authMiddleware.Authenticate(), bookingHandler.CancelBooking)
+			bookings.POST("/:id/no-show", sampleRequest, authMiddleware.Authenticate(), noShowHandler.MarkNoShow)
 		}
 
 		reviews := api.Group("/reviews")
 		{
-			reviews.POST("", reviewHandler.CreateReview)
+			reviews.POST("", authMiddleware.Authenticate(), reviewHandler.CreateReview)
 			reviews.GET("/:id", reviewHandler.GetReview)
-			reviews.PUT("/:id", reviewHandler.UpdateReview)
-			reviews.DELETE("/:id", reviewHandler.DeleteReview)
+			reviews.PUT("/:id", authMiddleware.Authenticate(), reviewHandler.UpdateReview)
+			reviews.DELETE("/:id", authMiddleware.Authenticate(), reviewHandler.DeleteReview)
+		}
+
+		rebookingOffers := api.Group("/rebooking-offers")
+		{
+			rebookingOffers.GET("/:id", authMiddleware.Authenticate(), rebookingHandler.GetOffer)
+			rebookingOffers.POST("/:id/accept", sampleRequest, authMiddleware.Authenticate(), rebookingHandler.AcceptOffer)
+		}
+
+		recurringBookings := api.Group("/recurring-bookings")
+		{
+			recurringBookings.POST("", sampleRequest, authMiddleware.Authenticate(), recurringBookingHandler.CreateRecurringBooking)
+			recurringBookings.GET("", authMiddleware.Authenticate(), recurringBookingHandler.ListRecurringBookings)
+			recurringBookings.POST("/:id/cancel", sampleRequest, authMiddleware.Authenticate(), recurringBookingHandler.CancelRecurringBooking)
 		}
 
-		wallet := api.Group("/wallet")
+		waitlist := api.Group("/waitlist")
+		{
+			waitlist.POST("/claim/:token", sampleRequest, authMiddleware.Authenticate(), waitlistHandler.ClaimOffer)
+		}
+
+		// The token in the path is the credential, so these need no login.
+		reschedule := api.Group("/reschedule")
+		{
+			reschedule.GET("/:token", rescheduleHandler.GetReschedule)
+			reschedule.POST("/:token", sampleRequest, rescheduleHandler.Reschedule)
+		}
+
+		invitations := api.Group("/manager-invitations")
+		{
+			invitations.POST("/:token/accept", authMiddleware.Authenticate(), managerHandler.AcceptInvitation)
+			invitations.POST("/:token/decline", managerHandler.DeclineInvitation)
+		}
+
+		api.POST("/sponsored-placements/events", sponsoredHandler.RecordEvents)
+
+		wallet := api.Group("/wallet", authMiddleware.Authenticate())
 		{
 			wallet.GET("", walletHandler.GetWallet)
 			wallet.GET("/transactions", walletHandler.GetTransactions)
-			wallet.POST("/deposit", walletHandler.Deposit)
 			wallet.POST("/withdraw", walletHandler.Withdraw)
 		}
 
 		payments := api.Group("/payments")
 		{
-			payments.GET("", paymentHandler.GetUserPayments)
-			payments.POST("/wallet", paymentHandler.CreateWalletPayment)
-			payments.POST("/halyk", paymentHandler.CreateHalykPayment)
-			payments.POST("/kaspi", paymentHandler.CreateKaspiPayment)
 			payments.POST("/webhook/halyk", paymentHandler.HalykWebhook)
 			payments.POST("/webhook/kaspi", paymentHandler.KaspiWebhook)
-			payments.POST("/:id/refund", paymentHandler.RefundPayment)
+
+			authenticated := payments.Group("", authMiddleware.Authenticate())
+			authenticated.GET("", paymentHandler.GetUserPayments)
+
+			sampled := payments.Group("", sampleRequest, authMiddleware.Authenticate())
+			sampled.POST("/wallet", paymentHandler.CreateWalletPayment)
+			sampled.POST("/halyk", paymentHandler.CreateHalykPayment)
+			sampled.POST("/kaspi", paymentHandler.CreateKaspiPayment)
+			sampled.POST("/:id/refund", paymentHandler.RefundPayment)
+		}
+
+		admin := api.Group("/admin", authMiddleware.Authenticate(), requireAdmin)
+		{
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.POST("/users/:id/reactivate", adminHandler.ReactivateUser)
+			admin.GET("/audit", adminHandler.ListAudit)
+			admin.GET("/restaurants", restaurantApprovalHandler.ListRestaurants)
+			admin.POST("/restaurants/:id/approve", restaurantApprovalHandler.ApproveRestaurant)
+			admin.POST("/restaurants/:id/reject", restaurantApprovalHandler.RejectRestaurant)
+			admin.GET("/request-samples", adminHandler.ListRequestSamples)
+			admin.PATCH("/reviews/:id/visibility", reviewHandler.SetReviewVisibility)
+			admin.GET("/rebooking-offers/report", rebookingHandler.Report)
+			admin.POST("/sponsored-placements", sponsoredHandler.CreatePlacement)
+			admin.GET("/sponsored-placements", sponsoredHandler.ListPlacements)
+			admin.GET("/sponsored-placements/:id", sponsoredHandler.GetPlacement)
+			admin.PATCH("/sponsored-placements/:id", sponsoredHandler.UpdatePlacement)
+			admin.DELETE("/sponsored-placements/:id", sponsoredHandler.DeletePlacement)
+			admin.POST("/maintenance/recompute-ratings", jobHandler.RecomputeRatings)
+			admin.POST("/maintenance/assign-slugs", jobHandler.AssignRestaurantSlugs)
+			admin.POST("/maintenance/send-reminders", reminderHandler.SendReminders)
+			admin.GET("/jobs/:id", jobHandler.GetJob)
+			admin.POST("/wallets/:user_id/adjust", sampleRequest, walletAdjustmentHandler.AdjustWallet)
+			admin.GET("/wallet-adjustments", walletAdjustmentHandler.ListPending)
+			admin.POST("/wallet-adjustments/:id/approve", sampleRequest, walletAdjustmentHandler.Approve)
+			admin.POST("/wallet-adjustments/:id/reject", walletAdjustmentHandler.Reject)
 		}
 
 		demo := api.Group("/demo")
 		{
 			demo.POST("/bulk-notifications", concurrentDemoHandler.SendBulkNotifications)
 			demo.GET("/notification-stats", concurrentDemoHandler.GetNotificationStats)
-			demo.POST("/check-availability", concurrentDemoHandler.CheckTablesAvailability)
 			demo.GET("/booking-stats/:restaurant_id", concurrentDemoHandler.GetBookingStats)
 			demo.POST("/search-tables", concurrentDemoHandler.SearchAvailableTables)
 		}
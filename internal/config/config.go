@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -18,6 +20,83 @@ type Config struct {
 	JWTAccessExpire  time.Duration
 	JWTRefreshExpire time.Duration
 	Port             string
+
+	NotificationMinWorkers int
+	NotificationMaxWorkers int
+	// NotificationMaxQueueAge is how long a notification may wait before the
+	// deep health check reports the queue as degraded.
+	NotificationMaxQueueAge time.Duration
+
+	LoginMaxAttempts      int
+	LoginMaxAttemptsPerIP int
+	LoginAttemptWindow    time.Duration
+	LoginLockoutDuration  time.Duration
+
+	GoogleClientID string
+
+	// Restaurant images go to Cloudinary when CloudinaryCloudName is set and
+	// to ImageUploadDir, served under /uploads, otherwise.
+	CloudinaryCloudName string
+	CloudinaryAPIKey    string
+	CloudinaryAPISecret string
+	CloudinaryFolder    string
+	ImageUploadDir      string
+
+	// PhoneDefaultCountryCode is the calling code, without +, assumed for
+	// phone numbers entered without one.
+	PhoneDefaultCountryCode string
+
+	// BusynessModerateThreshold and BusynessBusyThreshold are the occupancy
+	// percentages from which an hour is shown as moderate or busy.
+	BusynessModerateThreshold int
+	BusynessBusyThreshold     int
+	// AnalyticsJobHour is the local hour the nightly analytics job runs at.
+	AnalyticsJobHour int
+
+	// PaymentFeePercentHalyk and PaymentFeePercentKaspi are the service fee
+	// charged on card payments. Each fee is clamped to PaymentFeeMin and,
+	// when non-zero, PaymentFeeMax. Wallet payments carry no fee.
+	PaymentFeePercentHalyk float64
+	PaymentFeePercentKaspi float64
+	PaymentFeeMin          int
+	PaymentFeeMax          int
+	// HalykWebhookSecret and KaspiWebhookSecret sign each provider's payment
+	// webhooks. Webhooks of a provider without a secret are rejected.
+	HalykWebhookSecret string
+	KaspiWebhookSecret string
+
+	// RequestSampleRateSuccess and RequestSampleRateError are the share, from
+	// 0 to 1, of 2xx and of 4xx/5xx responses on sampled routes that get
+	// stored for support.
+	RequestSampleRateSuccess float64
+	RequestSampleRateError   float64
+	// PurgeJobHour is the local hour the nightly purge job runs at.
+	PurgeJobHour int
+
+	// WalletAdjustmentApprovalThreshold is the amount above which a manual
+	// wallet adjustment needs a second admin's approval, which must come
+	// within WalletAdjustmentApprovalTTL.
+	WalletAdjustmentApprovalThreshold int
+	WalletAdjustmentApprovalTTL       time.Duration
+
+	// PendingBookingTTL is how long a booking may stay pending before it is
+	// cancelled and its table freed.
+	PendingBookingTTL time.Duration
+
+	// RecurringBookingWeeks is how many weeks ahead the occurrences of
+	// recurring bookings are booked.
+	RecurringBookingWeeks int
+
+	// BookingReminderLeads are how long before a confirmed booking starts
+	// its customer is reminded of it, one reminder per lead.
+	BookingReminderLeads []time.Duration
+
+	// BackfillBatchDelay is the pause between the batches of a backfill job.
+	BackfillBatchDelay time.Duration
+
+	// TableQRURLTemplate is the public URL printed table QR codes open, with
+	// {restaurant_id} and {table_id} filled in.
+	TableQRURLTemplate string
 }
 
 func Load() (*Config, error) {
@@ -32,12 +111,35 @@ func Load() (*Config, error) {
 		DBName:     getEnv("DB_NAME", "restaurant_booking"),
 		JWTSecret:  getEnv("JWT_SECRET", ""),
 		Port:       getEnv("PORT", "8080"),
+
+		GoogleClientID: getEnv("GOOGLE_CLIENT_ID", ""),
+
+		CloudinaryCloudName: getEnv("CLOUDINARY_CLOUD_NAME", ""),
+		CloudinaryAPIKey:    getEnv("CLOUDINARY_API_KEY", ""),
+		CloudinaryAPISecret: getEnv("CLOUDINARY_API_SECRET", ""),
+		CloudinaryFolder:    getEnv("CLOUDINARY_FOLDER", "restaurants"),
+		ImageUploadDir:      getEnv("IMAGE_UPLOAD_DIR", "uploads"),
+
+		PhoneDefaultCountryCode: getEnv("PHONE_DEFAULT_COUNTRY_CODE", "7"),
+
+		HalykWebhookSecret: getEnv("HALYK_WEBHOOK_SECRET", ""),
+		KaspiWebhookSecret: getEnv("KASPI_WEBHOOK_SECRET", ""),
+
+		TableQRURLTemplate: getEnv("TABLE_QR_URL_TEMPLATE", "http://localhost:3000/restaurants/{restaurant_id}?table={table_id}"),
 	}
 
 	if cfg.JWTSecret == "" {
 		return nil, errors.New("JWT_SECRET is required")
 	}
 
+	if cfg.CloudinaryCloudName != "" && (cfg.CloudinaryAPIKey == "" || cfg.CloudinaryAPISecret == "") {
+		return nil, errors.New("CLOUDINARY_API_KEY and CLOUDINARY_API_SECRET are required with CLOUDINARY_CLOUD_NAME")
+	}
+
+	if !strings.Contains(cfg.TableQRURLTemplate, "{table_id}") {
+		return nil, errors.New("TABLE_QR_URL_TEMPLATE must contain {table_id}")
+	}
+
 	accessExpire := getEnv("JWT_ACCESS_EXPIRE", "15m")
 	refreshExpire := getEnv("JWT_REFRESH_EXPIRE", "7d")
 
@@ -52,6 +154,133 @@ func Load() (*Config, error) {
 		return nil, errors.New("invalid JWT_REFRESH_EXPIRE format")
 	}
 
+	cfg.NotificationMinWorkers, err = strconv.Atoi(getEnv("NOTIFICATION_MIN_WORKERS", "5"))
+	if err != nil || cfg.NotificationMinWorkers < 1 {
+		return nil, errors.New("invalid NOTIFICATION_MIN_WORKERS format")
+	}
+
+	cfg.NotificationMaxWorkers, err = strconv.Atoi(getEnv("NOTIFICATION_MAX_WORKERS", "20"))
+	if err != nil || cfg.NotificationMaxWorkers < cfg.NotificationMinWorkers {
+		return nil, errors.New("NOTIFICATION_MAX_WORKERS must be a number not less than NOTIFICATION_MIN_WORKERS")
+	}
+
+	cfg.NotificationMaxQueueAge, err = time.ParseDuration(getEnv("NOTIFICATION_MAX_QUEUE_AGE", "1m"))
+	if err != nil || cfg.NotificationMaxQueueAge <= 0 {
+		return nil, errors.New("invalid NOTIFICATION_MAX_QUEUE_AGE format")
+	}
+
+	cfg.LoginMaxAttempts, err = strconv.Atoi(getEnv("LOGIN_MAX_ATTEMPTS", "5"))
+	if err != nil {
+		return nil, errors.New("invalid LOGIN_MAX_ATTEMPTS format")
+	}
+
+	cfg.LoginMaxAttemptsPerIP, err = strconv.Atoi(getEnv("LOGIN_MAX_ATTEMPTS_PER_IP", "20"))
+	if err != nil {
+		return nil, errors.New("invalid LOGIN_MAX_ATTEMPTS_PER_IP format")
+	}
+
+	cfg.LoginAttemptWindow, err = time.ParseDuration(getEnv("LOGIN_ATTEMPT_WINDOW", "15m"))
+	if err != nil {
+		return nil, errors.New("invalid LOGIN_ATTEMPT_WINDOW format")
+	}
+
+	cfg.LoginLockoutDuration, err = time.ParseDuration(getEnv("LOGIN_LOCKOUT_DURATION", "15m"))
+	if err != nil {
+		return nil, errors.New("invalid LOGIN_LOCKOUT_DURATION format")
+	}
+
+	if code, err := strconv.Atoi(cfg.PhoneDefaultCountryCode); err != nil || code < 1 || code > 999 {
+		return nil, errors.New("invalid PHONE_DEFAULT_COUNTRY_CODE format")
+	}
+
+	cfg.BusynessModerateThreshold, err = strconv.Atoi(getEnv("BUSYNESS_MODERATE_THRESHOLD", "40"))
+	if err != nil {
+		return nil, errors.New("invalid BUSYNESS_MODERATE_THRESHOLD format")
+	}
+
+	cfg.BusynessBusyThreshold, err = strconv.Atoi(getEnv("BUSYNESS_BUSY_THRESHOLD", "70"))
+	if err != nil {
+		return nil, errors.New("invalid BUSYNESS_BUSY_THRESHOLD format")
+	}
+
+	if cfg.BusynessModerateThreshold < 1 || cfg.BusynessBusyThreshold <= cfg.BusynessModerateThreshold || cfg.BusynessBusyThreshold > 100 {
+		return nil, errors.New("busyness thresholds must satisfy 0 < BUSYNESS_MODERATE_THRESHOLD < BUSYNESS_BUSY_THRESHOLD <= 100")
+	}
+
+	cfg.AnalyticsJobHour, err = strconv.Atoi(getEnv("ANALYTICS_JOB_HOUR", "3"))
+	if err != nil || cfg.AnalyticsJobHour < 0 || cfg.AnalyticsJobHour > 23 {
+		return nil, errors.New("invalid ANALYTICS_JOB_HOUR format")
+	}
+
+	cfg.PaymentFeePercentHalyk, err = strconv.ParseFloat(getEnv("PAYMENT_FEE_PERCENT_HALYK", "2"), 64)
+	if err != nil || cfg.PaymentFeePercentHalyk < 0 || cfg.PaymentFeePercentHalyk > 100 {
+		return nil, errors.New("invalid PAYMENT_FEE_PERCENT_HALYK format")
+	}
+
+	cfg.PaymentFeePercentKaspi, err = strconv.ParseFloat(getEnv("PAYMENT_FEE_PERCENT_KASPI", "2"), 64)
+	if err != nil || cfg.PaymentFeePercentKaspi < 0 || cfg.PaymentFeePercentKaspi > 100 {
+		return nil, errors.New("invalid PAYMENT_FEE_PERCENT_KASPI format")
+	}
+
+	cfg.PaymentFeeMin, err = strconv.Atoi(getEnv("PAYMENT_FEE_MIN", "0"))
+	if err != nil || cfg.PaymentFeeMin < 0 {
+		return nil, errors.New("invalid PAYMENT_FEE_MIN format")
+	}
+
+	cfg.PaymentFeeMax, err = strconv.Atoi(getEnv("PAYMENT_FEE_MAX", "0"))
+	if err != nil || cfg.PaymentFeeMax < 0 || (cfg.PaymentFeeMax > 0 && cfg.PaymentFeeMax < cfg.PaymentFeeMin) {
+		return nil, errors.New("PAYMENT_FEE_MAX must be 0 (no cap) or a number not less than PAYMENT_FEE_MIN")
+	}
+
+	cfg.RequestSampleRateSuccess, err = strconv.ParseFloat(getEnv("REQUEST_SAMPLE_RATE_SUCCESS", "0.01"), 64)
+	if err != nil || cfg.RequestSampleRateSuccess < 0 || cfg.RequestSampleRateSuccess > 1 {
+		return nil, errors.New("invalid REQUEST_SAMPLE_RATE_SUCCESS format")
+	}
+
+	cfg.RequestSampleRateError, err = strconv.ParseFloat(getEnv("REQUEST_SAMPLE_RATE_ERROR", "1"), 64)
+	if err != nil || cfg.RequestSampleRateError < 0 || cfg.RequestSampleRateError > 1 {
+		return nil, errors.New("invalid REQUEST_SAMPLE_RATE_ERROR format")
+	}
+
+	cfg.PurgeJobHour, err = strconv.Atoi(getEnv("PURGE_JOB_HOUR", "4"))
+	if err != nil || cfg.PurgeJobHour < 0 || cfg.PurgeJobHour > 23 {
+		return nil, errors.New("invalid PURGE_JOB_HOUR format")
+	}
+
+	cfg.WalletAdjustmentApprovalThreshold, err = strconv.Atoi(getEnv("WALLET_ADJUSTMENT_APPROVAL_THRESHOLD", "50000"))
+	if err != nil || cfg.WalletAdjustmentApprovalThreshold < 0 {
+		return nil, errors.New("invalid WALLET_ADJUSTMENT_APPROVAL_THRESHOLD format")
+	}
+
+	cfg.WalletAdjustmentApprovalTTL, err = time.ParseDuration(getEnv("WALLET_ADJUSTMENT_APPROVAL_TTL", "72h"))
+	if err != nil || cfg.WalletAdjustmentApprovalTTL <= 0 {
+		return nil, errors.New("invalid WALLET_ADJUSTMENT_APPROVAL_TTL format")
+	}
+
+	pendingMinutes, err := strconv.Atoi(getEnv("PENDING_BOOKING_TTL_MINUTES", "30"))
+	if err != nil || pendingMinutes < 1 {
+		return nil, errors.New("invalid PENDING_BOOKING_TTL_MINUTES format")
+	}
+	cfg.PendingBookingTTL = time.Duration(pendingMinutes) * time.Minute
+
+	cfg.RecurringBookingWeeks, err = strconv.Atoi(getEnv("RECURRING_BOOKING_WEEKS", "8"))
+	if err != nil || cfg.RecurringBookingWeeks < 1 {
+		return nil, errors.New("invalid RECURRING_BOOKING_WEEKS format")
+	}
+
+	for _, raw := range strings.Split(getEnv("BOOKING_REMINDER_LEADS", "24h,2h"), ",") {
+		lead, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || lead < time.Minute || lead%time.Minute != 0 {
+			return nil, errors.New("BOOKING_REMINDER_LEADS must be a comma-separated list of whole minutes, e.g. 24h,2h")
+		}
+		cfg.BookingReminderLeads = append(cfg.BookingReminderLeads, lead)
+	}
+
+	cfg.BackfillBatchDelay, err = time.ParseDuration(getEnv("BACKFILL_BATCH_DELAY", "500ms"))
+	if err != nil || cfg.BackfillBatchDelay < 0 {
+		return nil, errors.New("invalid BACKFILL_BATCH_DELAY format")
+	}
+
 	return cfg, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"restaurant-booking/internal/config"
+	"restaurant-booking/internal/database/migrate"
 	"restaurant-booking/internal/domain"
 
 	"gorm.io/driver/postgres"
@@ -34,6 +35,14 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to create enum types: %w", err)
 	}
 
+	// Databases created before the values existed get them here.
+	if err := migrate.AddEnumValue(db, "transaction_type", string(domain.TransactionAdjustment), string(domain.TransactionPaymentToRestaurant)); err != nil {
+		return nil, err
+	}
+	if err := migrate.AddEnumValue(db, "payment_status", string(domain.PaymentStatusVoided), string(domain.PaymentStatusRefunded)); err != nil {
+		return nil, err
+	}
+
 	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pgcrypto;").Error; err != nil {
 		return nil, fmt.Errorf("failed to ensure pgcrypto extension: %w", err)
 	}
@@ -78,26 +87,117 @@ END$$;`).Error; err != nil {
 		return nil, fmt.Errorf("failed to ensure location_type type: %w", err)
 	}
 
+	// Restaurants listed before approval existed stay listed. AutoMigrate
+	// then switches the default to pending for new ones.
+	if db.Migrator().HasTable(&domain.Restaurant{}) && !db.Migrator().HasColumn(&domain.Restaurant{}, "Status") {
+		if err := db.Exec(`ALTER TABLE restaurants ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'approved'`).Error; err != nil {
+			return nil, fmt.Errorf("failed to add restaurant status: %w", err)
+		}
+	}
+
+	// The model has no default so that an explicit 0 is stored, which would
+	// leave AutoMigrate adding a NOT NULL column without a value for existing
+	// restaurants. They get the usual hour, as in migration 000015.
+	if db.Migrator().HasTable(&domain.Restaurant{}) && !db.Migrator().HasColumn(&domain.Restaurant{}, "LastSeatingOffsetMinutes") {
+		if err := db.Exec(`ALTER TABLE restaurants ADD COLUMN last_seating_offset_minutes INTEGER NOT NULL DEFAULT 60`).Error; err != nil {
+			return nil, fmt.Errorf("failed to add restaurant last seating offset: %w", err)
+		}
+	}
+
+	// Reviews written before frequency windows existed open the usual 30
+	// days. A NULL end would make the window unbounded and block the user's
+	// reviews of the restaurant for good, so the column is not null.
+	if db.Migrator().HasTable(&domain.Review{}) {
+		if !db.Migrator().HasColumn(&domain.Review{}, "WindowEndsAt") {
+			if err := db.Exec(`ALTER TABLE reviews ADD COLUMN window_ends_at TIMESTAMPTZ`).Error; err != nil {
+				return nil, fmt.Errorf("failed to add review window: %w", err)
+			}
+		}
+		if err := db.Exec(`UPDATE reviews SET window_ends_at = created_at + INTERVAL '30 days' WHERE window_ends_at IS NULL;
+ALTER TABLE reviews ALTER COLUMN window_ends_at SET NOT NULL;`).Error; err != nil {
+			return nil, fmt.Errorf("failed to backfill review windows: %w", err)
+		}
+	}
+
 	if err := db.AutoMigrate(
 		&domain.User{},
 		&domain.RefreshToken{},
+		&domain.EmailVerificationToken{},
+		&domain.TwoFactorRecoveryCode{},
 		&domain.Restaurant{},
+		&domain.RestaurantConfigVersion{},
 		&domain.RestaurantImage{},
 		&domain.RestaurantManager{},
 		&domain.Table{},
 		&domain.Booking{},
+		&domain.BookingReminder{},
+		&domain.NoShowCharge{},
 		&domain.Review{},
 		&domain.Wallet{},
 		&domain.WalletTransaction{},
 		&domain.Payment{},
+		&domain.AuditLog{},
+		&domain.RestaurantBusyness{},
+		&domain.APIKey{},
+		&domain.ManagerInvitation{},
+		&domain.RebookingOffer{},
+		&domain.RequestSample{},
+		&domain.Favorite{},
+		&domain.TableBlock{},
+		&domain.SponsoredPlacement{},
+		&domain.BookingRescheduleToken{},
+		&domain.WalletAdjustment{},
+		&domain.MenuCategory{},
+		&domain.MenuItem{},
+		&domain.BackgroundJob{},
+		&domain.RestaurantOwnershipTransfer{},
+		&domain.WaitlistEntry{},
+		&domain.RecurringBooking{},
+		&domain.RecurringOccurrence{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// Restaurants created before address keys existed get one here, the
+	// same way migration 000042 fills them.
+	if err := db.Exec(`UPDATE restaurants SET address_key = ` + addressKeySQL + ` WHERE address_key = '' AND address <> ''`).Error; err != nil {
+		return nil, fmt.Errorf("failed to backfill restaurant address keys: %w", err)
+	}
+
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS btree_gist;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'excl_reviews_user_restaurant_window') THEN
+        ` + reviewWindowOverlapSQL + `
+        ALTER TABLE reviews ADD CONSTRAINT excl_reviews_user_restaurant_window
+            EXCLUDE USING gist (user_id WITH =, restaurant_id WITH =, tstzrange(created_at, window_ends_at) WITH &&);
+    END IF;
+END$$;`).Error; err != nil {
+		return nil, fmt.Errorf("failed to ensure review window constraint: %w", err)
+	}
+
+	if err := VerifyEnums(db); err != nil {
+		return nil, err
+	}
+
 	log.Println("Database connected and migrated successfully")
 	return db, nil
 }
 
+// addressKeySQL approximates service.normalizeAddress in SQL: lowercase,
+// ё read as е, and runs of anything but letters and digits turned into a
+// single space. It needs a UTF-8 locale to treat Cyrillic as letters.
+const addressKeySQL = `btrim(regexp_replace(replace(lower(address), 'ё', 'е'), '[^[:alnum:]]+', ' ', 'g'))`
+
+// reviewWindowOverlapSQL ends each review's window where the user's next
+// review of the restaurant starts. Reviews written before the frequency
+// limit can be days apart; with their windows cut short they all stay, and
+// the exclusion constraint can be added over them. Migration 000017 does
+// the same.
+const reviewWindowOverlapSQL = `UPDATE reviews SET window_ends_at = later.created_at
+        FROM (SELECT id, LEAD(created_at) OVER (PARTITION BY user_id, restaurant_id ORDER BY created_at, id) AS created_at FROM reviews) later
+        WHERE reviews.id = later.id AND later.created_at < reviews.window_ends_at;`
+
 func createEnumTypes(db *gorm.DB) error {
 	enumTypes := []string{
 		`DO $$ BEGIN
@@ -121,7 +221,7 @@ func createEnumTypes(db *gorm.DB) error {
 			WHEN duplicate_object THEN null;
 		END $$;`,
 		`DO $$ BEGIN
-			CREATE TYPE transaction_type AS ENUM ('deposit', 'withdraw', 'booking_charge', 'refund', 'payment_to_restaurant');
+			CREATE TYPE transaction_type AS ENUM ('deposit', 'withdraw', 'booking_charge', 'refund', 'payment_to_restaurant', 'adjustment');
 		EXCEPTION
 			WHEN duplicate_object THEN null;
 		END $$;`,
@@ -131,7 +231,7 @@ func createEnumTypes(db *gorm.DB) error {
 			WHEN duplicate_object THEN null;
 		END $$;`,
 		`DO $$ BEGIN
-			CREATE TYPE payment_status AS ENUM ('pending', 'completed', 'failed', 'refunded');
+			CREATE TYPE payment_status AS ENUM ('pending', 'completed', 'failed', 'refunded', 'voided');
 		EXCEPTION
 			WHEN duplicate_object THEN null;
 		END $$;`,
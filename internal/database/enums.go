@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"restaurant-booking/internal/database/migrate"
+	"restaurant-booking/internal/domain"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// domainEnums maps every Postgres enum type to the domain constants stored in
+// it. A constant added here without a migration makes startup fail instead of
+// failing the first insert that uses it.
+var domainEnums = map[string][]string{
+	"user_role": enumLabels(
+		domain.UserRoleCustomer,
+		domain.UserRoleOwner,
+		domain.UserRoleManager,
+		domain.UserRoleAdmin,
+	),
+	"booking_status": enumLabels(
+		domain.BookingStatusPending,
+		domain.BookingStatusConfirmed,
+		domain.BookingStatusCancelled,
+		domain.BookingStatusCompleted,
+		domain.BookingStatusNoShow,
+	),
+	"cuisine_type":  enumLabels(domain.CuisineTypes...),
+	"location_type": enumLabels(domain.LocationTypes...),
+	"transaction_type": enumLabels(
+		domain.TransactionDeposit,
+		domain.TransactionWithdraw,
+		domain.TransactionBookingCharge,
+		domain.TransactionRefund,
+		domain.TransactionPaymentToRestaurant,
+		domain.TransactionAdjustment,
+	),
+	"payment_method": enumLabels(
+		domain.PaymentMethodWallet,
+		domain.PaymentMethodHalyk,
+		domain.PaymentMethodKaspi,
+	),
+	"payment_status": enumLabels(
+		domain.PaymentStatusPending,
+		domain.PaymentStatusCompleted,
+		domain.PaymentStatusFailed,
+		domain.PaymentStatusRefunded,
+		domain.PaymentStatusVoided,
+	),
+}
+
+func enumLabels[T ~string](values ...T) []string {
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = string(v)
+	}
+	return labels
+}
+
+// VerifyEnums checks that every domain enum constant exists in its Postgres
+// enum type and lists the missing ones otherwise.
+func VerifyEnums(db *gorm.DB) error {
+	typeNames := make([]string, 0, len(domainEnums))
+	for typeName := range domainEnums {
+		typeNames = append(typeNames, typeName)
+	}
+	slices.Sort(typeNames)
+
+	var missing []string
+	for _, typeName := range typeNames {
+		existing, err := migrate.EnumValues(db, typeName)
+		if err != nil {
+			return fmt.Errorf("failed to read enum %s: %w", typeName, err)
+		}
+		for _, value := range domainEnums[typeName] {
+			if !slices.Contains(existing, value) {
+				missing = append(missing, typeName+"."+value)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("database enums are missing values used by the code, add a migration for: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
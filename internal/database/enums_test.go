@@ -0,0 +1,62 @@
+package database
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnumTypes_CoverDomainConstants fails when a string constant is added
+// to a domain enum type without listing it in domainEnums.
+func TestEnumTypes_CoverDomainConstants(t *testing.T) {
+	// Go type name -> Postgres enum type, for the types stored as enums.
+	stored := map[string]string{
+		"UserRole":        "user_role",
+		"BookingStatus":   "booking_status",
+		"CuisineType":     "cuisine_type",
+		"LocationType":    "location_type",
+		"TransactionType": "transaction_type",
+		"PaymentMethod":   "payment_method",
+		"PaymentStatus":   "payment_status",
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../domain", nil, 0)
+	require.NoError(t, err)
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					ident, ok := vs.Type.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					typeName, ok := stored[ident.Name]
+					if !ok {
+						continue
+					}
+					for i, value := range vs.Values {
+						lit, ok := value.(*ast.BasicLit)
+						if !ok {
+							continue
+						}
+						label, err := strconv.Unquote(lit.Value)
+						require.NoError(t, err)
+						assert.Contains(t, domainEnums[typeName], label, "%s (%s) is missing from domainEnums", vs.Names[i].Name, typeName)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package migrate holds helpers for schema changes that plain SQL migration
+// files cannot express safely.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// ErrInTransaction is returned by AddEnumValue when called inside a
+// transaction. Postgres before 12 rejects ALTER TYPE ... ADD VALUE there, and
+// newer versions cannot use the value until the transaction commits.
+var ErrInTransaction = errors.New("enum values cannot be added inside a transaction")
+
+// EnumValues returns the labels of a Postgres enum type in sort order. The
+// result is empty when the type does not exist.
+func EnumValues(db *gorm.DB, typeName string) ([]string, error) {
+	var values []string
+	err := db.Raw(`SELECT e.enumlabel FROM pg_enum e
+		JOIN pg_type t ON t.oid = e.enumtypid
+		WHERE t.typname = ?
+		ORDER BY e.enumsortorder`, typeName).Scan(&values).Error
+	return values, err
+}
+
+// AddEnumValue adds value to the enum type, placed after afterValue unless
+// that is empty. It does nothing when the value already exists, so it is safe
+// to run on every start.
+func AddEnumValue(db *gorm.DB, typeName, value, afterValue string) error {
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return ErrInTransaction
+	}
+
+	values, err := EnumValues(db, typeName)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("enum type %s does not exist", typeName)
+	}
+	if slices.Contains(values, value) {
+		return nil
+	}
+
+	// IF NOT EXISTS covers another instance adding the value since the check.
+	stmt := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", pgx.Identifier{typeName}.Sanitize(), quoteLiteral(value))
+	if afterValue != "" {
+		if !slices.Contains(values, afterValue) {
+			return fmt.Errorf("enum type %s has no value %q to add %q after", typeName, afterValue, value)
+		}
+		stmt += " AFTER " + quoteLiteral(afterValue)
+	}
+
+	if err := db.Exec(stmt).Error; err != nil {
+		return fmt.Errorf("failed to add %q to enum %s: %w", value, typeName, err)
+	}
+	return nil
+}
+
+// quoteLiteral quotes an enum label; ALTER TYPE does not accept bind
+// parameters.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
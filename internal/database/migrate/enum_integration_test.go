@@ -0,0 +1,65 @@
+//go:build integration
+
+package migrate_test
+
+import (
+	"os"
+	"restaurant-booking/internal/config"
+	"restaurant-booking/internal/database"
+	"restaurant-booking/internal/database/migrate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupIntegrationDB(t *testing.T) *gorm.DB {
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST is not set, skipping integration test")
+	}
+
+	db, err := database.InitDB(&config.Config{
+		DBHost:     os.Getenv("DB_HOST"),
+		DBPort:     os.Getenv("DB_PORT"),
+		DBUser:     os.Getenv("DB_USER"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     os.Getenv("DB_NAME"),
+	})
+	require.NoError(t, err)
+
+	return db
+}
+
+// TestAddEnumValue_Idempotent runs the helper twice against a scratch enum.
+func TestAddEnumValue_Idempotent(t *testing.T) {
+	db := setupIntegrationDB(t)
+
+	require.NoError(t, db.Exec("DROP TYPE IF EXISTS migrate_test_status").Error)
+	require.NoError(t, db.Exec("CREATE TYPE migrate_test_status AS ENUM ('pending', 'done')").Error)
+	t.Cleanup(func() { db.Exec("DROP TYPE IF EXISTS migrate_test_status") })
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, migrate.AddEnumValue(db, "migrate_test_status", "seated", "pending"))
+	}
+
+	values, err := migrate.EnumValues(db, "migrate_test_status")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending", "seated", "done"}, values)
+}
+
+func TestAddEnumValue_Errors(t *testing.T) {
+	db := setupIntegrationDB(t)
+
+	require.NoError(t, db.Exec("DROP TYPE IF EXISTS migrate_test_kind").Error)
+	require.NoError(t, db.Exec("CREATE TYPE migrate_test_kind AS ENUM ('a')").Error)
+	t.Cleanup(func() { db.Exec("DROP TYPE IF EXISTS migrate_test_kind") })
+
+	assert.Error(t, migrate.AddEnumValue(db, "migrate_test_missing", "a", ""))
+	assert.Error(t, migrate.AddEnumValue(db, "migrate_test_kind", "b", "z"))
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return migrate.AddEnumValue(tx, "migrate_test_kind", "b", "a")
+	})
+	assert.ErrorIs(t, err, migrate.ErrInTransaction)
+}
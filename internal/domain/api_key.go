@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey lets a restaurant's own systems, such as a POS, read its tables and
+// bookings without a user session. Only a hash of the key is stored; Prefix
+// is kept so owners can tell their keys apart.
+type APIKey struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID uuid.UUID  `gorm:"type:uuid;not null;index" json:"restaurant_id"`
+	Name         string     `gorm:"not null" json:"name"`
+	Prefix       string     `gorm:"not null" json:"prefix"`
+	KeyHash      string     `gorm:"uniqueIndex;not null" json:"-"`
+	CreatedBy    uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
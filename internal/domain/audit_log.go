@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is a persistent record of a security or money related event.
+// ActorID is nil when the actor is unknown, such as a failed login for an
+// email that has no account.
+type AuditLog struct {
+	ID         uuid.UUID              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID    *uuid.UUID             `gorm:"type:uuid;index:idx_audit_logs_actor_created" json:"actor_id,omitempty"`
+	Action     string                 `gorm:"not null;index:idx_audit_logs_action_created" json:"action"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   *uuid.UUID             `gorm:"type:uuid" json:"target_id,omitempty"`
+	IPAddress  string                 `json:"ip_address,omitempty"`
+	UserAgent  string                 `gorm:"type:text" json:"user_agent,omitempty"`
+	Metadata   map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
+	CreatedAt  time.Time              `gorm:"index:idx_audit_logs_actor_created;index:idx_audit_logs_action_created" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
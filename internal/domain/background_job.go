@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BackgroundJobStatus string
+
+const (
+	BackgroundJobRunning   BackgroundJobStatus = "running"
+	BackgroundJobCompleted BackgroundJobStatus = "completed"
+	BackgroundJobFailed    BackgroundJobStatus = "failed"
+)
+
+// BackgroundJob tracks a long-running job such as a backfill. Cursor is
+// where the job has got to, in a form only its kind understands, so that a
+// job interrupted by a restart can carry on from there.
+type BackgroundJob struct {
+	ID         uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Kind       string              `gorm:"type:varchar(64);not null;index;uniqueIndex:idx_background_jobs_running_kind,where:status = 'running'" json:"kind"`
+	Status     BackgroundJobStatus `gorm:"type:varchar(20);not null;default:'running';index" json:"status"`
+	Cursor     string              `gorm:"type:text;not null;default:''" json:"cursor,omitempty"`
+	Processed  int64               `gorm:"not null;default:0" json:"processed"`
+	Total      int64               `gorm:"not null;default:0" json:"total"`
+	Error      *string             `gorm:"type:text" json:"error,omitempty"`
+	StartedBy  uuid.UUID           `gorm:"type:uuid;not null" json:"started_by"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+}
+
+func (BackgroundJob) TableName() string {
+	return "background_jobs"
+}
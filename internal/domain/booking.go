@@ -10,21 +10,52 @@ type Booking struct {
 	ID           uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	RestaurantID uuid.UUID     `gorm:"type:uuid;not null" json:"restaurant_id"`
 	TableID      uuid.UUID     `gorm:"type:uuid;not null" json:"table_id"`
-	UserID       uuid.UUID     `gorm:"type:uuid;not null" json:"user_id"`
+	UserID       *uuid.UUID    `gorm:"type:uuid" json:"user_id"`
 	BookingDate  time.Time     `gorm:"not null" json:"booking_date"`
-	StartTime    time.Time     `gorm:"not null" json:"start_time"`
+	StartTime    time.Time     `gorm:"not null;index:idx_bookings_status_start_time,priority:2" json:"start_time"`
 	EndTime      time.Time     `gorm:"not null" json:"end_time"`
 	GuestsCount  int           `gorm:"not null" json:"guests_count"`
-	Status       BookingStatus `gorm:"type:booking_status;not null;default:'pending'" json:"status"`
+	Status       BookingStatus `gorm:"type:booking_status;not null;default:'pending';index:idx_bookings_status_start_time,priority:1" json:"status"`
 	SpecialNote  string        `gorm:"type:text" json:"special_note,omitempty"`
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
+	// CancellationPolicy and PolicyVersion are the restaurant's terms when
+	// the booking was made. Later policy changes do not touch them.
+	CancellationPolicy *CancellationPolicy `gorm:"type:jsonb;serializer:json" json:"cancellation_policy,omitempty"`
+	PolicyVersion      string              `gorm:"type:varchar(32)" json:"policy_version,omitempty"`
+	// RescheduleCount is how often the customer moved the booking through a
+	// reschedule link.
+	RescheduleCount int `gorm:"not null;default:0" json:"reschedule_count"`
+	// CancellationNote says why the system cancelled the booking. It is
+	// empty when a person cancelled it.
+	CancellationNote string `gorm:"type:text" json:"cancellation_note,omitempty"`
+	// IsGuest marks a booking staff made for GuestName, who has no
+	// account, so UserID is nil. A user whose verified account phone is
+	// GuestPhone can claim it, which sets UserID and clears IsGuest.
+	IsGuest    bool   `gorm:"not null;default:false" json:"is_guest"`
+	GuestName  string `gorm:"type:varchar(100)" json:"guest_name,omitempty"`
+	GuestPhone string `gorm:"type:varchar(20)" json:"guest_phone,omitempty"`
 
 	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
 	Table      *Table      `gorm:"foreignKey:TableID" json:"table,omitempty"`
 	User       *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+// BookedBy reports whether userID is the customer who holds the booking.
+func (b *Booking) BookedBy(userID uuid.UUID) bool {
+	return b.UserID != nil && *b.UserID == userID
+}
+
+// PaidBy reports whether payment settles the booking's deposit: it was made
+// by the customer who holds the booking and covers the deposit in full.
+func (b *Booking) PaidBy(payment *Payment) bool {
+	deposit := 0
+	if b.CancellationPolicy != nil {
+		deposit = b.CancellationPolicy.DepositAmount
+	}
+	return b.BookedBy(payment.UserID) && payment.Amount >= deposit
+}
+
 type BookingStatus string
 
 const (
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookingReminder records that the customer was reminded of a booking
+// LeadMinutes before it starts. A booking gets each reminder at most once.
+type BookingReminder struct {
+	BookingID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"booking_id"`
+	LeadMinutes int       `gorm:"primaryKey" json:"lead_minutes"`
+	SentAt      time.Time `gorm:"not null" json:"sent_at"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookingRescheduleToken is the secret of a link that lets the customer
+// move a booking without signing in. It expires when the booking starts and
+// works once; moving the booking uses up every other token of it as well.
+type BookingRescheduleToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BookingID uuid.UUID  `gorm:"type:uuid;not null;index" json:"booking_id"`
+	Token     string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (BookingRescheduleToken) TableName() string {
+	return "booking_reschedule_tokens"
+}
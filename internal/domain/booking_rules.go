@@ -0,0 +1,26 @@
+package domain
+
+// BookingRules limit how long a table may be booked and which start times
+// it offers. A zero field inherits from the level above.
+type BookingRules struct {
+	MinDurationMinutes     int `json:"min_duration_minutes,omitempty"`
+	SlotGranularityMinutes int `json:"slot_granularity_minutes,omitempty"`
+}
+
+// RestaurantBookingRules are the restaurant-wide rules and the defaults for
+// the tables of each zone. Tables may override both.
+type RestaurantBookingRules struct {
+	BookingRules
+	Zones map[LocationType]BookingRules `json:"zones,omitempty"`
+}
+
+// Inherit fills the zero fields of r from parent.
+func (r BookingRules) Inherit(parent BookingRules) BookingRules {
+	if r.MinDurationMinutes == 0 {
+		r.MinDurationMinutes = parent.MinDurationMinutes
+	}
+	if r.SlotGranularityMinutes == 0 {
+		r.SlotGranularityMinutes = parent.SlotGranularityMinutes
+	}
+	return r
+}
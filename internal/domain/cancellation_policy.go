@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CancellationPolicy is what a restaurant charges around cancellations.
+// Amounts are in the same unit as payments. The zero value is free
+// cancellation until the booking starts, with no deposit.
+type CancellationPolicy struct {
+	// FreeCancellationHours is how long before the start a booking can
+	// still be cancelled for free.
+	FreeCancellationHours int `json:"free_cancellation_hours"`
+	// LateCancellationFee is charged for cancelling after that cutoff.
+	LateCancellationFee int  `json:"late_cancellation_fee"`
+	DepositAmount       int  `json:"deposit_amount"`
+	DepositRefundable   bool `json:"deposit_refundable"`
+	// NoShowFee is charged from the customer's wallet when staff mark the
+	// booking a no-show. It is omitted when zero so that policies without
+	// one keep the version they had before the fee existed.
+	NoShowFee int `json:"no_show_fee,omitempty"`
+}
+
+// Version is a short hash of the policy terms, so any change to them gives
+// a new version and bookings can name the terms they were made under.
+func (p CancellationPolicy) Version() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ApplyPolicy records the policy the booking is made under.
+func (b *Booking) ApplyPolicy(policy CancellationPolicy) {
+	b.CancellationPolicy = &policy
+	b.PolicyVersion = policy.Version()
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Favorite is a restaurant a customer bookmarked. A user favorites a
+// restaurant at most once.
+type Favorite struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RestaurantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"restaurant_id"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Locale is the language a user receives notifications in, as a two-letter
+// ISO 639-1 code.
+type Locale string
+
+const (
+	LocaleEnglish Locale = "en"
+	LocaleRussian Locale = "ru"
+	LocaleKazakh  Locale = "kk"
+)
+
+// DefaultLocale is used when nothing better is known about the user.
+const DefaultLocale = LocaleEnglish
+
+// Locales lists every supported locale.
+var Locales = []Locale{LocaleEnglish, LocaleRussian, LocaleKazakh}
+
+// ParseLocale returns the supported locale a language tag such as "ru-KZ"
+// asks for, matching on the primary subtag.
+func ParseLocale(tag string) (Locale, bool) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary = strings.ToLower(primary)
+	for _, locale := range Locales {
+		if string(locale) == primary {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// LocaleFromAcceptLanguage picks the supported locale an Accept-Language
+// header prefers most, or DefaultLocale when it names none of them.
+func LocaleFromAcceptLanguage(header string) Locale {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Ties go to the tag listed first.
+		if locale, ok := ParseLocale(tag); ok && q > bestQ {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	cases := map[string]Locale{
+		"":                             LocaleEnglish,
+		"ru":                           LocaleRussian,
+		"ru-KZ,ru;q=0.9,en;q=0.8":      LocaleRussian,
+		"en-US,en;q=0.9,kk;q=0.95":     LocaleEnglish,
+		"de-DE,de;q=0.9,kk-KZ;q=0.7":   LocaleKazakh,
+		"fr;q=0.9, KK;q=0.5, ru;q=0.6": LocaleRussian,
+		"de, fr":                       LocaleEnglish,
+		"kk;q=bogus, ru;q=0.1":         LocaleRussian,
+		"*":                            LocaleEnglish,
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, LocaleFromAcceptLanguage(header), header)
+	}
+}
+
+func TestRestaurantLocation(t *testing.T) {
+	assert.Equal(t, "Asia/Tokyo", (&Restaurant{Timezone: "Asia/Tokyo"}).Location().String())
+	assert.Equal(t, DefaultTimezone, (&Restaurant{}).Location().String())
+	assert.Equal(t, DefaultTimezone, (&Restaurant{Timezone: "Mars/Olympus"}).Location().String())
+}
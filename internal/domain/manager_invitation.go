@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusDeclined InvitationStatus = "declined"
+	InvitationStatusExpired  InvitationStatus = "expired"
+)
+
+// ManagerInvitation asks the owner of Email to become a manager of a
+// restaurant. A pending invitation past ExpiresAt can no longer be
+// answered; it is marked expired when the same email is invited again.
+type ManagerInvitation struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID uuid.UUID        `gorm:"type:uuid;not null;index;uniqueIndex:idx_manager_invitations_pending_email,where:status = 'pending'" json:"restaurant_id"`
+	Email        string           `gorm:"not null;uniqueIndex:idx_manager_invitations_pending_email,where:status = 'pending'" json:"email"`
+	Role         UserRole         `gorm:"type:varchar(20);not null" json:"role"`
+	Token        string           `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedBy    uuid.UUID        `gorm:"type:uuid;not null" json:"invited_by"`
+	Status       InvitationStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ExpiresAt    time.Time        `gorm:"not null" json:"expires_at"`
+	RespondedAt  *time.Time       `json:"responded_at,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+func (ManagerInvitation) TableName() string {
+	return "manager_invitations"
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MenuCategory groups the items of a restaurant's menu, e.g. starters or
+// drinks.
+type MenuCategory struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID uuid.UUID `gorm:"type:uuid;not null;index" json:"restaurant_id"`
+	Name         string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description  *string   `gorm:"type:text" json:"description,omitempty"`
+	// Position orders the categories of the menu, lowest first.
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Items []*MenuItem `gorm:"foreignKey:CategoryID" json:"items"`
+}
+
+func (MenuCategory) TableName() string {
+	return "menu_categories"
+}
+
+// MenuItem is a dish or drink. Price is in tenge.
+type MenuItem struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CategoryID   uuid.UUID `gorm:"type:uuid;not null;index" json:"category_id"`
+	RestaurantID uuid.UUID `gorm:"type:uuid;not null;index" json:"restaurant_id"`
+	Name         string    `gorm:"type:varchar(150);not null" json:"name"`
+	Description  *string   `gorm:"type:text" json:"description,omitempty"`
+	Price        int       `gorm:"not null" json:"price"`
+	PhotoURL     *string   `gorm:"type:text" json:"photo_url,omitempty"`
+	IsAvailable  bool      `gorm:"not null;default:true" json:"is_available"`
+	// Position orders the items of the category, lowest first.
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (MenuItem) TableName() string {
+	return "menu_items"
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NoShowChargeStatus string
+
+const (
+	// NoShowChargePaid was taken from the customer's wallet.
+	NoShowChargePaid NoShowChargeStatus = "paid"
+	// NoShowChargeOwed could not be taken because the balance was too low
+	// and is a debt of the customer.
+	NoShowChargeOwed NoShowChargeStatus = "owed"
+)
+
+// NoShowCharge is the fee of a booking marked a no-show. A booking is
+// charged at most once.
+type NoShowCharge struct {
+	ID           uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BookingID    uuid.UUID          `gorm:"type:uuid;not null;uniqueIndex" json:"booking_id"`
+	UserID       uuid.UUID          `gorm:"type:uuid;not null;index" json:"user_id"`
+	RestaurantID uuid.UUID          `gorm:"type:uuid;not null" json:"restaurant_id"`
+	Amount       int                `gorm:"not null" json:"amount"`
+	Status       NoShowChargeStatus `gorm:"type:varchar(10);not null" json:"status"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
@@ -21,13 +21,19 @@ const (
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusRefunded  PaymentStatus = "refunded"
+	// PaymentStatusVoided is a pending payment whose booking was cancelled
+	// before it was paid. It can no longer be paid.
+	PaymentStatusVoided PaymentStatus = "voided"
 )
 
 type Payment struct {
-	ID                 uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID             uuid.UUID     `gorm:"type:uuid;not null" json:"user_id"`
-	BookingID          *uuid.UUID    `gorm:"type:uuid" json:"booking_id,omitempty"`
-	Amount             int           `gorm:"not null" json:"amount"`
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	BookingID *uuid.UUID `gorm:"type:uuid" json:"booking_id,omitempty"`
+	Amount    int        `gorm:"not null" json:"amount"`
+	// ServiceFeeAmount is charged on top of Amount. It is fixed when the
+	// payment is created, so later fee changes do not affect it.
+	ServiceFeeAmount   int           `gorm:"not null;default:0" json:"service_fee_amount"`
 	PaymentMethod      PaymentMethod `gorm:"type:payment_method;not null" json:"payment_method"`
 	PaymentStatus      PaymentStatus `gorm:"type:payment_status;not null;default:'pending'" json:"payment_status"`
 	ExternalPaymentID  *string       `gorm:"type:varchar(255)" json:"external_payment_id,omitempty"`
@@ -39,3 +45,8 @@ type Payment struct {
 	User    *User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Booking *Booking `gorm:"foreignKey:BookingID" json:"booking,omitempty"`
 }
+
+// TotalCharged is what the customer pays: Amount plus the service fee.
+func (p *Payment) TotalCharged() int {
+	return p.Amount + p.ServiceFeeAmount
+}
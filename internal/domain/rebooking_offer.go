@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RebookingOfferStatus string
+
+const (
+	RebookingOfferStatusPending  RebookingOfferStatus = "pending"
+	RebookingOfferStatusAccepted RebookingOfferStatus = "accepted"
+	RebookingOfferStatusExpired  RebookingOfferStatus = "expired"
+)
+
+type RebookingOptionKind string
+
+const (
+	// RebookingOptionSameRestaurant is another slot at the restaurant that
+	// cancelled.
+	RebookingOptionSameRestaurant RebookingOptionKind = "same_restaurant"
+	// RebookingOptionSimilarRestaurant is the original slot at a nearby
+	// restaurant of the same cuisine and price.
+	RebookingOptionSimilarRestaurant RebookingOptionKind = "similar_restaurant"
+)
+
+// RebookingOption is one replacement the customer can pick. The table is
+// chosen again on acceptance, since the offer outlives the availability it
+// was computed from.
+type RebookingOption struct {
+	Kind           RebookingOptionKind `json:"kind"`
+	RestaurantID   uuid.UUID           `json:"restaurant_id"`
+	RestaurantName string              `json:"restaurant_name"`
+	StartTime      time.Time           `json:"start_time"`
+	EndTime        time.Time           `json:"end_time"`
+}
+
+// RebookingOffer is generated when a restaurant cancels a confirmed booking.
+// A pending offer past ExpiresAt can no longer be accepted and counts as
+// expired in the recovery report.
+type RebookingOffer struct {
+	ID                uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BookingID         uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex" json:"booking_id"`
+	UserID            uuid.UUID            `gorm:"type:uuid;not null;index" json:"user_id"`
+	RestaurantID      uuid.UUID            `gorm:"type:uuid;not null" json:"restaurant_id"`
+	GuestsCount       int                  `gorm:"not null" json:"guests_count"`
+	Options           []RebookingOption    `gorm:"type:jsonb;serializer:json;not null" json:"options"`
+	Status            RebookingOfferStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	ExpiresAt         time.Time            `gorm:"not null" json:"expires_at"`
+	AcceptedBookingID *uuid.UUID           `gorm:"type:uuid" json:"accepted_booking_id,omitempty"`
+	RespondedAt       *time.Time           `json:"responded_at,omitempty"`
+	CreatedAt         time.Time            `gorm:"index" json:"created_at"`
+}
+
+func (RebookingOffer) TableName() string {
+	return "rebooking_offers"
+}
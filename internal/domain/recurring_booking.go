@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecurringBookingStatus string
+
+const (
+	RecurringBookingStatusActive    RecurringBookingStatus = "active"
+	RecurringBookingStatusCancelled RecurringBookingStatus = "cancelled"
+)
+
+// RecurringBooking books TableID for GuestsCount every Weekday from
+// StartClock to EndClock, as HH:MM on the restaurant's clock. It starts on
+// StartsOn and ends either after EndsOn or after OccurrenceCount weeks; an
+// EndClock at or before StartClock ends the next morning. Its occurrences
+// are booked a few weeks ahead, one RecurringOccurrence each.
+type RecurringBooking struct {
+	ID              uuid.UUID              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID    uuid.UUID              `gorm:"type:uuid;not null" json:"restaurant_id"`
+	TableID         uuid.UUID              `gorm:"type:uuid;not null" json:"table_id"`
+	UserID          uuid.UUID              `gorm:"type:uuid;not null;index" json:"user_id"`
+	Weekday         time.Weekday           `gorm:"type:smallint;not null" json:"weekday"`
+	StartClock      string                 `gorm:"type:varchar(5);not null" json:"start_time"`
+	EndClock        string                 `gorm:"type:varchar(5);not null" json:"end_time"`
+	GuestsCount     int                    `gorm:"not null" json:"guests_count"`
+	StartsOn        time.Time              `gorm:"type:date;not null" json:"starts_on"`
+	EndsOn          *time.Time             `gorm:"type:date" json:"ends_on,omitempty"`
+	OccurrenceCount *int                   `json:"occurrence_count,omitempty"`
+	SpecialNote     string                 `gorm:"type:text" json:"special_note,omitempty"`
+	Status          RecurringBookingStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	CancelledAt     *time.Time             `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+
+	Occurrences []RecurringOccurrence `gorm:"foreignKey:RecurringBookingID" json:"occurrences,omitempty"`
+}
+
+func (RecurringBooking) TableName() string {
+	return "recurring_bookings"
+}
+
+type RecurringOccurrenceStatus string
+
+const (
+	// RecurringOccurrenceBooked made BookingID.
+	RecurringOccurrenceBooked RecurringOccurrenceStatus = "booked"
+	// RecurringOccurrenceSkipped could not be booked, for SkipReason.
+	RecurringOccurrenceSkipped RecurringOccurrenceStatus = "skipped"
+)
+
+// RecurringOccurrence is one week of a RecurringBooking. It is saved once,
+// booked or skipped, so expanding the series again leaves it alone.
+type RecurringOccurrence struct {
+	ID                 uuid.UUID                 `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RecurringBookingID uuid.UUID                 `gorm:"type:uuid;not null;uniqueIndex:idx_recurring_booking_occurrences_start,priority:1" json:"recurring_booking_id"`
+	StartTime          time.Time                 `gorm:"not null;uniqueIndex:idx_recurring_booking_occurrences_start,priority:2" json:"start_time"`
+	EndTime            time.Time                 `gorm:"not null" json:"end_time"`
+	Status             RecurringOccurrenceStatus `gorm:"type:varchar(20);not null" json:"status"`
+	BookingID          *uuid.UUID                `gorm:"type:uuid" json:"booking_id,omitempty"`
+	SkipReason         string                    `gorm:"type:text" json:"skip_reason,omitempty"`
+	CreatedAt          time.Time                 `json:"created_at"`
+}
+
+func (RecurringOccurrence) TableName() string {
+	return "recurring_booking_occurrences"
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestSample is a sanitized copy of an API request kept for support.
+// Route is the route template, Path the concrete path requested. Body only
+// holds allow-listed fields, and Error is the error message the client got.
+type RequestSample struct {
+	ID        uuid.UUID              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    *uuid.UUID             `gorm:"type:uuid;index:idx_request_samples_user_created" json:"user_id,omitempty"`
+	Method    string                 `gorm:"type:varchar(10);not null" json:"method"`
+	Route     string                 `gorm:"not null;index:idx_request_samples_route_created" json:"route"`
+	Path      string                 `gorm:"type:text;not null" json:"path"`
+	Status    int                    `gorm:"not null" json:"status"`
+	Error     string                 `gorm:"type:text" json:"error,omitempty"`
+	Body      map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"body,omitempty"`
+	CreatedAt time.Time              `gorm:"index;index:idx_request_samples_user_created;index:idx_request_samples_route_created" json:"created_at"`
+}
+
+func (RequestSample) TableName() string {
+	return "request_samples"
+}
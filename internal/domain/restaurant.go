@@ -7,10 +7,17 @@ import (
 )
 
 type Restaurant struct {
-	ID                  uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OwnerID             uuid.UUID    `gorm:"type:uuid;not null" json:"owner_id"`
-	Name                string       `gorm:"not null" json:"name"`
-	Address             string       `gorm:"type:text;not null" json:"address"`
+	ID      uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OwnerID uuid.UUID `gorm:"type:uuid;not null" json:"owner_id"`
+	Name    string    `gorm:"not null" json:"name"`
+	// Slug names the restaurant in shareable URLs. It is made from the name
+	// on create and stays put on rename unless the owner regenerates it.
+	// Restaurants created before slugs existed have none until the
+	// assign-slugs job runs.
+	Slug    *string `gorm:"type:varchar(80);uniqueIndex:idx_restaurants_slug" json:"slug,omitempty"`
+	Address string  `gorm:"type:text;not null" json:"address"`
+	// AddressKey is Address normalized for spotting duplicate restaurants.
+	AddressKey          string       `gorm:"type:text;not null;default:'';index" json:"-"`
 	Latitude            *float64     `json:"latitude,omitempty"`
 	Longitude           *float64     `json:"longitude,omitempty"`
 	Description         string       `gorm:"type:text" json:"description"`
@@ -21,11 +28,30 @@ type Restaurant struct {
 	AveragePrice        int          `gorm:"not null" json:"average_price"`
 	MaxCombinableTables int          `gorm:"not null;default:3" json:"max_combinable_tables"`
 	WorkingHours        WorkingHours `gorm:"type:jsonb;not null" json:"working_hours"`
-	Rating              float64      `gorm:"type:decimal(2,1);default:0.0" json:"rating"`
-	ReviewsCount        int          `gorm:"default:0" json:"reviews_count"`
-	IsActive            bool         `gorm:"default:true" json:"is_active"`
-	CreatedAt           time.Time    `json:"created_at"`
-	UpdatedAt           time.Time    `json:"updated_at"`
+	// Timezone is the IANA name of the zone the restaurant is in. Times in
+	// notifications are shown on its clock.
+	Timezone string `gorm:"type:varchar(64);default:'Asia/Almaty'" json:"timezone"`
+	// MaxConcurrentGuests caps the guests of all bookings that overlap a
+	// new one, for kitchens that cannot serve every free table at once.
+	// 0 means no limit.
+	MaxConcurrentGuests int `gorm:"not null;default:0" json:"max_concurrent_guests"`
+	// LastSeatingOffsetMinutes is how long before closing the last booking
+	// may start. It has no gorm default so that an explicit 0 is stored.
+	LastSeatingOffsetMinutes int                    `gorm:"not null" json:"last_seating_offset_minutes"`
+	CancellationPolicy       CancellationPolicy     `gorm:"type:jsonb;serializer:json;not null;default:'{}'" json:"cancellation_policy"`
+	BookingRules             RestaurantBookingRules `gorm:"type:jsonb;serializer:json;not null;default:'{}'" json:"booking_rules"`
+	Rating                   float64                `gorm:"type:decimal(2,1);default:0.0" json:"rating"`
+	ReviewsCount             int                    `gorm:"default:0" json:"reviews_count"`
+	IsActive                 bool                   `gorm:"default:true" json:"is_active"`
+	DeactivatedBy            *uuid.UUID             `gorm:"type:uuid" json:"deactivated_by,omitempty"`
+	// Status is whether an admin has let the restaurant into public
+	// listings. RejectionReason is set when it was rejected.
+	Status          RestaurantStatus `gorm:"type:varchar(16);not null;default:'pending';index" json:"status"`
+	RejectionReason *string          `gorm:"type:text" json:"rejection_reason,omitempty"`
+	ReviewedBy      *uuid.UUID       `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time       `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
 
 	Owner    *User               `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
 	Images   []RestaurantImage   `gorm:"foreignKey:RestaurantID" json:"images,omitempty"`
@@ -35,6 +61,41 @@ type Restaurant struct {
 	Managers []RestaurantManager `gorm:"foreignKey:RestaurantID" json:"managers,omitempty"`
 }
 
+type RestaurantStatus string
+
+const (
+	RestaurantStatusPending  RestaurantStatus = "pending"
+	RestaurantStatusApproved RestaurantStatus = "approved"
+	RestaurantStatusRejected RestaurantStatus = "rejected"
+)
+
+// RestaurantStatuses lists every RestaurantStatus.
+var RestaurantStatuses = []RestaurantStatus{RestaurantStatusPending, RestaurantStatusApproved, RestaurantStatusRejected}
+
+// DeactivatedByAdmin reports whether the inactive restaurant was
+// deactivated by someone other than its owner, which can only be an admin.
+func (r *Restaurant) DeactivatedByAdmin() bool {
+	return !r.IsActive && r.DeactivatedBy != nil && *r.DeactivatedBy != r.OwnerID
+}
+
+// DefaultTimezone is the zone of restaurants that do not set one.
+const DefaultTimezone = "Asia/Almaty"
+
+// Location returns the restaurant's time zone, falling back to
+// DefaultTimezone when Timezone is empty or unknown.
+func (r *Restaurant) Location() *time.Location {
+	// LoadLocation reads "" as UTC, which is not what an unset zone means.
+	if r.Timezone != "" {
+		if loc, err := time.LoadLocation(r.Timezone); err == nil {
+			return loc
+		}
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
 type CuisineType string
 
 const (
@@ -56,12 +117,35 @@ const (
 	CuisineTypeOther      CuisineType = "Other"
 )
 
+// CuisineTypes lists every value of the cuisine_type enum.
+var CuisineTypes = []CuisineType{
+	CuisineTypeItalian,
+	CuisineTypeChinese,
+	CuisineTypeMexican,
+	CuisineTypeJapanese,
+	CuisineTypeIndian,
+	CuisineTypeFrench,
+	CuisineTypeKazakh,
+	CuisineTypeTurkish,
+	CuisineTypeThai,
+	CuisineTypeAmerican,
+	CuisineTypeKorean,
+	CuisineTypeCafe,
+	CuisineTypeBar,
+	CuisineTypeFastFood,
+	CuisineTypeVegetarian,
+	CuisineTypeOther,
+}
+
 type WorkingHours map[string]DaySchedule
 
+// DaySchedule is one day of WorkingHours, with times as HH:MM. Overnight
+// marks a day whose CloseTime falls on the next morning, e.g. 18:00 to 02:00.
 type DaySchedule struct {
 	OpenTime  string `json:"open_time"`
 	CloseTime string `json:"close_time"`
 	IsClosed  bool   `json:"is_closed"`
+	Overnight bool   `json:"overnight,omitempty"`
 }
 
 type RestaurantImage struct {
@@ -70,5 +154,7 @@ type RestaurantImage struct {
 	CloudinaryURL      string    `gorm:"type:text;not null" json:"cloudinary_url"`
 	CloudinaryPublicID string    `json:"cloudinary_public_id,omitempty"`
 	IsMain             bool      `gorm:"default:false" json:"is_main"`
-	CreatedAt          time.Time `json:"created_at"`
+	// Position orders the gallery, lowest first.
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
 }
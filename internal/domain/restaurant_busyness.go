@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OccupancyGrid holds a percentage of a restaurant's tables for each weekday,
+// indexed like time.Weekday, and hour of the day.
+type OccupancyGrid [7][24]int
+
+// RestaurantBusyness is the typical occupancy of a restaurant, recomputed
+// nightly from its recent booking history. BookingCount is how many bookings
+// the grid was computed from.
+type RestaurantBusyness struct {
+	RestaurantID uuid.UUID     `gorm:"type:uuid;primary_key" json:"restaurant_id"`
+	Occupancy    OccupancyGrid `gorm:"type:jsonb;serializer:json;not null" json:"occupancy"`
+	BookingCount int           `gorm:"not null" json:"booking_count"`
+	ComputedAt   time.Time     `gorm:"not null" json:"computed_at"`
+}
+
+func (RestaurantBusyness) TableName() string {
+	return "restaurant_busyness"
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RestaurantConfig is the owner-editable part of a restaurant that config
+// versions snapshot and roll back.
+type RestaurantConfig struct {
+	Name                string       `json:"name"`
+	Address             string       `json:"address"`
+	Latitude            *float64     `json:"latitude,omitempty"`
+	Longitude           *float64     `json:"longitude,omitempty"`
+	Description         string       `json:"description"`
+	Phone               string       `json:"phone"`
+	Instagram           *string      `json:"instagram,omitempty"`
+	Website             *string      `json:"website,omitempty"`
+	CuisineType         CuisineType  `json:"cuisine_type"`
+	AveragePrice        int          `json:"average_price"`
+	MaxCombinableTables int          `json:"max_combinable_tables"`
+	WorkingHours        WorkingHours `json:"working_hours"`
+	// LastSeatingOffsetMinutes is missing from snapshots taken before the
+	// setting existed; rolling back to those keeps the current value.
+	LastSeatingOffsetMinutes *int `json:"last_seating_offset_minutes,omitempty"`
+	// MaxConcurrentGuests is missing from snapshots taken before the limit
+	// existed, the same way.
+	MaxConcurrentGuests *int `json:"max_concurrent_guests,omitempty"`
+	// CancellationPolicy is missing from snapshots taken before policies
+	// existed, the same way.
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"`
+	// BookingRules is missing from snapshots taken before booking rules
+	// existed, the same way.
+	BookingRules *RestaurantBookingRules `json:"booking_rules,omitempty"`
+	IsActive     bool                    `json:"is_active"`
+}
+
+func (r *Restaurant) Config() RestaurantConfig {
+	config := RestaurantConfig{
+		Name:                r.Name,
+		Address:             r.Address,
+		Latitude:            r.Latitude,
+		Longitude:           r.Longitude,
+		Description:         r.Description,
+		Phone:               r.Phone,
+		Instagram:           r.Instagram,
+		Website:             r.Website,
+		CuisineType:         r.CuisineType,
+		AveragePrice:        r.AveragePrice,
+		MaxCombinableTables: r.MaxCombinableTables,
+		WorkingHours:        r.WorkingHours,
+		IsActive:            r.IsActive,
+	}
+	lastSeating := r.LastSeatingOffsetMinutes
+	config.LastSeatingOffsetMinutes = &lastSeating
+	maxGuests := r.MaxConcurrentGuests
+	config.MaxConcurrentGuests = &maxGuests
+	policy := r.CancellationPolicy
+	config.CancellationPolicy = &policy
+	rules := r.BookingRules
+	config.BookingRules = &rules
+	return config
+}
+
+type RestaurantConfigVersion struct {
+	ID           uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_restaurant_config_versions_version" json:"restaurant_id"`
+	Version      int              `gorm:"not null;uniqueIndex:idx_restaurant_config_versions_version" json:"version"`
+	ActorID      uuid.UUID        `gorm:"type:uuid;not null" json:"actor_id"`
+	Snapshot     RestaurantConfig `gorm:"type:jsonb;serializer:json;not null" json:"snapshot"`
+	Changes      string           `gorm:"type:text" json:"changes"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+func (RestaurantConfigVersion) TableName() string {
+	return "restaurant_config_versions"
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RestaurantOwnershipTransfer records a restaurant changing hands.
+// TransferredBy is the previous owner, or the admin who made the transfer.
+type RestaurantOwnershipTransfer struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID    uuid.UUID `gorm:"type:uuid;not null;index" json:"restaurant_id"`
+	PreviousOwnerID uuid.UUID `gorm:"type:uuid;not null" json:"previous_owner_id"`
+	NewOwnerID      uuid.UUID `gorm:"type:uuid;not null" json:"new_owner_id"`
+	TransferredBy   uuid.UUID `gorm:"type:uuid;not null" json:"transferred_by"`
+	// PreviousOwnerKept is set when the previous owner stayed on as a
+	// manager.
+	PreviousOwnerKept bool      `gorm:"not null;default:false" json:"previous_owner_kept"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (RestaurantOwnershipTransfer) TableName() string {
+	return "restaurant_ownership_transfers"
+}
@@ -14,8 +14,11 @@ type Review struct {
 	Rating       int        `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
 	Comment      string     `gorm:"type:text" json:"comment"`
 	IsVisible    bool       `gorm:"default:true" json:"is_visible"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	// WindowEndsAt closes the review frequency window opened by this review;
+	// the database rejects overlapping windows for the same user and restaurant.
+	WindowEndsAt time.Time `gorm:"type:timestamptz;not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 
 	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
 	User       *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SponsoredPlacement is paid promotion of a restaurant in restaurant lists.
+// City and CuisineType scope it to lists for that city or cuisine; nil
+// leaves it unscoped. It is shown from StartsAt until EndsAt while its spend,
+// Clicks * CostPerClick, stays below Budget. Budget and CostPerClick are in
+// KZT.
+type SponsoredPlacement struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID uuid.UUID    `gorm:"type:uuid;not null;index" json:"restaurant_id"`
+	City         *string      `gorm:"type:varchar(100)" json:"city,omitempty"`
+	CuisineType  *CuisineType `gorm:"type:cuisine_type" json:"cuisine_type,omitempty"`
+	// Weight orders placements competing for the same list, highest first.
+	Weight       int       `gorm:"not null;default:1" json:"weight"`
+	StartsAt     time.Time `gorm:"not null" json:"starts_at"`
+	EndsAt       time.Time `gorm:"not null" json:"ends_at"`
+	Budget       int       `gorm:"not null" json:"budget"`
+	CostPerClick int       `gorm:"not null" json:"cost_per_click"`
+	Impressions  int64     `gorm:"not null;default:0" json:"impressions"`
+	Clicks       int64     `gorm:"not null;default:0" json:"clicks"`
+	CreatedBy    uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
+}
+
+// Spent is what the placement has cost so far, in KZT.
+func (p *SponsoredPlacement) Spent() int64 {
+	return p.Clicks * int64(p.CostPerClick)
+}
+
+// IsServing reports whether the placement may be shown at now: its window
+// has started and not ended, and its budget is not used up.
+func (p *SponsoredPlacement) IsServing(now time.Time) bool {
+	return !now.Before(p.StartsAt) && now.Before(p.EndsAt) && p.Spent() < int64(p.Budget)
+}
@@ -8,16 +8,24 @@ import (
 
 type Table struct {
 	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	RestaurantID uuid.UUID    `gorm:"type:uuid;not null" json:"restaurant_id"`
-	TableNumber  string       `gorm:"not null" json:"table_number"`
+	RestaurantID uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_tables_restaurant_table_number_active,where:is_active = true" json:"restaurant_id"`
+	TableNumber  string       `gorm:"not null;uniqueIndex:idx_tables_restaurant_table_number_active,where:is_active = true" json:"table_number"`
 	MinCapacity  int          `gorm:"not null" json:"min_capacity"`
 	MaxCapacity  int          `gorm:"not null" json:"max_capacity"`
 	LocationType LocationType `gorm:"type:location_type;not null;default:'regular'" json:"location_type"`
 	XPosition    *int         `json:"x_position,omitempty"`
 	YPosition    *int         `json:"y_position,omitempty"`
-	IsActive     bool         `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
+	// MinDurationMinutes and SlotGranularityMinutes override the zone and
+	// restaurant booking rules for this table when set.
+	MinDurationMinutes     *int `json:"min_duration_minutes,omitempty"`
+	SlotGranularityMinutes *int `json:"slot_granularity_minutes,omitempty"`
+	// DepositAmount is charged when the table is booked, and MinSpend is what
+	// the party is expected to order, both in KZT. 0 means none.
+	DepositAmount int       `gorm:"not null;default:0" json:"deposit_amount"`
+	MinSpend      int       `gorm:"not null;default:0" json:"min_spend"`
+	IsActive      bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 
 	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
 	Bookings   []Booking   `gorm:"foreignKey:TableID" json:"bookings,omitempty"`
@@ -32,6 +40,14 @@ const (
 	LocationOutdoor LocationType = "outdoor"
 )
 
+// LocationTypes lists every value of the location_type enum.
+var LocationTypes = []LocationType{
+	LocationWindow,
+	LocationVIP,
+	LocationRegular,
+	LocationOutdoor,
+}
+
 type RestaurantManager struct {
 	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
@@ -41,3 +57,15 @@ type RestaurantManager struct {
 	User       *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Restaurant *Restaurant `gorm:"foreignKey:RestaurantID" json:"restaurant,omitempty"`
 }
+
+// BookingRules returns the table's own overrides; unset ones are zero.
+func (t *Table) BookingRules() BookingRules {
+	var rules BookingRules
+	if t.MinDurationMinutes != nil {
+		rules.MinDurationMinutes = *t.MinDurationMinutes
+	}
+	if t.SlotGranularityMinutes != nil {
+		rules.SlotGranularityMinutes = *t.SlotGranularityMinutes
+	}
+	return rules
+}
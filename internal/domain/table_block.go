@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TableBlock takes a table out of service between StartsAt and EndsAt, for
+// maintenance or anything else that keeps guests off it.
+type TableBlock struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TableID   uuid.UUID `gorm:"type:uuid;not null;index:idx_table_blocks_table_period" json:"table_id"`
+	StartsAt  time.Time `gorm:"not null;index:idx_table_blocks_table_period" json:"starts_at"`
+	EndsAt    time.Time `gorm:"not null" json:"ends_at"`
+	Reason    string    `gorm:"type:varchar(255)" json:"reason,omitempty"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Table *Table `gorm:"foreignKey:TableID" json:"-"`
+}
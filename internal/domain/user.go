@@ -7,18 +7,40 @@ import (
 )
 
 type User struct {
-	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Email       string     `gorm:"unique;not null" json:"email"`
-	Password    string     `gorm:"not null" json:"-"`
-	FirstName   string     `gorm:"not null" json:"first_name"`
-	LastName    string     `gorm:"not null" json:"last_name"`
-	Phone       string     `gorm:"unique" json:"phone"`
-	Role        UserRole   `gorm:"type:user_role;not null;default:'customer'" json:"role"`
-	Avatar      *string    `json:"avatar,omitempty"`
-	IsActive    bool       `gorm:"default:true" json:"is_active"`
-	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID            uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Email         string       `gorm:"unique;not null" json:"email"`
+	Password      string       `gorm:"not null" json:"-"`
+	FirstName     string       `gorm:"not null" json:"first_name"`
+	LastName      string       `gorm:"not null" json:"last_name"`
+	Phone         string       `gorm:"uniqueIndex:idx_users_phone_unique,where:phone <> ''" json:"phone"`
+	Role          UserRole     `gorm:"type:user_role;not null;default:'customer'" json:"role"`
+	Avatar        *string      `json:"avatar,omitempty"`
+	IsActive      bool         `gorm:"default:true" json:"is_active"`
+	EmailVerified bool         `gorm:"default:false" json:"email_verified"`
+	AuthProvider  AuthProvider `gorm:"type:varchar(20);not null;default:'password'" json:"auth_provider"`
+	GoogleID      *string      `gorm:"uniqueIndex" json:"-"`
+	// TwoFactorSecret is stored at setup and only used once TwoFactorEnabled
+	// is set by confirming a code.
+	TwoFactorSecret  *string    `gorm:"type:varchar(64)" json:"-"`
+	TwoFactorEnabled bool       `gorm:"not null;default:false" json:"two_factor_enabled"`
+	LastLoginAt      *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	// StaffPinHash is the bcrypt hash of the PIN staff enter on shared
+	// restaurant tablets. Empty until the user sets one.
+	StaffPinHash string `gorm:"type:varchar(255)" json:"-"`
+	// Locale is the language notifications are sent in. Registration takes
+	// it from the Accept-Language header.
+	Locale Locale `gorm:"type:varchar(10);default:'en'" json:"locale"`
+	// PhoneVerified is set once the user enters the code texted to Phone and
+	// cleared whenever Phone changes.
+	PhoneVerified bool `gorm:"not null;default:false" json:"phone_verified"`
+	// PhoneCodeHash is the bcrypt hash of the last verification code texted
+	// to Phone, valid until PhoneCodeExpiresAt. PhoneCodeAttempts counts the
+	// wrong codes entered for it.
+	PhoneCodeHash      string     `gorm:"type:varchar(255)" json:"-"`
+	PhoneCodeExpiresAt *time.Time `json:"-"`
+	PhoneCodeAttempts  int        `gorm:"not null;default:0" json:"-"`
 
 	OwnedRestaurants   []Restaurant        `gorm:"foreignKey:OwnerID" json:"owned_restaurants,omitempty"`
 	ManagedRestaurants []RestaurantManager `gorm:"foreignKey:UserID" json:"managed_restaurants,omitempty"`
@@ -26,6 +48,13 @@ type User struct {
 	Reviews            []Review            `gorm:"foreignKey:UserID" json:"reviews,omitempty"`
 }
 
+// ClearPhoneCode drops the pending phone verification code.
+func (u *User) ClearPhoneCode() {
+	u.PhoneCodeHash = ""
+	u.PhoneCodeExpiresAt = nil
+	u.PhoneCodeAttempts = 0
+}
+
 type UserRole string
 
 const (
@@ -35,14 +64,58 @@ const (
 	UserRoleAdmin    UserRole = "admin"
 )
 
+// AuthProvider records how a user signs in. Accounts backed by an OAuth
+// provider have no usable password.
+type AuthProvider string
+
+const (
+	AuthProviderPassword AuthProvider = "password"
+	AuthProviderGoogle   AuthProvider = "google"
+)
+
 type RefreshToken struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID    uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
 	Token     string    `gorm:"uniqueIndex;not null" json:"token"`
 	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+	// FamilyID is shared by every token rotated from the same login, so a
+	// stolen session can be revoked as a whole.
+	FamilyID uuid.UUID `gorm:"type:uuid;index" json:"family_id"`
+	// ParentID is the token this one was rotated from, nil for the first
+	// token of a family.
+	ParentID *uuid.UUID `gorm:"type:uuid" json:"parent_id,omitempty"`
+	// ReplacedBy is set once the token has been rotated. A rotated token is
+	// kept until it expires so that replaying it can be detected.
+	ReplacedBy *uuid.UUID `gorm:"type:uuid" json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 func (RefreshToken) TableName() string {
 	return "refresh_tokens"
 }
+
+// TwoFactorRecoveryCode is a single-use code that can stand in for a TOTP
+// code. Only a bcrypt hash of the code is kept.
+type TwoFactorRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+type EmailVerificationToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Token     string    `gorm:"uniqueIndex;not null" json:"token"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
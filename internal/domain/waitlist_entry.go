@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistStatus string
+
+const (
+	// WaitlistStatusWaiting is in line for a table.
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+	// WaitlistStatusOffered has been sent a claim link for a freed table.
+	WaitlistStatusOffered WaitlistStatus = "offered"
+	// WaitlistStatusBooked claimed its offer, which made BookingID.
+	WaitlistStatusBooked WaitlistStatus = "booked"
+	// WaitlistStatusExpired let its offer lapse, so the table went to the
+	// next entry.
+	WaitlistStatusExpired WaitlistStatus = "expired"
+)
+
+// WaitlistEntry is a customer waiting for a table for GuestsCount from
+// StartTime to EndTime at a restaurant. When a cancellation frees a fitting
+// table, the oldest waiting entry is offered it: OfferTableID is set and
+// ClaimToken books it until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RestaurantID   uuid.UUID      `gorm:"type:uuid;not null" json:"restaurant_id"`
+	UserID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	StartTime      time.Time      `gorm:"not null" json:"start_time"`
+	EndTime        time.Time      `gorm:"not null" json:"end_time"`
+	GuestsCount    int            `gorm:"not null" json:"guests_count"`
+	Status         WaitlistStatus `gorm:"type:varchar(20);not null;default:'waiting'" json:"status"`
+	OfferTableID   *uuid.UUID     `gorm:"type:uuid" json:"offer_table_id,omitempty"`
+	ClaimToken     *string        `gorm:"uniqueIndex" json:"-"`
+	OfferExpiresAt *time.Time     `json:"offer_expires_at,omitempty"`
+	BookingID      *uuid.UUID     `gorm:"type:uuid" json:"booking_id,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
@@ -25,6 +25,9 @@ const (
 	TransactionBookingCharge       TransactionType = "booking_charge"
 	TransactionRefund              TransactionType = "refund"
 	TransactionPaymentToRestaurant TransactionType = "payment_to_restaurant"
+	// TransactionAdjustment is a manual correction made by support. Unlike
+	// the other types its Amount is signed: negative for debits.
+	TransactionAdjustment TransactionType = "adjustment"
 )
 
 type WalletTransaction struct {
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdjustmentDirection string
+
+const (
+	AdjustmentCredit AdjustmentDirection = "credit"
+	AdjustmentDebit  AdjustmentDirection = "debit"
+)
+
+type WalletAdjustmentStatus string
+
+const (
+	// WalletAdjustmentPending waits for a second admin to approve it.
+	WalletAdjustmentPending  WalletAdjustmentStatus = "pending"
+	WalletAdjustmentApplied  WalletAdjustmentStatus = "applied"
+	WalletAdjustmentRejected WalletAdjustmentStatus = "rejected"
+	WalletAdjustmentExpired  WalletAdjustmentStatus = "expired"
+)
+
+// WalletAdjustment is a manual change to a user's wallet requested by an
+// admin. Small ones are applied at once; larger ones stay pending until
+// another admin approves them before ExpiresAt, and the balance only
+// changes then. TransactionID is set once the adjustment is applied.
+type WalletAdjustment struct {
+	ID            uuid.UUID              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID        uuid.UUID              `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount        int                    `gorm:"not null" json:"amount"`
+	Direction     AdjustmentDirection    `gorm:"type:varchar(10);not null" json:"direction"`
+	Reason        string                 `gorm:"type:text;not null" json:"reason"`
+	Status        WalletAdjustmentStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	RequestedBy   uuid.UUID              `gorm:"type:uuid;not null" json:"requested_by"`
+	DecidedBy     *uuid.UUID             `gorm:"type:uuid" json:"decided_by,omitempty"`
+	DecidedAt     *time.Time             `json:"decided_at,omitempty"`
+	ExpiresAt     *time.Time             `json:"expires_at,omitempty"`
+	TransactionID *uuid.UUID             `gorm:"type:uuid" json:"transaction_id,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+func (WalletAdjustment) TableName() string {
+	return "wallet_adjustments"
+}
+
+// SignedAmount is the change to the balance.
+func (a *WalletAdjustment) SignedAmount() int {
+	if a.Direction == AdjustmentDebit {
+		return -a.Amount
+	}
+	return a.Amount
+}
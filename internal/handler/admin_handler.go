@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxAdminUserPageSize = 100
+
+type AdminHandler struct {
+	userService service.UserService
+	auditRepo   repository.AuditRepository
+	sampleRepo  repository.RequestSampleRepository
+}
+
+func NewAdminHandler(userService service.UserService, auditRepo repository.AuditRepository, sampleRepo repository.RequestSampleRepository) *AdminHandler {
+	return &AdminHandler{userService: userService, auditRepo: auditRepo, sampleRepo: sampleRepo}
+}
+
+// ListUsers returns a page of users for the admin panel. Supported filters:
+// role, is_active and search (matched against email, first and last name).
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var filter repository.UserFilter
+
+	if r := c.Query("role"); r != "" {
+		role := domain.UserRole(r)
+		switch role {
+		case domain.UserRoleCustomer, domain.UserRoleOwner, domain.UserRoleManager, domain.UserRoleAdmin:
+			filter.Role = &role
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid role"})
+			return
+		}
+	}
+
+	if a := c.Query("is_active"); a != "" {
+		isActive, err := strconv.ParseBool(a)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid is_active value"})
+			return
+		}
+		filter.IsActive = &isActive
+	}
+
+	filter.Search = strings.TrimSpace(c.Query("search"))
+
+	limit := 20
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+	if limit <= 0 || limit > maxAdminUserPageSize {
+		limit = maxAdminUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := AdminUserListResponse{
+		Users:  make([]AdminUserResponse, len(users)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for i, user := range users {
+		response.Users[i] = toAdminUserResponse(user)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReactivateUser re-enables an account deactivated through DELETE
+// /api/users/me. The user has to log in again.
+func (h *AdminHandler) ReactivateUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+		return
+	}
+
+	user, err := h.userService.ReactivateUser(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminUserResponse(user))
+}
+
+// ListAudit returns audit log entries, newest first. Supported filters:
+// user_id (the actor), action, and a from/to range on when the entry was
+// recorded.
+func (h *AdminHandler) ListAudit(c *gin.Context) {
+	var filter repository.AuditFilter
+
+	if u := c.Query("user_id"); u != "" {
+		userID, err := uuid.Parse(u)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+			return
+		}
+		filter.ActorID = &userID
+	}
+
+	filter.Action = strings.TrimSpace(c.Query("action"))
+
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+			return
+		}
+		*bound.target = &parsed.Time
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return
+	}
+
+	limit := 20
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+	if limit <= 0 || limit > maxAdminUserPageSize {
+		limit = maxAdminUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, total, err := h.auditRepo.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditListResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// ListRequestSamples returns sampled booking and payment requests, newest
+// first. Supported filters: user_id, route (the route template, e.g.
+// /api/bookings/:id/cancel), status, and a from/to range on when the request
+// was made.
+func (h *AdminHandler) ListRequestSamples(c *gin.Context) {
+	var filter repository.RequestSampleFilter
+
+	if u := c.Query("user_id"); u != "" {
+		userID, err := uuid.Parse(u)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	filter.Route = strings.TrimSpace(c.Query("route"))
+
+	if s := c.Query("status"); s != "" {
+		status, err := strconv.Atoi(s)
+		if err != nil || status < 100 || status > 599 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid status"})
+			return
+		}
+		filter.Status = &status
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+			return
+		}
+		*bound.target = &parsed.Time
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return
+	}
+
+	limit := 20
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+	if limit <= 0 || limit > maxAdminUserPageSize {
+		limit = maxAdminUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	samples, total, err := h.sampleRepo.List(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RequestSampleListResponse{
+		Samples: samples,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+type RequestSampleListResponse struct {
+	Samples []*domain.RequestSample `json:"samples"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+type AuditListResponse struct {
+	Entries []*domain.AuditLog `json:"entries"`
+	Total   int64              `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+}
+
+type AdminUserResponse struct {
+	ID            uuid.UUID           `json:"id"`
+	Email         string              `json:"email"`
+	FirstName     string              `json:"first_name"`
+	LastName      string              `json:"last_name"`
+	Phone         string              `json:"phone"`
+	Role          domain.UserRole     `json:"role"`
+	IsActive      bool                `json:"is_active"`
+	EmailVerified bool                `json:"email_verified"`
+	AuthProvider  domain.AuthProvider `json:"auth_provider"`
+	LastLoginAt   *string             `json:"last_login_at,omitempty"`
+	CreatedAt     string              `json:"created_at"`
+}
+
+type AdminUserListResponse struct {
+	Users  []AdminUserResponse `json:"users"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+func toAdminUserResponse(user *domain.User) AdminUserResponse {
+	response := AdminUserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone,
+		Role:          user.Role,
+		IsActive:      user.IsActive,
+		EmailVerified: user.EmailVerified,
+		AuthProvider:  user.AuthProvider,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if user.LastLoginAt != nil {
+		lastLogin := user.LastLoginAt.Format("2006-01-02T15:04:05Z07:00")
+		response.LastLoginAt = &lastLogin
+	}
+	return response
+}
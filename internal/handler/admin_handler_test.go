@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUserService struct {
+	service.UserService
+	filter repository.UserFilter
+	limit  int
+	offset int
+	called bool
+}
+
+func (s *stubUserService) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	s.called = true
+	s.filter = filter
+	s.limit = limit
+	s.offset = offset
+	return []*domain.User{{
+		ID:       uuid.New(),
+		Email:    "owner@example.com",
+		Password: "$2a$10$secret-hash",
+		Role:     domain.UserRoleOwner,
+		IsActive: true,
+	}}, 57, nil
+}
+
+func TestAdminListUsers_AppliesFilters(t *testing.T) {
+	svc := &stubUserService{}
+	adminID := uuid.New()
+
+	w := performAsUser(NewAdminHandler(svc, nil, nil).ListUsers, http.MethodGet, "/api/admin/users",
+		"/api/admin/users?role=owner&is_active=false&search=%20smith%20&limit=10&offset=30", &adminID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.filter.Role)
+	assert.Equal(t, domain.UserRoleOwner, *svc.filter.Role)
+	require.NotNil(t, svc.filter.IsActive)
+	assert.False(t, *svc.filter.IsActive)
+	assert.Equal(t, "smith", svc.filter.Search)
+	assert.Equal(t, 10, svc.limit)
+	assert.Equal(t, 30, svc.offset)
+
+	var resp AdminUserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(57), resp.Total)
+	assert.Len(t, resp.Users, 1)
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestAdminListUsers_CapsLimit(t *testing.T) {
+	svc := &stubUserService{}
+
+	w := performAsUser(NewAdminHandler(svc, nil, nil).ListUsers, http.MethodGet, "/api/admin/users",
+		"/api/admin/users?limit=5000", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, maxAdminUserPageSize, svc.limit)
+	assert.Nil(t, svc.filter.Role)
+	assert.Nil(t, svc.filter.IsActive)
+}
+
+func TestAdminListUsers_InvalidFilters(t *testing.T) {
+	for _, query := range []string{"role=superuser", "is_active=maybe"} {
+		svc := &stubUserService{}
+
+		w := performAsUser(NewAdminHandler(svc, nil, nil).ListUsers, http.MethodGet, "/api/admin/users",
+			"/api/admin/users?"+query, nil, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.False(t, svc.called, query)
+	}
+}
+
+func (s *stubUserService) ReactivateUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	s.called = true
+	return &domain.User{ID: id, Role: domain.UserRoleCustomer, IsActive: true}, nil
+}
+
+func TestAdminReactivateUser(t *testing.T) {
+	svc := &stubUserService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewAdminHandler(svc, nil, nil).ReactivateUser, http.MethodPost, "/api/admin/users/:id/reactivate",
+		"/api/admin/users/"+userID.String()+"/reactivate", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp AdminUserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, userID, resp.ID)
+	assert.True(t, resp.IsActive)
+
+	svc = &stubUserService{}
+	w = performAsUser(NewAdminHandler(svc, nil, nil).ReactivateUser, http.MethodPost, "/api/admin/users/:id/reactivate",
+		"/api/admin/users/42/reactivate", nil, "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, svc.called)
+}
+
+type stubAuditRepository struct {
+	repository.AuditRepository
+	filter repository.AuditFilter
+	limit  int
+	called bool
+}
+
+func (r *stubAuditRepository) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*domain.AuditLog, int64, error) {
+	r.called = true
+	r.filter = filter
+	r.limit = limit
+	return []*domain.AuditLog{{ID: uuid.New(), Action: service.AuditActionLogin}}, 1, nil
+}
+
+func TestAdminListAudit_AppliesFilters(t *testing.T) {
+	repo := &stubAuditRepository{}
+	userID := uuid.New()
+
+	w := performAsUser(NewAdminHandler(nil, repo, nil).ListAudit, http.MethodGet, "/api/admin/audit",
+		"/api/admin/audit?user_id="+userID.String()+"&action=auth.login&from=2024-06-01T00:00:00%2B05:00&to=2024-06-02T00:00:00%2B05:00&limit=500", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, repo.filter.ActorID)
+	assert.Equal(t, userID, *repo.filter.ActorID)
+	assert.Equal(t, "auth.login", repo.filter.Action)
+	require.NotNil(t, repo.filter.From)
+	require.NotNil(t, repo.filter.To)
+	assert.Equal(t, maxAdminUserPageSize, repo.limit)
+
+	var resp AuditListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1), resp.Total)
+	assert.Len(t, resp.Entries, 1)
+}
+
+func TestAdminListAudit_InvalidFilters(t *testing.T) {
+	for _, query := range []string{
+		"user_id=42",
+		"from=yesterday",
+		"from=2024-06-02T00:00:00Z&to=2024-06-01T00:00:00Z",
+	} {
+		repo := &stubAuditRepository{}
+
+		w := performAsUser(NewAdminHandler(nil, repo, nil).ListAudit, http.MethodGet, "/api/admin/audit",
+			"/api/admin/audit?"+query, nil, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.False(t, repo.called, query)
+	}
+}
+
+type stubRequestSampleRepository struct {
+	repository.RequestSampleRepository
+	filter repository.RequestSampleFilter
+	called bool
+}
+
+func (r *stubRequestSampleRepository) List(ctx context.Context, filter repository.RequestSampleFilter, limit, offset int) ([]*domain.RequestSample, int64, error) {
+	r.called = true
+	r.filter = filter
+	return []*domain.RequestSample{{ID: uuid.New(), Route: "/api/bookings", Status: http.StatusConflict}}, 1, nil
+}
+
+func TestAdminListRequestSamples_AppliesFilters(t *testing.T) {
+	repo := &stubRequestSampleRepository{}
+	userID := uuid.New()
+
+	w := performAsUser(NewAdminHandler(nil, nil, repo).ListRequestSamples, http.MethodGet, "/api/admin/request-samples",
+		"/api/admin/request-samples?user_id="+userID.String()+"&route=/api/bookings&status=409", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, repo.filter.UserID)
+	assert.Equal(t, userID, *repo.filter.UserID)
+	assert.Equal(t, "/api/bookings", repo.filter.Route)
+	require.NotNil(t, repo.filter.Status)
+	assert.Equal(t, http.StatusConflict, *repo.filter.Status)
+
+	var resp RequestSampleListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1), resp.Total)
+	assert.Len(t, resp.Samples, 1)
+}
+
+func TestAdminListRequestSamples_InvalidFilters(t *testing.T) {
+	for _, query := range []string{
+		"user_id=42",
+		"status=abc",
+		"status=42",
+		"from=2024-06-02T00:00:00Z&to=2024-06-01T00:00:00Z",
+	} {
+		repo := &stubRequestSampleRepository{}
+
+		w := performAsUser(NewAdminHandler(nil, nil, repo).ListRequestSamples, http.MethodGet, "/api/admin/request-samples",
+			"/api/admin/request-samples?"+query, nil, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.False(t, repo.called, query)
+	}
+}
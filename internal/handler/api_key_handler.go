@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey issues a key for the restaurant's integrations. The plain key
+// is only part of this response.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	key, plainKey, err := h.apiKeyService.CreateKey(c.Request.Context(), restaurantID, userID, req.Name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: plainKey})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(c.Request.Context(), restaurantID, userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid api key id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(c.Request.Context(), restaurantID, keyID, userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNameNeeded):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "api key name cannot be empty"})
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "api key not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required" example:"Front desk POS"`
+}
+
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key" example:"rbk_3q2Wm7Yx..."`
+}
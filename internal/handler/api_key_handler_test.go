@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAPIKeyService struct {
+	service.APIKeyService
+	err error
+}
+
+func (s *stubAPIKeyService) CreateKey(ctx context.Context, restaurantID, userID uuid.UUID, name string) (*domain.APIKey, string, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	return &domain.APIKey{ID: uuid.New(), RestaurantID: restaurantID, Name: name, Prefix: "rbk_abcdefgh", KeyHash: "stored-hash"}, "rbk_abcdefgh-plain", nil
+}
+
+func (s *stubAPIKeyService) RevokeKey(ctx context.Context, restaurantID, keyID, userID uuid.UUID) error {
+	return s.err
+}
+
+func TestCreateAPIKey_ReturnsKeyOnce(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+
+	w := performAsUser(NewAPIKeyHandler(&stubAPIKeyService{}).CreateAPIKey, http.MethodPost, "/api/restaurants/:id/api-keys",
+		"/api/restaurants/"+restaurantID.String()+"/api-keys", &userID, `{"name":"POS"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "rbk_abcdefgh-plain", resp["key"])
+	assert.Equal(t, "rbk_abcdefgh", resp["prefix"])
+	assert.Equal(t, restaurantID.String(), resp["restaurant_id"])
+	assert.NotContains(t, w.Body.String(), "stored-hash")
+}
+
+func TestCreateAPIKey_NotOwner(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewAPIKeyHandler(&stubAPIKeyService{err: service.ErrUnauthorized}).CreateAPIKey, http.MethodPost, "/api/restaurants/:id/api-keys",
+		"/api/restaurants/"+uuid.NewString()+"/api-keys", &userID, `{"name":"POS"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRevokeAPIKey_Responses(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/restaurants/:id/api-keys/:key_id"
+	target := "/api/restaurants/" + uuid.NewString() + "/api-keys/" + uuid.NewString()
+
+	w := performAsUser(NewAPIKeyHandler(&stubAPIKeyService{}).RevokeAPIKey, http.MethodDelete, route, target, &userID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = performAsUser(NewAPIKeyHandler(&stubAPIKeyService{err: service.ErrAPIKeyNotFound}).RevokeAPIKey, http.MethodDelete, route, target, &userID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"restaurant-booking/pkg/jwt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -14,24 +16,32 @@ import (
 )
 
 type AuthHandler struct {
-	authService service.AuthService
-	userService service.UserService
+	authService    service.AuthService
+	userService    service.UserService
+	managerService service.ManagerService
 }
 
-func NewAuthHandler(authService service.AuthService, userService service.UserService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, userService service.UserService, managerService service.ManagerService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		userService: userService,
+		authService:    authService,
+		userService:    userService,
+		managerService: managerService,
 	}
 }
 
 type RegisterRequest struct {
-	Email     string          `json:"email" binding:"required"`
-	Password  string          `json:"password" binding:"required"`
-	FirstName string          `json:"first_name" binding:"required"`
-	LastName  string          `json:"last_name" binding:"required"`
-	Phone     string          `json:"phone" binding:"required"`
-	Role      domain.UserRole `json:"role" binding:"required"`
+	Email     string `json:"email" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	Phone     string `json:"phone" binding:"required"`
+	// Role is customer or owner, customer when empty.
+	Role domain.UserRole `json:"role" binding:"omitempty,oneof=customer owner"`
+	// InvitationToken accepts a manager invitation sent to Email once the
+	// account is created.
+	InvitationToken string `json:"invitation_token,omitempty"`
+	// Locale defaults to the best match for the Accept-Language header.
+	Locale domain.Locale `json:"locale,omitempty" binding:"omitempty,oneof=en ru kk"`
 }
 
 type LoginRequest struct {
@@ -39,6 +49,10 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type GoogleLoginRequest struct {
+	IDToken string `json:"id_token" binding:"required"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -47,6 +61,20 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type EnableTwoFactorRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is the authenticator code or an unused recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type AuthResponse struct {
 	User         *UserResponse `json:"user"`
 	AccessToken  string        `json:"access_token"`
@@ -54,13 +82,36 @@ type AuthResponse struct {
 }
 
 type UserResponse struct {
-	ID        uuid.UUID       `json:"id"`
-	Email     string          `json:"email"`
-	FirstName string          `json:"first_name"`
-	LastName  string          `json:"last_name"`
-	Phone     string          `json:"phone"`
-	Role      domain.UserRole `json:"role"`
-	CreatedAt string          `json:"created_at"`
+	ID               uuid.UUID       `json:"id"`
+	Email            string          `json:"email"`
+	FirstName        string          `json:"first_name"`
+	LastName         string          `json:"last_name"`
+	Phone            string          `json:"phone"`
+	Role             domain.UserRole `json:"role"`
+	EmailVerified    bool            `json:"email_verified"`
+	PhoneVerified    bool            `json:"phone_verified"`
+	TwoFactorEnabled bool            `json:"two_factor_enabled"`
+	Locale           domain.Locale   `json:"locale"`
+	CreatedAt        string          `json:"created_at"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when the
+// account has two-factor authentication enabled.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool         `json:"two_factor_required"`
+	ChallengeToken    string       `json:"challenge_token"`
+	ExpiresAt         apitime.Time `json:"expires_at" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+type TwoFactorEnableResponse struct {
+	Message string `json:"message"`
+	// RecoveryCodes are shown once and cannot be retrieved again.
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type TokenResponse struct {
@@ -72,6 +123,11 @@ type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+type LogoutAllResponse struct {
+	Message            string `json:"message"`
+	SessionsTerminated int64  `json:"sessions_terminated"`
+}
+
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -79,13 +135,24 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	locale := req.Locale
+	if locale == "" {
+		locale = domain.LocaleFromAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+
+	role := req.Role
+	if role == "" {
+		role = domain.UserRoleCustomer
+	}
+
 	user, accessToken, refreshToken, err := h.authService.Register(
 		req.Email,
 		req.Password,
 		req.FirstName,
 		req.LastName,
 		req.Phone,
-		req.Role,
+		role,
+		locale,
 	)
 	if err != nil {
 		log.Printf("Register error: %v", err)
@@ -96,12 +163,26 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Password must be at least 8 characters"})
 		case errors.Is(err, service.ErrEmailExists):
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email already exists"})
+		case errors.Is(err, service.ErrRoleNotAllowed):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Only customer and owner accounts can be registered"})
+		case errors.Is(err, service.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid phone number"})
+		case errors.Is(err, service.ErrPhoneTaken):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "Phone number is already in use"})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
 		}
 		return
 	}
 
+	if req.InvitationToken != "" {
+		// The account exists either way; a stale invitation must not fail
+		// the registration.
+		if _, err := h.managerService.AcceptInvitation(c.Request.Context(), req.InvitationToken, user.ID); err != nil {
+			log.Printf("Accept manager invitation on register error: %v", err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, AuthResponse{
 		User:         toUserResponse(user),
 		AccessToken:  accessToken,
@@ -116,14 +197,75 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, user, err := h.authService.Login(req.Email, req.Password)
+	accessToken, refreshToken, user, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
+		var challenge *service.TwoFactorChallenge
+		if errors.As(err, &challenge) {
+			c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge.Token,
+				ExpiresAt:         apitime.New(challenge.ExpiresAt),
+			})
+			return
+		}
+
 		log.Printf("Login error: %v", err)
-		if errors.Is(err, service.ErrInvalidCredentials) {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid email or password"})
+		case errors.Is(err, service.ErrOAuthAccount):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "This account uses Google sign-in, please log in with Google"})
+		case errors.Is(err, service.ErrAccountDeactivated):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Account is deactivated"})
+		case errors.Is(err, service.ErrAccountLocked):
+			c.JSON(http.StatusLocked, ErrorResponse{Error: "Account is temporarily locked due to too many failed login attempts"})
+		case errors.Is(err, service.ErrTooManyLoginAttempts):
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "Too many failed login attempts, please try again later"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		User:         toUserResponse(user),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+func (h *AuthHandler) GoogleLogin(c *gin.Context) {
+	var req GoogleLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, user, err := h.authService.LoginWithGoogle(c.Request.Context(), req.IDToken)
+	if err != nil {
+		var challenge *service.TwoFactorChallenge
+		if errors.As(err, &challenge) {
+			c.JSON(http.StatusOK, TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge.Token,
+				ExpiresAt:         apitime.New(challenge.ExpiresAt),
+			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+
+		log.Printf("Google login error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidGoogleToken):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid Google ID token"})
+		case errors.Is(err, service.ErrGoogleEmailNotVerified):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Google account email is not verified"})
+		case errors.Is(err, service.ErrGoogleLoginNotAvailable):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Google sign-in is not configured"})
+		case errors.Is(err, service.ErrAccountDeactivated):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Account is deactivated"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
 		return
 	}
 
@@ -141,7 +283,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newAccessToken, newRefreshToken, err := h.authService.RefreshToken(req.RefreshToken)
+	newAccessToken, newRefreshToken, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
 		log.Printf("Refresh token error: %v", err)
 		switch {
@@ -149,6 +291,10 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid refresh token"})
 		case errors.Is(err, service.ErrExpiredRefreshToken):
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Refresh token has expired"})
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Refresh token was already used, the session has been revoked"})
+		case errors.Is(err, service.ErrAccountDeactivated):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Account is deactivated"})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
 		}
@@ -168,7 +314,12 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Logout(req.RefreshToken); err != nil {
+	var accessToken *jwt.Claims
+	if claims, ok := c.Get("token_claims"); ok {
+		accessToken, _ = claims.(*jwt.Claims)
+	}
+
+	if err := h.authService.Logout(req.RefreshToken, accessToken); err != nil {
 		log.Printf("Logout error: %v", err)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Token not found"})
@@ -181,6 +332,25 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, MessageResponse{Message: "Logged out successfully"})
 }
 
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	terminated, err := h.authService.LogoutAll(userID)
+	if err != nil {
+		log.Printf("Logout all error: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LogoutAllResponse{
+		Message:            "Logged out from all devices",
+		SessionsTerminated: terminated,
+	})
+}
+
 func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -204,15 +374,165 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	})
 }
 
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		log.Printf("Verify email error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidVerificationToken):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid verification token"})
+		case errors.Is(err, service.ErrExpiredVerificationToken):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Verification token has expired"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Email verified successfully"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	if err := h.authService.ResendVerification(userID.(uuid.UUID)); err != nil {
+		log.Printf("Resend verification error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrEmailAlreadyVerified):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email is already verified"})
+		case errors.Is(err, service.ErrVerificationCooldown):
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: "Verification email was sent recently, please try again later"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Verification email sent"})
+}
+
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	secret, otpauthURL, err := h.authService.SetupTwoFactor(userID)
+	if err != nil {
+		log.Printf("2FA setup error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "Two-factor authentication is already enabled"})
+		case errors.Is(err, service.ErrOAuthAccount):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "This account uses Google sign-in, two-factor authentication is managed by Google"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURL: otpauthURL,
+	})
+}
+
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req EnableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.authService.EnableTwoFactor(userID, req.Code)
+	if err != nil {
+		log.Printf("2FA enable error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid two-factor authentication code"})
+		case errors.Is(err, service.ErrTwoFactorNotSetUp):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Two-factor authentication has not been set up"})
+		case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "Two-factor authentication is already enabled"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorEnableResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, user, err := h.authService.VerifyTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		log.Printf("2FA verify error: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidTwoFactorToken):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired two-factor challenge"})
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid two-factor authentication code"})
+		case errors.Is(err, service.ErrAccountLocked):
+			c.JSON(http.StatusLocked, ErrorResponse{Error: "Account is temporarily locked due to too many failed login attempts"})
+		case errors.Is(err, service.ErrAccountDeactivated):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Account is deactivated"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		User:         toUserResponse(user),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
 func toUserResponse(user *domain.User) *UserResponse {
 	return &UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Phone:            user.Phone,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerified,
+		PhoneVerified:    user.PhoneVerified,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		Locale:           user.Locale,
+		CreatedAt:        user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/pkg/ical"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const calendarProdID = "-//Restaurant Booking//Bookings//EN"
+
+// @Summary Export my bookings as iCalendar
+// @Description Returns an iCalendar feed of the caller's bookings that have not ended yet: pending ones as tentative events, confirmed ones as confirmed events and cancelled ones as cancelled events, so subscribed calendars drop them. Event UIDs are the booking IDs.
+// @Tags Bookings
+// @Produce text/calendar
+// @Success 200 {file} binary
+// @Failure 401 {object} ErrorResponse
+// @Router /api/users/me/bookings.ics [get]
+func (h *BookingHandler) GetMyBookingsCalendar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bookings, err := h.bookingRepo.GetCalendarByUser(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	events := make([]ical.Event, 0, len(bookings))
+	for _, booking := range bookings {
+		events = append(events, bookingEvent(booking))
+	}
+	writeCalendar(c, "bookings.ics", ical.Calendar{ProdID: calendarProdID, Name: "My bookings", Events: events})
+}
+
+// @Summary Export a booking as iCalendar
+// @Description Returns the booking as a single iCalendar event, with its UID set to the booking ID. Only the user who made the booking or an admin can export it.
+// @Tags Bookings
+// @Produce text/calendar
+// @Param id path string true "Booking ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/bookings/{id}/ics [get]
+func (h *BookingHandler) GetBookingCalendar(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid booking id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	booking, err := h.bookingRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	role, _ := c.Get("user_role")
+	if !booking.BookedBy(userID) && role != domain.UserRoleAdmin {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "not your booking"})
+		return
+	}
+
+	writeCalendar(c, "booking-"+booking.ID.String()+".ics", ical.Calendar{ProdID: calendarProdID, Events: []ical.Event{bookingEvent(booking)}})
+}
+
+// bookingEvent describes the booking as a calendar event. Completed and
+// no-show bookings only turn up when exporting a single booking, and are
+// shown as confirmed.
+func bookingEvent(booking *domain.Booking) ical.Event {
+	event := ical.Event{
+		UID:      booking.ID.String(),
+		Summary:  "Booking",
+		Start:    booking.StartTime,
+		End:      booking.EndTime,
+		Status:   ical.StatusConfirmed,
+		Modified: booking.UpdatedAt,
+	}
+	switch booking.Status {
+	case domain.BookingStatusPending:
+		event.Status = ical.StatusTentative
+	case domain.BookingStatusCancelled:
+		event.Status = ical.StatusCancelled
+	}
+	if booking.Restaurant != nil {
+		event.Summary = "Booking at " + booking.Restaurant.Name
+		event.Location = booking.Restaurant.Address
+	}
+
+	details := []string{fmt.Sprintf("Guests: %d", booking.GuestsCount)}
+	if booking.Table != nil {
+		details = append(details, "Table: "+booking.Table.TableNumber)
+	}
+	if booking.SpecialNote != "" {
+		details = append(details, "Note: "+booking.SpecialNote)
+	}
+	event.Description = strings.Join(details, "\n")
+	return event
+}
+
+func writeCalendar(c *gin.Context, filename string, cal ical.Calendar) {
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
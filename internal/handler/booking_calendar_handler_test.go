@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// calendarBookingRepository serves bookings for the calendar exports.
+type calendarBookingRepository struct {
+	repository.BookingRepository
+	bookings []*domain.Booking
+	userID   uuid.UUID
+	from     time.Time
+}
+
+func (r *calendarBookingRepository) GetCalendarByUser(ctx context.Context, userID uuid.UUID, from time.Time) ([]*domain.Booking, error) {
+	r.userID, r.from = userID, from
+	return r.bookings, nil
+}
+
+func (r *calendarBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error) {
+	for _, booking := range r.bookings {
+		if booking.ID == id {
+			return booking, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func getCalendar(bookings repository.BookingRepository, currentID uuid.UUID, role domain.UserRole, route, target string) *httptest.ResponseRecorder {
+	h := NewBookingHandler(bookings, nil, nil, nil, nil, nil, nil)
+	handlerFunc := h.GetMyBookingsCalendar
+	if strings.HasPrefix(route, "/api/bookings") {
+		handlerFunc = h.GetBookingCalendar
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(route, func(c *gin.Context) {
+		c.Set("user_id", currentID)
+		c.Set("user_role", role)
+		c.Next()
+	}, handlerFunc)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func calendarBooking(userID uuid.UUID, status domain.BookingStatus) *domain.Booking {
+	start := time.Date(2030, time.June, 4, 19, 0, 0, 0, time.UTC)
+	return &domain.Booking{
+		ID:          uuid.New(),
+		UserID:      &userID,
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		GuestsCount: 2,
+		SpecialNote: "Window, please",
+		Status:      status,
+		UpdatedAt:   start.Add(-24 * time.Hour),
+		Restaurant:  &domain.Restaurant{Name: "Osteria", Address: "Abay 10, Almaty"},
+		Table:       &domain.Table{TableNumber: "T1"},
+	}
+}
+
+func TestGetMyBookingsCalendar(t *testing.T) {
+	userID := uuid.New()
+	confirmed := calendarBooking(userID, domain.BookingStatusConfirmed)
+	pending := calendarBooking(userID, domain.BookingStatusPending)
+	cancelled := calendarBooking(userID, domain.BookingStatusCancelled)
+	bookings := &calendarBookingRepository{bookings: []*domain.Booking{confirmed, pending, cancelled}}
+
+	w := getCalendar(bookings, userID, domain.UserRoleCustomer, "/api/users/me/bookings.ics", "/api/users/me/bookings.ics")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="bookings.ics"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, userID, bookings.userID)
+	assert.WithinDuration(t, time.Now(), bookings.from, time.Minute)
+
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+	assert.Equal(t, 3, strings.Count(body, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, body, "UID:"+confirmed.ID.String()+"\r\n")
+	assert.Contains(t, body, "SUMMARY:Booking at Osteria\r\n")
+	assert.Contains(t, body, `LOCATION:Abay 10\, Almaty`+"\r\n")
+	assert.Contains(t, body, `DESCRIPTION:Guests: 2\nTable: T1\nNote: Window\, please`+"\r\n")
+	assert.Contains(t, body, "DTSTART:20300604T190000Z\r\n")
+	assert.Contains(t, body, "DTEND:20300604T210000Z\r\n")
+	assert.Contains(t, body, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, body, "STATUS:TENTATIVE\r\n")
+	assert.Contains(t, body, "STATUS:CANCELLED\r\n")
+}
+
+func TestGetBookingCalendar(t *testing.T) {
+	userID := uuid.New()
+	booking := calendarBooking(userID, domain.BookingStatusCancelled)
+	bookings := &calendarBookingRepository{bookings: []*domain.Booking{booking}}
+	route := "/api/bookings/:id/ics"
+	target := "/api/bookings/" + booking.ID.String() + "/ics"
+
+	w := getCalendar(bookings, userID, domain.UserRoleCustomer, route, target)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="booking-`+booking.ID.String()+`.ics"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, w.Body.String(), "UID:"+booking.ID.String()+"\r\n")
+	assert.Contains(t, w.Body.String(), "STATUS:CANCELLED\r\n")
+
+	w = getCalendar(bookings, uuid.New(), domain.UserRoleAdmin, route, target)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetBookingCalendar_Rejections(t *testing.T) {
+	userID := uuid.New()
+	booking := calendarBooking(userID, domain.BookingStatusConfirmed)
+	bookings := &calendarBookingRepository{bookings: []*domain.Booking{booking}}
+	route := "/api/bookings/:id/ics"
+
+	cases := []struct {
+		name      string
+		currentID uuid.UUID
+		target    string
+		want      int
+	}{
+		{"invalid id", userID, "/api/bookings/nope/ics", http.StatusBadRequest},
+		{"not found", userID, "/api/bookings/" + uuid.New().String() + "/ics", http.StatusNotFound},
+		{"other user", uuid.New(), "/api/bookings/" + booking.ID.String() + "/ics", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := getCalendar(bookings, tc.currentID, domain.UserRoleCustomer, route, tc.target)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
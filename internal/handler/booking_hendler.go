@@ -1,68 +1,138 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type BookingHandler struct {
-	bookingRepo repository.BookingRepository
-	tableRepo   repository.TableRepository
+	bookingRepo    repository.BookingRepository
+	tableRepo      repository.TableRepository
+	restaurantRepo repository.RestaurantRepository
+	rebooking      service.RebookingService
+	payments       service.PaymentService
+	bookings       service.BookingWriter
+	waitlist       service.WaitlistService
 }
 
-func NewBookingHandler(bookingRepo repository.BookingRepository, tableRepo repository.TableRepository) *BookingHandler {
+func NewBookingHandler(bookingRepo repository.BookingRepository, tableRepo repository.TableRepository, restaurantRepo repository.RestaurantRepository, rebooking service.RebookingService, payments service.PaymentService, bookings service.BookingWriter, waitlist service.WaitlistService) *BookingHandler {
 	return &BookingHandler{
-		bookingRepo: bookingRepo,
-		tableRepo:   tableRepo,
+		bookingRepo:    bookingRepo,
+		tableRepo:      tableRepo,
+		restaurantRepo: restaurantRepo,
+		rebooking:      rebooking,
+		payments:       payments,
+		bookings:       bookings,
+		waitlist:       waitlist,
 	}
 }
 
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
-	var req CreateBookingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	available, err := h.bookingRepo.CheckTableAvailability(
-		c.Request.Context(),
-		req.TableID,
-		req.StartTime,
-		req.EndTime,
-	)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	var req CreateBookingRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
 		return
 	}
 
-	if !available {
-		c.JSON(http.StatusConflict, ErrorResponse{Error: "table is not available for the selected time"})
+	booking, err := h.bookings.CreateBooking(c.Request.Context(), userID, service.CreateBookingRequest{
+		RestaurantID:  req.RestaurantID,
+		TableID:       req.TableID,
+		BookingDate:   req.BookingDate.Time,
+		StartTime:     req.StartTime.Time,
+		EndTime:       req.EndTime.Time,
+		GuestsCount:   req.GuestsCount,
+		SpecialNote:   req.SpecialNote,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrTableNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		case errors.Is(err, service.ErrTableNotAvailable), errors.Is(err, service.ErrGuestLimitReached):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrInvalidBookingPeriod), errors.Is(err, service.ErrPastBooking),
+			errors.Is(err, service.ErrAfterLastSeating), errors.Is(err, service.ErrOutsideWorkingHours),
+			errors.Is(err, service.ErrGuestsExceedCapacity), errors.Is(err, service.ErrDurationTooShort),
+			errors.Is(err, service.ErrDepositPaymentRequired):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
-	booking := &domain.Booking{
-		RestaurantID: req.RestaurantID,
-		TableID:      req.TableID,
-		UserID:       req.UserID,
-		BookingDate:  req.BookingDate,
-		StartTime:    req.StartTime,
-		EndTime:      req.EndTime,
-		GuestsCount:  req.GuestsCount,
-		SpecialNote:  req.SpecialNote,
-		Status:       domain.BookingStatusPending,
+	var deposit *domain.Payment
+	var depositURL string
+	if amount := booking.CancellationPolicy.DepositAmount; amount > 0 {
+		deposit, depositURL, err = h.takeDeposit(c, booking, amount, req.PaymentMethod)
+		if err != nil {
+			// A booking whose deposit could not be taken must not hold the
+			// table. Cancelling it voids the payment if one was made.
+			if _, cancelErr := h.bookings.UpdateBookingStatus(c.Request.Context(), booking.ID, userID, domain.BookingStatusCancelled); cancelErr != nil {
+				log.Printf("Cancel booking without deposit error: %v", cancelErr)
+			}
+			if errors.Is(err, service.ErrInsufficientBalance) {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "insufficient balance for the deposit"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		// Wallet deposits are charged at once, which confirms the booking.
+		if deposit.PaymentStatus == domain.PaymentStatusCompleted {
+			booking.Status = domain.BookingStatusConfirmed
+		}
 	}
 
-	if err := h.bookingRepo.Create(c.Request.Context(), booking); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
+	c.JSON(http.StatusCreated, BookingResponse{
+		Booking:           booking,
+		PolicySummary:     service.PolicySummary(*booking.CancellationPolicy, requestLanguages(c)),
+		DepositPayment:    deposit,
+		DepositPaymentURL: depositURL,
+	})
+}
+
+// takeDeposit creates the booking's deposit payment and, for card and Kaspi
+// payments, the checkout page the customer pays it on.
+func (h *BookingHandler) takeDeposit(c *gin.Context, booking *domain.Booking, amount int, method domain.PaymentMethod) (*domain.Payment, string, error) {
+	ctx := c.Request.Context()
+	deposit, err := h.payments.CreatePayment(ctx, *booking.UserID, amount, method, &booking.ID)
+	if err != nil {
+		return nil, "", err
 	}
 
-	c.JSON(http.StatusCreated, booking)
+	var url string
+	switch method {
+	case domain.PaymentMethodHalyk:
+		url, err = h.payments.CreateHalykPayment(ctx, deposit.ID)
+	case domain.PaymentMethodKaspi:
+		url, err = h.payments.CreateKaspiPayment(ctx, deposit.ID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return deposit, url, nil
 }
 
 func (h *BookingHandler) GetBooking(c *gin.Context) {
@@ -79,18 +149,82 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 		return
 	}
 
+	// GetBooking is public; a guest's name and phone are only for the
+	// restaurant's staff.
+	if booking.IsGuest && !h.isRestaurantStaff(c, booking.Restaurant) {
+		booking.GuestName, booking.GuestPhone = "", ""
+	}
+
 	c.JSON(http.StatusOK, booking)
 }
 
+// isRestaurantStaff reports whether the signed-in caller, if any, is staff
+// of the restaurant.
+func (h *BookingHandler) isRestaurantStaff(c *gin.Context, restaurant *domain.Restaurant) bool {
+	value, ok := c.Get("user_id")
+	if !ok || restaurant == nil {
+		return false
+	}
+	userID, ok := value.(uuid.UUID)
+	return ok && h.bookings.CanViewRestaurantBookings(c.Request.Context(), restaurant, userID) == nil
+}
+
+// @Summary List a user's bookings
+// @Description Lists a page of the user's bookings. upcoming=true keeps those that have not started yet, soonest first; otherwise the latest come first. Users can only list their own bookings unless they are admins.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "User ID"
+// @Param status query string false "Booking status" Enums(pending, confirmed, cancelled, completed, no_show)
+// @Param upcoming query bool false "Only bookings that start later (true) or have started (false)"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.Booking
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/users/{id}/bookings [get]
 func (h *BookingHandler) GetUserBookings(c *gin.Context) {
-	idStr := c.Param("user_id")
+	currentID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
 	userID, err := uuid.Parse(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
 		return
 	}
+	role, _ := c.Get("user_role")
+	if userID != currentID && role != domain.UserRoleAdmin {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "only admins can view other users' bookings"})
+		return
+	}
 
-	bookings, err := h.bookingRepo.GetByUserID(c.Request.Context(), userID)
+	filter := repository.UserBookingFilter{Now: time.Now()}
+	if raw := c.Query("status"); raw != "" {
+		status := domain.BookingStatus(raw)
+		if !slices.Contains(bookingStatuses, status) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid status"})
+			return
+		}
+		filter.Status = &status
+	}
+	if raw := c.Query("upcoming"); raw != "" {
+		upcoming, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "upcoming must be true or false"})
+			return
+		}
+		filter.Upcoming = &upcoming
+	}
+
+	page, ok := paginationParams(c, 50)
+	if !ok {
+		return
+	}
+
+	bookings, err := h.bookingRepo.GetByUserID(c.Request.Context(), userID, filter, page.Limit, page.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -99,6 +233,27 @@ func (h *BookingHandler) GetUserBookings(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+// maxBookingRangeDays caps how many days apart from and to may be when
+// listing a restaurant's bookings.
+const maxBookingRangeDays = 31
+
+// @Summary List a restaurant's bookings
+// @Description Lists the bookings that start on the days from to to, inclusive, on the restaurant's clock, earliest first and with their tables. date is a shorthand for a single day; with no dates, today is listed. Cancelled bookings are left out unless status asks for them. Only staff of the restaurant, or an X-API-Key of it, may list them.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param date query string false "Single day, YYYY-MM-DD"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD, at most 31 days after from"
+// @Param status query string false "Booking status" Enums(pending, confirmed, cancelled, completed, no_show)
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.Booking
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/bookings [get]
 func (h *BookingHandler) GetRestaurantBookings(c *gin.Context) {
 	idStr := c.Param("id")
 	restaurantID, err := uuid.Parse(idStr)
@@ -107,14 +262,58 @@ func (h *BookingHandler) GetRestaurantBookings(c *gin.Context) {
 		return
 	}
 
-	dateStr := c.Query("date")
-	date, err := time.Parse("2006-01-02", dateStr)
+	// APIKeyMiddleware has already matched a key to this restaurant; without
+	// one the caller must be logged in as its staff.
+	_, viaAPIKey := c.Get("api_key_restaurant_id")
+	var userID uuid.UUID
+	if !viaAPIKey {
+		var ok bool
+		if userID, ok = currentUserID(c); !ok {
+			return
+		}
+	}
+
+	var status *domain.BookingStatus
+	if raw := c.Query("status"); raw != "" {
+		s := domain.BookingStatus(raw)
+		if !slices.Contains(bookingStatuses, s) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid status"})
+			return
+		}
+		status = &s
+	}
+
+	page, ok := paginationParams(c, 50)
+	if !ok {
+		return
+	}
+
+	restaurant, err := h.restaurantRepo.GetByID(c.Request.Context(), restaurantID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !viaAPIKey {
+		if err := h.bookings.CanViewRestaurantBookings(c.Request.Context(), restaurant, userID); err != nil {
+			if errors.Is(err, service.ErrNotRestaurantStaff) {
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+	}
+
+	from, to, ok := bookingDayRange(c, restaurant.Location())
+	if !ok {
 		return
 	}
 
-	bookings, err := h.bookingRepo.GetByRestaurantID(c.Request.Context(), restaurantID, date)
+	bookings, err := h.bookingRepo.GetByRestaurantIDRange(c.Request.Context(), restaurantID, from, to, status, page.Limit, page.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
@@ -123,6 +322,61 @@ func (h *BookingHandler) GetRestaurantBookings(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+// bookingStatuses are the values the status filter accepts.
+var bookingStatuses = []domain.BookingStatus{
+	domain.BookingStatusPending,
+	domain.BookingStatusConfirmed,
+	domain.BookingStatusCancelled,
+	domain.BookingStatusCompleted,
+	domain.BookingStatusNoShow,
+}
+
+// bookingDayRange reads the date, or from and to, query parameters as days
+// in loc and returns the span from the start of the first day to the end
+// of the last. With none of them it is today. When they are malformed,
+// reversed or too far apart it writes a 400 response and returns false.
+func bookingDayRange(c *gin.Context, loc *time.Location) (time.Time, time.Time, bool) {
+	fromStr, toStr := c.Query("from"), c.Query("to")
+	if date := c.Query("date"); date != "" {
+		if fromStr != "" || toStr != "" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "use either date or from and to"})
+			return time.Time{}, time.Time{}, false
+		}
+		fromStr, toStr = date, date
+	}
+
+	today := time.Now().In(loc).Format("2006-01-02")
+	if fromStr == "" {
+		fromStr = today
+	}
+	if toStr == "" {
+		toStr = fromStr
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.ParseInLocation("2006-01-02", toStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "to must not be before from"})
+		return time.Time{}, time.Time{}, false
+	}
+	if to.After(from.AddDate(0, 0, maxBookingRangeDays)) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("from and to must be at most %d days apart", maxBookingRangeDays)})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to.AddDate(0, 0, 1), true
+}
+
+// UpdateBookingStatus lets staff of the booking's restaurant set any status
+// and the customer who made the booking cancel it.
 func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -131,9 +385,8 @@ func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 		return
 	}
 
-	booking, err := h.bookingRepo.GetByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -143,40 +396,210 @@ func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 		return
 	}
 
-	booking.Status = req.Status
+	h.setBookingStatus(c, id, userID, req.Status)
+}
 
-	if err := h.bookingRepo.Update(c.Request.Context(), booking); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+// setBookingStatus changes the booking's status through the booking service
+// and writes the response, including what follows from a cancellation.
+func (h *BookingHandler) setBookingStatus(c *gin.Context, id, userID uuid.UUID, status domain.BookingStatus) {
+	change, err := h.bookings.UpdateBookingStatus(c.Request.Context(), id, userID, status)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		case errors.Is(err, service.ErrNotRestaurantStaff):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+		case errors.Is(err, service.ErrCustomerCanOnlyCancel):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrDepositUnpaid):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
+	booking := change.Booking
+
+	// Staff cancelling a confirmed booking is a restaurant-initiated
+	// cancellation, so the customer is offered a way to rebook. The
+	// cancellation stands even if building the offer fails.
+	if change.ByStaff && change.Previous == domain.BookingStatusConfirmed && booking.Status == domain.BookingStatusCancelled {
+		if _, err := h.rebooking.OfferRebooking(c.Request.Context(), booking); err != nil {
+			log.Printf("Offer rebooking error: %v", err)
+		}
+	}
+	if booking.Status == domain.BookingStatusCancelled && holdsTable(change.Previous) {
+		h.waitlist.SlotFreed(c.Request.Context(), booking)
+	}
 
 	c.JSON(http.StatusOK, booking)
 }
 
-func (h *BookingHandler) CancelBooking(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+// @Summary Modify a booking
+// @Description Moves a pending or confirmed booking to new times, a new party size or another table, checked like a new booking. Staff of the restaurant may modify it, and so may its customer until it starts. The other party is notified.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body ModifyBookingRequest true "New booking details"
+// @Success 200 {object} domain.Booking
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/bookings/{id} [put]
+func (h *BookingHandler) ModifyBooking(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid booking id"})
 		return
 	}
 
-	booking, err := h.bookingRepo.GetByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	booking.Status = domain.BookingStatusCancelled
+	var req ModifyBookingRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
 
-	if err := h.bookingRepo.Update(c.Request.Context(), booking); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	booking, err := h.bookings.ModifyBooking(c.Request.Context(), service.ModifyBookingRequest{
+		BookingID:   id,
+		UserID:      userID,
+		StartTime:   req.StartTime.Time,
+		EndTime:     req.EndTime.Time,
+		GuestsCount: req.GuestsCount,
+		TableID:     req.TableID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		case errors.Is(err, service.ErrTableNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		case errors.Is(err, service.ErrNotRestaurantStaff):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+		case errors.Is(err, service.ErrBookingStarted), errors.Is(err, service.ErrBookingNotModifiable),
+			errors.Is(err, service.ErrTableNotAvailable), errors.Is(err, service.ErrGuestLimitReached):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrInvalidBookingPeriod), errors.Is(err, service.ErrPastBooking),
+			errors.Is(err, service.ErrAfterLastSeating), errors.Is(err, service.ErrOutsideWorkingHours),
+			errors.Is(err, service.ErrGuestsExceedCapacity), errors.Is(err, service.ErrDurationTooShort):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, booking)
 }
 
+// @Summary Book a table for a guest
+// @Description Staff of the restaurant book a table for a guest without an account, such as a reservation taken over the phone, checked like any other booking. The booking is confirmed and needs no deposit. A user whose account phone matches guest_phone can claim it later.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param request body CreateGuestBookingRequest true "Guest booking details"
+// @Success 201 {object} BookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/restaurants/{id}/bookings/guest [post]
+func (h *BookingHandler) CreateGuestBooking(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateGuestBookingRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+
+	booking, err := h.bookings.CreateGuestBooking(c.Request.Context(), service.GuestBookingRequest{
+		RestaurantID: restaurantID,
+		TableID:      req.TableID,
+		StaffID:      staffID,
+		GuestName:    req.GuestName,
+		GuestPhone:   req.GuestPhone,
+		StartTime:    req.StartTime.Time,
+		EndTime:      req.EndTime.Time,
+		GuestsCount:  req.GuestsCount,
+		SpecialNote:  req.SpecialNote,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrTableNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		case errors.Is(err, service.ErrNotRestaurantStaff):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+		case errors.Is(err, service.ErrTableNotAvailable), errors.Is(err, service.ErrGuestLimitReached):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrGuestNameRequired), errors.Is(err, service.ErrGuestPhoneRequired),
+			errors.Is(err, service.ErrInvalidPhone),
+			errors.Is(err, service.ErrInvalidBookingPeriod), errors.Is(err, service.ErrPastBooking),
+			errors.Is(err, service.ErrAfterLastSeating), errors.Is(err, service.ErrOutsideWorkingHours),
+			errors.Is(err, service.ErrGuestsExceedCapacity), errors.Is(err, service.ErrDurationTooShort):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, BookingResponse{
+		Booking:       booking,
+		PolicySummary: service.PolicySummary(*booking.CancellationPolicy, requestLanguages(c)),
+	})
+}
+
+// @Summary Cancel a booking
+// @Description Cancels the booking like setting its status to cancelled: its customer or staff of its restaurant may, and a deposit payment still pending is voided so that it can no longer be completed.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} domain.Booking
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/bookings/{id}/cancel [post]
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid booking id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	h.setBookingStatus(c, id, userID, domain.BookingStatusCancelled)
+}
+
+// holdsTable reports whether a booking in status keeps its table from
+// others, so cancelling it frees the table for the waitlist.
+func holdsTable(status domain.BookingStatus) bool {
+	return status == domain.BookingStatusPending || status == domain.BookingStatusConfirmed
+}
+
 func (h *BookingHandler) CheckTableAvailability(c *gin.Context) {
 	tableIDStr := c.Query("table_id")
 	tableID, err := uuid.Parse(tableIDStr)
@@ -186,51 +609,112 @@ func (h *BookingHandler) CheckTableAvailability(c *gin.Context) {
 	}
 
 	startTimeStr := c.Query("start_time")
-	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	startTime, err := apitime.Parse(startTimeStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid start_time format, use RFC3339"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid start_time format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
 		return
 	}
 
 	endTimeStr := c.Query("end_time")
-	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	endTime, err := apitime.Parse(endTimeStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid end_time format, use RFC3339"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid end_time format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
 		return
 	}
 
-	available, err := h.bookingRepo.CheckTableAvailability(c.Request.Context(), tableID, startTime, endTime)
+	table, err := h.tableRepo.GetByID(c.Request.Context(), tableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	restaurant, err := h.restaurantRepo.GetByID(c.Request.Context(), table.RestaurantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	available, err := h.bookingRepo.CheckTableAvailability(c.Request.Context(), tableID, startTime.Time, endTime.Time)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// The policy is shown before booking so the customer knows the terms
+	// the booking would be made under.
 	c.JSON(http.StatusOK, AvailabilityResponse{
-		Available: available,
-		TableID:   tableID,
-		StartTime: startTime,
-		EndTime:   endTime,
+		Available:          available,
+		TableID:            tableID,
+		StartTime:          startTime,
+		EndTime:            endTime,
+		DepositAmount:      table.DepositAmount,
+		MinSpend:           table.MinSpend,
+		CancellationPolicy: cancellationPolicyResponse(c, service.TablePolicy(restaurant, table)),
 	})
 }
 
 type CreateBookingRequest struct {
-	RestaurantID uuid.UUID `json:"restaurant_id" binding:"required"`
-	TableID      uuid.UUID `json:"table_id" binding:"required"`
-	UserID       uuid.UUID `json:"user_id" binding:"required"`
-	BookingDate  time.Time `json:"booking_date" binding:"required"`
-	StartTime    time.Time `json:"start_time" binding:"required"`
-	EndTime      time.Time `json:"end_time" binding:"required"`
-	GuestsCount  int       `json:"guests_count" binding:"required,min=1"`
-	SpecialNote  string    `json:"special_note"`
+	RestaurantID uuid.UUID    `json:"restaurant_id" binding:"required"`
+	TableID      uuid.UUID    `json:"table_id" binding:"required"`
+	BookingDate  apitime.Time `json:"booking_date" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	StartTime    apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime      apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	GuestsCount  int          `json:"guests_count" binding:"required,min=1"`
+	SpecialNote  string       `json:"special_note"`
+	// PaymentMethod pays the table's deposit and is required for tables
+	// that have one.
+	PaymentMethod domain.PaymentMethod `json:"payment_method" binding:"omitempty,oneof=wallet halyk kaspi" example:"kaspi"`
+}
+
+// BookingResponse is a newly created booking with the summary of the
+// cancellation policy recorded on it. DepositPayment is the payment created
+// for the policy's deposit; wallet deposits are charged at once, the others
+// stay pending until the customer pays at DepositPaymentURL. The booking is
+// confirmed when its deposit is paid.
+type BookingResponse struct {
+	*domain.Booking
+	PolicySummary     string          `json:"policy_summary"`
+	DepositPayment    *domain.Payment `json:"deposit_payment,omitempty"`
+	DepositPaymentURL string          `json:"deposit_payment_url,omitempty" example:"https://kaspi-mock.kz/pay/3f1c2a9e"`
+}
+
+// ModifyBookingRequest is the new state of a booking. Leaving out TableID
+// keeps the booking's table.
+type ModifyBookingRequest struct {
+	StartTime   apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime     apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	GuestsCount int          `json:"guests_count" binding:"required,min=1"`
+	TableID     *uuid.UUID   `json:"table_id"`
+}
+
+// CreateGuestBookingRequest is a booking staff take for a guest without an
+// account.
+type CreateGuestBookingRequest struct {
+	TableID     uuid.UUID    `json:"table_id" binding:"required"`
+	GuestName   string       `json:"guest_name" binding:"required,max=100"`
+	GuestPhone  string       `json:"guest_phone" binding:"required"`
+	StartTime   apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime     apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	GuestsCount int          `json:"guests_count" binding:"required,min=1"`
+	SpecialNote string       `json:"special_note"`
 }
 
 type UpdateBookingStatusRequest struct {
-	Status domain.BookingStatus `json:"status" binding:"required"`
+	Status domain.BookingStatus `json:"status" binding:"required,oneof=pending confirmed cancelled completed no_show"`
 }
 
 type AvailabilityResponse struct {
-	Available bool      `json:"available"`
-	TableID   uuid.UUID `json:"table_id"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	Available bool         `json:"available"`
+	TableID   uuid.UUID    `json:"table_id"`
+	StartTime apitime.Time `json:"start_time" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime   apitime.Time `json:"end_time" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	// DepositAmount is charged when the table is booked and MinSpend is what
+	// the party is expected to order, in KZT. 0 means none.
+	DepositAmount int `json:"deposit_amount" example:"10000"`
+	MinSpend      int `json:"min_spend" example:"0"`
+
+	CancellationPolicy *CancellationPolicyResponse `json:"cancellation_policy"`
 }
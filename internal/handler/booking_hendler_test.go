@@ -0,0 +1,708 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type stubBookingWriter struct {
+	req *service.CreateBookingRequest
+	// customer is who CreateBooking was asked to book for.
+	customer uuid.UUID
+	modify   *service.ModifyBookingRequest
+	guest    *service.GuestBookingRequest
+	table    *domain.Table
+	err      error
+	change   *service.BookingStatusChange
+	userID   uuid.UUID
+	status   domain.BookingStatus
+}
+
+func (s *stubBookingWriter) CreateBooking(ctx context.Context, userID uuid.UUID, req service.CreateBookingRequest) (*domain.Booking, error) {
+	s.req, s.customer = &req, userID
+	if s.err != nil {
+		return nil, s.err
+	}
+	table := s.table
+	if table == nil {
+		table = &domain.Table{ID: req.TableID, RestaurantID: req.RestaurantID}
+	}
+	return &domain.Booking{
+		ID:                 uuid.New(),
+		RestaurantID:       req.RestaurantID,
+		TableID:            req.TableID,
+		UserID:             &userID,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		GuestsCount:        req.GuestsCount,
+		Status:             domain.BookingStatusPending,
+		CancellationPolicy: &domain.CancellationPolicy{DepositAmount: table.DepositAmount},
+		Table:              table,
+	}, nil
+}
+
+func bookingBody(extra string) string {
+	return fmt.Sprintf(`{"restaurant_id":%q,"table_id":%q,"booking_date":"2024-06-04T00:00:00+05:00",`+
+		`"start_time":"2024-06-04T19:00:00+05:00","end_time":"2024-06-04T21:00:00+05:00","guests_count":2%s}`,
+		uuid.NewString(), uuid.NewString(), extra)
+}
+
+func createBooking(creator service.BookingWriter, payments service.PaymentService, body string) (*BookingResponse, int) {
+	userID := uuid.New()
+	return createBookingAs(creator, payments, &userID, body)
+}
+
+func createBookingAs(creator service.BookingWriter, payments service.PaymentService, userID *uuid.UUID, body string) (*BookingResponse, int) {
+	h := NewBookingHandler(nil, nil, nil, nil, payments, creator, nil)
+	w := performAsUser(h.CreateBooking, http.MethodPost, "/api/bookings", "/api/bookings", userID, body)
+
+	var resp BookingResponse
+	if w.Code == http.StatusCreated {
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	}
+	return &resp, w.Code
+}
+
+func TestCreateBooking(t *testing.T) {
+	creator := &stubBookingWriter{}
+
+	resp, code := createBooking(creator, nil, bookingBody(`,"special_note":"window please"`))
+
+	require.Equal(t, http.StatusCreated, code)
+	require.NotNil(t, creator.req)
+	assert.Equal(t, 2, creator.req.GuestsCount)
+	assert.Equal(t, "window please", creator.req.SpecialNote)
+	assert.Equal(t, 14, creator.req.StartTime.UTC().Hour())
+	assert.NotNil(t, resp.Booking)
+	assert.NotEmpty(t, resp.PolicySummary)
+	assert.Nil(t, resp.DepositPayment)
+}
+
+func TestCreateBooking_TakesTheDeposit(t *testing.T) {
+	creator := &stubBookingWriter{table: &domain.Table{ID: uuid.New(), DepositAmount: 10000}}
+
+	resp, code := createBooking(creator, &stubPaymentService{}, bookingBody(`,"payment_method":"kaspi"`))
+
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, domain.PaymentMethodKaspi, creator.req.PaymentMethod)
+	require.NotNil(t, resp.DepositPayment)
+	assert.Equal(t, 10000, resp.DepositPayment.Amount)
+	assert.Equal(t, resp.Booking.ID, *resp.DepositPayment.BookingID)
+	assert.Equal(t, "https://kaspi-mock.kz/pay/"+resp.DepositPayment.ID.String(), resp.DepositPaymentURL)
+	assert.Equal(t, domain.BookingStatusPending, resp.Booking.Status)
+}
+
+func TestCreateBooking_WalletDepositConfirms(t *testing.T) {
+	creator := &stubBookingWriter{table: &domain.Table{ID: uuid.New(), DepositAmount: 10000}}
+	payments := &stubPaymentService{status: domain.PaymentStatusCompleted}
+
+	resp, code := createBooking(creator, payments, bookingBody(`,"payment_method":"wallet"`))
+
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, domain.BookingStatusConfirmed, resp.Booking.Status)
+	assert.Empty(t, resp.DepositPaymentURL)
+}
+
+func TestCreateBooking_CheckoutFailureCancels(t *testing.T) {
+	creator := &stubBookingWriter{table: &domain.Table{ID: uuid.New(), DepositAmount: 10000}}
+	payments := &stubPaymentService{checkoutErr: errors.New("kaspi is down")}
+
+	_, code := createBooking(creator, payments, bookingBody(`,"payment_method":"kaspi"`))
+
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, domain.BookingStatusCancelled, creator.status)
+	assert.Equal(t, creator.customer, creator.userID)
+}
+
+func TestCreateBooking_BooksForTheCaller(t *testing.T) {
+	creator := &stubBookingWriter{}
+	userID := uuid.New()
+
+	_, code := createBookingAs(creator, nil, &userID, bookingBody(fmt.Sprintf(`,"user_id":%q`, uuid.NewString())))
+
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, userID, creator.customer)
+}
+
+func TestCreateBooking_NoUser(t *testing.T) {
+	creator := &stubBookingWriter{}
+
+	_, code := createBookingAs(creator, nil, nil, bookingBody(""))
+
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Nil(t, creator.req)
+}
+
+func TestCreateBooking_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"unknown restaurant":     {service.ErrRestaurantNotFound, http.StatusNotFound},
+		"unknown table":          {service.ErrTableNotFound, http.StatusNotFound},
+		"ends before it starts":  {service.ErrInvalidBookingPeriod, http.StatusBadRequest},
+		"in the past":            {service.ErrPastBooking, http.StatusBadRequest},
+		"after the last seating": {fmt.Errorf("%w, latest start time is 22:00", service.ErrAfterLastSeating), http.StatusBadRequest},
+		"while closed":           {service.ErrOutsideWorkingHours, http.StatusBadRequest},
+		"too many guests":        {fmt.Errorf("%w, it seats 2 to 4 guests", service.ErrGuestsExceedCapacity), http.StatusBadRequest},
+		"too short":              {fmt.Errorf("%w, minimum is 60 minutes", service.ErrDurationTooShort), http.StatusBadRequest},
+		"deposit unpaid":         {service.ErrDepositPaymentRequired, http.StatusBadRequest},
+		"table taken":            {service.ErrTableNotAvailable, http.StatusConflict},
+		"restaurant full":        {fmt.Errorf("%w: 38 of 40 guests already booked for that time", service.ErrGuestLimitReached), http.StatusConflict},
+		"database down":          {errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, code := createBooking(&stubBookingWriter{err: tc.err}, nil, bookingBody(""))
+
+			assert.Equal(t, tc.want, code)
+		})
+	}
+}
+
+func TestCreateBooking_InvalidBody(t *testing.T) {
+	creator := &stubBookingWriter{}
+
+	_, code := createBooking(creator, nil, bookingBody(`,"guests_count":0`))
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Nil(t, creator.req)
+}
+
+func (s *stubBookingWriter) UpdateBookingStatus(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID, status domain.BookingStatus) (*service.BookingStatusChange, error) {
+	s.userID, s.status = userID, status
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.change == nil {
+		s.change = &service.BookingStatusChange{Booking: &domain.Booking{ID: bookingID}}
+	}
+	s.change.Booking.Status = status
+	return s.change, nil
+}
+
+func updateBookingStatus(h *BookingHandler, userID uuid.UUID, body string) *httptest.ResponseRecorder {
+	return performAsUser(h.UpdateBookingStatus, http.MethodPatch, "/api/bookings/:id/status",
+		"/api/bookings/"+uuid.NewString()+"/status", &userID, body)
+}
+
+func TestUpdateBookingStatus(t *testing.T) {
+	userID := uuid.New()
+	booking := &domain.Booking{ID: uuid.New(), Status: domain.BookingStatusPending}
+	writer := &stubBookingWriter{change: &service.BookingStatusChange{Booking: booking, Previous: booking.Status, ByStaff: true}}
+	rebooking := &stubRebookingService{}
+
+	w := updateBookingStatus(NewBookingHandler(nil, nil, nil, rebooking, nil, writer, nil), userID, `{"status":"confirmed"}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, writer.userID)
+	assert.Equal(t, domain.BookingStatusConfirmed, writer.status)
+	assert.Empty(t, rebooking.offered)
+}
+
+func TestUpdateBookingStatus_RebookingOffers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		byStaff bool
+		offered int
+	}{
+		"staff cancel":    {true, 1},
+		"customer cancel": {false, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			booking := &domain.Booking{ID: uuid.New(), Status: domain.BookingStatusConfirmed}
+			writer := &stubBookingWriter{change: &service.BookingStatusChange{Booking: booking, Previous: booking.Status, ByStaff: tc.byStaff}}
+			rebooking := &stubRebookingService{}
+			waitlist := &stubWaitlistService{}
+
+			w := updateBookingStatus(NewBookingHandler(nil, nil, nil, rebooking, nil, writer, waitlist), uuid.New(), `{"status":"cancelled"}`)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Len(t, rebooking.offered, tc.offered)
+			// Either way the table is free for the waitlist.
+			assert.Equal(t, []*domain.Booking{booking}, waitlist.freed)
+		})
+	}
+}
+
+func TestUpdateBookingStatus_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		body string
+		err  error
+		want int
+	}{
+		"unknown status":       {`{"status":"seated"}`, nil, http.StatusBadRequest},
+		"unknown booking":      {`{"status":"confirmed"}`, service.ErrBookingNotFound, http.StatusNotFound},
+		"unrelated user":       {`{"status":"confirmed"}`, service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"customer confirming":  {`{"status":"confirmed"}`, service.ErrCustomerCanOnlyCancel, http.StatusForbidden},
+		"deposit unpaid":       {`{"status":"confirmed"}`, service.ErrDepositUnpaid, http.StatusConflict},
+		"manager lookup fails": {`{"status":"confirmed"}`, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writer := &stubBookingWriter{err: tc.err}
+
+			w := updateBookingStatus(NewBookingHandler(nil, nil, nil, nil, nil, writer, nil), uuid.New(), tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func cancelBooking(h *BookingHandler, userID *uuid.UUID) *httptest.ResponseRecorder {
+	return performAsUser(h.CancelBooking, http.MethodPost, "/api/bookings/:id/cancel",
+		"/api/bookings/"+uuid.NewString()+"/cancel", userID, "")
+}
+
+func TestCancelBooking(t *testing.T) {
+	userID := uuid.New()
+	booking := &domain.Booking{ID: uuid.New(), Status: domain.BookingStatusPending}
+	writer := &stubBookingWriter{change: &service.BookingStatusChange{Booking: booking, Previous: booking.Status}}
+	waitlist := &stubWaitlistService{}
+
+	w := cancelBooking(NewBookingHandler(nil, nil, nil, &stubRebookingService{}, nil, writer, waitlist), &userID)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, writer.userID)
+	assert.Equal(t, domain.BookingStatusCancelled, writer.status)
+	assert.Equal(t, []*domain.Booking{booking}, waitlist.freed)
+}
+
+func TestCancelBooking_Rejections(t *testing.T) {
+	userID := uuid.New()
+	cases := map[string]struct {
+		userID *uuid.UUID
+		err    error
+		want   int
+	}{
+		"no user":         {nil, nil, http.StatusUnauthorized},
+		"unknown booking": {&userID, service.ErrBookingNotFound, http.StatusNotFound},
+		"unrelated user":  {&userID, service.ErrNotRestaurantStaff, http.StatusForbidden},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writer := &stubBookingWriter{err: tc.err}
+
+			w := cancelBooking(NewBookingHandler(nil, nil, nil, nil, nil, writer, nil), tc.userID)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func (s *stubBookingWriter) ModifyBooking(ctx context.Context, req service.ModifyBookingRequest) (*domain.Booking, error) {
+	s.modify = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Booking{ID: req.BookingID, StartTime: req.StartTime, EndTime: req.EndTime, GuestsCount: req.GuestsCount}, nil
+}
+
+func (s *stubBookingWriter) CreateGuestBooking(ctx context.Context, req service.GuestBookingRequest) (*domain.Booking, error) {
+	s.guest = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Booking{
+		ID:                 uuid.New(),
+		RestaurantID:       req.RestaurantID,
+		TableID:            req.TableID,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		GuestsCount:        req.GuestsCount,
+		Status:             domain.BookingStatusConfirmed,
+		IsGuest:            true,
+		GuestName:          req.GuestName,
+		GuestPhone:         req.GuestPhone,
+		CancellationPolicy: &domain.CancellationPolicy{},
+	}, nil
+}
+
+func modifyBooking(writer service.BookingWriter, userID uuid.UUID, bookingID uuid.UUID, body string) *httptest.ResponseRecorder {
+	h := NewBookingHandler(nil, nil, nil, nil, nil, writer, nil)
+	return performAsUser(h.ModifyBooking, http.MethodPut, "/api/bookings/:id", "/api/bookings/"+bookingID.String(), &userID, body)
+}
+
+func TestModifyBooking(t *testing.T) {
+	writer := &stubBookingWriter{}
+	userID, bookingID, tableID := uuid.New(), uuid.New(), uuid.New()
+
+	w := modifyBooking(writer, userID, bookingID, fmt.Sprintf(`{"start_time":"2024-06-04T20:00:00+05:00",`+
+		`"end_time":"2024-06-04T22:00:00+05:00","guests_count":4,"table_id":%q}`, tableID))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, writer.modify)
+	assert.Equal(t, bookingID, writer.modify.BookingID)
+	assert.Equal(t, userID, writer.modify.UserID)
+	assert.Equal(t, 15, writer.modify.StartTime.UTC().Hour())
+	assert.Equal(t, 4, writer.modify.GuestsCount)
+	assert.Equal(t, tableID, *writer.modify.TableID)
+}
+
+func TestModifyBooking_KeepsTheTable(t *testing.T) {
+	writer := &stubBookingWriter{}
+
+	w := modifyBooking(writer, uuid.New(), uuid.New(), `{"start_time":"2024-06-04T20:00:00+05:00",`+
+		`"end_time":"2024-06-04T22:00:00+05:00","guests_count":3}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, writer.modify.TableID)
+}
+
+func TestModifyBooking_Rejections(t *testing.T) {
+	body := `{"start_time":"2024-06-04T20:00:00+05:00","end_time":"2024-06-04T22:00:00+05:00","guests_count":2}`
+	cases := map[string]struct {
+		body string
+		err  error
+		want int
+	}{
+		"no guests":         {`{"start_time":"2024-06-04T20:00:00+05:00","end_time":"2024-06-04T22:00:00+05:00"}`, nil, http.StatusBadRequest},
+		"naive time":        {`{"start_time":"2024-06-04T20:00:00","end_time":"2024-06-04T22:00:00+05:00","guests_count":2}`, nil, http.StatusBadRequest},
+		"unknown booking":   {body, service.ErrBookingNotFound, http.StatusNotFound},
+		"unknown table":     {body, service.ErrTableNotFound, http.StatusNotFound},
+		"unrelated user":    {body, service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"already started":   {body, service.ErrBookingStarted, http.StatusConflict},
+		"already cancelled": {body, service.ErrBookingNotModifiable, http.StatusConflict},
+		"table taken":       {body, service.ErrTableNotAvailable, http.StatusConflict},
+		"restaurant full":   {body, service.ErrGuestLimitReached, http.StatusConflict},
+		"too many guests":   {body, fmt.Errorf("%w, it seats 2 to 4 guests", service.ErrGuestsExceedCapacity), http.StatusBadRequest},
+		"while closed":      {body, service.ErrOutsideWorkingHours, http.StatusBadRequest},
+		"database down":     {body, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := modifyBooking(&stubBookingWriter{err: tc.err}, uuid.New(), uuid.New(), tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+type stubRestaurantRepository struct {
+	repository.RestaurantRepository
+	restaurant *domain.Restaurant
+}
+
+func (r *stubRestaurantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error) {
+	if r.restaurant == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.restaurant, nil
+}
+
+// rangeBookingRepository records the arguments of GetByRestaurantIDRange.
+type rangeBookingRepository struct {
+	repository.BookingRepository
+	from, to      time.Time
+	status        *domain.BookingStatus
+	limit, offset int
+}
+
+func (r *rangeBookingRepository) GetByRestaurantIDRange(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, status *domain.BookingStatus, limit, offset int) ([]*domain.Booking, error) {
+	r.from, r.to, r.status, r.limit, r.offset = from, to, status, limit, offset
+	return []*domain.Booking{{ID: uuid.New(), Table: &domain.Table{TableNumber: "T1"}}}, nil
+}
+
+func (s *stubBookingWriter) CanViewRestaurantBookings(ctx context.Context, restaurant *domain.Restaurant, userID uuid.UUID) error {
+	s.userID = userID
+	return s.err
+}
+
+func getRestaurantBookings(bookings repository.BookingRepository, restaurant *domain.Restaurant, query string) *httptest.ResponseRecorder {
+	staffID := uuid.New()
+	h := NewBookingHandler(bookings, nil, &stubRestaurantRepository{restaurant: restaurant}, nil, nil, &stubBookingWriter{}, nil)
+	return performAsUser(h.GetRestaurantBookings, http.MethodGet, "/api/restaurants/:id/bookings",
+		"/api/restaurants/"+uuid.NewString()+"/bookings"+query, &staffID, "")
+}
+
+func TestGetRestaurantBookings_Access(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), Timezone: "Asia/Almaty"}
+	userID := uuid.New()
+	cases := map[string]struct {
+		userID *uuid.UUID
+		apiKey bool
+		err    error
+		want   int
+	}{
+		"staff":          {&userID, false, nil, http.StatusOK},
+		"api key":        {nil, true, nil, http.StatusOK},
+		"no login":       {nil, false, nil, http.StatusUnauthorized},
+		"not staff":      {&userID, false, service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"check breaking": {&userID, false, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writer := &stubBookingWriter{err: tc.err}
+			h := NewBookingHandler(&rangeBookingRepository{}, nil, &stubRestaurantRepository{restaurant: restaurant}, nil, nil, writer, nil)
+			handlerFunc := h.GetRestaurantBookings
+			if tc.apiKey {
+				handlerFunc = func(c *gin.Context) {
+					c.Set("api_key_restaurant_id", restaurant.ID)
+					h.GetRestaurantBookings(c)
+				}
+			}
+
+			w := performAsUser(handlerFunc, http.MethodGet, "/api/restaurants/:id/bookings",
+				"/api/restaurants/"+restaurant.ID.String()+"/bookings", tc.userID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+			if tc.userID != nil {
+				assert.Equal(t, *tc.userID, writer.userID)
+			}
+		})
+	}
+}
+
+func TestGetRestaurantBookings_Range(t *testing.T) {
+	bookings := &rangeBookingRepository{}
+	restaurant := &domain.Restaurant{ID: uuid.New(), Timezone: "Asia/Almaty"}
+
+	w := getRestaurantBookings(bookings, restaurant, "?from=2024-06-03&to=2024-06-09&status=confirmed&limit=20&offset=40")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	loc := restaurant.Location()
+	assert.True(t, time.Date(2024, time.June, 3, 0, 0, 0, 0, loc).Equal(bookings.from))
+	assert.True(t, time.Date(2024, time.June, 10, 0, 0, 0, 0, loc).Equal(bookings.to))
+	require.NotNil(t, bookings.status)
+	assert.Equal(t, domain.BookingStatusConfirmed, *bookings.status)
+	assert.Equal(t, 20, bookings.limit)
+	assert.Equal(t, 40, bookings.offset)
+	assert.Contains(t, w.Body.String(), `"table_number":"T1"`)
+}
+
+func TestGetRestaurantBookings_Defaults(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), Timezone: "Asia/Almaty"}
+	today := time.Now().In(restaurant.Location())
+	midnight := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, restaurant.Location())
+
+	for name, query := range map[string]string{
+		"nothing":     "",
+		"single date": "?date=" + today.Format("2006-01-02"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			bookings := &rangeBookingRepository{}
+
+			w := getRestaurantBookings(bookings, restaurant, query)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.True(t, midnight.Equal(bookings.from))
+			assert.True(t, midnight.AddDate(0, 0, 1).Equal(bookings.to))
+			assert.Nil(t, bookings.status)
+			assert.Equal(t, 50, bookings.limit)
+		})
+	}
+}
+
+func TestGetRestaurantBookings_Rejections(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New()}
+	cases := map[string]struct {
+		query      string
+		restaurant *domain.Restaurant
+		want       int
+	}{
+		"bad date":           {"?from=03.06.2024", restaurant, http.StatusBadRequest},
+		"reversed":           {"?from=2024-06-09&to=2024-06-03", restaurant, http.StatusBadRequest},
+		"too far apart":      {"?from=2024-06-01&to=2024-07-03", restaurant, http.StatusBadRequest},
+		"date and range":     {"?date=2024-06-03&from=2024-06-03", restaurant, http.StatusBadRequest},
+		"unknown status":     {"?status=seated", restaurant, http.StatusBadRequest},
+		"bad limit":          {"?limit=0", restaurant, http.StatusBadRequest},
+		"unknown restaurant": {"", nil, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := getRestaurantBookings(&rangeBookingRepository{}, tc.restaurant, tc.query)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestGetRestaurantBookings_ThirtyOneDays(t *testing.T) {
+	bookings := &rangeBookingRepository{}
+
+	w := getRestaurantBookings(bookings, &domain.Restaurant{ID: uuid.New()}, "?from=2024-06-01&to=2024-07-02")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// userBookingRepository records the arguments of GetByUserID.
+type userBookingRepository struct {
+	repository.BookingRepository
+	userID        uuid.UUID
+	filter        repository.UserBookingFilter
+	limit, offset int
+}
+
+func (r *userBookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter repository.UserBookingFilter, limit, offset int) ([]*domain.Booking, error) {
+	r.userID, r.filter, r.limit, r.offset = userID, filter, limit, offset
+	return []*domain.Booking{{ID: uuid.New(), UserID: &userID}}, nil
+}
+
+func getUserBookings(bookings repository.BookingRepository, currentID uuid.UUID, role domain.UserRole, userID uuid.UUID, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/users/:id/bookings", func(c *gin.Context) {
+		c.Set("user_id", currentID)
+		c.Set("user_role", role)
+		c.Next()
+	}, NewBookingHandler(bookings, nil, nil, nil, nil, nil, nil).GetUserBookings)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/"+userID.String()+"/bookings"+query, nil))
+	return w
+}
+
+func TestGetUserBookings_Filters(t *testing.T) {
+	bookings := &userBookingRepository{}
+	userID := uuid.New()
+
+	w := getUserBookings(bookings, userID, domain.UserRoleCustomer, userID, "?status=confirmed&upcoming=true&limit=10&offset=20")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, bookings.userID)
+	require.NotNil(t, bookings.filter.Status)
+	assert.Equal(t, domain.BookingStatusConfirmed, *bookings.filter.Status)
+	require.NotNil(t, bookings.filter.Upcoming)
+	assert.True(t, *bookings.filter.Upcoming)
+	assert.WithinDuration(t, time.Now(), bookings.filter.Now, time.Minute)
+	assert.Equal(t, 10, bookings.limit)
+	assert.Equal(t, 20, bookings.offset)
+}
+
+func TestGetUserBookings_Defaults(t *testing.T) {
+	bookings := &userBookingRepository{}
+	userID := uuid.New()
+
+	w := getUserBookings(bookings, userID, domain.UserRoleCustomer, userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, bookings.filter.Status)
+	assert.Nil(t, bookings.filter.Upcoming)
+	assert.Equal(t, 50, bookings.limit)
+	assert.Equal(t, 0, bookings.offset)
+}
+
+func TestGetUserBookings_OtherUser(t *testing.T) {
+	otherID := uuid.New()
+
+	w := getUserBookings(&userBookingRepository{}, uuid.New(), domain.UserRoleCustomer, otherID, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	bookings := &userBookingRepository{}
+	w = getUserBookings(bookings, uuid.New(), domain.UserRoleAdmin, otherID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, otherID, bookings.userID)
+}
+
+func TestGetUserBookings_Rejections(t *testing.T) {
+	for name, query := range map[string]string{
+		"unknown status": "?status=seated",
+		"bad upcoming":   "?upcoming=soon",
+		"bad offset":     "?offset=-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			userID := uuid.New()
+
+			w := getUserBookings(&userBookingRepository{}, userID, domain.UserRoleCustomer, userID, query)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func createGuestBooking(writer service.BookingWriter, staffID *uuid.UUID, restaurantID uuid.UUID, body string) *httptest.ResponseRecorder {
+	h := NewBookingHandler(nil, nil, nil, nil, nil, writer, nil)
+	return performAsUser(h.CreateGuestBooking, http.MethodPost, "/api/restaurants/:id/bookings/guest",
+		"/api/restaurants/"+restaurantID.String()+"/bookings/guest", staffID, body)
+}
+
+func guestBookingBody(tableID uuid.UUID) string {
+	return fmt.Sprintf(`{"table_id":%q,"guest_name":"Aigerim","guest_phone":"+77011234567",`+
+		`"start_time":"2024-06-04T19:00:00+05:00","end_time":"2024-06-04T21:00:00+05:00","guests_count":2}`, tableID)
+}
+
+func TestGetBooking_GuestDetailsOnlyForStaff(t *testing.T) {
+	userID := uuid.New()
+	cases := map[string]struct {
+		userID  *uuid.UUID
+		err     error
+		visible bool
+	}{
+		"staff":     {&userID, nil, true},
+		"not staff": {&userID, service.ErrNotRestaurantStaff, false},
+		"anonymous": {nil, nil, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			booking := &domain.Booking{ID: uuid.New(), IsGuest: true, GuestName: "Aigerim", GuestPhone: "+77011234567", Restaurant: &domain.Restaurant{ID: uuid.New()}}
+			h := NewBookingHandler(&stubBookingRepository{booking: booking}, nil, nil, nil, nil, &stubBookingWriter{err: tc.err}, nil)
+
+			w := performAsUser(h.GetBooking, http.MethodGet, "/api/bookings/:id", "/api/bookings/"+booking.ID.String(), tc.userID, "")
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.visible, strings.Contains(w.Body.String(), `"guest_phone":"+77011234567"`))
+			assert.Equal(t, tc.visible, strings.Contains(w.Body.String(), `"guest_name":"Aigerim"`))
+			assert.Contains(t, w.Body.String(), `"is_guest":true`)
+		})
+	}
+}
+
+func TestCreateGuestBooking(t *testing.T) {
+	writer := &stubBookingWriter{}
+	staffID, restaurantID, tableID := uuid.New(), uuid.New(), uuid.New()
+
+	w := createGuestBooking(writer, &staffID, restaurantID, guestBookingBody(tableID))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, writer.guest)
+	assert.Equal(t, staffID, writer.guest.StaffID)
+	assert.Equal(t, restaurantID, writer.guest.RestaurantID)
+	assert.Equal(t, tableID, writer.guest.TableID)
+	assert.Equal(t, "Aigerim", writer.guest.GuestName)
+	assert.Contains(t, w.Body.String(), `"is_guest":true`)
+	assert.Contains(t, w.Body.String(), `"user_id":null`)
+}
+
+func TestCreateGuestBooking_Errors(t *testing.T) {
+	staffID := uuid.New()
+	cases := map[string]struct {
+		err  error
+		body string
+		want int
+	}{
+		"no guest name":   {nil, `{"table_id":"` + uuid.NewString() + `","guest_phone":"+77011234567","start_time":"2024-06-04T19:00:00+05:00","end_time":"2024-06-04T21:00:00+05:00","guests_count":2}`, http.StatusBadRequest},
+		"not staff":       {service.ErrNotRestaurantStaff, "", http.StatusForbidden},
+		"bad phone":       {service.ErrInvalidPhone, "", http.StatusBadRequest},
+		"table taken":     {service.ErrTableNotAvailable, "", http.StatusConflict},
+		"restaurant full": {service.ErrGuestLimitReached, "", http.StatusConflict},
+		"no such place":   {service.ErrRestaurantNotFound, "", http.StatusNotFound},
+		"too many guests": {service.ErrGuestsExceedCapacity, "", http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			body := tc.body
+			if body == "" {
+				body = guestBookingBody(uuid.New())
+			}
+
+			w := createGuestBooking(&stubBookingWriter{err: tc.err}, &staffID, uuid.New(), body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
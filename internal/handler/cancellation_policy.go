@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CancellationPolicyResponse is a policy with its version and the summary
+// customers are shown before they book or pay.
+type CancellationPolicyResponse struct {
+	domain.CancellationPolicy
+	PolicyVersion string `json:"policy_version"`
+	Summary       string `json:"summary"`
+}
+
+func cancellationPolicyResponse(c *gin.Context, policy domain.CancellationPolicy) *CancellationPolicyResponse {
+	return &CancellationPolicyResponse{
+		CancellationPolicy: policy,
+		PolicyVersion:      policy.Version(),
+		Summary:            service.PolicySummary(policy, requestLanguages(c)),
+	}
+}
+
+// requestLanguages lists the primary language tags of Accept-Language in the
+// order the client sent them, e.g. ["ru", "en"] for "ru-RU,en;q=0.8".
+func requestLanguages(c *gin.Context) []string {
+	var languages []string
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(tag, "-")
+		if primary = strings.ToLower(strings.TrimSpace(primary)); primary != "" && primary != "*" {
+			languages = append(languages, primary)
+		}
+	}
+	return languages
+}
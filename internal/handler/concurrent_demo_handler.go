@@ -2,11 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 )
 
@@ -65,56 +70,36 @@ func (h *ConcurrentDemoHandler) SendBulkNotifications(c *gin.Context) {
 }
 
 // @Summary Get notification statistics
-// @Description Get statistics of sent and failed notifications
+// @Description Get statistics of sent and failed notifications, the current worker pool size and how far behind the queue is
 // @Tags Demo - Concurrent Features
 // @Produce json
 // @Success 200 {object} NotificationStatsResponse
 // @Router /api/demo/notification-stats [get]
 func (h *ConcurrentDemoHandler) GetNotificationStats(c *gin.Context) {
 	sent, failed := h.notificationSvc.GetStats()
+	queue := h.notificationSvc.QueueStats()
 
 	c.JSON(http.StatusOK, NotificationStatsResponse{
-		Sent:   sent,
-		Failed: failed,
-	})
-}
-
-// @Summary Check multiple tables availability
-// @Description Check availability of multiple tables concurrently
-// @Tags Demo - Concurrent Features
-// @Accept json
-// @Produce json
-// @Param request body CheckAvailabilityRequest true "Check availability request"
-// @Success 200 {object} ConcurrentAvailabilityResponse
-// @Failure 400 {object} ErrorResponse
-// @Router /api/demo/check-availability [post]
-func (h *ConcurrentDemoHandler) CheckTablesAvailability(c *gin.Context) {
-	var req CheckAvailabilityRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	ctx := c.Request.Context()
-	results := h.bookingSvc.CheckMultipleTablesAvailability(
-		ctx,
-		req.TableIDs,
-		req.StartTime,
-		req.EndTime,
-	)
-
-	c.JSON(http.StatusOK, ConcurrentAvailabilityResponse{
-		Availability: results,
+		Sent:              sent,
+		Failed:            failed,
+		Workers:           h.notificationSvc.WorkerCount(),
+		QueueDepth:        queue.Depth,
+		OldestAgeSeconds:  queue.OldestAge.Seconds(),
+		P95LatencySeconds: queue.P95Latency.Seconds(),
+		LatencySamples:    queue.Processed,
 	})
 }
 
 // @Summary Get booking statistics
-// @Description Get booking statistics for a restaurant (calculated in parallel)
+// @Description Counts a restaurant's bookings in total, active (pending or confirmed), completed and cancelled, calculated in parallel. With from and to only bookings that start in [from, to) are counted, and daily breaks the counts down by day on the restaurant's clock.
 // @Tags Demo - Concurrent Features
 // @Produce json
 // @Param restaurant_id path string true "Restaurant ID"
+// @Param from query string false "Start of the range, RFC3339 (inclusive); requires to"
+// @Param to query string false "End of the range, RFC3339 (exclusive); requires from"
 // @Success 200 {object} BookingStatsResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/demo/booking-stats/{restaurant_id} [get]
 func (h *ConcurrentDemoHandler) GetBookingStats(c *gin.Context) {
@@ -125,21 +110,49 @@ func (h *ConcurrentDemoHandler) GetBookingStats(c *gin.Context) {
 		return
 	}
 
+	var period *service.BookingPeriod
+	if (c.Query("from") == "") != (c.Query("to") == "") {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from and to must be given together"})
+		return
+	}
+	if c.Query("from") != "" {
+		period = &service.BookingPeriod{}
+		for _, bound := range []struct {
+			name   string
+			target *time.Time
+		}{{"from", &period.From}, {"to", &period.To}} {
+			parsed, err := apitime.Parse(c.Query(bound.name))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+				return
+			}
+			*bound.target = parsed.Time
+		}
+	}
+
 	ctx := c.Request.Context()
-	stats, err := h.bookingSvc.GetBookingStatistics(ctx, restaurantID)
+	stats, err := h.bookingSvc.GetBookingStatistics(ctx, restaurantID, period)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		switch {
+		case errors.Is(err, service.ErrInvalidStatsRange):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, BookingStatsResponse{
 		RestaurantID: restaurantID,
-		Stats:        stats,
+		Stats:        stats.Counts,
+		Daily:        stats.Daily,
 	})
 }
 
 // @Summary Search available tables across restaurants
-// @Description Search for available tables across multiple restaurants in parallel
+// @Description Searches the restaurants in parallel for active tables that seat guest_count and are free from start_time to end_time. The search stops after 5 seconds; restaurants not done by then are marked timed_out, with the tables found so far.
 // @Tags Demo - Concurrent Features
 // @Accept json
 // @Produce json
@@ -149,8 +162,8 @@ func (h *ConcurrentDemoHandler) GetBookingStats(c *gin.Context) {
 // @Router /api/demo/search-tables [post]
 func (h *ConcurrentDemoHandler) SearchAvailableTables(c *gin.Context) {
 	var req SearchTablesRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
 		return
 	}
 
@@ -160,14 +173,30 @@ func (h *ConcurrentDemoHandler) SearchAvailableTables(c *gin.Context) {
 	results := h.bookingSvc.SearchAvailableTablesParallel(
 		ctx,
 		req.RestaurantIDs,
-		req.StartTime,
-		req.EndTime,
+		req.StartTime.Time,
+		req.EndTime.Time,
 		req.GuestCount,
 	)
 
-	c.JSON(http.StatusOK, SearchTablesResponse{
-		Results: results,
-	})
+	response := SearchTablesResponse{Results: make(map[uuid.UUID]SearchTablesResult, len(results))}
+	for restaurantID, search := range results {
+		result := SearchTablesResult{Tables: make([]AvailableTableResponse, 0, len(search.Tables)), TimedOut: search.TimedOut}
+		for _, table := range search.Tables {
+			result.Tables = append(result.Tables, AvailableTableResponse{
+				ID:           table.ID,
+				TableNumber:  table.TableNumber,
+				MinCapacity:  table.MinCapacity,
+				MaxCapacity:  table.MaxCapacity,
+				LocationType: table.LocationType,
+			})
+		}
+		if search.Err != nil {
+			result.Error = search.Err.Error()
+		}
+		response.Results[restaurantID] = result
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 type BulkNotificationRequest struct {
@@ -177,32 +206,50 @@ type BulkNotificationRequest struct {
 }
 
 type NotificationStatsResponse struct {
-	Sent   int `json:"sent"`
-	Failed int `json:"failed"`
-}
-
-type CheckAvailabilityRequest struct {
-	TableIDs  []uuid.UUID `json:"table_ids" binding:"required"`
-	StartTime time.Time   `json:"start_time" binding:"required"`
-	EndTime   time.Time   `json:"end_time" binding:"required"`
-}
-
-type ConcurrentAvailabilityResponse struct {
-	Availability map[uuid.UUID]bool `json:"availability"`
+	Sent       int `json:"sent"`
+	Failed     int `json:"failed"`
+	Workers    int `json:"workers"`
+	QueueDepth int `json:"queue_depth"`
+	// OldestAgeSeconds is how long the oldest queued or in-flight
+	// notification has waited.
+	OldestAgeSeconds float64 `json:"oldest_age_seconds"`
+	// P95LatencySeconds covers notifications processed in the last 5
+	// minutes; LatencySamples is how many there were.
+	P95LatencySeconds float64 `json:"p95_latency_seconds"`
+	LatencySamples    int     `json:"latency_samples"`
 }
 
 type BookingStatsResponse struct {
 	RestaurantID uuid.UUID      `json:"restaurant_id"`
 	Stats        map[string]int `json:"stats"`
+	// Daily is keyed by day, "2006-01-02", and only set for a range.
+	Daily map[string]map[string]int `json:"daily,omitempty"`
 }
 
 type SearchTablesRequest struct {
-	RestaurantIDs []uuid.UUID `json:"restaurant_ids" binding:"required"`
-	StartTime     time.Time   `json:"start_time" binding:"required"`
-	EndTime       time.Time   `json:"end_time" binding:"required"`
-	GuestCount    int         `json:"guest_count" binding:"required"`
+	RestaurantIDs []uuid.UUID  `json:"restaurant_ids" binding:"required"`
+	StartTime     apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime       apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	GuestCount    int          `json:"guest_count" binding:"required"`
 }
 
 type SearchTablesResponse struct {
-	Results map[uuid.UUID][]uuid.UUID `json:"results"`
+	Results map[uuid.UUID]SearchTablesResult `json:"results"`
+}
+
+// SearchTablesResult lists a restaurant's free tables. TimedOut marks a
+// search cut short by the deadline, whose tables may be incomplete; Error
+// is set when the search failed.
+type SearchTablesResult struct {
+	Tables   []AvailableTableResponse `json:"tables"`
+	TimedOut bool                     `json:"timed_out"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+type AvailableTableResponse struct {
+	ID           uuid.UUID           `json:"id"`
+	TableNumber  string              `json:"table_number"`
+	MinCapacity  int                 `json:"min_capacity"`
+	MaxCapacity  int                 `json:"max_capacity"`
+	LocationType domain.LocationType `json:"location_type"`
 }
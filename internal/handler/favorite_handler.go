@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxFavoritesPageSize = 100
+
+type FavoriteHandler struct {
+	favoriteService service.FavoriteService
+}
+
+func NewFavoriteHandler(favoriteService service.FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{favoriteService: favoriteService}
+}
+
+// AddFavorite bookmarks the restaurant for the authenticated user. Repeating
+// it is harmless.
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.AddFavorite(c.Request.Context(), userID, restaurantID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "restaurant added to favorites"})
+}
+
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.RemoveFavorite(c.Request.Context(), userID, restaurantID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "restaurant removed from favorites"})
+}
+
+func (h *FavoriteHandler) ListMyFavorites(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+	if limit <= 0 || limit > maxFavoritesPageSize {
+		limit = maxFavoritesPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	favorites, err := h.favoriteService.ListFavorites(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, favorites)
+}
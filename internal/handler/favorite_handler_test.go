@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFavoriteService struct {
+	service.FavoriteService
+	favorites map[uuid.UUID]bool
+	err       error
+}
+
+func (s *stubFavoriteService) AddFavorite(ctx context.Context, userID, restaurantID uuid.UUID) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.favorites[restaurantID] = true
+	return nil
+}
+
+func (s *stubFavoriteService) IsFavorite(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error) {
+	return s.favorites[restaurantID], nil
+}
+
+func TestAddFavorite(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	favorites := &stubFavoriteService{favorites: map[uuid.UUID]bool{}}
+	h := NewFavoriteHandler(favorites)
+
+	for i := 0; i < 2; i++ {
+		w := performAsUser(h.AddFavorite, http.MethodPost, "/api/restaurants/:id/favorite",
+			"/api/restaurants/"+restaurantID.String()+"/favorite", &userID, "")
+		assert.Equal(t, http.StatusOK, w.Code, "favoriting twice is not an error")
+	}
+	assert.True(t, favorites.favorites[restaurantID])
+
+	w := performAsUser(NewFavoriteHandler(&stubFavoriteService{err: service.ErrRestaurantNotFound}).AddFavorite, http.MethodPost,
+		"/api/restaurants/:id/favorite", "/api/restaurants/"+uuid.NewString()+"/favorite", &userID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performAsUser(h.AddFavorite, http.MethodPost, "/api/restaurants/:id/favorite",
+		"/api/restaurants/"+restaurantID.String()+"/favorite", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetRestaurant_IsFavorite(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	favorites := &stubFavoriteService{favorites: map[uuid.UUID]bool{restaurant.ID: true}}
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, favorites, nil)
+	userID := uuid.New()
+	target := "/api/restaurants/" + restaurant.ID.String()
+
+	w := performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id", target, &userID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RestaurantDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.IsFavorite)
+	assert.True(t, *resp.IsFavorite)
+
+	w = getRestaurantDetails(h, restaurant.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "is_favorite", "anonymous requests get no is_favorite")
+
+	favorites.favorites = map[uuid.UUID]bool{}
+	w = performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id", target, &userID, "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.IsFavorite)
+	assert.False(t, *resp.IsFavorite)
+}
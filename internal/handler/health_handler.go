@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+const healthPingTimeout = 2 * time.Second
+
+// NotificationQueue is the part of the notification service the health
+// check looks at.
+type NotificationQueue interface {
+	QueueStats() service.NotificationQueueStats
+}
+
+type HealthHandler struct {
+	db            *sql.DB
+	notifications NotificationQueue
+	maxQueueAge   time.Duration
+}
+
+func NewHealthHandler(db *sql.DB, notifications NotificationQueue, maxQueueAge time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, notifications: notifications, maxQueueAge: maxQueueAge}
+}
+
+// @Summary Health check
+// @Description Reports that the server is up. With deep=true it also pings the database and checks the notification queue: the status is degraded when the oldest pending notification has waited longer than NOTIFICATION_MAX_QUEUE_AGE, and down, with 503, when the database does not answer.
+// @Tags Health
+// @Produce json
+// @Param deep query bool false "Check dependencies"
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /health [get]
+func (h *HealthHandler) Health(c *gin.Context) {
+	if c.Query("deep") != "true" {
+		c.JSON(http.StatusOK, HealthResponse{Status: HealthOK, Message: "Server is running"})
+		return
+	}
+
+	resp := HealthResponse{
+		Status:  HealthOK,
+		Message: "Server is running",
+		Checks: map[string]HealthCheck{
+			"database":           h.checkDatabase(c.Request.Context()),
+			"notification_queue": h.checkNotificationQueue(),
+		},
+	}
+
+	for _, check := range resp.Checks {
+		switch {
+		case check.Status == HealthDown:
+			resp.Status = HealthDown
+		case check.Status == HealthDegraded && resp.Status == HealthOK:
+			resp.Status = HealthDegraded
+		}
+	}
+
+	status := http.StatusOK
+	if resp.Status == HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, resp)
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		return HealthCheck{Status: HealthDown, Detail: err.Error()}
+	}
+	return HealthCheck{Status: HealthOK}
+}
+
+func (h *HealthHandler) checkNotificationQueue() HealthCheck {
+	stats := h.notifications.QueueStats()
+	detail := fmt.Sprintf("depth %d, oldest pending %s, p95 latency %s over %d notifications",
+		stats.Depth, stats.OldestAge.Round(time.Millisecond), stats.P95Latency.Round(time.Millisecond), stats.Processed)
+
+	if stats.OldestAge > h.maxQueueAge {
+		return HealthCheck{Status: HealthDegraded, Detail: detail + fmt.Sprintf(", above the %s threshold", h.maxQueueAge)}
+	}
+	return HealthCheck{Status: HealthOK, Detail: detail}
+}
+
+type HealthResponse struct {
+	Status  string                 `json:"status" enums:"ok,degraded,down"`
+	Message string                 `json:"message"`
+	Checks  map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Status string `json:"status" enums:"ok,degraded,down"`
+	Detail string `json:"detail,omitempty"`
+}
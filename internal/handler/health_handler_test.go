@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubNotificationQueue struct {
+	stats service.NotificationQueueStats
+}
+
+func (s *stubNotificationQueue) QueueStats() service.NotificationQueueStats {
+	return s.stats
+}
+
+func getHealth(t *testing.T, h *HealthHandler, target string) (*httptest.ResponseRecorder, HealthResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", h.Health)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w, resp
+}
+
+func TestHealth_DeepCheck(t *testing.T) {
+	cases := []struct {
+		name       string
+		oldestAge  time.Duration
+		pingErr    error
+		wantCode   int
+		wantStatus string
+		wantQueue  string
+	}{
+		{"healthy", 10 * time.Second, nil, http.StatusOK, HealthOK, HealthOK},
+		{"old notification", 2 * time.Minute, nil, http.StatusOK, HealthDegraded, HealthDegraded},
+		{"database down", 2 * time.Minute, errors.New("connection refused"), http.StatusServiceUnavailable, HealthDown, HealthDegraded},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer db.Close()
+			dbMock.ExpectPing().WillReturnError(tc.pingErr)
+
+			queue := &stubNotificationQueue{stats: service.NotificationQueueStats{Depth: 3, OldestAge: tc.oldestAge}}
+			w, resp := getHealth(t, NewHealthHandler(db, queue, time.Minute), "/health?deep=true")
+
+			assert.Equal(t, tc.wantCode, w.Code)
+			assert.Equal(t, tc.wantStatus, resp.Status)
+			assert.Equal(t, tc.wantQueue, resp.Checks["notification_queue"].Status)
+		})
+	}
+}
+
+func TestHealth_ShallowSkipsDependencies(t *testing.T) {
+	w, resp := getHealth(t, NewHealthHandler(nil, nil, time.Minute), "/health")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, HealthOK, resp.Status)
+	assert.Empty(t, resp.Checks)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobHandler struct {
+	jobService service.JobService
+}
+
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// @Summary Recompute all restaurant ratings
+// @Description Starts a background job that recomputes every restaurant's rating and review count from its visible reviews. Poll GET /api/admin/jobs/{id} for progress.
+// @Tags Admin
+// @Produce json
+// @Success 202 {object} domain.BackgroundJob
+// @Failure 409 {object} ErrorResponse
+// @Router /api/admin/maintenance/recompute-ratings [post]
+func (h *JobHandler) RecomputeRatings(c *gin.Context) {
+	h.start(c, service.JobKindRecomputeRatings)
+}
+
+// @Summary Give restaurants without a slug one
+// @Description Starts a background job that makes a slug from the name of every restaurant created before slugs existed. Poll GET /api/admin/jobs/{id} for progress.
+// @Tags Admin
+// @Produce json
+// @Success 202 {object} domain.BackgroundJob
+// @Failure 409 {object} ErrorResponse
+// @Router /api/admin/maintenance/assign-slugs [post]
+func (h *JobHandler) AssignRestaurantSlugs(c *gin.Context) {
+	h.start(c, service.JobKindAssignSlugs)
+}
+
+// @Summary Get a background job
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.BackgroundJob
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid job id"})
+		return
+	}
+
+	job, err := h.jobService.Get(c.Request.Context(), id)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *JobHandler) start(c *gin.Context, kind string) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.Start(c.Request.Context(), kind, adminID)
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func writeJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrJobAlreadyRunning):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
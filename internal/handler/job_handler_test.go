@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubJobService struct {
+	service.JobService
+	started string
+	err     error
+}
+
+func (s *stubJobService) Start(ctx context.Context, kind string, startedBy uuid.UUID) (*domain.BackgroundJob, error) {
+	s.started = kind
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.BackgroundJob{ID: uuid.New(), Kind: kind, Status: domain.BackgroundJobRunning, Total: 340, StartedBy: startedBy}, nil
+}
+
+func (s *stubJobService) Get(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.BackgroundJob{ID: id, Status: domain.BackgroundJobRunning, Processed: 100, Total: 340}, nil
+}
+
+func TestRecomputeRatings(t *testing.T) {
+	adminID := uuid.New()
+	stub := &stubJobService{}
+
+	w := performAsUser(NewJobHandler(stub).RecomputeRatings, http.MethodPost, "/api/admin/maintenance/recompute-ratings",
+		"/api/admin/maintenance/recompute-ratings", &adminID, "")
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, service.JobKindRecomputeRatings, stub.started)
+	assert.Contains(t, w.Body.String(), `"total":340`)
+}
+
+func TestAssignRestaurantSlugs(t *testing.T) {
+	adminID := uuid.New()
+	stub := &stubJobService{}
+
+	w := performAsUser(NewJobHandler(stub).AssignRestaurantSlugs, http.MethodPost, "/api/admin/maintenance/assign-slugs",
+		"/api/admin/maintenance/assign-slugs", &adminID, "")
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, service.JobKindAssignSlugs, stub.started)
+}
+
+func TestRecomputeRatings_AlreadyRunning(t *testing.T) {
+	adminID := uuid.New()
+
+	w := performAsUser(NewJobHandler(&stubJobService{err: service.ErrJobAlreadyRunning}).RecomputeRatings, http.MethodPost,
+		"/api/admin/maintenance/recompute-ratings", "/api/admin/maintenance/recompute-ratings", &adminID, "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestGetJob(t *testing.T) {
+	adminID := uuid.New()
+	target := "/api/admin/jobs/" + uuid.NewString()
+
+	w := performAsUser(NewJobHandler(&stubJobService{}).GetJob, http.MethodGet, "/api/admin/jobs/:id", target, &adminID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"processed":100`)
+
+	w = performAsUser(NewJobHandler(&stubJobService{err: service.ErrJobNotFound}).GetJob, http.MethodGet, "/api/admin/jobs/:id", target, &adminID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -26,15 +27,8 @@ func (h *ManagerHandler) AddManager(c *gin.Context) {
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
-		return
-	}
-
-	ownerID, err := uuid.Parse(ownerIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -84,15 +78,8 @@ func (h *ManagerHandler) RemoveManager(c *gin.Context) {
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
-		return
-	}
-
-	ownerID, err := uuid.Parse(ownerIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -123,6 +110,24 @@ func (h *ManagerHandler) GetManagers(c *gin.Context) {
 		return
 	}
 
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.managerService.ListPendingInvitations(c.Request.Context(), restaurantID, ownerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
 	managers, err := h.managerService.GetManagers(c.Request.Context(), restaurantID)
 	if err != nil {
 		switch {
@@ -134,9 +139,150 @@ func (h *ManagerHandler) GetManagers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, managers)
+	c.JSON(http.StatusOK, ManagersResponse{Managers: managers, PendingInvitations: invitations})
+}
+
+func (h *ManagerHandler) InviteManager(c *gin.Context) {
+
+	restaurantIDStr := c.Param("id")
+	restaurantID, err := uuid.Parse(restaurantIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req InviteManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invitation, err := h.managerService.InviteManager(c.Request.Context(), restaurantID, ownerID, service.InviteManagerRequest{
+		Email: req.Email,
+		Role:  req.Role,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEmail):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid email format"})
+		case errors.Is(err, service.ErrInvalidInvitationRole):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		case errors.Is(err, service.ErrManagerAlreadyExists):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "user is already a manager"})
+		case errors.Is(err, service.ErrInvitationAlreadyPending):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *ManagerHandler) AcceptInvitation(c *gin.Context) {
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	manager, err := h.managerService.AcceptInvitation(c.Request.Context(), c.Param("token"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvitationEmailMismatch):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrManagerAlreadyExists):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "user is already a manager"})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			writeInvitationError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, manager)
+}
+
+func (h *ManagerHandler) DeclineInvitation(c *gin.Context) {
+
+	if err := h.managerService.DeclineInvitation(c.Request.Context(), c.Param("token")); err != nil {
+		writeInvitationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeInvitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "invitation not found"})
+	case errors.Is(err, service.ErrInvitationExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvitationAnswered):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
 }
 
 type AddManagerRequest struct {
 	UserID uuid.UUID `json:"user_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
 }
+
+type InviteManagerRequest struct {
+	Email string `json:"email" binding:"required" example:"manager@example.com"`
+	// Role defaults to manager, the only role an invitation can grant.
+	Role domain.UserRole `json:"role" example:"manager"`
+}
+
+// ManagedRestaurantResponse is a restaurant with the role the user holds
+// there.
+type ManagedRestaurantResponse struct {
+	*domain.Restaurant
+	Role domain.UserRole `json:"role" enums:"owner,manager"`
+}
+
+// @Summary List restaurants I can manage
+// @Description Lists the restaurants the authenticated user owns or manages, each once, marked with the user's role there. Owned restaurants come first.
+// @Tags Restaurants
+// @Produce json
+// @Success 200 {array} ManagedRestaurantResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/users/me/managed-restaurants [get]
+func (h *ManagerHandler) ListManagedRestaurants(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	managed, err := h.managerService.ListManagedRestaurants(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := make([]ManagedRestaurantResponse, len(managed))
+	for i, m := range managed {
+		response[i] = ManagedRestaurantResponse{Restaurant: m.Restaurant, Role: m.Role}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type ManagersResponse struct {
+	Managers           []*domain.RestaurantManager `json:"managers"`
+	PendingInvitations []*domain.ManagerInvitation `json:"pending_invitations"`
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubManagerService struct {
+	service.ManagerService
+	ownerID   uuid.UUID
+	inviteErr error
+	acceptErr error
+}
+
+func (s *stubManagerService) AddManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req service.AddManagerRequest) (*domain.RestaurantManager, error) {
+	s.ownerID = ownerID
+	return &domain.RestaurantManager{RestaurantID: restaurantID, UserID: req.UserID}, nil
+}
+
+func (s *stubManagerService) RemoveManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, userID uuid.UUID) error {
+	s.ownerID = ownerID
+	return nil
+}
+
+func (s *stubManagerService) GetManagers(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantManager, error) {
+	return []*domain.RestaurantManager{{RestaurantID: restaurantID, UserID: uuid.New()}}, nil
+}
+
+func (s *stubManagerService) ListPendingInvitations(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID) ([]*domain.ManagerInvitation, error) {
+	s.ownerID = ownerID
+	return []*domain.ManagerInvitation{{RestaurantID: restaurantID, Email: "invitee@test.com"}}, nil
+}
+
+func (s *stubManagerService) InviteManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req service.InviteManagerRequest) (*domain.ManagerInvitation, error) {
+	s.ownerID = ownerID
+	if s.inviteErr != nil {
+		return nil, s.inviteErr
+	}
+	return &domain.ManagerInvitation{RestaurantID: restaurantID, Email: req.Email, Token: "secret"}, nil
+}
+
+func (s *stubManagerService) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID) (*domain.RestaurantManager, error) {
+	if s.acceptErr != nil {
+		return nil, s.acceptErr
+	}
+	return &domain.RestaurantManager{UserID: userID}, nil
+}
+
+func (s *stubManagerService) ListManagedRestaurants(ctx context.Context, userID uuid.UUID) ([]*service.ManagedRestaurant, error) {
+	return []*service.ManagedRestaurant{
+		{Restaurant: &domain.Restaurant{ID: uuid.New(), OwnerID: userID, Name: "Owned"}, Role: domain.UserRoleOwner},
+		{Restaurant: &domain.Restaurant{ID: uuid.New(), Name: "Managed"}, Role: domain.UserRoleManager},
+	}, nil
+}
+
+func TestAddManager_NoToken(t *testing.T) {
+	h := NewManagerHandler(&stubManagerService{})
+
+	w := performAsUser(h.AddManager, http.MethodPost, "/api/restaurants/:id/managers",
+		"/api/restaurants/"+uuid.NewString()+"/managers", nil, `{"user_id":"`+uuid.NewString()+`"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRemoveManager_NoToken(t *testing.T) {
+	h := NewManagerHandler(&stubManagerService{})
+
+	w := performAsUser(h.RemoveManager, http.MethodDelete, "/api/restaurants/:id/managers/:user_id",
+		"/api/restaurants/"+uuid.NewString()+"/managers/"+uuid.NewString(), nil, "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAddManager_UsesAuthenticatedOwner(t *testing.T) {
+	svc := &stubManagerService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewManagerHandler(svc).AddManager, http.MethodPost, "/api/restaurants/:id/managers",
+		"/api/restaurants/"+uuid.NewString()+"/managers?owner_id="+uuid.NewString(), &userID, `{"user_id":"`+uuid.NewString()+`"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+}
+
+func TestGetManagers_IncludesPendingInvitations(t *testing.T) {
+	svc := &stubManagerService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewManagerHandler(svc).GetManagers, http.MethodGet, "/api/restaurants/:id/managers",
+		"/api/restaurants/"+uuid.NewString()+"/managers", &userID, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+	var resp ManagersResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Managers, 1)
+	assert.Len(t, resp.PendingInvitations, 1)
+}
+
+func TestInviteManager_HidesToken(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewManagerHandler(&stubManagerService{}).InviteManager, http.MethodPost, "/api/restaurants/:id/managers/invite",
+		"/api/restaurants/"+uuid.NewString()+"/managers/invite", &userID, `{"email":"invitee@test.com","role":"manager"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
+
+func TestInviteManager_Errors(t *testing.T) {
+	userID := uuid.New()
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrInvitationAlreadyPending, http.StatusConflict},
+		{service.ErrInvalidEmail, http.StatusBadRequest},
+		{service.ErrUnauthorized, http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		w := performAsUser(NewManagerHandler(&stubManagerService{inviteErr: tc.err}).InviteManager, http.MethodPost, "/api/restaurants/:id/managers/invite",
+			"/api/restaurants/"+uuid.NewString()+"/managers/invite", &userID, `{"email":"invitee@test.com"}`)
+
+		assert.Equal(t, tc.want, w.Code, tc.err.Error())
+	}
+}
+
+func TestAcceptInvitation_Errors(t *testing.T) {
+	userID := uuid.New()
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusCreated},
+		{service.ErrInvitationNotFound, http.StatusNotFound},
+		{service.ErrInvitationExpired, http.StatusGone},
+		{service.ErrInvitationEmailMismatch, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		w := performAsUser(NewManagerHandler(&stubManagerService{acceptErr: tc.err}).AcceptInvitation, http.MethodPost, "/api/manager-invitations/:token/accept",
+			"/api/manager-invitations/token/accept", &userID, "")
+
+		assert.Equal(t, tc.want, w.Code)
+	}
+}
+
+func TestListManagedRestaurants_MarksRole(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewManagerHandler(&stubManagerService{}).ListManagedRestaurants, http.MethodGet, "/api/users/me/managed-restaurants",
+		"/api/users/me/managed-restaurants", &userID, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp, 2)
+	assert.Equal(t, "Owned", resp[0]["name"])
+	assert.Equal(t, "owner", resp[0]["role"])
+	assert.Equal(t, "Managed", resp[1]["name"])
+	assert.Equal(t, "manager", resp[1]["role"])
+}
+
+func TestListManagedRestaurants_NoToken(t *testing.T) {
+	w := performAsUser(NewManagerHandler(&stubManagerService{}).ListManagedRestaurants, http.MethodGet, "/api/users/me/managed-restaurants",
+		"/api/users/me/managed-restaurants", nil, "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
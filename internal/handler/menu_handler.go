@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MenuHandler struct {
+	menuService service.MenuService
+}
+
+func NewMenuHandler(menuService service.MenuService) *MenuHandler {
+	return &MenuHandler{menuService: menuService}
+}
+
+// @Summary Get a restaurant's menu
+// @Description Returns the menu categories with their items, both in menu order. Unavailable items are included with is_available false.
+// @Tags Restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} domain.MenuCategory
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/menu [get]
+func (h *MenuHandler) GetMenu(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	menu, err := h.menuService.GetMenu(c.Request.Context(), restaurantID)
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, menu)
+}
+
+func (h *MenuHandler) CreateCategory(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateMenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	category, err := h.menuService.CreateCategory(c.Request.Context(), restaurantID, staffID, service.CreateMenuCategoryRequest{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *MenuHandler) UpdateCategory(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	category, err := h.menuService.UpdateCategory(c.Request.Context(), restaurantID, categoryID, staffID, service.UpdateMenuCategoryRequest{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *MenuHandler) DeleteCategory(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.menuService.DeleteCategory(c.Request.Context(), restaurantID, categoryID, staffID); err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MenuHandler) ReorderCategories(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderMenuCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	categories, err := h.menuService.ReorderCategories(c.Request.Context(), restaurantID, staffID, req.CategoryIDs)
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *MenuHandler) CreateItem(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateMenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item, err := h.menuService.CreateItem(c.Request.Context(), restaurantID, categoryID, staffID, service.CreateMenuItemRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       *req.Price,
+		PhotoURL:    req.PhotoURL,
+		IsAvailable: req.IsAvailable,
+	})
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *MenuHandler) UpdateItem(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid item id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item, err := h.menuService.UpdateItem(c.Request.Context(), restaurantID, itemID, staffID, service.UpdateMenuItemRequest{
+		CategoryID:  req.CategoryID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		PhotoURL:    req.PhotoURL,
+		IsAvailable: req.IsAvailable,
+	})
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *MenuHandler) DeleteItem(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid item id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.menuService.DeleteItem(c.Request.Context(), restaurantID, itemID, staffID); err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MenuHandler) ReorderItems(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid category id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderMenuItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	items, err := h.menuService.ReorderItems(c.Request.Context(), restaurantID, categoryID, staffID, req.ItemIDs)
+	if err != nil {
+		writeMenuError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func writeMenuError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrMenuCategoryNotFound), errors.Is(err, service.ErrMenuItemNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrNotRestaurantStaff):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+	case errors.Is(err, service.ErrInvalidMenuName), errors.Is(err, service.ErrInvalidMenuPrice),
+		errors.Is(err, service.ErrInvalidPhotoURL), errors.Is(err, service.ErrInvalidMenuOrder):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrMenuCategoryNotEmpty):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type CreateMenuCategoryRequest struct {
+	Name        string  `json:"name" binding:"required,max=100" example:"Starters"`
+	Description *string `json:"description"`
+}
+
+type UpdateMenuCategoryRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+}
+
+// ReorderMenuCategoriesRequest lists every category of the menu in order.
+type ReorderMenuCategoriesRequest struct {
+	CategoryIDs []uuid.UUID `json:"category_ids" binding:"required,min=1"`
+}
+
+type CreateMenuItemRequest struct {
+	Name        string  `json:"name" binding:"required,max=150" example:"Beshbarmak"`
+	Description *string `json:"description"`
+	// Price is required but may be 0.
+	Price       *int    `json:"price" binding:"required,min=0" example:"4500"`
+	PhotoURL    *string `json:"photo_url"`
+	IsAvailable *bool   `json:"is_available"`
+}
+
+type UpdateMenuItemRequest struct {
+	CategoryID  *uuid.UUID `json:"category_id"`
+	Name        *string    `json:"name" binding:"omitempty,max=150"`
+	Description *string    `json:"description"`
+	Price       *int       `json:"price" binding:"omitempty,min=0"`
+	PhotoURL    *string    `json:"photo_url"`
+	IsAvailable *bool      `json:"is_available"`
+}
+
+// ReorderMenuItemsRequest lists every item of the category in order.
+type ReorderMenuItemsRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" binding:"required,min=1"`
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMenuService struct {
+	service.MenuService
+	createdItem *service.CreateMenuItemRequest
+	err         error
+}
+
+func (s *stubMenuService) GetMenu(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*domain.MenuCategory{{ID: uuid.New(), RestaurantID: restaurantID, Name: "Starters", Items: []*domain.MenuItem{}}}, nil
+}
+
+func (s *stubMenuService) CreateItem(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, req service.CreateMenuItemRequest) (*domain.MenuItem, error) {
+	s.createdItem = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.MenuItem{ID: uuid.New(), CategoryID: categoryID, Name: req.Name, Price: req.Price}, nil
+}
+
+func (s *stubMenuService) DeleteCategory(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID) error {
+	return s.err
+}
+
+func TestGetMenu(t *testing.T) {
+	w := performAsUser(NewMenuHandler(&stubMenuService{}).GetMenu, http.MethodGet, "/api/restaurants/:id/menu",
+		"/api/restaurants/"+uuid.NewString()+"/menu", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Starters"`)
+	assert.Contains(t, w.Body.String(), `"items":[]`)
+}
+
+func TestGetMenu_RestaurantNotFound(t *testing.T) {
+	w := performAsUser(NewMenuHandler(&stubMenuService{err: service.ErrRestaurantNotFound}).GetMenu, http.MethodGet, "/api/restaurants/:id/menu",
+		"/api/restaurants/"+uuid.NewString()+"/menu", nil, "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateMenuItem_FreeItem(t *testing.T) {
+	staffID := uuid.New()
+	stub := &stubMenuService{}
+
+	w := performAsUser(NewMenuHandler(stub).CreateItem, http.MethodPost, "/api/restaurants/:id/menu/categories/:category_id/items",
+		"/api/restaurants/"+uuid.NewString()+"/menu/categories/"+uuid.NewString()+"/items", &staffID, `{"name":"Bread","price":0}`)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, stub.createdItem)
+	assert.Equal(t, 0, stub.createdItem.Price)
+	assert.Nil(t, stub.createdItem.IsAvailable)
+}
+
+func TestCreateMenuItem_BadRequest(t *testing.T) {
+	staffID := uuid.New()
+	target := "/api/restaurants/" + uuid.NewString() + "/menu/categories/" + uuid.NewString() + "/items"
+
+	cases := map[string]struct {
+		body string
+		err  error
+	}{
+		"no price":       {`{"name":"Bread"}`, nil},
+		"negative price": {`{"name":"Bread","price":-100}`, nil},
+		"no name":        {`{"price":100}`, nil},
+		"bad photo":      {`{"name":"Bread","price":100,"photo_url":"bread.jpg"}`, service.ErrInvalidPhotoURL},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubMenuService{err: tc.err}
+
+			w := performAsUser(NewMenuHandler(stub).CreateItem, http.MethodPost, "/api/restaurants/:id/menu/categories/:category_id/items",
+				target, &staffID, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestDeleteMenuCategory_Errors(t *testing.T) {
+	staffID := uuid.New()
+	target := "/api/restaurants/" + uuid.NewString() + "/menu/categories/" + uuid.NewString()
+
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"deleted":   {nil, http.StatusNoContent},
+		"has items": {service.ErrMenuCategoryNotEmpty, http.StatusConflict},
+		"not staff": {service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"not found": {service.ErrMenuCategoryNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := performAsUser(NewMenuHandler(&stubMenuService{err: tc.err}).DeleteCategory, http.MethodDelete, "/api/restaurants/:id/menu/categories/:category_id",
+				target, &staffID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
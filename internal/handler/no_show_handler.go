@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NoShowHandler struct {
+	noShowService service.NoShowService
+}
+
+func NewNoShowHandler(noShowService service.NoShowService) *NoShowHandler {
+	return &NoShowHandler{noShowService: noShowService}
+}
+
+// NoShowResponse is a booking marked a no-show. Charge is omitted when the
+// booking's policy has no no-show fee; its status is "owed" when the wallet
+// could not cover it. NoShowCount is the customer's no-shows so far.
+type NoShowResponse struct {
+	Booking     *domain.Booking      `json:"booking"`
+	Charge      *domain.NoShowCharge `json:"charge,omitempty"`
+	NoShowCount int64                `json:"no_show_count"`
+}
+
+// @Summary Mark a booking a no-show
+// @Description Marks a pending or confirmed booking that has started a no-show and charges the policy's no-show fee to the customer's wallet, recording it as owed when the balance is too low. Staff of the restaurant only.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} NoShowResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/bookings/{id}/no-show [post]
+func (h *NoShowHandler) MarkNoShow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid booking id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.noShowService.MarkNoShow(c.Request.Context(), id, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "booking not found"})
+		case errors.Is(err, service.ErrNotRestaurantStaff):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+		case errors.Is(err, service.ErrNoShowBeforeStart), errors.Is(err, service.ErrNotNoShowable):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, NoShowResponse{
+		Booking:     result.Booking,
+		Charge:      result.Charge,
+		NoShowCount: result.NoShowCount,
+	})
+}
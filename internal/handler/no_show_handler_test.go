@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubNoShowService struct {
+	result  *service.NoShowResult
+	err     error
+	staffID uuid.UUID
+}
+
+func (s *stubNoShowService) MarkNoShow(ctx context.Context, bookingID uuid.UUID, staffID uuid.UUID) (*service.NoShowResult, error) {
+	s.staffID = staffID
+	return s.result, s.err
+}
+
+func markNoShow(svc service.NoShowService, staffID uuid.UUID) *httptest.ResponseRecorder {
+	return performAsUser(NewNoShowHandler(svc).MarkNoShow, http.MethodPost, "/api/bookings/:id/no-show",
+		"/api/bookings/"+uuid.NewString()+"/no-show", &staffID, "")
+}
+
+func TestMarkNoShow(t *testing.T) {
+	staffID := uuid.New()
+	booking := &domain.Booking{ID: uuid.New(), Status: domain.BookingStatusNoShow}
+	svc := &stubNoShowService{result: &service.NoShowResult{
+		Booking:     booking,
+		Charge:      &domain.NoShowCharge{BookingID: booking.ID, Amount: 3000, Status: domain.NoShowChargeOwed},
+		NoShowCount: 2,
+	}}
+
+	w := markNoShow(svc, staffID)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, staffID, svc.staffID)
+	var resp NoShowResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, domain.BookingStatusNoShow, resp.Booking.Status)
+	assert.Equal(t, domain.NoShowChargeOwed, resp.Charge.Status)
+	assert.Equal(t, int64(2), resp.NoShowCount)
+}
+
+func TestMarkNoShow_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"unknown booking":   {service.ErrBookingNotFound, http.StatusNotFound},
+		"not staff":         {service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"before the start":  {service.ErrNoShowBeforeStart, http.StatusConflict},
+		"already cancelled": {service.ErrNotNoShowable, http.StatusConflict},
+		"database down":     {errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := markNoShow(&stubNoShowService{err: tc.err}, uuid.New())
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
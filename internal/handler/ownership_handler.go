@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OwnershipHandler struct {
+	ownershipService service.OwnershipService
+}
+
+func NewOwnershipHandler(ownershipService service.OwnershipService) *OwnershipHandler {
+	return &OwnershipHandler{ownershipService: ownershipService}
+}
+
+// @Summary Transfer restaurant ownership
+// @Description Hands the restaurant to another user with the owner role. Only the current owner or an admin may do this. Set keep_previous_owner_as_manager to keep the previous owner on as a manager.
+// @Tags Restaurants
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param request body TransferOwnershipRequest true "New owner"
+// @Success 200 {object} domain.RestaurantOwnershipTransfer
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/restaurants/{id}/transfer-ownership [post]
+func (h *OwnershipHandler) TransferOwnership(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	transfer, err := h.ownershipService.TransferOwnership(c.Request.Context(), restaurantID, userID, service.TransferOwnershipRequest{
+		NewOwnerID:        req.NewOwnerID,
+		KeepPreviousOwner: req.KeepPreviousOwnerAsManager,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		case errors.Is(err, service.ErrAlreadyRestaurantOwner),
+			errors.Is(err, service.ErrNewOwnerNotOwnerRole),
+			errors.Is(err, service.ErrNewOwnerInactive):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrOwnershipChanged):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+type TransferOwnershipRequest struct {
+	NewOwnerID                 uuid.UUID `json:"new_owner_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
+	KeepPreviousOwnerAsManager bool      `json:"keep_previous_owner_as_manager" example:"true"`
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubOwnershipService struct {
+	service.OwnershipService
+	req *service.TransferOwnershipRequest
+	err error
+}
+
+func (s *stubOwnershipService) TransferOwnership(ctx context.Context, restaurantID, actorID uuid.UUID, req service.TransferOwnershipRequest) (*domain.RestaurantOwnershipTransfer, error) {
+	s.req = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.RestaurantOwnershipTransfer{ID: uuid.New(), RestaurantID: restaurantID, NewOwnerID: req.NewOwnerID, TransferredBy: actorID}, nil
+}
+
+func TestTransferOwnership(t *testing.T) {
+	ownerID, newOwnerID, restaurantID := uuid.New(), uuid.New(), uuid.New()
+	stub := &stubOwnershipService{}
+	body := `{"new_owner_id":"` + newOwnerID.String() + `","keep_previous_owner_as_manager":true}`
+
+	w := performAsUser(NewOwnershipHandler(stub).TransferOwnership, http.MethodPost, "/api/restaurants/:id/transfer-ownership",
+		"/api/restaurants/"+restaurantID.String()+"/transfer-ownership", &ownerID, body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, stub.req)
+	assert.Equal(t, newOwnerID, stub.req.NewOwnerID)
+	assert.True(t, stub.req.KeepPreviousOwner)
+}
+
+func TestTransferOwnership_Errors(t *testing.T) {
+	ownerID, restaurantID := uuid.New(), uuid.New()
+	body := `{"new_owner_id":"` + uuid.NewString() + `"}`
+
+	cases := map[string]struct {
+		body string
+		err  error
+		want int
+	}{
+		"no target":          {`{}`, nil, http.StatusBadRequest},
+		"not an owner":       {body, service.ErrNewOwnerNotOwnerRole, http.StatusBadRequest},
+		"unknown user":       {body, service.ErrUserNotFound, http.StatusNotFound},
+		"not the owner":      {body, service.ErrUnauthorized, http.StatusUnauthorized},
+		"changed meanwhile":  {body, service.ErrOwnershipChanged, http.StatusConflict},
+		"unknown restaurant": {body, service.ErrRestaurantNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubOwnershipService{err: tc.err}
+
+			w := performAsUser(NewOwnershipHandler(stub).TransferOwnership, http.MethodPost, "/api/restaurants/:id/transfer-ownership",
+				"/api/restaurants/"+restaurantID.String()+"/transfer-ownership", &ownerID, tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
@@ -1,22 +1,60 @@
 package handler
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of a provider
+// webhook's raw body, keyed with that provider's webhook secret.
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
 type PaymentHandler struct {
 	paymentService service.PaymentService
+	bookingRepo    repository.BookingRepository
+	// webhookSecrets holds the signing secret of each card provider. A
+	// provider without one has its webhooks rejected.
+	webhookSecrets map[domain.PaymentMethod]string
+}
+
+func NewPaymentHandler(paymentService service.PaymentService, bookingRepo repository.BookingRepository, webhookSecrets map[domain.PaymentMethod]string) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService, bookingRepo: bookingRepo, webhookSecrets: webhookSecrets}
 }
 
-func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
-	return &PaymentHandler{paymentService: paymentService}
+// bookingPolicy returns the cancellation policy recorded on the payment's
+// booking, or nil for payments without one and bookings made before
+// policies were recorded.
+func (h *PaymentHandler) bookingPolicy(c *gin.Context, payment *domain.Payment) *CancellationPolicyResponse {
+	if payment.BookingID == nil {
+		return nil
+	}
+	booking, err := h.bookingRepo.GetByID(c.Request.Context(), *payment.BookingID)
+	if err != nil {
+		log.Printf("Load booking policy error: %v", err)
+		return nil
+	}
+	if booking.CancellationPolicy == nil {
+		return nil
+	}
+	return cancellationPolicyResponse(c, *booking.CancellationPolicy)
 }
 
 // @Summary Create wallet payment
@@ -25,19 +63,19 @@ func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
 // @Accept json
 // @Produce json
 // @Param request body CreatePaymentRequest true "Payment request"
-// @Success 200 {object} domain.Payment
+// @Success 200 {object} PaymentResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Router /api/payments/wallet [post]
 func (h *PaymentHandler) CreateWalletPayment(c *gin.Context) {
-	var req CreatePaymentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -66,7 +104,10 @@ func (h *PaymentHandler) CreateWalletPayment(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
+	c.JSON(http.StatusOK, PaymentResponse{
+		Payment:            payment,
+		CancellationPolicy: h.bookingPolicy(c, payment),
+	})
 }
 
 // @Summary Create Halyk Bank payment
@@ -77,18 +118,18 @@ func (h *PaymentHandler) CreateWalletPayment(c *gin.Context) {
 // @Param request body CreatePaymentRequest true "Payment request"
 // @Success 200 {object} PaymentWithURLResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/payments/halyk [post]
 func (h *PaymentHandler) CreateHalykPayment(c *gin.Context) {
-	var req CreatePaymentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -122,6 +163,7 @@ func (h *PaymentHandler) CreateHalykPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, PaymentWithURLResponse{
 		Payment:            payment,
 		ExternalPaymentURL: url,
+		CancellationPolicy: h.bookingPolicy(c, payment),
 	})
 }
 
@@ -133,18 +175,18 @@ func (h *PaymentHandler) CreateHalykPayment(c *gin.Context) {
 // @Param request body CreatePaymentRequest true "Payment request"
 // @Success 200 {object} PaymentWithURLResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/payments/kaspi [post]
 func (h *PaymentHandler) CreateKaspiPayment(c *gin.Context) {
-	var req CreatePaymentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -178,20 +220,71 @@ func (h *PaymentHandler) CreateKaspiPayment(c *gin.Context) {
 	c.JSON(http.StatusOK, PaymentWithURLResponse{
 		Payment:            payment,
 		ExternalPaymentURL: url,
+		CancellationPolicy: h.bookingPolicy(c, payment),
 	})
 }
 
 // @Summary Halyk Bank webhook
-// @Description Webhook endpoint for Halyk Bank payment notifications
+// @Description Webhook endpoint for Halyk Bank payment notifications, signed in the X-Webhook-Signature header
 // @Tags Payments
 // @Accept json
 // @Produce json
+// @Param X-Webhook-Signature header string true "Hex HMAC-SHA256 of the body"
 // @Param request body WebhookRequest true "Webhook request"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/payments/webhook/halyk [post]
 func (h *PaymentHandler) HalykWebhook(c *gin.Context) {
+	h.handleWebhook(c, domain.PaymentMethodHalyk)
+}
+
+// @Summary Kaspi webhook
+// @Description Webhook endpoint for Kaspi payment notifications, signed in the X-Webhook-Signature header
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Signature header string true "Hex HMAC-SHA256 of the body"
+// @Param request body WebhookRequest true "Webhook request"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/payments/webhook/kaspi [post]
+func (h *PaymentHandler) KaspiWebhook(c *gin.Context) {
+	h.handleWebhook(c, domain.PaymentMethodKaspi)
+}
+
+// handleWebhook applies a provider's payment notification once its signature
+// checks out. Failures answer non-2xx so the provider retries them.
+func (h *PaymentHandler) handleWebhook(c *gin.Context, method domain.PaymentMethod) {
+	secret := h.webhookSecrets[method]
+	if secret == "" {
+		log.Printf("%s webhook rejected: no webhook secret is configured", method)
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid body"})
+		return
+	}
+	if !validWebhookSignature(secret, body, c.GetHeader(WebhookSignatureHeader)) {
+		log.Printf("%s webhook rejected: invalid signature", method)
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid signature"})
+		return
+	}
+
 	var req WebhookRequest
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
@@ -204,34 +297,50 @@ func (h *PaymentHandler) HalykWebhook(c *gin.Context) {
 		req.ExternalPaymentID,
 		success,
 	); err != nil {
-
+		log.Printf("%s webhook for payment %s error: %v", method, req.ExternalPaymentID, err)
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "payment not found"})
+		case errors.Is(err, service.ErrInvalidPaymentStatus):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to process webhook"})
+		}
+		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{Message: "ok"})
 }
 
-// @Summary Kaspi webhook
-// @Description Webhook endpoint for Kaspi payment notifications
-// @Tags Payments
-// @Accept json
-// @Produce json
-// @Param request body WebhookRequest true "Webhook request"
-// @Success 200 {object} SuccessResponse
-// @Failure 400 {object} ErrorResponse
-// @Router /api/payments/webhook/kaspi [post]
-func (h *PaymentHandler) KaspiWebhook(c *gin.Context) {
-	h.HalykWebhook(c)
+// validWebhookSignature reports whether signature is the hex HMAC-SHA256 of
+// body keyed with secret.
+func validWebhookSignature(secret string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
 }
 
 // @Summary Refund payment
-// @Description Refund a completed payment
+// @Description Refund a completed payment in full, service fee included, and cancel the booking it paid for. Only the payer or an admin may refund it.
 // @Tags Payments
 // @Produce json
 // @Param id path string true "Payment ID"
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Router /api/payments/{id}/refund [post]
 func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -239,8 +348,15 @@ func (h *PaymentHandler) RefundPayment(c *gin.Context) {
 		return
 	}
 
-	if err := h.paymentService.RefundPayment(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	if err := h.paymentService.RefundPayment(c.Request.Context(), id, userID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "payment not found"})
+		case errors.Is(err, service.ErrNotPaymentOwner):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
@@ -248,50 +364,161 @@ func (h *PaymentHandler) RefundPayment(c *gin.Context) {
 }
 
 // @Summary Get user payments
-// @Description Get payment history for a user
+// @Description Get payment history with a summary of the filtered set. Admins may pass user_id to see another user's payments.
 // @Tags Payments
 // @Produce json
-// @Param user_id query string true "User ID"
-// @Param limit query int false "Limit" default(10)
+// @Param user_id query string false "User ID (admins only)"
+// @Param status query string false "Payment status" Enums(pending, completed, failed, refunded, voided)
+// @Param method query string false "Payment method" Enums(wallet, halyk, kaspi)
+// @Param booking_id query string false "Booking ID"
+// @Param from query string false "Created at or after (RFC3339)"
+// @Param to query string false "Created before (RFC3339)"
+// @Param limit query int false "Limit, at most 100" default(10)
 // @Param offset query int false "Offset" default(0)
-// @Success 200 {array} domain.Payment
+// @Success 200 {object} PaymentListResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/payments [get]
 func (h *PaymentHandler) GetUserPayments(c *gin.Context) {
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "user_id is required"})
+	currentID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
-		return
+	userID := currentID
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		requested, err := uuid.Parse(userIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+			return
+		}
+		role, _ := c.Get("user_role")
+		if requested != currentID && role != domain.UserRoleAdmin {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "only admins can view other users' payments"})
+			return
+		}
+		userID = requested
+	}
+
+	filter := repository.PaymentFilter{UserID: &userID}
+
+	if st := c.Query("status"); st != "" {
+		status := domain.PaymentStatus(st)
+		if !slices.Contains(paymentStatuses, status) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid status, allowed values: %s", joinValues(paymentStatuses))})
+			return
+		}
+		filter.Status = &status
+	}
+
+	if m := c.Query("method"); m != "" {
+		method := domain.PaymentMethod(m)
+		if !slices.Contains(paymentMethods, method) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid method, allowed values: %s", joinValues(paymentMethods))})
+			return
+		}
+		filter.Method = &method
 	}
 
-	limit := 10
-	offset := 0
+	if b := c.Query("booking_id"); b != "" {
+		bookingID, err := uuid.Parse(b)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid booking_id"})
+			return
+		}
+		filter.BookingID = &bookingID
+	}
 
-	if l := c.Query("limit"); l != "" {
-		fmt.Sscanf(l, "%d", &limit)
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+			return
+		}
+		*bound.target = &parsed.Time
 	}
-	if o := c.Query("offset"); o != "" {
-		fmt.Sscanf(o, "%d", &offset)
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return
 	}
 
-	payments, err := h.paymentService.GetPaymentsByUser(c.Request.Context(), userID, limit, offset)
+	page, ok := paginationParams(c, 10)
+	if !ok {
+		return
+	}
+
+	payments, summary, err := h.paymentService.ListPayments(c.Request.Context(), filter, page.Limit, page.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, payments)
+	response := PaymentListResponse{
+		Items:            payments,
+		Total:            summary.Count,
+		PaginationParams: page,
+		Summary: PaymentSummaryResponse{
+			TotalPaid:     summary.TotalPaid,
+			TotalRefunded: summary.TotalRefunded,
+			TotalFees:     summary.TotalFees,
+			CountByStatus: make(map[domain.PaymentStatus]int64, len(paymentStatuses)),
+		},
+	}
+	for _, status := range paymentStatuses {
+		response.Summary.CountByStatus[status] = summary.CountByStatus[status]
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+var (
+	paymentStatuses = []domain.PaymentStatus{
+		domain.PaymentStatusPending,
+		domain.PaymentStatusCompleted,
+		domain.PaymentStatusFailed,
+		domain.PaymentStatusRefunded,
+		domain.PaymentStatusVoided,
+	}
+	paymentMethods = []domain.PaymentMethod{
+		domain.PaymentMethodWallet,
+		domain.PaymentMethodHalyk,
+		domain.PaymentMethodKaspi,
+	}
+)
+
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = string(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type PaymentListResponse struct {
+	Items []*domain.Payment `json:"items"`
+	Total int64             `json:"total" example:"42"`
+	PaginationParams
+	Summary PaymentSummaryResponse `json:"summary"`
+}
+
+// PaymentSummaryResponse covers every payment matching the filters, not just
+// the returned page. total_paid counts completed payments only.
+type PaymentSummaryResponse struct {
+	TotalPaid     int64                          `json:"total_paid"`
+	TotalRefunded int64                          `json:"total_refunded"`
+	TotalFees     int64                          `json:"total_fees"`
+	CountByStatus map[domain.PaymentStatus]int64 `json:"count_by_status"`
 }
 
 type CreatePaymentRequest struct {
-	UserID    string `json:"user_id" binding:"required"`
 	Amount    int    `json:"amount" binding:"required,min=1"`
 	BookingID string `json:"booking_id"`
 }
@@ -301,7 +528,15 @@ type WebhookRequest struct {
 	Status            string `json:"status" binding:"required"`
 }
 
+// PaymentResponse is a created payment with the cancellation policy of its
+// booking, if it has one.
+type PaymentResponse struct {
+	*domain.Payment
+	CancellationPolicy *CancellationPolicyResponse `json:"cancellation_policy,omitempty"`
+}
+
 type PaymentWithURLResponse struct {
-	Payment            *domain.Payment `json:"payment"`
-	ExternalPaymentURL string          `json:"external_payment_url"`
+	Payment            *domain.Payment             `json:"payment"`
+	ExternalPaymentURL string                      `json:"external_payment_url"`
+	CancellationPolicy *CancellationPolicyResponse `json:"cancellation_policy,omitempty"`
 }
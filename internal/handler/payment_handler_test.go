@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPaymentService struct {
+	service.PaymentService
+	filter *repository.PaymentFilter
+	// status is what created payments are in, pending when empty.
+	status      domain.PaymentStatus
+	checkoutErr error
+	refundErr   error
+	refundedBy  uuid.UUID
+	callbackErr error
+	// callbacks are the external IDs of the webhooks processed.
+	callbacks []string
+}
+
+func (s *stubPaymentService) ProcessExternalPaymentCallback(ctx context.Context, externalPaymentID string, success bool) error {
+	s.callbacks = append(s.callbacks, externalPaymentID)
+	return s.callbackErr
+}
+
+func (s *stubPaymentService) RefundPayment(ctx context.Context, paymentID, userID uuid.UUID) error {
+	s.refundedBy = userID
+	return s.refundErr
+}
+
+func (s *stubPaymentService) ListPayments(ctx context.Context, filter repository.PaymentFilter, limit, offset int) ([]*domain.Payment, *repository.PaymentSummary, error) {
+	s.filter = &filter
+	return []*domain.Payment{{ID: uuid.New(), UserID: *filter.UserID, Amount: 5000}}, &repository.PaymentSummary{
+		Count:         2,
+		TotalPaid:     5000,
+		TotalRefunded: 2000,
+		CountByStatus: map[domain.PaymentStatus]int64{
+			domain.PaymentStatusCompleted: 1,
+			domain.PaymentStatusRefunded:  1,
+		},
+	}, nil
+}
+
+func (s *stubPaymentService) CreatePayment(ctx context.Context, userID uuid.UUID, amount int, method domain.PaymentMethod, bookingID *uuid.UUID) (*domain.Payment, error) {
+	status := s.status
+	if status == "" {
+		status = domain.PaymentStatusPending
+	}
+	return &domain.Payment{ID: uuid.New(), UserID: userID, BookingID: bookingID, Amount: amount, PaymentMethod: method, PaymentStatus: status}, nil
+}
+
+func (s *stubPaymentService) CreateKaspiPayment(ctx context.Context, paymentID uuid.UUID) (string, error) {
+	if s.checkoutErr != nil {
+		return "", s.checkoutErr
+	}
+	return "https://kaspi-mock.kz/pay/" + paymentID.String(), nil
+}
+
+type stubBookingRepository struct {
+	repository.BookingRepository
+	booking *domain.Booking
+}
+
+func (r *stubBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error) {
+	return r.booking, nil
+}
+
+func listPayments(svc service.PaymentService, userID uuid.UUID, role domain.UserRole, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/payments", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("user_role", role)
+		c.Next()
+	}, NewPaymentHandler(svc, nil, nil).GetUserPayments)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/payments"+query, nil))
+	return w
+}
+
+func TestGetUserPayments_FiltersAndSummary(t *testing.T) {
+	svc := &stubPaymentService{}
+	userID := uuid.New()
+	bookingID := uuid.New()
+
+	w := listPayments(svc, userID, domain.UserRoleCustomer,
+		"?status=completed&method=kaspi&booking_id="+bookingID.String()+"&from=2024-05-01T00:00:00Z&to=2024-06-01T00:00:00Z")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.filter)
+	assert.Equal(t, userID, *svc.filter.UserID)
+	assert.Equal(t, domain.PaymentStatusCompleted, *svc.filter.Status)
+	assert.Equal(t, domain.PaymentMethodKaspi, *svc.filter.Method)
+	assert.Equal(t, bookingID, *svc.filter.BookingID)
+	assert.True(t, svc.filter.From.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, svc.filter.To.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+	var resp PaymentListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, int64(2), resp.Total)
+	assert.Equal(t, 10, resp.Limit)
+	assert.Equal(t, int64(5000), resp.Summary.TotalPaid)
+	assert.Equal(t, int64(2000), resp.Summary.TotalRefunded)
+	assert.Equal(t, int64(1), resp.Summary.CountByStatus[domain.PaymentStatusCompleted])
+	assert.Contains(t, resp.Summary.CountByStatus, domain.PaymentStatusPending)
+}
+
+func TestGetUserPayments_OtherUser(t *testing.T) {
+	otherID := uuid.New()
+
+	svc := &stubPaymentService{}
+	w := listPayments(svc, uuid.New(), domain.UserRoleCustomer, "?user_id="+otherID.String())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Nil(t, svc.filter)
+
+	svc = &stubPaymentService{}
+	w = listPayments(svc, uuid.New(), domain.UserRoleAdmin, "?user_id="+otherID.String())
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, otherID, *svc.filter.UserID)
+}
+
+func TestGetUserPayments_InvalidFilters(t *testing.T) {
+	cases := map[string]string{
+		"?status=paid":   "invalid status, allowed values: pending, completed, failed, refunded, voided",
+		"?method=cash":   "invalid method, allowed values: wallet, halyk, kaspi",
+		"?booking_id=42": "invalid booking_id",
+		"?from=2024-06-01T00:00:00Z&to=2024-05-01T00:00:00Z": "from must be before to",
+		"?offset=-1": "offset must be a non-negative integer",
+		"?limit=0":   "limit must be a positive integer",
+	}
+
+	for query, message := range cases {
+		svc := &stubPaymentService{}
+
+		w := listPayments(svc, uuid.New(), domain.UserRoleCustomer, query)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, message, resp.Error)
+		assert.Nil(t, svc.filter)
+	}
+}
+
+func TestCreateWalletPayment_EmbedsBookingPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	booking := &domain.Booking{ID: uuid.New()}
+	booking.ApplyPolicy(domain.CancellationPolicy{FreeCancellationHours: 24, DepositAmount: 5000})
+
+	router := gin.New()
+	router.POST("/api/payments/wallet", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		c.Next()
+	}, NewPaymentHandler(&stubPaymentService{}, &stubBookingRepository{booking: booking}, nil).CreateWalletPayment)
+
+	body := `{"amount":5000,"booking_id":"` + booking.ID.String() + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/wallet", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp PaymentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 5000, resp.Amount)
+	require.NotNil(t, resp.CancellationPolicy)
+	assert.Equal(t, booking.PolicyVersion, resp.CancellationPolicy.PolicyVersion)
+	assert.Equal(t, 24, resp.CancellationPolicy.FreeCancellationHours)
+	assert.Equal(t, "Бесплатная отмена не позднее чем за 24 ч до начала бронирования. Требуется депозит 5000 KZT, он не возвращается.", resp.CancellationPolicy.Summary)
+}
+
+func TestCreateWalletPayment_WithoutBookingHasNoPolicy(t *testing.T) {
+	userID := uuid.New()
+	h := NewPaymentHandler(&stubPaymentService{}, &stubBookingRepository{}, nil)
+	w := performAsUser(h.CreateWalletPayment, http.MethodPost, "/api/payments/wallet", "/api/payments/wallet", &userID, `{"amount":5000}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "cancellation_policy")
+}
+
+func TestCreateWalletPayment_PaysAsTheCaller(t *testing.T) {
+	userID := uuid.New()
+	h := NewPaymentHandler(&stubPaymentService{}, &stubBookingRepository{}, nil)
+	body := `{"user_id":"` + uuid.NewString() + `","amount":5000}`
+	w := performAsUser(h.CreateWalletPayment, http.MethodPost, "/api/payments/wallet", "/api/payments/wallet", &userID, body)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp PaymentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, userID, resp.UserID)
+}
+
+func TestCreatePayment_NoUser(t *testing.T) {
+	h := NewPaymentHandler(&stubPaymentService{}, &stubBookingRepository{}, nil)
+	for name, handlerFunc := range map[string]gin.HandlerFunc{
+		"wallet": h.CreateWalletPayment,
+		"halyk":  h.CreateHalykPayment,
+		"kaspi":  h.CreateKaspiPayment,
+	} {
+		w := performAsUser(handlerFunc, http.MethodPost, "/api/payments/"+name, "/api/payments/"+name, nil, `{"amount":5000}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+	}
+}
+
+func TestRefundPayment_AsTheCaller(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubPaymentService{}
+	h := NewPaymentHandler(svc, nil, nil)
+	target := "/api/payments/" + uuid.NewString() + "/refund"
+
+	w := performAsUser(h.RefundPayment, http.MethodPost, "/api/payments/:id/refund", target, &userID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, svc.refundedBy)
+
+	svc.refundErr = service.ErrNotPaymentOwner
+	w = performAsUser(h.RefundPayment, http.MethodPost, "/api/payments/:id/refund", target, &userID, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = performAsUser(h.RefundPayment, http.MethodPost, "/api/payments/:id/refund", target, nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func postWebhook(h *PaymentHandler, body, signature string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/payments/webhook/kaspi", h.KaspiWebhook)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook/kaspi", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(WebhookSignatureHeader, signature)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhook_Signature(t *testing.T) {
+	const secret = "kaspi-secret"
+	body := `{"external_payment_id":"ext-1","status":"success"}`
+	secrets := map[domain.PaymentMethod]string{domain.PaymentMethodKaspi: secret}
+
+	cases := []struct {
+		name      string
+		secrets   map[domain.PaymentMethod]string
+		signature string
+		want      int
+	}{
+		{"valid", secrets, signWebhook(secret, body), http.StatusOK},
+		{"missing", secrets, "", http.StatusUnauthorized},
+		{"other secret", secrets, signWebhook("guessed", body), http.StatusUnauthorized},
+		{"other provider's secret", map[domain.PaymentMethod]string{domain.PaymentMethodHalyk: secret}, signWebhook(secret, body), http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubPaymentService{}
+			w := postWebhook(NewPaymentHandler(svc, nil, tc.secrets), body, tc.signature)
+
+			assert.Equal(t, tc.want, w.Code)
+			if tc.want == http.StatusOK {
+				assert.Equal(t, []string{"ext-1"}, svc.callbacks)
+			} else {
+				assert.Empty(t, svc.callbacks)
+			}
+		})
+	}
+}
+
+func TestWebhook_ErrorsAreRetried(t *testing.T) {
+	const secret = "kaspi-secret"
+	body := `{"external_payment_id":"ext-1","status":"success"}`
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{service.ErrPaymentNotFound, http.StatusNotFound},
+		{fmt.Errorf("%w: failed", service.ErrInvalidPaymentStatus), http.StatusConflict},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		svc := &stubPaymentService{callbackErr: tc.err}
+		h := NewPaymentHandler(svc, nil, map[domain.PaymentMethod]string{domain.PaymentMethodKaspi: secret})
+
+		w := postWebhook(h, body, signWebhook(secret, body))
+		assert.Equal(t, tc.want, w.Code, tc.err.Error())
+	}
+}
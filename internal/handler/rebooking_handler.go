@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultRebookingReportDays is the period the rebooking report covers when
+// the admin does not give one.
+const defaultRebookingReportDays = 30
+
+type RebookingHandler struct {
+	rebookingService service.RebookingService
+}
+
+func NewRebookingHandler(rebookingService service.RebookingService) *RebookingHandler {
+	return &RebookingHandler{rebookingService: rebookingService}
+}
+
+func (h *RebookingHandler) GetOffer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid rebooking offer id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.rebookingService.GetOffer(c.Request.Context(), id, userID)
+	if err != nil {
+		writeRebookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// AcceptOffer books one of the offer's options, given by its index, and
+// moves the cancelled booking's deposit to it.
+func (h *RebookingHandler) AcceptOffer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid rebooking offer id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req AcceptRebookingOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	booking, err := h.rebookingService.AcceptOffer(c.Request.Context(), id, userID, *req.Option)
+	if err != nil {
+		writeRebookingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// Report returns how many rebooking offers created between from and to were
+// accepted or expired. The period defaults to the last 30 days.
+func (h *RebookingHandler) Report(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -defaultRebookingReportDays)
+
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+			return
+		}
+		*bound.target = parsed.Time
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return
+	}
+
+	report, err := h.rebookingService.Report(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RebookingReportResponse{
+		From:         apitime.Time{Time: from},
+		To:           apitime.Time{Time: to},
+		Offered:      report.Offered,
+		Accepted:     report.Accepted,
+		Expired:      report.Expired,
+		Pending:      report.Pending,
+		RecoveryRate: report.RecoveryRate,
+	})
+}
+
+func writeRebookingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRebookingOfferNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrRebookingOfferExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRebookingOfferAnswered),
+		errors.Is(err, service.ErrRebookingSlotTaken):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidRebookingOption):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type AcceptRebookingOfferRequest struct {
+	// Option is the index into the offer's options.
+	Option *int `json:"option" binding:"required,min=0"`
+}
+
+type RebookingReportResponse struct {
+	From         apitime.Time `json:"from" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	To           apitime.Time `json:"to" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	Offered      int64        `json:"offered"`
+	Accepted     int64        `json:"accepted"`
+	Expired      int64        `json:"expired"`
+	Pending      int64        `json:"pending"`
+	RecoveryRate float64      `json:"recovery_rate"`
+}
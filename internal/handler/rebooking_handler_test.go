@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRebookingService struct {
+	service.RebookingService
+	acceptErr error
+	option    int
+	from, to  time.Time
+	offered   []uuid.UUID
+}
+
+func (s *stubRebookingService) OfferRebooking(ctx context.Context, booking *domain.Booking) (*domain.RebookingOffer, error) {
+	s.offered = append(s.offered, booking.ID)
+	return &domain.RebookingOffer{BookingID: booking.ID}, nil
+}
+
+func (s *stubRebookingService) AcceptOffer(ctx context.Context, id, userID uuid.UUID, option int) (*domain.Booking, error) {
+	s.option = option
+	if s.acceptErr != nil {
+		return nil, s.acceptErr
+	}
+	return &domain.Booking{ID: uuid.New(), UserID: &userID, Status: domain.BookingStatusConfirmed}, nil
+}
+
+func (s *stubRebookingService) Report(ctx context.Context, from, to time.Time) (*service.RebookingReport, error) {
+	s.from, s.to = from, to
+	return &service.RebookingReport{Offered: 4, Accepted: 1, Expired: 3, RecoveryRate: 0.25}, nil
+}
+
+func TestAcceptRebookingOffer_Handler(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/rebooking-offers/:id/accept"
+	target := "/api/rebooking-offers/" + uuid.NewString() + "/accept"
+
+	svc := &stubRebookingService{}
+	w := performAsUser(NewRebookingHandler(svc).AcceptOffer, http.MethodPost, route, target, &userID, `{"option":0}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 0, svc.option)
+
+	w = performAsUser(NewRebookingHandler(svc).AcceptOffer, http.MethodPost, route, target, &userID, `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	cases := map[error]int{
+		service.ErrRebookingOfferNotFound: http.StatusNotFound,
+		service.ErrRebookingOfferExpired:  http.StatusGone,
+		service.ErrRebookingOfferAnswered: http.StatusConflict,
+		service.ErrRebookingSlotTaken:     http.StatusConflict,
+		service.ErrInvalidRebookingOption: http.StatusBadRequest,
+	}
+	for err, status := range cases {
+		svc := &stubRebookingService{acceptErr: err}
+		w := performAsUser(NewRebookingHandler(svc).AcceptOffer, http.MethodPost, route, target, &userID, `{"option":2}`)
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+
+	w = performAsUser(NewRebookingHandler(svc).AcceptOffer, http.MethodPost, route, target, nil, `{"option":0}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRebookingReport_Handler(t *testing.T) {
+	adminID := uuid.New()
+	route := "/api/admin/rebooking-offers/report"
+
+	svc := &stubRebookingService{}
+	w := performAsUser(NewRebookingHandler(svc).Report, http.MethodGet, route,
+		route+"?from=2024-06-01T00:00:00Z&to=2024-07-01T00:00:00Z", &adminID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), svc.from.UTC())
+
+	var resp RebookingReportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(4), resp.Offered)
+	assert.Equal(t, 0.25, resp.RecoveryRate)
+
+	w = performAsUser(NewRebookingHandler(svc).Report, http.MethodGet, route,
+		route+"?from=2024-07-01T00:00:00Z&to=2024-06-01T00:00:00Z", &adminID, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
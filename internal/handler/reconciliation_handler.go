@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// reconciliationMonthLayout is the format of the month query parameter.
+const reconciliationMonthLayout = "2006-01"
+
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// @Summary Revenue reconciliation
+// @Description Reconciles every booking of the restaurant starting in month (UTC) against its payments, refunds, service fees and payouts. Discrepancy rows list the payments and ledger entries involved. format=csv returns the rows as a CSV download.
+// @Tags Restaurants
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "Restaurant ID"
+// @Param month query string false "Month, as YYYY-MM; defaults to the current month"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} ReconciliationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/reconciliation [get]
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	month := time.Now().UTC()
+	if raw := c.Query("month"); raw != "" {
+		month, err = time.Parse(reconciliationMonthLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid month, use YYYY-MM"})
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be json or csv"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reconciliationService.MonthlyReport(c.Request.Context(), restaurantID, userID, month)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	if format == "csv" {
+		writeReconciliationCSV(c, report)
+		return
+	}
+	c.JSON(http.StatusOK, toReconciliationResponse(report))
+}
+
+// reconciliationCSVHeader names the columns of the CSV download. Ledger
+// entries and payments are listed by ID, separated by spaces.
+var reconciliationCSVHeader = []string{
+	"booking_id", "start_time", "booking_status", "charged", "refunded", "fees", "commission",
+	"due", "settled", "status", "problems", "payment_ids", "ledger_entry_ids",
+}
+
+func writeReconciliationCSV(c *gin.Context, report *service.Reconciliation) {
+	filename := fmt.Sprintf("reconciliation-%s-%s.csv", report.RestaurantID, report.Month)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(reconciliationCSVHeader)
+	for _, row := range report.Rows {
+		paymentIDs := make([]string, len(row.PaymentIDs))
+		for i, id := range row.PaymentIDs {
+			paymentIDs[i] = id.String()
+		}
+		entryIDs := make([]string, len(row.LedgerEntries))
+		for i, entry := range row.LedgerEntries {
+			entryIDs[i] = entry.ID.String()
+		}
+		_ = w.Write([]string{
+			row.BookingID.String(),
+			row.StartTime.UTC().Format(apitime.Layout),
+			string(row.BookingStatus),
+			strconv.Itoa(row.Charged),
+			strconv.Itoa(row.Refunded),
+			strconv.Itoa(row.Fees),
+			strconv.Itoa(row.Commission),
+			strconv.Itoa(row.Due),
+			strconv.Itoa(row.Settled),
+			string(row.Status),
+			strings.Join(row.Problems, "; "),
+			strings.Join(paymentIDs, " "),
+			strings.Join(entryIDs, " "),
+		})
+	}
+	w.Flush()
+}
+
+type ReconciliationResponse struct {
+	RestaurantID uuid.UUID                    `json:"restaurant_id"`
+	Month        string                       `json:"month" example:"2024-06"`
+	Rows         []ReconciliationRowResponse  `json:"rows"`
+	Totals       ReconciliationTotalsResponse `json:"totals"`
+}
+
+// ReconciliationRowResponse is one booking. Amounts are in KZT; due is
+// charged - refunded - commission.
+type ReconciliationRowResponse struct {
+	BookingID     uuid.UUID                    `json:"booking_id"`
+	StartTime     apitime.Time                 `json:"start_time" swaggertype:"string" format:"date-time"`
+	BookingStatus domain.BookingStatus         `json:"booking_status"`
+	Charged       int                          `json:"charged"`
+	Refunded      int                          `json:"refunded"`
+	Fees          int                          `json:"fees"`
+	Commission    int                          `json:"commission"`
+	Due           int                          `json:"due"`
+	Settled       int                          `json:"settled"`
+	Status        service.ReconciliationStatus `json:"status" enums:"fully_settled,pending,discrepancy"`
+	Problems      []string                     `json:"problems,omitempty"`
+	PaymentIDs    []uuid.UUID                  `json:"payment_ids,omitempty"`
+	LedgerEntries []LedgerEntryResponse        `json:"ledger_entries,omitempty"`
+}
+
+type LedgerEntryResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Type        domain.TransactionType `json:"type"`
+	Amount      int                    `json:"amount"`
+	Description string                 `json:"description"`
+	CreatedAt   apitime.Time           `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+type ReconciliationTotalsResponse struct {
+	Charged       int `json:"charged"`
+	Refunded      int `json:"refunded"`
+	Fees          int `json:"fees"`
+	Commission    int `json:"commission"`
+	Due           int `json:"due"`
+	Settled       int `json:"settled"`
+	Discrepancies int `json:"discrepancies"`
+}
+
+func toReconciliationResponse(report *service.Reconciliation) ReconciliationResponse {
+	response := ReconciliationResponse{
+		RestaurantID: report.RestaurantID,
+		Month:        report.Month,
+		Rows:         make([]ReconciliationRowResponse, len(report.Rows)),
+		Totals:       ReconciliationTotalsResponse(report.Totals),
+	}
+	for i, row := range report.Rows {
+		var entries []LedgerEntryResponse
+		for _, entry := range row.LedgerEntries {
+			entries = append(entries, LedgerEntryResponse{
+				ID:          entry.ID,
+				Type:        entry.Type,
+				Amount:      entry.Amount,
+				Description: entry.Description,
+				CreatedAt:   apitime.New(entry.CreatedAt),
+			})
+		}
+		response.Rows[i] = ReconciliationRowResponse{
+			BookingID:     row.BookingID,
+			StartTime:     apitime.New(row.StartTime),
+			BookingStatus: row.BookingStatus,
+			Charged:       row.Charged,
+			Refunded:      row.Refunded,
+			Fees:          row.Fees,
+			Commission:    row.Commission,
+			Due:           row.Due,
+			Settled:       row.Settled,
+			Status:        row.Status,
+			Problems:      row.Problems,
+			PaymentIDs:    row.PaymentIDs,
+			LedgerEntries: entries,
+		}
+	}
+	return response
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReconciliationService struct {
+	report *service.Reconciliation
+	err    error
+	month  time.Time
+}
+
+func (s *stubReconciliationService) MonthlyReport(ctx context.Context, restaurantID, userID uuid.UUID, month time.Time) (*service.Reconciliation, error) {
+	s.month = month
+	return s.report, s.err
+}
+
+func discrepancyReport(restaurantID uuid.UUID) *service.Reconciliation {
+	bookingID := uuid.New()
+	return &service.Reconciliation{
+		RestaurantID: restaurantID,
+		Month:        "2024-06",
+		Rows: []*service.ReconciliationRow{{
+			BookingID:     bookingID,
+			StartTime:     time.Date(2024, time.June, 3, 19, 0, 0, 0, time.UTC),
+			BookingStatus: domain.BookingStatusCancelled,
+			Charged:       10500,
+			Refunded:      10500,
+			Fees:          500,
+			Settled:       10000,
+			Status:        service.ReconciliationDiscrepancy,
+			Problems:      []string{"settled 10000 but only 0 is due"},
+			PaymentIDs:    []uuid.UUID{uuid.New()},
+			LedgerEntries: []*domain.WalletTransaction{
+				{ID: uuid.New(), Type: domain.TransactionPaymentToRestaurant, Amount: 10000, BookingID: &bookingID},
+				{ID: uuid.New(), Type: domain.TransactionRefund, Amount: 10500, BookingID: &bookingID},
+			},
+		}},
+		Totals: service.ReconciliationTotals{Charged: 10500, Refunded: 10500, Fees: 500, Settled: 10000, Discrepancies: 1},
+	}
+}
+
+func TestGetReconciliation_JSON(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	report := discrepancyReport(restaurantID)
+	stub := &stubReconciliationService{report: report}
+
+	w := performAsUser(NewReconciliationHandler(stub).GetReconciliation, http.MethodGet, "/api/restaurants/:id/reconciliation",
+		"/api/restaurants/"+restaurantID.String()+"/reconciliation?month=2024-06", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), stub.month)
+
+	var resp ReconciliationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2024-06", resp.Month)
+	assert.Equal(t, 1, resp.Totals.Discrepancies)
+	require.Len(t, resp.Rows, 1)
+	assert.Equal(t, service.ReconciliationDiscrepancy, resp.Rows[0].Status)
+	require.Len(t, resp.Rows[0].LedgerEntries, 2)
+	assert.Equal(t, report.Rows[0].LedgerEntries[1].ID, resp.Rows[0].LedgerEntries[1].ID)
+	assert.Equal(t, domain.TransactionRefund, resp.Rows[0].LedgerEntries[1].Type)
+}
+
+func TestGetReconciliation_CSV(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	report := discrepancyReport(restaurantID)
+
+	w := performAsUser(NewReconciliationHandler(&stubReconciliationService{report: report}).GetReconciliation, http.MethodGet, "/api/restaurants/:id/reconciliation",
+		"/api/restaurants/"+restaurantID.String()+"/reconciliation?month=2024-06&format=csv", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Equal(t, `attachment; filename="reconciliation-`+restaurantID.String()+`-2024-06.csv"`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, reconciliationCSVHeader, records[0])
+
+	row := records[1]
+	assert.Equal(t, report.Rows[0].BookingID.String(), row[0])
+	assert.Equal(t, "10500", row[3])
+	assert.Equal(t, "discrepancy", row[9])
+	assert.Equal(t, report.Rows[0].LedgerEntries[0].ID.String()+" "+report.Rows[0].LedgerEntries[1].ID.String(), row[12])
+}
+
+func TestGetReconciliation_Errors(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/restaurants/:id/reconciliation"
+	target := "/api/restaurants/" + uuid.NewString() + "/reconciliation"
+
+	cases := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{"invalid month", "?month=June", nil, http.StatusBadRequest},
+		{"invalid format", "?format=xlsx", nil, http.StatusBadRequest},
+		{"not found", "", service.ErrRestaurantNotFound, http.StatusNotFound},
+		{"not owner", "", service.ErrUnauthorized, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performAsUser(NewReconciliationHandler(&stubReconciliationService{err: tc.err}).GetReconciliation, http.MethodGet, route, target+tc.query, &userID, "")
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+type RecurringBookingHandler struct {
+	recurring service.RecurringBookingService
+	waitlist  service.WaitlistService
+}
+
+func NewRecurringBookingHandler(recurring service.RecurringBookingService, waitlist service.WaitlistService) *RecurringBookingHandler {
+	return &RecurringBookingHandler{recurring: recurring, waitlist: waitlist}
+}
+
+// CreateRecurringBookingRequest is a weekly booking of a table. The series
+// ends after ends_on or after occurrence_count weeks; exactly one of them is
+// required.
+type CreateRecurringBookingRequest struct {
+	RestaurantID uuid.UUID `json:"restaurant_id" binding:"required"`
+	TableID      uuid.UUID `json:"table_id" binding:"required"`
+	// Weekday counts from 0 for Sunday. It is a pointer so that Sunday is
+	// not taken for a missing value.
+	Weekday         *int   `json:"weekday" binding:"required,min=0,max=6" example:"5"`
+	StartTime       string `json:"start_time" binding:"required" example:"19:00"`
+	EndTime         string `json:"end_time" binding:"required" example:"21:00"`
+	GuestsCount     int    `json:"guests_count" binding:"required,min=1"`
+	StartsOn        string `json:"starts_on" binding:"required" example:"2030-06-07"`
+	EndsOn          string `json:"ends_on,omitempty" example:"2030-12-27"`
+	OccurrenceCount *int   `json:"occurrence_count,omitempty" binding:"omitempty,min=1" example:"12"`
+	SpecialNote     string `json:"special_note"`
+}
+
+// RecurringBookingResponse is a series with the occurrences expanded so
+// far. Dates and times of day are on the restaurant's clock.
+type RecurringBookingResponse struct {
+	ID              uuid.UUID                     `json:"id"`
+	RestaurantID    uuid.UUID                     `json:"restaurant_id"`
+	TableID         uuid.UUID                     `json:"table_id"`
+	Weekday         int                           `json:"weekday" example:"5"`
+	StartTime       string                        `json:"start_time" example:"19:00"`
+	EndTime         string                        `json:"end_time" example:"21:00"`
+	GuestsCount     int                           `json:"guests_count"`
+	StartsOn        string                        `json:"starts_on" example:"2030-06-07"`
+	EndsOn          string                        `json:"ends_on,omitempty" example:"2030-12-27"`
+	OccurrenceCount *int                          `json:"occurrence_count,omitempty"`
+	SpecialNote     string                        `json:"special_note,omitempty"`
+	Status          domain.RecurringBookingStatus `json:"status" example:"active"`
+	CancelledAt     *apitime.Time                 `json:"cancelled_at,omitempty" swaggertype:"string" format:"date-time"`
+	CreatedAt       apitime.Time                  `json:"created_at" swaggertype:"string" format:"date-time"`
+	Occurrences     []RecurringOccurrenceResponse `json:"occurrences"`
+}
+
+// RecurringOccurrenceResponse is one week of a series: booked as BookingID,
+// or skipped for SkipReason.
+type RecurringOccurrenceResponse struct {
+	StartTime  apitime.Time                     `json:"start_time" swaggertype:"string" format:"date-time" example:"2030-06-07T14:00:00Z"`
+	EndTime    apitime.Time                     `json:"end_time" swaggertype:"string" format:"date-time" example:"2030-06-07T16:00:00Z"`
+	Status     domain.RecurringOccurrenceStatus `json:"status" example:"skipped"`
+	BookingID  *uuid.UUID                       `json:"booking_id,omitempty"`
+	SkipReason string                           `json:"skip_reason,omitempty" example:"table is not available for the selected time"`
+}
+
+// CancelRecurringBookingResponse is a cancelled series with the number of
+// upcoming bookings cancelled along with it.
+type CancelRecurringBookingResponse struct {
+	RecurringBookingResponse
+	CancelledBookings int `json:"cancelled_bookings"`
+}
+
+func recurringBookingResponse(series *domain.RecurringBooking) RecurringBookingResponse {
+	resp := RecurringBookingResponse{
+		ID:              series.ID,
+		RestaurantID:    series.RestaurantID,
+		TableID:         series.TableID,
+		Weekday:         int(series.Weekday),
+		StartTime:       series.StartClock,
+		EndTime:         series.EndClock,
+		GuestsCount:     series.GuestsCount,
+		StartsOn:        series.StartsOn.Format("2006-01-02"),
+		OccurrenceCount: series.OccurrenceCount,
+		SpecialNote:     series.SpecialNote,
+		Status:          series.Status,
+		CreatedAt:       apitime.New(series.CreatedAt),
+		Occurrences:     make([]RecurringOccurrenceResponse, 0, len(series.Occurrences)),
+	}
+	if series.EndsOn != nil {
+		resp.EndsOn = series.EndsOn.Format("2006-01-02")
+	}
+	if series.CancelledAt != nil {
+		cancelledAt := apitime.New(*series.CancelledAt)
+		resp.CancelledAt = &cancelledAt
+	}
+	for _, occurrence := range series.Occurrences {
+		resp.Occurrences = append(resp.Occurrences, RecurringOccurrenceResponse{
+			StartTime:  apitime.New(occurrence.StartTime),
+			EndTime:    apitime.New(occurrence.EndTime),
+			Status:     occurrence.Status,
+			BookingID:  occurrence.BookingID,
+			SkipReason: occurrence.SkipReason,
+		})
+	}
+	return resp
+}
+
+// @Summary Create a recurring booking
+// @Description Books the table every weekday (0 is Sunday) from start_time to end_time, as HH:MM on the restaurant's clock, from starts_on until ends_on or for occurrence_count weeks. The first upcoming occurrence is checked like a booking. Occurrences are booked a few weeks ahead, confirmed and without a deposit; a week that cannot be booked, such as one the table is already taken, is skipped and listed with the reason.
+// @Tags Recurring bookings
+// @Accept json
+// @Produce json
+// @Param request body CreateRecurringBookingRequest true "Recurring booking"
+// @Success 201 {object} RecurringBookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/recurring-bookings [post]
+func (h *RecurringBookingHandler) CreateRecurringBooking(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateRecurringBookingRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		return
+	}
+	var endsOn *time.Time
+	if req.EndsOn != "" {
+		day, err := time.Parse("2006-01-02", req.EndsOn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+			return
+		}
+		endsOn = &day
+	}
+
+	series, err := h.recurring.Create(c.Request.Context(), service.CreateRecurringBookingRequest{
+		RestaurantID:    req.RestaurantID,
+		TableID:         req.TableID,
+		UserID:          userID,
+		Weekday:         time.Weekday(*req.Weekday),
+		StartClock:      req.StartTime,
+		EndClock:        req.EndTime,
+		GuestsCount:     req.GuestsCount,
+		StartsOn:        startsOn,
+		EndsOn:          endsOn,
+		OccurrenceCount: req.OccurrenceCount,
+		SpecialNote:     req.SpecialNote,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrTableNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		case errors.Is(err, service.ErrInvalidRecurrenceClock), errors.Is(err, service.ErrInvalidRecurrenceWeekday),
+			errors.Is(err, service.ErrRecurrenceEndRequired), errors.Is(err, service.ErrNoUpcomingOccurrence),
+			errors.Is(err, service.ErrAfterLastSeating), errors.Is(err, service.ErrOutsideWorkingHours),
+			errors.Is(err, service.ErrGuestsExceedCapacity), errors.Is(err, service.ErrDurationTooShort):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurringBookingResponse(series))
+}
+
+// @Summary List my recurring bookings
+// @Description Lists the caller's recurring bookings, newest first, each with the occurrences booked or skipped so far.
+// @Tags Recurring bookings
+// @Produce json
+// @Success 200 {array} RecurringBookingResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/recurring-bookings [get]
+func (h *RecurringBookingHandler) ListRecurringBookings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.recurring.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resp := make([]RecurringBookingResponse, 0, len(series))
+	for _, one := range series {
+		resp = append(resp, recurringBookingResponse(one))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Cancel a recurring booking
+// @Description Stops the caller's recurring booking and cancels its bookings that have not started yet. Past bookings are left as they are.
+// @Tags Recurring bookings
+// @Produce json
+// @Param id path string true "Recurring booking ID"
+// @Success 200 {object} CancelRecurringBookingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/recurring-bookings/{id}/cancel [post]
+func (h *RecurringBookingHandler) CancelRecurringBooking(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid recurring booking id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	cancellation, err := h.recurring.Cancel(c.Request.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRecurringBookingNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRecurringBookingCancelled):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	for _, booking := range cancellation.Bookings {
+		h.waitlist.SlotFreed(c.Request.Context(), booking)
+	}
+	c.JSON(http.StatusOK, CancelRecurringBookingResponse{
+		RecurringBookingResponse: recurringBookingResponse(cancellation.Series),
+		CancelledBookings:        len(cancellation.Bookings),
+	})
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRecurringBookingService struct {
+	service.RecurringBookingService
+	err    error
+	create *service.CreateRecurringBookingRequest
+	id     uuid.UUID
+	userID uuid.UUID
+	freed  []*domain.Booking
+}
+
+func (s *stubRecurringBookingService) Create(ctx context.Context, req service.CreateRecurringBookingRequest) (*domain.RecurringBooking, error) {
+	s.create = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	bookingID := uuid.New()
+	start := time.Date(2030, time.June, 7, 14, 0, 0, 0, time.UTC)
+	return &domain.RecurringBooking{
+		ID:              uuid.New(),
+		RestaurantID:    req.RestaurantID,
+		TableID:         req.TableID,
+		UserID:          req.UserID,
+		Weekday:         req.Weekday,
+		StartClock:      req.StartClock,
+		EndClock:        req.EndClock,
+		GuestsCount:     req.GuestsCount,
+		StartsOn:        req.StartsOn,
+		OccurrenceCount: req.OccurrenceCount,
+		Status:          domain.RecurringBookingStatusActive,
+		Occurrences: []domain.RecurringOccurrence{
+			{StartTime: start, EndTime: start.Add(2 * time.Hour), Status: domain.RecurringOccurrenceBooked, BookingID: &bookingID},
+			{StartTime: start.AddDate(0, 0, 7), EndTime: start.AddDate(0, 0, 7).Add(2 * time.Hour), Status: domain.RecurringOccurrenceSkipped, SkipReason: service.ErrTableNotAvailable.Error()},
+		},
+	}, nil
+}
+
+func (s *stubRecurringBookingService) List(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error) {
+	s.userID = userID
+	return []*domain.RecurringBooking{{ID: uuid.New(), UserID: userID, Status: domain.RecurringBookingStatusActive}}, s.err
+}
+
+func (s *stubRecurringBookingService) Cancel(ctx context.Context, id, userID uuid.UUID) (*service.RecurringCancellation, error) {
+	s.id, s.userID = id, userID
+	if s.err != nil {
+		return nil, s.err
+	}
+	cancelledAt := time.Now()
+	return &service.RecurringCancellation{
+		Series:   &domain.RecurringBooking{ID: id, UserID: userID, Status: domain.RecurringBookingStatusCancelled, CancelledAt: &cancelledAt},
+		Bookings: []*domain.Booking{{ID: uuid.New()}, {ID: uuid.New()}},
+	}, nil
+}
+
+func recurringBody(restaurantID, tableID uuid.UUID) string {
+	return `{"restaurant_id":"` + restaurantID.String() + `","table_id":"` + tableID.String() +
+		`","weekday":5,"start_time":"19:00","end_time":"21:00","guests_count":4,"starts_on":"2030-06-03","occurrence_count":12}`
+}
+
+func TestCreateRecurringBooking(t *testing.T) {
+	recurring := &stubRecurringBookingService{}
+	userID, restaurantID, tableID := uuid.New(), uuid.New(), uuid.New()
+
+	w := performAsUser(NewRecurringBookingHandler(recurring, &stubWaitlistService{}).CreateRecurringBooking, http.MethodPost, "/api/recurring-bookings",
+		"/api/recurring-bookings", &userID, recurringBody(restaurantID, tableID))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, recurring.create)
+	assert.Equal(t, userID, recurring.create.UserID)
+	assert.Equal(t, time.Friday, recurring.create.Weekday)
+	assert.Equal(t, "19:00", recurring.create.StartClock)
+	assert.Equal(t, time.Date(2030, time.June, 3, 0, 0, 0, 0, time.UTC), recurring.create.StartsOn)
+	assert.Nil(t, recurring.create.EndsOn)
+	require.NotNil(t, recurring.create.OccurrenceCount)
+	assert.Equal(t, 12, *recurring.create.OccurrenceCount)
+
+	var resp RecurringBookingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2030-06-03", resp.StartsOn)
+	require.Len(t, resp.Occurrences, 2)
+	assert.Equal(t, domain.RecurringOccurrenceSkipped, resp.Occurrences[1].Status)
+	assert.Equal(t, "table is not available for the selected time", resp.Occurrences[1].SkipReason)
+}
+
+func TestCreateRecurringBooking_Errors(t *testing.T) {
+	userID := uuid.New()
+	body := recurringBody(uuid.New(), uuid.New())
+	cases := map[string]struct {
+		userID *uuid.UUID
+		body   string
+		err    error
+		want   int
+	}{
+		"no token":          {nil, body, nil, http.StatusUnauthorized},
+		"no weekday":        {&userID, `{"restaurant_id":"` + uuid.NewString() + `","table_id":"` + uuid.NewString() + `","start_time":"19:00","end_time":"21:00","guests_count":4,"starts_on":"2030-06-03"}`, nil, http.StatusBadRequest},
+		"bad date":          {&userID, `{"restaurant_id":"` + uuid.NewString() + `","table_id":"` + uuid.NewString() + `","weekday":0,"start_time":"19:00","end_time":"21:00","guests_count":4,"starts_on":"03.06.2030","occurrence_count":2}`, nil, http.StatusBadRequest},
+		"unknown table":     {&userID, body, service.ErrTableNotFound, http.StatusNotFound},
+		"two ends":          {&userID, body, service.ErrRecurrenceEndRequired, http.StatusBadRequest},
+		"bad clock":         {&userID, body, service.ErrInvalidRecurrenceClock, http.StatusBadRequest},
+		"while closed":      {&userID, body, service.ErrOutsideWorkingHours, http.StatusBadRequest},
+		"connection failed": {&userID, body, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			recurring := &stubRecurringBookingService{err: tc.err}
+
+			w := performAsUser(NewRecurringBookingHandler(recurring, &stubWaitlistService{}).CreateRecurringBooking, http.MethodPost, "/api/recurring-bookings",
+				"/api/recurring-bookings", tc.userID, tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestListRecurringBookings(t *testing.T) {
+	recurring := &stubRecurringBookingService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewRecurringBookingHandler(recurring, &stubWaitlistService{}).ListRecurringBookings, http.MethodGet, "/api/recurring-bookings",
+		"/api/recurring-bookings", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, recurring.userID)
+	var resp []RecurringBookingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	assert.Empty(t, resp[0].Occurrences)
+}
+
+func TestCancelRecurringBooking(t *testing.T) {
+	recurring := &stubRecurringBookingService{}
+	waitlist := &stubWaitlistService{}
+	userID, id := uuid.New(), uuid.New()
+
+	w := performAsUser(NewRecurringBookingHandler(recurring, waitlist).CancelRecurringBooking, http.MethodPost, "/api/recurring-bookings/:id/cancel",
+		"/api/recurring-bookings/"+id.String()+"/cancel", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, id, recurring.id)
+	assert.Equal(t, userID, recurring.userID)
+	assert.Len(t, waitlist.freed, 2, "the cancelled tables go to the waitlist")
+
+	var resp CancelRecurringBookingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, domain.RecurringBookingStatusCancelled, resp.Status)
+	assert.Equal(t, 2, resp.CancelledBookings)
+}
+
+func TestCancelRecurringBooking_Errors(t *testing.T) {
+	cases := map[string]struct {
+		id   string
+		err  error
+		want int
+	}{
+		"bad id":            {"nope", nil, http.StatusBadRequest},
+		"unknown":           {uuid.NewString(), service.ErrRecurringBookingNotFound, http.StatusNotFound},
+		"already cancelled": {uuid.NewString(), service.ErrRecurringBookingCancelled, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			userID := uuid.New()
+			waitlist := &stubWaitlistService{}
+
+			w := performAsUser(NewRecurringBookingHandler(&stubRecurringBookingService{err: tc.err}, waitlist).CancelRecurringBooking, http.MethodPost, "/api/recurring-bookings/:id/cancel",
+				"/api/recurring-bookings/"+tc.id+"/cancel", &userID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+			assert.Empty(t, waitlist.freed)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"restaurant-booking/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReminderHandler struct {
+	reminderService service.ReminderService
+}
+
+func NewReminderHandler(reminderService service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminderService: reminderService}
+}
+
+// SendRemindersResponse counts the reminders a manual run sent.
+type SendRemindersResponse struct {
+	Sent int `json:"sent"`
+}
+
+// @Summary Send due booking reminders now
+// @Description Sends the reminders that are due without waiting for the next scheduled run. Reminders already sent are not sent again.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} SendRemindersResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/maintenance/send-reminders [post]
+func (h *ReminderHandler) SendReminders(c *gin.Context) {
+	sent, err := h.reminderService.SendDue(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, SendRemindersResponse{Sent: sent})
+}
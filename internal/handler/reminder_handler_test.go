@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReminderService struct {
+	sent int
+	err  error
+}
+
+func (s *stubReminderService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	return s.sent, s.err
+}
+
+func (s *stubReminderService) Start(ctx context.Context) {}
+
+func TestSendReminders(t *testing.T) {
+	adminID := uuid.New()
+
+	w := performAsUser(NewReminderHandler(&stubReminderService{sent: 3}).SendReminders, http.MethodPost,
+		"/api/admin/maintenance/send-reminders", "/api/admin/maintenance/send-reminders", &adminID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"sent":3}`, w.Body.String())
+}
+
+func TestSendReminders_Fails(t *testing.T) {
+	adminID := uuid.New()
+
+	w := performAsUser(NewReminderHandler(&stubReminderService{err: errors.New("connection refused")}).SendReminders, http.MethodPost,
+		"/api/admin/maintenance/send-reminders", "/api/admin/maintenance/send-reminders", &adminID, "")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// RescheduleHandler serves the reschedule links sent in booking reminders.
+// The token in the path is the only credential, so responses carry just
+// enough of the booking to pick a new time.
+type RescheduleHandler struct {
+	rescheduleService service.RescheduleService
+}
+
+func NewRescheduleHandler(rescheduleService service.RescheduleService) *RescheduleHandler {
+	return &RescheduleHandler{rescheduleService: rescheduleService}
+}
+
+// GetReschedule returns the booking behind the link and the next free slots
+// it can move to.
+func (h *RescheduleHandler) GetReschedule(c *gin.Context) {
+	preview, err := h.rescheduleService.Preview(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		writeRescheduleError(c, err)
+		return
+	}
+
+	alternatives := make([]RescheduleSlotResponse, len(preview.Alternatives))
+	for i, slot := range preview.Alternatives {
+		alternatives[i] = RescheduleSlotResponse{
+			StartTime: apitime.Time{Time: slot.StartTime},
+			EndTime:   apitime.Time{Time: slot.EndTime},
+		}
+	}
+
+	c.JSON(http.StatusOK, RescheduleLinkResponse{
+		Booking:              newRescheduleBookingResponse(preview.Booking),
+		ExpiresAt:            apitime.Time{Time: preview.ExpiresAt},
+		RemainingReschedules: preview.RemainingReschedules,
+		Alternatives:         alternatives,
+	})
+}
+
+// Reschedule moves the booking behind the link to new_start. The link
+// cannot be used again afterwards.
+func (h *RescheduleHandler) Reschedule(c *gin.Context) {
+	var req RescheduleBookingRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+
+	booking, err := h.rescheduleService.Reschedule(c.Request.Context(), c.Param("token"), req.NewStart.Time)
+	if err != nil {
+		writeRescheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRescheduleBookingResponse(booking))
+}
+
+func writeRescheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRescheduleLinkNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrRescheduleLinkExpired),
+		errors.Is(err, service.ErrRescheduleLinkUsed):
+		c.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRescheduleLimitReached),
+		errors.Is(err, service.ErrBookingNotReschedulable),
+		errors.Is(err, service.ErrRescheduleSlotTaken):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrInvalidRescheduleTime),
+		errors.Is(err, service.ErrRestaurantClosedAt),
+		errors.Is(err, service.ErrAfterLastSeating):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type RescheduleBookingRequest struct {
+	NewStart apitime.Time `json:"new_start" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+}
+
+type RescheduleLinkResponse struct {
+	Booking              RescheduleBookingResponse `json:"booking"`
+	ExpiresAt            apitime.Time              `json:"expires_at" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	RemainingReschedules int                       `json:"remaining_reschedules" example:"2"`
+	Alternatives         []RescheduleSlotResponse  `json:"alternatives"`
+}
+
+type RescheduleSlotResponse struct {
+	StartTime apitime.Time `json:"start_time" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime   apitime.Time `json:"end_time" swaggertype:"string" format:"date-time" example:"2024-06-01T21:00:00Z"`
+}
+
+type RescheduleBookingResponse struct {
+	ID              uuid.UUID            `json:"id"`
+	RestaurantID    uuid.UUID            `json:"restaurant_id"`
+	RestaurantName  string               `json:"restaurant_name,omitempty"`
+	StartTime       apitime.Time         `json:"start_time" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime         apitime.Time         `json:"end_time" swaggertype:"string" format:"date-time" example:"2024-06-01T21:00:00Z"`
+	GuestsCount     int                  `json:"guests_count" example:"2"`
+	Status          domain.BookingStatus `json:"status" example:"confirmed"`
+	RescheduleCount int                  `json:"reschedule_count" example:"1"`
+}
+
+func newRescheduleBookingResponse(booking *domain.Booking) RescheduleBookingResponse {
+	resp := RescheduleBookingResponse{
+		ID:              booking.ID,
+		RestaurantID:    booking.RestaurantID,
+		StartTime:       apitime.Time{Time: booking.StartTime},
+		EndTime:         apitime.Time{Time: booking.EndTime},
+		GuestsCount:     booking.GuestsCount,
+		Status:          booking.Status,
+		RescheduleCount: booking.RescheduleCount,
+	}
+	if booking.Restaurant != nil {
+		resp.RestaurantName = booking.Restaurant.Name
+	}
+	return resp
+}
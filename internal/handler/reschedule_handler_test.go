@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRescheduleService struct {
+	service.RescheduleService
+	booking  *domain.Booking
+	err      error
+	token    string
+	newStart time.Time
+}
+
+func (s *stubRescheduleService) Preview(ctx context.Context, token string) (*service.ReschedulePreview, error) {
+	s.token = token
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &service.ReschedulePreview{
+		Booking:              s.booking,
+		ExpiresAt:            s.booking.StartTime,
+		RemainingReschedules: 2,
+		Alternatives:         []service.RescheduleSlot{{StartTime: s.booking.StartTime.Add(time.Hour), EndTime: s.booking.EndTime.Add(time.Hour)}},
+	}, nil
+}
+
+func (s *stubRescheduleService) Reschedule(ctx context.Context, token string, newStart time.Time) (*domain.Booking, error) {
+	s.token, s.newStart = token, newStart
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.booking.StartTime = newStart
+	s.booking.RescheduleCount++
+	return s.booking, nil
+}
+
+func rescheduleStubBooking() *domain.Booking {
+	start := time.Date(2024, time.June, 1, 19, 0, 0, 0, time.UTC)
+	return &domain.Booking{
+		ID:          uuid.New(),
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		GuestsCount: 2,
+		Status:      domain.BookingStatusConfirmed,
+		Restaurant:  &domain.Restaurant{Name: "Osteria", Phone: "+77010000000"},
+		User:        &domain.User{Email: "guest@example.com"},
+	}
+}
+
+func TestGetReschedule(t *testing.T) {
+	stub := &stubRescheduleService{booking: rescheduleStubBooking()}
+
+	w := performAsUser(NewRescheduleHandler(stub).GetReschedule, http.MethodGet, "/api/reschedule/:token", "/api/reschedule/secret", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "secret", stub.token)
+	var resp RescheduleLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Osteria", resp.Booking.RestaurantName)
+	assert.Equal(t, 2, resp.RemainingReschedules)
+	require.Len(t, resp.Alternatives, 1)
+	assert.True(t, resp.Alternatives[0].StartTime.Equal(stub.booking.StartTime.Add(time.Hour)))
+	// The link is not a login, so the guest's details stay out of it.
+	assert.NotContains(t, w.Body.String(), "guest@example.com")
+}
+
+func TestRescheduleBooking(t *testing.T) {
+	stub := &stubRescheduleService{booking: rescheduleStubBooking()}
+
+	w := performAsUser(NewRescheduleHandler(stub).Reschedule, http.MethodPost, "/api/reschedule/:token", "/api/reschedule/secret",
+		nil, `{"new_start":"2024-06-02T20:00:00+05:00"}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "secret", stub.token)
+	assert.True(t, stub.newStart.Equal(time.Date(2024, time.June, 2, 15, 0, 0, 0, time.UTC)))
+	var resp RescheduleBookingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.RescheduleCount)
+}
+
+func TestRescheduleBooking_BadRequest(t *testing.T) {
+	for name, body := range map[string]string{
+		"no new start":   `{}`,
+		"no zone offset": `{"new_start":"2024-06-02T20:00:00"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubRescheduleService{booking: rescheduleStubBooking()}
+
+			w := performAsUser(NewRescheduleHandler(stub).Reschedule, http.MethodPost, "/api/reschedule/:token", "/api/reschedule/secret", nil, body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Empty(t, stub.token)
+		})
+	}
+}
+
+func TestRescheduleBooking_Errors(t *testing.T) {
+	cases := map[error]int{
+		service.ErrRescheduleLinkNotFound: http.StatusNotFound,
+		service.ErrRescheduleLinkExpired:  http.StatusGone,
+		service.ErrRescheduleLinkUsed:     http.StatusGone,
+		fmt.Errorf("%w, please contact Osteria at +77010000000", service.ErrRescheduleLimitReached): http.StatusConflict,
+		service.ErrRescheduleSlotTaken:                                            http.StatusConflict,
+		service.ErrInvalidRescheduleTime:                                          http.StatusBadRequest,
+		fmt.Errorf("%w, latest start time is 22:00", service.ErrAfterLastSeating): http.StatusBadRequest,
+	}
+	for err, want := range cases {
+		t.Run(err.Error(), func(t *testing.T) {
+			stub := &stubRescheduleService{booking: rescheduleStubBooking(), err: err}
+
+			w := performAsUser(NewRescheduleHandler(stub).Reschedule, http.MethodPost, "/api/reschedule/:token", "/api/reschedule/secret",
+				nil, `{"new_start":"2024-06-02T20:00:00Z"}`)
+
+			assert.Equal(t, want, w.Code)
+			assert.Contains(t, w.Body.String(), err.Error())
+		})
+	}
+}
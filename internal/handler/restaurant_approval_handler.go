@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RestaurantApprovalHandler struct {
+	approvalService service.RestaurantApprovalService
+}
+
+func NewRestaurantApprovalHandler(approvalService service.RestaurantApprovalService) *RestaurantApprovalHandler {
+	return &RestaurantApprovalHandler{approvalService: approvalService}
+}
+
+// @Summary List restaurants by approval status
+// @Description Lists restaurants in the given status, oldest first. status defaults to pending.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending, approved or rejected"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/restaurants [get]
+func (h *RestaurantApprovalHandler) ListRestaurants(c *gin.Context) {
+	status := domain.RestaurantStatusPending
+	if s := c.Query("status"); s != "" {
+		status = domain.RestaurantStatus(s)
+	}
+
+	params, ok := paginationParams(c, 20)
+	if !ok {
+		return
+	}
+
+	restaurants, total, err := h.approvalService.ListRestaurants(c.Request.Context(), status, params.Limit, params.Offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRestaurantStatus):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{Items: restaurants, Total: total, PaginationParams: params})
+}
+
+// @Summary Approve a restaurant
+// @Description Lists the restaurant publicly and notifies its owner.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} domain.Restaurant
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/admin/restaurants/{id}/approve [post]
+func (h *RestaurantApprovalHandler) ApproveRestaurant(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	restaurant, err := h.approvalService.Approve(c.Request.Context(), id, adminID)
+	if err != nil {
+		writeRestaurantApprovalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurant)
+}
+
+// @Summary Reject a restaurant
+// @Description Keeps the restaurant out of public listings and sends the reason to its owner.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param request body RejectRestaurantRequest true "Reason"
+// @Success 200 {object} domain.Restaurant
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/admin/restaurants/{id}/reject [post]
+func (h *RestaurantApprovalHandler) RejectRestaurant(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req RejectRestaurantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	restaurant, err := h.approvalService.Reject(c.Request.Context(), id, adminID, req.Reason)
+	if err != nil {
+		writeRestaurantApprovalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurant)
+}
+
+func writeRestaurantApprovalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrRejectionReasonRequired):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRestaurantStatusUnchanged),
+		errors.Is(err, service.ErrRestaurantStatusChanged):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type RejectRestaurantRequest struct {
+	Reason string `json:"reason" binding:"required" example:"The address could not be verified"`
+}
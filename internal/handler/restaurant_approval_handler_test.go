@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubApprovalService struct {
+	service.RestaurantApprovalService
+	status domain.RestaurantStatus
+	reason string
+	err    error
+}
+
+func (s *stubApprovalService) ListRestaurants(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, int64, error) {
+	s.status = status
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return []*domain.Restaurant{{ID: uuid.New(), Status: status}}, 1, nil
+}
+
+func (s *stubApprovalService) Approve(ctx context.Context, restaurantID, adminID uuid.UUID) (*domain.Restaurant, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Restaurant{ID: restaurantID, Status: domain.RestaurantStatusApproved, ReviewedBy: &adminID}, nil
+}
+
+func (s *stubApprovalService) Reject(ctx context.Context, restaurantID, adminID uuid.UUID, reason string) (*domain.Restaurant, error) {
+	s.reason = reason
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Restaurant{ID: restaurantID, Status: domain.RestaurantStatusRejected, RejectionReason: &reason}, nil
+}
+
+func TestListRestaurantsForApproval_DefaultsToPending(t *testing.T) {
+	adminID := uuid.New()
+	stub := &stubApprovalService{}
+
+	w := performAsUser(NewRestaurantApprovalHandler(stub).ListRestaurants, http.MethodGet, "/api/admin/restaurants",
+		"/api/admin/restaurants", &adminID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.RestaurantStatusPending, stub.status)
+	var resp struct {
+		Items []domain.Restaurant `json:"items"`
+		Total int64               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, int64(1), resp.Total)
+}
+
+func TestListRestaurantsForApproval_InvalidStatus(t *testing.T) {
+	adminID := uuid.New()
+	stub := &stubApprovalService{err: service.ErrInvalidRestaurantStatus}
+
+	w := performAsUser(NewRestaurantApprovalHandler(stub).ListRestaurants, http.MethodGet, "/api/admin/restaurants",
+		"/api/admin/restaurants?status=archived", &adminID, "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, domain.RestaurantStatus("archived"), stub.status)
+}
+
+func TestRejectRestaurant(t *testing.T) {
+	adminID, restaurantID := uuid.New(), uuid.New()
+	stub := &stubApprovalService{}
+
+	w := performAsUser(NewRestaurantApprovalHandler(stub).RejectRestaurant, http.MethodPost, "/api/admin/restaurants/:id/reject",
+		"/api/admin/restaurants/"+restaurantID.String()+"/reject", &adminID, `{"reason":"Address could not be verified"}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Address could not be verified", stub.reason)
+
+	w = performAsUser(NewRestaurantApprovalHandler(stub).RejectRestaurant, http.MethodPost, "/api/admin/restaurants/:id/reject",
+		"/api/admin/restaurants/"+restaurantID.String()+"/reject", &adminID, `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestApproveRestaurant_Errors(t *testing.T) {
+	adminID := uuid.New()
+
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"not found":         {service.ErrRestaurantNotFound, http.StatusNotFound},
+		"already approved":  {service.ErrRestaurantStatusUnchanged, http.StatusConflict},
+		"decided meanwhile": {service.ErrRestaurantStatusChanged, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := performAsUser(NewRestaurantApprovalHandler(&stubApprovalService{err: tc.err}).ApproveRestaurant, http.MethodPost,
+				"/api/admin/restaurants/:id/approve", "/api/admin/restaurants/"+uuid.NewString()+"/approve", &adminID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+type geoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+type restaurantField struct {
+	columns []string
+	value   func(restaurant *domain.Restaurant, origin *geoPoint) interface{}
+}
+
+// restaurantCardFields is the allow-list for the fields= query parameter on
+// restaurant lists, with the columns each field needs from the database.
+var restaurantCardFields = map[string]restaurantField{
+	"id": {
+		columns: []string{"id"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.ID },
+	},
+	"name": {
+		columns: []string{"name"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.Name },
+	},
+	"main_image": {
+		value: func(r *domain.Restaurant, _ *geoPoint) interface{} {
+			for _, image := range r.Images {
+				if image.IsMain {
+					return image.CloudinaryURL
+				}
+			}
+			return nil
+		},
+	},
+	"rating": {
+		columns: []string{"rating"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.Rating },
+	},
+	"price": {
+		columns: []string{"average_price"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.AveragePrice },
+	},
+	"cuisine": {
+		columns: []string{"cuisine_type"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.CuisineType },
+	},
+	"address": {
+		columns: []string{"address"},
+		value:   func(r *domain.Restaurant, _ *geoPoint) interface{} { return r.Address },
+	},
+	"distance": {
+		columns: []string{"latitude", "longitude"},
+		value: func(r *domain.Restaurant, origin *geoPoint) interface{} {
+			if origin == nil || r.Latitude == nil || r.Longitude == nil {
+				return nil
+			}
+			return distanceKm(*origin, geoPoint{Latitude: *r.Latitude, Longitude: *r.Longitude})
+		},
+	},
+}
+
+// parseRestaurantFields validates a comma-separated fields= value against the
+// allow-list and returns the requested fields in order, without duplicates.
+func parseRestaurantFields(raw string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := restaurantCardFields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must not be empty")
+	}
+
+	return fields, nil
+}
+
+func restaurantFieldColumns(fields []string) (columns []string, withMainImage bool) {
+	for _, name := range fields {
+		if name == "main_image" {
+			withMainImage = true
+		}
+		columns = append(columns, restaurantCardFields[name].columns...)
+	}
+	return columns, withMainImage
+}
+
+// toRestaurantCards builds the requested fields of each entry. Sponsored
+// cards also carry sponsored: true and their placement_id.
+func toRestaurantCards(ranked []service.RankedRestaurant, fields []string, origin *geoPoint) []map[string]interface{} {
+	cards := make([]map[string]interface{}, len(ranked))
+	for i, entry := range ranked {
+		card := make(map[string]interface{}, len(fields)+2)
+		for _, name := range fields {
+			card[name] = restaurantCardFields[name].value(entry.Restaurant, origin)
+		}
+		if entry.Placement != nil {
+			card["sponsored"] = true
+			card["placement_id"] = entry.Placement.ID
+		}
+		cards[i] = card
+	}
+	return cards
+}
+
+func distanceKm(a, b geoPoint) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return math.Round(2*earthRadiusKm*math.Asin(math.Sqrt(h))*100) / 100
+}
@@ -1,51 +1,123 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"restaurant-booking/pkg/imagestorage"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// availabilityBudget bounds how long GetRestaurant waits for the optional
+// availability block before answering without it.
+const availabilityBudget = 300 * time.Millisecond
+
+// maxImageUploadBytes caps the size of a restaurant image upload.
+const maxImageUploadBytes = 10 << 20
+
+// maxBulkImages caps how many files one AddImages request may carry.
+const maxBulkImages = 10
+
+// allowedImageTypes are the sniffed content types AddImage accepts.
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
 type RestaurantHandler struct {
-	restaurantService service.RestaurantService
+	restaurantService   service.RestaurantService
+	availabilityService service.AvailabilityService
+	busynessService     service.BusynessService
+	favoriteService     service.FavoriteService
+	sponsoredService    service.SponsoredPlacementService
 }
 
-func NewRestaurantHandler(restaurantService service.RestaurantService) *RestaurantHandler {
-	return &RestaurantHandler{restaurantService: restaurantService}
+func NewRestaurantHandler(restaurantService service.RestaurantService, availabilityService service.AvailabilityService, busynessService service.BusynessService, favoriteService service.FavoriteService, sponsoredService service.SponsoredPlacementService) *RestaurantHandler {
+	return &RestaurantHandler{
+		restaurantService:   restaurantService,
+		availabilityService: availabilityService,
+		busynessService:     busynessService,
+		favoriteService:     favoriteService,
+		sponsoredService:    sponsoredService,
+	}
 }
 
 func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req CreateRestaurantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	force := false
+	if f := c.Query("force"); f != "" {
+		parsed, err := strconv.ParseBool(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid force value"})
+			return
+		}
+		force = parsed
+	}
+
 	serviceReq := service.CreateRestaurantRequest{
-		Name:                req.Name,
-		Address:             req.Address,
-		Latitude:            req.Latitude,
-		Longitude:           req.Longitude,
-		Description:         req.Description,
-		Phone:               req.Phone,
-		Instagram:           req.Instagram,
-		Website:             req.Website,
-		CuisineType:         req.CuisineType,
-		AveragePrice:        req.AveragePrice,
-		MaxCombinableTables: req.MaxCombinableTables,
-		WorkingHours:        req.WorkingHours,
-	}
-
-	restaurant, err := h.restaurantService.CreateRestaurant(c.Request.Context(), req.OwnerID, serviceReq)
+		Name:                     req.Name,
+		Address:                  req.Address,
+		Latitude:                 req.Latitude,
+		Longitude:                req.Longitude,
+		Description:              req.Description,
+		Phone:                    req.Phone,
+		Instagram:                req.Instagram,
+		Website:                  req.Website,
+		CuisineType:              req.CuisineType,
+		AveragePrice:             req.AveragePrice,
+		MaxCombinableTables:      req.MaxCombinableTables,
+		WorkingHours:             req.WorkingHours,
+		LastSeatingOffsetMinutes: req.LastSeatingOffsetMinutes,
+		MaxConcurrentGuests:      req.MaxConcurrentGuests,
+		CancellationPolicy:       req.CancellationPolicy,
+		BookingRules:             req.BookingRules,
+		Timezone:                 req.Timezone,
+		Force:                    force,
+	}
+
+	restaurant, err := h.restaurantService.CreateRestaurant(c.Request.Context(), ownerID, serviceReq)
 	if err != nil {
+		var duplicate *service.DuplicateRestaurantError
 		switch {
+		case errors.As(err, &duplicate):
+			c.JSON(http.StatusConflict, DuplicateRestaurantResponse{
+				Error:                   "a restaurant with the same " + string(duplicate.Match) + " already exists; pass force=true to create it anyway",
+				ConflictingRestaurantID: duplicate.RestaurantID,
+				Match:                   duplicate.Match,
+			})
 		case errors.Is(err, service.ErrInvalidRestaurantName):
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "restaurant name cannot be empty"})
+		case errors.Is(err, service.ErrInvalidWorkingHours), errors.Is(err, service.ErrInvalidCancellationPolicy),
+			errors.Is(err, service.ErrInvalidBookingRules), errors.Is(err, service.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantSlugTaken):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		}
@@ -56,13 +128,166 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 }
 
 func (h *RestaurantHandler) GetRestaurant(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+	var checkAt *time.Time
+	guests := 0
+
+	if at := c.Query("check_availability_at"); at != "" {
+		parsed, err := apitime.Parse(at)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid check_availability_at format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
+			return
+		}
+		checkAt = &parsed.Time
+	}
+	if g := c.Query("guests"); g != "" {
+		if checkAt == nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "guests requires check_availability_at"})
+			return
+		}
+		parsed, err := strconv.Atoi(g)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "guests must be a positive integer"})
+			return
+		}
+		guests = parsed
+	}
+
+	now := time.Now()
+	busynessDay := now
+	if d := c.Query("busyness_date"); d != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", d, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid busyness_date format, use YYYY-MM-DD"})
+			return
+		}
+		busynessDay = parsed
+	}
+
+	// The path names the restaurant by ID or by slug. Slugs never parse as
+	// UUIDs.
+	var restaurant *domain.Restaurant
+	var err error
+	if id, parseErr := uuid.Parse(c.Param("id")); parseErr == nil {
+		restaurant, err = h.restaurantService.GetRestaurant(c.Request.Context(), id)
+	} else {
+		restaurant, err = h.restaurantService.GetRestaurantBySlug(c.Request.Context(), c.Param("id"))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	// Until an admin approves it, only its owner and admins see a restaurant.
+	if restaurant.Status != domain.RestaurantStatusApproved {
+		userID, _ := c.Get("user_id")
+		role, _ := c.Get("user_role")
+		if userID != restaurant.OwnerID && role != domain.UserRoleAdmin {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+			return
+		}
+	}
+
+	if !restaurant.IsActive {
+		c.JSON(http.StatusOK, restaurant)
+		return
+	}
+
+	// Errors in the optional blocks only drop the block; the details still matter.
+	response := RestaurantDetailsResponse{Restaurant: restaurant}
+	if busyness, err := h.busynessService.ForDay(c.Request.Context(), restaurant.ID, busynessDay); err == nil {
+		response.Busyness = toBusynessBlock(busyness, now)
+	}
+
+	// GetRestaurant is public; is_favorite is only known for signed-in users.
+	if value, ok := c.Get("user_id"); ok {
+		if userID, ok := value.(uuid.UUID); ok {
+			if favorite, err := h.favoriteService.IsFavorite(c.Request.Context(), userID, restaurant.ID); err == nil {
+				response.IsFavorite = &favorite
+			}
+		}
+	}
+
+	if checkAt != nil {
+		availability, err := h.checkAvailability(c.Request.Context(), restaurant, *checkAt, guests)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			response.AvailabilityTimeout = true
+		case err == nil:
+			response.Availability = toAvailabilityBlock(availability)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkAvailability runs the availability check within availabilityBudget and
+// returns context.DeadlineExceeded once the budget is spent, even if the
+// underlying queries have not noticed the cancellation yet.
+func (h *RestaurantHandler) checkAvailability(ctx context.Context, restaurant *domain.Restaurant, at time.Time, guests int) (*service.RestaurantAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, availabilityBudget)
+	defer cancel()
+
+	type result struct {
+		availability *service.RestaurantAvailability
+		err          error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		availability, err := h.availabilityService.CheckRestaurantAvailability(ctx, restaurant, at, guests)
+		done <- result{availability: availability, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil {
+			return nil, context.DeadlineExceeded
+		}
+		return r.availability, r.err
+	case <-ctx.Done():
+		return nil, context.DeadlineExceeded
+	}
+}
+
+// @Summary Availability calendar
+// @Description Lists the slots of each table that fits the party on date. Every table has its own grid: slots are slot_granularity_minutes apart from opening time up to the last seating, and a slot is available when a booking of the table's min_duration_minutes starting then is free.
+// @Tags Restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param date query string true "Day, as YYYY-MM-DD"
+// @Param guests query int false "Party size"
+// @Success 200 {object} AvailabilityCalendarResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/availability [get]
+func (h *RestaurantHandler) GetAvailabilityCalendar(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
 		return
 	}
 
+	day, err := time.ParseInLocation("2006-01-02", c.Query("date"), time.Now().Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	guests := 0
+	if g := c.Query("guests"); g != "" {
+		parsed, err := strconv.Atoi(g)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "guests must be a positive integer"})
+			return
+		}
+		guests = parsed
+	}
+
 	restaurant, err := h.restaurantService.GetRestaurant(c.Request.Context(), id)
 	if err != nil {
 		switch {
@@ -73,11 +298,219 @@ func (h *RestaurantHandler) GetRestaurant(c *gin.Context) {
 		}
 		return
 	}
+	if !restaurant.IsActive {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		return
+	}
 
-	c.JSON(http.StatusOK, restaurant)
+	calendar, err := h.availabilityService.Calendar(c.Request.Context(), restaurant, day, guests)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAvailabilityCalendar(day, calendar))
 }
 
+func toAvailabilityCalendar(day time.Time, calendar []service.TableSlots) AvailabilityCalendarResponse {
+	response := AvailabilityCalendarResponse{
+		Date:   day.Format("2006-01-02"),
+		Tables: make([]TableCalendar, len(calendar)),
+	}
+	for i, table := range calendar {
+		slots := make([]CalendarSlot, len(table.Slots))
+		for j, slot := range table.Slots {
+			slots[j] = CalendarSlot{StartTime: apitime.Time{Time: slot.Start}, Available: slot.Available}
+		}
+		response.Tables[i] = TableCalendar{
+			TableID:                table.Table.ID,
+			TableNumber:            table.Table.TableNumber,
+			LocationType:           table.Table.LocationType,
+			MinCapacity:            table.Table.MinCapacity,
+			MaxCapacity:            table.Table.MaxCapacity,
+			MinDurationMinutes:     table.Rules.MinDurationMinutes,
+			SlotGranularityMinutes: table.Rules.SlotGranularityMinutes,
+			DepositAmount:          table.Table.DepositAmount,
+			MinSpend:               table.Table.MinSpend,
+			Slots:                  slots,
+		}
+	}
+	return response
+}
+
+// ListRestaurants serves GET /api/restaurants. sort is one of created_at
+// (the default), rating or price, the keys repository.RestaurantSort
+// allow-lists; order is asc or desc (the default).
 func (h *RestaurantHandler) ListRestaurants(c *gin.Context) {
+	page, ok := paginationParams(c, 10)
+	if !ok {
+		return
+	}
+
+	filter, ok := restaurantListFilter(c)
+	if !ok {
+		return
+	}
+
+	if rawFields, ok := c.GetQuery("fields"); ok {
+		h.listRestaurantCards(c, rawFields, filter, page)
+		return
+	}
+
+	restaurants, err := h.restaurantService.GetRestaurants(c.Request.Context(), filter, page.Limit, page.Offset)
+	if err != nil {
+		respondListRestaurantsError(c, err)
+		return
+	}
+	total, ok := h.countRestaurants(c, filter)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Items:            toRestaurantListItems(h.rankRestaurants(c, restaurants, nil, page.Offset)),
+		Total:            total,
+		PaginationParams: page,
+	})
+}
+
+// countRestaurants counts the restaurants a list pages through. Sponsored
+// results are not counted. ok is false once an error response has been
+// written.
+func (h *RestaurantHandler) countRestaurants(c *gin.Context, filter repository.RestaurantFilter) (int64, bool) {
+	total, err := h.restaurantService.CountRestaurants(c.Request.Context(), filter)
+	if err != nil {
+		respondListRestaurantsError(c, err)
+		return 0, false
+	}
+	return total, true
+}
+
+// rankRestaurants blends sponsored results into the first page of a list,
+// scoped to the city query parameter and the cuisine the list is filtered
+// by. Later pages are returned as they are, so the first page can hold up
+// to service.MaxSponsoredResults more entries than limit.
+func (h *RestaurantHandler) rankRestaurants(c *gin.Context, organic []*domain.Restaurant, cuisineType *domain.CuisineType, offset int) []service.RankedRestaurant {
+	if offset > 0 {
+		ranked := make([]service.RankedRestaurant, len(organic))
+		for i, restaurant := range organic {
+			ranked[i] = service.RankedRestaurant{Restaurant: restaurant}
+		}
+		return ranked
+	}
+	scope := repository.SponsoredScope{City: strings.TrimSpace(c.Query("city")), CuisineType: cuisineType}
+	return h.sponsoredService.Blend(c.Request.Context(), organic, scope)
+}
+
+// RestaurantListItem is a restaurant in a list. Sponsored results are
+// flagged and carry the placement that impressions and clicks are reported
+// against.
+type RestaurantListItem struct {
+	*domain.Restaurant
+	Sponsored   bool       `json:"sponsored"`
+	PlacementID *uuid.UUID `json:"placement_id,omitempty"`
+}
+
+func toRestaurantListItems(ranked []service.RankedRestaurant) []RestaurantListItem {
+	items := make([]RestaurantListItem, len(ranked))
+	for i, entry := range ranked {
+		items[i] = RestaurantListItem{Restaurant: entry.Restaurant, Sponsored: entry.Sponsored()}
+		if entry.Placement != nil {
+			items[i].PlacementID = &entry.Placement.ID
+		}
+	}
+	return items
+}
+
+// restaurantListFilter reads open_now, min_price, max_price, sort and order.
+// ok is false once a 400 has been written for an invalid value. Unknown
+// sort keys are left for the service to reject.
+func restaurantListFilter(c *gin.Context) (repository.RestaurantFilter, bool) {
+	var filter repository.RestaurantFilter
+
+	openAt, ok := openNowFilter(c)
+	if !ok {
+		return filter, false
+	}
+	filter.OpenAt = openAt
+
+	for _, bound := range []struct {
+		param string
+		value **int
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+	} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		price, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid " + bound.param})
+			return filter, false
+		}
+		*bound.value = &price
+	}
+
+	filter.Sort = repository.RestaurantSort(c.Query("sort"))
+	switch c.Query("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "order must be asc or desc"})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+func respondListRestaurantsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSort), errors.Is(err, service.ErrInvalidPriceRange):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// openNowFilter reads the open_now query parameter. It returns the current
+// time when open_now=true and nil when the filter is off. ok is false once
+// a 400 has been written for an invalid value.
+func openNowFilter(c *gin.Context) (*time.Time, bool) {
+	raw := c.Query("open_now")
+	if raw == "" {
+		return nil, true
+	}
+	openNow, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid open_now value"})
+		return nil, false
+	}
+	if !openNow {
+		return nil, true
+	}
+	now := time.Now()
+	return &now, true
+}
+
+// SearchRestaurants serves GET /api/restaurants/search. Like the list, it
+// returns only the cards' requested fields when fields is given.
+func (h *RestaurantHandler) SearchRestaurants(c *gin.Context) {
+	var fields []string
+	var origin *geoPoint
+	if rawFields, ok := c.GetQuery("fields"); ok {
+		var err error
+		if fields, err = parseRestaurantFields(rawFields); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if origin, ok = cardOrigin(c); !ok {
+			return
+		}
+	}
+
 	limit := 10
 	offset := 0
 
@@ -88,32 +521,173 @@ func (h *RestaurantHandler) ListRestaurants(c *gin.Context) {
 		fmt.Sscanf(o, "%d", &offset)
 	}
 
-	restaurants, err := h.restaurantService.GetRestaurants(c.Request.Context(), limit, offset)
+	var cuisineType *domain.CuisineType
+	if cuisine := c.Query("cuisine"); cuisine != "" {
+		value := domain.CuisineType(cuisine)
+		cuisineType = &value
+	}
+
+	var minRating float64
+	if raw := c.Query("min_rating"); raw != "" {
+		if _, err := fmt.Sscanf(raw, "%g", &minRating); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid min_rating"})
+			return
+		}
+	}
+
+	openAt, ok := openNowFilter(c)
+	if !ok {
+		return
+	}
+
+	var restaurants []*domain.Restaurant
+	var err error
+	if cuisineType == nil && c.Query("min_rating") == "" {
+		restaurants, err = h.restaurantService.GetRestaurants(c.Request.Context(), repository.RestaurantFilter{OpenAt: openAt}, limit, offset)
+	} else {
+		restaurants, err = h.restaurantService.SearchRestaurants(c.Request.Context(), cuisineType, minRating, openAt, limit, offset)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCuisineType), errors.Is(err, service.ErrInvalidMinRating):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	ranked := h.rankRestaurants(c, restaurants, cuisineType, offset)
+	if fields != nil {
+		c.JSON(http.StatusOK, toRestaurantCards(ranked, fields, origin))
+		return
+	}
+	c.JSON(http.StatusOK, toRestaurantListItems(ranked))
+}
+
+// cuisinesMaxAge is how long clients may reuse the cuisine list before
+// revalidating it with its ETag.
+const cuisinesMaxAge = 5 * time.Minute
+
+// @Summary List cuisine types
+// @Description Lists every cuisine type with the number of active restaurants serving it. The response carries an ETag and honours If-None-Match.
+// @Tags Restaurants
+// @Produce json
+// @Success 200 {array} repository.CuisineCount
+// @Success 304
+// @Router /api/restaurants/cuisines [get]
+func (h *RestaurantHandler) ListCuisines(c *gin.Context) {
+	cuisines, err := h.restaurantService.ListCuisines(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	respondWithETag(c, cuisinesMaxAge, cuisines)
+}
+
+// defaultNearbyRadiusKm is used when radius_km is not given.
+const defaultNearbyRadiusKm = 5.0
+
+func (h *RestaurantHandler) NearbyRestaurants(c *gin.Context) {
+	limit := 10
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+
+	var lat, lng float64
+	if _, err := fmt.Sscanf(c.Query("lat")+" "+c.Query("lng"), "%g %g", &lat, &lng); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lat and lng are required"})
+		return
+	}
+
+	radiusKm := defaultNearbyRadiusKm
+	if raw := c.Query("radius_km"); raw != "" {
+		if _, err := fmt.Sscanf(raw, "%g", &radiusKm); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid radius_km"})
+			return
+		}
+	}
+
+	restaurants, err := h.restaurantService.NearbyRestaurants(c.Request.Context(), lat, lng, radiusKm, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCoordinates), errors.Is(err, service.ErrInvalidRadius):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, restaurants)
 }
 
-func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+// cardOrigin reads the lat and lng that restaurant cards measure distance
+// from, nil when neither is given. ok is false once a 400 has been written.
+func cardOrigin(c *gin.Context) (*geoPoint, bool) {
+	lat, lng := c.Query("lat"), c.Query("lng")
+	if lat == "" && lng == "" {
+		return nil, true
+	}
+	var point geoPoint
+	if _, err := fmt.Sscanf(lat+" "+lng, "%g %g", &point.Latitude, &point.Longitude); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid lat/lng"})
+		return nil, false
+	}
+	return &point, true
+}
+
+func (h *RestaurantHandler) listRestaurantCards(c *gin.Context, rawFields string, filter repository.RestaurantFilter, page PaginationParams) {
+	fields, err := parseRestaurantFields(rawFields)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
+	origin, ok := cardOrigin(c)
+	if !ok {
 		return
 	}
 
-	ownerID, err := uuid.Parse(ownerIDStr)
+	columns, withMainImage := restaurantFieldColumns(fields)
+	// Blending needs the IDs to keep a sponsored restaurant from appearing
+	// twice on a page.
+	if !slices.Contains(columns, "id") {
+		columns = append(columns, "id")
+	}
+	restaurants, err := h.restaurantService.GetRestaurantsWithColumns(c.Request.Context(), columns, withMainImage, filter, page.Limit, page.Offset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+		respondListRestaurantsError(c, err)
+		return
+	}
+	total, ok := h.countRestaurants(c, filter)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Items:            toRestaurantCards(h.rankRestaurants(c, restaurants, nil, page.Offset), fields, origin),
+		Total:            total,
+		PaginationParams: page,
+	})
+}
+
+func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -124,11 +698,25 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 	}
 
 	serviceReq := service.UpdateRestaurantRequest{
-		Name:        req.Name,
-		Address:     req.Address,
-		Description: req.Description,
-		Phone:       req.Phone,
-		IsActive:    req.IsActive,
+		Name:                     req.Name,
+		Address:                  req.Address,
+		Latitude:                 req.Latitude,
+		Longitude:                req.Longitude,
+		Description:              req.Description,
+		Phone:                    req.Phone,
+		Instagram:                req.Instagram,
+		Website:                  req.Website,
+		CuisineType:              req.CuisineType,
+		AveragePrice:             req.AveragePrice,
+		MaxCombinableTables:      req.MaxCombinableTables,
+		WorkingHours:             req.WorkingHours,
+		LastSeatingOffsetMinutes: req.LastSeatingOffsetMinutes,
+		MaxConcurrentGuests:      req.MaxConcurrentGuests,
+		CancellationPolicy:       req.CancellationPolicy,
+		BookingRules:             req.BookingRules,
+		Timezone:                 req.Timezone,
+		IsActive:                 req.IsActive,
+		RegenerateSlug:           req.RegenerateSlug,
 	}
 
 	restaurant, err := h.restaurantService.UpdateRestaurant(c.Request.Context(), id, ownerID, serviceReq)
@@ -140,6 +728,13 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
 		case errors.Is(err, service.ErrInvalidRestaurantName):
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "restaurant name cannot be empty"})
+		case errors.Is(err, service.ErrInvalidWorkingHours), errors.Is(err, service.ErrInvalidCancellationPolicy),
+			errors.Is(err, service.ErrInvalidBookingRules), errors.Is(err, service.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantDeactivatedByAdmin):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantSlugTaken):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		}
@@ -157,32 +752,97 @@ func (h *RestaurantHandler) DeleteRestaurant(c *gin.Context) {
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err = h.restaurantService.DeleteRestaurant(c.Request.Context(), id, ownerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
-	ownerID, err := uuid.Parse(ownerIDStr)
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Restore a deactivated restaurant
+// @Description Reactivates the restaurant. Tables that were deactivated on their own stay inactive. A restaurant an admin deactivated can only be restored by an admin.
+// @Tags Restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} domain.Restaurant
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/restore [post]
+func (h *RestaurantHandler) RestoreRestaurant(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
 		return
 	}
 
-	err = h.restaurantService.DeleteRestaurant(c.Request.Context(), id, ownerID)
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	restaurant, err := h.restaurantService.RestoreRestaurant(c.Request.Context(), id, ownerID)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrRestaurantNotFound):
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		case errors.Is(err, service.ErrRestaurantDeactivatedByAdmin):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		}
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, restaurant)
+}
+
+// @Summary List my restaurants
+// @Description Lists the authenticated owner's restaurants. Deactivated ones are only included with include_inactive=true.
+// @Tags Restaurants
+// @Produce json
+// @Param include_inactive query bool false "Include deactivated restaurants"
+// @Success 200 {array} domain.Restaurant
+// @Failure 400 {object} ErrorResponse
+// @Router /api/users/me/restaurants [get]
+func (h *RestaurantHandler) ListMyRestaurants(c *gin.Context) {
+	includeInactive := false
+	if v := c.Query("include_inactive"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "include_inactive must be true or false"})
+			return
+		}
+		includeInactive = parsed
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	restaurants, err := h.restaurantService.ListOwnedRestaurants(c.Request.Context(), ownerID, includeInactive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurants)
 }
 
 func (h *RestaurantHandler) AddImage(c *gin.Context) {
@@ -193,33 +853,27 @@ func (h *RestaurantHandler) AddImage(c *gin.Context) {
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	ownerID, err := uuid.Parse(ownerIDStr)
+	fileHeader, err := c.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "image file is required"})
 		return
 	}
 
-	_, err = c.FormFile("image")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "image file is required"})
+	upload, file, uploadErr := openImageUpload(fileHeader)
+	if uploadErr != nil {
+		c.JSON(uploadErr.status, ErrorResponse{Error: uploadErr.message})
 		return
 	}
-
-	isMain := c.DefaultPostForm("is_main", "false") == "true"
-
-	mockURL := "https://via.placeholder.com/400"
-	mockPublicID := "mock-id"
+	defer file.Close()
 
 	serviceReq := service.AddImageRequest{
-		CloudinaryURL:      mockURL,
-		CloudinaryPublicID: mockPublicID,
-		IsMain:             isMain,
+		File:   upload,
+		IsMain: c.DefaultPostForm("is_main", "false") == "true",
 	}
 
 	image, err := h.restaurantService.AddImage(c.Request.Context(), restaurantID, ownerID, serviceReq)
@@ -229,6 +883,8 @@ func (h *RestaurantHandler) AddImage(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
 		case errors.Is(err, service.ErrUnauthorized):
 			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		case errors.Is(err, imagestorage.ErrEmptyFile):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "image file is empty"})
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		}
@@ -238,6 +894,123 @@ func (h *RestaurantHandler) AddImage(c *gin.Context) {
 	c.JSON(http.StatusCreated, image)
 }
 
+// AddImages uploads up to maxBulkImages files sent as "images" parts of one
+// multipart request. Each file is checked and stored on its own, and the
+// response reports every file in the order it was sent.
+func (h *RestaurantHandler) AddImages(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing around the largest allowed batch.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkImages*maxImageUploadBytes+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("request must be at most %d MB", tooLarge.Limit>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "multipart form with image files is required"})
+		return
+	}
+
+	fileHeaders := form.File["images"]
+	if len(fileHeaders) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at least one image file is required"})
+		return
+	}
+	if len(fileHeaders) > maxBulkImages {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("at most %d images can be uploaded at once", maxBulkImages)})
+		return
+	}
+
+	results := make([]ImageUploadResult, len(fileHeaders))
+	var uploads []imagestorage.File
+	var positions []int
+	for i, fileHeader := range fileHeaders {
+		results[i].Filename = fileHeader.Filename
+
+		upload, file, uploadErr := openImageUpload(fileHeader)
+		if uploadErr != nil {
+			results[i].Error = uploadErr.message
+			continue
+		}
+		defer file.Close()
+		uploads = append(uploads, upload)
+		positions = append(positions, i)
+	}
+
+	if len(uploads) > 0 {
+		stored, err := h.restaurantService.AddImages(c.Request.Context(), restaurantID, ownerID, uploads)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrRestaurantNotFound):
+				c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+			case errors.Is(err, service.ErrUnauthorized):
+				c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+			default:
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		for j, result := range stored {
+			i := positions[j]
+			switch {
+			case result.Err == nil:
+				results[i].Image = result.Image
+			case errors.Is(result.Err, imagestorage.ErrEmptyFile):
+				results[i].Error = "image file is empty"
+			default:
+				results[i].Error = result.Err.Error()
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, ImageUploadResponse{Results: results})
+}
+
+// imageUploadError is why an uploaded file was not accepted as an image,
+// with the status a single-image upload answers with.
+type imageUploadError struct {
+	status  int
+	message string
+}
+
+// openImageUpload checks the size and sniffed type of an uploaded image and
+// opens it for storing. The caller closes the returned file.
+func openImageUpload(fileHeader *multipart.FileHeader) (imagestorage.File, io.Closer, *imageUploadError) {
+	if fileHeader.Size > maxImageUploadBytes {
+		return imagestorage.File{}, nil, &imageUploadError{http.StatusRequestEntityTooLarge, fmt.Sprintf("image must be at most %d MB", maxImageUploadBytes>>20)}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return imagestorage.File{}, nil, &imageUploadError{http.StatusBadRequest, "image file cannot be read"}
+	}
+
+	// Trust the bytes, not the client's Content-Type.
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	if !allowedImageTypes[http.DetectContentType(head[:n])] {
+		file.Close()
+		return imagestorage.File{}, nil, &imageUploadError{http.StatusBadRequest, "image must be a JPEG, PNG, GIF or WebP file"}
+	}
+
+	return imagestorage.File{
+		Name:    fileHeader.Filename,
+		Content: io.MultiReader(bytes.NewReader(head[:n]), file),
+	}, file, nil
+}
+
 func (h *RestaurantHandler) DeleteImage(c *gin.Context) {
 	idStr := c.Param("id")
 	restaurantID, err := uuid.Parse(idStr)
@@ -253,15 +1026,8 @@ func (h *RestaurantHandler) DeleteImage(c *gin.Context) {
 		return
 	}
 
-	ownerIDStr := c.Query("owner_id")
-	if ownerIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "owner_id is required"})
-		return
-	}
-
-	ownerID, err := uuid.Parse(ownerIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid owner id"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -283,8 +1049,159 @@ func (h *RestaurantHandler) DeleteImage(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *RestaurantHandler) SetMainImage(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid image id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	image, err := h.restaurantService.SetMainImage(c.Request.Context(), imageID, restaurantID, ownerID)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, image)
+}
+
+func (h *RestaurantHandler) ReorderImages(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	images, err := h.restaurantService.ReorderImages(c.Request.Context(), restaurantID, ownerID, req.ImageIDs)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+func writeImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "image not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+	case errors.Is(err, service.ErrInvalidImageOrder):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+func (h *RestaurantHandler) ListConfigVersions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+
+	versions, err := h.restaurantService.ListConfigVersions(c.Request.Context(), id, ownerID, limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+func (h *RestaurantHandler) RollbackConfig(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid config version"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	restaurant, err := h.restaurantService.RollbackConfig(c.Request.Context(), id, ownerID, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrConfigVersionNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "config version not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		case errors.Is(err, service.ErrInvalidRestaurantName):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "restaurant name cannot be empty"})
+		case errors.Is(err, service.ErrInvalidWorkingHours), errors.Is(err, service.ErrInvalidCancellationPolicy),
+			errors.Is(err, service.ErrInvalidBookingRules):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantDeactivatedByAdmin):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurant)
+}
+
 type CreateRestaurantRequest struct {
-	OwnerID             uuid.UUID           `json:"owner_id" binding:"required"`
 	Name                string              `json:"name" binding:"required"`
 	Address             string              `json:"address" binding:"required"`
 	Latitude            *float64            `json:"latitude"`
@@ -297,12 +1214,152 @@ type CreateRestaurantRequest struct {
 	AveragePrice        int                 `json:"average_price" binding:"required"`
 	MaxCombinableTables int                 `json:"max_combinable_tables" binding:"required"`
 	WorkingHours        domain.WorkingHours `json:"working_hours" binding:"required"`
+	// LastSeatingOffsetMinutes defaults to 60 when omitted.
+	LastSeatingOffsetMinutes *int `json:"last_seating_offset_minutes" binding:"omitempty,min=0,max=1440"`
+	// MaxConcurrentGuests caps the guests booked at any one time; 0 or
+	// omitted means no limit.
+	MaxConcurrentGuests int `json:"max_concurrent_guests" binding:"omitempty,min=0"`
+	// CancellationPolicy defaults to free cancellation without a deposit.
+	CancellationPolicy domain.CancellationPolicy `json:"cancellation_policy"`
+	// BookingRules default to 30-minute bookings on a 30-minute grid.
+	BookingRules domain.RestaurantBookingRules `json:"booking_rules"`
+	// Timezone is an IANA zone name and defaults to Asia/Almaty.
+	Timezone string `json:"timezone"`
+}
+
+// DuplicateRestaurantResponse is the 409 CreateRestaurant answers with when
+// the new restaurant looks like an existing one.
+type DuplicateRestaurantResponse struct {
+	Error                   string                 `json:"error"`
+	ConflictingRestaurantID uuid.UUID              `json:"conflicting_restaurant_id"`
+	Match                   service.DuplicateMatch `json:"match" enums:"address,name"`
 }
 
 type UpdateRestaurantRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	Phone       *string `json:"phone"`
-	Address     *string `json:"address"`
-	IsActive    *bool   `json:"is_active"`
+	Name                     *string                    `json:"name"`
+	Description              *string                    `json:"description"`
+	Phone                    *string                    `json:"phone"`
+	Address                  *string                    `json:"address"`
+	Latitude                 *float64                   `json:"latitude"`
+	Longitude                *float64                   `json:"longitude"`
+	Instagram                *string                    `json:"instagram"`
+	Website                  *string                    `json:"website"`
+	CuisineType              *domain.CuisineType        `json:"cuisine_type"`
+	AveragePrice             *int                       `json:"average_price"`
+	MaxCombinableTables      *int                       `json:"max_combinable_tables"`
+	WorkingHours             *domain.WorkingHours       `json:"working_hours"`
+	LastSeatingOffsetMinutes *int                       `json:"last_seating_offset_minutes" binding:"omitempty,min=0,max=1440"`
+	MaxConcurrentGuests      *int                       `json:"max_concurrent_guests" binding:"omitempty,min=0"`
+	CancellationPolicy       *domain.CancellationPolicy `json:"cancellation_policy"`
+	// BookingRules replaces the restaurant and zone rules as a whole.
+	BookingRules *domain.RestaurantBookingRules `json:"booking_rules"`
+	Timezone     *string                        `json:"timezone"`
+	IsActive     *bool                          `json:"is_active"`
+	// RegenerateSlug makes a new slug from the name. Renaming alone keeps
+	// the old one, so shared links keep working.
+	RegenerateSlug bool `json:"regenerate_slug"`
+}
+
+// ImageUploadResponse reports every file of a bulk upload in the order it
+// was sent.
+type ImageUploadResponse struct {
+	Results []ImageUploadResult `json:"results"`
+}
+
+// ImageUploadResult is one file of a bulk upload: the stored image, or the
+// error that kept it out of the gallery.
+type ImageUploadResult struct {
+	Filename string                  `json:"filename"`
+	Image    *domain.RestaurantImage `json:"image,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+// ReorderImagesRequest lists every image of the restaurant in gallery order.
+type ReorderImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"required,min=1"`
+}
+
+type RestaurantDetailsResponse struct {
+	*domain.Restaurant
+	Availability        *AvailabilityBlock `json:"availability,omitempty"`
+	AvailabilityTimeout bool               `json:"availability_timeout,omitempty"`
+	Busyness            *BusynessBlock     `json:"busyness,omitempty"`
+	IsFavorite          *bool              `json:"is_favorite,omitempty"`
+}
+
+type AvailabilityBlock struct {
+	FreeTables int           `json:"free_tables"`
+	BestFit    *domain.Table `json:"best_fit,omitempty"`
+	NextSlot   *apitime.Time `json:"next_slot,omitempty" swaggertype:"string" format:"date-time" example:"2024-06-01T19:30:00Z"`
+}
+
+// AvailabilityCalendarResponse is the slot grid of every table that fits the
+// party on Date. Tables do not share a grid: each slot list follows the
+// table's own SlotGranularityMinutes.
+type AvailabilityCalendarResponse struct {
+	Date   string          `json:"date" example:"2024-06-01"`
+	Tables []TableCalendar `json:"tables"`
+}
+
+// TableCalendar is one table's grid with the booking rules that shape it.
+type TableCalendar struct {
+	TableID                uuid.UUID           `json:"table_id"`
+	TableNumber            string              `json:"table_number"`
+	LocationType           domain.LocationType `json:"location_type"`
+	MinCapacity            int                 `json:"min_capacity"`
+	MaxCapacity            int                 `json:"max_capacity"`
+	MinDurationMinutes     int                 `json:"min_duration_minutes"`
+	SlotGranularityMinutes int                 `json:"slot_granularity_minutes"`
+	DepositAmount          int                 `json:"deposit_amount" example:"10000"`
+	MinSpend               int                 `json:"min_spend" example:"0"`
+	Slots                  []CalendarSlot      `json:"slots"`
+}
+
+type CalendarSlot struct {
+	StartTime apitime.Time `json:"start_time" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	Available bool         `json:"available"`
+}
+
+// BusynessBlock tells how busy the restaurant usually is at each hour of
+// Date. Status is "insufficient_data" and Hours empty when there are too few
+// past bookings to tell.
+type BusynessBlock struct {
+	Date   string         `json:"date" example:"2024-06-01"`
+	Status string         `json:"status" enums:"ok,insufficient_data"`
+	Hours  []BusynessHour `json:"hours,omitempty"`
+}
+
+// BusynessHour is one hour of a BusynessBlock. Current marks the hour that is
+// happening now.
+type BusynessHour struct {
+	Hour    int                   `json:"hour"`
+	Level   service.BusynessLevel `json:"level" enums:"quiet,moderate,busy"`
+	Current bool                  `json:"current,omitempty"`
+}
+
+func toBusynessBlock(day *service.BusynessDay, now time.Time) *BusynessBlock {
+	block := &BusynessBlock{Date: day.Date.Format("2006-01-02"), Status: "ok"}
+	if day.InsufficientData {
+		block.Status = "insufficient_data"
+		return block
+	}
+
+	today := day.Date.Format("2006-01-02") == now.Format("2006-01-02")
+	block.Hours = make([]BusynessHour, len(day.Hours))
+	for hour, level := range day.Hours {
+		block.Hours[hour] = BusynessHour{Hour: hour, Level: level, Current: today && hour == now.Hour()}
+	}
+	return block
+}
+
+func toAvailabilityBlock(availability *service.RestaurantAvailability) *AvailabilityBlock {
+	block := &AvailabilityBlock{
+		FreeTables: availability.FreeTables,
+		BestFit:    availability.BestFit,
+	}
+	if availability.NextSlot != nil {
+		next := apitime.New(*availability.NextSlot)
+		block.NextSlot = &next
+	}
+	return block
 }
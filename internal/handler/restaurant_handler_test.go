@@ -0,0 +1,1176 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/imagestorage"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRestaurantService struct {
+	service.RestaurantService
+	restaurants     []*domain.Restaurant
+	columns         []string
+	ownerID         uuid.UUID
+	restaurant      *domain.Restaurant
+	searched        bool
+	radiusKm        float64
+	openAt          *time.Time
+	includeInactive bool
+	cuisines        []*repository.CuisineCount
+	filter          repository.RestaurantFilter
+	total           int64
+	limit           int
+	created         *service.CreateRestaurantRequest
+	createErr       error
+	uploaded        []string
+}
+
+// AddImages stores every file except those named "broken.png".
+func (s *stubRestaurantService) AddImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, files []imagestorage.File) ([]service.AddImageResult, error) {
+	if s.restaurant != nil && s.restaurant.OwnerID != ownerID {
+		return nil, service.ErrUnauthorized
+	}
+	results := make([]service.AddImageResult, len(files))
+	for i, file := range files {
+		s.uploaded = append(s.uploaded, file.Name)
+		if file.Name == "broken.png" {
+			results[i].Err = errors.New("storage unavailable")
+			continue
+		}
+		results[i].Image = &domain.RestaurantImage{ID: uuid.New(), RestaurantID: restaurantID, Position: i}
+	}
+	return results, nil
+}
+
+func (s *stubRestaurantService) CreateRestaurant(ctx context.Context, ownerID uuid.UUID, req service.CreateRestaurantRequest) (*domain.Restaurant, error) {
+	s.created = &req
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	return &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: req.Name}, nil
+}
+
+func (s *stubRestaurantService) GetRestaurant(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error) {
+	if s.restaurant == nil || s.restaurant.ID != id {
+		return nil, service.ErrRestaurantNotFound
+	}
+	return s.restaurant, nil
+}
+
+func (s *stubRestaurantService) GetRestaurantBySlug(ctx context.Context, slug string) (*domain.Restaurant, error) {
+	if s.restaurant == nil || s.restaurant.Slug == nil || *s.restaurant.Slug != slug {
+		return nil, service.ErrRestaurantNotFound
+	}
+	return s.restaurant, nil
+}
+
+type stubAvailabilityService struct {
+	service.AvailabilityService
+	delay    time.Duration
+	called   bool
+	guests   int
+	calendar []service.TableSlots
+}
+
+func (s *stubAvailabilityService) Calendar(ctx context.Context, restaurant *domain.Restaurant, day time.Time, guests int) ([]service.TableSlots, error) {
+	s.guests = guests
+	return s.calendar, nil
+}
+
+func (s *stubAvailabilityService) CheckRestaurantAvailability(ctx context.Context, restaurant *domain.Restaurant, at time.Time, guests int) (*service.RestaurantAvailability, error) {
+	s.called = true
+	s.guests = guests
+	time.Sleep(s.delay)
+	next := at.Add(30 * time.Minute)
+	return &service.RestaurantAvailability{FreeTables: 0, NextSlot: &next}, nil
+}
+
+type stubBusynessService struct {
+	service.BusynessService
+	insufficient bool
+	day          time.Time
+}
+
+func (s *stubBusynessService) ForDay(ctx context.Context, restaurantID uuid.UUID, day time.Time) (*service.BusynessDay, error) {
+	s.day = day
+	result := &service.BusynessDay{Date: day, InsufficientData: s.insufficient}
+	if !s.insufficient {
+		result.Hours = make([]service.BusynessLevel, 24)
+		for hour := range result.Hours {
+			result.Hours[hour] = service.BusynessQuiet
+		}
+		result.Hours[20] = service.BusynessBusy
+	}
+	return result, nil
+}
+
+func (s *stubRestaurantService) UpdateRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req service.UpdateRestaurantRequest) (*domain.Restaurant, error) {
+	s.ownerID = ownerID
+	return &domain.Restaurant{ID: id, OwnerID: ownerID}, nil
+}
+
+func (s *stubRestaurantService) DeleteRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error {
+	s.ownerID = ownerID
+	return nil
+}
+
+func (s *stubRestaurantService) RestoreRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) (*domain.Restaurant, error) {
+	s.ownerID = ownerID
+	if s.restaurant == nil || s.restaurant.ID != id {
+		return nil, service.ErrRestaurantNotFound
+	}
+	if s.restaurant.DeactivatedByAdmin() {
+		return nil, service.ErrRestaurantDeactivatedByAdmin
+	}
+	s.restaurant.IsActive = true
+	return s.restaurant, nil
+}
+
+func (s *stubRestaurantService) ListOwnedRestaurants(ctx context.Context, ownerID uuid.UUID, includeInactive bool) ([]*domain.Restaurant, error) {
+	s.ownerID = ownerID
+	s.includeInactive = includeInactive
+	return s.restaurants, nil
+}
+
+func (s *stubRestaurantService) ListCuisines(ctx context.Context) ([]*repository.CuisineCount, error) {
+	return s.cuisines, nil
+}
+
+func (s *stubRestaurantService) RollbackConfig(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, version int) (*domain.Restaurant, error) {
+	s.ownerID = ownerID
+	if version > 5 {
+		return nil, service.ErrConfigVersionNotFound
+	}
+	return &domain.Restaurant{ID: id, OwnerID: ownerID}, nil
+}
+
+func (s *stubRestaurantService) ReorderImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, imageIDs []uuid.UUID) ([]*domain.RestaurantImage, error) {
+	s.ownerID = ownerID
+	if len(imageIDs) < 2 {
+		return nil, service.ErrInvalidImageOrder
+	}
+	images := make([]*domain.RestaurantImage, len(imageIDs))
+	for i, id := range imageIDs {
+		images[i] = &domain.RestaurantImage{ID: id, RestaurantID: restaurantID, Position: i}
+	}
+	return images, nil
+}
+
+func (s *stubRestaurantService) SearchRestaurants(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error) {
+	s.searched = true
+	s.openAt = openAt
+	if cuisineType != nil && *cuisineType == "Martian" {
+		return nil, service.ErrInvalidCuisineType
+	}
+	return s.restaurants, nil
+}
+
+func (s *stubRestaurantService) NearbyRestaurants(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*repository.NearbyRestaurant, error) {
+	s.radiusKm = radiusKm
+	nearby := make([]*repository.NearbyRestaurant, len(s.restaurants))
+	for i, restaurant := range s.restaurants {
+		nearby[i] = &repository.NearbyRestaurant{Restaurant: *restaurant, DistanceKm: float64(i)}
+	}
+	return nearby, nil
+}
+
+func (s *stubRestaurantService) GetRestaurants(ctx context.Context, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	s.filter = filter
+	s.limit = limit
+	s.openAt = filter.OpenAt
+	if filter.Sort != "" && !slices.Contains(repository.RestaurantSorts, filter.Sort) {
+		return nil, service.ErrInvalidSort
+	}
+	return s.restaurants, nil
+}
+
+func (s *stubRestaurantService) GetRestaurantsWithColumns(ctx context.Context, columns []string, withMainImage bool, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	s.columns = columns
+	s.filter = filter
+	s.openAt = filter.OpenAt
+	return s.restaurants, nil
+}
+
+func (s *stubRestaurantService) CountRestaurants(ctx context.Context, filter repository.RestaurantFilter) (int64, error) {
+	return s.total, nil
+}
+
+func sampleRestaurants(n int) []*domain.Restaurant {
+	lat, lng := 43.238949, 76.889709
+	hours := domain.WorkingHours{}
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		hours[day] = domain.DaySchedule{OpenTime: "10:00", CloseTime: "23:00"}
+	}
+
+	restaurants := make([]*domain.Restaurant, n)
+	for i := range restaurants {
+		restaurants[i] = &domain.Restaurant{
+			ID:           uuid.New(),
+			OwnerID:      uuid.New(),
+			Name:         fmt.Sprintf("Restaurant %d", i),
+			Address:      "Abay Avenue 1, Almaty",
+			Latitude:     &lat,
+			Longitude:    &lng,
+			Description:  strings.Repeat("Cozy place with seasonal menu. ", 20),
+			Phone:        "+77001234567",
+			CuisineType:  domain.CuisineTypeKazakh,
+			AveragePrice: 5000,
+			WorkingHours: hours,
+			Status:       domain.RestaurantStatusApproved,
+			Rating:       4.5,
+			Images: []domain.RestaurantImage{
+				{CloudinaryURL: "https://example.com/main.jpg", IsMain: true},
+			},
+		}
+	}
+	return restaurants
+}
+
+// stubSponsoredService blends placements into lists with the real ranking.
+type stubSponsoredService struct {
+	service.SponsoredPlacementService
+	placements []*domain.SponsoredPlacement
+	scope      repository.SponsoredScope
+	blended    bool
+}
+
+func (s *stubSponsoredService) Blend(ctx context.Context, organic []*domain.Restaurant, scope repository.SponsoredScope) []service.RankedRestaurant {
+	s.blended = true
+	s.scope = scope
+	return service.BlendSponsored(organic, s.placements, time.Now())
+}
+
+func performListRestaurants(t *testing.T, svc service.RestaurantService, query string) *httptest.ResponseRecorder {
+	return performSponsoredList(svc, &stubSponsoredService{}, "/api/restaurants"+query)
+}
+
+// performSponsoredList serves target from the list or search endpoint,
+// whichever its path names.
+func performSponsoredList(svc service.RestaurantService, sponsored *stubSponsoredService, target string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewRestaurantHandler(svc, nil, nil, nil, sponsored)
+	router.GET("/api/restaurants", h.ListRestaurants)
+	router.GET("/api/restaurants/search", h.SearchRestaurants)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+// listItems decodes the items of a paginated restaurant list.
+func listItems(t *testing.T, w *httptest.ResponseRecorder) []map[string]interface{} {
+	t.Helper()
+	var page struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page.Items
+}
+
+func TestListRestaurants_FieldsShrinkPayload(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(20)}
+
+	full := performListRestaurants(t, svc, "")
+	projected := performListRestaurants(t, svc, "?fields=id,name,main_image,rating,price,cuisine,address")
+
+	require.Equal(t, http.StatusOK, full.Code)
+	require.Equal(t, http.StatusOK, projected.Code)
+	assert.Less(t, projected.Body.Len()*4, full.Body.Len(), "projected payload should be under a quarter of the full card")
+	assert.ElementsMatch(t, []string{"id", "name", "rating", "average_price", "cuisine_type", "address"}, svc.columns)
+}
+
+func TestListRestaurants_FieldsOnlyRequestedKeys(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performListRestaurants(t, svc, "?fields=name,main_image,distance&lat=43.25&lng=76.9")
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cards := listItems(t, w)
+	require.Len(t, cards, 1)
+	assert.Len(t, cards[0], 3)
+	assert.Equal(t, "Restaurant 0", cards[0]["name"])
+	assert.Equal(t, "https://example.com/main.jpg", cards[0]["main_image"])
+	assert.InDelta(t, 1.6, cards[0]["distance"], 0.2)
+}
+
+func performSearchRestaurants(svc service.RestaurantService, query string) *httptest.ResponseRecorder {
+	return performSponsoredList(svc, &stubSponsoredService{}, "/api/restaurants/search"+query)
+}
+
+func TestSearchRestaurants_Filters(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+
+	w := performSearchRestaurants(svc, "?cuisine=Italian&min_rating=4")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.searched)
+}
+
+func TestSearchRestaurants_NoFiltersFallsBackToList(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+
+	w := performSearchRestaurants(svc, "?limit=5")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, svc.searched)
+}
+
+func TestSearchRestaurants_Fields(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performSearchRestaurants(svc, "?cuisine=Kazakh&fields=name,main_image,distance&lat=43.25&lng=76.9")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.searched)
+	var cards []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cards))
+	require.Len(t, cards, 1)
+	assert.Len(t, cards[0], 3)
+	assert.Equal(t, "Restaurant 0", cards[0]["name"])
+	assert.Equal(t, "https://example.com/main.jpg", cards[0]["main_image"])
+	assert.InDelta(t, 1.6, cards[0]["distance"], 0.2)
+}
+
+func TestSearchRestaurants_UnknownField(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performSearchRestaurants(svc, "?cuisine=Kazakh&fields=name,owner_id")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, svc.searched)
+}
+
+func TestSearchRestaurants_OpenNow(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+
+	w := performSearchRestaurants(svc, "?cuisine=Italian&open_now=true")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.searched)
+	require.NotNil(t, svc.openAt)
+	assert.WithinDuration(t, time.Now(), *svc.openAt, time.Minute)
+}
+
+func TestListRestaurants_OpenNow(t *testing.T) {
+	for query, wantFilter := range map[string]bool{
+		"?open_now=true":                 true,
+		"?open_now=false":                false,
+		"":                               false,
+		"?fields=id,name&open_now=true":  true,
+		"?fields=id,name&open_now=false": false,
+	} {
+		svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+		w := performListRestaurants(t, svc, query)
+
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.Equal(t, wantFilter, svc.openAt != nil, query)
+	}
+
+	w := performListRestaurants(t, &stubRestaurantService{}, "?open_now=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchRestaurants_BadRequest(t *testing.T) {
+	for _, query := range []string{"?cuisine=Martian", "?min_rating=high", "?open_now=sometimes"} {
+		w := performSearchRestaurants(&stubRestaurantService{}, query)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestNearbyRestaurants_IncludesDistance(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/restaurants/nearby", NewRestaurantHandler(svc, nil, nil, nil, nil).NearbyRestaurants)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/restaurants/nearby?lat=43.25&lng=76.9", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "Restaurant 1", body[1]["name"])
+	assert.Equal(t, 1.0, body[1]["distance_km"])
+	assert.Equal(t, defaultNearbyRadiusKm, svc.radiusKm)
+}
+
+func TestNearbyRestaurants_RequiresCoordinates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/restaurants/nearby", NewRestaurantHandler(&stubRestaurantService{}, nil, nil, nil, nil).NearbyRestaurants)
+
+	for _, query := range []string{"", "?lat=43.25", "?lat=43.25&lng=76.9&radius_km=far"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/restaurants/nearby"+query, nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestListRestaurants_PriceAndSort(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performListRestaurants(t, svc, "?min_price=2000&max_price=8000&sort=price&order=asc")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.filter.MinPrice)
+	require.NotNil(t, svc.filter.MaxPrice)
+	assert.Equal(t, 2000, *svc.filter.MinPrice)
+	assert.Equal(t, 8000, *svc.filter.MaxPrice)
+	assert.Equal(t, repository.RestaurantSortPrice, svc.filter.Sort)
+	assert.True(t, svc.filter.Ascending)
+}
+
+func TestListRestaurants_DefaultOrder(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performListRestaurants(t, svc, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, repository.RestaurantFilter{}, svc.filter)
+}
+
+func TestListRestaurants_InvalidListParams(t *testing.T) {
+	for _, query := range []string{"?sort=name", "?order=up", "?min_price=cheap", "?max_price=1.5", "?offset=-1", "?limit=0", "?limit=ten"} {
+		t.Run(query, func(t *testing.T) {
+			w := performListRestaurants(t, &stubRestaurantService{}, query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestListRestaurants_PageEnvelope(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2), total: 57}
+
+	w := performListRestaurants(t, svc, "?limit=500&offset=40")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Items  []map[string]interface{} `json:"items"`
+		Total  int64                    `json:"total"`
+		Limit  int                      `json:"limit"`
+		Offset int                      `json:"offset"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, int64(57), page.Total)
+	assert.Equal(t, 100, page.Limit)
+	assert.Equal(t, 40, page.Offset)
+	assert.Equal(t, 100, svc.limit)
+}
+
+func TestListRestaurants_CardsPageEnvelope(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1), total: 3}
+
+	w := performListRestaurants(t, svc, "?fields=name")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[{"name":"Restaurant 0"}],"total":3,"limit":10,"offset":0}`, w.Body.String())
+}
+
+func TestListRestaurants_UnknownField(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performListRestaurants(t, svc, "?fields=id,working_hours")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "working_hours")
+}
+
+func TestListRestaurants_EmptyFields(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(1)}
+
+	w := performListRestaurants(t, svc, "?fields=")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// livePlacement promotes a new restaurant and is serving now.
+func livePlacement(name string) *domain.SponsoredPlacement {
+	promoted := sampleRestaurants(1)[0]
+	promoted.Name = name
+	promoted.IsActive = true
+	return &domain.SponsoredPlacement{
+		ID:           uuid.New(),
+		RestaurantID: promoted.ID,
+		Weight:       1,
+		StartsAt:     time.Now().Add(-time.Hour),
+		EndsAt:       time.Now().Add(time.Hour),
+		Budget:       10000,
+		CostPerClick: 100,
+		Restaurant:   promoted,
+	}
+}
+
+func TestListRestaurants_FlagsSponsoredResults(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(3)}
+	placement := livePlacement("Promoted")
+	sponsored := &stubSponsoredService{placements: []*domain.SponsoredPlacement{placement}}
+
+	w := performSponsoredList(svc, sponsored, "/api/restaurants?city=Almaty")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	items := listItems(t, w)
+	require.Len(t, items, 4)
+	assert.Equal(t, "Promoted", items[0]["name"])
+	assert.Equal(t, true, items[0]["sponsored"])
+	assert.Equal(t, placement.ID.String(), items[0]["placement_id"])
+	assert.Equal(t, false, items[1]["sponsored"])
+	assert.NotContains(t, items[1], "placement_id")
+	assert.Equal(t, repository.SponsoredScope{City: "Almaty"}, sponsored.scope)
+}
+
+func TestListRestaurants_SponsoredOnlyOnFirstPage(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(3)}
+	sponsored := &stubSponsoredService{placements: []*domain.SponsoredPlacement{livePlacement("Promoted")}}
+
+	w := performSponsoredList(svc, sponsored, "/api/restaurants?offset=20")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	items := listItems(t, w)
+	assert.Len(t, items, 3)
+	assert.False(t, sponsored.blended)
+}
+
+func TestListRestaurants_SponsoredCards(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+	placement := livePlacement("Promoted")
+	sponsored := &stubSponsoredService{placements: []*domain.SponsoredPlacement{placement}}
+
+	w := performSponsoredList(svc, sponsored, "/api/restaurants?fields=name")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	cards := listItems(t, w)
+	require.Len(t, cards, 3)
+	assert.Equal(t, map[string]interface{}{"name": "Promoted", "sponsored": true, "placement_id": placement.ID.String()}, cards[0])
+	assert.Equal(t, map[string]interface{}{"name": "Restaurant 0"}, cards[1])
+	assert.Contains(t, svc.columns, "id")
+}
+
+func TestSearchRestaurants_SponsoredScope(t *testing.T) {
+	svc := &stubRestaurantService{restaurants: sampleRestaurants(2)}
+	sponsored := &stubSponsoredService{}
+
+	w := performSponsoredList(svc, sponsored, "/api/restaurants/search?cuisine=Italian&city=%20Astana%20")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, sponsored.blended)
+	assert.Equal(t, "Astana", sponsored.scope.City)
+	require.NotNil(t, sponsored.scope.CuisineType)
+	assert.Equal(t, domain.CuisineTypeItalian, *sponsored.scope.CuisineType)
+}
+
+// performAsUser runs the handler behind a fake auth step that sets user_id
+// the way AuthMiddleware.Authenticate does. A nil userID simulates a missing token.
+func performAsUser(handlerFunc gin.HandlerFunc, method, route, target string, userID *uuid.UUID, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", *userID)
+		}
+		c.Next()
+	}, handlerFunc)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRestaurantOwnerEndpoints_NoToken(t *testing.T) {
+	h := NewRestaurantHandler(&stubRestaurantService{}, nil, nil, nil, nil)
+	id := uuid.New()
+
+	cases := []struct {
+		name    string
+		method  string
+		route   string
+		target  string
+		handler gin.HandlerFunc
+	}{
+		{"create", http.MethodPost, "/api/restaurants", "/api/restaurants", h.CreateRestaurant},
+		{"update", http.MethodPut, "/api/restaurants/:id", "/api/restaurants/" + id.String(), h.UpdateRestaurant},
+		{"delete", http.MethodDelete, "/api/restaurants/:id", "/api/restaurants/" + id.String(), h.DeleteRestaurant},
+		{"add image", http.MethodPost, "/api/restaurants/:id/images", "/api/restaurants/" + id.String() + "/images", h.AddImage},
+		{"add images", http.MethodPost, "/api/restaurants/:id/images/bulk", "/api/restaurants/" + id.String() + "/images/bulk", h.AddImages},
+		{"delete image", http.MethodDelete, "/api/restaurants/:id/images/:image_id", "/api/restaurants/" + id.String() + "/images/" + uuid.NewString(), h.DeleteImage},
+		{"set main image", http.MethodPatch, "/api/restaurants/:id/images/:image_id/main", "/api/restaurants/" + id.String() + "/images/" + uuid.NewString() + "/main", h.SetMainImage},
+		{"reorder images", http.MethodPut, "/api/restaurants/:id/images/order", "/api/restaurants/" + id.String() + "/images/order", h.ReorderImages},
+		{"list config versions", http.MethodGet, "/api/restaurants/:id/config-versions", "/api/restaurants/" + id.String() + "/config-versions", h.ListConfigVersions},
+		{"rollback config", http.MethodPost, "/api/restaurants/:id/config-versions/:version/rollback", "/api/restaurants/" + id.String() + "/config-versions/1/rollback", h.RollbackConfig},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performAsUser(tc.handler, tc.method, tc.route, tc.target, nil, "{}")
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestUpdateRestaurant_UsesAuthenticatedOwner(t *testing.T) {
+	svc := &stubRestaurantService{}
+	userID := uuid.New()
+	id := uuid.New()
+
+	w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).UpdateRestaurant, http.MethodPut, "/api/restaurants/:id",
+		"/api/restaurants/"+id.String()+"?owner_id="+uuid.NewString(), &userID, `{"name":"New name"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+}
+
+const createRestaurantBody = `{"name":"Navat","address":"ул. Абая, 10","phone":"+77011234567","cuisine_type":"Italian","average_price":5000,"max_combinable_tables":2,"working_hours":{"monday":{"open_time":"10:00","close_time":"22:00"}}}`
+
+func TestCreateRestaurant_Duplicate(t *testing.T) {
+	userID, existingID := uuid.New(), uuid.New()
+	svc := &stubRestaurantService{createErr: &service.DuplicateRestaurantError{RestaurantID: existingID, Match: service.DuplicateMatchAddress}}
+
+	w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).CreateRestaurant, http.MethodPost, "/api/restaurants",
+		"/api/restaurants", &userID, createRestaurantBody)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var body DuplicateRestaurantResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, existingID, body.ConflictingRestaurantID)
+	assert.Equal(t, service.DuplicateMatchAddress, body.Match)
+	assert.False(t, svc.created.Force)
+}
+
+func TestCreateRestaurant_Force(t *testing.T) {
+	userID := uuid.New()
+
+	for target, want := range map[string]int{
+		"/api/restaurants?force=true": http.StatusCreated,
+		"/api/restaurants?force=yes":  http.StatusBadRequest,
+	} {
+		svc := &stubRestaurantService{}
+
+		w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).CreateRestaurant, http.MethodPost, "/api/restaurants",
+			target, &userID, createRestaurantBody)
+
+		require.Equal(t, want, w.Code, target)
+		if want == http.StatusCreated {
+			assert.True(t, svc.created.Force)
+		} else {
+			assert.Nil(t, svc.created)
+		}
+	}
+}
+
+func TestDeleteRestaurant_UsesAuthenticatedOwner(t *testing.T) {
+	svc := &stubRestaurantService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).DeleteRestaurant, http.MethodDelete, "/api/restaurants/:id",
+		"/api/restaurants/"+uuid.NewString(), &userID, "")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+}
+
+func TestRestoreRestaurant_Responses(t *testing.T) {
+	userID := uuid.New()
+	adminID := uuid.New()
+	route := "/api/restaurants/:id/restore"
+
+	cases := []struct {
+		name          string
+		deactivatedBy *uuid.UUID
+		id            string
+		status        int
+	}{
+		{"restored", &userID, "", http.StatusOK},
+		{"deactivated by admin", &adminID, "", http.StatusForbidden},
+		{"unknown restaurant", nil, uuid.NewString(), http.StatusNotFound},
+		{"invalid id", nil, "abc", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: userID, DeactivatedBy: tc.deactivatedBy}
+			svc := &stubRestaurantService{restaurant: restaurant}
+			id := tc.id
+			if id == "" {
+				id = restaurant.ID.String()
+			}
+
+			w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).RestoreRestaurant, http.MethodPost, route,
+				"/api/restaurants/"+id+"/restore", &userID, "")
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestListMyRestaurants_IncludeInactive(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/users/me/restaurants"
+
+	cases := []struct {
+		name            string
+		query           string
+		status          int
+		includeInactive bool
+	}{
+		{"default", "", http.StatusOK, false},
+		{"include inactive", "?include_inactive=true", http.StatusOK, true},
+		{"invalid flag", "?include_inactive=maybe", http.StatusBadRequest, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubRestaurantService{restaurants: []*domain.Restaurant{{ID: uuid.New(), OwnerID: userID}}}
+
+			w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).ListMyRestaurants, http.MethodGet, route,
+				route+tc.query, &userID, "")
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.includeInactive, svc.includeInactive)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, userID, svc.ownerID)
+			}
+		})
+	}
+}
+
+func TestListCuisines_ETag(t *testing.T) {
+	svc := &stubRestaurantService{cuisines: []*repository.CuisineCount{
+		{CuisineType: domain.CuisineTypeKazakh, RestaurantCount: 3},
+	}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/restaurants/cuisines", NewRestaurantHandler(svc, nil, nil, nil, nil).ListCuisines)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/restaurants/cuisines", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age=")
+
+	var body []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body, 1)
+	assert.Equal(t, "Kazakh", body[0]["cuisine_type"])
+	assert.Equal(t, float64(3), body[0]["restaurant_count"])
+
+	notModified := get(`"other", W/` + etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	svc.cuisines[0].RestaurantCount = 4
+	changed := get(etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
+func TestRollbackConfig_Responses(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/restaurants/:id/config-versions/:version/rollback"
+	id := uuid.NewString()
+
+	cases := []struct {
+		name    string
+		version string
+		status  int
+	}{
+		{"rolled back", "2", http.StatusOK},
+		{"unknown version", "9", http.StatusNotFound},
+		{"invalid version", "latest", http.StatusBadRequest},
+		{"zero version", "0", http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubRestaurantService{}
+			w := performAsUser(NewRestaurantHandler(svc, nil, nil, nil, nil).RollbackConfig, http.MethodPost, route,
+				"/api/restaurants/"+id+"/config-versions/"+tc.version+"/rollback", &userID, "")
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func getRestaurantDetails(h *RestaurantHandler, id uuid.UUID, query string) *httptest.ResponseRecorder {
+	return performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id",
+		"/api/restaurants/"+id.String()+query, nil, "")
+}
+
+func TestGetRestaurant_InlineAvailability(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	availability := &stubAvailabilityService{}
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, availability, &stubBusynessService{}, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "?check_availability_at=2024-06-01T19:00:00%2B05:00&guests=4")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, availability.guests)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, restaurant.Name, body["name"])
+	block, ok := body["availability"].(map[string]interface{})
+	require.True(t, ok, "expected availability block, got %v", body)
+	assert.Equal(t, float64(0), block["free_tables"])
+	assert.Equal(t, "2024-06-01T14:30:00Z", block["next_slot"])
+	assert.NotContains(t, body, "availability_timeout")
+}
+
+func TestGetRestaurant_AvailabilityTimeout(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{delay: time.Second}, &stubBusynessService{}, nil, nil)
+
+	start := time.Now()
+	w := getRestaurantDetails(h, restaurant.ID, "?check_availability_at=2024-06-01T19:00:00Z")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Less(t, time.Since(start), 900*time.Millisecond)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, true, body["availability_timeout"])
+	assert.NotContains(t, body, "availability")
+}
+
+func TestGetRestaurant_AvailabilitySkippedForInactiveRestaurant(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = false
+	availability := &stubAvailabilityService{}
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, availability, &stubBusynessService{}, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "?check_availability_at=2024-06-01T19:00:00Z&guests=2")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, availability.called)
+	assert.NotContains(t, w.Body.String(), "availability")
+}
+
+func TestGetRestaurant_InvalidAvailabilityParams(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+
+	for _, query := range []string{
+		"?guests=4",
+		"?check_availability_at=2024-06-01%2019:00",
+		"?check_availability_at=2024-06-01T19:00:00Z&guests=0",
+		"?check_availability_at=2024-06-01T19:00:00Z&guests=many",
+	} {
+		availability := &stubAvailabilityService{}
+		h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, availability, &stubBusynessService{}, nil, nil)
+
+		w := getRestaurantDetails(h, restaurant.ID, query)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.False(t, availability.called, query)
+	}
+}
+
+func TestGetRestaurant_Busyness(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	busyness := &stubBusynessService{}
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, busyness, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "?busyness_date=2024-06-01")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2024-06-01", busyness.day.Format("2006-01-02"))
+
+	var resp RestaurantDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Busyness)
+	assert.Equal(t, "ok", resp.Busyness.Status)
+	require.Len(t, resp.Busyness.Hours, 24)
+	assert.Equal(t, service.BusynessBusy, resp.Busyness.Hours[20].Level)
+	for _, hour := range resp.Busyness.Hours {
+		assert.False(t, hour.Current, "no hour of a past day is current")
+	}
+}
+
+func TestGetRestaurant_BusynessHighlightsCurrentHour(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RestaurantDetailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Busyness)
+
+	var current []int
+	for _, hour := range resp.Busyness.Hours {
+		if hour.Current {
+			current = append(current, hour.Hour)
+		}
+	}
+	assert.Len(t, current, 1)
+}
+
+func TestGetRestaurant_BusynessInsufficientData(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{insufficient: true}, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	block, ok := body["busyness"].(map[string]interface{})
+	require.True(t, ok, "expected busyness block, got %v", body)
+	assert.Equal(t, "insufficient_data", block["status"])
+	assert.NotContains(t, block, "hours")
+}
+
+func TestGetRestaurant_InvalidBusynessDate(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, nil, nil)
+
+	w := getRestaurantDetails(h, restaurant.ID, "?busyness_date=tomorrow")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAvailabilityCalendar_PerTableGrids(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	at := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+	bar := &domain.Table{ID: uuid.New(), TableNumber: "B1", LocationType: domain.LocationRegular, MinCapacity: 1, MaxCapacity: 2}
+	privateRoom := &domain.Table{ID: uuid.New(), TableNumber: "P1", LocationType: domain.LocationVIP, MinCapacity: 4, MaxCapacity: 10}
+	availability := &stubAvailabilityService{calendar: []service.TableSlots{
+		{Table: bar, Rules: domain.BookingRules{MinDurationMinutes: 45, SlotGranularityMinutes: 45}, Slots: []service.CalendarSlot{
+			{Start: at, Available: true}, {Start: at.Add(45 * time.Minute), Available: false},
+		}},
+		{Table: privateRoom, Rules: domain.BookingRules{MinDurationMinutes: 180, SlotGranularityMinutes: 180}, Slots: []service.CalendarSlot{
+			{Start: at, Available: true},
+		}},
+	}}
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, availability, &stubBusynessService{}, nil, nil)
+
+	w := performAsUser(h.GetAvailabilityCalendar, http.MethodGet, "/api/restaurants/:id/availability",
+		"/api/restaurants/"+restaurant.ID.String()+"/availability?date=2024-06-01&guests=2", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, availability.guests)
+
+	var body AvailabilityCalendarResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "2024-06-01", body.Date)
+	require.Len(t, body.Tables, 2)
+	assert.Equal(t, "B1", body.Tables[0].TableNumber)
+	assert.Equal(t, 45, body.Tables[0].SlotGranularityMinutes)
+	require.Len(t, body.Tables[0].Slots, 2)
+	assert.True(t, body.Tables[0].Slots[1].StartTime.Equal(at.Add(45*time.Minute)))
+	assert.False(t, body.Tables[0].Slots[1].Available)
+	assert.Equal(t, 180, body.Tables[1].MinDurationMinutes)
+	assert.Len(t, body.Tables[1].Slots, 1)
+}
+
+func TestGetAvailabilityCalendar_BadRequest(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.IsActive = true
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, nil, nil)
+
+	for _, query := range []string{"", "?date=01-06-2024", "?date=2024-06-01&guests=0"} {
+		w := performAsUser(h.GetAvailabilityCalendar, http.MethodGet, "/api/restaurants/:id/availability",
+			"/api/restaurants/"+restaurant.ID.String()+"/availability"+query, nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, "query %q", query)
+	}
+}
+
+func TestReorderImages_Responses(t *testing.T) {
+	userID := uuid.New()
+	target := "/api/restaurants/" + uuid.NewString() + "/images/order"
+	first, second := uuid.NewString(), uuid.NewString()
+
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"reordered", `{"image_ids": ["` + first + `", "` + second + `"]}`, http.StatusOK},
+		{"incomplete order", `{"image_ids": ["` + first + `"]}`, http.StatusBadRequest},
+		{"empty order", `{"image_ids": []}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubRestaurantService{}
+			h := NewRestaurantHandler(svc, nil, nil, nil, nil)
+
+			w := performAsUser(h.ReorderImages, http.MethodPut, "/api/restaurants/:id/images/order", target, &userID, tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestGetRestaurant_BySlug(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	slug := "la-piazza-almaty"
+	restaurant.Slug = &slug
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, &stubFavoriteService{}, nil)
+
+	w := performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id", "/api/restaurants/la-piazza-almaty", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"`+restaurant.ID.String()+`"`)
+	assert.Equal(t, http.StatusOK, getRestaurantDetails(h, restaurant.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id",
+		"/api/restaurants/la-piazza", nil, "").Code)
+}
+
+func TestGetRestaurant_PendingOnlyForOwnerAndAdmins(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	restaurant.Status = domain.RestaurantStatusPending
+	h := NewRestaurantHandler(&stubRestaurantService{restaurant: restaurant}, &stubAvailabilityService{}, &stubBusynessService{}, &stubFavoriteService{}, nil)
+	target := "/api/restaurants/" + restaurant.ID.String()
+	stranger := uuid.New()
+
+	assert.Equal(t, http.StatusNotFound, getRestaurantDetails(h, restaurant.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id", target, &stranger, "").Code)
+	assert.Equal(t, http.StatusOK, performAsUser(h.GetRestaurant, http.MethodGet, "/api/restaurants/:id", target, &restaurant.OwnerID, "").Code)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/restaurants/:id", func(c *gin.Context) {
+		c.Set("user_id", stranger)
+		c.Set("user_role", domain.UserRoleAdmin)
+	}, h.GetRestaurant)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// pngBytes starts with the PNG signature, so sniffing takes it for a PNG.
+const pngBytes = "\x89PNG\r\n\x1a\n image bytes"
+
+// postImages sends files, by name, as the "images" parts of a bulk upload.
+func postImages(t *testing.T, h *RestaurantHandler, restaurantID uuid.UUID, userID uuid.UUID, files map[string]string, order []string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, name := range order {
+		part, err := writer.CreateFormFile("images", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/restaurants/:id/images/bulk", func(c *gin.Context) {
+		c.Set("user_id", userID)
+	}, h.AddImages)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/restaurants/"+restaurantID.String()+"/images/bulk", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAddImages_PerFileResults(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+	svc := &stubRestaurantService{restaurant: restaurant}
+	h := NewRestaurantHandler(svc, nil, nil, nil, nil)
+	files := map[string]string{
+		"front.png":  pngBytes,
+		"menu.txt":   "not an image",
+		"broken.png": pngBytes,
+		"hall.png":   pngBytes,
+	}
+
+	w := postImages(t, h, restaurant.ID, restaurant.OwnerID, files, []string{"front.png", "menu.txt", "broken.png", "hall.png"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body ImageUploadResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Results, 4)
+	assert.Equal(t, []string{"front.png", "broken.png", "hall.png"}, svc.uploaded)
+
+	assert.Equal(t, "front.png", body.Results[0].Filename)
+	assert.NotNil(t, body.Results[0].Image)
+	assert.Empty(t, body.Results[0].Error)
+	assert.Equal(t, "menu.txt", body.Results[1].Filename)
+	assert.Nil(t, body.Results[1].Image)
+	assert.Equal(t, "image must be a JPEG, PNG, GIF or WebP file", body.Results[1].Error)
+	assert.Equal(t, "storage unavailable", body.Results[2].Error)
+	assert.Equal(t, "hall.png", body.Results[3].Filename)
+	assert.NotNil(t, body.Results[3].Image)
+}
+
+func TestAddImages_BadRequests(t *testing.T) {
+	restaurant := sampleRestaurants(1)[0]
+
+	tooMany := make(map[string]string)
+	var order []string
+	for i := 0; i <= maxBulkImages; i++ {
+		name := fmt.Sprintf("photo-%d.png", i)
+		tooMany[name] = pngBytes
+		order = append(order, name)
+	}
+
+	cases := map[string]struct {
+		files map[string]string
+		order []string
+		user  uuid.UUID
+		want  int
+	}{
+		"no files":  {nil, nil, restaurant.OwnerID, http.StatusBadRequest},
+		"too many":  {tooMany, order, restaurant.OwnerID, http.StatusBadRequest},
+		"not owner": {map[string]string{"a.png": pngBytes}, []string{"a.png"}, uuid.New(), http.StatusUnauthorized},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubRestaurantService{restaurant: restaurant}
+
+			w := postImages(t, NewRestaurantHandler(svc, nil, nil, nil, nil), restaurant.ID, tc.user, tc.files, tc.order)
+
+			assert.Equal(t, tc.want, w.Code)
+			if tc.want == http.StatusBadRequest {
+				assert.Empty(t, svc.uploaded)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RestaurantStatsHandler struct {
+	statsService service.RestaurantStatsService
+}
+
+func NewRestaurantStatsHandler(statsService service.RestaurantStatsService) *RestaurantStatsHandler {
+	return &RestaurantStatsHandler{statsService: statsService}
+}
+
+// @Summary Restaurant statistics
+// @Description Dashboard figures for the restaurant's owner and managers. Today and this week are counted on the restaurant's clock; cancellation rate, average party size and revenue cover [from, to), the last 30 days by default. Revenue sums completed payments without service fees.
+// @Tags Restaurants
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param from query string false "Start of the range, RFC3339 (inclusive)"
+// @Param to query string false "End of the range, RFC3339 (exclusive); defaults to now"
+// @Success 200 {object} RestaurantStatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/stats [get]
+func (h *RestaurantStatsHandler) GetStats(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	var from, to time.Time
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid %s format, use RFC3339 with timezone offset, e.g. %s", bound.name, apitime.Example)})
+			return
+		}
+		*bound.target = parsed.Time
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.Stats(c.Request.Context(), restaurantID, userID, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidStatsRange):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, RestaurantStatsResponse{
+		RestaurantID:      stats.RestaurantID,
+		From:              apitime.New(stats.From),
+		To:                apitime.New(stats.To),
+		BookingsToday:     stats.BookingsToday,
+		BookingsThisWeek:  stats.BookingsThisWeek,
+		Bookings:          stats.Bookings,
+		CancelledBookings: stats.CancelledBookings,
+		CancellationRate:  stats.CancellationRate,
+		AveragePartySize:  stats.AveragePartySize,
+		Revenue:           stats.Revenue,
+	})
+}
+
+type RestaurantStatsResponse struct {
+	RestaurantID      uuid.UUID    `json:"restaurant_id"`
+	From              apitime.Time `json:"from"`
+	To                apitime.Time `json:"to"`
+	BookingsToday     int64        `json:"bookings_today"`
+	BookingsThisWeek  int64        `json:"bookings_this_week"`
+	Bookings          int64        `json:"bookings"`
+	CancelledBookings int64        `json:"cancelled_bookings"`
+	// CancellationRate is a fraction between 0 and 1.
+	CancellationRate float64 `json:"cancellation_rate"`
+	AveragePartySize float64 `json:"average_party_size"`
+	// Revenue is in KZT.
+	Revenue int64 `json:"revenue"`
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRestaurantStatsService struct {
+	stats    *service.RestaurantStats
+	err      error
+	from, to time.Time
+}
+
+func (s *stubRestaurantStatsService) Stats(ctx context.Context, restaurantID, userID uuid.UUID, from, to time.Time) (*service.RestaurantStats, error) {
+	s.from, s.to = from, to
+	return s.stats, s.err
+}
+
+func TestGetRestaurantStats(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	from := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	stub := &stubRestaurantStatsService{stats: &service.RestaurantStats{
+		RestaurantID:      restaurantID,
+		From:              from,
+		To:                to,
+		BookingsToday:     3,
+		BookingsThisWeek:  12,
+		Bookings:          40,
+		CancelledBookings: 8,
+		CancellationRate:  0.2,
+		AveragePartySize:  3.5,
+		Revenue:           420000,
+	}}
+
+	w := performAsUser(NewRestaurantStatsHandler(stub).GetStats, http.MethodGet, "/api/restaurants/:id/stats",
+		"/api/restaurants/"+restaurantID.String()+"/stats?from=2024-05-01T05:00:00%2B05:00&to=2024-06-01T00:00:00Z", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, from.Equal(stub.from))
+	assert.True(t, to.Equal(stub.to))
+
+	var resp RestaurantStatsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(3), resp.BookingsToday)
+	assert.Equal(t, int64(12), resp.BookingsThisWeek)
+	assert.Equal(t, 0.2, resp.CancellationRate)
+	assert.Equal(t, 3.5, resp.AveragePartySize)
+	assert.Equal(t, int64(420000), resp.Revenue)
+	assert.True(t, from.Equal(resp.From.Time))
+}
+
+func TestGetRestaurantStats_DefaultsRange(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	stub := &stubRestaurantStatsService{stats: &service.RestaurantStats{RestaurantID: restaurantID}}
+
+	w := performAsUser(NewRestaurantStatsHandler(stub).GetStats, http.MethodGet, "/api/restaurants/:id/stats",
+		"/api/restaurants/"+restaurantID.String()+"/stats", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, stub.from.IsZero())
+	assert.True(t, stub.to.IsZero())
+}
+
+func TestGetRestaurantStats_Errors(t *testing.T) {
+	userID := uuid.New()
+	route := "/api/restaurants/:id/stats"
+	target := "/api/restaurants/" + uuid.NewString() + "/stats"
+
+	cases := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{"invalid from", "?from=2024-05-01", nil, http.StatusBadRequest},
+		{"invalid range", "", service.ErrInvalidStatsRange, http.StatusBadRequest},
+		{"not found", "", service.ErrRestaurantNotFound, http.StatusNotFound},
+		{"not owner", "", service.ErrUnauthorized, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performAsUser(NewRestaurantStatsHandler(&stubRestaurantStatsService{err: tc.err}).GetStats, http.MethodGet, route, target+tc.query, &userID, "")
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
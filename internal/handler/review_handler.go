@@ -1,49 +1,98 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type ReviewHandler struct {
+	reviewService  service.ReviewService
 	reviewRepo     repository.ReviewRepository
 	restaurantRepo repository.RestaurantRepository
 }
 
-func NewReviewHandler(reviewRepo repository.ReviewRepository, restaurantRepo repository.RestaurantRepository) *ReviewHandler {
+func NewReviewHandler(reviewService service.ReviewService, reviewRepo repository.ReviewRepository, restaurantRepo repository.RestaurantRepository) *ReviewHandler {
 	return &ReviewHandler{
+		reviewService:  reviewService,
 		reviewRepo:     reviewRepo,
 		restaurantRepo: restaurantRepo,
 	}
 }
 
 func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req CreateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	review := &domain.Review{
+	review, err := h.reviewService.CreateReview(c.Request.Context(), userID, service.CreateReviewRequest{
 		RestaurantID: req.RestaurantID,
-		UserID:       req.UserID,
 		BookingID:    req.BookingID,
 		Rating:       req.Rating,
 		Comment:      req.Comment,
-		IsVisible:    true,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReviewLimitReached):
+			c.JSON(http.StatusConflict, ReviewLimitResponse{Error: err.Error(), ExistingReviewID: review.ID})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
 	}
 
-	if err := h.reviewRepo.Create(c.Request.Context(), review); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	c.JSON(http.StatusCreated, review)
+}
+
+// UpsertMyReview edits the authenticated user's review of the restaurant
+// while it is inside the review window, and creates a new one otherwise.
+func (h *ReviewHandler) UpsertMyReview(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusCreated, review)
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	var req UpsertMyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review, created, err := h.reviewService.UpsertMyReview(c.Request.Context(), userID, restaurantID, req.Rating, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, review)
 }
 
 func (h *ReviewHandler) GetReview(c *gin.Context) {
@@ -71,23 +120,23 @@ func (h *ReviewHandler) GetRestaurantReviews(c *gin.Context) {
 		return
 	}
 
-	limit := 10
-	offset := 0
-
-	if l := c.Query("limit"); l != "" {
-		fmt.Sscanf(l, "%d", &limit)
-	}
-	if o := c.Query("offset"); o != "" {
-		fmt.Sscanf(o, "%d", &offset)
+	page, ok := paginationParams(c, 10)
+	if !ok {
+		return
 	}
 
-	reviews, err := h.reviewRepo.GetByRestaurantID(c.Request.Context(), restaurantID, limit, offset)
+	reviews, err := h.reviewRepo.GetByRestaurantID(c.Request.Context(), restaurantID, page.Limit, page.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	total, err := h.reviewRepo.CountByRestaurantID(c.Request.Context(), restaurantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, reviews)
+	c.JSON(http.StatusOK, PaginatedResponse{Items: reviews, Total: total, PaginationParams: page})
 }
 
 func (h *ReviewHandler) GetUserReviews(c *gin.Context) {
@@ -115,9 +164,8 @@ func (h *ReviewHandler) UpdateReview(c *gin.Context) {
 		return
 	}
 
-	review, err := h.reviewRepo.GetByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "review not found"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -127,15 +175,9 @@ func (h *ReviewHandler) UpdateReview(c *gin.Context) {
 		return
 	}
 
-	if req.Rating != nil {
-		review.Rating = *req.Rating
-	}
-	if req.Comment != nil {
-		review.Comment = *req.Comment
-	}
-
-	if err := h.reviewRepo.Update(c.Request.Context(), review); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	review, err := h.reviewService.UpdateReview(c.Request.Context(), id, userID, req.Rating, req.Comment)
+	if err != nil {
+		writeReviewError(c, err)
 		return
 	}
 
@@ -150,17 +192,56 @@ func (h *ReviewHandler) DeleteReview(c *gin.Context) {
 		return
 	}
 
-	if err := h.reviewRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "review not found"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.DeleteReview(c.Request.Context(), id, userID); err != nil {
+		writeReviewError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// SetReviewVisibility lets an admin hide a review from the restaurant page
+// and its rating, or show it again.
+func (h *ReviewHandler) SetReviewVisibility(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid review id"})
+		return
+	}
+
+	var req ReviewVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.SetReviewVisibility(c.Request.Context(), id, *req.IsVisible)
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func writeReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrNotReviewAuthor):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
 type CreateReviewRequest struct {
 	RestaurantID uuid.UUID  `json:"restaurant_id" binding:"required"`
-	UserID       uuid.UUID  `json:"user_id" binding:"required"`
 	BookingID    *uuid.UUID `json:"booking_id"`
 	Rating       int        `json:"rating" binding:"required,min=1,max=5"`
 	Comment      string     `json:"comment"`
@@ -170,3 +251,17 @@ type UpdateReviewRequest struct {
 	Rating  *int    `json:"rating" binding:"omitempty,min=1,max=5"`
 	Comment *string `json:"comment"`
 }
+
+type ReviewVisibilityRequest struct {
+	IsVisible *bool `json:"is_visible" binding:"required"`
+}
+
+type UpsertMyReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment"`
+}
+
+type ReviewLimitResponse struct {
+	Error            string    `json:"error"`
+	ExistingReviewID uuid.UUID `json:"existing_review_id"`
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubReviewService struct {
+	service.ReviewService
+	existing *domain.Review
+	userID   uuid.UUID
+}
+
+func (s *stubReviewService) CreateReview(ctx context.Context, userID uuid.UUID, req service.CreateReviewRequest) (*domain.Review, error) {
+	s.userID = userID
+	if s.existing != nil {
+		return s.existing, service.ErrReviewLimitReached
+	}
+	return &domain.Review{ID: uuid.New(), UserID: userID, RestaurantID: req.RestaurantID, Rating: req.Rating}, nil
+}
+
+func (s *stubReviewService) UpsertMyReview(ctx context.Context, userID, restaurantID uuid.UUID, rating int, comment string) (*domain.Review, bool, error) {
+	s.userID = userID
+	if s.existing != nil {
+		s.existing.Rating = rating
+		return s.existing, false, nil
+	}
+	return &domain.Review{ID: uuid.New(), UserID: userID, RestaurantID: restaurantID, Rating: rating}, true, nil
+}
+
+func (s *stubReviewService) SetReviewVisibility(ctx context.Context, id uuid.UUID, visible bool) (*domain.Review, error) {
+	if s.existing == nil || s.existing.ID != id {
+		return nil, service.ErrReviewNotFound
+	}
+	s.existing.IsVisible = visible
+	return s.existing, nil
+}
+
+func (s *stubReviewService) UpdateReview(ctx context.Context, id, userID uuid.UUID, rating *int, comment *string) (*domain.Review, error) {
+	s.userID = userID
+	if s.existing == nil || s.existing.ID != id {
+		return nil, service.ErrReviewNotFound
+	}
+	if s.existing.UserID != userID {
+		return nil, service.ErrNotReviewAuthor
+	}
+	return s.existing, nil
+}
+
+func (s *stubReviewService) DeleteReview(ctx context.Context, id, userID uuid.UUID) error {
+	_, err := s.UpdateReview(ctx, id, userID, nil, nil)
+	return err
+}
+
+func TestUpdateAndDeleteReview_Handler(t *testing.T) {
+	authorID := uuid.New()
+	otherID := uuid.New()
+	cases := map[string]struct {
+		userID *uuid.UUID
+		want   int
+	}{
+		"author":   {&authorID, http.StatusOK},
+		"someone":  {&otherID, http.StatusForbidden},
+		"no token": {nil, http.StatusUnauthorized},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			review := &domain.Review{ID: uuid.New(), UserID: authorID, Rating: 3}
+			h := NewReviewHandler(&stubReviewService{existing: review}, nil, nil)
+			target := "/api/reviews/" + review.ID.String()
+
+			w := performAsUser(h.UpdateReview, http.MethodPut, "/api/reviews/:id", target, tc.userID, `{"rating":5}`)
+			assert.Equal(t, tc.want, w.Code)
+
+			w = performAsUser(h.DeleteReview, http.MethodDelete, "/api/reviews/:id", target, tc.userID, "")
+			if tc.want == http.StatusOK {
+				assert.Equal(t, http.StatusNoContent, w.Code)
+			} else {
+				assert.Equal(t, tc.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestCreateReview_UsesTokenUser(t *testing.T) {
+	svc := &stubReviewService{}
+	userID := uuid.New()
+	body := `{"restaurant_id":"` + uuid.NewString() + `","user_id":"` + uuid.NewString() + `","rating":4}`
+
+	w := performAsUser(NewReviewHandler(svc, nil, nil).CreateReview, http.MethodPost, "/api/reviews",
+		"/api/reviews", &userID, body)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, svc.userID)
+}
+
+func TestCreateReview_LimitReached(t *testing.T) {
+	svc := &stubReviewService{existing: &domain.Review{ID: uuid.New()}}
+	userID := uuid.New()
+
+	w := performAsUser(NewReviewHandler(svc, nil, nil).CreateReview, http.MethodPost, "/api/reviews",
+		"/api/reviews", &userID, `{"restaurant_id":"`+uuid.NewString()+`","rating":1}`)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var resp ReviewLimitResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, svc.existing.ID, resp.ExistingReviewID)
+	assert.Equal(t, service.ErrReviewLimitReached.Error(), resp.Error)
+}
+
+func TestUpsertMyReview_Handler(t *testing.T) {
+	userID := uuid.New()
+	restaurantID := uuid.New()
+	target := "/api/restaurants/" + restaurantID.String() + "/my-review"
+
+	svc := &stubReviewService{}
+	w := performAsUser(NewReviewHandler(svc, nil, nil).UpsertMyReview, http.MethodPut, "/api/restaurants/:id/my-review",
+		target, &userID, `{"rating":5}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	svc = &stubReviewService{existing: &domain.Review{ID: uuid.New(), Rating: 2}}
+	w = performAsUser(NewReviewHandler(svc, nil, nil).UpsertMyReview, http.MethodPut, "/api/restaurants/:id/my-review",
+		target, &userID, `{"rating":5}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, svc.existing.Rating)
+
+	w = performAsUser(NewReviewHandler(svc, nil, nil).UpsertMyReview, http.MethodPut, "/api/restaurants/:id/my-review",
+		target, nil, `{"rating":5}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetReviewVisibility_Handler(t *testing.T) {
+	adminID := uuid.New()
+	svc := &stubReviewService{existing: &domain.Review{ID: uuid.New(), IsVisible: true}}
+	route := "/api/admin/reviews/:id/visibility"
+
+	w := performAsUser(NewReviewHandler(svc, nil, nil).SetReviewVisibility, http.MethodPatch, route,
+		"/api/admin/reviews/"+svc.existing.ID.String()+"/visibility", &adminID, `{"is_visible":false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, svc.existing.IsVisible)
+
+	w = performAsUser(NewReviewHandler(svc, nil, nil).SetReviewVisibility, http.MethodPatch, route,
+		"/api/admin/reviews/"+svc.existing.ID.String()+"/visibility", &adminID, `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performAsUser(NewReviewHandler(svc, nil, nil).SetReviewVisibility, http.MethodPatch, route,
+		"/api/admin/reviews/"+uuid.NewString()+"/visibility", &adminID, `{"is_visible":true}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubReviewRepository struct {
+	repository.ReviewRepository
+	reviews       []*domain.Review
+	total         int64
+	limit, offset int
+}
+
+func (r *stubReviewRepository) GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.Review, error) {
+	r.limit, r.offset = limit, offset
+	return r.reviews, nil
+}
+
+func (r *stubReviewRepository) CountByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (int64, error) {
+	return r.total, nil
+}
+
+func TestGetRestaurantReviews_PageEnvelope(t *testing.T) {
+	restaurantID := uuid.New()
+	repo := &stubReviewRepository{reviews: []*domain.Review{{ID: uuid.New(), RestaurantID: restaurantID, Rating: 5}}, total: 31}
+
+	w := performAsUser(NewReviewHandler(nil, repo, nil).GetRestaurantReviews, http.MethodGet, "/api/restaurants/:id/reviews",
+		"/api/restaurants/"+restaurantID.String()+"/reviews?limit=1000&offset=30", nil, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Items  []domain.Review `json:"items"`
+		Total  int64           `json:"total"`
+		Limit  int             `json:"limit"`
+		Offset int             `json:"offset"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, int64(31), page.Total)
+	assert.Equal(t, 100, page.Limit)
+	assert.Equal(t, 30, page.Offset)
+	assert.Equal(t, 100, repo.limit)
+}
+
+func TestGetRestaurantReviews_NegativeOffset(t *testing.T) {
+	repo := &stubReviewRepository{}
+
+	w := performAsUser(NewReviewHandler(nil, repo, nil).GetRestaurantReviews, http.MethodGet, "/api/restaurants/:id/reviews",
+		"/api/restaurants/"+uuid.NewString()+"/reviews?offset=-10", nil, "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Zero(t, repo.limit)
+}
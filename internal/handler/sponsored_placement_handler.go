@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SponsoredPlacementHandler struct {
+	sponsoredService service.SponsoredPlacementService
+}
+
+func NewSponsoredPlacementHandler(sponsoredService service.SponsoredPlacementService) *SponsoredPlacementHandler {
+	return &SponsoredPlacementHandler{sponsoredService: sponsoredService}
+}
+
+// @Summary Create a sponsored placement
+// @Description Promotes a restaurant in restaurant lists from starts_at until ends_at, or until clicks * cost_per_click reaches budget. city and cuisine_type limit it to lists for that city or cuisine.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body CreateSponsoredPlacementRequest true "Placement"
+// @Success 201 {object} domain.SponsoredPlacement
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/sponsored-placements [post]
+func (h *SponsoredPlacementHandler) CreatePlacement(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateSponsoredPlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	placement, err := h.sponsoredService.Create(c.Request.Context(), adminID, service.CreateSponsoredPlacementRequest{
+		RestaurantID: req.RestaurantID,
+		City:         req.City,
+		CuisineType:  req.CuisineType,
+		Weight:       req.Weight,
+		StartsAt:     req.StartsAt.Time,
+		EndsAt:       req.EndsAt.Time,
+		Budget:       req.Budget,
+		CostPerClick: req.CostPerClick,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, placement)
+}
+
+// @Summary List sponsored placements
+// @Tags Admin
+// @Produce json
+// @Param restaurant_id query string false "Only this restaurant's placements"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.SponsoredPlacement
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/sponsored-placements [get]
+func (h *SponsoredPlacementHandler) ListPlacements(c *gin.Context) {
+	var restaurantID *uuid.UUID
+	if raw := c.Query("restaurant_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant_id"})
+			return
+		}
+		restaurantID = &id
+	}
+
+	limit := 20
+	offset := 0
+	if l := c.Query("limit"); l != "" {
+		fmt.Sscanf(l, "%d", &limit)
+	}
+	if o := c.Query("offset"); o != "" {
+		fmt.Sscanf(o, "%d", &offset)
+	}
+
+	placements, err := h.sponsoredService.List(c.Request.Context(), restaurantID, limit, offset)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, placements)
+}
+
+// @Summary Get a sponsored placement
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Placement ID"
+// @Success 200 {object} domain.SponsoredPlacement
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/sponsored-placements/{id} [get]
+func (h *SponsoredPlacementHandler) GetPlacement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid placement id"})
+		return
+	}
+
+	placement, err := h.sponsoredService.Get(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, placement)
+}
+
+// @Summary Update a sponsored placement
+// @Description Changes the fields that are sent. An empty city or cuisine_type removes that scope. The impression and click counters cannot be edited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Placement ID"
+// @Param request body UpdateSponsoredPlacementRequest true "Changes"
+// @Success 200 {object} domain.SponsoredPlacement
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/sponsored-placements/{id} [patch]
+func (h *SponsoredPlacementHandler) UpdatePlacement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid placement id"})
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSponsoredPlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	serviceReq := service.UpdateSponsoredPlacementRequest{
+		City:         req.City,
+		CuisineType:  req.CuisineType,
+		Weight:       req.Weight,
+		Budget:       req.Budget,
+		CostPerClick: req.CostPerClick,
+	}
+	if req.StartsAt != nil {
+		serviceReq.StartsAt = &req.StartsAt.Time
+	}
+	if req.EndsAt != nil {
+		serviceReq.EndsAt = &req.EndsAt.Time
+	}
+
+	placement, err := h.sponsoredService.Update(c.Request.Context(), adminID, id, serviceReq)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, placement)
+}
+
+// @Summary Delete a sponsored placement
+// @Tags Admin
+// @Param id path string true "Placement ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/sponsored-placements/{id} [delete]
+func (h *SponsoredPlacementHandler) DeletePlacement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid placement id"})
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sponsoredService.Delete(c.Request.Context(), adminID, id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Report sponsored result events
+// @Description Clients report when a sponsored result from a restaurant list is shown (impression) or opened (click). Counters are updated in the background, so the request is accepted before they change.
+// @Tags Restaurants
+// @Accept json
+// @Param request body SponsoredEventsRequest true "Events"
+// @Success 202
+// @Failure 400 {object} ErrorResponse
+// @Router /api/sponsored-placements/events [post]
+func (h *SponsoredPlacementHandler) RecordEvents(c *gin.Context) {
+	var req SponsoredEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	for _, event := range req.Events {
+		h.sponsoredService.RecordEvent(event.PlacementID, event.Type)
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *SponsoredPlacementHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSponsoredPlacement):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "weight, budget and cost_per_click must be positive and ends_at must be after starts_at"})
+	case errors.Is(err, service.ErrInvalidCuisineType):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrSponsoredPlacementNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "sponsored placement not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type CreateSponsoredPlacementRequest struct {
+	RestaurantID uuid.UUID           `json:"restaurant_id" binding:"required"`
+	City         *string             `json:"city" example:"Almaty"`
+	CuisineType  *domain.CuisineType `json:"cuisine_type"`
+	// Weight defaults to 1.
+	Weight       int          `json:"weight" binding:"omitempty,min=1"`
+	StartsAt     apitime.Time `json:"starts_at" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T00:00:00Z"`
+	EndsAt       apitime.Time `json:"ends_at" binding:"required" swaggertype:"string" format:"date-time" example:"2024-07-01T00:00:00Z"`
+	Budget       int          `json:"budget" binding:"required,min=1" example:"500000"`
+	CostPerClick int          `json:"cost_per_click" binding:"required,min=1" example:"150"`
+}
+
+type UpdateSponsoredPlacementRequest struct {
+	City         *string             `json:"city"`
+	CuisineType  *domain.CuisineType `json:"cuisine_type"`
+	Weight       *int                `json:"weight" binding:"omitempty,min=1"`
+	StartsAt     *apitime.Time       `json:"starts_at" swaggertype:"string" format:"date-time"`
+	EndsAt       *apitime.Time       `json:"ends_at" swaggertype:"string" format:"date-time"`
+	Budget       *int                `json:"budget" binding:"omitempty,min=1"`
+	CostPerClick *int                `json:"cost_per_click" binding:"omitempty,min=1"`
+}
+
+type SponsoredEventsRequest struct {
+	Events []SponsoredEvent `json:"events" binding:"required,min=1,max=20,dive"`
+}
+
+type SponsoredEvent struct {
+	PlacementID uuid.UUID                  `json:"placement_id" binding:"required"`
+	Type        service.SponsoredEventType `json:"type" binding:"required,oneof=impression click" swaggertype:"string" enums:"impression,click"`
+}
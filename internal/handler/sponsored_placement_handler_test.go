@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPlacementAdminService struct {
+	service.SponsoredPlacementService
+	created *service.CreateSponsoredPlacementRequest
+	err     error
+	events  map[uuid.UUID][]service.SponsoredEventType
+}
+
+func (s *stubPlacementAdminService) Create(ctx context.Context, adminID uuid.UUID, req service.CreateSponsoredPlacementRequest) (*domain.SponsoredPlacement, error) {
+	s.created = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.SponsoredPlacement{ID: uuid.New(), RestaurantID: req.RestaurantID, CreatedBy: adminID}, nil
+}
+
+func (s *stubPlacementAdminService) RecordEvent(placementID uuid.UUID, event service.SponsoredEventType) {
+	if s.events == nil {
+		s.events = make(map[uuid.UUID][]service.SponsoredEventType)
+	}
+	s.events[placementID] = append(s.events[placementID], event)
+}
+
+func TestCreateSponsoredPlacement(t *testing.T) {
+	adminID, restaurantID := uuid.New(), uuid.New()
+	stub := &stubPlacementAdminService{}
+	body := `{"restaurant_id":"` + restaurantID.String() + `","city":"Almaty","starts_at":"2024-06-01T00:00:00+05:00","ends_at":"2024-07-01T00:00:00Z","budget":500000,"cost_per_click":150}`
+
+	w := performAsUser(NewSponsoredPlacementHandler(stub).CreatePlacement, http.MethodPost, "/api/admin/sponsored-placements",
+		"/api/admin/sponsored-placements", &adminID, body)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, stub.created)
+	assert.Equal(t, restaurantID, stub.created.RestaurantID)
+	assert.Equal(t, "Almaty", *stub.created.City)
+	assert.Equal(t, 500000, stub.created.Budget)
+	assert.Equal(t, 19, stub.created.StartsAt.UTC().Hour())
+}
+
+func TestCreateSponsoredPlacement_BadRequest(t *testing.T) {
+	adminID := uuid.New()
+	valid := `"restaurant_id":"` + uuid.NewString() + `","starts_at":"2024-06-01T00:00:00Z","ends_at":"2024-07-01T00:00:00Z"`
+
+	cases := map[string]struct {
+		body string
+		err  error
+	}{
+		"no budget":       {`{` + valid + `,"cost_per_click":150}`, nil},
+		"negative weight": {`{` + valid + `,"budget":100,"cost_per_click":150,"weight":-2}`, nil},
+		"invalid window":  {`{` + valid + `,"budget":100,"cost_per_click":150}`, service.ErrInvalidSponsoredPlacement},
+		"unknown cuisine": {`{` + valid + `,"budget":100,"cost_per_click":150}`, service.ErrInvalidCuisineType},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubPlacementAdminService{err: tc.err}
+
+			w := performAsUser(NewSponsoredPlacementHandler(stub).CreatePlacement, http.MethodPost, "/api/admin/sponsored-placements",
+				"/api/admin/sponsored-placements", &adminID, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestCreateSponsoredPlacement_RestaurantNotFound(t *testing.T) {
+	adminID := uuid.New()
+	stub := &stubPlacementAdminService{err: service.ErrRestaurantNotFound}
+	body := `{"restaurant_id":"` + uuid.NewString() + `","starts_at":"2024-06-01T00:00:00Z","ends_at":"2024-07-01T00:00:00Z","budget":100,"cost_per_click":10}`
+
+	w := performAsUser(NewSponsoredPlacementHandler(stub).CreatePlacement, http.MethodPost, "/api/admin/sponsored-placements",
+		"/api/admin/sponsored-placements", &adminID, body)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRecordSponsoredEvents(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	stub := &stubPlacementAdminService{}
+	body := `{"events":[{"placement_id":"` + first.String() + `","type":"impression"},{"placement_id":"` + first.String() + `","type":"click"},{"placement_id":"` + second.String() + `","type":"impression"}]}`
+
+	w := performAsUser(NewSponsoredPlacementHandler(stub).RecordEvents, http.MethodPost, "/api/sponsored-placements/events",
+		"/api/sponsored-placements/events", nil, body)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, []service.SponsoredEventType{service.SponsoredEventImpression, service.SponsoredEventClick}, stub.events[first])
+	assert.Equal(t, []service.SponsoredEventType{service.SponsoredEventImpression}, stub.events[second])
+}
+
+func TestRecordSponsoredEvents_BadRequest(t *testing.T) {
+	id := uuid.NewString()
+
+	for name, body := range map[string]string{
+		"unknown type": `{"events":[{"placement_id":"` + id + `","type":"hover"}]}`,
+		"no events":    `{"events":[]}`,
+		"no placement": `{"events":[{"type":"click"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubPlacementAdminService{}
+
+			w := performAsUser(NewSponsoredPlacementHandler(stub).RecordEvents, http.MethodPost, "/api/sponsored-placements/events",
+				"/api/sponsored-placements/events", nil, body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Empty(t, stub.events)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaffPinHandler struct {
+	staffPinService service.StaffPinService
+}
+
+func NewStaffPinHandler(staffPinService service.StaffPinService) *StaffPinHandler {
+	return &StaffPinHandler{staffPinService: staffPinService}
+}
+
+// SetPin sets the PIN the authenticated owner or manager enters on shared
+// tablets of their restaurants.
+func (h *StaffPinHandler) SetPin(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req StaffPinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.staffPinService.SetPin(c.Request.Context(), userID, req.Pin); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidStaffPinFormat):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "staff PIN updated"})
+}
+
+type StaffPinRequest struct {
+	Pin string `json:"pin" binding:"required" example:"4821"`
+}
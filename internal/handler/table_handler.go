@@ -1,46 +1,201 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"restaurant-booking/internal/domain"
-	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 )
 
+// maxTableImportBytes caps the size of a table import upload.
+const maxTableImportBytes = 1 << 20
+
 type TableHandler struct {
-	tableRepo repository.TableRepository
+	tableService service.TableService
+	availability service.AvailabilityService
 }
 
-func NewTableHandler(tableRepo repository.TableRepository) *TableHandler {
-	return &TableHandler{tableRepo: tableRepo}
+func NewTableHandler(tableService service.TableService, availability service.AvailabilityService) *TableHandler {
+	return &TableHandler{
+		tableService: tableService,
+		availability: availability,
+	}
 }
 
 func (h *TableHandler) CreateTable(c *gin.Context) {
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req CreateTableRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	table := &domain.Table{
-		RestaurantID: req.RestaurantID,
-		TableNumber:  req.TableNumber,
-		MinCapacity:  req.MinCapacity,
-		MaxCapacity:  req.MaxCapacity,
-		LocationType: req.LocationType,
-		XPosition:    req.XPosition,
-		YPosition:    req.YPosition,
+	table, err := h.tableService.CreateTable(c.Request.Context(), req.RestaurantID, ownerID, req.TableFields.toService())
+	if err != nil {
+		writeTableError(c, err)
+		return
 	}
 
-	if err := h.tableRepo.Create(c.Request.Context(), table); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	c.JSON(http.StatusCreated, table)
+}
+
+func (h *TableHandler) BulkCreateTables(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, table)
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req BulkCreateTablesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	serviceReq := service.BulkCreateTablesRequest{Tables: make([]service.CreateTableRequest, len(req.Tables))}
+	for i, table := range req.Tables {
+		serviceReq.Tables[i] = table.toService()
+	}
+
+	tables, err := h.tableService.BulkCreateTables(c.Request.Context(), restaurantID, ownerID, serviceReq)
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tables)
+}
+
+func (h *TableHandler) UpdateTablePositions(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTablePositionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	positions := make([]service.TablePosition, len(req.Positions))
+	for i, position := range req.Positions {
+		positions[i] = service.TablePosition{
+			TableID:   position.TableID,
+			XPosition: position.XPosition,
+			YPosition: position.YPosition,
+		}
+	}
+
+	tables, err := h.tableService.UpdateTablePositions(c.Request.Context(), restaurantID, ownerID, positions)
+	if err != nil {
+		var rejected *service.TablePositionsError
+		if errors.As(err, &rejected) {
+			resp := TablePositionsErrorResponse{Error: err.Error(), Items: make([]TablePositionIssue, len(rejected.Issues))}
+			for i, issue := range rejected.Issues {
+				resp.Items[i] = TablePositionIssue{Index: issue.Index, TableID: issue.TableID, Error: issue.Err.Error()}
+			}
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		writeTableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tables)
+}
+
+// @Summary Import tables from a CSV
+// @Description Creates the tables listed in a CSV uploaded as "file", with a header naming the columns table_number, min_capacity, max_capacity, location_type and optionally x and y. Rows are checked like a bulk creation. Rejected rows are skipped and reported by line number; with strict=true any rejected row fails the whole import. Owner only.
+// @Tags Tables
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param file formData file true "CSV of tables, at most 1000 rows"
+// @Param strict query bool false "Create nothing when a row is rejected"
+// @Success 200 {object} TableImportResponse
+// @Failure 400 {object} TableImportResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /api/restaurants/{id}/tables/import [post]
+func (h *TableHandler) ImportTables(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	strict := false
+	if raw := c.Query("strict"); raw != "" {
+		strict, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid strict value"})
+			return
+		}
+	}
+
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTableImportBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("request must be at most %d MB", tooLarge.Limit>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "csv file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "csv file cannot be read"})
+		return
+	}
+	defer file.Close()
+
+	rows, err := h.tableService.ImportTables(c.Request.Context(), restaurantID, ownerID, file, strict)
+	if err != nil {
+		var rejected *service.TableImportError
+		switch {
+		case errors.As(err, &rejected):
+			c.JSON(http.StatusBadRequest, newTableImportResponse(err.Error(), rejected.Rows))
+		case errors.Is(err, service.ErrInvalidTableImport):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			writeTableError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTableImportResponse("", rows))
 }
 
 func (h *TableHandler) GetTable(c *gin.Context) {
@@ -51,9 +206,9 @@ func (h *TableHandler) GetTable(c *gin.Context) {
 		return
 	}
 
-	table, err := h.tableRepo.GetByID(c.Request.Context(), id)
+	table, err := h.tableService.GetTable(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		writeTableError(c, err)
 		return
 	}
 
@@ -68,15 +223,65 @@ func (h *TableHandler) GetRestaurantTables(c *gin.Context) {
 		return
 	}
 
-	tables, err := h.tableRepo.GetByRestaurantID(c.Request.Context(), restaurantID)
+	tables, err := h.tableService.GetTablesByRestaurant(c.Request.Context(), restaurantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		writeTableError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tables)
 }
 
+// @Summary Get the restaurant floor plan
+// @Description Lists the active tables with their coordinates, capacity range, location type and whether each is booked at the given time. Tables without coordinates have null x_position and y_position.
+// @Tags Tables
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param at query string false "RFC3339 timestamp with offset, defaults to now" example(2024-06-01T19:00:00Z)
+// @Success 200 {object} FloorPlanResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/floor-plan [get]
+func (h *TableHandler) GetFloorPlan(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := apitime.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid at format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
+			return
+		}
+		at = parsed.Time
+	}
+
+	tables, err := h.tableService.GetFloorPlan(c.Request.Context(), restaurantID, at)
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	resp := FloorPlanResponse{At: apitime.New(at), Tables: make([]FloorPlanTable, len(tables))}
+	for i, table := range tables {
+		resp.Tables[i] = FloorPlanTable{
+			ID:           table.ID,
+			TableNumber:  table.TableNumber,
+			MinCapacity:  table.MinCapacity,
+			MaxCapacity:  table.MaxCapacity,
+			LocationType: table.LocationType,
+			XPosition:    table.XPosition,
+			YPosition:    table.YPosition,
+			Booked:       table.Booked,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *TableHandler) GetAvailableTables(c *gin.Context) {
 	restaurantIDStr := c.Query("restaurant_id")
 	restaurantID, err := uuid.Parse(restaurantIDStr)
@@ -86,13 +291,34 @@ func (h *TableHandler) GetAvailableTables(c *gin.Context) {
 	}
 
 	minCapacity := 1
-	if mc := c.Query("min_capacity"); mc != "" {
-		fmt.Sscanf(mc, "%d", &minCapacity)
+	if raw := c.Query("min_capacity"); raw != "" {
+		minCapacity, err = strconv.Atoi(raw)
+		if err != nil || minCapacity < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "min_capacity must be a positive integer"})
+			return
+		}
 	}
 
-	tables, err := h.tableRepo.GetAvailableTables(c.Request.Context(), restaurantID, minCapacity)
+	startTime, err := apitime.Parse(c.Query("start_time"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid start_time format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
+		return
+	}
+
+	endTime, err := apitime.Parse(c.Query("end_time"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid end_time format, use RFC3339 with timezone offset, e.g. " + apitime.Example})
+		return
+	}
+
+	if !endTime.After(startTime.Time) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "end_time must be after start_time"})
+		return
+	}
+
+	tables, err := h.tableService.GetAvailableTables(c.Request.Context(), restaurantID, minCapacity, startTime.Time, endTime.Time)
+	if err != nil {
+		writeTableError(c, err)
 		return
 	}
 
@@ -107,9 +333,19 @@ func (h *TableHandler) UpdateTable(c *gin.Context) {
 		return
 	}
 
-	table, err := h.tableRepo.GetByID(c.Request.Context(), id)
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	force, ok := forceParam(c)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableService.GetTable(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		writeTableError(c, err)
 		return
 	}
 
@@ -119,25 +355,29 @@ func (h *TableHandler) UpdateTable(c *gin.Context) {
 		return
 	}
 
-	if req.IsActive != nil {
-		table.IsActive = *req.IsActive
-	}
-	if req.LocationType != nil {
-		table.LocationType = *req.LocationType
-	}
-	if req.XPosition != nil {
-		table.XPosition = req.XPosition
-	}
-	if req.YPosition != nil {
-		table.YPosition = req.YPosition
+	serviceReq := service.UpdateTableRequest{
+		TableNumber:  req.TableNumber,
+		MinCapacity:  req.MinCapacity,
+		MaxCapacity:  req.MaxCapacity,
+		IsActive:     req.IsActive,
+		LocationType: req.LocationType,
+		XPosition:    req.XPosition,
+		YPosition:    req.YPosition,
+
+		MinDurationMinutes:     req.MinDurationMinutes,
+		SlotGranularityMinutes: req.SlotGranularityMinutes,
+		DepositAmount:          req.DepositAmount,
+		MinSpend:               req.MinSpend,
+		Force:                  force,
 	}
 
-	if err := h.tableRepo.Update(c.Request.Context(), table); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	updated, err := h.tableService.UpdateTable(c.Request.Context(), id, table.RestaurantID, ownerID, serviceReq)
+	if err != nil {
+		writeTableError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, table)
+	c.JSON(http.StatusOK, updated)
 }
 
 func (h *TableHandler) DeleteTable(c *gin.Context) {
@@ -148,27 +388,461 @@ func (h *TableHandler) DeleteTable(c *gin.Context) {
 		return
 	}
 
-	if err := h.tableRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+	ownerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	force, ok := forceParam(c)
+	if !ok {
+		return
+	}
+
+	table, err := h.tableService.GetTable(c.Request.Context(), id)
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	if err := h.tableService.DeleteTable(c.Request.Context(), id, table.RestaurantID, ownerID, force); err != nil {
+		writeTableError(c, err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// @Summary Check availability of several tables
+// @Description Checks up to 50 (table_id, start_time, end_time) windows at once. Each result has available set, or a reason: table_not_found, table_inactive, outside_working_hours, blocked_for_maintenance, occupied, timeout or check_failed.
+// @Tags Tables
+// @Accept json
+// @Produce json
+// @Param request body CheckTablesAvailabilityRequest true "Windows to check"
+// @Success 200 {object} CheckTablesAvailabilityResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/tables/check-availability [post]
+func (h *TableHandler) CheckAvailability(c *gin.Context) {
+	var req CheckTablesAvailabilityRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+
+	checks := make([]service.TableCheck, len(req.Checks))
+	for i, check := range req.Checks {
+		if !check.EndTime.After(check.StartTime.Time) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("check at index %d: end_time must be after start_time", i)})
+			return
+		}
+		checks[i] = service.TableCheck{TableID: check.TableID, Start: check.StartTime.Time, End: check.EndTime.Time}
+	}
+
+	results := h.availability.CheckTables(c.Request.Context(), checks)
+
+	resp := CheckTablesAvailabilityResponse{Results: make([]TableAvailabilityResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = TableAvailabilityResult{
+			TableID:   result.TableID,
+			StartTime: apitime.New(result.Start),
+			EndTime:   apitime.New(result.End),
+			Available: result.Available,
+			Reason:    result.Reason,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *TableHandler) BlockTable(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid table id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req BlockTableRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+
+	block, err := h.tableService.BlockTable(c.Request.Context(), id, staffID, service.BlockTableRequest{
+		StartsAt: req.StartsAt.Time,
+		EndsAt:   req.EndsAt.Time,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+func (h *TableHandler) ListBlocks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid table id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	blocks, err := h.tableService.ListTableBlocks(c.Request.Context(), id, staffID)
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, blocks)
+}
+
+// @Summary Get a table's day schedule
+// @Description Divides the restaurant's opening on date, in its own time zone, into slots of slot_minutes and marks each free or booked, with the ID of the booking holding it. Staff of the restaurant only.
+// @Tags Tables
+// @Produce json
+// @Param id path string true "Table ID"
+// @Param date query string true "Day, as YYYY-MM-DD"
+// @Param slot_minutes query int false "Slot length, 5 to 240" default(30)
+// @Success 200 {object} TableScheduleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/tables/{id}/availability [get]
+func (h *TableHandler) GetTableSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid table id"})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	slotMinutes := service.DefaultScheduleSlotMinutes
+	if raw := c.Query("slot_minutes"); raw != "" {
+		slotMinutes, err = strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: service.ErrInvalidScheduleSlot.Error()})
+			return
+		}
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.tableService.GetTableSchedule(c.Request.Context(), id, staffID, date, slotMinutes)
+	if err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	resp := TableScheduleResponse{
+		TableID:     schedule.Table.ID,
+		Date:        schedule.Day.Format("2006-01-02"),
+		Timezone:    schedule.Day.Location().String(),
+		SlotMinutes: slotMinutes,
+		Slots:       make([]TableScheduleSlot, len(schedule.Slots)),
+	}
+	for i, slot := range schedule.Slots {
+		resp.Slots[i] = TableScheduleSlot{
+			StartTime: apitime.New(slot.Start),
+			EndTime:   apitime.New(slot.End),
+			Status:    ScheduleSlotFree,
+			BookingID: slot.BookingID,
+		}
+		if slot.BookingID != nil {
+			resp.Slots[i].Status = ScheduleSlotBooked
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *TableHandler) UnblockTable(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid table id"})
+		return
+	}
+
+	blockID, err := uuid.Parse(c.Param("block_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid block id"})
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tableService.UnblockTable(c.Request.Context(), id, blockID, staffID); err != nil {
+		writeTableError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// forceParam reads the force query parameter, which cancels the upcoming
+// bookings of a table being taken out of service. When it is not a boolean
+// it writes a 400 response and returns false.
+func forceParam(c *gin.Context) (bool, bool) {
+	raw := c.Query("force")
+	if raw == "" {
+		return false, true
+	}
+	force, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid force value"})
+		return false, false
+	}
+	return force, true
+}
+
+func writeTableError(c *gin.Context, err error) {
+	var hasBookings *service.TableHasBookingsError
+	switch {
+	case errors.As(err, &hasBookings):
+		c.JSON(http.StatusConflict, TableHasBookingsResponse{Error: err.Error(), BookingIDs: hasBookings.BookingIDs})
+	case errors.Is(err, service.ErrRestaurantNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+	case errors.Is(err, service.ErrTableNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+	case errors.Is(err, service.ErrTableBlockNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "table block not found"})
+	case errors.Is(err, service.ErrUnauthorized):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized: not the owner"})
+	case errors.Is(err, service.ErrNotRestaurantStaff):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "not staff of this restaurant"})
+	case errors.Is(err, service.ErrInvalidTableNumber), errors.Is(err, service.ErrInvalidCapacity), errors.Is(err, service.ErrInvalidBlockPeriod),
+		errors.Is(err, service.ErrInvalidBookingRules), errors.Is(err, service.ErrInvalidScheduleSlot), errors.Is(err, service.ErrInvalidTableDeposit),
+		errors.Is(err, service.ErrInvalidLocationType):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrDuplicateTableNumber):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
 type CreateTableRequest struct {
-	RestaurantID uuid.UUID           `json:"restaurant_id" binding:"required"`
+	RestaurantID uuid.UUID `json:"restaurant_id" binding:"required"`
+	TableFields
+}
+
+// TableFields describes a new table, on its own or as part of a bulk
+// creation.
+type TableFields struct {
 	TableNumber  string              `json:"table_number" binding:"required"`
 	MinCapacity  int                 `json:"min_capacity" binding:"required,min=1"`
 	MaxCapacity  int                 `json:"max_capacity" binding:"required,min=1"`
 	LocationType domain.LocationType `json:"location_type" binding:"required"`
 	XPosition    *int                `json:"x_position"`
 	YPosition    *int                `json:"y_position"`
+	// MinDurationMinutes and SlotGranularityMinutes override the zone and
+	// restaurant booking rules for this table.
+	MinDurationMinutes     *int `json:"min_duration_minutes" binding:"omitempty,min=0"`
+	SlotGranularityMinutes *int `json:"slot_granularity_minutes" binding:"omitempty,min=0"`
+	// DepositAmount is charged when the table is booked, in KZT. MinSpend is
+	// shown to guests. 0 means none.
+	DepositAmount int `json:"deposit_amount" binding:"min=0" example:"10000"`
+	MinSpend      int `json:"min_spend" binding:"min=0" example:"50000"`
+}
+
+func (f TableFields) toService() service.CreateTableRequest {
+	return service.CreateTableRequest{
+		TableNumber:  f.TableNumber,
+		MinCapacity:  f.MinCapacity,
+		MaxCapacity:  f.MaxCapacity,
+		LocationType: f.LocationType,
+		XPosition:    f.XPosition,
+		YPosition:    f.YPosition,
+
+		MinDurationMinutes:     f.MinDurationMinutes,
+		SlotGranularityMinutes: f.SlotGranularityMinutes,
+		DepositAmount:          f.DepositAmount,
+		MinSpend:               f.MinSpend,
+	}
+}
+
+// BulkCreateTablesRequest creates up to 100 tables of the restaurant in the
+// path at once. Either all of them are created or none is.
+type BulkCreateTablesRequest struct {
+	Tables []TableFields `json:"tables" binding:"required,min=1,max=100,dive"`
+}
+
+// UpdateTablePositionsRequest moves up to 500 tables of the restaurant in the
+// path at once. Null coordinates take a table off the plan.
+type UpdateTablePositionsRequest struct {
+	Positions []TablePositionRequest `json:"positions" binding:"required,min=1,max=500,dive"`
+}
+
+type TablePositionRequest struct {
+	TableID   uuid.UUID `json:"table_id" binding:"required"`
+	XPosition *int      `json:"x_position"`
+	YPosition *int      `json:"y_position"`
+}
+
+// TablePositionsErrorResponse lists the rejected entries of a position
+// update. No table was moved.
+type TablePositionsErrorResponse struct {
+	Error string               `json:"error"`
+	Items []TablePositionIssue `json:"items"`
+}
+
+type TablePositionIssue struct {
+	Index   int       `json:"index"`
+	TableID uuid.UUID `json:"table_id"`
+	Error   string    `json:"error"`
+}
+
+// TableImportResponse reports the rows of a table import. After a failed
+// strict import Error is set and Rows holds only the rejected rows.
+type TableImportResponse struct {
+	Error    string           `json:"error,omitempty"`
+	Created  int              `json:"created"`
+	Rejected int              `json:"rejected"`
+	Rows     []TableImportRow `json:"rows"`
+}
+
+// TableImportRow is one data line of the CSV. Line counts the header as
+// line 1.
+type TableImportRow struct {
+	Line        int           `json:"line"`
+	TableNumber string        `json:"table_number"`
+	Status      string        `json:"status" enums:"created,rejected"`
+	Table       *domain.Table `json:"table,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+const (
+	TableImportCreated  = "created"
+	TableImportRejected = "rejected"
+)
+
+func newTableImportResponse(message string, rows []service.TableImportRow) TableImportResponse {
+	resp := TableImportResponse{Error: message, Rows: make([]TableImportRow, len(rows))}
+	for i, row := range rows {
+		resp.Rows[i] = TableImportRow{Line: row.Line, TableNumber: row.TableNumber, Status: TableImportCreated, Table: row.Table}
+		if row.Err != nil {
+			resp.Rows[i].Status = TableImportRejected
+			resp.Rows[i].Error = row.Err.Error()
+			resp.Rejected++
+		} else {
+			resp.Created++
+		}
+	}
+	return resp
+}
+
+// TableHasBookingsResponse lists the upcoming bookings that keep a table in
+// service. Retry with force=true to cancel them.
+type TableHasBookingsResponse struct {
+	Error      string      `json:"error"`
+	BookingIDs []uuid.UUID `json:"booking_ids"`
 }
 
 type UpdateTableRequest struct {
+	TableNumber  *string              `json:"table_number" binding:"omitempty,min=1"`
+	MinCapacity  *int                 `json:"min_capacity" binding:"omitempty,min=1"`
+	MaxCapacity  *int                 `json:"max_capacity" binding:"omitempty,min=1"`
 	IsActive     *bool                `json:"is_active"`
 	LocationType *domain.LocationType `json:"location_type"`
 	XPosition    *int                 `json:"x_position"`
 	YPosition    *int                 `json:"y_position"`
+	// MinDurationMinutes and SlotGranularityMinutes of 0 drop the table's
+	// override.
+	MinDurationMinutes     *int `json:"min_duration_minutes" binding:"omitempty,min=0"`
+	SlotGranularityMinutes *int `json:"slot_granularity_minutes" binding:"omitempty,min=0"`
+	// DepositAmount and MinSpend of 0 remove them.
+	DepositAmount *int `json:"deposit_amount" binding:"omitempty,min=0"`
+	MinSpend      *int `json:"min_spend" binding:"omitempty,min=0"`
+}
+
+type CheckTablesAvailabilityRequest struct {
+	Checks []TableAvailabilityCheck `json:"checks" binding:"required,min=1,max=50,dive"`
+}
+
+type TableAvailabilityCheck struct {
+	TableID   uuid.UUID    `json:"table_id" binding:"required"`
+	StartTime apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime   apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T21:00:00Z"`
+}
+
+type CheckTablesAvailabilityResponse struct {
+	Results []TableAvailabilityResult `json:"results"`
+}
+
+// TableAvailabilityResult answers one check, in request order. Reason is set
+// only when the table is not available.
+type TableAvailabilityResult struct {
+	TableID   uuid.UUID    `json:"table_id"`
+	StartTime apitime.Time `json:"start_time" swaggertype:"string" format:"date-time"`
+	EndTime   apitime.Time `json:"end_time" swaggertype:"string" format:"date-time"`
+	Available bool         `json:"available"`
+	Reason    string       `json:"reason,omitempty" enums:"table_not_found,table_inactive,outside_working_hours,blocked_for_maintenance,occupied,timeout,check_failed"`
+}
+
+type BlockTableRequest struct {
+	StartsAt apitime.Time `json:"starts_at" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T09:00:00Z"`
+	EndsAt   apitime.Time `json:"ends_at" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T12:00:00Z"`
+	Reason   string       `json:"reason" binding:"max=255"`
+}
+
+type FloorPlanResponse struct {
+	At     apitime.Time     `json:"at" swaggertype:"string" format:"date-time"`
+	Tables []FloorPlanTable `json:"tables"`
+}
+
+// FloorPlanTable places one table. XPosition and YPosition are null for
+// tables not yet placed on the plan.
+type FloorPlanTable struct {
+	ID           uuid.UUID           `json:"id"`
+	TableNumber  string              `json:"table_number" example:"T1"`
+	MinCapacity  int                 `json:"min_capacity" example:"2"`
+	MaxCapacity  int                 `json:"max_capacity" example:"4"`
+	LocationType domain.LocationType `json:"location_type" example:"window"`
+	XPosition    *int                `json:"x_position"`
+	YPosition    *int                `json:"y_position"`
+	Booked       bool                `json:"booked"`
+}
+
+// Statuses of a TableScheduleSlot.
+const (
+	ScheduleSlotFree   = "free"
+	ScheduleSlotBooked = "booked"
+)
+
+// TableScheduleResponse is a table's day. The slot times are in UTC;
+// Timezone is the restaurant's, which Date is a day of.
+type TableScheduleResponse struct {
+	TableID     uuid.UUID           `json:"table_id"`
+	Date        string              `json:"date" example:"2024-06-01"`
+	Timezone    string              `json:"timezone" example:"Asia/Almaty"`
+	SlotMinutes int                 `json:"slot_minutes" example:"30"`
+	Slots       []TableScheduleSlot `json:"slots"`
+}
+
+// TableScheduleSlot has BookingID set when it is booked. The last slot of
+// the day ends at closing time, so it may be shorter.
+type TableScheduleSlot struct {
+	StartTime apitime.Time `json:"start_time" swaggertype:"string" format:"date-time"`
+	EndTime   apitime.Time `json:"end_time" swaggertype:"string" format:"date-time"`
+	Status    string       `json:"status" enums:"free,booked"`
+	BookingID *uuid.UUID   `json:"booking_id,omitempty"`
 }
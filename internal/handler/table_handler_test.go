@@ -0,0 +1,712 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTableService struct {
+	service.TableService
+	ownerID uuid.UUID
+	err     error
+	table   *domain.Table
+	bulk    *service.BulkCreateTablesRequest
+	plan    []repository.FloorPlanTable
+	planAt  time.Time
+
+	minCapacity int
+	start, end  time.Time
+	positions   []service.TablePosition
+	force       bool
+	schedule    *service.TableSchedule
+	slotMinutes int
+	strict      bool
+	imported    string
+}
+
+func (s *stubTableService) UpdateTablePositions(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, positions []service.TablePosition) ([]*domain.Table, error) {
+	s.ownerID = ownerID
+	s.positions = positions
+	if s.err != nil {
+		return nil, s.err
+	}
+	tables := make([]*domain.Table, len(positions))
+	for i, position := range positions {
+		tables[i] = &domain.Table{ID: position.TableID, RestaurantID: restaurantID, XPosition: position.XPosition, YPosition: position.YPosition}
+	}
+	return tables, nil
+}
+
+func (s *stubTableService) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error) {
+	s.minCapacity, s.start, s.end = minCapacity, start, end
+	return []*domain.Table{}, s.err
+}
+
+func (s *stubTableService) GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]repository.FloorPlanTable, error) {
+	s.planAt = at
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.plan, nil
+}
+
+// GetTable returns the stub's table, or ErrTableNotFound when it has none.
+func (s *stubTableService) GetTable(ctx context.Context, id uuid.UUID) (*domain.Table, error) {
+	if s.table == nil {
+		return nil, service.ErrTableNotFound
+	}
+	return s.table, nil
+}
+
+func (s *stubTableService) UpdateTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, req service.UpdateTableRequest) (*domain.Table, error) {
+	s.ownerID = ownerID
+	s.force = req.Force
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.table, nil
+}
+
+func (s *stubTableService) DeleteTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, force bool) error {
+	s.ownerID = ownerID
+	s.force = force
+	return s.err
+}
+
+func (s *stubTableService) GetTableSchedule(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, date time.Time, slotMinutes int) (*service.TableSchedule, error) {
+	s.ownerID = staffID
+	s.slotMinutes = slotMinutes
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.schedule, nil
+}
+
+func (s *stubTableService) BulkCreateTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req service.BulkCreateTablesRequest) ([]*domain.Table, error) {
+	s.ownerID = ownerID
+	s.bulk = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	tables := make([]*domain.Table, len(req.Tables))
+	for i, table := range req.Tables {
+		tables[i] = &domain.Table{RestaurantID: restaurantID, TableNumber: table.TableNumber}
+	}
+	return tables, nil
+}
+
+func (s *stubTableService) CreateTable(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req service.CreateTableRequest) (*domain.Table, error) {
+	s.ownerID = ownerID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Table{RestaurantID: restaurantID, TableNumber: req.TableNumber}, nil
+}
+
+func (s *stubTableService) BlockTable(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, req service.BlockTableRequest) (*domain.TableBlock, error) {
+	s.ownerID = staffID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.TableBlock{TableID: tableID, StartsAt: req.StartsAt, EndsAt: req.EndsAt, CreatedBy: staffID}, nil
+}
+
+// stubTableAvailability answers every check with the reason set for its
+// table, or as available.
+type stubTableAvailability struct {
+	service.AvailabilityService
+	reasons map[uuid.UUID]string
+	checks  []service.TableCheck
+}
+
+func (s *stubTableAvailability) CheckTables(ctx context.Context, checks []service.TableCheck) []service.TableCheckResult {
+	s.checks = checks
+	results := make([]service.TableCheckResult, len(checks))
+	for i, check := range checks {
+		reason := s.reasons[check.TableID]
+		results[i] = service.TableCheckResult{TableCheck: check, Available: reason == "", Reason: reason}
+	}
+	return results
+}
+
+func availabilityChecksBody(tableIDs []uuid.UUID, start, end string) string {
+	checks := make([]string, len(tableIDs))
+	for i, id := range tableIDs {
+		checks[i] = fmt.Sprintf(`{"table_id": %q, "start_time": %q, "end_time": %q}`, id, start, end)
+	}
+	return `{"checks": [` + strings.Join(checks, ",") + `]}`
+}
+
+const createTableBody = `{
+	"restaurant_id": "7f1b5a6e-1d2c-4b3a-9e8f-0a1b2c3d4e5f",
+	"table_number": "T1",
+	"min_capacity": 2,
+	"max_capacity": 4,
+	"location_type": "window"
+}`
+
+func TestTableOwnerEndpoints_NoToken(t *testing.T) {
+	h := NewTableHandler(&stubTableService{}, nil)
+	id := uuid.NewString()
+
+	assert.Equal(t, http.StatusUnauthorized,
+		performAsUser(h.CreateTable, http.MethodPost, "/api/tables", "/api/tables", nil, createTableBody).Code)
+	assert.Equal(t, http.StatusUnauthorized,
+		performAsUser(h.UpdateTable, http.MethodPut, "/api/tables/:id", "/api/tables/"+id, nil, "{}").Code)
+	assert.Equal(t, http.StatusUnauthorized,
+		performAsUser(h.BulkCreateTables, http.MethodPost, "/api/restaurants/:id/tables/bulk", "/api/restaurants/"+id+"/tables/bulk", nil, "{}").Code)
+	assert.Equal(t, http.StatusUnauthorized,
+		performAsUser(h.DeleteTable, http.MethodDelete, "/api/tables/:id", "/api/tables/"+id, nil, "").Code)
+}
+
+func TestCreateTable_UsesAuthenticatedOwner(t *testing.T) {
+	svc := &stubTableService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewTableHandler(svc, nil).CreateTable, http.MethodPost, "/api/tables", "/api/tables", &userID, createTableBody)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+}
+
+func TestCreateTable_NotOwner(t *testing.T) {
+	svc := &stubTableService{err: service.ErrUnauthorized}
+	userID := uuid.New()
+
+	w := performAsUser(NewTableHandler(svc, nil).CreateTable, http.MethodPost, "/api/tables", "/api/tables", &userID, createTableBody)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCheckTablesAvailability_ReasonPerCheck(t *testing.T) {
+	free, booked, blocked := uuid.New(), uuid.New(), uuid.New()
+	availability := &stubTableAvailability{reasons: map[uuid.UUID]string{
+		booked:  service.ReasonOccupied,
+		blocked: service.ReasonBlockedForMaintenance,
+	}}
+	h := NewTableHandler(nil, availability)
+
+	body := availabilityChecksBody([]uuid.UUID{free, booked, blocked}, "2024-06-01T19:00:00+05:00", "2024-06-01T21:00:00+05:00")
+	w := performAsUser(h.CheckAvailability, http.MethodPost, "/api/tables/check-availability", "/api/tables/check-availability", nil, body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp CheckTablesAvailabilityResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Results, 3) {
+		assert.True(t, resp.Results[0].Available)
+		assert.Empty(t, resp.Results[0].Reason)
+		assert.Equal(t, booked, resp.Results[1].TableID)
+		assert.Equal(t, service.ReasonOccupied, resp.Results[1].Reason)
+		assert.Equal(t, service.ReasonBlockedForMaintenance, resp.Results[2].Reason)
+		assert.Equal(t, "2024-06-01T14:00:00Z", resp.Results[2].StartTime.Format("2006-01-02T15:04:05Z07:00"))
+	}
+}
+
+func TestCheckTablesAvailability_Validation(t *testing.T) {
+	tooMany := make([]uuid.UUID, 51)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"no checks", `{"checks": []}`},
+		{"more than 50 checks", availabilityChecksBody(tooMany, "2024-06-01T19:00:00Z", "2024-06-01T21:00:00Z")},
+		{"end before start", availabilityChecksBody([]uuid.UUID{uuid.New()}, "2024-06-01T21:00:00Z", "2024-06-01T19:00:00Z")},
+		{"naive timestamp", availabilityChecksBody([]uuid.UUID{uuid.New()}, "2024-06-01 19:00", "2024-06-01T21:00:00Z")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			availability := &stubTableAvailability{}
+			h := NewTableHandler(nil, availability)
+
+			w := performAsUser(h.CheckAvailability, http.MethodPost, "/api/tables/check-availability", "/api/tables/check-availability", nil, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, availability.checks)
+		})
+	}
+}
+
+func TestBlockTable_Responses(t *testing.T) {
+	userID := uuid.New()
+	id := uuid.NewString()
+	body := `{"starts_at": "2024-06-01T09:00:00Z", "ends_at": "2024-06-01T12:00:00Z", "reason": "repainting"}`
+
+	svc := &stubTableService{}
+	w := performAsUser(NewTableHandler(svc, nil).BlockTable, http.MethodPost, "/api/tables/:id/blocks", "/api/tables/"+id+"/blocks", &userID, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+
+	svc = &stubTableService{err: service.ErrNotRestaurantStaff}
+	w = performAsUser(NewTableHandler(svc, nil).BlockTable, http.MethodPost, "/api/tables/:id/blocks", "/api/tables/"+id+"/blocks", &userID, body)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	svc = &stubTableService{err: service.ErrInvalidBlockPeriod}
+	w = performAsUser(NewTableHandler(svc, nil).BlockTable, http.MethodPost, "/api/tables/:id/blocks", "/api/tables/"+id+"/blocks", &userID, body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTable_NotFound(t *testing.T) {
+	id := uuid.NewString()
+
+	w := performAsUser(NewTableHandler(&stubTableService{}, nil).GetTable, http.MethodGet, "/api/tables/:id", "/api/tables/"+id, nil, "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateTable_ErrorStatuses(t *testing.T) {
+	userID := uuid.New()
+	id := uuid.NewString()
+
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"duplicate number": {service.ErrDuplicateTableNumber, http.StatusConflict},
+		"bad capacity":     {service.ErrInvalidCapacity, http.StatusBadRequest},
+		"not the owner":    {service.ErrUnauthorized, http.StatusUnauthorized},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{table: &domain.Table{RestaurantID: uuid.New()}, err: tc.err}
+
+			w := performAsUser(NewTableHandler(svc, nil).UpdateTable, http.MethodPut, "/api/tables/:id", "/api/tables/"+id, &userID, `{"min_capacity": 6}`)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestUpdateTable_NotFound(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewTableHandler(&stubTableService{}, nil).UpdateTable, http.MethodPut, "/api/tables/:id", "/api/tables/"+uuid.NewString(), &userID, "{}")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteTable_HasUpcomingBookings(t *testing.T) {
+	userID := uuid.New()
+	bookingIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	svc := &stubTableService{table: &domain.Table{RestaurantID: uuid.New()}, err: &service.TableHasBookingsError{BookingIDs: bookingIDs}}
+
+	w := performAsUser(NewTableHandler(svc, nil).DeleteTable, http.MethodDelete, "/api/tables/:id", "/api/tables/"+uuid.NewString(), &userID, "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.False(t, svc.force)
+	var resp TableHasBookingsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, bookingIDs, resp.BookingIDs)
+}
+
+func TestDeactivateTable_Force(t *testing.T) {
+	userID := uuid.New()
+	id := uuid.NewString()
+
+	svc := &stubTableService{table: &domain.Table{RestaurantID: uuid.New()}}
+	w := performAsUser(NewTableHandler(svc, nil).DeleteTable, http.MethodDelete, "/api/tables/:id", "/api/tables/"+id+"?force=true", &userID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, svc.force)
+
+	svc = &stubTableService{table: &domain.Table{RestaurantID: uuid.New()}}
+	w = performAsUser(NewTableHandler(svc, nil).UpdateTable, http.MethodPut, "/api/tables/:id", "/api/tables/"+id+"?force=true", &userID, `{"is_active": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.force)
+
+	w = performAsUser(NewTableHandler(svc, nil).DeleteTable, http.MethodDelete, "/api/tables/:id", "/api/tables/"+id+"?force=maybe", &userID, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBulkCreateTables(t *testing.T) {
+	userID, restaurantID := uuid.New(), uuid.New()
+	svc := &stubTableService{}
+	body := `{"tables": [
+		{"table_number": "T1", "min_capacity": 2, "max_capacity": 4, "location_type": "window"},
+		{"table_number": "T2", "min_capacity": 4, "max_capacity": 6, "location_type": "regular"}
+	]}`
+
+	w := performAsUser(NewTableHandler(svc, nil).BulkCreateTables, http.MethodPost, "/api/restaurants/:id/tables/bulk",
+		"/api/restaurants/"+restaurantID.String()+"/tables/bulk", &userID, body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+	if assert.NotNil(t, svc.bulk) && assert.Len(t, svc.bulk.Tables, 2) {
+		assert.Equal(t, "T2", svc.bulk.Tables[1].TableNumber)
+		assert.Equal(t, 6, svc.bulk.Tables[1].MaxCapacity)
+	}
+	var tables []domain.Table
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tables))
+	assert.Len(t, tables, 2)
+}
+
+func TestBulkCreateTables_Rejections(t *testing.T) {
+	userID := uuid.New()
+	target := "/api/restaurants/" + uuid.NewString() + "/tables/bulk"
+	valid := `{"tables": [{"table_number": "T1", "min_capacity": 2, "max_capacity": 4, "location_type": "window"}]}`
+
+	cases := map[string]struct {
+		body string
+		err  error
+		want int
+	}{
+		"no tables":          {`{"tables": []}`, nil, http.StatusBadRequest},
+		"missing number":     {`{"tables": [{"min_capacity": 2, "max_capacity": 4, "location_type": "window"}]}`, nil, http.StatusBadRequest},
+		"negative deposit":   {`{"tables": [{"table_number": "T1", "min_capacity": 2, "max_capacity": 4, "location_type": "vip", "deposit_amount": -1}]}`, nil, http.StatusBadRequest},
+		"duplicate number":   {valid, service.ErrDuplicateTableNumber, http.StatusConflict},
+		"bad capacity":       {valid, service.ErrInvalidCapacity, http.StatusBadRequest},
+		"bad deposit":        {valid, service.ErrInvalidTableDeposit, http.StatusBadRequest},
+		"not the owner":      {valid, service.ErrUnauthorized, http.StatusUnauthorized},
+		"unknown restaurant": {valid, service.ErrRestaurantNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{err: tc.err}
+
+			w := performAsUser(NewTableHandler(svc, nil).BulkCreateTables, http.MethodPost, "/api/restaurants/:id/tables/bulk", target, &userID, tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestGetAvailableTables_InvalidMinCapacity(t *testing.T) {
+	target := "/api/tables/available?restaurant_id=" + uuid.NewString() + "&min_capacity=two&start_time=2024-06-01T19:00:00Z&end_time=2024-06-01T21:00:00Z"
+
+	w := performAsUser(NewTableHandler(&stubTableService{}, nil).GetAvailableTables, http.MethodGet, "/api/tables/available", target, nil, "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAvailableTables_PassesTimeRange(t *testing.T) {
+	svc := &stubTableService{}
+	target := "/api/tables/available?restaurant_id=" + uuid.NewString() + "&min_capacity=4&start_time=2024-06-01T19:00:00%2B05:00&end_time=2024-06-01T21:00:00%2B05:00"
+
+	w := performAsUser(NewTableHandler(svc, nil).GetAvailableTables, http.MethodGet, "/api/tables/available", target, nil, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, svc.minCapacity)
+	assert.Equal(t, time.Date(2024, time.June, 1, 14, 0, 0, 0, time.UTC), svc.start.UTC())
+	assert.Equal(t, time.Date(2024, time.June, 1, 16, 0, 0, 0, time.UTC), svc.end.UTC())
+}
+
+func TestGetAvailableTables_InvalidTimeRange(t *testing.T) {
+	base := "/api/tables/available?restaurant_id=" + uuid.NewString()
+
+	cases := map[string]string{
+		"missing range":  base,
+		"missing end":    base + "&start_time=2024-06-01T19:00:00Z",
+		"naive start":    base + "&start_time=2024-06-01T19:00:00&end_time=2024-06-01T21:00:00Z",
+		"inverted range": base + "&start_time=2024-06-01T21:00:00Z&end_time=2024-06-01T19:00:00Z",
+		"empty range":    base + "&start_time=2024-06-01T19:00:00Z&end_time=2024-06-01T19:00:00Z",
+	}
+	for name, target := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{}
+
+			w := performAsUser(NewTableHandler(svc, nil).GetAvailableTables, http.MethodGet, "/api/tables/available", target, nil, "")
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.True(t, svc.start.IsZero())
+		})
+	}
+}
+
+func TestGetFloorPlan(t *testing.T) {
+	x, y := 3, 5
+	svc := &stubTableService{plan: []repository.FloorPlanTable{
+		{ID: uuid.New(), TableNumber: "T1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationWindow, XPosition: &x, YPosition: &y, Booked: true},
+		{ID: uuid.New(), TableNumber: "T2", MinCapacity: 4, MaxCapacity: 6, LocationType: domain.LocationRegular},
+	}}
+	target := "/api/restaurants/" + uuid.NewString() + "/floor-plan?at=2024-06-01T19:00:00%2B05:00"
+
+	w := performAsUser(NewTableHandler(svc, nil).GetFloorPlan, http.MethodGet, "/api/restaurants/:id/floor-plan", target, nil, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, time.June, 1, 14, 0, 0, 0, time.UTC), svc.planAt.UTC())
+
+	var resp struct {
+		At     string                       `json:"at"`
+		Tables []map[string]json.RawMessage `json:"tables"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2024-06-01T14:00:00Z", resp.At)
+	if assert.Len(t, resp.Tables, 2) {
+		assert.JSONEq(t, "true", string(resp.Tables[0]["booked"]))
+		assert.JSONEq(t, "3", string(resp.Tables[0]["x_position"]))
+		assert.JSONEq(t, "null", string(resp.Tables[1]["x_position"]))
+		assert.JSONEq(t, "null", string(resp.Tables[1]["y_position"]))
+		assert.JSONEq(t, "false", string(resp.Tables[1]["booked"]))
+	}
+}
+
+func TestGetFloorPlan_Errors(t *testing.T) {
+	cases := map[string]struct {
+		target string
+		err    error
+		want   int
+	}{
+		"naive time":         {"/api/restaurants/" + uuid.NewString() + "/floor-plan?at=2024-06-01T19:00:00", nil, http.StatusBadRequest},
+		"invalid id":         {"/api/restaurants/nope/floor-plan", nil, http.StatusBadRequest},
+		"unknown restaurant": {"/api/restaurants/" + uuid.NewString() + "/floor-plan", service.ErrRestaurantNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := performAsUser(NewTableHandler(&stubTableService{err: tc.err}, nil).GetFloorPlan, http.MethodGet, "/api/restaurants/:id/floor-plan", tc.target, nil, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestUpdateTablePositions(t *testing.T) {
+	userID := uuid.New()
+	moved, unplaced := uuid.New(), uuid.New()
+	svc := &stubTableService{}
+	body := `{"positions": [{"table_id": "` + moved.String() + `", "x_position": 120, "y_position": 80}, {"table_id": "` + unplaced.String() + `", "x_position": null, "y_position": null}]}`
+
+	w := performAsUser(NewTableHandler(svc, nil).UpdateTablePositions, http.MethodPut, "/api/restaurants/:id/tables/positions",
+		"/api/restaurants/"+uuid.NewString()+"/tables/positions", &userID, body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+	if assert.Len(t, svc.positions, 2) {
+		assert.Equal(t, moved, svc.positions[0].TableID)
+		assert.Equal(t, 120, *svc.positions[0].XPosition)
+		assert.Nil(t, svc.positions[1].XPosition)
+	}
+}
+
+func TestUpdateTablePositions_RejectedEntries(t *testing.T) {
+	userID, foreignID := uuid.New(), uuid.New()
+	svc := &stubTableService{err: &service.TablePositionsError{Issues: []service.TablePositionIssue{
+		{Index: 1, TableID: foreignID, Err: errors.New("not an active table of this restaurant")},
+	}}}
+	body := `{"positions": [{"table_id": "` + uuid.NewString() + `"}, {"table_id": "` + foreignID.String() + `"}]}`
+
+	w := performAsUser(NewTableHandler(svc, nil).UpdateTablePositions, http.MethodPut, "/api/restaurants/:id/tables/positions",
+		"/api/restaurants/"+uuid.NewString()+"/tables/positions", &userID, body)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp TablePositionsErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Items, 1) {
+		assert.Equal(t, 1, resp.Items[0].Index)
+		assert.Equal(t, foreignID, resp.Items[0].TableID)
+		assert.Equal(t, "not an active table of this restaurant", resp.Items[0].Error)
+	}
+}
+
+func TestUpdateTablePositions_BadRequests(t *testing.T) {
+	userID := uuid.New()
+	target := "/api/restaurants/" + uuid.NewString() + "/tables/positions"
+
+	for name, body := range map[string]string{
+		"no positions":  `{"positions": []}`,
+		"missing table": `{"positions": [{"x_position": 1}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{}
+
+			w := performAsUser(NewTableHandler(svc, nil).UpdateTablePositions, http.MethodPut, "/api/restaurants/:id/tables/positions", target, &userID, body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, svc.positions)
+		})
+	}
+
+	svc := &stubTableService{err: service.ErrUnauthorized}
+	w := performAsUser(NewTableHandler(svc, nil).UpdateTablePositions, http.MethodPut, "/api/restaurants/:id/tables/positions", target, &userID,
+		`{"positions": [{"table_id": "`+uuid.NewString()+`"}]}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetTableSchedule(t *testing.T) {
+	userID, bookingID := uuid.New(), uuid.New()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	opens := time.Date(2024, 6, 3, 10, 0, 0, 0, tokyo)
+	svc := &stubTableService{schedule: &service.TableSchedule{
+		Table: &domain.Table{ID: uuid.New()},
+		Day:   time.Date(2024, 6, 3, 0, 0, 0, 0, tokyo),
+		Slots: []service.ScheduleSlot{
+			{Start: opens, End: opens.Add(time.Hour), BookingID: &bookingID},
+			{Start: opens.Add(time.Hour), End: opens.Add(2 * time.Hour)},
+		},
+	}}
+
+	w := performAsUser(NewTableHandler(svc, nil).GetTableSchedule, http.MethodGet, "/api/tables/:id/availability",
+		"/api/tables/"+uuid.NewString()+"/availability?date=2024-06-03&slot_minutes=60", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 60, svc.slotMinutes)
+	var resp TableScheduleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2024-06-03", resp.Date)
+	assert.Equal(t, "Asia/Tokyo", resp.Timezone)
+	require.Len(t, resp.Slots, 2)
+	assert.Equal(t, ScheduleSlotBooked, resp.Slots[0].Status)
+	assert.Equal(t, &bookingID, resp.Slots[0].BookingID)
+	assert.True(t, resp.Slots[0].StartTime.Equal(opens))
+	assert.Equal(t, ScheduleSlotFree, resp.Slots[1].Status)
+	assert.Nil(t, resp.Slots[1].BookingID)
+}
+
+func TestGetTableSchedule_BadRequests(t *testing.T) {
+	userID := uuid.New()
+	target := "/api/tables/" + uuid.NewString() + "/availability"
+
+	cases := map[string]struct {
+		query string
+		err   error
+		want  int
+	}{
+		"no date":              {"", nil, http.StatusBadRequest},
+		"bad date":             {"?date=03.06.2024", nil, http.StatusBadRequest},
+		"slot not a number":    {"?date=2024-06-03&slot_minutes=half", nil, http.StatusBadRequest},
+		"slot out of range":    {"?date=2024-06-03&slot_minutes=1", service.ErrInvalidScheduleSlot, http.StatusBadRequest},
+		"not restaurant staff": {"?date=2024-06-03", service.ErrNotRestaurantStaff, http.StatusForbidden},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{err: tc.err}
+
+			w := performAsUser(NewTableHandler(svc, nil).GetTableSchedule, http.MethodGet, "/api/tables/:id/availability", target+tc.query, &userID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func (s *stubTableService) ImportTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, csv io.Reader, strict bool) ([]service.TableImportRow, error) {
+	s.ownerID = ownerID
+	s.strict = strict
+	if s.err != nil {
+		return nil, s.err
+	}
+	data, _ := io.ReadAll(csv)
+	s.imported = string(data)
+	return []service.TableImportRow{
+		{Line: 2, TableNumber: "T1", Table: &domain.Table{ID: uuid.New(), TableNumber: "T1"}},
+		{Line: 3, TableNumber: "T2", Err: service.ErrInvalidCapacity},
+	}, nil
+}
+
+// postTableImport uploads csv as the "file" part of a table import.
+func postTableImport(t *testing.T, svc *stubTableService, userID uuid.UUID, target, csv string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "tables.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(csv))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/restaurants/:id/tables/import", func(c *gin.Context) {
+		c.Set("user_id", userID)
+	}, NewTableHandler(svc, nil).ImportTables)
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestImportTables(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubTableService{}
+	csv := "table_number,min_capacity,max_capacity,location_type\nT1,2,4,window\nT2,4,2,vip\n"
+
+	w := postTableImport(t, svc, userID, "/api/restaurants/"+uuid.NewString()+"/tables/import", csv)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userID, svc.ownerID)
+	assert.False(t, svc.strict)
+	assert.Equal(t, csv, svc.imported)
+
+	var resp TableImportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Created)
+	assert.Equal(t, 1, resp.Rejected)
+	require.Len(t, resp.Rows, 2)
+	assert.Equal(t, TableImportCreated, resp.Rows[0].Status)
+	assert.NotNil(t, resp.Rows[0].Table)
+	assert.Equal(t, 3, resp.Rows[1].Line)
+	assert.Equal(t, TableImportRejected, resp.Rows[1].Status)
+	assert.Equal(t, service.ErrInvalidCapacity.Error(), resp.Rows[1].Error)
+}
+
+func TestImportTables_StrictRejected(t *testing.T) {
+	svc := &stubTableService{err: &service.TableImportError{Rows: []service.TableImportRow{
+		{Line: 4, TableNumber: "T3", Err: service.ErrInvalidLocationType},
+	}}}
+
+	w := postTableImport(t, svc, uuid.New(), "/api/restaurants/"+uuid.NewString()+"/tables/import?strict=true", "csv")
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.True(t, svc.strict)
+	var resp TableImportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Error)
+	assert.Equal(t, 0, resp.Created)
+	require.Len(t, resp.Rows, 1)
+	assert.Equal(t, 4, resp.Rows[0].Line)
+	assert.Equal(t, TableImportRejected, resp.Rows[0].Status)
+}
+
+func TestImportTables_Rejections(t *testing.T) {
+	target := "/api/restaurants/" + uuid.NewString() + "/tables/import"
+
+	cases := map[string]struct {
+		target string
+		err    error
+		want   int
+	}{
+		"bad strict":         {target + "?strict=maybe", nil, http.StatusBadRequest},
+		"malformed file":     {target, fmt.Errorf("%w: the file has no tables", service.ErrInvalidTableImport), http.StatusBadRequest},
+		"not the owner":      {target, service.ErrUnauthorized, http.StatusUnauthorized},
+		"unknown restaurant": {target, service.ErrRestaurantNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubTableService{err: tc.err}
+
+			w := postTableImport(t, svc, uuid.New(), tc.target, "csv")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestImportTables_NoFile(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewTableHandler(&stubTableService{}, nil).ImportTables, http.MethodPost, "/api/restaurants/:id/tables/import",
+		"/api/restaurants/"+uuid.NewString()+"/tables/import", &userID, `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// qrCodeCacheControl lets browsers keep a code for a day. Codes only change
+// when the URL template does, and they are never shared between staff.
+const qrCodeCacheControl = "private, max-age=86400"
+
+type TableQRHandler struct {
+	qrService service.TableQRService
+}
+
+func NewTableQRHandler(qrService service.TableQRService) *TableQRHandler {
+	return &TableQRHandler{qrService: qrService}
+}
+
+// @Summary Get a table's QR code
+// @Description Renders a PNG QR code opening the table's public page. Staff of the restaurant only.
+// @Tags Tables
+// @Produce image/png
+// @Param id path string true "Table ID"
+// @Param size query int false "Side in pixels, 128 to 2048" default(512)
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/tables/{id}/qr [get]
+func (h *TableQRHandler) GetTableQRCode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid table id"})
+		return
+	}
+
+	size, ok := qrCodeSize(c)
+	if !ok {
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	code, err := h.qrService.TableCode(c.Request.Context(), id, staffID, size)
+	if err != nil {
+		writeTableQRError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", qrCodeCacheControl)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", qrCodeFilename(code.Table)))
+	c.Data(http.StatusOK, "image/png", code.PNG)
+}
+
+// @Summary Download the QR codes of all tables
+// @Description Returns a zip with one PNG QR code per active table of the restaurant, named after the table number. Staff of the restaurant only.
+// @Tags Tables
+// @Produce application/zip
+// @Param id path string true "Restaurant ID"
+// @Param size query int false "Side in pixels, 128 to 2048" default(512)
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/tables/qr.zip [get]
+func (h *TableQRHandler) GetRestaurantQRCodes(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	size, ok := qrCodeSize(c)
+	if !ok {
+		return
+	}
+
+	staffID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.qrService.RestaurantCodes(c.Request.Context(), restaurantID, staffID, size)
+	if err != nil {
+		writeTableQRError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", qrCodeCacheControl)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "tables-qr-"+restaurantID.String()+".zip"))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	// The codes are PNGs already, so storing them skips a pointless deflate.
+	w := zip.NewWriter(c.Writer)
+	for _, code := range codes {
+		entry, err := w.CreateHeader(&zip.FileHeader{Name: qrCodeFilename(code.Table), Method: zip.Store})
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if _, err := entry.Write(code.PNG); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// qrCodeSize reads the size query parameter, DefaultQRCodeSize when absent.
+// When it is not an integer it writes a 400 response and returns false; the
+// service checks the range.
+func qrCodeSize(c *gin.Context) (int, bool) {
+	raw := c.Query("size")
+	if raw == "" {
+		return service.DefaultQRCodeSize, true
+	}
+	size, err := strconv.Atoi(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: service.ErrInvalidQRCodeSize.Error()})
+		return 0, false
+	}
+	return size, true
+}
+
+// qrCodeFilename names a table's code after its number, keeping only
+// characters that are safe in file names.
+func qrCodeFilename(table *domain.Table) string {
+	number := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, table.TableNumber)
+	if number == "" {
+		number = table.ID.String()
+	}
+	return "table-" + number + ".png"
+}
+
+func writeTableQRError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidQRCodeSize):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		writeTableError(c, err)
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTableQRService struct {
+	service.TableQRService
+	tables []*domain.Table
+	size   int
+	err    error
+}
+
+func (s *stubTableQRService) TableCode(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, size int) (*service.TableQRCode, error) {
+	s.size = size
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &service.TableQRCode{Table: &domain.Table{ID: tableID, TableNumber: "T 1"}, PNG: []byte(pngBytes)}, nil
+}
+
+func (s *stubTableQRService) RestaurantCodes(ctx context.Context, restaurantID uuid.UUID, staffID uuid.UUID, size int) ([]service.TableQRCode, error) {
+	s.size = size
+	if s.err != nil {
+		return nil, s.err
+	}
+	codes := make([]service.TableQRCode, len(s.tables))
+	for i, table := range s.tables {
+		codes[i] = service.TableQRCode{Table: table, PNG: []byte(pngBytes)}
+	}
+	return codes, nil
+}
+
+func TestGetTableQRCode(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubTableQRService{}
+
+	w := performAsUser(NewTableQRHandler(svc).GetTableQRCode, http.MethodGet, "/api/tables/:id/qr", "/api/tables/"+uuid.NewString()+"/qr?size=1024", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1024, svc.size)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "private, max-age=86400", w.Header().Get("Cache-Control"))
+	assert.Equal(t, `inline; filename="table-T_1.png"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, pngBytes, w.Body.String())
+}
+
+func TestGetTableQRCode_DefaultSize(t *testing.T) {
+	userID := uuid.New()
+	svc := &stubTableQRService{}
+
+	performAsUser(NewTableQRHandler(svc).GetTableQRCode, http.MethodGet, "/api/tables/:id/qr", "/api/tables/"+uuid.NewString()+"/qr", &userID, "")
+
+	assert.Equal(t, service.DefaultQRCodeSize, svc.size)
+}
+
+func TestGetTableQRCode_Errors(t *testing.T) {
+	userID := uuid.New()
+	id := uuid.NewString()
+
+	cases := map[string]struct {
+		query string
+		err   error
+		want  int
+	}{
+		"size not a number": {"?size=big", nil, http.StatusBadRequest},
+		"size out of range": {"?size=64", service.ErrInvalidQRCodeSize, http.StatusBadRequest},
+		"not staff":         {"", service.ErrNotRestaurantStaff, http.StatusForbidden},
+		"unknown table":     {"", service.ErrTableNotFound, http.StatusNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := performAsUser(NewTableQRHandler(&stubTableQRService{err: tc.err}).GetTableQRCode, http.MethodGet, "/api/tables/:id/qr", "/api/tables/"+id+"/qr"+tc.query, &userID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+
+	w := performAsUser(NewTableQRHandler(&stubTableQRService{}).GetTableQRCode, http.MethodGet, "/api/tables/:id/qr", "/api/tables/"+id+"/qr", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetRestaurantQRCodes_Zip(t *testing.T) {
+	userID, restaurantID := uuid.New(), uuid.New()
+	svc := &stubTableQRService{tables: []*domain.Table{
+		{ID: uuid.New(), TableNumber: "T1"},
+		{ID: uuid.New(), TableNumber: "VIP/2"},
+	}}
+
+	w := performAsUser(NewTableQRHandler(svc).GetRestaurantQRCodes, http.MethodGet, "/api/restaurants/:id/tables/qr.zip",
+		"/api/restaurants/"+restaurantID.String()+"/tables/qr.zip", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="tables-qr-`+restaurantID.String()+`.zip"`, w.Header().Get("Content-Disposition"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	assert.Equal(t, "table-T1.png", archive.File[0].Name)
+	assert.Equal(t, "table-VIP_2.png", archive.File[1].Name)
+}
+
+func TestGetRestaurantQRCodes_NotFound(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewTableQRHandler(&stubTableQRService{err: service.ErrRestaurantNotFound}).GetRestaurantQRCodes, http.MethodGet,
+		"/api/restaurants/:id/tables/qr.zip", "/api/restaurants/"+uuid.NewString()+"/tables/qr.zip", &userID, "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,5 +1,34 @@
 package handler
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"restaurant-booking/pkg/apitime"
+	"restaurant-booking/pkg/phone"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+func init() {
+	// Let binding tags like "required" see apitime.Time as a plain time.Time.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+			return field.Interface().(apitime.Time).Time
+		}, apitime.Time{})
+		v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return phone.Plausible(fl.Field().String())
+		})
+	}
+}
+
 type ErrorResponse struct {
 	Error string `json:"error" example:"invalid request"`
 }
@@ -12,3 +41,114 @@ type PaginationParams struct {
 	Limit  int `json:"limit" example:"10"`
 	Offset int `json:"offset" example:"0"`
 }
+
+// maxPageSize caps the limit of paginated listings.
+const maxPageSize = 100
+
+// PaginatedResponse is a page of a listing. Total counts every matching
+// item, not just the ones in Items.
+type PaginatedResponse struct {
+	Items interface{} `json:"items"`
+	Total int64       `json:"total" example:"42"`
+	PaginationParams
+}
+
+// paginationParams reads the limit and offset query parameters. limit
+// defaults to defaultLimit and is capped at maxPageSize. When limit is not
+// a positive integer or offset is not a non-negative one it writes a 400
+// response and returns false.
+func paginationParams(c *gin.Context, defaultLimit int) (PaginationParams, bool) {
+	params := PaginationParams{Limit: defaultLimit}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive integer"})
+			return params, false
+		}
+		params.Limit = min(limit, maxPageSize)
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "offset must be a non-negative integer"})
+			return params, false
+		}
+		params.Offset = offset
+	}
+
+	return params, true
+}
+
+// currentUserID returns the user set by AuthMiddleware.Authenticate. When it is
+// missing it writes a 401 response and returns false.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return uuid.Nil, false
+	}
+
+	userID, ok := value.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// bindErrorMessage turns a request binding error into a client-facing message,
+// naming the field and the expected format for malformed timestamps. The request
+// must have been bound with ShouldBindBodyWith so the raw body is still available.
+func bindErrorMessage(c *gin.Context, obj interface{}, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && apitime.IsTypeError(typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = invalidTimeField(c, obj)
+		}
+		return fmt.Sprintf("invalid %s: expected RFC3339 timestamp with timezone offset, e.g. %s", field, apitime.Example)
+	}
+	return err.Error()
+}
+
+// invalidTimeField finds the first top-level apitime.Time field of obj whose
+// value in the request body does not parse. Not every encoding/json version
+// reports the field path for errors returned by custom unmarshalers.
+func invalidTimeField(c *gin.Context, obj interface{}) string {
+	body, ok := c.Get(gin.BodyBytesKey)
+	if !ok {
+		return "timestamp"
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body.([]byte), &raw); err != nil {
+		return "timestamp"
+	}
+
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type != reflect.TypeOf(apitime.Time{}) {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+
+		var t apitime.Time
+		if err := json.Unmarshal(value, &t); err != nil {
+			return name
+		}
+	}
+
+	return "timestamp"
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func bindCreateBookingRequest(t *testing.T, startTime string) (CreateBookingRequest, string, error) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{
+		"restaurant_id": "7f1b5a6e-1d2c-4b3a-9e8f-0a1b2c3d4e5f",
+		"table_id": "8e2c6b7f-2e3d-4c4b-af90-1b2c3d4e5f60",
+		"user_id": "9f3d7c80-3f4e-4d5c-b0a1-2c3d4e5f6071",
+		"booking_date": "2024-06-01T00:00:00Z",
+		"start_time": ` + startTime + `,
+		"end_time": "2024-06-01T21:00:00Z",
+		"guests_count": 2
+	}`
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req CreateBookingRequest
+	err := c.ShouldBindBodyWith(&req, binding.JSON)
+	if err != nil {
+		return req, bindErrorMessage(c, &req, err), err
+	}
+	return req, "", nil
+}
+
+func TestBindCreateBookingRequest_RFC3339(t *testing.T) {
+	req, _, err := bindCreateBookingRequest(t, `"2024-06-01T19:00:00+03:00"`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 16, req.StartTime.UTC().Hour())
+}
+
+func TestBindCreateBookingRequest_NaiveTimestamp(t *testing.T) {
+	_, message, err := bindCreateBookingRequest(t, `"2024-06-01 19:00"`)
+
+	assert.Error(t, err)
+	assert.Equal(t,
+		"invalid start_time: expected RFC3339 timestamp with timezone offset, e.g. 2024-06-01T19:00:00Z",
+		message,
+	)
+}
+
+func TestBindCreateBookingRequest_Garbage(t *testing.T) {
+	_, message, err := bindCreateBookingRequest(t, `"not a time"`)
+
+	assert.Error(t, err)
+	assert.Contains(t, message, "invalid start_time")
+}
+
+func TestBindCreateBookingRequest_MissingTime(t *testing.T) {
+	_, _, err := bindCreateBookingRequest(t, `null`)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "StartTime")
+}
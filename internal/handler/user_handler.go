@@ -1,72 +1,277 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type UserHandler struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	userService service.UserService
 }
 
-func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
-	return &UserHandler{userRepo: userRepo}
+func NewUserHandler(userRepo repository.UserRepository, userService service.UserService) *UserHandler {
+	return &UserHandler{
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		return
+	}
+
+	user.Password = ""
+
+	c.JSON(http.StatusOK, user)
 }
 
-func (h *UserHandler) CreateUser(c *gin.Context) {
-	var req CreateUserRequest
+// UpdateMe changes the profile of the authenticated user. Fields left out of
+// the request keep their current value.
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateMeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	user := &domain.User{
-		Email:     req.Email,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Phone:     req.Phone,
-		Role:      req.Role,
+	if req.Email != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "email cannot be changed through this endpoint"})
+		return
+	}
+	if req.Role != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "role cannot be changed through this endpoint"})
+		return
 	}
 
-	if err := h.userRepo.Create(user); err != nil {
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	user.Password = ""
+	firstName, lastName, phone, locale := user.FirstName, user.LastName, user.Phone, user.Locale
+	if req.FirstName != nil {
+		firstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		lastName = *req.LastName
+	}
+	if req.Phone != nil {
+		phone = *req.Phone
+	}
+	if req.Locale != nil {
+		locale = *req.Locale
+	}
+
+	user, err = h.userService.UpdateUser(userID, firstName, lastName, phone, locale)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		case errors.Is(err, service.ErrPhoneTaken):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "phone number is already in use"})
+		case errors.Is(err, service.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid phone number"})
+		case errors.Is(err, service.ErrInvalidLocale):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "locale must be one of en, ru, kk"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
 
-	c.JSON(http.StatusCreated, user)
+	c.JSON(http.StatusOK, toUserResponse(user))
 }
 
-func (h *UserHandler) GetUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
+// ChangePassword replaces the authenticated user's password. The service ends
+// every session of the user afterwards, so other devices have to log in again.
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), userID, req.OldPassword, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOldPasswordIncorrect):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "old password is incorrect"})
+		case errors.Is(err, service.ErrInvalidPassword):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "new password must be at least 8 characters"})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "password changed, please log in again on your other devices"})
+}
+
+// DeleteMe deactivates the authenticated user's account. Nothing is deleted:
+// the account stops working, every session ends and upcoming pending
+// bookings are cancelled. An admin can reactivate it later.
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	cancelled, err := h.userService.DeactivateAccount(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
-	user, err := h.userRepo.GetByID(id)
+	c.JSON(http.StatusOK, DeactivateAccountResponse{
+		Message:           "account deactivated",
+		BookingsCancelled: cancelled,
+	})
+}
+
+// SendPhoneCode texts a verification code to the authenticated user's phone.
+func (h *UserHandler) SendPhoneCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.SendPhoneCode(c.Request.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		case errors.Is(err, service.ErrNoPhone), errors.Is(err, service.ErrPhoneAlreadyVerified):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrPhoneCodeCooldown):
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{Message: "verification code sent"})
+}
+
+// VerifyPhone marks the authenticated user's phone verified with the code
+// texted to it.
+func (h *UserHandler) VerifyPhone(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.userService.VerifyPhone(c.Request.Context(), userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		case errors.Is(err, service.ErrInvalidPhoneCode), errors.Is(err, service.ErrPhoneAlreadyVerified):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "phone number verified"})
+}
+
+// ClaimGuestBookings links the guest bookings staff made for the
+// authenticated user's verified account phone to the account.
+func (h *UserHandler) ClaimGuestBookings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	claimed, err := h.userService.ClaimGuestBookings(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		case errors.Is(err, service.ErrNoPhoneToClaim):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrPhoneNotVerified):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
 		return
 	}
 
-	user.Password = ""
+	c.JSON(http.StatusOK, ClaimGuestBookingsResponse{BookingsClaimed: claimed})
+}
 
-	c.JSON(http.StatusOK, user)
+type ClaimGuestBookingsResponse struct {
+	BookingsClaimed int64 `json:"bookings_claimed"`
+}
+
+type DeactivateAccountResponse struct {
+	Message           string `json:"message"`
+	BookingsCancelled int64  `json:"bookings_cancelled"`
 }
 
-type CreateUserRequest struct {
-	Email     string          `json:"email" binding:"required,email"`
-	Password  string          `json:"password" binding:"required,min=6"`
-	FirstName string          `json:"first_name" binding:"required"`
-	LastName  string          `json:"last_name" binding:"required"`
-	Phone     string          `json:"phone" binding:"required"`
-	Role      domain.UserRole `json:"role" binding:"required"`
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type UpdateMeRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=1,max=100"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=1,max=100"`
+	Phone     *string `json:"phone" binding:"omitempty,phone"`
+	// Locale is the language notifications are sent in.
+	Locale *domain.Locale `json:"locale" binding:"omitempty,oneof=en ru kk"`
+
+	// Email and Role are only bound to reject requests that try to set them.
+	Email json.RawMessage `json:"email,omitempty" swaggerignore:"true"`
+	Role  json.RawMessage `json:"role,omitempty" swaggerignore:"true"`
 }
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProfileService struct {
+	service.UserService
+	user      *domain.User
+	updateErr error
+	updated   bool
+}
+
+func (s *stubProfileService) GetUserByID(id uuid.UUID) (*domain.User, error) {
+	if s.user == nil || s.user.ID != id {
+		return nil, service.ErrUserNotFound
+	}
+	copied := *s.user
+	return &copied, nil
+}
+
+func (s *stubProfileService) UpdateUser(id uuid.UUID, firstName, lastName, phone string, locale domain.Locale) (*domain.User, error) {
+	if s.updateErr != nil {
+		return nil, s.updateErr
+	}
+	s.updated = true
+	s.user.FirstName = firstName
+	s.user.LastName = lastName
+	s.user.Phone = phone
+	s.user.Locale = locale
+	return s.user, nil
+}
+
+func newProfileStub() (*stubProfileService, uuid.UUID) {
+	userID := uuid.New()
+	return &stubProfileService{user: &domain.User{
+		ID:        userID,
+		Email:     "john@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Phone:     "1234567890",
+		Role:      domain.UserRoleCustomer,
+	}}, userID
+}
+
+func TestUpdateMe_NoToken(t *testing.T) {
+	svc, _ := newProfileStub()
+
+	w := performAsUser(NewUserHandler(nil, svc).UpdateMe, http.MethodPut, "/api/users/me",
+		"/api/users/me", nil, `{"first_name":"Jane"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUpdateMe_PartialUpdate(t *testing.T) {
+	svc, userID := newProfileStub()
+
+	w := performAsUser(NewUserHandler(nil, svc).UpdateMe, http.MethodPut, "/api/users/me",
+		"/api/users/me", &userID, `{"first_name":"Jane","phone":"+77001234567"}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Jane", resp.FirstName)
+	assert.Equal(t, "Doe", resp.LastName)
+	assert.Equal(t, "+77001234567", resp.Phone)
+	assert.Equal(t, "john@example.com", resp.Email)
+}
+
+func TestUpdateMe_RejectsInvalidRequests(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"email change", `{"email":"other@example.com"}`},
+		{"role change", `{"first_name":"Jane","role":"admin"}`},
+		{"invalid phone", `{"phone":"call me"}`},
+		{"short phone", `{"phone":"12345"}`},
+		{"empty first name", `{"first_name":""}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, userID := newProfileStub()
+
+			w := performAsUser(NewUserHandler(nil, svc).UpdateMe, http.MethodPut, "/api/users/me",
+				"/api/users/me", &userID, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.False(t, svc.updated)
+			assert.Equal(t, domain.UserRoleCustomer, svc.user.Role)
+		})
+	}
+}
+
+func TestUpdateMe_PhoneTaken(t *testing.T) {
+	svc, userID := newProfileStub()
+	svc.updateErr = service.ErrPhoneTaken
+
+	w := performAsUser(NewUserHandler(nil, svc).UpdateMe, http.MethodPut, "/api/users/me",
+		"/api/users/me", &userID, `{"phone":"0987654321"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+type stubPasswordService struct {
+	service.UserService
+	err    error
+	userID uuid.UUID
+}
+
+func (s *stubPasswordService) ChangePassword(ctx context.Context, id uuid.UUID, oldPassword, newPassword string) error {
+	s.userID = id
+	return s.err
+}
+
+func TestChangePassword_Handler(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"success", nil, http.StatusOK, ""},
+		{"wrong old password", service.ErrOldPasswordIncorrect, http.StatusBadRequest, "old password is incorrect"},
+		{"short new password", service.ErrInvalidPassword, http.StatusBadRequest, "new password must be at least 8 characters"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubPasswordService{err: tc.err}
+			userID := uuid.New()
+
+			w := performAsUser(NewUserHandler(nil, svc).ChangePassword, http.MethodPost, "/api/users/me/password",
+				"/api/users/me/password", &userID, `{"old_password":"oldpassword123","new_password":"short"}`)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, userID, svc.userID)
+			if tc.message != "" {
+				var resp ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tc.message, resp.Error)
+			}
+		})
+	}
+}
+
+func TestChangePassword_Handler_NoToken(t *testing.T) {
+	svc := &stubPasswordService{}
+
+	w := performAsUser(NewUserHandler(nil, svc).ChangePassword, http.MethodPost, "/api/users/me/password",
+		"/api/users/me/password", nil, `{"old_password":"a","new_password":"b"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, uuid.Nil, svc.userID)
+}
+
+func TestUpdateMe_InvalidPhone(t *testing.T) {
+	svc, userID := newProfileStub()
+	svc.updateErr = service.ErrInvalidPhone
+
+	w := performAsUser(NewUserHandler(nil, svc).UpdateMe, http.MethodPut, "/api/users/me",
+		"/api/users/me", &userID, `{"phone":"+1 234 567 8901 234"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid phone number"}`, w.Body.String())
+}
+
+type stubClaimService struct {
+	service.UserService
+	claimed int64
+	err     error
+}
+
+func (s *stubClaimService) ClaimGuestBookings(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.claimed, s.err
+}
+
+func TestClaimGuestBookings(t *testing.T) {
+	userID := uuid.New()
+
+	w := performAsUser(NewUserHandler(nil, &stubClaimService{claimed: 2}).ClaimGuestBookings, http.MethodPost,
+		"/api/users/me/guest-bookings/claim", "/api/users/me/guest-bookings/claim", &userID, "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"bookings_claimed":2}`, w.Body.String())
+
+	w = performAsUser(NewUserHandler(nil, &stubClaimService{err: service.ErrNoPhoneToClaim}).ClaimGuestBookings, http.MethodPost,
+		"/api/users/me/guest-bookings/claim", "/api/users/me/guest-bookings/claim", &userID, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performAsUser(NewUserHandler(nil, &stubClaimService{err: service.ErrPhoneNotVerified}).ClaimGuestBookings, http.MethodPost,
+		"/api/users/me/guest-bookings/claim", "/api/users/me/guest-bookings/claim", &userID, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/apitime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+type WaitlistHandler struct {
+	waitlist service.WaitlistService
+}
+
+func NewWaitlistHandler(waitlist service.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{waitlist: waitlist}
+}
+
+// @Summary Join a restaurant's waitlist
+// @Description Puts the caller in line for a table for guests_count from start_time to end_time, checked like a booking except that no table has to be free. When a cancellation frees a fitting table, the oldest entry it is free for gets an email with a claim link, valid for 15 minutes.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param request body JoinWaitlistRequest true "Desired booking"
+// @Success 201 {object} domain.WaitlistEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/restaurants/{id}/waitlist [post]
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	restaurantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid restaurant id"})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req JoinWaitlistRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: bindErrorMessage(c, &req, err)})
+		return
+	}
+
+	entry, err := h.waitlist.Join(c.Request.Context(), service.JoinWaitlistRequest{
+		RestaurantID: restaurantID,
+		UserID:       userID,
+		StartTime:    req.StartTime.Time,
+		EndTime:      req.EndTime.Time,
+		GuestsCount:  req.GuestsCount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrInvalidBookingPeriod), errors.Is(err, service.ErrPastBooking),
+			errors.Is(err, service.ErrAfterLastSeating), errors.Is(err, service.ErrOutsideWorkingHours),
+			errors.Is(err, service.ErrNoFittingTable):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// @Summary Claim a waitlist offer
+// @Description Books the table a waitlist offer emailed to the caller. The booking is pending and any deposit is paid through the payment endpoints, as for any other booking. A lapsed offer is passed on to the next guest in line.
+// @Tags Waitlist
+// @Produce json
+// @Param token path string true "Claim token from the offer email"
+// @Success 201 {object} BookingResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/waitlist/claim/{token} [post]
+func (h *WaitlistHandler) ClaimOffer(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	booking, err := h.waitlist.Claim(c.Request.Context(), c.Param("token"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWaitlistOfferNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrRestaurantNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "restaurant not found"})
+		case errors.Is(err, service.ErrTableNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "table not found"})
+		case errors.Is(err, service.ErrWaitlistOfferExpired):
+			c.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, service.ErrWaitlistOfferClaimed), errors.Is(err, service.ErrTableNotAvailable),
+			errors.Is(err, service.ErrGuestLimitReached):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, BookingResponse{
+		Booking:       booking,
+		PolicySummary: service.PolicySummary(*booking.CancellationPolicy, requestLanguages(c)),
+	})
+}
+
+type JoinWaitlistRequest struct {
+	StartTime   apitime.Time `json:"start_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T19:00:00Z"`
+	EndTime     apitime.Time `json:"end_time" binding:"required" swaggertype:"string" format:"date-time" example:"2024-06-01T21:00:00Z"`
+	GuestsCount int          `json:"guests_count" binding:"required,min=1"`
+}
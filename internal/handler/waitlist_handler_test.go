@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubWaitlistService struct {
+	service.WaitlistService
+	err    error
+	join   *service.JoinWaitlistRequest
+	token  string
+	userID uuid.UUID
+	freed  []*domain.Booking
+}
+
+func (s *stubWaitlistService) Join(ctx context.Context, req service.JoinWaitlistRequest) (*domain.WaitlistEntry, error) {
+	s.join = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.WaitlistEntry{ID: uuid.New(), RestaurantID: req.RestaurantID, UserID: req.UserID, Status: domain.WaitlistStatusWaiting}, nil
+}
+
+func (s *stubWaitlistService) Claim(ctx context.Context, token string, userID uuid.UUID) (*domain.Booking, error) {
+	s.token, s.userID = token, userID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Booking{
+		ID:                 uuid.New(),
+		UserID:             &userID,
+		Status:             domain.BookingStatusPending,
+		CancellationPolicy: &domain.CancellationPolicy{},
+	}, nil
+}
+
+func (s *stubWaitlistService) SlotFreed(ctx context.Context, booking *domain.Booking) {
+	s.freed = append(s.freed, booking)
+}
+
+const waitlistBody = `{"start_time":"2030-06-01T19:00:00+05:00","end_time":"2030-06-01T21:00:00+05:00","guests_count":4}`
+
+func TestJoinWaitlist(t *testing.T) {
+	waitlist := &stubWaitlistService{}
+	userID, restaurantID := uuid.New(), uuid.New()
+
+	w := performAsUser(NewWaitlistHandler(waitlist).JoinWaitlist, http.MethodPost, "/api/restaurants/:id/waitlist",
+		"/api/restaurants/"+restaurantID.String()+"/waitlist", &userID, waitlistBody)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, waitlist.join)
+	assert.Equal(t, restaurantID, waitlist.join.RestaurantID)
+	assert.Equal(t, userID, waitlist.join.UserID)
+	assert.True(t, time.Date(2030, time.June, 1, 14, 0, 0, 0, time.UTC).Equal(waitlist.join.StartTime))
+	assert.True(t, time.Date(2030, time.June, 1, 16, 0, 0, 0, time.UTC).Equal(waitlist.join.EndTime))
+	assert.Equal(t, 4, waitlist.join.GuestsCount)
+
+	var entry domain.WaitlistEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, domain.WaitlistStatusWaiting, entry.Status)
+}
+
+func TestJoinWaitlist_Errors(t *testing.T) {
+	userID := uuid.New()
+	cases := map[string]struct {
+		userID *uuid.UUID
+		body   string
+		err    error
+		want   int
+	}{
+		"no token":          {nil, waitlistBody, nil, http.StatusUnauthorized},
+		"no guests":         {&userID, `{"start_time":"2030-06-01T19:00:00Z","end_time":"2030-06-01T21:00:00Z","guests_count":0}`, nil, http.StatusBadRequest},
+		"unknown":           {&userID, waitlistBody, service.ErrRestaurantNotFound, http.StatusNotFound},
+		"in the past":       {&userID, waitlistBody, service.ErrPastBooking, http.StatusBadRequest},
+		"closed":            {&userID, waitlistBody, service.ErrOutsideWorkingHours, http.StatusBadRequest},
+		"party too large":   {&userID, waitlistBody, service.ErrNoFittingTable, http.StatusBadRequest},
+		"connection failed": {&userID, waitlistBody, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			waitlist := &stubWaitlistService{err: tc.err}
+
+			w := performAsUser(NewWaitlistHandler(waitlist).JoinWaitlist, http.MethodPost, "/api/restaurants/:id/waitlist",
+				"/api/restaurants/"+uuid.NewString()+"/waitlist", tc.userID, tc.body)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestClaimWaitlistOffer(t *testing.T) {
+	waitlist := &stubWaitlistService{}
+	userID := uuid.New()
+
+	w := performAsUser(NewWaitlistHandler(waitlist).ClaimOffer, http.MethodPost, "/api/waitlist/claim/:token",
+		"/api/waitlist/claim/secret", &userID, "")
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "secret", waitlist.token)
+	assert.Equal(t, userID, waitlist.userID)
+	assert.Contains(t, w.Body.String(), `"policy_summary"`)
+}
+
+func TestClaimWaitlistOffer_Errors(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"unknown token": {service.ErrWaitlistOfferNotFound, http.StatusNotFound},
+		"too late":      {service.ErrWaitlistOfferExpired, http.StatusGone},
+		"claimed":       {service.ErrWaitlistOfferClaimed, http.StatusConflict},
+		"table taken":   {service.ErrTableNotAvailable, http.StatusConflict},
+		"full":          {service.ErrGuestLimitReached, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			userID := uuid.New()
+
+			w := performAsUser(NewWaitlistHandler(&stubWaitlistService{err: tc.err}).ClaimOffer, http.MethodPost, "/api/waitlist/claim/:token",
+				"/api/waitlist/claim/secret", &userID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
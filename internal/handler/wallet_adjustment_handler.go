@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WalletAdjustmentHandler struct {
+	adjustmentService service.WalletAdjustmentService
+}
+
+func NewWalletAdjustmentHandler(adjustmentService service.WalletAdjustmentService) *WalletAdjustmentHandler {
+	return &WalletAdjustmentHandler{adjustmentService: adjustmentService}
+}
+
+// @Summary Adjust a wallet balance
+// @Description Credits or debits a user's wallet by hand, e.g. for goodwill. Amounts up to the approval threshold are applied at once (201); larger ones wait for another admin's approval (202) and change the balance only then.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body AdjustWalletRequest true "Adjustment"
+// @Success 201 {object} domain.WalletAdjustment
+// @Success 202 {object} domain.WalletAdjustment
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/wallets/{user_id}/adjust [post]
+func (h *WalletAdjustmentHandler) AdjustWallet(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+		return
+	}
+
+	var req AdjustWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Adjust(c.Request.Context(), adminID, userID, service.AdjustWalletRequest{
+		Amount:    req.Amount,
+		Direction: req.Direction,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		writeWalletAdjustmentError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if adjustment.Status == domain.WalletAdjustmentPending {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, adjustment)
+}
+
+// @Summary List wallet adjustments awaiting approval
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.WalletAdjustment
+// @Failure 400 {object} ErrorResponse
+// @Router /api/admin/wallet-adjustments [get]
+func (h *WalletAdjustmentHandler) ListPending(c *gin.Context) {
+	page, ok := paginationParams(c, 20)
+	if !ok {
+		return
+	}
+
+	adjustments, err := h.adjustmentService.ListPending(c.Request.Context(), page.Limit, page.Offset)
+	if err != nil {
+		writeWalletAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustments)
+}
+
+// @Summary Approve a wallet adjustment
+// @Description Applies a pending adjustment. It must be approved by an admin other than the one who requested it, before it expires.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} domain.WalletAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/admin/wallet-adjustments/{id}/approve [post]
+func (h *WalletAdjustmentHandler) Approve(c *gin.Context) {
+	h.decide(c, h.adjustmentService.Approve)
+}
+
+// @Summary Reject a wallet adjustment
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} domain.WalletAdjustment
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /api/admin/wallet-adjustments/{id}/reject [post]
+func (h *WalletAdjustmentHandler) Reject(c *gin.Context) {
+	h.decide(c, h.adjustmentService.Reject)
+}
+
+func (h *WalletAdjustmentHandler) decide(c *gin.Context, fn func(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error)) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid adjustment id"})
+		return
+	}
+
+	adjustment, err := fn(c.Request.Context(), adminID, id)
+	if err != nil {
+		writeWalletAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+func writeWalletAdjustmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInvalidAdjustmentDirection),
+		errors.Is(err, service.ErrAdjustmentReasonTooShort):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrWalletAdjustmentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrWalletAdjustmentDecided),
+		errors.Is(err, service.ErrInsufficientBalance):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrWalletAdjustmentExpired):
+		c.JSON(http.StatusGone, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+type AdjustWalletRequest struct {
+	Amount    int                        `json:"amount" binding:"required,min=1" example:"5000"`
+	Direction domain.AdjustmentDirection `json:"direction" binding:"required,oneof=credit debit" example:"credit"`
+	Reason    string                     `json:"reason" binding:"required" example:"Goodwill credit for a cancelled event"`
+}
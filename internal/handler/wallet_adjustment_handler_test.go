@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubWalletAdjustmentService struct {
+	service.WalletAdjustmentService
+	adjusted *service.AdjustWalletRequest
+	status   domain.WalletAdjustmentStatus
+	err      error
+}
+
+func (s *stubWalletAdjustmentService) Adjust(ctx context.Context, adminID, userID uuid.UUID, req service.AdjustWalletRequest) (*domain.WalletAdjustment, error) {
+	s.adjusted = &req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.WalletAdjustment{ID: uuid.New(), UserID: userID, Amount: req.Amount, Status: s.status, RequestedBy: adminID}, nil
+}
+
+func (s *stubWalletAdjustmentService) Approve(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.WalletAdjustment{ID: id, Status: domain.WalletAdjustmentApplied, DecidedBy: &adminID}, nil
+}
+
+func TestAdjustWallet_Status(t *testing.T) {
+	adminID := uuid.New()
+	target := "/api/admin/wallets/" + uuid.NewString() + "/adjust"
+	body := `{"amount":5000,"direction":"credit","reason":"Goodwill credit"}`
+
+	for status, want := range map[domain.WalletAdjustmentStatus]int{
+		domain.WalletAdjustmentApplied: http.StatusCreated,
+		domain.WalletAdjustmentPending: http.StatusAccepted,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			stub := &stubWalletAdjustmentService{status: status}
+
+			w := performAsUser(NewWalletAdjustmentHandler(stub).AdjustWallet, http.MethodPost, "/api/admin/wallets/:user_id/adjust",
+				target, &adminID, body)
+
+			require.Equal(t, want, w.Code)
+			require.NotNil(t, stub.adjusted)
+			assert.Equal(t, 5000, stub.adjusted.Amount)
+			assert.Equal(t, domain.AdjustmentCredit, stub.adjusted.Direction)
+		})
+	}
+}
+
+func TestAdjustWallet_BadRequest(t *testing.T) {
+	adminID := uuid.New()
+	target := "/api/admin/wallets/" + uuid.NewString() + "/adjust"
+
+	cases := map[string]struct {
+		body string
+		err  error
+	}{
+		"no reason":         {`{"amount":5000,"direction":"credit"}`, nil},
+		"negative amount":   {`{"amount":-5,"direction":"debit","reason":"Goodwill credit"}`, nil},
+		"unknown direction": {`{"amount":5000,"direction":"refund","reason":"Goodwill credit"}`, nil},
+		"short reason":      {`{"amount":5000,"direction":"credit","reason":"oops"}`, service.ErrAdjustmentReasonTooShort},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubWalletAdjustmentService{err: tc.err}
+
+			w := performAsUser(NewWalletAdjustmentHandler(stub).AdjustWallet, http.MethodPost, "/api/admin/wallets/:user_id/adjust",
+				target, &adminID, tc.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestApproveWalletAdjustment_Errors(t *testing.T) {
+	adminID := uuid.New()
+	target := "/api/admin/wallet-adjustments/" + uuid.NewString() + "/approve"
+
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"self approval":        {service.ErrSelfApproval, http.StatusForbidden},
+		"not found":            {service.ErrWalletAdjustmentNotFound, http.StatusNotFound},
+		"already decided":      {service.ErrWalletAdjustmentDecided, http.StatusConflict},
+		"insufficient balance": {service.ErrInsufficientBalance, http.StatusConflict},
+		"expired":              {service.ErrWalletAdjustmentExpired, http.StatusGone},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stub := &stubWalletAdjustmentService{err: tc.err}
+
+			w := performAsUser(NewWalletAdjustmentHandler(stub).Approve, http.MethodPost, "/api/admin/wallet-adjustments/:id/approve",
+				target, &adminID, "")
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestApproveWalletAdjustment(t *testing.T) {
+	adminID := uuid.New()
+
+	w := performAsUser(NewWalletAdjustmentHandler(&stubWalletAdjustmentService{}).Approve, http.MethodPost, "/api/admin/wallet-adjustments/:id/approve",
+		"/api/admin/wallet-adjustments/"+uuid.NewString()+"/approve", &adminID, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"applied"`)
+}
@@ -7,7 +7,6 @@ import (
 	"restaurant-booking/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type WalletHandler struct {
@@ -19,24 +18,16 @@ func NewWalletHandler(walletService service.WalletService) *WalletHandler {
 }
 
 // @Summary Get user wallet
-// @Description Get or create wallet for a user
+// @Description Get or create the logged-in user's wallet
 // @Tags Wallet
 // @Produce json
-// @Param user_id query string true "User ID"
 // @Success 200 {object} domain.Wallet
-// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/wallet [get]
 func (h *WalletHandler) GetWallet(c *gin.Context) {
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "user_id is required"})
-		return
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -49,62 +40,26 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 	c.JSON(http.StatusOK, wallet)
 }
 
-// @Summary Deposit to wallet
-// @Description Add funds to user's wallet
-// @Tags Wallet
-// @Accept json
-// @Produce json
-// @Param request body DepositRequest true "Deposit request"
-// @Success 200 {object} domain.Wallet
-// @Failure 400 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /api/wallet/deposit [post]
-func (h *WalletHandler) Deposit(c *gin.Context) {
-	var req DepositRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
-		return
-	}
-
-	if err := h.walletService.Deposit(c.Request.Context(), userID, req.Amount, req.Description); err != nil {
-		if errors.Is(err, service.ErrInvalidAmount) {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	wallet, _ := h.walletService.GetOrCreateWallet(c.Request.Context(), userID)
-	c.JSON(http.StatusOK, wallet)
-}
-
 // @Summary Withdraw from wallet
-// @Description Withdraw funds from user's wallet
+// @Description Withdraw funds from the logged-in user's wallet
 // @Tags Wallet
 // @Accept json
 // @Produce json
 // @Param request body WithdrawRequest true "Withdraw request"
 // @Success 200 {object} domain.Wallet
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/wallet/withdraw [post]
 func (h *WalletHandler) Withdraw(c *gin.Context) {
-	var req WithdrawRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	var req WithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -125,26 +80,18 @@ func (h *WalletHandler) Withdraw(c *gin.Context) {
 }
 
 // @Summary Get wallet transactions
-// @Description Get transaction history for a user's wallet
+// @Description Get transaction history for the logged-in user's wallet
 // @Tags Wallet
 // @Produce json
-// @Param user_id query string true "User ID"
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {array} domain.WalletTransaction
-// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/wallet/transactions [get]
 func (h *WalletHandler) GetTransactions(c *gin.Context) {
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "user_id is required"})
-		return
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user_id"})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -167,14 +114,7 @@ func (h *WalletHandler) GetTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, transactions)
 }
 
-type DepositRequest struct {
-	UserID      string `json:"user_id" binding:"required"`
-	Amount      int    `json:"amount" binding:"required,min=1"`
-	Description string `json:"description"`
-}
-
 type WithdrawRequest struct {
-	UserID      string `json:"user_id" binding:"required"`
 	Amount      int    `json:"amount" binding:"required,min=1"`
 	Description string `json:"description"`
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"restaurant-booking/internal/handler"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyMiddleware struct {
+	apiKeys service.APIKeyService
+}
+
+func NewAPIKeyMiddleware(apiKeys service.APIKeyService) *APIKeyMiddleware {
+	return &APIKeyMiddleware{apiKeys: apiKeys}
+}
+
+// Authenticate resolves the X-API-Key header to its restaurant and puts it
+// into the context as "api_key_restaurant_id". Requests without the header
+// pass through unchanged. Keys only grant reads, and only of the restaurant
+// in the :id route parameter.
+func (m *APIKeyMiddleware) Authenticate() gin.HandlerFunc {
+	return m.AuthenticateOr(func(c *gin.Context) { c.Next() })
+}
+
+// AuthenticateOr is Authenticate for routes that are not public: requests
+// without the header go through fallback instead, normally the user login.
+func (m *APIKeyMiddleware) AuthenticateOr(fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plainKey := c.GetHeader(APIKeyHeader)
+		if plainKey == "" {
+			fallback(c)
+			return
+		}
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.AbortWithStatusJSON(http.StatusForbidden, handler.ErrorResponse{Error: "API keys are read-only"})
+			return
+		}
+
+		key, err := m.apiKeys.Authenticate(c.Request.Context(), plainKey)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ErrorResponse{Error: "Invalid or revoked API key"})
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, handler.ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		restaurantID, err := uuid.Parse(c.Param("id"))
+		if err != nil || restaurantID != key.RestaurantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, handler.ErrorResponse{Error: "API key does not belong to this restaurant"})
+			return
+		}
+
+		c.Set("api_key_restaurant_id", key.RestaurantID)
+		c.Set("api_key", key)
+		c.Next()
+	}
+}
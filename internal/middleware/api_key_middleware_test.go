@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAPIKeyService struct {
+	service.APIKeyService
+	key *domain.APIKey
+}
+
+func (s *stubAPIKeyService) Authenticate(ctx context.Context, plainKey string) (*domain.APIKey, error) {
+	if s.key == nil || plainKey != "rbk_valid" {
+		return nil, service.ErrInvalidAPIKey
+	}
+	return s.key, nil
+}
+
+func performWithAPIKey(keys service.APIKeyService, method, restaurantID, apiKey string) (*httptest.ResponseRecorder, interface{}) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var resolved interface{}
+	router.Handle(method, "/api/restaurants/:id/bookings", NewAPIKeyMiddleware(keys).Authenticate(), func(c *gin.Context) {
+		resolved, _ = c.Get("api_key_restaurant_id")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(method, "/api/restaurants/"+restaurantID+"/bookings", nil)
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, resolved
+}
+
+func TestAPIKeyAuthenticate_OwnRestaurant(t *testing.T) {
+	restaurantID := uuid.New()
+	keys := &stubAPIKeyService{key: &domain.APIKey{ID: uuid.New(), RestaurantID: restaurantID}}
+
+	w, resolved := performWithAPIKey(keys, http.MethodGet, restaurantID.String(), "rbk_valid")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, restaurantID, resolved)
+}
+
+func TestAPIKeyAuthenticate_OtherRestaurant(t *testing.T) {
+	keys := &stubAPIKeyService{key: &domain.APIKey{ID: uuid.New(), RestaurantID: uuid.New()}}
+
+	w, _ := performWithAPIKey(keys, http.MethodGet, uuid.NewString(), "rbk_valid")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIKeyAuthenticate_InvalidKey(t *testing.T) {
+	restaurantID := uuid.New()
+	keys := &stubAPIKeyService{key: &domain.APIKey{RestaurantID: restaurantID}}
+
+	w, _ := performWithAPIKey(keys, http.MethodGet, restaurantID.String(), "rbk_revoked")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIKeyAuthenticate_ReadOnly(t *testing.T) {
+	restaurantID := uuid.New()
+	keys := &stubAPIKeyService{key: &domain.APIKey{RestaurantID: restaurantID}}
+
+	w, _ := performWithAPIKey(keys, http.MethodPost, restaurantID.String(), "rbk_valid")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIKeyAuthenticate_NoKeyPassesThrough(t *testing.T) {
+	w, resolved := performWithAPIKey(&stubAPIKeyService{}, http.MethodGet, uuid.NewString(), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, resolved)
+}
+
+func TestAPIKeyAuthenticateOr_NoKeyRunsFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	login := func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+	router.GET("/api/restaurants/:id/bookings", NewAPIKeyMiddleware(&stubAPIKeyService{}).AuthenticateOr(login), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/restaurants/"+uuid.NewString()+"/bookings", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIKeyAuthenticateOr_KeySkipsFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	restaurantID := uuid.New()
+	keys := &stubAPIKeyService{key: &domain.APIKey{ID: uuid.New(), RestaurantID: restaurantID}}
+	login := func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+	router.GET("/api/restaurants/:id/bookings", NewAPIKeyMiddleware(keys).AuthenticateOr(login), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/restaurants/"+restaurantID.String()+"/bookings", nil)
+	req.Header.Set(APIKeyHeader, "rbk_valid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
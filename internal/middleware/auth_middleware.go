@@ -3,8 +3,10 @@ package middleware
 import (
 	"net/http"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
 	"restaurant-booking/pkg/jwt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +14,14 @@ import (
 type AuthMiddleware struct {
 	jwtManager *jwt.Manager
 	userRepo   repository.UserRepository
+	blacklist  service.TokenBlacklist
 }
 
-func NewAuthMiddleware(jwtManager *jwt.Manager, userRepo repository.UserRepository) *AuthMiddleware {
+func NewAuthMiddleware(jwtManager *jwt.Manager, userRepo repository.UserRepository, blacklist service.TokenBlacklist) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtManager: jwtManager,
 		userRepo:   userRepo,
+		blacklist:  blacklist,
 	}
 }
 
@@ -47,6 +51,18 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		// A blacklist that cannot be read fails closed.
+		revoked, err := m.blacklist.IsRevoked(claims.ID, claims.UserID, issuedAt)
+		if err != nil || revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		user, err := m.userRepo.GetByID(claims.UserID)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
@@ -54,10 +70,32 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		if !user.IsActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", user.ID)
 		c.Set("user_role", user.Role)
 		c.Set("user", user)
+		c.Set("token_claims", claims)
+		c.Request = c.Request.WithContext(service.WithActor(c.Request.Context(), service.Actor{ID: user.ID, Role: user.Role}))
 
 		c.Next()
 	}
 }
+
+// OptionalAuthenticate lets requests without an Authorization header through
+// anonymously and authenticates the rest like Authenticate, so a bad token
+// is still rejected rather than silently ignored.
+func (m *AuthMiddleware) OptionalAuthenticate() gin.HandlerFunc {
+	authenticate := m.Authenticate()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
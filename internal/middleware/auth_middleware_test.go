@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/internal/service"
+	"restaurant-booking/pkg/jwt"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUserRepository struct {
+	repository.UserRepository
+	user *domain.User
+}
+
+func (r *stubUserRepository) GetByID(id uuid.UUID) (*domain.User, error) {
+	return r.user, nil
+}
+
+func performAuthenticated(m *AuthMiddleware, token string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", m.Authenticate(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate_RejectsRevokedToken(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour)
+	blacklist := service.NewInMemoryTokenBlacklist()
+	user := &domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer, IsActive: true}
+	m := NewAuthMiddleware(jwtManager, &stubUserRepository{user: user}, blacklist)
+
+	token, err := jwtManager.GenerateAccessToken(user.ID, user.Role)
+	require.NoError(t, err)
+	claims, err := jwtManager.ValidateAccessToken(token)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, performAuthenticated(m, token).Code)
+
+	require.NoError(t, blacklist.Revoke(claims.ID, claims.ExpiresAt.Time))
+
+	w := performAuthenticated(m, token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Token has been revoked"}`, w.Body.String())
+}
+
+func TestAuthenticate_RejectsTokensIssuedBeforeUserRevocation(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour)
+	blacklist := service.NewInMemoryTokenBlacklist()
+	user := &domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer, IsActive: true}
+	m := NewAuthMiddleware(jwtManager, &stubUserRepository{user: user}, blacklist)
+
+	token, err := jwtManager.GenerateAccessToken(user.ID, user.Role)
+	require.NoError(t, err)
+
+	// Revocation cutoffs have whole seconds, like the token's iat.
+	later := time.Now().Add(time.Second)
+	require.NoError(t, blacklist.RevokeUser(user.ID, later, later.Add(time.Hour)))
+
+	assert.Equal(t, http.StatusUnauthorized, performAuthenticated(m, token).Code)
+}
+
+func TestAuthenticate_AcceptsTokenIssuedRightAfterUserRevocation(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour)
+	blacklist := service.NewInMemoryTokenBlacklist()
+	user := &domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer, IsActive: true}
+	m := NewAuthMiddleware(jwtManager, &stubUserRepository{user: user}, blacklist)
+
+	now := time.Now()
+	require.NoError(t, blacklist.RevokeUser(user.ID, now, now.Add(time.Hour)))
+	token, err := jwtManager.GenerateAccessToken(user.ID, user.Role)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, performAuthenticated(m, token).Code)
+}
+
+func TestAuthenticate_RejectsDeactivatedUser(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour)
+	user := &domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer}
+	m := NewAuthMiddleware(jwtManager, &stubUserRepository{user: user}, service.NewInMemoryTokenBlacklist())
+
+	token, err := jwtManager.GenerateAccessToken(user.ID, user.Role)
+	require.NoError(t, err)
+
+	w := performAuthenticated(m, token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Account is deactivated"}`, w.Body.String())
+}
+
+func TestOptionalAuthenticate(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour)
+	user := &domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer, IsActive: true}
+	m := NewAuthMiddleware(jwtManager, &stubUserRepository{user: user}, service.NewInMemoryTokenBlacklist())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/public", m.OptionalAuthenticate(), func(c *gin.Context) {
+		_, signedIn := c.Get("user_id")
+		c.JSON(http.StatusOK, gin.H{"signed_in": signedIn})
+	})
+	perform := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/public", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	token, err := jwtManager.GenerateAccessToken(user.ID, user.Role)
+	require.NoError(t, err)
+
+	w := perform("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"signed_in":false}`, w.Body.String())
+
+	w = perform("Bearer " + token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"signed_in":true}`, w.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, perform("Bearer not-a-token").Code)
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestInfo puts the client IP and user agent into the request context so
+// audit entries recorded further down can be attributed.
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := service.WithRequestInfo(c.Request.Context(), service.RequestInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxSampledBodyBytes caps how much of a request or response body is read
+// for a sample. Larger bodies are still passed through untouched.
+const maxSampledBodyBytes = 64 << 10
+
+// RequestSampler stores a sanitized copy of a share of requests so support
+// can see what a customer actually sent. Error responses and successful
+// ones are sampled at separate rates.
+type RequestSampler struct {
+	samples     service.RequestSampleService
+	successRate float64
+	errorRate   float64
+	roll        func() float64
+}
+
+func NewRequestSampler(samples service.RequestSampleService, successRate, errorRate float64) *RequestSampler {
+	return &RequestSampler{
+		samples:     samples,
+		successRate: successRate,
+		errorRate:   errorRate,
+		roll:        rand.Float64,
+	}
+}
+
+// Sample must run before Authenticate on the route so that requests
+// rejected there are sampled too; the user ID is read once the handler
+// chain has finished.
+func (s *RequestSampler) Sample() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxSampledBodyBytes))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &sampledResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		rate := s.successRate
+		if status >= 400 {
+			rate = s.errorRate
+		}
+		if rate <= 0 || s.roll() >= rate {
+			return
+		}
+
+		sample := &domain.RequestSample{
+			Method: c.Request.Method,
+			Route:  c.FullPath(),
+			Path:   c.Request.URL.Path,
+			Status: status,
+			Body:   service.SanitizeRequestBody(body),
+		}
+		if value, ok := c.Get("user_id"); ok {
+			if userID, ok := value.(uuid.UUID); ok {
+				sample.UserID = &userID
+			}
+		}
+		if status >= 400 {
+			var response struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(writer.body.Bytes(), &response) == nil {
+				sample.Error = response.Error
+			}
+		}
+
+		s.samples.Record(c.Request.Context(), sample)
+	}
+}
+
+// sampledResponseWriter keeps the start of the response body so the error
+// message can be stored with the sample.
+type sampledResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *sampledResponseWriter) Write(data []byte) (int, error) {
+	if room := maxSampledBodyBytes - w.body.Len(); room > 0 {
+		if len(data) < room {
+			room = len(data)
+		}
+		w.body.Write(data[:room])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sampledResponseWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
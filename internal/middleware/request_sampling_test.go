@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRequestSampleService struct {
+	service.RequestSampleService
+	recorded []*domain.RequestSample
+}
+
+func (s *stubRequestSampleService) Record(ctx context.Context, sample *domain.RequestSample) {
+	s.recorded = append(s.recorded, sample)
+}
+
+func performSampled(sampler *RequestSampler, userID *uuid.UUID, status int, body string) (*httptest.ResponseRecorder, string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var received string
+	router.POST("/api/bookings/:id/cancel", sampler.Sample(), func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", *userID)
+		}
+		data, _ := io.ReadAll(c.Request.Body)
+		received = string(data)
+		if status >= http.StatusBadRequest {
+			c.JSON(status, gin.H{"error": "booking not found"})
+			return
+		}
+		c.JSON(status, gin.H{"ok": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/bookings/42/cancel", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, received
+}
+
+func TestRequestSampler_StoresErrorWithoutSecrets(t *testing.T) {
+	samples := &stubRequestSampleService{}
+	sampler := NewRequestSampler(samples, 0, 1)
+	userID := uuid.New()
+	body := `{"guests_count":2,"password":"hunter2","token":"tok_live_123","special_note":"+77010000000"}`
+
+	w, received := performSampled(sampler, &userID, http.StatusNotFound, body)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, body, received, "the handler must still see the full body")
+	require.Len(t, samples.recorded, 1)
+
+	sample := samples.recorded[0]
+	assert.Equal(t, "/api/bookings/:id/cancel", sample.Route)
+	assert.Equal(t, "/api/bookings/42/cancel", sample.Path)
+	assert.Equal(t, http.StatusNotFound, sample.Status)
+	assert.Equal(t, "booking not found", sample.Error)
+	assert.Equal(t, &userID, sample.UserID)
+
+	stored, err := json.Marshal(sample)
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "tok_live_123", "+77010000000", "password", "special_note"} {
+		assert.NotContains(t, string(stored), secret)
+	}
+	assert.Equal(t, map[string]interface{}{"guests_count": float64(2)}, sample.Body)
+}
+
+func TestRequestSampler_SuccessRate(t *testing.T) {
+	samples := &stubRequestSampleService{}
+	sampler := NewRequestSampler(samples, 0.01, 1)
+
+	sampler.roll = func() float64 { return 0.5 }
+	performSampled(sampler, nil, http.StatusOK, `{}`)
+	assert.Empty(t, samples.recorded)
+
+	sampler.roll = func() float64 { return 0.005 }
+	performSampled(sampler, nil, http.StatusOK, `{}`)
+	require.Len(t, samples.recorded, 1)
+	assert.Nil(t, samples.recorded[0].UserID)
+	assert.Empty(t, samples.recorded[0].Error)
+}
+
+func TestRequestSampler_ZeroRateNeverSamples(t *testing.T) {
+	samples := &stubRequestSampleService{}
+	sampler := NewRequestSampler(samples, 0, 0)
+	sampler.roll = func() float64 { return 0 }
+
+	performSampled(sampler, nil, http.StatusInternalServerError, `{}`)
+	performSampled(sampler, nil, http.StatusCreated, `{}`)
+
+	assert.Empty(t, samples.recorded)
+}
@@ -3,23 +3,24 @@ package middleware
 import (
 	"net/http"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/handler"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RequireRole allows the request through only if Authenticate has put one of
+// the given roles into the context. It must be registered after Authenticate.
 func RequireRole(roles ...domain.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handler.ErrorResponse{Error: "Unauthorized"})
 			return
 		}
 
 		role, ok := userRole.(domain.UserRole)
 		if !ok {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid role"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusForbidden, handler.ErrorResponse{Error: "Invalid role"})
 			return
 		}
 
@@ -30,7 +31,6 @@ func RequireRole(roles ...domain.UserRole) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
-		c.Abort()
+		c.AbortWithStatusJSON(http.StatusForbidden, handler.ErrorResponse{Error: "Insufficient permissions"})
 	}
 }
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func performWithRole(role interface{}, roles ...domain.UserRole) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", func(c *gin.Context) {
+		if role != nil {
+			c.Set("user_role", role)
+		}
+		c.Next()
+	}, RequireRole(roles...), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/protected", nil))
+	return w
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	w := performWithRole(domain.UserRoleOwner, domain.UserRoleOwner, domain.UserRoleAdmin)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireRole_Forbidden(t *testing.T) {
+	w := performWithRole(domain.UserRoleCustomer, domain.UserRoleOwner, domain.UserRoleAdmin)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error":"Insufficient permissions"}`, w.Body.String())
+}
+
+func TestRequireRole_NoClaims(t *testing.T) {
+	w := performWithRole(nil, domain.UserRoleOwner)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Unauthorized"}`, w.Body.String())
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedStaffAction struct {
+	sessionUserID uuid.UUID
+	attribution   service.StaffAttribution
+	route         string
+	status        int
+}
+
+type stubStaffPinService struct {
+	service.StaffPinService
+	staffID uuid.UUID
+	err     error
+	actions []recordedStaffAction
+}
+
+func (s *stubStaffPinService) Resolve(ctx context.Context, device *domain.APIKey, pin string) (uuid.UUID, error) {
+	return s.staffID, s.err
+}
+
+func (s *stubStaffPinService) RecordAction(ctx context.Context, sessionUserID uuid.UUID, device *domain.APIKey, method, route string, status int) {
+	attribution, _ := service.StaffAttributionFromContext(ctx)
+	s.actions = append(s.actions, recordedStaffAction{sessionUserID: sessionUserID, attribution: attribution, route: route, status: status})
+}
+
+func performFromSharedDevice(pins service.StaffPinService, device *domain.APIKey, method, pin string, sessionUserID uuid.UUID) (*httptest.ResponseRecorder, *service.StaffAttribution) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var seen *service.StaffAttribution
+	router.Handle(method, "/api/bookings/:id/status", NewStaffPinMiddleware(&stubAPIKeyService{key: device}, pins).Attribute(), func(c *gin.Context) {
+		c.Set("user_id", sessionUserID)
+		if attribution, ok := service.StaffAttributionFromContext(c.Request.Context()); ok {
+			seen = &attribution
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(method, "/api/bookings/42/status", nil)
+	req.Header.Set("Authorization", "Bearer shared-session")
+	req.Header.Set(SharedDeviceHeader, "rbk_valid")
+	if pin != "" {
+		req.Header.Set(StaffPinHeader, pin)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, seen
+}
+
+func TestStaffPinMiddleware_AttributesAction(t *testing.T) {
+	device := &domain.APIKey{ID: uuid.New(), RestaurantID: uuid.New()}
+	pins := &stubStaffPinService{staffID: uuid.New()}
+	sessionUserID := uuid.New()
+
+	w, seen := performFromSharedDevice(pins, device, http.MethodPatch, "4821", sessionUserID)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, seen)
+	assert.Equal(t, service.StaffAttribution{StaffID: pins.staffID, DeviceID: device.ID}, *seen)
+	require.Len(t, pins.actions, 1)
+	assert.Equal(t, sessionUserID, pins.actions[0].sessionUserID)
+	assert.Equal(t, pins.staffID, pins.actions[0].attribution.StaffID)
+	assert.Equal(t, "/api/bookings/:id/status", pins.actions[0].route)
+	assert.Equal(t, http.StatusOK, pins.actions[0].status)
+}
+
+func TestStaffPinMiddleware_RejectsMissingOrLockedPin(t *testing.T) {
+	device := &domain.APIKey{ID: uuid.New(), RestaurantID: uuid.New()}
+
+	pins := &stubStaffPinService{staffID: uuid.New()}
+	w, _ := performFromSharedDevice(pins, device, http.MethodPatch, "", uuid.New())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	pins = &stubStaffPinService{err: service.ErrInvalidStaffPin}
+	w, _ = performFromSharedDevice(pins, device, http.MethodPatch, "0000", uuid.New())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	pins = &stubStaffPinService{err: service.ErrStaffPinLocked}
+	w, _ = performFromSharedDevice(pins, device, http.MethodPatch, "0000", uuid.New())
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, pins.actions)
+}
+
+func TestStaffPinMiddleware_ReadsPassThrough(t *testing.T) {
+	device := &domain.APIKey{ID: uuid.New(), RestaurantID: uuid.New()}
+	pins := &stubStaffPinService{err: service.ErrInvalidStaffPin}
+
+	w, seen := performFromSharedDevice(pins, device, http.MethodGet, "", uuid.New())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, seen)
+	assert.Empty(t, pins.actions)
+}
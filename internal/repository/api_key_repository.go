@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	// GetActiveByHash returns the unrevoked key with the given hash.
+	GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*domain.APIKey, error)
+	// Revoke returns gorm.ErrRecordNotFound when the restaurant has no
+	// unrevoked key with that ID.
+	Revoke(ctx context.Context, restaurantID, id uuid.UUID, at time.Time) error
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("key_hash = ? AND revoked_at IS NULL", keyHash).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ?", restaurantID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, restaurantID, id uuid.UUID, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND restaurant_id = ? AND revoked_at IS NULL", id, restaurantID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*domain.AuditLog, int64, error)
+}
+
+// AuditFilter narrows List. Nil fields and an empty Action match everything;
+// From is inclusive and To exclusive.
+type AuditFilter struct {
+	ActorID *uuid.UUID
+	Action  string
+	From    *time.Time
+	To      *time.Time
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *auditRepository) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*domain.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*domain.AuditLog
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var ErrBackgroundJobRunning = errors.New("a job of this kind is already running")
+
+const runningJobKindIndex = "idx_background_jobs_running_kind"
+
+type BackgroundJobRepository interface {
+	// Create fails with ErrBackgroundJobRunning when a job of the same kind
+	// is still running.
+	Create(ctx context.Context, job *domain.BackgroundJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error)
+	Update(ctx context.Context, job *domain.BackgroundJob) error
+	// ListRunning returns the jobs still marked running, oldest first.
+	ListRunning(ctx context.Context) ([]*domain.BackgroundJob, error)
+}
+
+type backgroundJobRepository struct {
+	db *gorm.DB
+}
+
+func NewBackgroundJobRepository(db *gorm.DB) BackgroundJobRepository {
+	return &backgroundJobRepository{db: db}
+}
+
+func (r *backgroundJobRepository) Create(ctx context.Context, job *domain.BackgroundJob) error {
+	err := r.db.WithContext(ctx).Create(job).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == runningJobKindIndex {
+		return ErrBackgroundJobRunning
+	}
+	return err
+}
+
+func (r *backgroundJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error) {
+	var job domain.BackgroundJob
+	if err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *backgroundJobRepository) Update(ctx context.Context, job *domain.BackgroundJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+func (r *backgroundJobRepository) ListRunning(ctx context.Context) ([]*domain.BackgroundJob, error) {
+	var jobs []*domain.BackgroundJob
+	err := r.db.WithContext(ctx).
+		Where("status = ?", domain.BackgroundJobRunning).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
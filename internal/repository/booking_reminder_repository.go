@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookingReminderRepository interface {
+	// GetDue returns up to limit confirmed bookings that start in
+	// (from, to] and have no reminder of leadMinutes yet, with their
+	// restaurants and users, soonest first.
+	GetDue(ctx context.Context, leadMinutes int, from, to time.Time, limit int) ([]*domain.Booking, error)
+	// Claim records the booking's reminder of leadMinutes as sent at now
+	// unless it already is, and reports whether it did. Of two concurrent
+	// calls only one succeeds.
+	Claim(ctx context.Context, bookingID uuid.UUID, leadMinutes int, now time.Time) (bool, error)
+}
+
+type bookingReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingReminderRepository(db *gorm.DB) BookingReminderRepository {
+	return &bookingReminderRepository{db: db}
+}
+
+func (r *bookingReminderRepository) GetDue(ctx context.Context, leadMinutes int, from, to time.Time, limit int) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Preload("Restaurant").
+		Preload("User").
+		Where("status = ? AND start_time > ? AND start_time <= ?", domain.BookingStatusConfirmed, from, to).
+		Where("NOT EXISTS (SELECT 1 FROM booking_reminders WHERE booking_reminders.booking_id = bookings.id AND booking_reminders.lead_minutes = ?)", leadMinutes).
+		Order("start_time, id").
+		Limit(limit).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingReminderRepository) Claim(ctx context.Context, bookingID uuid.UUID, leadMinutes int, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.BookingReminder{BookingID: bookingID, LeadMinutes: leadMinutes, SentAt: now})
+	return result.RowsAffected == 1, result.Error
+}
@@ -2,21 +2,110 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"restaurant-booking/internal/domain"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrGuestLimitReached is returned by CreateIfAvailable and
+// UpdateIfAvailable when the booking would take the restaurant past its
+// MaxConcurrentGuests.
+var ErrGuestLimitReached = errors.New("restaurant cannot seat that many guests at once")
+
 type BookingRepository interface {
 	Create(ctx context.Context, booking *domain.Booking) error
+	// CreateIfAvailable creates the booking unless another booking holds
+	// its table at some point of it, and reports whether it did. The table
+	// is locked while it checks, so of two concurrent bookings of the same
+	// slot only one is created. If the restaurant has a MaxConcurrentGuests,
+	// the guests of its overlapping bookings are counted in the same
+	// transaction and ErrGuestLimitReached returned if the booking would
+	// exceed it.
+	CreateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error)
+	// UpdateIfAvailable saves the booking's new terms the way
+	// CreateIfAvailable creates one, leaving the booking itself out of the
+	// table and guest checks, and reports whether it saved them.
+	UpdateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Booking, error)
+	// GetByUserID returns a page of the user's bookings matching filter.
+	// Upcoming bookings come soonest first, the others latest first.
+	GetByUserID(ctx context.Context, userID uuid.UUID, filter UserBookingFilter, limit, offset int) ([]*domain.Booking, error)
+	// GetCalendarByUser returns the user's pending, confirmed and cancelled
+	// bookings that end after from, with their restaurants and tables,
+	// soonest first.
+	GetCalendarByUser(ctx context.Context, userID uuid.UUID, from time.Time) ([]*domain.Booking, error)
 	GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID, date time.Time) ([]*domain.Booking, error)
+	// GetByRestaurantIDRange returns a page of the restaurant's bookings
+	// that start in [from, to), with their tables, earliest first. A nil
+	// status leaves out cancelled bookings.
+	GetByRestaurantIDRange(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, status *domain.BookingStatus, limit, offset int) ([]*domain.Booking, error)
 	Update(ctx context.Context, booking *domain.Booking) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	CheckTableAvailability(ctx context.Context, tableID uuid.UUID, startTime, endTime time.Time) (bool, error)
+	// CheckTableAvailabilityExcept is CheckTableAvailability leaving out the
+	// booking bookingID, so a booking can be checked against the others
+	// when it changes.
+	CheckTableAvailabilityExcept(ctx context.Context, tableID uuid.UUID, startTime, endTime time.Time, bookingID uuid.UUID) (bool, error)
+	GetOverlapping(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error)
+	// GetOverlappingByTable returns the table's bookings that hold it at some
+	// point between from and to, earliest first.
+	GetOverlappingByTable(ctx context.Context, tableID uuid.UUID, from, to time.Time) ([]*domain.Booking, error)
+	// GetUpcomingByTable returns the table's bookings that are not cancelled
+	// or completed and end after from, with their users, soonest first.
+	GetUpcomingByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.Booking, error)
+	// CancelPendingByUser cancels the user's pending bookings that start
+	// after from and returns how many were cancelled.
+	CancelPendingByUser(ctx context.Context, userID uuid.UUID, from time.Time) (int64, error)
+	// GetStalePending returns up to limit bookings that are still pending
+	// and were created before createdBefore, with their restaurants and
+	// users, oldest first.
+	GetStalePending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Booking, error)
+	// ExpirePending cancels the booking with note if it is still pending and
+	// reports whether it did.
+	ExpirePending(ctx context.Context, id uuid.UUID, note string) (bool, error)
+	// ConfirmPending confirms the booking if it is still pending and
+	// reports whether it did.
+	ConfirmPending(ctx context.Context, id uuid.UUID) (bool, error)
+	// MarkNoShow sets the no-show status if the booking is pending or
+	// confirmed and started before now, and reports whether it did.
+	MarkNoShow(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+	// CountNoShowsByUser counts the user's bookings marked a no-show.
+	CountNoShowsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// ClaimGuestBookings links the guest bookings made for phone to the
+	// user and returns how many it linked.
+	ClaimGuestBookings(ctx context.Context, phone string, userID uuid.UUID) (int64, error)
+	// GetHistory returns the restaurant's confirmed and completed bookings
+	// that start in [from, to).
+	GetHistory(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error)
+	// CountByStatus counts the restaurant's bookings that start in
+	// [from, to), by status.
+	CountByStatus(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (map[domain.BookingStatus]int64, error)
+	// AverageGuestCount is the mean party size of the restaurant's bookings
+	// that start in [from, to), leaving out cancelled ones. It is 0 when
+	// there are none.
+	AverageGuestCount(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (float64, error)
+	// CountWithStatus counts the restaurant's bookings with one of
+	// statuses, or all of them when statuses is empty.
+	CountWithStatus(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus) (int64, error)
+	// CountWithStatusByDay is CountWithStatus for the bookings that start
+	// in [from, to), keyed by the day they start in zone as "2006-01-02".
+	// Days without bookings are left out.
+	CountWithStatusByDay(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus, from, to time.Time, zone string) (map[string]int64, error)
+	WithTx(tx *gorm.DB) BookingRepository
+}
+
+// UserBookingFilter narrows GetByUserID. A nil Status matches every status.
+// Upcoming splits the bookings at Now: true keeps those that start after it,
+// false those that started at or before it, and nil keeps both.
+type UserBookingFilter struct {
+	Status   *domain.BookingStatus
+	Upcoming *bool
+	Now      time.Time
 }
 
 type bookingRepository struct {
@@ -27,10 +116,105 @@ func NewBookingRepository(db *gorm.DB) BookingRepository {
 	return &bookingRepository{db: db}
 }
 
+func (r *bookingRepository) WithTx(tx *gorm.DB) BookingRepository {
+	return &bookingRepository{db: tx}
+}
+
 func (r *bookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
 	return r.db.WithContext(ctx).Create(booking).Error
 }
 
+func (r *bookingRepository) CreateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTable(tx, booking.TableID); err != nil {
+			return err
+		}
+
+		available, err := r.WithTx(tx).CheckTableAvailability(ctx, booking.TableID, booking.StartTime, booking.EndTime)
+		if err != nil || !available {
+			return err
+		}
+		if err := checkGuestLimit(tx, booking); err != nil {
+			return err
+		}
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *bookingRepository) UpdateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error) {
+	updated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTable(tx, booking.TableID); err != nil {
+			return err
+		}
+
+		available, err := r.WithTx(tx).CheckTableAvailabilityExcept(ctx, booking.TableID, booking.StartTime, booking.EndTime, booking.ID)
+		if err != nil || !available {
+			return err
+		}
+		if err := checkGuestLimit(tx, booking); err != nil {
+			return err
+		}
+		if err := tx.Save(booking).Error; err != nil {
+			return err
+		}
+		updated = true
+		return nil
+	})
+	return updated, err
+}
+
+// lockTable locks the table's row until the transaction ends, so that
+// bookings of it are checked and written one at a time.
+func lockTable(tx *gorm.DB, tableID uuid.UUID) error {
+	var table domain.Table
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&table, "id = ?", tableID).Error
+}
+
+// checkGuestLimit returns ErrGuestLimitReached if booking would take its
+// restaurant past MaxConcurrentGuests. The booking's own saved guests are
+// left out, since a modification replaces them. Bookings of different
+// tables do not share a row lock, so the restaurant is locked while the
+// guests are summed.
+func checkGuestLimit(tx *gorm.DB, booking *domain.Booking) error {
+	var restaurant domain.Restaurant
+	err := tx.Select("id", "max_concurrent_guests").
+		First(&restaurant, "id = ?", booking.RestaurantID).Error
+	if err != nil || restaurant.MaxConcurrentGuests == 0 {
+		return err
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "booking_guests:"+booking.RestaurantID.String()).Error; err != nil {
+		return err
+	}
+
+	var booked int64
+	err = tx.Model(&domain.Booking{}).
+		Select("COALESCE(SUM(guests_count), 0)").
+		Where("restaurant_id = ? AND id <> ? AND status NOT IN (?, ?) AND start_time < ? AND end_time > ?",
+			booking.RestaurantID,
+			booking.ID,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			booking.EndTime, booking.StartTime,
+		).
+		Scan(&booked).Error
+	if err != nil {
+		return err
+	}
+	if int(booked)+booking.GuestsCount > restaurant.MaxConcurrentGuests {
+		return fmt.Errorf("%w: %d of %d guests already booked for that time", ErrGuestLimitReached, booked, restaurant.MaxConcurrentGuests)
+	}
+	return nil
+}
+
 func (r *bookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error) {
 	var booking domain.Booking
 	err := r.db.WithContext(ctx).
@@ -44,11 +228,42 @@ func (r *bookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	return &booking, nil
 }
 
-func (r *bookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Booking, error) {
+func (r *bookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter UserBookingFilter, limit, offset int) ([]*domain.Booking, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if filter.Upcoming != nil && *filter.Upcoming {
+		query = query.Where("start_time > ?", filter.Now).Order("start_time, id")
+	} else {
+		if filter.Upcoming != nil {
+			query = query.Where("start_time <= ?", filter.Now)
+		}
+		query = query.Order("start_time DESC, id")
+	}
+
+	var bookings []*domain.Booking
+	err := query.
+		Limit(limit).
+		Offset(offset).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) GetCalendarByUser(ctx context.Context, userID uuid.UUID, from time.Time) ([]*domain.Booking, error) {
 	var bookings []*domain.Booking
 	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("booking_date DESC, start_time DESC").
+		Preload("Restaurant").
+		Preload("Table").
+		Where("user_id = ? AND status IN (?, ?, ?) AND end_time > ?",
+			userID,
+			domain.BookingStatusPending,
+			domain.BookingStatusConfirmed,
+			domain.BookingStatusCancelled,
+			from,
+		).
+		Order("start_time, id").
 		Find(&bookings).Error
 	return bookings, err
 }
@@ -62,6 +277,25 @@ func (r *bookingRepository) GetByRestaurantID(ctx context.Context, restaurantID
 	return bookings, err
 }
 
+func (r *bookingRepository) GetByRestaurantIDRange(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, status *domain.BookingStatus, limit, offset int) ([]*domain.Booking, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Table").
+		Where("restaurant_id = ? AND start_time >= ? AND start_time < ?", restaurantID, from, to)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	} else {
+		query = query.Where("status != ?", domain.BookingStatusCancelled)
+	}
+
+	var bookings []*domain.Booking
+	err := query.
+		Order("start_time, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&bookings).Error
+	return bookings, err
+}
+
 func (r *bookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
 	return r.db.WithContext(ctx).Save(booking).Error
 }
@@ -85,3 +319,210 @@ func (r *bookingRepository) CheckTableAvailability(ctx context.Context, tableID
 
 	return count == 0, err
 }
+
+func (r *bookingRepository) CheckTableAvailabilityExcept(ctx context.Context, tableID uuid.UUID, startTime, endTime time.Time, bookingID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("table_id = ? AND id <> ? AND status NOT IN (?, ?) AND start_time < ? AND end_time > ?",
+			tableID,
+			bookingID,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			endTime, startTime,
+		).
+		Count(&count).Error
+
+	return count == 0, err
+}
+
+// CancelPendingByUser cancels the user's pending bookings that start after
+// from in one statement, so bookings confirmed meanwhile are left alone.
+func (r *bookingRepository) CancelPendingByUser(ctx context.Context, userID uuid.UUID, from time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("user_id = ? AND status = ? AND start_time > ?", userID, domain.BookingStatusPending, from).
+		Update("status", domain.BookingStatusCancelled)
+	return result.RowsAffected, result.Error
+}
+
+func (r *bookingRepository) GetStalePending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Preload("Restaurant").
+		Preload("User").
+		Where("status = ? AND created_at < ?", domain.BookingStatusPending, createdBefore).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) ExpirePending(ctx context.Context, id uuid.UUID, note string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("id = ? AND status = ?", id, domain.BookingStatusPending).
+		Updates(map[string]interface{}{
+			"status":            domain.BookingStatusCancelled,
+			"cancellation_note": note,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *bookingRepository) ConfirmPending(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("id = ? AND status = ?", id, domain.BookingStatusPending).
+		Update("status", domain.BookingStatusConfirmed)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *bookingRepository) MarkNoShow(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("id = ? AND status IN (?, ?) AND start_time < ?", id, domain.BookingStatusPending, domain.BookingStatusConfirmed, now).
+		Update("status", domain.BookingStatusNoShow)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *bookingRepository) CountNoShowsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("user_id = ? AND status = ?", userID, domain.BookingStatusNoShow).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *bookingRepository) ClaimGuestBookings(ctx context.Context, phone string, userID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("is_guest AND guest_phone = ?", phone).
+		Updates(map[string]interface{}{"user_id": userID, "is_guest": false})
+	return result.RowsAffected, result.Error
+}
+
+// GetOverlapping returns the bookings of a restaurant that still hold their
+// table at some point between from and to.
+func (r *bookingRepository) GetOverlapping(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ? AND status NOT IN (?, ?) AND start_time < ? AND end_time > ?",
+			restaurantID,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			to, from,
+		).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) GetOverlappingByTable(ctx context.Context, tableID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Where("table_id = ? AND status NOT IN (?, ?) AND start_time < ? AND end_time > ?",
+			tableID,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			to, from,
+		).
+		Order("start_time ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) GetUpcomingByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("table_id = ? AND status NOT IN (?, ?) AND end_time > ?",
+			tableID,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			from,
+		).
+		Order("start_time ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) GetHistory(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ? AND status IN (?, ?) AND start_time >= ? AND start_time < ?",
+			restaurantID,
+			domain.BookingStatusConfirmed,
+			domain.BookingStatusCompleted,
+			from, to,
+		).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) CountByStatus(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (map[domain.BookingStatus]int64, error) {
+	var rows []struct {
+		Status domain.BookingStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Select("status, COUNT(*) AS count").
+		Where("restaurant_id = ? AND start_time >= ? AND start_time < ?", restaurantID, from, to).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.BookingStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *bookingRepository) AverageGuestCount(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (float64, error) {
+	var average float64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Select("COALESCE(AVG(guests_count), 0)").
+		Where("restaurant_id = ? AND status != ? AND start_time >= ? AND start_time < ?",
+			restaurantID, domain.BookingStatusCancelled, from, to).
+		Scan(&average).Error
+	return average, err
+}
+
+func (r *bookingRepository) CountWithStatus(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus) (int64, error) {
+	var count int64
+	query := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("restaurant_id = ?", restaurantID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *bookingRepository) CountWithStatusByDay(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus, from, to time.Time, zone string) (map[string]int64, error) {
+	var rows []struct {
+		Day   string
+		Count int64
+	}
+	query := r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Select("TO_CHAR(start_time AT TIME ZONE ?, 'YYYY-MM-DD') AS day, COUNT(*) AS count", zone).
+		Where("restaurant_id = ? AND start_time >= ? AND start_time < ?", restaurantID, from, to)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	if err := query.Group("day").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BookingRescheduleTokenRepository interface {
+	Create(ctx context.Context, token *domain.BookingRescheduleToken) error
+	GetByToken(ctx context.Context, token string) (*domain.BookingRescheduleToken, error)
+	// Use marks the token used at now unless it already is, and reports
+	// whether it did. Of two concurrent calls only one succeeds.
+	Use(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+	// UseAllForBooking marks every unused token of the booking used at now.
+	UseAllForBooking(ctx context.Context, bookingID uuid.UUID, now time.Time) error
+	WithTx(tx *gorm.DB) BookingRescheduleTokenRepository
+}
+
+type bookingRescheduleTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingRescheduleTokenRepository(db *gorm.DB) BookingRescheduleTokenRepository {
+	return &bookingRescheduleTokenRepository{db: db}
+}
+
+func (r *bookingRescheduleTokenRepository) WithTx(tx *gorm.DB) BookingRescheduleTokenRepository {
+	return &bookingRescheduleTokenRepository{db: tx}
+}
+
+func (r *bookingRescheduleTokenRepository) Create(ctx context.Context, token *domain.BookingRescheduleToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *bookingRescheduleTokenRepository) GetByToken(ctx context.Context, token string) (*domain.BookingRescheduleToken, error) {
+	var found domain.BookingRescheduleToken
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&found).Error; err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (r *bookingRescheduleTokenRepository) Use(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.BookingRescheduleToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *bookingRescheduleTokenRepository) UseAllForBooking(ctx context.Context, bookingID uuid.UUID, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.BookingRescheduleToken{}).
+		Where("booking_id = ? AND used_at IS NULL", bookingID).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BusynessRepository interface {
+	// Upsert replaces the stored busyness of the restaurant.
+	Upsert(ctx context.Context, busyness *domain.RestaurantBusyness) error
+	GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantBusyness, error)
+}
+
+type busynessRepository struct {
+	db *gorm.DB
+}
+
+func NewBusynessRepository(db *gorm.DB) BusynessRepository {
+	return &busynessRepository{db: db}
+}
+
+func (r *busynessRepository) Upsert(ctx context.Context, busyness *domain.RestaurantBusyness) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "restaurant_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"occupancy", "booking_count", "computed_at"}),
+		}).
+		Create(busyness).Error
+}
+
+func (r *busynessRepository) GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantBusyness, error) {
+	var busyness domain.RestaurantBusyness
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ?", restaurantID).
+		First(&busyness).Error
+	if err != nil {
+		return nil, err
+	}
+	return &busyness, nil
+}
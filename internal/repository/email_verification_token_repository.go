@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationTokenRepository interface {
+	Create(token *domain.EmailVerificationToken) error
+	GetByToken(token string) (*domain.EmailVerificationToken, error)
+	GetLatestByUserID(userID uuid.UUID) (*domain.EmailVerificationToken, error)
+	DeleteAllByUserID(userID uuid.UUID) error
+}
+
+type emailVerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationTokenRepository(db *gorm.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{db: db}
+}
+
+func (r *emailVerificationTokenRepository) Create(token *domain.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *emailVerificationTokenRepository) GetByToken(token string) (*domain.EmailVerificationToken, error) {
+	var verificationToken domain.EmailVerificationToken
+	if err := r.db.Where("token = ?", token).First(&verificationToken).Error; err != nil {
+		return nil, err
+	}
+	return &verificationToken, nil
+}
+
+func (r *emailVerificationTokenRepository) GetLatestByUserID(userID uuid.UUID) (*domain.EmailVerificationToken, error) {
+	var verificationToken domain.EmailVerificationToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&verificationToken).Error; err != nil {
+		return nil, err
+	}
+	return &verificationToken, nil
+}
+
+func (r *emailVerificationTokenRepository) DeleteAllByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.EmailVerificationToken{}).Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FavoriteRepository interface {
+	// Add keeps the existing favorite when the user already has one for
+	// the restaurant.
+	Add(ctx context.Context, favorite *domain.Favorite) error
+	Remove(ctx context.Context, userID, restaurantID uuid.UUID) error
+	Exists(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error)
+	// ListByUser returns the user's favorites of active restaurants, newest
+	// first, with the restaurant preloaded.
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Favorite, error)
+}
+
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+func (r *favoriteRepository) Add(ctx context.Context, favorite *domain.Favorite) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(favorite).Error
+}
+
+func (r *favoriteRepository) Remove(ctx context.Context, userID, restaurantID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND restaurant_id = ?", userID, restaurantID).
+		Delete(&domain.Favorite{}).Error
+}
+
+func (r *favoriteRepository) Exists(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Favorite{}).
+		Where("user_id = ? AND restaurant_id = ?", userID, restaurantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *favoriteRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Favorite, error) {
+	var favorites []*domain.Favorite
+	err := r.db.WithContext(ctx).
+		Joins("JOIN restaurants ON restaurants.id = favorites.restaurant_id AND restaurants.is_active = ?", true).
+		Where("favorites.user_id = ?", userID).
+		Preload("Restaurant").
+		Order("favorites.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&favorites).Error
+	return favorites, err
+}
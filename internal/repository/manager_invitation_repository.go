@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var ErrDuplicatePendingInvitation = errors.New("a pending invitation already exists for this email")
+
+const uniquePendingInvitationIndex = "idx_manager_invitations_pending_email"
+
+type ManagerInvitationRepository interface {
+	Create(ctx context.Context, invitation *domain.ManagerInvitation) error
+	GetByToken(ctx context.Context, token string) (*domain.ManagerInvitation, error)
+	// GetPending returns the pending invitation of email to the restaurant,
+	// expired or not.
+	GetPending(ctx context.Context, restaurantID uuid.UUID, email string) (*domain.ManagerInvitation, error)
+	ListPendingByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.ManagerInvitation, error)
+	Update(ctx context.Context, invitation *domain.ManagerInvitation) error
+}
+
+type managerInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewManagerInvitationRepository(db *gorm.DB) ManagerInvitationRepository {
+	return &managerInvitationRepository{db: db}
+}
+
+func (r *managerInvitationRepository) Create(ctx context.Context, invitation *domain.ManagerInvitation) error {
+	return translateInvitationError(r.db.WithContext(ctx).Create(invitation).Error)
+}
+
+func (r *managerInvitationRepository) GetByToken(ctx context.Context, token string) (*domain.ManagerInvitation, error) {
+	var invitation domain.ManagerInvitation
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *managerInvitationRepository) GetPending(ctx context.Context, restaurantID uuid.UUID, email string) (*domain.ManagerInvitation, error) {
+	var invitation domain.ManagerInvitation
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ? AND email = ? AND status = ?", restaurantID, email, domain.InvitationStatusPending).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *managerInvitationRepository) ListPendingByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.ManagerInvitation, error) {
+	var invitations []*domain.ManagerInvitation
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ? AND status = ?", restaurantID, domain.InvitationStatusPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *managerInvitationRepository) Update(ctx context.Context, invitation *domain.ManagerInvitation) error {
+	return r.db.WithContext(ctx).Save(invitation).Error
+}
+
+func translateInvitationError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == uniquePendingInvitationIndex {
+		return ErrDuplicatePendingInvitation
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MenuRepository interface {
+	// LockMenu takes a transaction-scoped advisory lock on the restaurant's
+	// menu so concurrent changes do not hand out the same position twice.
+	// It must run inside a transaction.
+	LockMenu(ctx context.Context, restaurantID uuid.UUID) error
+	// ListCategories returns the restaurant's categories with their items,
+	// both in menu order.
+	ListCategories(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error)
+	GetCategory(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuCategory, error)
+	CreateCategory(ctx context.Context, category *domain.MenuCategory) error
+	UpdateCategory(ctx context.Context, category *domain.MenuCategory) error
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	// NextCategoryPosition returns the position after the restaurant's last
+	// category.
+	NextCategoryPosition(ctx context.Context, restaurantID uuid.UUID) (int, error)
+	// SetCategoryPositions gives each category the position of its ID in ids.
+	SetCategoryPositions(ctx context.Context, ids []uuid.UUID) error
+
+	ListItems(ctx context.Context, categoryID uuid.UUID) ([]*domain.MenuItem, error)
+	CountItems(ctx context.Context, categoryID uuid.UUID) (int64, error)
+	GetItem(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuItem, error)
+	CreateItem(ctx context.Context, item *domain.MenuItem) error
+	UpdateItem(ctx context.Context, item *domain.MenuItem) error
+	DeleteItem(ctx context.Context, id uuid.UUID) error
+	// NextItemPosition returns the position after the category's last item.
+	NextItemPosition(ctx context.Context, categoryID uuid.UUID) (int, error)
+	// SetItemPositions gives each item the position of its ID in ids.
+	SetItemPositions(ctx context.Context, ids []uuid.UUID) error
+
+	WithTx(tx *gorm.DB) MenuRepository
+}
+
+type menuRepository struct {
+	db *gorm.DB
+}
+
+func NewMenuRepository(db *gorm.DB) MenuRepository {
+	return &menuRepository{db: db}
+}
+
+func (r *menuRepository) WithTx(tx *gorm.DB) MenuRepository {
+	return &menuRepository{db: tx}
+}
+
+func (r *menuRepository) LockMenu(ctx context.Context, restaurantID uuid.UUID) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "menu:"+restaurantID.String()).Error
+}
+
+func (r *menuRepository) ListCategories(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error) {
+	var categories []*domain.MenuCategory
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, created_at ASC")
+		}).
+		Where("restaurant_id = ?", restaurantID).
+		Order("position ASC, created_at ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+func (r *menuRepository) GetCategory(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuCategory, error) {
+	var category domain.MenuCategory
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND restaurant_id = ?", id, restaurantID).
+		First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *menuRepository) CreateCategory(ctx context.Context, category *domain.MenuCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *menuRepository) UpdateCategory(ctx context.Context, category *domain.MenuCategory) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(category).Error
+}
+
+func (r *menuRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.MenuCategory{}, "id = ?", id).Error
+}
+
+func (r *menuRepository) NextCategoryPosition(ctx context.Context, restaurantID uuid.UUID) (int, error) {
+	var last int
+	err := r.db.WithContext(ctx).
+		Model(&domain.MenuCategory{}).
+		Where("restaurant_id = ?", restaurantID).
+		Select("COALESCE(MAX(position), -1)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+func (r *menuRepository) SetCategoryPositions(ctx context.Context, ids []uuid.UUID) error {
+	for position, id := range ids {
+		if err := r.db.WithContext(ctx).
+			Model(&domain.MenuCategory{}).
+			Where("id = ?", id).
+			Update("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *menuRepository) ListItems(ctx context.Context, categoryID uuid.UUID) ([]*domain.MenuItem, error) {
+	var items []*domain.MenuItem
+	err := r.db.WithContext(ctx).
+		Where("category_id = ?", categoryID).
+		Order("position ASC, created_at ASC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *menuRepository) CountItems(ctx context.Context, categoryID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.MenuItem{}).
+		Where("category_id = ?", categoryID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *menuRepository) GetItem(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuItem, error) {
+	var item domain.MenuItem
+	if err := r.db.WithContext(ctx).
+		Where("id = ? AND restaurant_id = ?", id, restaurantID).
+		First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *menuRepository) CreateItem(ctx context.Context, item *domain.MenuItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *menuRepository) UpdateItem(ctx context.Context, item *domain.MenuItem) error {
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+func (r *menuRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.MenuItem{}, "id = ?", id).Error
+}
+
+func (r *menuRepository) NextItemPosition(ctx context.Context, categoryID uuid.UUID) (int, error) {
+	var last int
+	err := r.db.WithContext(ctx).
+		Model(&domain.MenuItem{}).
+		Where("category_id = ?", categoryID).
+		Select("COALESCE(MAX(position), -1)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+func (r *menuRepository) SetItemPositions(ctx context.Context, ids []uuid.UUID) error {
+	for position, id := range ids {
+		if err := r.db.WithContext(ctx).
+			Model(&domain.MenuItem{}).
+			Where("id = ?", id).
+			Update("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
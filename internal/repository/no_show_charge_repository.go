@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type NoShowChargeRepository interface {
+	Create(ctx context.Context, charge *domain.NoShowCharge) error
+	WithTx(tx *gorm.DB) NoShowChargeRepository
+}
+
+type noShowChargeRepository struct {
+	db *gorm.DB
+}
+
+func NewNoShowChargeRepository(db *gorm.DB) NoShowChargeRepository {
+	return &noShowChargeRepository{db: db}
+}
+
+func (r *noShowChargeRepository) WithTx(tx *gorm.DB) NoShowChargeRepository {
+	return &noShowChargeRepository{db: tx}
+}
+
+func (r *noShowChargeRepository) Create(ctx context.Context, charge *domain.NoShowCharge) error {
+	return r.db.WithContext(ctx).Create(charge).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrderArgumentsAreSafe keeps ORDER BY out of reach of request input:
+// every Order call in the repositories takes a string literal or a
+// sqlsafe.Sort clause resolved against an allow-list.
+func TestOrderArgumentsAreSafe(t *testing.T) {
+	entries, err := os.ReadDir(".")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != "Order" || len(call.Args) == 0 {
+				return true
+			}
+			if !safeOrderArgument(call.Args[0]) {
+				t.Errorf("%s: Order takes a non-constant argument; resolve it with sqlsafe.ResolveSort", fset.Position(call.Pos()))
+			}
+			return true
+		})
+	}
+}
+
+func safeOrderArgument(arg ast.Expr) bool {
+	switch arg := arg.(type) {
+	case *ast.BasicLit:
+		return arg.Kind == token.STRING
+	case *ast.CallExpr:
+		selector, ok := arg.Fun.(*ast.SelectorExpr)
+		return ok && selector.Sel.Name == "Clause" && len(arg.Args) == 0
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"restaurant-booking/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,8 +13,43 @@ type PaymentRepository interface {
 	Create(ctx context.Context, payment *domain.Payment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error)
 	GetByExternalID(ctx context.Context, externalID string) (*domain.Payment, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Payment, error)
+	List(ctx context.Context, filter PaymentFilter, limit, offset int) ([]*domain.Payment, error)
+	Summarize(ctx context.Context, filter PaymentFilter) (*PaymentSummary, error)
 	Update(ctx context.Context, payment *domain.Payment) error
+	// ReassignBooking moves the completed payments of one booking to another
+	// and returns how many were moved.
+	ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int64, error)
+	// VoidPendingByBooking voids the booking's pending payments and returns
+	// how many were voided.
+	VoidPendingByBooking(ctx context.Context, bookingID uuid.UUID) (int64, error)
+	// RestaurantRevenue sums the completed payments made in [from, to) for
+	// the restaurant's bookings. Service fees are platform revenue and are
+	// left out.
+	RestaurantRevenue(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (int64, error)
+	WithTx(tx *gorm.DB) PaymentRepository
+}
+
+// PaymentFilter narrows List and Summarize. Nil fields match everything; From
+// is inclusive and To exclusive.
+type PaymentFilter struct {
+	UserID    *uuid.UUID
+	Status    *domain.PaymentStatus
+	Method    *domain.PaymentMethod
+	BookingID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+}
+
+// PaymentSummary aggregates every payment matching a filter, ignoring
+// pagination. Count is the number of those payments. TotalPaid counts
+// completed payments only and excludes service fees, which TotalFees reports
+// as platform revenue.
+type PaymentSummary struct {
+	Count         int64
+	TotalPaid     int64
+	TotalRefunded int64
+	TotalFees     int64
+	CountByStatus map[domain.PaymentStatus]int64
 }
 
 type paymentRepository struct {
@@ -24,6 +60,10 @@ func NewPaymentRepository(db *gorm.DB) PaymentRepository {
 	return &paymentRepository{db: db}
 }
 
+func (r *paymentRepository) WithTx(tx *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: tx}
+}
+
 func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
 	return r.db.WithContext(ctx).Create(payment).Error
 }
@@ -51,10 +91,9 @@ func (r *paymentRepository) GetByExternalID(ctx context.Context, externalID stri
 	return &payment, nil
 }
 
-func (r *paymentRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Payment, error) {
+func (r *paymentRepository) List(ctx context.Context, filter PaymentFilter, limit, offset int) ([]*domain.Payment, error) {
 	var payments []*domain.Payment
-	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+	err := r.filtered(ctx, filter).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -62,6 +101,89 @@ func (r *paymentRepository) GetByUserID(ctx context.Context, userID uuid.UUID, l
 	return payments, err
 }
 
+func (r *paymentRepository) Summarize(ctx context.Context, filter PaymentFilter) (*PaymentSummary, error) {
+	var rows []struct {
+		PaymentStatus domain.PaymentStatus
+		Count         int64
+		Total         int64
+		Fees          int64
+	}
+	err := r.filtered(ctx, filter).
+		Select("payment_status, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS total, COALESCE(SUM(service_fee_amount), 0) AS fees").
+		Group("payment_status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PaymentSummary{CountByStatus: make(map[domain.PaymentStatus]int64, len(rows))}
+	for _, row := range rows {
+		summary.CountByStatus[row.PaymentStatus] = row.Count
+		summary.Count += row.Count
+		switch row.PaymentStatus {
+		case domain.PaymentStatusCompleted:
+			summary.TotalPaid = row.Total
+			summary.TotalFees = row.Fees
+		case domain.PaymentStatusRefunded:
+			summary.TotalRefunded = row.Total
+		}
+	}
+	return summary, nil
+}
+
+func (r *paymentRepository) filtered(ctx context.Context, filter PaymentFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&domain.Payment{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != nil {
+		query = query.Where("payment_status = ?", *filter.Status)
+	}
+	if filter.Method != nil {
+		query = query.Where("payment_method = ?", *filter.Method)
+	}
+	if filter.BookingID != nil {
+		query = query.Where("booking_id = ?", *filter.BookingID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return query
+}
+
 func (r *paymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
 	return r.db.WithContext(ctx).Save(payment).Error
 }
+
+func (r *paymentRepository) ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Payment{}).
+		Where("booking_id = ? AND payment_status = ?", fromBookingID, domain.PaymentStatusCompleted).
+		Update("booking_id", toBookingID)
+	return result.RowsAffected, result.Error
+}
+
+func (r *paymentRepository) VoidPendingByBooking(ctx context.Context, bookingID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Payment{}).
+		Where("booking_id = ? AND payment_status = ?", bookingID, domain.PaymentStatusPending).
+		Update("payment_status", domain.PaymentStatusVoided)
+	return result.RowsAffected, result.Error
+}
+
+func (r *paymentRepository) RestaurantRevenue(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (int64, error) {
+	var revenue int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Payment{}).
+		Select("COALESCE(SUM(payments.amount), 0)").
+		Joins("JOIN bookings ON bookings.id = payments.booking_id").
+		Where("bookings.restaurant_id = ? AND payments.payment_status = ? AND payments.created_at >= ? AND payments.created_at < ?",
+			restaurantID, domain.PaymentStatusCompleted, from, to).
+		Scan(&revenue).Error
+	return revenue, err
+}
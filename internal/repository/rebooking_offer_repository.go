@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RebookingOfferRepository interface {
+	Create(ctx context.Context, offer *domain.RebookingOffer) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.RebookingOffer, error)
+	Update(ctx context.Context, offer *domain.RebookingOffer) error
+	// ExpirePending marks pending offers whose ExpiresAt is not after now as
+	// expired and returns how many were marked.
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+	// CountByStatus counts offers created in [from, to) per status.
+	CountByStatus(ctx context.Context, from, to time.Time) (map[domain.RebookingOfferStatus]int64, error)
+	WithTx(tx *gorm.DB) RebookingOfferRepository
+}
+
+type rebookingOfferRepository struct {
+	db *gorm.DB
+}
+
+func NewRebookingOfferRepository(db *gorm.DB) RebookingOfferRepository {
+	return &rebookingOfferRepository{db: db}
+}
+
+func (r *rebookingOfferRepository) WithTx(tx *gorm.DB) RebookingOfferRepository {
+	return &rebookingOfferRepository{db: tx}
+}
+
+func (r *rebookingOfferRepository) Create(ctx context.Context, offer *domain.RebookingOffer) error {
+	return r.db.WithContext(ctx).Create(offer).Error
+}
+
+func (r *rebookingOfferRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RebookingOffer, error) {
+	var offer domain.RebookingOffer
+	if err := r.db.WithContext(ctx).First(&offer, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+func (r *rebookingOfferRepository) Update(ctx context.Context, offer *domain.RebookingOffer) error {
+	return r.db.WithContext(ctx).Save(offer).Error
+}
+
+func (r *rebookingOfferRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.RebookingOffer{}).
+		Where("status = ? AND expires_at <= ?", domain.RebookingOfferStatusPending, now).
+		Update("status", domain.RebookingOfferStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+func (r *rebookingOfferRepository) CountByStatus(ctx context.Context, from, to time.Time) (map[domain.RebookingOfferStatus]int64, error) {
+	var rows []struct {
+		Status domain.RebookingOfferStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.RebookingOffer{}).
+		Select("status, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.RebookingOfferStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
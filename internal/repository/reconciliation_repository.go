@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReconciliationRepository loads the sections of a restaurant's revenue
+// reconciliation. Each method is a single query over the restaurant's
+// bookings that start in [from, to).
+type ReconciliationRepository interface {
+	Bookings(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error)
+	// Payments returns the payments made for those bookings.
+	Payments(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Payment, error)
+	// LedgerEntries returns the wallet transactions recorded against those
+	// bookings, oldest first.
+	LedgerEntries(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.WalletTransaction, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+func (r *reconciliationRepository) Bookings(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ? AND start_time >= ? AND start_time < ?", restaurantID, from, to).
+		Order("start_time, id").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *reconciliationRepository) Payments(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Payment, error) {
+	var payments []*domain.Payment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN bookings ON bookings.id = payments.booking_id").
+		Where("bookings.restaurant_id = ? AND bookings.start_time >= ? AND bookings.start_time < ?", restaurantID, from, to).
+		Order("payments.created_at, payments.id").
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *reconciliationRepository) LedgerEntries(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.WalletTransaction, error) {
+	var entries []*domain.WalletTransaction
+	err := r.db.WithContext(ctx).
+		Joins("JOIN bookings ON bookings.id = wallet_transactions.booking_id").
+		Where("bookings.restaurant_id = ? AND bookings.start_time >= ? AND bookings.start_time < ?", restaurantID, from, to).
+		Order("wallet_transactions.created_at, wallet_transactions.id").
+		Find(&entries).Error
+	return entries, err
+}
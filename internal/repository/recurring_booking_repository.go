@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecurringBookingRepository interface {
+	Create(ctx context.Context, series *domain.RecurringBooking) error
+	// GetByID returns the series with its occurrences, earliest first.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.RecurringBooking, error)
+	// ListByUser returns the user's series with their occurrences, newest
+	// series first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error)
+	// ListActive returns up to limit active series with an ID above after,
+	// ordered by ID.
+	ListActive(ctx context.Context, after uuid.UUID, limit int) ([]*domain.RecurringBooking, error)
+	// GetOccurrenceStarts returns the start times of the series'
+	// occurrences saved so far.
+	GetOccurrenceStarts(ctx context.Context, id uuid.UUID) ([]time.Time, error)
+	// LockActive locks the series until the end of the transaction if it is
+	// still active, and reports whether it did.
+	LockActive(ctx context.Context, id uuid.UUID) (bool, error)
+	// CreateOccurrence saves the occurrence unless the series already has
+	// one at its start time, and reports whether it did.
+	CreateOccurrence(ctx context.Context, occurrence *domain.RecurringOccurrence) (bool, error)
+	// Cancel cancels the series if it is still active and reports whether
+	// it did.
+	Cancel(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+	// CancelUpcomingBookings cancels the pending and confirmed bookings of
+	// the series that start after now and returns them.
+	CancelUpcomingBookings(ctx context.Context, id uuid.UUID, now time.Time) ([]*domain.Booking, error)
+	WithTx(tx *gorm.DB) RecurringBookingRepository
+}
+
+type recurringBookingRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurringBookingRepository(db *gorm.DB) RecurringBookingRepository {
+	return &recurringBookingRepository{db: db}
+}
+
+func (r *recurringBookingRepository) WithTx(tx *gorm.DB) RecurringBookingRepository {
+	return &recurringBookingRepository{db: tx}
+}
+
+func (r *recurringBookingRepository) Create(ctx context.Context, series *domain.RecurringBooking) error {
+	return r.db.WithContext(ctx).Create(series).Error
+}
+
+func (r *recurringBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RecurringBooking, error) {
+	var series domain.RecurringBooking
+	err := r.db.WithContext(ctx).
+		Preload("Occurrences", func(db *gorm.DB) *gorm.DB { return db.Order("start_time") }).
+		First(&series, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *recurringBookingRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error) {
+	var series []*domain.RecurringBooking
+	err := r.db.WithContext(ctx).
+		Preload("Occurrences", func(db *gorm.DB) *gorm.DB { return db.Order("start_time") }).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id").
+		Find(&series).Error
+	return series, err
+}
+
+func (r *recurringBookingRepository) ListActive(ctx context.Context, after uuid.UUID, limit int) ([]*domain.RecurringBooking, error) {
+	var series []*domain.RecurringBooking
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND id > ?", domain.RecurringBookingStatusActive, after).
+		Order("id").
+		Limit(limit).
+		Find(&series).Error
+	return series, err
+}
+
+func (r *recurringBookingRepository) GetOccurrenceStarts(ctx context.Context, id uuid.UUID) ([]time.Time, error) {
+	var starts []time.Time
+	err := r.db.WithContext(ctx).
+		Model(&domain.RecurringOccurrence{}).
+		Where("recurring_booking_id = ?", id).
+		Pluck("start_time", &starts).Error
+	return starts, err
+}
+
+func (r *recurringBookingRepository) LockActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&domain.RecurringBooking{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND status = ?", id, domain.RecurringBookingStatusActive).
+		Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+func (r *recurringBookingRepository) CreateOccurrence(ctx context.Context, occurrence *domain.RecurringOccurrence) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(occurrence)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *recurringBookingRepository) Cancel(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.RecurringBooking{}).
+		Where("id = ? AND status = ?", id, domain.RecurringBookingStatusActive).
+		Updates(map[string]interface{}{
+			"status":       domain.RecurringBookingStatusCancelled,
+			"cancelled_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *recurringBookingRepository) CancelUpcomingBookings(ctx context.Context, id uuid.UUID, now time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN (SELECT booking_id FROM recurring_booking_occurrences WHERE recurring_booking_id = ?) AND status IN (?, ?) AND start_time > ?",
+			id, domain.BookingStatusPending, domain.BookingStatusConfirmed, now).
+		Order("start_time").
+		Find(&bookings).Error
+	if err != nil || len(bookings) == 0 {
+		return bookings, err
+	}
+
+	ids := make([]uuid.UUID, len(bookings))
+	for i, booking := range bookings {
+		ids[i] = booking.ID
+		booking.Status = domain.BookingStatusCancelled
+	}
+	err = r.db.WithContext(ctx).
+		Model(&domain.Booking{}).
+		Where("id IN ?", ids).
+		Update("status", domain.BookingStatusCancelled).Error
+	return bookings, err
+}
@@ -11,7 +11,12 @@ type RefreshTokenRepository interface {
 	Create(token *domain.RefreshToken) error
 	GetByToken(token string) (*domain.RefreshToken, error)
 	DeleteByToken(token string) error
-	DeleteAllByUserID(userID uuid.UUID) error
+	// MarkReplaced records that the token id was rotated into replacedBy. It
+	// reports false when the token was already rotated.
+	MarkReplaced(id, replacedBy uuid.UUID) (bool, error)
+	DeleteAllByUserID(userID uuid.UUID) (int64, error)
+	// DeleteByFamilyID revokes every token rotated from the same login.
+	DeleteByFamilyID(familyID uuid.UUID) (int64, error)
 }
 
 type refreshTokenRepository struct {
@@ -38,6 +43,19 @@ func (r *refreshTokenRepository) DeleteByToken(token string) error {
 	return r.db.Where("token = ?", token).Delete(&domain.RefreshToken{}).Error
 }
 
-func (r *refreshTokenRepository) DeleteAllByUserID(userID uuid.UUID) error {
-	return r.db.Where("user_id = ?", userID).Delete(&domain.RefreshToken{}).Error
+func (r *refreshTokenRepository) MarkReplaced(id, replacedBy uuid.UUID) (bool, error) {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("id = ? AND replaced_by IS NULL", id).
+		Update("replaced_by", replacedBy)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *refreshTokenRepository) DeleteAllByUserID(userID uuid.UUID) (int64, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&domain.RefreshToken{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *refreshTokenRepository) DeleteByFamilyID(familyID uuid.UUID) (int64, error) {
+	result := r.db.Where("family_id = ?", familyID).Delete(&domain.RefreshToken{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RequestSampleRepository interface {
+	Create(ctx context.Context, sample *domain.RequestSample) error
+	List(ctx context.Context, filter RequestSampleFilter, limit, offset int) ([]*domain.RequestSample, int64, error)
+	// DeleteBefore removes samples created before cutoff and returns how
+	// many were removed.
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// RequestSampleFilter narrows List. Nil fields and an empty Route match
+// everything; From is inclusive and To exclusive.
+type RequestSampleFilter struct {
+	UserID *uuid.UUID
+	Route  string
+	Status *int
+	From   *time.Time
+	To     *time.Time
+}
+
+type requestSampleRepository struct {
+	db *gorm.DB
+}
+
+func NewRequestSampleRepository(db *gorm.DB) RequestSampleRepository {
+	return &requestSampleRepository{db: db}
+}
+
+func (r *requestSampleRepository) Create(ctx context.Context, sample *domain.RequestSample) error {
+	return r.db.WithContext(ctx).Create(sample).Error
+}
+
+func (r *requestSampleRepository) List(ctx context.Context, filter RequestSampleFilter, limit, offset int) ([]*domain.RequestSample, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.RequestSample{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Route != "" {
+		query = query.Where("route = ?", filter.Route)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var samples []*domain.RequestSample
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&samples).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return samples, total, nil
+}
+
+func (r *requestSampleRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&domain.RequestSample{})
+	return result.RowsAffected, result.Error
+}
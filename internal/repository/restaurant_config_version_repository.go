@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RestaurantConfigVersionRepository interface {
+	Append(ctx context.Context, version *domain.RestaurantConfigVersion, keep int) error
+	Latest(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantConfigVersion, error)
+	GetByVersion(ctx context.Context, restaurantID uuid.UUID, version int) (*domain.RestaurantConfigVersion, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.RestaurantConfigVersion, error)
+	WithTx(tx *gorm.DB) RestaurantConfigVersionRepository
+}
+
+type restaurantConfigVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewRestaurantConfigVersionRepository(db *gorm.DB) RestaurantConfigVersionRepository {
+	return &restaurantConfigVersionRepository{db: db}
+}
+
+func (r *restaurantConfigVersionRepository) WithTx(tx *gorm.DB) RestaurantConfigVersionRepository {
+	return &restaurantConfigVersionRepository{db: tx}
+}
+
+// Append stores version as the next version number of its restaurant and
+// prunes everything but the newest keep versions.
+func (r *restaurantConfigVersionRepository) Append(ctx context.Context, version *domain.RestaurantConfigVersion, keep int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "restaurant_config_versions:"+version.RestaurantID.String()).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		if err := tx.Model(&domain.RestaurantConfigVersion{}).
+			Where("restaurant_id = ?", version.RestaurantID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+
+		version.Version = latest + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Where("restaurant_id = ? AND version <= ?", version.RestaurantID, version.Version-keep).
+			Delete(&domain.RestaurantConfigVersion{}).Error
+	})
+}
+
+func (r *restaurantConfigVersionRepository) Latest(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantConfigVersion, error) {
+	var version domain.RestaurantConfigVersion
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ?", restaurantID).
+		Order("version DESC").
+		First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (r *restaurantConfigVersionRepository) GetByVersion(ctx context.Context, restaurantID uuid.UUID, version int) (*domain.RestaurantConfigVersion, error) {
+	var configVersion domain.RestaurantConfigVersion
+	err := r.db.WithContext(ctx).
+		First(&configVersion, "restaurant_id = ? AND version = ?", restaurantID, version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &configVersion, nil
+}
+
+func (r *restaurantConfigVersionRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.RestaurantConfigVersion, error) {
+	var versions []*domain.RestaurantConfigVersion
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ?", restaurantID).
+		Order("version DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&versions).Error
+	return versions, err
+}
@@ -14,6 +14,7 @@ type RestaurantManagerRepository interface {
 	GetManagersByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantManager, error)
 	GetRestaurantsByManager(ctx context.Context, userID uuid.UUID) ([]*domain.RestaurantManager, error)
 	IsManager(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error)
+	WithTx(tx *gorm.DB) RestaurantManagerRepository
 }
 
 type restaurantManagerRepository struct {
@@ -24,6 +25,10 @@ func NewRestaurantManagerRepository(db *gorm.DB) RestaurantManagerRepository {
 	return &restaurantManagerRepository{db: db}
 }
 
+func (r *restaurantManagerRepository) WithTx(tx *gorm.DB) RestaurantManagerRepository {
+	return &restaurantManagerRepository{db: tx}
+}
+
 func (r *restaurantManagerRepository) Create(ctx context.Context, manager *domain.RestaurantManager) error {
 	return r.db.WithContext(ctx).Create(manager).Error
 }
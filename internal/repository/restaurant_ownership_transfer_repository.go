@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RestaurantOwnershipTransferRepository interface {
+	Create(ctx context.Context, transfer *domain.RestaurantOwnershipTransfer) error
+	// ListByRestaurant returns the restaurant's transfers, newest first.
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantOwnershipTransfer, error)
+	WithTx(tx *gorm.DB) RestaurantOwnershipTransferRepository
+}
+
+type restaurantOwnershipTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewRestaurantOwnershipTransferRepository(db *gorm.DB) RestaurantOwnershipTransferRepository {
+	return &restaurantOwnershipTransferRepository{db: db}
+}
+
+func (r *restaurantOwnershipTransferRepository) WithTx(tx *gorm.DB) RestaurantOwnershipTransferRepository {
+	return &restaurantOwnershipTransferRepository{db: tx}
+}
+
+func (r *restaurantOwnershipTransferRepository) Create(ctx context.Context, transfer *domain.RestaurantOwnershipTransfer) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+func (r *restaurantOwnershipTransferRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantOwnershipTransfer, error) {
+	var transfers []*domain.RestaurantOwnershipTransfer
+	err := r.db.WithContext(ctx).
+		Where("restaurant_id = ?", restaurantID).
+		Order("created_at DESC").
+		Find(&transfers).Error
+	return transfers, err
+}
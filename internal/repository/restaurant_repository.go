@@ -2,20 +2,170 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/pkg/sqlsafe"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+const uniqueSlugIndex = "idx_restaurants_slug"
+
+// ErrRestaurantSlugTaken is returned when another restaurant took the slug
+// between picking it and saving it.
+var ErrRestaurantSlugTaken = errors.New("restaurant slug is already taken")
+
+// ErrRestaurantStatusChanged is returned by SetStatus when the restaurant no
+// longer has the status the decision was made on.
+var ErrRestaurantStatusChanged = errors.New("restaurant status changed in the meantime")
+
+// restaurantReviewColumns are written only by SetStatus, so that saving an
+// edit made from a stale copy cannot undo an admin's decision.
+var restaurantReviewColumns = []string{"status", "rejection_reason", "reviewed_by", "reviewed_at"}
+
 type RestaurantRepository interface {
 	Create(ctx context.Context, restaurant *domain.Restaurant) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error)
 	GetByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]*domain.Restaurant, error)
+	// GetByAddressKey returns the oldest restaurant with the address key,
+	// whatever its status.
+	GetByAddressKey(ctx context.Context, key string) (*domain.Restaurant, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Restaurant, error)
+	// ListSlugs returns the slugs of restaurants other than except that are
+	// base or start with base followed by a hyphen.
+	ListSlugs(ctx context.Context, base string, except uuid.UUID) ([]string, error)
+	// ListWithoutSlug returns the first limit restaurants, by ID, that have
+	// no slug and whose ID is greater than after.
+	ListWithoutSlug(ctx context.Context, after uuid.UUID, limit int) ([]*domain.Restaurant, error)
+	// CountWithoutSlug counts the restaurants without a slug whose ID is
+	// greater than after.
+	CountWithoutSlug(ctx context.Context, after uuid.UUID) (int64, error)
+	// SetSlug stores only the restaurant's slug.
+	SetSlug(ctx context.Context, id uuid.UUID, slug string) error
+	// Update saves every column but the review ones SetStatus writes.
 	Update(ctx context.Context, restaurant *domain.Restaurant) error
+	// SetStatus stores the restaurant's Status, RejectionReason, ReviewedBy
+	// and ReviewedAt if its status is still from, and returns
+	// ErrRestaurantStatusChanged otherwise.
+	SetStatus(ctx context.Context, restaurant *domain.Restaurant, from domain.RestaurantStatus) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error)
-	Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, limit, offset int) ([]*domain.Restaurant, error)
+	// ListFiltered and ListColumns return the listed restaurants filter
+	// selects, in the order it asks for. Listed restaurants are the active
+	// ones an admin approved.
+	ListFiltered(ctx context.Context, filter RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error)
+	ListColumns(ctx context.Context, columns []string, withMainImage bool, filter RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error)
+	// Count counts the listed restaurants filter selects.
+	Count(ctx context.Context, filter RestaurantFilter) (int64, error)
+	// Search returns listed restaurants. A non-nil openAt keeps only those
+	// whose working hours include it.
+	Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error)
+	// ListNearby returns listed restaurants with coordinates within radiusKm
+	// of (lat, lng), nearest first.
+	ListNearby(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*NearbyRestaurant, error)
+	// CountByCuisine counts listed restaurants per cuisine. Cuisines without
+	// any are left out.
+	CountByCuisine(ctx context.Context) ([]*CuisineCount, error)
+	// ListByStatus returns restaurants in status whether active or not,
+	// oldest first, so the ones waiting longest for a decision come first.
+	ListByStatus(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, error)
+	CountByStatus(ctx context.Context, status domain.RestaurantStatus) (int64, error)
+	WithTx(tx *gorm.DB) RestaurantRepository
+}
+
+// NearbyRestaurant is a restaurant together with its distance from the point
+// it was searched around.
+type NearbyRestaurant struct {
+	domain.Restaurant
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// RestaurantSort is the key a restaurant list is ordered by.
+type RestaurantSort string
+
+const (
+	RestaurantSortCreatedAt RestaurantSort = "created_at"
+	RestaurantSortRating    RestaurantSort = "rating"
+	RestaurantSortPrice     RestaurantSort = "price"
+)
+
+// RestaurantSorts lists every supported RestaurantSort.
+var RestaurantSorts = []RestaurantSort{RestaurantSortCreatedAt, RestaurantSortRating, RestaurantSortPrice}
+
+// restaurantSortColumns is the sqlsafe allow-list behind RestaurantSort,
+// mapping each key to its column.
+var restaurantSortColumns = map[string]string{
+	string(RestaurantSortCreatedAt): "created_at",
+	string(RestaurantSortRating):    "rating",
+	string(RestaurantSortPrice):     "average_price",
+}
+
+// Valid reports whether s is one of RestaurantSorts.
+func (s RestaurantSort) Valid() bool {
+	_, err := sqlsafe.ResolveSort(restaurantSortColumns, string(s))
+	return err == nil
+}
+
+// RestaurantFilter narrows and orders ListFiltered and ListColumns, and
+// narrows Count. Nil fields match everything. A non-nil OpenAt keeps only
+// restaurants whose working hours include it; MinPrice and MaxPrice bound
+// AveragePrice. An empty Sort orders by created_at, and the order is
+// descending unless Ascending is set, so the zero value lists the newest
+// restaurants first.
+type RestaurantFilter struct {
+	OpenAt    *time.Time
+	MinPrice  *int
+	MaxPrice  *int
+	Sort      RestaurantSort
+	Ascending bool
+}
+
+// CuisineCount is how many listed restaurants serve a cuisine.
+type CuisineCount struct {
+	CuisineType     domain.CuisineType `json:"cuisine_type"`
+	RestaurantCount int64              `json:"restaurant_count"`
+}
+
+// haversineKm is the great-circle distance in kilometres between @lat/@lng
+// and a restaurant. LEAST guards acos against rounding just above 1.
+const haversineKm = `6371 * acos(LEAST(1, cos(radians(@lat)) * cos(radians(latitude)) * cos(radians(longitude) - radians(@lng)) + sin(radians(@lat)) * sin(radians(latitude))))`
+
+// openAtSQL matches restaurants whose working hours include @now (HH:MM) on
+// @today, or whose overnight opening from @yesterday is still running. It
+// follows the same rules as service.IsOpenAt; days that are missing or have
+// malformed times never match.
+var openAtSQL = fmt.Sprintf(`(
+	(working_hours -> CAST(@yesterday AS text) ->> 'is_closed' IS DISTINCT FROM 'true' AND %[2]s <= %[1]s AND CAST(@now AS time) < %[2]s)
+	OR (working_hours -> CAST(@today AS text) ->> 'is_closed' IS DISTINCT FROM 'true' AND %[3]s <= CAST(@now AS time) AND (CAST(@now AS time) < %[4]s OR %[4]s <= %[3]s))
+)`,
+	scheduleTimeSQL("@yesterday", "open_time"),
+	scheduleTimeSQL("@yesterday", "close_time"),
+	scheduleTimeSQL("@today", "open_time"),
+	scheduleTimeSQL("@today", "close_time"),
+)
+
+// scheduleTimeSQL reads one HH:MM field of a day's working hours as a time,
+// or NULL when it is missing or malformed, so a bad row cannot fail the
+// whole query.
+func scheduleTimeSQL(day, field string) string {
+	value := fmt.Sprintf("(working_hours -> CAST(%s AS text) ->> '%s')", day, field)
+	return fmt.Sprintf(`(CASE WHEN %[1]s ~ '^([01]{0,1}[0-9]|2[0-3]):[0-5][0-9]$' THEN CAST(%[1]s AS time) END)`, value)
+}
+
+// whereOpenAt applies openAtSQL for at, when given.
+func whereOpenAt(query *gorm.DB, at *time.Time) *gorm.DB {
+	if at == nil {
+		return query
+	}
+	return query.Where(openAtSQL, map[string]interface{}{
+		"today":     strings.ToLower(at.Weekday().String()),
+		"yesterday": strings.ToLower(at.AddDate(0, 0, -1).Weekday().String()),
+		"now":       at.Format("15:04"),
+	})
 }
 
 type restaurantRepository struct {
@@ -26,14 +176,18 @@ func NewRestaurantRepository(db *gorm.DB) RestaurantRepository {
 	return &restaurantRepository{db: db}
 }
 
+func (r *restaurantRepository) WithTx(tx *gorm.DB) RestaurantRepository {
+	return &restaurantRepository{db: tx}
+}
+
 func (r *restaurantRepository) Create(ctx context.Context, restaurant *domain.Restaurant) error {
-	return r.db.WithContext(ctx).Create(restaurant).Error
+	return translateRestaurantError(r.db.WithContext(ctx).Create(restaurant).Error)
 }
 
 func (r *restaurantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error) {
 	var restaurant domain.Restaurant
 	err := r.db.WithContext(ctx).
-		Preload("Images").
+		Preload("Images", orderedImages).
 		Preload("Tables").
 		First(&restaurant, "id = ?", id).Error
 	if err != nil {
@@ -50,27 +204,205 @@ func (r *restaurantRepository) GetByOwnerID(ctx context.Context, ownerID uuid.UU
 	return restaurants, err
 }
 
+func (r *restaurantRepository) GetByAddressKey(ctx context.Context, key string) (*domain.Restaurant, error) {
+	var restaurant domain.Restaurant
+	err := r.db.WithContext(ctx).
+		Where("address_key = ?", key).
+		Order("created_at ASC").
+		First(&restaurant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+func (r *restaurantRepository) GetBySlug(ctx context.Context, slug string) (*domain.Restaurant, error) {
+	var restaurant domain.Restaurant
+	err := r.db.WithContext(ctx).
+		Preload("Images", orderedImages).
+		Preload("Tables").
+		First(&restaurant, "slug = ?", slug).Error
+	if err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+func (r *restaurantRepository) ListSlugs(ctx context.Context, base string, except uuid.UUID) ([]string, error) {
+	var slugs []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, base+"-%", except).
+		Pluck("slug", &slugs).Error
+	return slugs, err
+}
+
+func (r *restaurantRepository) ListWithoutSlug(ctx context.Context, after uuid.UUID, limit int) ([]*domain.Restaurant, error) {
+	var restaurants []*domain.Restaurant
+	err := r.db.WithContext(ctx).
+		Select("id", "name").
+		Where("slug IS NULL AND id > ?", after).
+		Order("id").
+		Limit(limit).
+		Find(&restaurants).Error
+	return restaurants, err
+}
+
+func (r *restaurantRepository) CountWithoutSlug(ctx context.Context, after uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Where("slug IS NULL AND id > ?", after).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *restaurantRepository) SetSlug(ctx context.Context, id uuid.UUID, slug string) error {
+	return translateRestaurantError(r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Where("id = ?", id).
+		Update("slug", slug).Error)
+}
+
 func (r *restaurantRepository) Update(ctx context.Context, restaurant *domain.Restaurant) error {
-	return r.db.WithContext(ctx).Save(restaurant).Error
+	return translateRestaurantError(r.db.WithContext(ctx).Omit(restaurantReviewColumns...).Save(restaurant).Error)
+}
+
+func (r *restaurantRepository) SetStatus(ctx context.Context, restaurant *domain.Restaurant, from domain.RestaurantStatus) error {
+	result := r.db.WithContext(ctx).
+		Model(restaurant).
+		Where("status = ?", from).
+		Select(restaurantReviewColumns).
+		Updates(restaurant)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRestaurantStatusChanged
+	}
+	return nil
 }
 
 func (r *restaurantRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&domain.Restaurant{}, "id = ?", id).Error
 }
 
-func (r *restaurantRepository) List(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error) {
+func (r *restaurantRepository) ListFiltered(ctx context.Context, filter RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	var restaurants []*domain.Restaurant
+	err := filteredRestaurants(r.db.WithContext(ctx), filter).
+		Limit(limit).
+		Offset(offset).
+		Find(&restaurants).Error
+	return restaurants, err
+}
+
+func (r *restaurantRepository) ListColumns(ctx context.Context, columns []string, withMainImage bool, filter RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	query := filteredRestaurants(r.db.WithContext(ctx).Select(columns), filter)
+
+	if withMainImage {
+		query = query.Preload("Images", "is_main = ?", true)
+	}
+
 	var restaurants []*domain.Restaurant
+	err := query.
+		Limit(limit).
+		Offset(offset).
+		Find(&restaurants).Error
+	return restaurants, err
+}
+
+func (r *restaurantRepository) Count(ctx context.Context, filter RestaurantFilter) (int64, error) {
+	var count int64
+	err := whereRestaurantFilter(r.db.WithContext(ctx).Model(&domain.Restaurant{}), filter).
+		Count(&count).Error
+	return count, err
+}
+
+// filteredRestaurants applies filter to a query over listed restaurants. id
+// breaks ties so that pages do not overlap when restaurants share a sort
+// value.
+func filteredRestaurants(query *gorm.DB, filter RestaurantFilter) *gorm.DB {
+	key := string(filter.Sort)
+	if !filter.Sort.Valid() {
+		key = string(RestaurantSortCreatedAt)
+	}
+	if !filter.Ascending {
+		key = "-" + key
+	}
+	sort, _ := sqlsafe.ResolveSort(restaurantSortColumns, key)
+	return whereRestaurantFilter(query, filter).
+		Order(sort.Clause()).
+		Order(sort.Then("id").Clause())
+}
+
+// whereRestaurantFilter narrows a query to the listed restaurants filter
+// selects, leaving the order alone.
+func whereRestaurantFilter(query *gorm.DB, filter RestaurantFilter) *gorm.DB {
+	query = whereOpenAt(whereListed(query), filter.OpenAt)
+	if filter.MinPrice != nil {
+		query = query.Where("average_price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("average_price <= ?", *filter.MaxPrice)
+	}
+	return query
+}
+
+// whereListed keeps the restaurants the public sees: active ones an admin
+// approved.
+func whereListed(query *gorm.DB) *gorm.DB {
+	return query.Where("is_active = ? AND status = ?", true, domain.RestaurantStatusApproved)
+}
+
+func (r *restaurantRepository) ListNearby(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*NearbyRestaurant, error) {
+	withDistance := r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Select("restaurants.*, "+haversineKm+" AS distance_km", map[string]interface{}{"lat": lat, "lng": lng}).
+		Where("is_active = ? AND status = ? AND latitude IS NOT NULL AND longitude IS NOT NULL", true, domain.RestaurantStatusApproved)
+
+	var restaurants []*NearbyRestaurant
 	err := r.db.WithContext(ctx).
-		Where("is_active = ?", true).
+		Table("(?) AS restaurants", withDistance).
+		Where("distance_km <= ?", radiusKm).
+		Order("distance_km").
+		Limit(limit).
+		Offset(offset).
+		Scan(&restaurants).Error
+	return restaurants, err
+}
+
+func (r *restaurantRepository) CountByCuisine(ctx context.Context) ([]*CuisineCount, error) {
+	var counts []*CuisineCount
+	err := whereListed(r.db.WithContext(ctx).Model(&domain.Restaurant{})).
+		Select("cuisine_type, COUNT(*) AS restaurant_count").
+		Group("cuisine_type").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *restaurantRepository) ListByStatus(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, error) {
+	var restaurants []*domain.Restaurant
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at ASC, id ASC").
 		Limit(limit).
 		Offset(offset).
 		Find(&restaurants).Error
 	return restaurants, err
 }
 
-func (r *restaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, limit, offset int) ([]*domain.Restaurant, error) {
+func (r *restaurantRepository) CountByStatus(ctx context.Context, status domain.RestaurantStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Where("status = ?", status).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *restaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error) {
 	var restaurants []*domain.Restaurant
-	query := r.db.WithContext(ctx).Where("is_active = ?", true)
+	query := whereListed(r.db.WithContext(ctx))
 
 	if cuisineType != nil {
 		query = query.Where("cuisine_type = ?", *cuisineType)
@@ -80,6 +412,21 @@ func (r *restaurantRepository) Search(ctx context.Context, cuisineType *domain.C
 		query = query.Where("rating >= ?", minRating)
 	}
 
+	query = whereOpenAt(query, openAt)
+
 	err := query.Limit(limit).Offset(offset).Find(&restaurants).Error
 	return restaurants, err
 }
+
+// orderedImages sorts a restaurant's gallery the way the owner arranged it.
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, created_at ASC")
+}
+
+func translateRestaurantError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == uniqueSlugIndex {
+		return ErrRestaurantSlugTaken
+	}
+	return err
+}
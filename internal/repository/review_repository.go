@@ -2,19 +2,40 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"restaurant-booking/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+var ErrReviewWindowOverlap = errors.New("review overlaps an existing review window")
+
+const reviewWindowConstraint = "excl_reviews_user_restaurant_window"
+
 type ReviewRepository interface {
 	Create(ctx context.Context, review *domain.Review) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Review, error)
 	GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.Review, error)
+	// CountByRestaurantID counts the visible reviews GetByRestaurantID pages
+	// through.
+	CountByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (int64, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Review, error)
+	// GetLatestByUserAndRestaurant includes hidden reviews.
+	GetLatestByUserAndRestaurant(ctx context.Context, userID, restaurantID uuid.UUID) (*domain.Review, error)
 	Update(ctx context.Context, review *domain.Review) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// RefreshRestaurantRating recomputes the restaurant's rating and
+	// reviews_count from its visible reviews.
+	RefreshRestaurantRating(ctx context.Context, restaurantID uuid.UUID) error
+	// RefreshRestaurantRatings does the same for the first limit restaurants,
+	// by ID, whose ID is greater than after, and returns their IDs in order.
+	RefreshRestaurantRatings(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	// CountRestaurantsAfter counts the restaurants whose ID is greater than
+	// after.
+	CountRestaurantsAfter(ctx context.Context, after uuid.UUID) (int64, error)
+	WithTx(tx *gorm.DB) ReviewRepository
 }
 
 type reviewRepository struct {
@@ -25,8 +46,12 @@ func NewReviewRepository(db *gorm.DB) ReviewRepository {
 	return &reviewRepository{db: db}
 }
 
+func (r *reviewRepository) WithTx(tx *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: tx}
+}
+
 func (r *reviewRepository) Create(ctx context.Context, review *domain.Review) error {
-	return r.db.WithContext(ctx).Create(review).Error
+	return translateReviewError(r.db.WithContext(ctx).Create(review).Error)
 }
 
 func (r *reviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Review, error) {
@@ -53,6 +78,15 @@ func (r *reviewRepository) GetByRestaurantID(ctx context.Context, restaurantID u
 	return reviews, err
 }
 
+func (r *reviewRepository) CountByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Review{}).
+		Where("restaurant_id = ? AND is_visible = ?", restaurantID, true).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *reviewRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Review, error) {
 	var reviews []*domain.Review
 	err := r.db.WithContext(ctx).
@@ -63,6 +97,18 @@ func (r *reviewRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([
 	return reviews, err
 }
 
+func (r *reviewRepository) GetLatestByUserAndRestaurant(ctx context.Context, userID, restaurantID uuid.UUID) (*domain.Review, error) {
+	var review domain.Review
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND restaurant_id = ?", userID, restaurantID).
+		Order("created_at DESC").
+		First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
 func (r *reviewRepository) Update(ctx context.Context, review *domain.Review) error {
 	return r.db.WithContext(ctx).Save(review).Error
 }
@@ -70,3 +116,51 @@ func (r *reviewRepository) Update(ctx context.Context, review *domain.Review) er
 func (r *reviewRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&domain.Review{}, "id = ?", id).Error
 }
+
+func (r *reviewRepository) RefreshRestaurantRating(ctx context.Context, restaurantID uuid.UUID) error {
+	return r.db.WithContext(ctx).Exec(`
+		UPDATE restaurants SET
+			rating = COALESCE((SELECT ROUND(AVG(rating), 1) FROM reviews WHERE restaurant_id = @id AND is_visible), 0),
+			reviews_count = (SELECT COUNT(*) FROM reviews WHERE restaurant_id = @id AND is_visible)
+		WHERE id = @id`, map[string]interface{}{"id": restaurantID}).Error
+}
+
+func (r *reviewRepository) RefreshRestaurantRatings(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		WITH batch AS (
+			SELECT id FROM restaurants WHERE id > @after ORDER BY id LIMIT @limit
+		), visible AS (
+			SELECT restaurant_id, ROUND(AVG(rating), 1) AS rating, COUNT(*) AS reviews_count
+			FROM reviews
+			WHERE is_visible AND restaurant_id IN (SELECT id FROM batch)
+			GROUP BY restaurant_id
+		), updated AS (
+			UPDATE restaurants SET
+				rating = COALESCE(visible.rating, 0),
+				reviews_count = COALESCE(visible.reviews_count, 0)
+			FROM batch LEFT JOIN visible ON visible.restaurant_id = batch.id
+			WHERE restaurants.id = batch.id
+			RETURNING restaurants.id
+		)
+		SELECT id FROM updated ORDER BY id`, map[string]interface{}{"after": after, "limit": limit}).
+		Scan(&ids).Error
+	return ids, err
+}
+
+func (r *reviewRepository) CountRestaurantsAfter(ctx context.Context, after uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Restaurant{}).
+		Where("id > ?", after).
+		Count(&count).Error
+	return count, err
+}
+
+func translateReviewError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23P01" && pgErr.ConstraintName == reviewWindowConstraint {
+		return ErrReviewWindowOverlap
+	}
+	return err
+}
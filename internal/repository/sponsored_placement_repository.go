@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SponsoredPlacementRepository interface {
+	Create(ctx context.Context, placement *domain.SponsoredPlacement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.SponsoredPlacement, error)
+	// List returns placements newest first, only the restaurant's when
+	// restaurantID is set.
+	List(ctx context.Context, restaurantID *uuid.UUID, limit, offset int) ([]*domain.SponsoredPlacement, error)
+	// Update saves the editable fields of placement, leaving the counters
+	// to AddCounts.
+	Update(ctx context.Context, placement *domain.SponsoredPlacement) error
+	// Delete returns gorm.ErrRecordNotFound when there is no placement with
+	// that ID.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListServing returns the placements of active restaurants that are
+	// serving at now and match scope, with Restaurant and its images
+	// loaded.
+	ListServing(ctx context.Context, scope SponsoredScope, now time.Time) ([]*domain.SponsoredPlacement, error)
+	// AddCounts adds to a placement's impression and click counters.
+	AddCounts(ctx context.Context, id uuid.UUID, impressions, clicks int64) error
+}
+
+// SponsoredScope describes the list sponsored results are picked for. A
+// scoped placement only matches lists for its city and cuisine; unscoped
+// placements match every list. With CuisineType set, the promoted
+// restaurant must also serve that cuisine.
+type SponsoredScope struct {
+	City        string
+	CuisineType *domain.CuisineType
+}
+
+type sponsoredPlacementRepository struct {
+	db *gorm.DB
+}
+
+func NewSponsoredPlacementRepository(db *gorm.DB) SponsoredPlacementRepository {
+	return &sponsoredPlacementRepository{db: db}
+}
+
+func (r *sponsoredPlacementRepository) Create(ctx context.Context, placement *domain.SponsoredPlacement) error {
+	return r.db.WithContext(ctx).Create(placement).Error
+}
+
+func (r *sponsoredPlacementRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.SponsoredPlacement, error) {
+	var placement domain.SponsoredPlacement
+	err := r.db.WithContext(ctx).First(&placement, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &placement, nil
+}
+
+func (r *sponsoredPlacementRepository) List(ctx context.Context, restaurantID *uuid.UUID, limit, offset int) ([]*domain.SponsoredPlacement, error) {
+	query := r.db.WithContext(ctx)
+	if restaurantID != nil {
+		query = query.Where("restaurant_id = ?", *restaurantID)
+	}
+
+	var placements []*domain.SponsoredPlacement
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&placements).Error
+	return placements, err
+}
+
+func (r *sponsoredPlacementRepository) Update(ctx context.Context, placement *domain.SponsoredPlacement) error {
+	return r.db.WithContext(ctx).
+		Model(placement).
+		Select("city", "cuisine_type", "weight", "starts_at", "ends_at", "budget", "cost_per_click", "updated_at").
+		Updates(placement).Error
+}
+
+func (r *sponsoredPlacementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SponsoredPlacement{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *sponsoredPlacementRepository) ListServing(ctx context.Context, scope SponsoredScope, now time.Time) ([]*domain.SponsoredPlacement, error) {
+	query := r.db.WithContext(ctx).
+		Joins("JOIN restaurants ON restaurants.id = sponsored_placements.restaurant_id").
+		Where("restaurants.is_active = ? AND restaurants.status = ?", true, domain.RestaurantStatusApproved).
+		Where("sponsored_placements.starts_at <= ? AND sponsored_placements.ends_at > ?", now, now).
+		Where("sponsored_placements.clicks * sponsored_placements.cost_per_click < sponsored_placements.budget")
+
+	if scope.City == "" {
+		query = query.Where("sponsored_placements.city IS NULL")
+	} else {
+		query = query.Where("(sponsored_placements.city IS NULL OR LOWER(sponsored_placements.city) = LOWER(?))", scope.City)
+	}
+	if scope.CuisineType == nil {
+		query = query.Where("sponsored_placements.cuisine_type IS NULL")
+	} else {
+		query = query.Where("(sponsored_placements.cuisine_type IS NULL OR sponsored_placements.cuisine_type = ?) AND restaurants.cuisine_type = ?",
+			*scope.CuisineType, *scope.CuisineType)
+	}
+
+	var placements []*domain.SponsoredPlacement
+	err := query.
+		Preload("Restaurant").
+		Preload("Restaurant.Images").
+		Order("sponsored_placements.weight DESC, sponsored_placements.impressions, sponsored_placements.id").
+		Find(&placements).Error
+	return placements, err
+}
+
+func (r *sponsoredPlacementRepository) AddCounts(ctx context.Context, id uuid.UUID, impressions, clicks int64) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.SponsoredPlacement{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"impressions": gorm.Expr("impressions + ?", impressions),
+			"clicks":      gorm.Expr("clicks + ?", clicks),
+		}).Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TableBlockRepository interface {
+	Create(ctx context.Context, block *domain.TableBlock) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.TableBlock, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListByTable returns the table's blocks that have not ended by from,
+	// earliest first.
+	ListByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.TableBlock, error)
+	// IsBlocked reports whether any block of the table overlaps start to end.
+	IsBlocked(ctx context.Context, tableID uuid.UUID, start, end time.Time) (bool, error)
+}
+
+type tableBlockRepository struct {
+	db *gorm.DB
+}
+
+func NewTableBlockRepository(db *gorm.DB) TableBlockRepository {
+	return &tableBlockRepository{db: db}
+}
+
+func (r *tableBlockRepository) Create(ctx context.Context, block *domain.TableBlock) error {
+	return r.db.WithContext(ctx).Create(block).Error
+}
+
+func (r *tableBlockRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TableBlock, error) {
+	var block domain.TableBlock
+	if err := r.db.WithContext(ctx).First(&block, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *tableBlockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.TableBlock{}, "id = ?", id).Error
+}
+
+func (r *tableBlockRepository) ListByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.TableBlock, error) {
+	var blocks []*domain.TableBlock
+	err := r.db.WithContext(ctx).
+		Where("table_id = ? AND ends_at > ?", tableID, from).
+		Order("starts_at ASC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *tableBlockRepository) IsBlocked(ctx context.Context, tableID uuid.UUID, start, end time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.TableBlock{}).
+		Where("table_id = ? AND starts_at < ? AND ends_at > ?", tableID, end, start).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -2,20 +2,50 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"restaurant-booking/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+var ErrDuplicateTableNumber = errors.New("table number already exists for this restaurant")
+
+const uniqueTableNumberIndex = "idx_tables_restaurant_table_number_active"
+
+// FloorPlanTable is an active table of a floor plan. Booked reports whether a
+// booking holds the table at the requested time.
+type FloorPlanTable struct {
+	ID           uuid.UUID
+	TableNumber  string
+	MinCapacity  int
+	MaxCapacity  int
+	LocationType domain.LocationType
+	XPosition    *int
+	YPosition    *int
+	Booked       bool
+}
+
 type TableRepository interface {
 	Create(ctx context.Context, table *domain.Table) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Table, error)
 	GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*domain.Table, error)
-	GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error)
+	// GetByMinCapacity returns the restaurant's active tables seating at
+	// least minCapacity guests, smallest first.
+	GetByMinCapacity(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error)
+	// GetAvailableTables is GetByMinCapacity without the tables a booking
+	// holds at some point between start and end. A booking ending exactly at
+	// start does not count.
+	GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error)
+	// GetFloorPlan returns the restaurant's active tables, each marked booked
+	// when a pending or confirmed booking covers at.
+	GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]FloorPlanTable, error)
 	Update(ctx context.Context, table *domain.Table) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, limit, offset int) ([]*domain.Table, error)
+	WithTx(tx *gorm.DB) TableRepository
 }
 
 type tableRepository struct {
@@ -26,8 +56,12 @@ func NewTableRepository(db *gorm.DB) TableRepository {
 	return &tableRepository{db: db}
 }
 
+func (r *tableRepository) WithTx(tx *gorm.DB) TableRepository {
+	return &tableRepository{db: tx}
+}
+
 func (r *tableRepository) Create(ctx context.Context, table *domain.Table) error {
-	return r.db.WithContext(ctx).Create(table).Error
+	return translateTableError(r.db.WithContext(ctx).Create(table).Error)
 }
 
 func (r *tableRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Table, error) {
@@ -50,7 +84,7 @@ func (r *tableRepository) GetByRestaurantID(ctx context.Context, restaurantID uu
 	return tables, err
 }
 
-func (r *tableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
+func (r *tableRepository) GetByMinCapacity(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
 	var tables []*domain.Table
 	err := r.db.WithContext(ctx).
 		Where("restaurant_id = ? AND is_active = ? AND max_capacity >= ?",
@@ -60,8 +94,50 @@ func (r *tableRepository) GetAvailableTables(ctx context.Context, restaurantID u
 	return tables, err
 }
 
+func (r *tableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error) {
+	var tables []*domain.Table
+	err := r.db.WithContext(ctx).
+		Where("tables.restaurant_id = ? AND tables.is_active = ? AND tables.max_capacity >= ?",
+			restaurantID, true, minCapacity).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM bookings
+			WHERE bookings.table_id = tables.id
+				AND bookings.status NOT IN (?, ?)
+				AND bookings.start_time < ? AND bookings.end_time > ?
+		)`,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			end, start,
+		).
+		Order("tables.min_capacity ASC").
+		Find(&tables).Error
+	return tables, err
+}
+
+func (r *tableRepository) GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]FloorPlanTable, error) {
+	var tables []FloorPlanTable
+	err := r.db.WithContext(ctx).
+		Model(&domain.Table{}).
+		Select(`tables.id, tables.table_number, tables.min_capacity, tables.max_capacity,
+			tables.location_type, tables.x_position, tables.y_position,
+			EXISTS (
+				SELECT 1 FROM bookings
+				WHERE bookings.table_id = tables.id
+					AND bookings.status NOT IN (?, ?)
+					AND bookings.start_time <= ? AND bookings.end_time > ?
+			) AS booked`,
+			domain.BookingStatusCancelled,
+			domain.BookingStatusCompleted,
+			at, at,
+		).
+		Where("tables.restaurant_id = ? AND tables.is_active = ?", restaurantID, true).
+		Order("tables.table_number ASC").
+		Scan(&tables).Error
+	return tables, err
+}
+
 func (r *tableRepository) Update(ctx context.Context, table *domain.Table) error {
-	return r.db.WithContext(ctx).Save(table).Error
+	return translateTableError(r.db.WithContext(ctx).Save(table).Error)
 }
 
 func (r *tableRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -77,3 +153,11 @@ func (r *tableRepository) List(ctx context.Context, limit, offset int) ([]*domai
 		Find(&tables).Error
 	return tables, err
 }
+
+func translateTableError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == uniqueTableNumberIndex {
+		return ErrDuplicateTableNumber
+	}
+	return err
+}
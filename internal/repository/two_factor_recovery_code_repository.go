@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRecoveryCodeRepository interface {
+	// ReplaceForUser drops the user's existing codes and stores the new ones.
+	ReplaceForUser(userID uuid.UUID, codes []*domain.TwoFactorRecoveryCode) error
+	GetUnusedByUserID(userID uuid.UUID) ([]*domain.TwoFactorRecoveryCode, error)
+	// MarkUsed reports false when the code was already used.
+	MarkUsed(id uuid.UUID) (bool, error)
+}
+
+type twoFactorRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRecoveryCodeRepository(db *gorm.DB) TwoFactorRecoveryCodeRepository {
+	return &twoFactorRecoveryCodeRepository{db: db}
+}
+
+func (r *twoFactorRecoveryCodeRepository) ReplaceForUser(userID uuid.UUID, codes []*domain.TwoFactorRecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRecoveryCodeRepository) GetUnusedByUserID(userID uuid.UUID) ([]*domain.TwoFactorRecoveryCode, error) {
+	var codes []*domain.TwoFactorRecoveryCode
+	if err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r *twoFactorRecoveryCodeRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&domain.TwoFactorRecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
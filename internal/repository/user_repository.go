@@ -1,18 +1,34 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"restaurant-booking/internal/domain"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+var ErrDuplicatePhone = errors.New("phone number is already in use")
+
+const uniquePhoneIndex = "idx_users_phone_unique"
+
 type UserRepository interface {
 	Create(user *domain.User) error
 	GetByID(id uuid.UUID) (*domain.User, error)
 	GetByEmail(email string) (*domain.User, error)
 	Update(user *domain.User) error
 	Delete(id uuid.UUID) error
+	List(ctx context.Context, filter UserFilter, limit, offset int) ([]*domain.User, int64, error)
+}
+
+// UserFilter narrows List. Nil fields and an empty Search match everything.
+type UserFilter struct {
+	Role     *domain.UserRole
+	IsActive *bool
+	Search   string
 }
 
 type userRepository struct {
@@ -24,7 +40,7 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+	return translateUserError(r.db.Create(user).Error)
 }
 
 func (r *userRepository) GetByID(id uuid.UUID) (*domain.User, error) {
@@ -44,9 +60,55 @@ func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 }
 
 func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+	return translateUserError(r.db.Save(user).Error)
 }
 
 func (r *userRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
+
+// List returns one page of users matching filter, newest first, together
+// with the total number of matches.
+func (r *userRepository) List(ctx context.Context, filter UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.User{})
+
+	if filter.Role != nil {
+		query = query.Where("role = ?", *filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*domain.User
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func translateUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == uniquePhoneIndex {
+		return ErrDuplicatePhone
+	}
+	return err
+}
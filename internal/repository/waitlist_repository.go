@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaitlistRepository interface {
+	Create(ctx context.Context, entry *domain.WaitlistEntry) error
+	GetByClaimToken(ctx context.Context, token string) (*domain.WaitlistEntry, error)
+	// ListWaiting returns up to limit waiting entries of the restaurant for
+	// minGuests to maxGuests that want a table at some point between from
+	// and to and start after now, with their users, oldest first.
+	ListWaiting(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, minGuests, maxGuests int, now time.Time, limit int) ([]*domain.WaitlistEntry, error)
+	// GetLapsedOffers returns up to limit offered entries whose offer
+	// expired by now, oldest offer first.
+	GetLapsedOffers(ctx context.Context, now time.Time, limit int) ([]*domain.WaitlistEntry, error)
+	// MarkOffered offers the table to the entry if it is still waiting and
+	// reports whether it did.
+	MarkOffered(ctx context.Context, id, tableID uuid.UUID, token string, expiresAt time.Time) (bool, error)
+	// MarkBooked records the booking made from the entry's offer if the
+	// offer is still open at now, and reports whether it did.
+	MarkBooked(ctx context.Context, id, bookingID uuid.UUID, now time.Time) (bool, error)
+	// ExpireOffer expires the entry if it is still offered and reports
+	// whether it did.
+	ExpireOffer(ctx context.Context, id uuid.UUID) (bool, error)
+	// ReturnToWaiting withdraws the entry's offer, keeping its place in
+	// line, if it is still offered and reports whether it did.
+	ReturnToWaiting(ctx context.Context, id uuid.UUID) (bool, error)
+	WithTx(tx *gorm.DB) WaitlistRepository
+}
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (r *waitlistRepository) WithTx(tx *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{db: tx}
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, entry *domain.WaitlistEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *waitlistRepository) GetByClaimToken(ctx context.Context, token string) (*domain.WaitlistEntry, error) {
+	var entry domain.WaitlistEntry
+	if err := r.db.WithContext(ctx).First(&entry, "claim_token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *waitlistRepository) ListWaiting(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, minGuests, maxGuests int, now time.Time, limit int) ([]*domain.WaitlistEntry, error) {
+	var entries []*domain.WaitlistEntry
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("restaurant_id = ? AND status = ? AND start_time < ? AND end_time > ? AND start_time > ? AND guests_count BETWEEN ? AND ?",
+			restaurantID, domain.WaitlistStatusWaiting, to, from, now, minGuests, maxGuests).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *waitlistRepository) GetLapsedOffers(ctx context.Context, now time.Time, limit int) ([]*domain.WaitlistEntry, error) {
+	var entries []*domain.WaitlistEntry
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND offer_expires_at <= ?", domain.WaitlistStatusOffered, now).
+		Order("offer_expires_at, id").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *waitlistRepository) MarkOffered(ctx context.Context, id, tableID uuid.UUID, token string, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ?", id, domain.WaitlistStatusWaiting).
+		Updates(map[string]interface{}{
+			"status":           domain.WaitlistStatusOffered,
+			"offer_table_id":   tableID,
+			"claim_token":      token,
+			"offer_expires_at": expiresAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *waitlistRepository) MarkBooked(ctx context.Context, id, bookingID uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ? AND offer_expires_at > ?", id, domain.WaitlistStatusOffered, now).
+		Updates(map[string]interface{}{
+			"status":     domain.WaitlistStatusBooked,
+			"booking_id": bookingID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *waitlistRepository) ExpireOffer(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ?", id, domain.WaitlistStatusOffered).
+		Update("status", domain.WaitlistStatusExpired)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *waitlistRepository) ReturnToWaiting(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ?", id, domain.WaitlistStatusOffered).
+		Updates(map[string]interface{}{
+			"status":           domain.WaitlistStatusWaiting,
+			"offer_table_id":   nil,
+			"claim_token":      nil,
+			"offer_expires_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WalletAdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *domain.WalletAdjustment) error
+	// GetForUpdate returns the adjustment with its row locked until the
+	// transaction ends. It must run under WithTx.
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.WalletAdjustment, error)
+	Update(ctx context.Context, adjustment *domain.WalletAdjustment) error
+	// ListPending returns pending adjustments, oldest first.
+	ListPending(ctx context.Context, limit, offset int) ([]*domain.WalletAdjustment, error)
+	// ExpirePending marks pending adjustments whose ExpiresAt is not after
+	// now as expired and returns how many were marked.
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+	WithTx(tx *gorm.DB) WalletAdjustmentRepository
+}
+
+type walletAdjustmentRepository struct {
+	db *gorm.DB
+}
+
+func NewWalletAdjustmentRepository(db *gorm.DB) WalletAdjustmentRepository {
+	return &walletAdjustmentRepository{db: db}
+}
+
+func (r *walletAdjustmentRepository) WithTx(tx *gorm.DB) WalletAdjustmentRepository {
+	return &walletAdjustmentRepository{db: tx}
+}
+
+func (r *walletAdjustmentRepository) Create(ctx context.Context, adjustment *domain.WalletAdjustment) error {
+	return r.db.WithContext(ctx).Create(adjustment).Error
+}
+
+func (r *walletAdjustmentRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.WalletAdjustment, error) {
+	var adjustment domain.WalletAdjustment
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&adjustment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+func (r *walletAdjustmentRepository) Update(ctx context.Context, adjustment *domain.WalletAdjustment) error {
+	return r.db.WithContext(ctx).Save(adjustment).Error
+}
+
+func (r *walletAdjustmentRepository) ListPending(ctx context.Context, limit, offset int) ([]*domain.WalletAdjustment, error) {
+	var adjustments []*domain.WalletAdjustment
+	err := r.db.WithContext(ctx).
+		Where("status = ?", domain.WalletAdjustmentPending).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&adjustments).Error
+	return adjustments, err
+}
+
+func (r *walletAdjustmentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.WalletAdjustment{}).
+		Where("status = ? AND expires_at <= ?", domain.WalletAdjustmentPending, now).
+		Update("status", domain.WalletAdjustmentExpired)
+	return result.RowsAffected, result.Error
+}
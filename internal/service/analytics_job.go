@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AnalyticsJob recomputes booking analytics once a night, at Hour local time.
+type AnalyticsJob struct {
+	busyness BusynessService
+	hour     int
+	log      logger.Logger
+}
+
+func NewAnalyticsJob(busyness BusynessService, hour int, log logger.Logger) *AnalyticsJob {
+	return &AnalyticsJob{busyness: busyness, hour: hour, log: log}
+}
+
+// Start runs the job in the background until ctx is cancelled.
+func (j *AnalyticsJob) Start(ctx context.Context) {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextAnalyticsRun(time.Now(), j.hour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case now := <-timer.C:
+				j.Run(ctx, now)
+			}
+		}
+	}()
+}
+
+// Run performs one pass of the job.
+func (j *AnalyticsJob) Run(ctx context.Context, now time.Time) {
+	started := time.Now()
+	if err := j.busyness.RecomputeAll(ctx, now); err != nil {
+		j.log.Warn("analytics job: busyness recompute failed", zap.Error(err))
+		return
+	}
+	j.log.Info("analytics job finished", zap.Duration("took", time.Since(started)))
+}
+
+// nextAnalyticsRun returns the first time after now that falls on hour.
+func nextAnalyticsRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+var _ repository.APIKeyRepository = (*MockAPIKeyRepository)(nil)
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*domain.APIKey, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, restaurantID, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, restaurantID, id, at)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func setupAPIKeyService(restaurant *domain.Restaurant) (APIKeyService, *MockAPIKeyRepository) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil).Maybe()
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+	return NewAPIKeyService(apiKeyRepo, restaurantRepo, authz, NewLogAuditRecorder(zap.NewNop()), zap.NewNop()), apiKeyRepo
+}
+
+func TestCreateAPIKey_StoresOnlyHash(t *testing.T) {
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	service, apiKeyRepo := setupAPIKeyService(restaurant)
+
+	var stored *domain.APIKey
+	apiKeyRepo.On("Create", ctx, mock.AnythingOfType("*domain.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.APIKey) }).
+		Return(nil)
+
+	key, plainKey, err := service.CreateKey(ctx, restaurant.ID, restaurant.OwnerID, "  POS  ")
+
+	require.NoError(t, err)
+	assert.Same(t, stored, key)
+	assert.True(t, strings.HasPrefix(plainKey, apiKeyPrefix))
+	assert.Equal(t, "POS", key.Name)
+	assert.Equal(t, restaurant.ID, key.RestaurantID)
+	assert.Equal(t, plainKey[:apiKeyDisplayLength], key.Prefix)
+	assert.Equal(t, hashAPIKey(plainKey), key.KeyHash)
+	assert.NotContains(t, key.KeyHash, plainKey)
+}
+
+func TestCreateAPIKey_NotOwner(t *testing.T) {
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	service, apiKeyRepo := setupAPIKeyService(restaurant)
+
+	_, _, err := service.CreateKey(ctx, restaurant.ID, uuid.New(), "POS")
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	apiKeyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey_EmptyName(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	service, _ := setupAPIKeyService(restaurant)
+
+	_, _, err := service.CreateKey(context.Background(), restaurant.ID, restaurant.OwnerID, " ")
+
+	assert.Equal(t, ErrAPIKeyNameNeeded, err)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New()}
+	service, apiKeyRepo := setupAPIKeyService(restaurant)
+
+	plainKey := apiKeyPrefix + "valid"
+	stored := &domain.APIKey{ID: uuid.New(), RestaurantID: restaurant.ID}
+	apiKeyRepo.On("GetActiveByHash", ctx, hashAPIKey(plainKey)).Return(stored, nil)
+	apiKeyRepo.On("GetActiveByHash", ctx, hashAPIKey(apiKeyPrefix+"revoked")).Return(nil, gorm.ErrRecordNotFound)
+	apiKeyRepo.On("MarkUsed", ctx, stored.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+	key, err := service.Authenticate(ctx, plainKey)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID, key.ID)
+
+	_, err = service.Authenticate(ctx, apiKeyPrefix+"revoked")
+	assert.Equal(t, ErrInvalidAPIKey, err)
+
+	_, err = service.Authenticate(ctx, "Bearer something")
+	assert.Equal(t, ErrInvalidAPIKey, err)
+
+	apiKeyRepo.AssertNumberOfCalls(t, "MarkUsed", 1)
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	service, apiKeyRepo := setupAPIKeyService(restaurant)
+
+	keyID := uuid.New()
+	apiKeyRepo.On("Revoke", ctx, restaurant.ID, keyID, mock.AnythingOfType("time.Time")).Return(gorm.ErrRecordNotFound)
+
+	err := service.RevokeKey(ctx, restaurant.ID, keyID, restaurant.OwnerID)
+
+	assert.Equal(t, ErrAPIKeyNotFound, err)
+}
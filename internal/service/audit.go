@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Audited actions. Admin overrides use the action passed to the
+// RestaurantAuthorizer instead.
+const (
+	AuditActionLogin          = "auth.login"
+	AuditActionLoginFailed    = "auth.login_failed"
+	AuditActionTokenRefresh   = "auth.token_refresh"
+	AuditActionTokenReuse     = "auth.token_reuse"
+	AuditActionPasswordChange = "auth.password_change"
+	AuditActionDeposit        = "wallet.deposit"
+	AuditActionWithdraw       = "wallet.withdraw"
+	AuditActionWalletRefund   = "wallet.refund"
+	AuditActionPaymentRefund  = "payment.refund"
+	AuditActionAPIKeyCreate   = "api_key.create"
+	AuditActionAPIKeyRevoke   = "api_key.revoke"
+	AuditActionStaffPinSet    = "staff_pin.set"
+	// AuditActionStaffAction is a mutating request made from a shared
+	// device, attributed to the staff member who entered their PIN.
+	AuditActionStaffAction = "staff.action"
+
+	AuditActionSponsoredCreate = "sponsored_placement.create"
+	AuditActionSponsoredUpdate = "sponsored_placement.update"
+	AuditActionSponsoredDelete = "sponsored_placement.delete"
+
+	AuditActionWalletAdjustRequest = "wallet.adjustment_request"
+	AuditActionWalletAdjustApply   = "wallet.adjustment_apply"
+	AuditActionWalletAdjustReject  = "wallet.adjustment_reject"
+
+	AuditActionJobStart = "background_job.start"
+
+	AuditActionOwnershipTransfer = "restaurant.ownership_transfer"
+
+	AuditActionRestaurantApprove = "restaurant.approve"
+	AuditActionRestaurantReject  = "restaurant.reject"
+
+	AuditActionBookingModify = "booking.modify"
+)
+
+// AuditSeverityHigh marks, in an entry's "severity" metadata, events that
+// point at an attack and deserve a look.
+const AuditSeverityHigh = "high"
+
+// AuditEntry describes a security or money related action. ActorID is
+// uuid.Nil when the actor is unknown. IPAddress and UserAgent default to the
+// request info in the context. On a shared device the staff attribution in
+// the context replaces ActorID; see withStaffAttribution.
+type AuditEntry struct {
+	ActorID       uuid.UUID
+	Action        string
+	TargetType    string
+	TargetID      uuid.UUID
+	AdminOverride bool
+	IPAddress     string
+	UserAgent     string
+	Metadata      map[string]interface{}
+	CreatedAt     time.Time
+}
+
+type AuditRecorder interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// RequestInfo identifies where a request came from, for the audit trail.
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type requestInfoContextKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoContextKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoContextKey{}).(RequestInfo)
+	return info, ok
+}
+
+type logAuditRecorder struct {
+	log logger.Logger
+}
+
+// NewLogAuditRecorder writes audit entries to the application log.
+func NewLogAuditRecorder(log logger.Logger) AuditRecorder {
+	return &logAuditRecorder{log: log}
+}
+
+func (r *logAuditRecorder) Record(ctx context.Context, entry AuditEntry) error {
+	entry = withStaffAttribution(ctx, withRequestInfo(ctx, entry))
+	r.log.Info("audit",
+		zap.String("actor_id", entry.ActorID.String()),
+		zap.String("action", entry.Action),
+		zap.String("target_type", entry.TargetType),
+		zap.String("target_id", entry.TargetID.String()),
+		zap.Bool("admin_override", entry.AdminOverride),
+		zap.String("ip_address", entry.IPAddress),
+		zap.Any("metadata", entry.Metadata),
+		zap.Time("created_at", entry.CreatedAt),
+	)
+	return nil
+}
+
+type repositoryAuditRecorder struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewRepositoryAuditRecorder stores audit entries in the audit_logs table.
+func NewRepositoryAuditRecorder(auditRepo repository.AuditRepository) AuditRecorder {
+	return &repositoryAuditRecorder{auditRepo: auditRepo}
+}
+
+func (r *repositoryAuditRecorder) Record(ctx context.Context, entry AuditEntry) error {
+	entry = withStaffAttribution(ctx, withRequestInfo(ctx, entry))
+
+	metadata := entry.Metadata
+	if entry.AdminOverride {
+		metadata = make(map[string]interface{}, len(entry.Metadata)+1)
+		for k, v := range entry.Metadata {
+			metadata[k] = v
+		}
+		metadata["admin_override"] = true
+	}
+
+	record := &domain.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		Metadata:   metadata,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.ActorID != uuid.Nil {
+		actorID := entry.ActorID
+		record.ActorID = &actorID
+	}
+	if entry.TargetID != uuid.Nil {
+		targetID := entry.TargetID
+		record.TargetID = &targetID
+	}
+
+	return r.auditRepo.Create(ctx, record)
+}
+
+func withRequestInfo(ctx context.Context, entry AuditEntry) AuditEntry {
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		if entry.IPAddress == "" {
+			entry.IPAddress = info.IPAddress
+		}
+		if entry.UserAgent == "" {
+			entry.UserAgent = info.UserAgent
+		}
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return entry
+}
+
+// withStaffAttribution credits an entry made by the account a shared device
+// is logged in with to the staff member who entered their PIN. The account
+// and the device stay in the metadata. Entries without an actor, or already
+// made by that staff member, are left alone.
+func withStaffAttribution(ctx context.Context, entry AuditEntry) AuditEntry {
+	attribution, ok := StaffAttributionFromContext(ctx)
+	if !ok || entry.ActorID == uuid.Nil || entry.ActorID == attribution.StaffID {
+		return entry
+	}
+
+	metadata := make(map[string]interface{}, len(entry.Metadata)+2)
+	for k, v := range entry.Metadata {
+		metadata[k] = v
+	}
+	metadata["shared_session_user_id"] = entry.ActorID.String()
+	metadata["shared_device_id"] = attribution.DeviceID.String()
+
+	entry.Metadata = metadata
+	entry.ActorID = attribution.StaffID
+	return entry
+}
+
+// recordAudit is for events where a lost audit entry must not fail the
+// action itself, like a login or a deposit that already happened.
+func recordAudit(ctx context.Context, audit AuditRecorder, log logger.Logger, entry AuditEntry) {
+	if err := audit.Record(ctx, entry); err != nil {
+		log.Warn("failed to record audit entry", zap.String("action", entry.Action), zap.Error(err))
+	}
+}
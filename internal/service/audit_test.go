@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*domain.AuditLog, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]*domain.AuditLog), args.Get(1).(int64), args.Error(2)
+}
+
+func TestRepositoryAuditRecorder_UsesRequestInfo(t *testing.T) {
+	repo := new(MockAuditRepository)
+	recorder := NewRepositoryAuditRecorder(repo)
+	ctx := WithRequestInfo(context.Background(), RequestInfo{IPAddress: "10.0.0.7", UserAgent: "curl/8.0"})
+	userID := uuid.New()
+
+	var saved *domain.AuditLog
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+
+	err := recorder.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		Action:     AuditActionPasswordChange,
+		TargetType: "user",
+		TargetID:   userID,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, userID, *saved.ActorID)
+	assert.Equal(t, "10.0.0.7", saved.IPAddress)
+	assert.Equal(t, "curl/8.0", saved.UserAgent)
+	assert.False(t, saved.CreatedAt.IsZero())
+}
+
+func TestRepositoryAuditRecorder_UnknownActorAndOverride(t *testing.T) {
+	repo := new(MockAuditRepository)
+	recorder := NewRepositoryAuditRecorder(repo)
+	ctx := context.Background()
+
+	var saved *domain.AuditLog
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+
+	metadata := map[string]interface{}{"reason": "unknown_email"}
+	err := recorder.Record(ctx, AuditEntry{
+		Action:        AuditActionLoginFailed,
+		AdminOverride: true,
+		Metadata:      metadata,
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, saved.ActorID)
+	assert.Nil(t, saved.TargetID)
+	assert.Equal(t, true, saved.Metadata["admin_override"])
+	assert.NotContains(t, metadata, "admin_override")
+}
+
+func TestRepositoryAuditRecorder_AttributesSharedDeviceToStaff(t *testing.T) {
+	repo := new(MockAuditRepository)
+	recorder := NewRepositoryAuditRecorder(repo)
+	staffID, deviceID, sessionUserID := uuid.New(), uuid.New(), uuid.New()
+	ctx := WithStaffAttribution(context.Background(), StaffAttribution{StaffID: staffID, DeviceID: deviceID})
+
+	var saved *domain.AuditLog
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*domain.AuditLog) }).
+		Return(nil)
+
+	metadata := map[string]interface{}{"status": "confirmed"}
+	err := recorder.Record(ctx, AuditEntry{
+		ActorID:    sessionUserID,
+		Action:     AuditActionStaffAction,
+		TargetType: "restaurant",
+		TargetID:   uuid.New(),
+		Metadata:   metadata,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, staffID, *saved.ActorID)
+	assert.Equal(t, sessionUserID.String(), saved.Metadata["shared_session_user_id"])
+	assert.Equal(t, deviceID.String(), saved.Metadata["shared_device_id"])
+	assert.Equal(t, "confirmed", saved.Metadata["status"])
+	assert.NotContains(t, metadata, "shared_session_user_id")
+}
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"regexp"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/googleauth"
 	"restaurant-booking/pkg/jwt"
 	"restaurant-booking/pkg/logger"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,41 +23,105 @@ var (
 	ErrInvalidEmail        = errors.New("invalid email format")
 	ErrInvalidPassword     = errors.New("password must be at least 8 characters")
 	ErrEmailExists         = errors.New("email already exists")
+	ErrRoleNotAllowed      = errors.New("only customer and owner accounts can be registered")
 	ErrInvalidCredentials  = errors.New("invalid email or password")
 	ErrUserNotFound        = errors.New("user not found")
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
 	ErrExpiredRefreshToken = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	ErrExpiredVerificationToken = errors.New("verification token has expired")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationCooldown     = errors.New("verification email was sent recently, please wait before requesting another")
+
+	ErrAccountLocked        = errors.New("account is temporarily locked due to too many failed login attempts")
+	ErrAccountDeactivated   = errors.New("account is deactivated")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
+
+	ErrOAuthAccount            = errors.New("this account uses Google sign-in, please log in with Google")
+	ErrInvalidGoogleToken      = errors.New("invalid google id token")
+	ErrGoogleEmailNotVerified  = errors.New("google account email is not verified")
+	ErrGoogleLoginNotAvailable = errors.New("google sign-in is not configured")
+)
+
+const (
+	emailVerificationTokenTTL = 24 * time.Hour
+	emailVerificationCooldown = 2 * time.Minute
 )
 
 type AuthService interface {
-	Register(email, password, firstName, lastName string, phone string, role domain.UserRole) (*domain.User, string, string, error)
-	Login(email, password string) (string, string, *domain.User, error)
-	RefreshToken(refreshToken string) (string, string, error)
-	Logout(refreshToken string) error
+	// Register creates a password account. role must be customer or owner;
+	// managers join through invitations and admins are never self-registered.
+	// An unsupported locale falls back to domain.DefaultLocale.
+	Register(email, password, firstName, lastName string, phone string, role domain.UserRole, locale domain.Locale) (*domain.User, string, string, error)
+	Login(ctx context.Context, email, password, ipAddress string) (string, string, *domain.User, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	LoginWithGoogle(ctx context.Context, idToken string) (string, string, *domain.User, error)
+	// Logout revokes the refresh token and, when given, the access token
+	// the request was made with.
+	Logout(refreshToken string, accessToken *jwt.Claims) error
+	LogoutAll(userID uuid.UUID) (int64, error)
+	SetupTwoFactor(userID uuid.UUID) (string, string, error)
+	EnableTwoFactor(userID uuid.UUID, code string) ([]string, error)
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, *domain.User, error)
+	VerifyEmail(token string) error
+	ResendVerification(userID uuid.UUID) error
 }
 
 type authService struct {
-	userRepo         repository.UserRepository
-	refreshTokenRepo repository.RefreshTokenRepository
-	jwtManager       *jwt.Manager
-	log              logger.Logger
+	userRepo              repository.UserRepository
+	refreshTokenRepo      repository.RefreshTokenRepository
+	verificationTokenRepo repository.EmailVerificationTokenRepository
+	recoveryCodeRepo      repository.TwoFactorRecoveryCodeRepository
+	notificationSvc       *NotificationService
+	loginAttempts         LoginAttemptStore
+	loginPolicy           LoginPolicy
+	tokenBlacklist        TokenBlacklist
+	audit                 AuditRecorder
+	googleVerifier        googleauth.Verifier
+	phoneCountryCode      string
+	jwtManager            *jwt.Manager
+	log                   logger.Logger
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
+	verificationTokenRepo repository.EmailVerificationTokenRepository,
+	recoveryCodeRepo repository.TwoFactorRecoveryCodeRepository,
+	notificationSvc *NotificationService,
+	loginAttempts LoginAttemptStore,
+	loginPolicy LoginPolicy,
+	tokenBlacklist TokenBlacklist,
+	audit AuditRecorder,
+	googleVerifier googleauth.Verifier,
+	phoneCountryCode string,
 	jwtManager *jwt.Manager,
 	log logger.Logger,
 ) AuthService {
 	return &authService{
-		userRepo:         userRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		jwtManager:       jwtManager,
-		log:              log,
+		userRepo:              userRepo,
+		refreshTokenRepo:      refreshTokenRepo,
+		verificationTokenRepo: verificationTokenRepo,
+		recoveryCodeRepo:      recoveryCodeRepo,
+		notificationSvc:       notificationSvc,
+		loginAttempts:         loginAttempts,
+		loginPolicy:           loginPolicy,
+		tokenBlacklist:        tokenBlacklist,
+		audit:                 audit,
+		googleVerifier:        googleVerifier,
+		phoneCountryCode:      phoneCountryCode,
+		jwtManager:            jwtManager,
+		log:                   log,
 	}
 }
 
-func (s *authService) Register(email, password, firstName, lastName string, phone string, role domain.UserRole) (*domain.User, string, string, error) {
+func (s *authService) Register(email, password, firstName, lastName string, phone string, role domain.UserRole, locale domain.Locale) (*domain.User, string, string, error) {
+
+	if role != domain.UserRoleCustomer && role != domain.UserRoleOwner {
+		return nil, "", "", ErrRoleNotAllowed
+	}
 
 	if !isValidEmail(email) {
 		s.log.Warn("invalid email address", zap.Error(ErrInvalidEmail))
@@ -64,7 +132,12 @@ func (s *authService) Register(email, password, firstName, lastName string, phon
 		return nil, "", "", ErrInvalidPassword
 	}
 
-	_, err := s.userRepo.GetByEmail(email)
+	phone, err := normalizePhone(phone, s.phoneCountryCode)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	_, err = s.userRepo.GetByEmail(email)
 	if err == nil {
 		return nil, "", "", ErrEmailExists
 	}
@@ -78,87 +151,257 @@ func (s *authService) Register(email, password, firstName, lastName string, phon
 	}
 
 	user := &domain.User{
-		ID:        uuid.New(),
-		Email:     email,
-		Password:  string(hashedPassword),
-		FirstName: firstName,
-		LastName:  lastName,
-		Phone:     phone,
-		Role:      role,
-		IsActive:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:           uuid.New(),
+		Email:        email,
+		Password:     string(hashedPassword),
+		FirstName:    firstName,
+		LastName:     lastName,
+		Phone:        phone,
+		Role:         role,
+		IsActive:     true,
+		AuthProvider: domain.AuthProviderPassword,
+		Locale:       supportedLocale(locale),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, "", "", err
 	}
 
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Role)
+	if err := s.sendVerificationEmail(user); err != nil {
+		s.log.Warn("failed to send verification email", zap.String("user_id", user.ID.String()), zap.Error(err))
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(user)
 	if err != nil {
 		return nil, "", "", err
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken()
+	return user, accessToken, refreshToken, nil
+}
+
+func (s *authService) Login(ctx context.Context, email, password, ipAddress string) (string, string, *domain.User, error) {
+	emailKey := "email:" + strings.ToLower(strings.TrimSpace(email))
+	ipKey := "ip:" + ipAddress
+
+	if s.isLoginLocked(emailKey) {
+		s.auditLoginFailure(ctx, nil, email, "account_locked")
+		return "", "", nil, ErrAccountLocked
+	}
+	if ipAddress != "" && s.isLoginLocked(ipKey) {
+		s.auditLoginFailure(ctx, nil, email, "ip_throttled")
+		return "", "", nil, ErrTooManyLoginAttempts
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
-		return nil, "", "", err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.auditLoginFailure(ctx, nil, email, "unknown_email")
+			return "", "", nil, s.recordLoginFailure(emailKey, ipKey, ipAddress != "")
+		}
+		return "", "", nil, err
 	}
 
-	refreshTokenEntity := &domain.RefreshToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(s.jwtManager.GetRefreshExpire()),
-		CreatedAt: time.Now(),
+	if user.AuthProvider == domain.AuthProviderGoogle {
+		return "", "", nil, ErrOAuthAccount
 	}
 
-	if err := s.refreshTokenRepo.Create(refreshTokenEntity); err != nil {
-		return nil, "", "", err
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		s.auditLoginFailure(ctx, user, email, "wrong_password")
+		return "", "", nil, s.recordLoginFailure(emailKey, ipKey, ipAddress != "")
 	}
 
-	return user, accessToken, refreshToken, nil
+	// Checked after the password so the error does not reveal which
+	// accounts exist.
+	if !user.IsActive {
+		s.auditLoginFailure(ctx, user, email, "account_deactivated")
+		return "", "", nil, ErrAccountDeactivated
+	}
+
+	// Only the account counter is cleared: resetting the IP counter would let
+	// an attacker wipe it by logging into an account they control.
+	if err := s.loginAttempts.Reset(emailKey); err != nil {
+		s.log.Warn("failed to reset login attempts", zap.String("key", emailKey), zap.Error(err))
+	}
+
+	if user.TwoFactorEnabled {
+		return "", "", nil, s.issueTwoFactorChallenge(user)
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(user)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	s.auditLogin(ctx, user, "password")
+	return accessToken, refreshToken, user, nil
 }
 
-func (s *authService) Login(email, password string) (string, string, *domain.User, error) {
+// LoginWithGoogle verifies a Google ID token and signs in the user with the
+// matching email, creating the account on first sign-in. A matching password
+// account is switched to Google sign-in, unless it has 2FA on: a Google
+// account does not stand in for the second factor, so such accounts get the
+// same challenge as Login and keep their password.
+func (s *authService) LoginWithGoogle(ctx context.Context, idToken string) (string, string, *domain.User, error) {
+	if s.googleVerifier == nil {
+		return "", "", nil, ErrGoogleLoginNotAvailable
+	}
 
-	user, err := s.userRepo.GetByEmail(email)
+	claims, err := s.googleVerifier.Verify(ctx, idToken)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", "", nil, ErrInvalidCredentials
+		s.log.Warn("google id token rejected", zap.Error(err))
+		return "", "", nil, ErrInvalidGoogleToken
+	}
+
+	if !claims.EmailVerified {
+		return "", "", nil, ErrGoogleEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetByEmail(claims.Email)
+	switch {
+	case err == nil:
+		if user.GoogleID != nil && *user.GoogleID != claims.Subject {
+			return "", "", nil, ErrInvalidGoogleToken
+		}
+		if !user.IsActive {
+			return "", "", nil, ErrAccountDeactivated
+		}
+		if user.TwoFactorEnabled {
+			return "", "", nil, s.issueTwoFactorChallenge(user)
+		}
+		if user.AuthProvider != domain.AuthProviderGoogle || user.GoogleID == nil || !user.EmailVerified {
+			user.AuthProvider = domain.AuthProviderGoogle
+			user.GoogleID = &claims.Subject
+			user.EmailVerified = true
+			if err := s.userRepo.Update(user); err != nil {
+				return "", "", nil, err
+			}
 		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user = newGoogleUser(claims)
+		if err := s.userRepo.Create(user); err != nil {
+			return "", "", nil, err
+		}
+	default:
 		return "", "", nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", "", nil, ErrInvalidCredentials
+	accessToken, refreshToken, err := s.issueTokens(user)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	s.auditLogin(ctx, user, "google")
+	return accessToken, refreshToken, user, nil
+}
+
+func newGoogleUser(claims *googleauth.Claims) *domain.User {
+	firstName := claims.GivenName
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(claims.Email, "@")
 	}
 
+	user := &domain.User{
+		ID:            uuid.New(),
+		Email:         claims.Email,
+		FirstName:     firstName,
+		LastName:      claims.FamilyName,
+		Role:          domain.UserRoleCustomer,
+		IsActive:      true,
+		EmailVerified: true,
+		AuthProvider:  domain.AuthProviderGoogle,
+		GoogleID:      &claims.Subject,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if claims.Picture != "" {
+		user.Avatar = &claims.Picture
+	}
+
+	return user
+}
+
+// issueTokens creates an access token and a stored refresh token for user.
+func (s *authService) issueTokens(user *domain.User) (string, string, error) {
 	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Role)
 	if err != nil {
-		return "", "", nil, err
+		return "", "", err
 	}
 
 	refreshToken, err := s.jwtManager.GenerateRefreshToken()
 	if err != nil {
-		return "", "", nil, err
+		return "", "", err
 	}
 
+	// Every login starts a new token family.
+	id := uuid.New()
 	refreshTokenEntity := &domain.RefreshToken{
-		ID:        uuid.New(),
+		ID:        id,
 		UserID:    user.ID,
 		Token:     refreshToken,
+		FamilyID:  id,
 		ExpiresAt: time.Now().Add(s.jwtManager.GetRefreshExpire()),
 		CreatedAt: time.Now(),
 	}
 
 	if err := s.refreshTokenRepo.Create(refreshTokenEntity); err != nil {
-		return "", "", nil, err
+		return "", "", err
 	}
 
-	return accessToken, refreshToken, user, nil
+	return accessToken, refreshToken, nil
+}
+
+func (s *authService) isLoginLocked(key string) bool {
+	until, err := s.loginAttempts.LockedUntil(key)
+	if err != nil {
+		s.log.Warn("failed to read login lock", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return time.Now().Before(until)
+}
+
+// recordLoginFailure counts a failed login against the email and, when known,
+// the client IP, and returns the error the caller should report.
+func (s *authService) recordLoginFailure(emailKey, ipKey string, withIP bool) error {
+	now := time.Now()
+	result := ErrInvalidCredentials
+
+	if withIP {
+		if s.addLoginFailure(ipKey, now, s.loginPolicy.MaxAttemptsPerIP) {
+			s.log.Warn("login throttled for ip", zap.String("key", ipKey))
+			result = ErrTooManyLoginAttempts
+		}
+	}
+
+	if s.addLoginFailure(emailKey, now, s.loginPolicy.MaxAttempts) {
+		s.log.Warn("account locked after failed logins", zap.String("key", emailKey))
+		result = ErrAccountLocked
+	}
+
+	return result
 }
 
-func (s *authService) RefreshToken(refreshToken string) (string, string, error) {
+// addLoginFailure records a failure for key and locks it once the policy
+// limit is reached. It reports whether the key was locked.
+func (s *authService) addLoginFailure(key string, now time.Time, limit int) bool {
+	failures, err := s.loginAttempts.AddFailure(key, now, s.loginPolicy.Window)
+	if err != nil {
+		s.log.Warn("failed to record login attempt", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	if limit <= 0 || failures < limit {
+		return false
+	}
+
+	if err := s.loginAttempts.Lock(key, now.Add(s.loginPolicy.LockoutDuration)); err != nil {
+		s.log.Warn("failed to lock login", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
 
 	tokenEntity, err := s.refreshTokenRepo.GetByToken(refreshToken)
 	if err != nil {
@@ -168,6 +411,10 @@ func (s *authService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", err
 	}
 
+	if tokenEntity.ReplacedBy != nil {
+		return "", "", s.revokeReusedToken(ctx, tokenEntity)
+	}
+
 	if time.Now().After(tokenEntity.ExpiresAt) {
 
 		_ = s.refreshTokenRepo.DeleteByToken(refreshToken)
@@ -178,6 +425,9 @@ func (s *authService) RefreshToken(refreshToken string) (string, string, error)
 	if err != nil {
 		return "", "", err
 	}
+	if !user.IsActive {
+		return "", "", ErrAccountDeactivated
+	}
 
 	newAccessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Role)
 	if err != nil {
@@ -189,14 +439,12 @@ func (s *authService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", err
 	}
 
-	if err := s.refreshTokenRepo.DeleteByToken(refreshToken); err != nil {
-		return "", "", err
-	}
-
 	newTokenEntity := &domain.RefreshToken{
 		ID:        uuid.New(),
 		UserID:    user.ID,
 		Token:     newRefreshToken,
+		FamilyID:  tokenEntity.FamilyID,
+		ParentID:  &tokenEntity.ID,
 		ExpiresAt: time.Now().Add(s.jwtManager.GetRefreshExpire()),
 		CreatedAt: time.Now(),
 	}
@@ -205,11 +453,230 @@ func (s *authService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", err
 	}
 
+	// The old token is kept, marked as replaced, so a later replay of it can
+	// be told apart from a token that never existed.
+	replaced, err := s.refreshTokenRepo.MarkReplaced(tokenEntity.ID, newTokenEntity.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if !replaced {
+		// Another request rotated the same token first.
+		return "", "", s.revokeReusedToken(ctx, tokenEntity)
+	}
+
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    user.ID,
+		Action:     AuditActionTokenRefresh,
+		TargetType: "refresh_token",
+		TargetID:   tokenEntity.ID,
+	})
 	return newAccessToken, newRefreshToken, nil
 }
 
-func (s *authService) Logout(refreshToken string) error {
-	return s.refreshTokenRepo.DeleteByToken(refreshToken)
+func (s *authService) auditLogin(ctx context.Context, user *domain.User, method string) {
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    user.ID,
+		Action:     AuditActionLogin,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   map[string]interface{}{"method": method},
+	})
+}
+
+// auditLoginFailure records a rejected login. user is nil when the email
+// does not belong to an account.
+func (s *authService) auditLoginFailure(ctx context.Context, user *domain.User, email, reason string) {
+	entry := AuditEntry{
+		Action:     AuditActionLoginFailed,
+		TargetType: "user",
+		Metadata:   map[string]interface{}{"email": email, "reason": reason},
+	}
+	if user != nil {
+		entry.ActorID = user.ID
+		entry.TargetID = user.ID
+	}
+	recordAudit(ctx, s.audit, s.log, entry)
+}
+
+// revokeReusedToken handles a rotated refresh token being presented again.
+// Either the client or an attacker holds a stale copy, and there is no way to
+// tell which, so the whole token family dies: the attacker and the victim
+// both have to log in again. Access tokens of every session are revoked too,
+// since the one issued to the attacker cannot be singled out. Other logins
+// keep their refresh tokens.
+func (s *authService) revokeReusedToken(ctx context.Context, token *domain.RefreshToken) error {
+	var revoked int64
+	var err error
+	if token.FamilyID == uuid.Nil {
+		// Tokens issued before families existed cannot be traced to a login.
+		revoked, err = s.refreshTokenRepo.DeleteAllByUserID(token.UserID)
+	} else {
+		revoked, err = s.refreshTokenRepo.DeleteByFamilyID(token.FamilyID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The attacker's access token may have been issued within this very
+	// second, so the cutoff includes it; nobody of the family is logged in
+	// again yet.
+	now := time.Now()
+	if err := s.tokenBlacklist.RevokeUser(token.UserID, now.Add(time.Second), now.Add(s.jwtManager.GetAccessExpire())); err != nil {
+		return err
+	}
+
+	s.log.Warn("security: refresh token reuse detected, token family revoked",
+		zap.String("user_id", token.UserID.String()),
+		zap.String("token_id", token.ID.String()),
+		zap.String("family_id", token.FamilyID.String()),
+		zap.Int64("tokens_revoked", revoked))
+
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    token.UserID,
+		Action:     AuditActionTokenReuse,
+		TargetType: "refresh_token",
+		TargetID:   token.ID,
+		Metadata: map[string]interface{}{
+			"severity":       AuditSeverityHigh,
+			"family_id":      token.FamilyID.String(),
+			"tokens_revoked": revoked,
+		},
+	})
+
+	s.sendTokenReuseAlert(token.UserID)
+
+	return ErrRefreshTokenReused
+}
+
+// sendTokenReuseAlert tells the user their session was revoked. Failing to
+// send it does not undo the revocation.
+func (s *authService) sendTokenReuseAlert(userID uuid.UUID) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		s.log.Warn("failed to load user for token reuse alert", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+
+	err = s.notificationSvc.SendEmail(
+		user.Email,
+		"Security alert: you were signed out",
+		"An old sign-in token for your account was used again, so we signed out the session it belonged to. "+
+			"If you did not expect this, change your password.",
+	)
+	if err != nil {
+		s.log.Warn("failed to send token reuse alert", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+func (s *authService) Logout(refreshToken string, accessToken *jwt.Claims) error {
+	if err := s.refreshTokenRepo.DeleteByToken(refreshToken); err != nil {
+		return err
+	}
+
+	if accessToken == nil || accessToken.ID == "" || accessToken.ExpiresAt == nil {
+		return nil
+	}
+	return s.tokenBlacklist.Revoke(accessToken.ID, accessToken.ExpiresAt.Time)
+}
+
+// LogoutAll revokes every refresh token of the user and returns how many
+// sessions were terminated. Access tokens issued so far are rejected until
+// the longest of them would have expired.
+func (s *authService) LogoutAll(userID uuid.UUID) (int64, error) {
+	terminated, err := s.refreshTokenRepo.DeleteAllByUserID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if err := s.tokenBlacklist.RevokeUser(userID, now, now.Add(s.jwtManager.GetAccessExpire())); err != nil {
+		return 0, err
+	}
+	return terminated, nil
+}
+
+func (s *authService) VerifyEmail(token string) error {
+	tokenEntity, err := s.verificationTokenRepo.GetByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if time.Now().After(tokenEntity.ExpiresAt) {
+		return ErrExpiredVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(tokenEntity.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+	}
+
+	return s.verificationTokenRepo.DeleteAllByUserID(user.ID)
+}
+
+func (s *authService) ResendVerification(userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := s.verificationTokenRepo.GetLatestByUserID(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < emailVerificationCooldown {
+		return ErrVerificationCooldown
+	}
+
+	if err := s.verificationTokenRepo.DeleteAllByUserID(user.ID); err != nil {
+		return err
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *authService) sendVerificationEmail(user *domain.User) error {
+	token, err := s.jwtManager.GenerateRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	tokenEntity := &domain.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(emailVerificationTokenTTL),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.verificationTokenRepo.Create(tokenEntity); err != nil {
+		return err
+	}
+
+	return s.notificationSvc.SendEmail(
+		user.Email,
+		"Verify your email",
+		fmt.Sprintf("Use this token to verify your email address: %s", token),
+	)
 }
 
 func isValidEmail(email string) bool {
@@ -1,8 +1,11 @@
 package service
 
 import (
-	_ "errors"
+	"context"
+	"errors"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/googleauth"
 	"restaurant-booking/pkg/jwt"
 	"testing"
 	"time"
@@ -10,6 +13,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -49,6 +54,14 @@ func (m *MockUserRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) List(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.User), args.Get(1).(int64), args.Error(2)
+}
+
 type MockRefreshTokenRepository struct {
 	mock.Mock
 }
@@ -71,23 +84,79 @@ func (m *MockRefreshTokenRepository) DeleteByToken(token string) error {
 	return args.Error(0)
 }
 
-func (m *MockRefreshTokenRepository) DeleteAllByUserID(userID uuid.UUID) error {
+func (m *MockRefreshTokenRepository) MarkReplaced(id, replacedBy uuid.UUID) (bool, error) {
+	args := m.Called(id, replacedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) DeleteAllByUserID(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) DeleteByFamilyID(familyID uuid.UUID) (int64, error) {
+	args := m.Called(familyID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockEmailVerificationTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationTokenRepository) Create(token *domain.EmailVerificationToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationTokenRepository) GetByToken(token string) (*domain.EmailVerificationToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationTokenRepository) GetLatestByUserID(userID uuid.UUID) (*domain.EmailVerificationToken, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationTokenRepository) DeleteAllByUserID(userID uuid.UUID) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
 
 func setupAuthService() (*authService, *MockUserRepository, *MockRefreshTokenRepository) {
+	service, mockUserRepo, mockRefreshRepo, _ := setupAuthServiceWithVerification()
+	return service, mockUserRepo, mockRefreshRepo
+}
+
+func setupAuthServiceWithVerification() (*authService, *MockUserRepository, *MockRefreshTokenRepository, *MockEmailVerificationTokenRepository) {
 	mockUserRepo := new(MockUserRepository)
 	mockRefreshRepo := new(MockRefreshTokenRepository)
+	mockVerificationRepo := new(MockEmailVerificationTokenRepository)
 	jwtManager := jwt.NewManager("test-secret", time.Hour, time.Hour*24)
 
+	mockVerificationRepo.On("Create", mock.AnythingOfType("*domain.EmailVerificationToken")).Return(nil).Maybe()
+
 	service := &authService{
-		userRepo:         mockUserRepo,
-		refreshTokenRepo: mockRefreshRepo,
-		jwtManager:       jwtManager,
+		userRepo:              mockUserRepo,
+		refreshTokenRepo:      mockRefreshRepo,
+		verificationTokenRepo: mockVerificationRepo,
+		notificationSvc:       NewNotificationService(1, 10),
+		loginAttempts:         NewInMemoryLoginAttemptStore(),
+		loginPolicy:           DefaultLoginPolicy(),
+		tokenBlacklist:        NewInMemoryTokenBlacklist(),
+		audit:                 NewLogAuditRecorder(zap.NewNop()),
+		phoneCountryCode:      "7",
+		jwtManager:            jwtManager,
+		log:                   zap.NewNop(),
 	}
 
-	return service, mockUserRepo, mockRefreshRepo
+	return service, mockUserRepo, mockRefreshRepo, mockVerificationRepo
 }
 
 func TestRegister_Success(t *testing.T) {
@@ -97,14 +166,14 @@ func TestRegister_Success(t *testing.T) {
 	password := "password123"
 	firstName := "Test"
 	lastName := "User"
-	phone := "1234567890"
+	phone := "8 (700) 123-45-67"
 	role := domain.UserRoleCustomer
 
 	mockUserRepo.On("GetByEmail", email).Return(nil, gorm.ErrRecordNotFound)
 	mockUserRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(nil)
 	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
-	user, accessToken, refreshToken, err := service.Register(email, password, firstName, lastName, phone, role)
+	user, accessToken, refreshToken, err := service.Register(email, password, firstName, lastName, phone, role, domain.LocaleRussian)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -113,17 +182,54 @@ func TestRegister_Success(t *testing.T) {
 	assert.Equal(t, email, user.Email)
 	assert.Equal(t, firstName, user.FirstName)
 	assert.Equal(t, lastName, user.LastName)
-	assert.Equal(t, phone, user.Phone)
+	assert.Equal(t, "+77001234567", user.Phone)
 	assert.Equal(t, role, user.Role)
+	assert.Equal(t, domain.LocaleRussian, user.Locale)
 
 	mockUserRepo.AssertExpectations(t)
 	mockRefreshRepo.AssertExpectations(t)
 }
 
+func TestRegister_RejectsStaffRoles(t *testing.T) {
+	for _, role := range []domain.UserRole{domain.UserRoleAdmin, domain.UserRoleManager, "superuser"} {
+		service, mockUserRepo, _ := setupAuthService()
+
+		_, _, _, err := service.Register("test@example.com", "password123", "Test", "User", "1234567890", role, domain.LocaleEnglish)
+
+		assert.ErrorIs(t, err, ErrRoleNotAllowed, role)
+		mockUserRepo.AssertNotCalled(t, "Create", mock.Anything)
+	}
+}
+
+func TestRegister_InvalidPhone(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+
+	_, _, _, err := service.Register("test@example.com", "password123", "Test", "User", "12345", domain.UserRoleCustomer, domain.LocaleEnglish)
+
+	assert.Equal(t, ErrInvalidPhone, err)
+	mockUserRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestRegister_CreatesVerificationToken(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	user, _, _, err := service.Register("test@example.com", "password123", "Test", "User", "1234567890", domain.UserRoleCustomer, domain.LocaleEnglish)
+
+	assert.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+	mockVerificationRepo.AssertCalled(t, "Create", mock.MatchedBy(func(token *domain.EmailVerificationToken) bool {
+		return token.UserID == user.ID && token.Token != "" && token.ExpiresAt.After(time.Now())
+	}))
+}
+
 func TestRegister_InvalidEmail(t *testing.T) {
 	service, _, _ := setupAuthService()
 
-	_, _, _, err := service.Register("invalid-email", "password123", "Test", "User", "1234567890", domain.UserRoleCustomer)
+	_, _, _, err := service.Register("invalid-email", "password123", "Test", "User", "1234567890", domain.UserRoleCustomer, domain.LocaleEnglish)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidEmail, err)
@@ -132,7 +238,7 @@ func TestRegister_InvalidEmail(t *testing.T) {
 func TestRegister_ShortPassword(t *testing.T) {
 	service, _, _ := setupAuthService()
 
-	_, _, _, err := service.Register("test@example.com", "short", "Test", "User", "1234567890", domain.UserRoleCustomer)
+	_, _, _, err := service.Register("test@example.com", "short", "Test", "User", "1234567890", domain.UserRoleCustomer, domain.LocaleEnglish)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidPassword, err)
@@ -142,13 +248,14 @@ func TestRegister_EmailExists(t *testing.T) {
 	service, mockUserRepo, _ := setupAuthService()
 
 	existingUser := &domain.User{
-		ID:    uuid.New(),
-		Email: "test@example.com",
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
 	}
 
 	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
 
-	_, _, _, err := service.Register("test@example.com", "password123", "Test", "User", "1234567890", domain.UserRoleCustomer)
+	_, _, _, err := service.Register("test@example.com", "password123", "Test", "User", "1234567890", domain.UserRoleCustomer, domain.LocaleEnglish)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrEmailExists, err)
@@ -163,6 +270,7 @@ func TestLogin_Success(t *testing.T) {
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
 	existingUser := &domain.User{
+		IsActive: true,
 		ID:       uuid.New(),
 		Email:    email,
 		Password: string(hashedPassword),
@@ -172,7 +280,7 @@ func TestLogin_Success(t *testing.T) {
 	mockUserRepo.On("GetByEmail", email).Return(existingUser, nil)
 	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
-	accessToken, refreshToken, user, err := service.Login(email, password)
+	accessToken, refreshToken, user, err := service.Login(context.Background(), email, password, "127.0.0.1")
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, accessToken)
@@ -189,7 +297,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 	mockUserRepo.On("GetByEmail", "nonexistent@example.com").Return(nil, gorm.ErrRecordNotFound)
 
-	_, _, _, err := service.Login("nonexistent@example.com", "password123")
+	_, _, _, err := service.Login(context.Background(), "nonexistent@example.com", "password123", "127.0.0.1")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidCredentials, err)
@@ -201,6 +309,7 @@ func TestLogin_WrongPassword(t *testing.T) {
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.DefaultCost)
 	existingUser := &domain.User{
+		IsActive: true,
 		ID:       uuid.New(),
 		Email:    "test@example.com",
 		Password: string(hashedPassword),
@@ -209,13 +318,333 @@ func TestLogin_WrongPassword(t *testing.T) {
 
 	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
 
-	_, _, _, err := service.Login("test@example.com", "wrongpassword")
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidCredentials, err)
 	mockUserRepo.AssertExpectations(t)
 }
 
+func TestLogin_RecordsAuditEntries(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	audit := new(MockAuditRecorder)
+	service.audit = audit
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	existingUser := &domain.User{
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}
+
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
+	mockUserRepo.On("GetByEmail", "ghost@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionLoginFailed &&
+			entry.ActorID == existingUser.ID &&
+			entry.Metadata["reason"] == "wrong_password"
+	})).Return(nil).Once()
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionLoginFailed &&
+			entry.ActorID == uuid.Nil &&
+			entry.Metadata["email"] == "ghost@example.com" &&
+			entry.Metadata["reason"] == "unknown_email"
+	})).Return(nil).Once()
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionLogin &&
+			entry.ActorID == existingUser.ID &&
+			entry.Metadata["method"] == "password"
+	})).Return(nil).Once()
+
+	ctx := context.Background()
+	_, _, _, err := service.Login(ctx, "test@example.com", "wrongpassword", "127.0.0.1")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, _, _, err = service.Login(ctx, "ghost@example.com", "whatever", "127.0.0.1")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, _, _, err = service.Login(ctx, "test@example.com", "correctpassword", "127.0.0.1")
+	assert.NoError(t, err)
+
+	audit.AssertExpectations(t)
+}
+
+func TestLogin_AuditFailureDoesNotBlockLogin(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	audit := new(MockAuditRecorder)
+	service.audit = audit
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(&domain.User{
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	audit.On("Record", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "password123", "127.0.0.1")
+
+	assert.NoError(t, err)
+}
+
+func TestLogin_DeactivatedAccount(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(&domain.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}, nil)
+
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "password123", "127.0.0.1")
+
+	assert.Equal(t, ErrAccountDeactivated, err)
+	mockRefreshRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestLogin_LocksAccountAfterMaxAttempts(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	existingUser := &domain.User{
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}
+
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
+
+	for i := 0; i < service.loginPolicy.MaxAttempts-1; i++ {
+		_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
+		assert.Equal(t, ErrInvalidCredentials, err)
+	}
+
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
+	assert.Equal(t, ErrAccountLocked, err)
+
+	_, _, _, err = service.Login(context.Background(), "TEST@example.com", "correctpassword", "10.0.0.1")
+	assert.Equal(t, ErrAccountLocked, err)
+}
+
+func TestLogin_LockExpires(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	service.loginPolicy.MaxAttempts = 1
+	service.loginPolicy.LockoutDuration = 20 * time.Millisecond
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	existingUser := &domain.User{
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}
+
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
+	assert.Equal(t, ErrAccountLocked, err)
+
+	time.Sleep(30 * time.Millisecond)
+
+	_, _, user, err := service.Login(context.Background(), "test@example.com", "correctpassword", "127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, existingUser.ID, user.ID)
+}
+
+func TestLogin_SuccessResetsFailedAttempts(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	existingUser := &domain.User{
+		IsActive: true,
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: string(hashedPassword),
+		Role:     domain.UserRoleCustomer,
+	}
+
+	mockUserRepo.On("GetByEmail", "test@example.com").Return(existingUser, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	for i := 0; i < service.loginPolicy.MaxAttempts-1; i++ {
+		_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
+		assert.Equal(t, ErrInvalidCredentials, err)
+	}
+
+	_, _, _, err := service.Login(context.Background(), "test@example.com", "correctpassword", "127.0.0.1")
+	assert.NoError(t, err)
+
+	for i := 0; i < service.loginPolicy.MaxAttempts-1; i++ {
+		_, _, _, err := service.Login(context.Background(), "test@example.com", "wrongpassword", "127.0.0.1")
+		assert.Equal(t, ErrInvalidCredentials, err)
+	}
+}
+
+func TestLogin_ThrottlesIPAcrossAccounts(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+	service.loginPolicy.MaxAttemptsPerIP = 3
+
+	mockUserRepo.On("GetByEmail", mock.AnythingOfType("string")).Return(nil, gorm.ErrRecordNotFound)
+
+	_, _, _, err := service.Login(context.Background(), "user1@example.com", "password123", "10.0.0.1")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, _, _, err = service.Login(context.Background(), "user2@example.com", "password123", "10.0.0.1")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, _, _, err = service.Login(context.Background(), "user3@example.com", "password123", "10.0.0.1")
+	assert.Equal(t, ErrTooManyLoginAttempts, err)
+
+	_, _, _, err = service.Login(context.Background(), "user4@example.com", "password123", "10.0.0.1")
+	assert.Equal(t, ErrTooManyLoginAttempts, err)
+
+	_, _, _, err = service.Login(context.Background(), "user4@example.com", "password123", "10.0.0.2")
+	assert.Equal(t, ErrInvalidCredentials, err)
+}
+
+type stubGoogleVerifier struct {
+	claims *googleauth.Claims
+	err    error
+}
+
+func (v stubGoogleVerifier) Verify(ctx context.Context, idToken string) (*googleauth.Claims, error) {
+	return v.claims, v.err
+}
+
+func googleClaims() *googleauth.Claims {
+	return &googleauth.Claims{
+		Subject:       "google-sub-1",
+		Email:         "user@gmail.com",
+		EmailVerified: true,
+		GivenName:     "Google",
+		FamilyName:    "User",
+	}
+}
+
+func TestLogin_OAuthAccount(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+
+	googleID := "google-sub-1"
+	mockUserRepo.On("GetByEmail", "user@gmail.com").Return(&domain.User{
+		IsActive:     true,
+		ID:           uuid.New(),
+		Email:        "user@gmail.com",
+		AuthProvider: domain.AuthProviderGoogle,
+		GoogleID:     &googleID,
+	}, nil)
+
+	_, _, _, err := service.Login(context.Background(), "user@gmail.com", "password123", "127.0.0.1")
+
+	assert.Equal(t, ErrOAuthAccount, err)
+}
+
+func TestLoginWithGoogle_CreatesUser(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	service.googleVerifier = stubGoogleVerifier{claims: googleClaims()}
+
+	mockUserRepo.On("GetByEmail", "user@gmail.com").Return(nil, gorm.ErrRecordNotFound)
+	mockUserRepo.On("Create", mock.MatchedBy(func(user *domain.User) bool {
+		return user.AuthProvider == domain.AuthProviderGoogle &&
+			user.GoogleID != nil && *user.GoogleID == "google-sub-1" &&
+			user.EmailVerified &&
+			user.FirstName == "Google" &&
+			user.Role == domain.UserRoleCustomer
+	})).Return(nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	accessToken, refreshToken, user, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, accessToken)
+	assert.NotEmpty(t, refreshToken)
+	assert.Equal(t, "user@gmail.com", user.Email)
+	mockUserRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+func TestLoginWithGoogle_LinksExistingUser(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	service.googleVerifier = stubGoogleVerifier{claims: googleClaims()}
+
+	existingUser := &domain.User{
+		IsActive:     true,
+		ID:           uuid.New(),
+		Email:        "user@gmail.com",
+		Password:     "hashed",
+		Role:         domain.UserRoleOwner,
+		AuthProvider: domain.AuthProviderPassword,
+	}
+
+	mockUserRepo.On("GetByEmail", "user@gmail.com").Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	_, _, user, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, existingUser.ID, user.ID)
+	assert.Equal(t, domain.AuthProviderGoogle, user.AuthProvider)
+	assert.Equal(t, "google-sub-1", *user.GoogleID)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, domain.UserRoleOwner, user.Role)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestLoginWithGoogle_SubjectMismatch(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+	service.googleVerifier = stubGoogleVerifier{claims: googleClaims()}
+
+	otherID := "google-sub-2"
+	mockUserRepo.On("GetByEmail", "user@gmail.com").Return(&domain.User{
+		IsActive:     true,
+		ID:           uuid.New(),
+		Email:        "user@gmail.com",
+		AuthProvider: domain.AuthProviderGoogle,
+		GoogleID:     &otherID,
+	}, nil)
+
+	_, _, _, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.Equal(t, ErrInvalidGoogleToken, err)
+}
+
+func TestLoginWithGoogle_InvalidToken(t *testing.T) {
+	service, _, _ := setupAuthService()
+	service.googleVerifier = stubGoogleVerifier{err: googleauth.ErrInvalidToken}
+
+	_, _, _, err := service.LoginWithGoogle(context.Background(), "bad-token")
+
+	assert.Equal(t, ErrInvalidGoogleToken, err)
+}
+
+func TestLoginWithGoogle_UnverifiedEmail(t *testing.T) {
+	service, _, _ := setupAuthService()
+	claims := googleClaims()
+	claims.EmailVerified = false
+	service.googleVerifier = stubGoogleVerifier{claims: claims}
+
+	_, _, _, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.Equal(t, ErrGoogleEmailNotVerified, err)
+}
+
+func TestLoginWithGoogle_NotConfigured(t *testing.T) {
+	service, _, _ := setupAuthService()
+
+	_, _, _, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.Equal(t, ErrGoogleLoginNotAvailable, err)
+}
+
 func TestRefreshToken_Success(t *testing.T) {
 	service, mockUserRepo, mockRefreshRepo := setupAuthService()
 
@@ -223,8 +652,9 @@ func TestRefreshToken_Success(t *testing.T) {
 	oldRefreshToken := "old-refresh-token"
 
 	existingUser := &domain.User{
-		ID:   userID,
-		Role: domain.UserRoleCustomer,
+		IsActive: true,
+		ID:       userID,
+		Role:     domain.UserRoleCustomer,
 	}
 
 	existingRefreshToken := &domain.RefreshToken{
@@ -236,10 +666,10 @@ func TestRefreshToken_Success(t *testing.T) {
 
 	mockRefreshRepo.On("GetByToken", oldRefreshToken).Return(existingRefreshToken, nil)
 	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
-	mockRefreshRepo.On("DeleteByToken", oldRefreshToken).Return(nil)
 	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	mockRefreshRepo.On("MarkReplaced", existingRefreshToken.ID, mock.AnythingOfType("uuid.UUID")).Return(true, nil)
 
-	newAccessToken, newRefreshToken, err := service.RefreshToken(oldRefreshToken)
+	newAccessToken, newRefreshToken, err := service.RefreshToken(context.Background(), oldRefreshToken)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, newAccessToken)
@@ -255,7 +685,7 @@ func TestRefreshToken_TokenNotFound(t *testing.T) {
 
 	mockRefreshRepo.On("GetByToken", "invalid-token").Return(nil, gorm.ErrRecordNotFound)
 
-	_, _, err := service.RefreshToken("invalid-token")
+	_, _, err := service.RefreshToken(context.Background(), "invalid-token")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidRefreshToken, err)
@@ -275,37 +705,278 @@ func TestRefreshToken_ExpiredToken(t *testing.T) {
 	mockRefreshRepo.On("GetByToken", "expired-token").Return(expiredToken, nil)
 	mockRefreshRepo.On("DeleteByToken", "expired-token").Return(nil)
 
-	_, _, err := service.RefreshToken("expired-token")
+	_, _, err := service.RefreshToken(context.Background(), "expired-token")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrExpiredRefreshToken, err)
 	mockRefreshRepo.AssertExpectations(t)
 }
 
+func TestRefreshToken_ReusedTokenRevokesFamily(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	replacedBy := uuid.New()
+	rotatedToken := &domain.RefreshToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Token:      "rotated-token",
+		ExpiresAt:  time.Now().Add(time.Hour * 24),
+		FamilyID:   uuid.New(),
+		ReplacedBy: &replacedBy,
+	}
+
+	mockRefreshRepo.On("GetByToken", "rotated-token").Return(rotatedToken, nil)
+	mockRefreshRepo.On("DeleteByFamilyID", rotatedToken.FamilyID).Return(int64(2), nil)
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Email: "victim@example.com"}, nil)
+
+	_, _, err := service.RefreshToken(context.Background(), "rotated-token")
+
+	assert.Equal(t, ErrRefreshTokenReused, err)
+	mockRefreshRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockRefreshRepo.AssertNotCalled(t, "DeleteAllByUserID", mock.Anything)
+}
+
+func TestRefreshToken_ReusedTokenWithoutFamilyRevokesAllSessions(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	replacedBy := uuid.New()
+	legacyToken := &domain.RefreshToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Token:      "legacy-token",
+		ExpiresAt:  time.Now().Add(time.Hour * 24),
+		ReplacedBy: &replacedBy,
+	}
+
+	mockRefreshRepo.On("GetByToken", "legacy-token").Return(legacyToken, nil)
+	mockRefreshRepo.On("DeleteAllByUserID", userID).Return(int64(2), nil)
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Email: "victim@example.com"}, nil)
+
+	_, _, err := service.RefreshToken(context.Background(), "legacy-token")
+
+	assert.Equal(t, ErrRefreshTokenReused, err)
+	mockRefreshRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertNotCalled(t, "DeleteByFamilyID", mock.Anything)
+}
+
+func TestRefreshToken_ConcurrentRotationRevokesFamily(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	token := &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Token:     "raced-token",
+		ExpiresAt: time.Now().Add(time.Hour * 24),
+		FamilyID:  uuid.New(),
+	}
+
+	mockRefreshRepo.On("GetByToken", "raced-token").Return(token, nil)
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Role: domain.UserRoleCustomer, IsActive: true}, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	mockRefreshRepo.On("MarkReplaced", token.ID, mock.AnythingOfType("uuid.UUID")).Return(false, nil)
+	mockRefreshRepo.On("DeleteByFamilyID", token.FamilyID).Return(int64(3), nil)
+
+	accessToken, refreshToken, err := service.RefreshToken(context.Background(), "raced-token")
+
+	assert.Equal(t, ErrRefreshTokenReused, err)
+	assert.Empty(t, accessToken)
+	assert.Empty(t, refreshToken)
+	mockRefreshRepo.AssertExpectations(t)
+}
+
+// memRefreshTokenRepository keeps refresh tokens in memory so that a sequence
+// of logins and rotations can be played through the real refresh flow.
+type memRefreshTokenRepository struct {
+	tokens map[string]*domain.RefreshToken
+}
+
+func newMemRefreshTokenRepository() *memRefreshTokenRepository {
+	return &memRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+}
+
+func (r *memRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	stored := *token
+	r.tokens[token.Token] = &stored
+	return nil
+}
+
+func (r *memRefreshTokenRepository) GetByToken(token string) (*domain.RefreshToken, error) {
+	stored, ok := r.tokens[token]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *stored
+	return &found, nil
+}
+
+func (r *memRefreshTokenRepository) DeleteByToken(token string) error {
+	delete(r.tokens, token)
+	return nil
+}
+
+func (r *memRefreshTokenRepository) MarkReplaced(id, replacedBy uuid.UUID) (bool, error) {
+	for _, stored := range r.tokens {
+		if stored.ID == id && stored.ReplacedBy == nil {
+			stored.ReplacedBy = &replacedBy
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memRefreshTokenRepository) DeleteAllByUserID(userID uuid.UUID) (int64, error) {
+	return r.deleteWhere(func(token *domain.RefreshToken) bool { return token.UserID == userID }), nil
+}
+
+func (r *memRefreshTokenRepository) DeleteByFamilyID(familyID uuid.UUID) (int64, error) {
+	return r.deleteWhere(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID }), nil
+}
+
+func (r *memRefreshTokenRepository) deleteWhere(match func(*domain.RefreshToken) bool) int64 {
+	var deleted int64
+	for key, token := range r.tokens {
+		if match(token) {
+			delete(r.tokens, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func TestRefreshToken_StolenTokenKillsAttackerAndVictimSessions(t *testing.T) {
+	service, mockUserRepo, _ := setupAuthService()
+	tokens := newMemRefreshTokenRepository()
+	service.refreshTokenRepo = tokens
+	audit := new(MockAuditRecorder)
+	service.audit = audit
+	ctx := context.Background()
+
+	user := &domain.User{ID: uuid.New(), Email: "victim@example.com", Role: domain.UserRoleCustomer, IsActive: true}
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionTokenRefresh
+	})).Return(nil)
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionTokenReuse &&
+			entry.ActorID == user.ID &&
+			entry.Metadata["severity"] == AuditSeverityHigh
+	})).Return(nil).Once()
+
+	// The victim logs in on two devices; the token of the first one is stolen.
+	_, stolen, err := service.issueTokens(user)
+	require.NoError(t, err)
+	_, otherDevice, err := service.issueTokens(user)
+	require.NoError(t, err)
+	family := tokens.tokens[stolen].FamilyID
+
+	// The attacker rotates the stolen copy first.
+	attackerAccess, attackerToken, err := service.RefreshToken(ctx, stolen)
+	require.NoError(t, err)
+	rotated := tokens.tokens[attackerToken]
+	assert.Equal(t, family, rotated.FamilyID)
+	assert.Equal(t, tokens.tokens[stolen].ID, *rotated.ParentID)
+
+	// The victim's device still holds the token the attacker rotated away.
+	_, _, err = service.RefreshToken(ctx, stolen)
+	assert.Equal(t, ErrRefreshTokenReused, err)
+
+	// Both sessions of the stolen family are gone.
+	_, _, err = service.RefreshToken(ctx, attackerToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	_, _, err = service.RefreshToken(ctx, stolen)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+	for _, token := range tokens.tokens {
+		assert.NotEqual(t, family, token.FamilyID)
+	}
+
+	// The attacker's access token is rejected as well.
+	claims, err := service.jwtManager.ValidateAccessToken(attackerAccess)
+	require.NoError(t, err)
+	revoked, err := service.tokenBlacklist.IsRevoked(claims.ID, user.ID, claims.IssuedAt.Time)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// The login on the other device was not part of the stolen family.
+	_, _, err = service.RefreshToken(ctx, otherDevice)
+	assert.NoError(t, err)
+	audit.AssertExpectations(t)
+}
+
 func TestLogout_Success(t *testing.T) {
 	service, _, mockRefreshRepo := setupAuthService()
 
 	refreshToken := "test-refresh-token"
 	mockRefreshRepo.On("DeleteByToken", refreshToken).Return(nil)
 
-	err := service.Logout(refreshToken)
+	err := service.Logout(refreshToken, nil)
 
 	assert.NoError(t, err)
 	mockRefreshRepo.AssertExpectations(t)
 }
 
+func TestLogout_RevokesAccessToken(t *testing.T) {
+	service, _, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	accessToken, err := service.jwtManager.GenerateAccessToken(userID, domain.UserRoleCustomer)
+	require.NoError(t, err)
+	claims, err := service.jwtManager.ValidateAccessToken(accessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.ID)
+
+	mockRefreshRepo.On("DeleteByToken", "test-refresh-token").Return(nil)
+
+	require.NoError(t, service.Logout("test-refresh-token", claims))
+
+	revoked, err := service.tokenBlacklist.IsRevoked(claims.ID, userID, claims.IssuedAt.Time)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
 func TestLogout_TokenNotFound(t *testing.T) {
 	service, _, mockRefreshRepo := setupAuthService()
 
 	mockRefreshRepo.On("DeleteByToken", "nonexistent-token").Return(gorm.ErrRecordNotFound)
 
-	err := service.Logout("nonexistent-token")
+	err := service.Logout("nonexistent-token", nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 	mockRefreshRepo.AssertExpectations(t)
 }
 
+func TestLogoutAll_Success(t *testing.T) {
+	service, _, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	mockRefreshRepo.On("DeleteAllByUserID", userID).Return(int64(3), nil)
+
+	terminated, err := service.LogoutAll(userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), terminated)
+	mockRefreshRepo.AssertExpectations(t)
+
+	revoked, err := service.tokenBlacklist.IsRevoked("", userID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestLogoutAll_RepositoryError(t *testing.T) {
+	service, _, mockRefreshRepo := setupAuthService()
+
+	userID := uuid.New()
+	mockRefreshRepo.On("DeleteAllByUserID", userID).Return(int64(0), gorm.ErrInvalidDB)
+
+	_, err := service.LogoutAll(userID)
+
+	assert.ErrorIs(t, err, gorm.ErrInvalidDB)
+}
+
 func TestIsValidEmail(t *testing.T) {
 	tests := []struct {
 		email    string
@@ -328,3 +999,95 @@ func TestIsValidEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyEmail_Success(t *testing.T) {
+	service, mockUserRepo, _, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	userID := uuid.New()
+	user := &domain.User{ID: userID, Email: "test@example.com"}
+	tokenEntity := &domain.EmailVerificationToken{
+		UserID:    userID,
+		Token:     "verify-token",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mockVerificationRepo.On("GetByToken", "verify-token").Return(tokenEntity, nil)
+	mockUserRepo.On("GetByID", userID).Return(user, nil)
+	mockUserRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool { return u.EmailVerified })).Return(nil)
+	mockVerificationRepo.On("DeleteAllByUserID", userID).Return(nil)
+
+	err := service.VerifyEmail("verify-token")
+
+	assert.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+	mockUserRepo.AssertExpectations(t)
+	mockVerificationRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmail_InvalidToken(t *testing.T) {
+	service, _, _, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	mockVerificationRepo.On("GetByToken", "unknown").Return(nil, gorm.ErrRecordNotFound)
+
+	err := service.VerifyEmail("unknown")
+
+	assert.Equal(t, ErrInvalidVerificationToken, err)
+}
+
+func TestVerifyEmail_ExpiredToken(t *testing.T) {
+	service, _, _, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	mockVerificationRepo.On("GetByToken", "expired").Return(&domain.EmailVerificationToken{
+		UserID:    uuid.New(),
+		Token:     "expired",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	err := service.VerifyEmail("expired")
+
+	assert.Equal(t, ErrExpiredVerificationToken, err)
+}
+
+func TestResendVerification_Success(t *testing.T) {
+	service, mockUserRepo, _, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Email: "test@example.com"}, nil)
+	mockVerificationRepo.On("GetLatestByUserID", userID).Return(&domain.EmailVerificationToken{
+		UserID:    userID,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}, nil)
+	mockVerificationRepo.On("DeleteAllByUserID", userID).Return(nil)
+
+	err := service.ResendVerification(userID)
+
+	assert.NoError(t, err)
+	mockVerificationRepo.AssertCalled(t, "Create", mock.AnythingOfType("*domain.EmailVerificationToken"))
+}
+
+func TestResendVerification_Cooldown(t *testing.T) {
+	service, mockUserRepo, _, mockVerificationRepo := setupAuthServiceWithVerification()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Email: "test@example.com"}, nil)
+	mockVerificationRepo.On("GetLatestByUserID", userID).Return(&domain.EmailVerificationToken{
+		UserID:    userID,
+		CreatedAt: time.Now().Add(-30 * time.Second),
+	}, nil)
+
+	err := service.ResendVerification(userID)
+
+	assert.Equal(t, ErrVerificationCooldown, err)
+	mockVerificationRepo.AssertNotCalled(t, "DeleteAllByUserID", userID)
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	service, mockUserRepo, _, _ := setupAuthServiceWithVerification()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, EmailVerified: true}, nil)
+
+	err := service.ResendVerification(userID)
+
+	assert.Equal(t, ErrEmailAlreadyVerified, err)
+}
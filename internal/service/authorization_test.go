@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRecorder is a mock implementation of AuditRecorder
+type MockAuditRecorder struct {
+	mock.Mock
+}
+
+func (m *MockAuditRecorder) Record(ctx context.Context, entry AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+// expectAdminOverride registers the audit entry an admin override must write
+func expectAdminOverride(audit *MockAuditRecorder, adminID, restaurantID uuid.UUID, action string) {
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.AdminOverride &&
+			entry.ActorID == adminID &&
+			entry.Action == action &&
+			entry.TargetType == "restaurant" &&
+			entry.TargetID == restaurantID
+	})).Return(nil).Once()
+}
+
+func adminContext(adminID uuid.UUID) context.Context {
+	return WithActor(context.Background(), Actor{ID: adminID, Role: domain.UserRoleAdmin})
+}
+
+func TestCanManageRestaurant_Owner(t *testing.T) {
+	audit := new(MockAuditRecorder)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	err := authz.CanManageRestaurant(context.Background(), restaurant, restaurant.OwnerID, "restaurant.update")
+
+	assert.NoError(t, err)
+	audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestCanManageRestaurant_NotOwner(t *testing.T) {
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	userID := uuid.New()
+
+	ctx := WithActor(context.Background(), Actor{ID: userID, Role: domain.UserRoleOwner})
+	err := authz.CanManageRestaurant(ctx, restaurant, userID, "restaurant.update")
+
+	assert.Equal(t, ErrUnauthorized, err)
+}
+
+func TestCanManageRestaurant_AdminOverrideIsAudited(t *testing.T) {
+	audit := new(MockAuditRecorder)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	adminID := uuid.New()
+
+	expectAdminOverride(audit, adminID, restaurant.ID, "restaurant.update")
+
+	err := authz.CanManageRestaurant(adminContext(adminID), restaurant, adminID, "restaurant.update")
+
+	assert.NoError(t, err)
+	audit.AssertExpectations(t)
+}
+
+func TestCanManageRestaurant_AdminActorMustBeCaller(t *testing.T) {
+	audit := new(MockAuditRecorder)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	err := authz.CanManageRestaurant(adminContext(uuid.New()), restaurant, uuid.New(), "restaurant.update")
+
+	assert.Equal(t, ErrUnauthorized, err)
+	audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestCanManageRestaurant_AuditFailureDeniesOverride(t *testing.T) {
+	audit := new(MockAuditRecorder)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	adminID := uuid.New()
+	auditErr := errors.New("audit unavailable")
+
+	audit.On("Record", mock.Anything, mock.Anything).Return(auditErr)
+
+	err := authz.CanManageRestaurant(adminContext(adminID), restaurant, adminID, "restaurant.delete")
+
+	assert.Equal(t, auditErr, err)
+}
+
+func TestCanStaffRestaurant(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	managerID := uuid.New()
+	strangerID := uuid.New()
+	adminID := uuid.New()
+
+	managerRepo := new(MockRestaurantManagerRepository)
+	managerRepo.On("IsManager", mock.Anything, managerID, restaurant.ID).Return(true, nil)
+	managerRepo.On("IsManager", mock.Anything, strangerID, restaurant.ID).Return(false, nil)
+	managerRepo.On("IsManager", mock.Anything, adminID, restaurant.ID).Return(false, nil)
+
+	audit := new(MockAuditRecorder)
+	expectAdminOverride(audit, adminID, restaurant.ID, "booking.update_status")
+
+	authz := NewRestaurantAuthorizer(managerRepo, audit)
+	ctx := context.Background()
+
+	assert.NoError(t, authz.CanStaffRestaurant(ctx, restaurant, restaurant.OwnerID, "booking.update_status"))
+	assert.NoError(t, authz.CanStaffRestaurant(ctx, restaurant, managerID, "booking.update_status"))
+	assert.Equal(t, ErrNotRestaurantStaff, authz.CanStaffRestaurant(ctx, restaurant, strangerID, "booking.update_status"))
+	assert.NoError(t, authz.CanStaffRestaurant(adminContext(adminID), restaurant, adminID, "booking.update_status"))
+
+	audit.AssertExpectations(t)
+}
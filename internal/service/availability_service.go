@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultLastSeatingOffsetMinutes is used for restaurants that do not set
+// their own last seating offset.
+const DefaultLastSeatingOffsetMinutes = 60
+
+var ErrAfterLastSeating = errors.New("booking starts after the last seating")
+
+const (
+	// defaultBookingDuration is how long a table is assumed to be taken when
+	// the client only asks about a start time.
+	defaultBookingDuration = 2 * time.Hour
+	nextSlotStep           = 30 * time.Minute
+	nextSlotHorizon        = 6 * time.Hour
+)
+
+const (
+	// tableCheckTimeout is the deadline shared by all checks of one call.
+	tableCheckTimeout = 3 * time.Second
+	// tableCheckWorkers bounds how many checks hit the database at once.
+	tableCheckWorkers = 10
+)
+
+// Reasons a TableCheckResult gives for a table that is not available.
+const (
+	// ReasonTableNotFound: there is no table with that ID.
+	ReasonTableNotFound = "table_not_found"
+	// ReasonTableInactive: the table, or its restaurant, is deactivated.
+	ReasonTableInactive = "table_inactive"
+	// ReasonOutsideWorkingHours: the restaurant does not seat guests at the
+	// start time, because it is closed or past its last seating.
+	ReasonOutsideWorkingHours = "outside_working_hours"
+	// ReasonDurationTooShort: the window is shorter than the table's
+	// minimum booking duration.
+	ReasonDurationTooShort = "duration_too_short"
+	// ReasonBlockedForMaintenance: a table block overlaps the window.
+	ReasonBlockedForMaintenance = "blocked_for_maintenance"
+	// ReasonOccupied: a booking holds the table during the window.
+	ReasonOccupied = "occupied"
+	// ReasonTimeout: the check did not finish before the shared deadline.
+	ReasonTimeout = "timeout"
+	// ReasonCheckFailed: the check failed for another reason; retrying may
+	// help.
+	ReasonCheckFailed = "check_failed"
+)
+
+// TableCheck asks whether one table is free from Start to End.
+type TableCheck struct {
+	TableID uuid.UUID
+	Start   time.Time
+	End     time.Time
+}
+
+// TableCheckResult answers a TableCheck. Reason is empty when Available is
+// true and one of the Reason constants otherwise.
+type TableCheckResult struct {
+	TableCheck
+	Available bool
+	Reason    string
+}
+
+// RestaurantAvailability summarises which tables are free for a party at a
+// given time. NextSlot is only set when nothing is free at that time.
+type RestaurantAvailability struct {
+	FreeTables int
+	BestFit    *domain.Table
+	NextSlot   *time.Time
+}
+
+// TableSlots is one table's slot grid in an availability calendar. Rules are
+// the booking rules the table is booked under, which set the grid.
+type TableSlots struct {
+	Table *domain.Table
+	Rules domain.BookingRules
+	Slots []CalendarSlot
+}
+
+// CalendarSlot is a start time on a table's grid. Available means a booking
+// of the table's minimum duration starting then is free.
+type CalendarSlot struct {
+	Start     time.Time
+	Available bool
+}
+
+type AvailabilityService interface {
+	// CheckRestaurantAvailability looks at tables that fit guests (any table
+	// when guests is 0) for a defaultBookingDuration booking starting at at.
+	CheckRestaurantAvailability(ctx context.Context, restaurant *domain.Restaurant, at time.Time, guests int) (*RestaurantAvailability, error)
+	// CheckTables runs the checks concurrently under one deadline and
+	// returns a result per check, in the order given.
+	CheckTables(ctx context.Context, checks []TableCheck) []TableCheckResult
+	// Calendar lays out every table that fits guests on its own slot grid
+	// for the restaurant's opening on day, from opening time up to the last
+	// seating. A closed day has no slots.
+	Calendar(ctx context.Context, restaurant *domain.Restaurant, day time.Time, guests int) ([]TableSlots, error)
+}
+
+type availabilityService struct {
+	tableRepo   repository.TableRepository
+	bookingRepo repository.BookingRepository
+	blockRepo   repository.TableBlockRepository
+}
+
+func NewAvailabilityService(tableRepo repository.TableRepository, bookingRepo repository.BookingRepository, blockRepo repository.TableBlockRepository) AvailabilityService {
+	return &availabilityService{
+		tableRepo:   tableRepo,
+		bookingRepo: bookingRepo,
+		blockRepo:   blockRepo,
+	}
+}
+
+func (s *availabilityService) CheckRestaurantAvailability(ctx context.Context, restaurant *domain.Restaurant, at time.Time, guests int) (*RestaurantAvailability, error) {
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, restaurant.ID, guests)
+	if err != nil {
+		return nil, err
+	}
+
+	fitting := fittingTables(tables, guests)
+
+	result := &RestaurantAvailability{}
+	if len(fitting) == 0 {
+		return result, nil
+	}
+
+	// One query covers the requested slot and every candidate next slot.
+	bookings, err := s.bookingRepo.GetOverlapping(ctx, restaurant.ID, at, at.Add(nextSlotHorizon+defaultBookingDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	busy := bookingsByTable(bookings)
+
+	free := freeTablesAt(restaurant, fitting, busy, at)
+	result.FreeTables = len(free)
+	result.BestFit = bestFitTable(free)
+	if len(free) > 0 {
+		return result, nil
+	}
+
+	for start := at.Add(nextSlotStep); !start.After(at.Add(nextSlotHorizon)); start = start.Add(nextSlotStep) {
+		if len(freeTablesAt(restaurant, fitting, busy, start)) > 0 {
+			slot := start
+			result.NextSlot = &slot
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (s *availabilityService) CheckTables(ctx context.Context, checks []TableCheck) []TableCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, tableCheckTimeout)
+	defer cancel()
+
+	results := make([]TableCheckResult, len(checks))
+	workers := make(chan struct{}, tableCheckWorkers)
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check TableCheck) {
+			defer wg.Done()
+
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-ctx.Done():
+				results[i] = TableCheckResult{TableCheck: check, Reason: ReasonTimeout}
+				return
+			}
+
+			reason, err := s.unavailableReason(ctx, check)
+			if err != nil {
+				// Drivers do not all wrap the context error, so the
+				// deadline is checked on ctx as well.
+				reason = ReasonCheckFailed
+				if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					reason = ReasonTimeout
+				}
+			}
+			results[i] = TableCheckResult{TableCheck: check, Available: reason == "", Reason: reason}
+		}(i, check)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (s *availabilityService) Calendar(ctx context.Context, restaurant *domain.Restaurant, day time.Time, guests int) ([]TableSlots, error) {
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, restaurant.ID, guests)
+	if err != nil {
+		return nil, err
+	}
+	tables = fittingTables(tables, guests)
+
+	calendar := make([]TableSlots, len(tables))
+	var longest time.Duration
+	for i, table := range tables {
+		rules := TableRules(restaurant, table)
+		calendar[i] = TableSlots{Table: table, Rules: rules, Slots: []CalendarSlot{}}
+		longest = max(longest, minDuration(rules))
+	}
+
+	first, last, open := seatingWindow(restaurant, day)
+	if !open || len(tables) == 0 {
+		return calendar, nil
+	}
+
+	// One query covers every slot of every table.
+	bookings, err := s.bookingRepo.GetOverlapping(ctx, restaurant.ID, first, last.Add(longest))
+	if err != nil {
+		return nil, err
+	}
+	busy := bookingsByTable(bookings)
+
+	for i := range calendar {
+		slots := &calendar[i]
+		blocks, err := s.blockRepo.ListByTable(ctx, slots.Table.ID, first)
+		if err != nil {
+			return nil, err
+		}
+
+		length := minDuration(slots.Rules)
+		for start := first; !start.After(last); start = start.Add(slotGranularity(slots.Rules)) {
+			end := start.Add(length)
+			slots.Slots = append(slots.Slots, CalendarSlot{
+				Start:     start,
+				Available: !overlapsBooking(busy[slots.Table.ID], start, end) && !overlapsBlock(blocks, start, end),
+			})
+		}
+	}
+	return calendar, nil
+}
+
+// seatingWindow returns the first and last start times the restaurant
+// accepts for the opening that begins on day. A day without a usable
+// schedule is bookable around the clock, as in canSeatAt.
+func seatingWindow(restaurant *domain.Restaurant, day time.Time) (first, last time.Time, open bool) {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	opens, closes, closed, ok := dayHours(restaurant.WorkingHours, midnight)
+	switch {
+	case !ok:
+		return midnight, midnight.Add(24*time.Hour - time.Minute), true
+	case closed:
+		return time.Time{}, time.Time{}, false
+	}
+	last = closes.Add(-time.Duration(restaurant.LastSeatingOffsetMinutes) * time.Minute)
+	return opens, last, !last.Before(opens)
+}
+
+func overlapsBlock(blocks []*domain.TableBlock, start, end time.Time) bool {
+	for _, block := range blocks {
+		if block.StartsAt.Before(end) && block.EndsAt.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// unavailableReason returns why the table cannot be booked for the check,
+// or "" when it can. The cheapest reasons are checked first.
+func (s *availabilityService) unavailableReason(ctx context.Context, check TableCheck) (string, error) {
+	table, err := s.tableRepo.GetByID(ctx, check.TableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ReasonTableNotFound, nil
+		}
+		return "", err
+	}
+	if !table.IsActive || table.Restaurant == nil || !table.Restaurant.IsActive {
+		return ReasonTableInactive, nil
+	}
+	if !canSeatAt(table.Restaurant, check.Start) {
+		return ReasonOutsideWorkingHours, nil
+	}
+	if ValidateBookingDuration(table.Restaurant, table, check.Start, check.End) != nil {
+		return ReasonDurationTooShort, nil
+	}
+
+	blocked, err := s.blockRepo.IsBlocked(ctx, check.TableID, check.Start, check.End)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return ReasonBlockedForMaintenance, nil
+	}
+
+	free, err := s.bookingRepo.CheckTableAvailability(ctx, check.TableID, check.Start, check.End)
+	if err != nil {
+		return "", err
+	}
+	if !free {
+		return ReasonOccupied, nil
+	}
+	return "", nil
+}
+
+// fittingTables drops tables too large for the party; the repository has
+// already dropped the ones too small. guests of 0 keeps every table.
+func fittingTables(tables []*domain.Table, guests int) []*domain.Table {
+	var fitting []*domain.Table
+	for _, table := range tables {
+		if guests == 0 || table.MinCapacity <= guests {
+			fitting = append(fitting, table)
+		}
+	}
+	return fitting
+}
+
+func bookingsByTable(bookings []*domain.Booking) map[uuid.UUID][]*domain.Booking {
+	busy := make(map[uuid.UUID][]*domain.Booking)
+	for _, booking := range bookings {
+		busy[booking.TableID] = append(busy[booking.TableID], booking)
+	}
+	return busy
+}
+
+func freeTablesAt(restaurant *domain.Restaurant, tables []*domain.Table, busy map[uuid.UUID][]*domain.Booking, start time.Time) []*domain.Table {
+	return freeTablesBetween(restaurant, tables, busy, start, start.Add(defaultBookingDuration))
+}
+
+// freeTablesBetween returns the tables not held by any booking between start
+// and end, provided the restaurant seats guests at start. Tables whose
+// minimum duration is longer than the booking are left out, so a short
+// booking is never placed at a table sold in longer blocks.
+func freeTablesBetween(restaurant *domain.Restaurant, tables []*domain.Table, busy map[uuid.UUID][]*domain.Booking, start, end time.Time) []*domain.Table {
+	if !canSeatAt(restaurant, start) {
+		return nil
+	}
+
+	var free []*domain.Table
+	for _, table := range tables {
+		if end.Sub(start) >= minDuration(TableRules(restaurant, table)) && !overlapsBooking(busy[table.ID], start, end) {
+			free = append(free, table)
+		}
+	}
+	return free
+}
+
+func overlapsBooking(bookings []*domain.Booking, start, end time.Time) bool {
+	for _, booking := range bookings {
+		if booking.StartTime.Before(end) && booking.EndTime.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// bestFitTable picks the smallest table, so larger ones stay free for
+// larger parties.
+func bestFitTable(tables []*domain.Table) *domain.Table {
+	var best *domain.Table
+	for _, table := range tables {
+		if best == nil ||
+			table.MaxCapacity < best.MaxCapacity ||
+			(table.MaxCapacity == best.MaxCapacity && table.MinCapacity < best.MinCapacity) {
+			best = table
+		}
+	}
+	return best
+}
+
+// ValidateLastSeating returns ErrAfterLastSeating, naming the latest allowed
+// start time, when start is later than the restaurant's last seating.
+func ValidateLastSeating(restaurant *domain.Restaurant, start time.Time) error {
+	latest, ok := LatestSeating(restaurant, start)
+	if ok && start.After(latest) {
+		return fmt.Errorf("%w, latest start time is %s", ErrAfterLastSeating, latest.Format("15:04"))
+	}
+	return nil
+}
+
+// LatestSeating returns the last start time the restaurant accepts for the
+// opening that start falls into: closing time minus the last seating offset.
+// ok is false when there is no usable schedule to derive it from.
+func LatestSeating(restaurant *domain.Restaurant, start time.Time) (time.Time, bool) {
+	_, closes, closed, ok := businessHours(restaurant.WorkingHours, start)
+	if !ok || closed {
+		return time.Time{}, false
+	}
+	return closes.Add(-time.Duration(restaurant.LastSeatingOffsetMinutes) * time.Minute), true
+}
+
+// IsOpenAt reports whether at falls inside one of the restaurant's openings,
+// read on at's clock. An overnight opening counts until its closing time the
+// next morning. Days without a usable schedule are treated as closed.
+func IsOpenAt(restaurant *domain.Restaurant, at time.Time) bool {
+	opens, closes, closed, ok := businessHours(restaurant.WorkingHours, at)
+	return ok && !closed && !at.Before(opens) && at.Before(closes)
+}
+
+// canSeatAt reports whether a booking may start at start: inside an opening
+// and no later than its last seating. Guests seated in time may stay past
+// closing. Unlike IsOpenAt, days without a usable schedule are treated as
+// open, so such restaurants can still be booked.
+func canSeatAt(restaurant *domain.Restaurant, start time.Time) bool {
+	if _, _, _, ok := businessHours(restaurant.WorkingHours, start); !ok {
+		return true
+	}
+	if !IsOpenAt(restaurant, start) {
+		return false
+	}
+	latest, _ := LatestSeating(restaurant, start)
+	return !start.After(latest)
+}
+
+// businessHours finds the opening that start falls into, keyed by the
+// lowercase English day name. A closing time at or before the opening time
+// means the restaurant closes after midnight, so early-morning times belong
+// to the previous day's opening while it lasts. ok is false when the day has
+// no usable schedule.
+func businessHours(hours domain.WorkingHours, start time.Time) (opens, closes time.Time, closed, ok bool) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	if prevOpens, prevCloses, prevClosed, prevOK := dayHours(hours, day.AddDate(0, 0, -1)); prevOK && !prevClosed && start.Before(prevCloses) {
+		return prevOpens, prevCloses, false, true
+	}
+	return dayHours(hours, day)
+}
+
+// dayHours returns the opening of the schedule for the given midnight.
+func dayHours(hours domain.WorkingHours, day time.Time) (opens, closes time.Time, closed, ok bool) {
+	schedule, found := hours[strings.ToLower(day.Weekday().String())]
+	if !found {
+		return time.Time{}, time.Time{}, false, false
+	}
+	if schedule.IsClosed {
+		return time.Time{}, time.Time{}, true, true
+	}
+
+	openAt, errOpen := time.Parse("15:04", schedule.OpenTime)
+	closeAt, errClose := time.Parse("15:04", schedule.CloseTime)
+	if errOpen != nil || errClose != nil {
+		return time.Time{}, time.Time{}, false, false
+	}
+
+	opens = day.Add(time.Duration(openAt.Hour())*time.Hour + time.Duration(openAt.Minute())*time.Minute)
+	closes = day.Add(time.Duration(closeAt.Hour())*time.Hour + time.Duration(closeAt.Minute())*time.Minute)
+	if !closes.After(opens) {
+		closes = closes.Add(24 * time.Hour)
+	}
+	return opens, closes, false, true
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupAvailabilityService() (AvailabilityService, *MockTableRepository, *BookingMockBookingRepository) {
+	service, tableRepo, bookingRepo, _ := setupTableCheckService()
+	return service, tableRepo, bookingRepo
+}
+
+func setupTableCheckService() (AvailabilityService, *MockTableRepository, *BookingMockBookingRepository, *MockTableBlockRepository) {
+	tableRepo := new(MockTableRepository)
+	bookingRepo := new(BookingMockBookingRepository)
+	blockRepo := new(MockTableBlockRepository)
+	return NewAvailabilityService(tableRepo, bookingRepo, blockRepo), tableRepo, bookingRepo, blockRepo
+}
+
+func availabilityRestaurant() *domain.Restaurant {
+	hours := domain.WorkingHours{}
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		hours[day] = domain.DaySchedule{OpenTime: "10:00", CloseTime: "23:00"}
+	}
+	return &domain.Restaurant{ID: uuid.New(), IsActive: true, WorkingHours: hours, LastSeatingOffsetMinutes: DefaultLastSeatingOffsetMinutes}
+}
+
+func TestCheckRestaurantAvailability_BestFit(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	at := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	small := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}
+	large := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 8}
+	bookedSmall := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}
+	tooBig := &domain.Table{ID: uuid.New(), MinCapacity: 6, MaxCapacity: 10}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 4).Return([]*domain.Table{small, bookedSmall, large, tooBig}, nil)
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{
+		{TableID: bookedSmall.ID, StartTime: at.Add(-time.Hour), EndTime: at.Add(time.Hour)},
+	}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.FreeTables)
+	assert.Equal(t, small.ID, result.BestFit.ID)
+	assert.Nil(t, result.NextSlot)
+}
+
+func TestCheckRestaurantAvailability_NextSlot(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	at := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{
+		{TableID: table.ID, StartTime: at.Add(-30 * time.Minute), EndTime: at.Add(30 * time.Minute)},
+	}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.FreeTables)
+	assert.Nil(t, result.BestFit)
+	require.NotNil(t, result.NextSlot)
+	assert.Equal(t, at.Add(30*time.Minute), *result.NextSlot)
+}
+
+func TestCheckRestaurantAvailability_RespectsWorkingHours(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	// Past the 22:00 last seating, and nothing opens again within the horizon.
+	at := time.Date(2024, 6, 1, 22, 30, 0, 0, time.UTC)
+
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.FreeTables)
+	assert.Nil(t, result.NextSlot)
+}
+
+func TestCheckRestaurantAvailability_NoFittingTables(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	at := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 12).Return([]*domain.Table{}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 12)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.FreeTables)
+	assert.Nil(t, result.NextSlot)
+	bookingRepo.AssertNotCalled(t, "GetOverlapping", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckRestaurantAvailability_LastSeatingIsBookable(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	at := time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC)
+
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FreeTables)
+}
+
+func TestCheckRestaurantAvailability_NextSlotStopsAtLastSeating(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	restaurant.LastSeatingOffsetMinutes = 90
+	at := time.Date(2024, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+	// Free again from 21:00, but the last seating is 21:30 and 21:00 still overlaps.
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{
+		{TableID: table.ID, StartTime: at.Add(-time.Hour), EndTime: at.Add(3 * time.Hour)},
+	}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.FreeTables)
+	assert.Nil(t, result.NextSlot)
+}
+
+func TestCheckRestaurantAvailability_SkipsTablesWithLongerMinimum(t *testing.T) {
+	service, tableRepo, bookingRepo := setupAvailabilityService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	at := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	// The private room fits a couple best, but is only sold in 3-hour blocks.
+	privateRoom := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 2, MinDurationMinutes: intPtr(180)}
+	regular := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{privateRoom, regular}, nil)
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at, mock.AnythingOfType("time.Time")).Return([]*domain.Booking{}, nil)
+
+	result, err := service.CheckRestaurantAvailability(ctx, restaurant, at, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FreeTables)
+	assert.Equal(t, regular.ID, result.BestFit.ID)
+}
+
+func TestCalendar_MixedGranularity(t *testing.T) {
+	service, tableRepo, bookingRepo, blockRepo := setupTableCheckService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	restaurant.BookingRules = domain.RestaurantBookingRules{
+		Zones: map[domain.LocationType]domain.BookingRules{
+			domain.LocationVIP: {MinDurationMinutes: 180, SlotGranularityMinutes: 180},
+		},
+	}
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	regular := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4, LocationType: domain.LocationRegular}
+	bar := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 2, LocationType: domain.LocationRegular,
+		MinDurationMinutes: intPtr(45), SlotGranularityMinutes: intPtr(45)}
+	privateRoom := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 10, LocationType: domain.LocationVIP}
+
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{regular, bar, privateRoom}, nil)
+	// Opening at 10:00, last seating at 22:00, and the longest minimum is 3 hours.
+	bookingRepo.On("GetOverlapping", ctx, restaurant.ID, at(10, 0), at(25, 0)).Return([]*domain.Booking{
+		{TableID: privateRoom.ID, StartTime: at(19, 0), EndTime: at(21, 0)},
+		{TableID: regular.ID, StartTime: at(12, 0), EndTime: at(14, 0)},
+	}, nil)
+	blockRepo.On("ListByTable", ctx, bar.ID, at(10, 0)).Return([]*domain.TableBlock{
+		{TableID: bar.ID, StartsAt: at(12, 0), EndsAt: at(13, 0)},
+	}, nil)
+	blockRepo.On("ListByTable", ctx, mock.Anything, at(10, 0)).Return([]*domain.TableBlock{}, nil)
+
+	calendar, err := service.Calendar(ctx, restaurant, day, 2)
+
+	require.NoError(t, err)
+	require.Len(t, calendar, 3)
+
+	unavailable := func(slots []CalendarSlot) []time.Time {
+		var starts []time.Time
+		for _, slot := range slots {
+			if !slot.Available {
+				starts = append(starts, slot.Start)
+			}
+		}
+		return starts
+	}
+
+	assert.Equal(t, domain.BookingRules{MinDurationMinutes: 30, SlotGranularityMinutes: 30}, calendar[0].Rules)
+	assert.Len(t, calendar[0].Slots, 25)
+	assert.Equal(t, []time.Time{at(12, 0), at(12, 30), at(13, 0), at(13, 30)}, unavailable(calendar[0].Slots))
+
+	assert.Equal(t, domain.BookingRules{MinDurationMinutes: 45, SlotGranularityMinutes: 45}, calendar[1].Rules)
+	assert.Len(t, calendar[1].Slots, 17)
+	assert.Equal(t, at(10, 45), calendar[1].Slots[1].Start)
+	assert.Equal(t, []time.Time{at(11, 30), at(12, 15)}, unavailable(calendar[1].Slots))
+
+	assert.Equal(t, domain.BookingRules{MinDurationMinutes: 180, SlotGranularityMinutes: 180}, calendar[2].Rules)
+	require.Len(t, calendar[2].Slots, 5)
+	assert.Equal(t, at(22, 0), calendar[2].Slots[4].Start)
+	assert.Equal(t, []time.Time{at(19, 0)}, unavailable(calendar[2].Slots))
+}
+
+func TestCalendar_ClosedDay(t *testing.T) {
+	service, tableRepo, _, _ := setupTableCheckService()
+	ctx := context.Background()
+	restaurant := availabilityRestaurant()
+	restaurant.WorkingHours["saturday"] = domain.DaySchedule{IsClosed: true}
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 4}
+	tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 0).Return([]*domain.Table{table}, nil)
+
+	calendar, err := service.Calendar(ctx, restaurant, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 0)
+
+	require.NoError(t, err)
+	require.Len(t, calendar, 1)
+	assert.Empty(t, calendar[0].Slots)
+}
+
+func TestValidateLastSeating(t *testing.T) {
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	restaurant := &domain.Restaurant{
+		WorkingHours: domain.WorkingHours{
+			"friday":   {OpenTime: "10:00", CloseTime: "23:00"},
+			"saturday": {OpenTime: "18:00", CloseTime: "02:00"},
+			"sunday":   {IsClosed: true},
+		},
+		LastSeatingOffsetMinutes: 60,
+	}
+
+	assert.NoError(t, ValidateLastSeating(restaurant, saturday.Add(25*time.Hour)))
+
+	err := ValidateLastSeating(restaurant, saturday.Add(25*time.Hour+time.Minute))
+	assert.ErrorIs(t, err, ErrAfterLastSeating)
+	assert.Contains(t, err.Error(), "latest start time is 01:00")
+
+	err = ValidateLastSeating(restaurant, saturday.Add(-time.Hour-30*time.Minute))
+	assert.ErrorIs(t, err, ErrAfterLastSeating)
+	assert.Contains(t, err.Error(), "latest start time is 22:00")
+
+	restaurant.LastSeatingOffsetMinutes = 0
+	assert.NoError(t, ValidateLastSeating(restaurant, saturday.Add(-time.Hour)))
+}
+
+func TestCanSeatAt(t *testing.T) {
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	restaurant := &domain.Restaurant{
+		WorkingHours: domain.WorkingHours{
+			"saturday": {OpenTime: "18:00", CloseTime: "02:00"},
+			"sunday":   {IsClosed: true},
+		},
+		LastSeatingOffsetMinutes: 60,
+	}
+
+	assert.True(t, canSeatAt(restaurant, saturday.Add(23*time.Hour)))
+	// After midnight the Saturday opening still applies even though Sunday is closed.
+	assert.True(t, canSeatAt(restaurant, saturday.Add(25*time.Hour)))
+	assert.False(t, canSeatAt(restaurant, saturday.Add(25*time.Hour+30*time.Minute)))
+	assert.False(t, canSeatAt(restaurant, saturday.Add(17*time.Hour)))
+	assert.False(t, canSeatAt(restaurant, saturday.Add(24*time.Hour+19*time.Hour)))
+	assert.True(t, canSeatAt(&domain.Restaurant{WorkingHours: domain.WorkingHours{}}, saturday))
+}
+
+func TestIsOpenAt(t *testing.T) {
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	restaurant := &domain.Restaurant{
+		WorkingHours: domain.WorkingHours{
+			"friday":   {OpenTime: "10:00", CloseTime: "23:00"},
+			"saturday": {OpenTime: "18:00", CloseTime: "02:00", Overnight: true},
+			"sunday":   {IsClosed: true},
+		},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"friday before midnight", saturday.Add(-30 * time.Minute), false},
+		{"friday evening", saturday.Add(-90 * time.Minute), true},
+		{"saturday before opening", saturday.Add(17*time.Hour + 59*time.Minute), false},
+		{"saturday opening time", saturday.Add(18 * time.Hour), true},
+		{"saturday at midnight", saturday.Add(24 * time.Hour), true},
+		{"after midnight into closed sunday", saturday.Add(25*time.Hour + 59*time.Minute), true},
+		{"overnight closing time", saturday.Add(26 * time.Hour), false},
+		{"sunday evening", saturday.Add(24*time.Hour + 19*time.Hour), false},
+		{"day without schedule", saturday.Add(-36 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsOpenAt(restaurant, tt.at))
+		})
+	}
+}
+
+func TestCheckTables_Reasons(t *testing.T) {
+	service, tableRepo, bookingRepo, blockRepo := setupTableCheckService()
+	restaurant := availabilityRestaurant()
+	closedRestaurant := availabilityRestaurant()
+	closedRestaurant.IsActive = false
+	evening := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)
+
+	free := &domain.Table{ID: uuid.New(), IsActive: true, Restaurant: restaurant}
+	inactive := &domain.Table{ID: uuid.New(), IsActive: false, Restaurant: restaurant}
+	inClosedRestaurant := &domain.Table{ID: uuid.New(), IsActive: true, Restaurant: closedRestaurant}
+	blocked := &domain.Table{ID: uuid.New(), IsActive: true, Restaurant: restaurant}
+	booked := &domain.Table{ID: uuid.New(), IsActive: true, Restaurant: restaurant}
+	privateRoom := &domain.Table{ID: uuid.New(), IsActive: true, Restaurant: restaurant, MinDurationMinutes: intPtr(180)}
+	missing := uuid.New()
+
+	for _, table := range []*domain.Table{free, inactive, inClosedRestaurant, blocked, booked, privateRoom} {
+		tableRepo.On("GetByID", mock.Anything, table.ID).Return(table, nil)
+	}
+	tableRepo.On("GetByID", mock.Anything, missing).Return(nil, gorm.ErrRecordNotFound)
+	blockRepo.On("IsBlocked", mock.Anything, blocked.ID, evening, evening.Add(2*time.Hour)).Return(true, nil)
+	blockRepo.On("IsBlocked", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	bookingRepo.On("CheckTableAvailability", mock.Anything, booked.ID, evening, evening.Add(2*time.Hour)).Return(false, nil)
+	bookingRepo.On("CheckTableAvailability", mock.Anything, free.ID, evening, evening.Add(2*time.Hour)).Return(true, nil)
+
+	checks := []TableCheck{
+		{TableID: free.ID, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: missing, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: inactive.ID, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: inClosedRestaurant.ID, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: free.ID, Start: night, End: night.Add(2 * time.Hour)},
+		{TableID: blocked.ID, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: booked.ID, Start: evening, End: evening.Add(2 * time.Hour)},
+		{TableID: privateRoom.ID, Start: evening, End: evening.Add(time.Hour)},
+	}
+
+	results := service.CheckTables(context.Background(), checks)
+
+	require.Len(t, results, len(checks))
+	wantReasons := []string{"", ReasonTableNotFound, ReasonTableInactive, ReasonTableInactive, ReasonOutsideWorkingHours, ReasonBlockedForMaintenance, ReasonOccupied, ReasonDurationTooShort}
+	for i, want := range wantReasons {
+		assert.Equal(t, checks[i], results[i].TableCheck, "check %d", i)
+		assert.Equal(t, want, results[i].Reason, "check %d", i)
+		assert.Equal(t, want == "", results[i].Available, "check %d", i)
+	}
+}
+
+func TestCheckTables_Failures(t *testing.T) {
+	service, tableRepo, _, _ := setupTableCheckService()
+	start := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+	slow := uuid.New()
+	broken := uuid.New()
+
+	tableRepo.On("GetByID", mock.Anything, slow).Return(nil, context.DeadlineExceeded)
+	tableRepo.On("GetByID", mock.Anything, broken).Return(nil, errors.New("connection reset"))
+
+	results := service.CheckTables(context.Background(), []TableCheck{
+		{TableID: slow, Start: start, End: start.Add(time.Hour)},
+		{TableID: broken, Start: start, End: start.Add(time.Hour)},
+	})
+
+	assert.Equal(t, ReasonTimeout, results[0].Reason)
+	assert.Equal(t, ReasonCheckFailed, results[1].Reason)
+	assert.False(t, results[0].Available)
+	assert.False(t, results[1].Available)
+}
+
+func TestCheckTables_SharedDeadline(t *testing.T) {
+	service, tableRepo, _, _ := setupTableCheckService()
+	start := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	tableRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("query aborted"))
+
+	checks := make([]TableCheck, 20)
+	for i := range checks {
+		checks[i] = TableCheck{TableID: uuid.New(), Start: start, End: start.Add(time.Hour)}
+	}
+
+	for _, result := range service.CheckTables(ctx, checks) {
+		assert.Equal(t, ReasonTimeout, result.Reason)
+	}
+}
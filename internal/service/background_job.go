@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrUnknownJobKind    = errors.New("unknown job kind")
+	ErrJobAlreadyRunning = repository.ErrBackgroundJobRunning
+)
+
+// Backfill is a job that walks a table in batches. Between batches its
+// progress is only the cursor, so it can be stopped and picked up again
+// from the last batch it finished. Processing an entry twice must be
+// harmless.
+type Backfill interface {
+	// Kind names the backfill, e.g. "recompute_ratings".
+	Kind() string
+	// Remaining counts the entries after cursor that are still to do. An
+	// empty cursor is the start.
+	Remaining(ctx context.Context, cursor string) (int64, error)
+	// Step processes the batch after cursor and returns how many entries it
+	// processed and the cursor to carry on from. Processing none ends the
+	// job.
+	Step(ctx context.Context, cursor string) (next string, processed int, err error)
+}
+
+// JobService runs backfills in the background and tracks their progress in
+// a BackgroundJob.
+type JobService interface {
+	// Start runs the backfill of kind from the beginning. Only one job of a
+	// kind runs at a time.
+	Start(ctx context.Context, kind string, startedBy uuid.UUID) (*domain.BackgroundJob, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error)
+	// Resume carries on with the jobs a previous process left running.
+	Resume(ctx context.Context)
+}
+
+type jobService struct {
+	jobRepo   repository.BackgroundJobRepository
+	backfills map[string]Backfill
+	audit     AuditRecorder
+	log       logger.Logger
+	// throttle is the pause between batches, so a backfill does not
+	// starve the database.
+	throttle time.Duration
+	now      func() time.Time
+	launch   func(fn func())
+}
+
+func NewJobService(jobRepo repository.BackgroundJobRepository, backfills []Backfill, audit AuditRecorder, log logger.Logger, throttle time.Duration) JobService {
+	byKind := make(map[string]Backfill, len(backfills))
+	for _, backfill := range backfills {
+		byKind[backfill.Kind()] = backfill
+	}
+	return &jobService{
+		jobRepo:   jobRepo,
+		backfills: byKind,
+		audit:     audit,
+		log:       log,
+		throttle:  throttle,
+		now:       time.Now,
+		launch:    func(fn func()) { go fn() },
+	}
+}
+
+func (s *jobService) Start(ctx context.Context, kind string, startedBy uuid.UUID) (*domain.BackgroundJob, error) {
+	backfill, ok := s.backfills[kind]
+	if !ok {
+		return nil, ErrUnknownJobKind
+	}
+
+	total, err := backfill.Remaining(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	job := &domain.BackgroundJob{
+		Kind:      kind,
+		Status:    domain.BackgroundJobRunning,
+		Total:     total,
+		StartedBy: startedBy,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    startedBy,
+		Action:     AuditActionJobStart,
+		TargetType: "background_job",
+		TargetID:   job.ID,
+		Metadata:   map[string]interface{}{"kind": kind},
+	})
+
+	// The job outlives the request that started it.
+	runCtx := context.WithoutCancel(ctx)
+	snapshot := *job
+	s.launch(func() { s.run(runCtx, &snapshot, backfill) })
+	return job, nil
+}
+
+func (s *jobService) Get(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *jobService) Resume(ctx context.Context) {
+	jobs, err := s.jobRepo.ListRunning(ctx)
+	if err != nil {
+		s.log.Warn("failed to list running jobs", zap.Error(err))
+		return
+	}
+
+	for _, job := range jobs {
+		backfill, ok := s.backfills[job.Kind]
+		if !ok {
+			s.finish(ctx, job, fmt.Errorf("%w %q", ErrUnknownJobKind, job.Kind))
+			continue
+		}
+		s.log.Info("resuming job", zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind), zap.String("cursor", job.Cursor))
+		s.launch(func() { s.run(ctx, job, backfill) })
+	}
+}
+
+// run steps through the backfill, saving progress after every batch. When
+// ctx is cancelled or progress cannot be saved it stops and leaves the job
+// running, for Resume to pick up.
+func (s *jobService) run(ctx context.Context, job *domain.BackgroundJob, backfill Backfill) {
+	for {
+		next, processed, err := backfill.Step(ctx, job.Cursor)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.finish(ctx, job, err)
+			return
+		}
+		if processed == 0 {
+			s.finish(ctx, job, nil)
+			return
+		}
+
+		job.Cursor = next
+		job.Processed += int64(processed)
+		// Entries added since the start are counted as they are reached.
+		job.Total = max(job.Total, job.Processed)
+		if err := s.jobRepo.Update(ctx, job); err != nil {
+			s.log.Warn("failed to save job progress", zap.String("job_id", job.ID.String()), zap.Error(err))
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.throttle):
+		}
+	}
+}
+
+// finish marks the job completed, or failed with err.
+func (s *jobService) finish(ctx context.Context, job *domain.BackgroundJob, err error) {
+	now := s.now()
+	job.FinishedAt = &now
+	job.Status = domain.BackgroundJobCompleted
+	if err != nil {
+		message := err.Error()
+		job.Status = domain.BackgroundJobFailed
+		job.Error = &message
+		s.log.Warn("job failed", zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind), zap.Error(err))
+	} else {
+		s.log.Info("job finished", zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind), zap.Int64("processed", job.Processed))
+	}
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		s.log.Warn("failed to save finished job", zap.String("job_id", job.ID.String()), zap.Error(err))
+	}
+}
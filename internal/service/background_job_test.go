@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MockBackgroundJobRepository struct {
+	mock.Mock
+	// saved holds a copy of the job at every Update.
+	saved []domain.BackgroundJob
+}
+
+func (m *MockBackgroundJobRepository) Create(ctx context.Context, job *domain.BackgroundJob) error {
+	args := m.Called(ctx, job)
+	if args.Error(0) == nil {
+		job.ID = uuid.New()
+	}
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.BackgroundJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BackgroundJob), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) Update(ctx context.Context, job *domain.BackgroundJob) error {
+	args := m.Called(ctx, job)
+	m.saved = append(m.saved, *job)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) ListRunning(ctx context.Context) ([]*domain.BackgroundJob, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.BackgroundJob), args.Error(1)
+}
+
+// countingBackfill has size entries. Its cursor is how many it has done,
+// and it does them batch at a time.
+type countingBackfill struct {
+	size    int
+	batch   int
+	failAt  string
+	cursors []string
+}
+
+func (b *countingBackfill) Kind() string { return "counting" }
+
+func (b *countingBackfill) Remaining(ctx context.Context, cursor string) (int64, error) {
+	done, _ := strconv.Atoi(cursor)
+	return int64(b.size - done), nil
+}
+
+func (b *countingBackfill) Step(ctx context.Context, cursor string) (string, int, error) {
+	b.cursors = append(b.cursors, cursor)
+	if b.failAt != "" && cursor == b.failAt {
+		return cursor, 0, errors.New("connection reset")
+	}
+	done, _ := strconv.Atoi(cursor)
+	n := min(b.batch, b.size-done)
+	return strconv.Itoa(done + n), n, nil
+}
+
+var jobNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func setupJobService(backfill Backfill) (*jobService, *MockBackgroundJobRepository, *MockAuditRecorder) {
+	repo := new(MockBackgroundJobRepository)
+	audit := new(MockAuditRecorder)
+	svc := NewJobService(repo, []Backfill{backfill}, audit, zap.NewNop(), 0).(*jobService)
+	svc.now = func() time.Time { return jobNow }
+	// Run jobs inline so tests can look at the outcome.
+	svc.launch = func(fn func()) { fn() }
+	return svc, repo, audit
+}
+
+func TestStartJob_RunsToCompletion(t *testing.T) {
+	backfill := &countingBackfill{size: 250, batch: 100}
+	svc, repo, audit := setupJobService(backfill)
+	ctx := context.Background()
+	adminID := uuid.New()
+
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.BackgroundJob")).Return(nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.BackgroundJob")).Return(nil)
+	audit.On("Record", ctx, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionJobStart && entry.ActorID == adminID && entry.Metadata["kind"] == "counting"
+	})).Return(nil).Once()
+
+	job, err := svc.Start(ctx, "counting", adminID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.BackgroundJobRunning, job.Status)
+	assert.Equal(t, int64(250), job.Total)
+	assert.Equal(t, []string{"", "100", "200", "250"}, backfill.cursors)
+
+	require.Len(t, repo.saved, 4)
+	assert.Equal(t, []int64{100, 200, 250, 250}, []int64{repo.saved[0].Processed, repo.saved[1].Processed, repo.saved[2].Processed, repo.saved[3].Processed})
+	assert.Equal(t, "200", repo.saved[1].Cursor)
+	last := repo.saved[3]
+	assert.Equal(t, domain.BackgroundJobCompleted, last.Status)
+	assert.Equal(t, jobNow, *last.FinishedAt)
+	assert.Nil(t, last.Error)
+	audit.AssertExpectations(t)
+}
+
+func TestStartJob_UnknownKind(t *testing.T) {
+	svc, repo, _ := setupJobService(&countingBackfill{})
+
+	_, err := svc.Start(context.Background(), "slugs", uuid.New())
+
+	assert.ErrorIs(t, err, ErrUnknownJobKind)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestStartJob_AlreadyRunning(t *testing.T) {
+	backfill := &countingBackfill{size: 10, batch: 5}
+	svc, repo, audit := setupJobService(backfill)
+
+	repo.On("Create", mock.Anything, mock.Anything).Return(repository.ErrBackgroundJobRunning)
+
+	_, err := svc.Start(context.Background(), "counting", uuid.New())
+
+	assert.ErrorIs(t, err, ErrJobAlreadyRunning)
+	assert.Empty(t, backfill.cursors)
+	audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestResumeJob_ContinuesFromCursor(t *testing.T) {
+	backfill := &countingBackfill{size: 250, batch: 100}
+	svc, repo, _ := setupJobService(backfill)
+	ctx := context.Background()
+	job := &domain.BackgroundJob{ID: uuid.New(), Kind: "counting", Status: domain.BackgroundJobRunning, Cursor: "200", Processed: 200, Total: 250}
+
+	repo.On("ListRunning", ctx).Return([]*domain.BackgroundJob{job}, nil)
+	repo.On("Update", ctx, job).Return(nil)
+
+	svc.Resume(ctx)
+
+	assert.Equal(t, []string{"200", "250"}, backfill.cursors)
+	assert.Equal(t, domain.BackgroundJobCompleted, job.Status)
+	assert.Equal(t, int64(250), job.Processed)
+}
+
+func TestResumeJob_UnknownKindFails(t *testing.T) {
+	svc, repo, _ := setupJobService(&countingBackfill{})
+	ctx := context.Background()
+	job := &domain.BackgroundJob{ID: uuid.New(), Kind: "retired", Status: domain.BackgroundJobRunning}
+
+	repo.On("ListRunning", ctx).Return([]*domain.BackgroundJob{job}, nil)
+	repo.On("Update", ctx, job).Return(nil)
+
+	svc.Resume(ctx)
+
+	assert.Equal(t, domain.BackgroundJobFailed, job.Status)
+	require.NotNil(t, job.Error)
+	assert.Contains(t, *job.Error, "retired")
+}
+
+func TestRunJob_StepErrorFailsJob(t *testing.T) {
+	backfill := &countingBackfill{size: 250, batch: 100, failAt: "100"}
+	svc, repo, _ := setupJobService(backfill)
+	job := &domain.BackgroundJob{ID: uuid.New(), Kind: "counting", Status: domain.BackgroundJobRunning}
+
+	repo.On("Update", mock.Anything, job).Return(nil)
+
+	svc.run(context.Background(), job, backfill)
+
+	assert.Equal(t, domain.BackgroundJobFailed, job.Status)
+	assert.Equal(t, "connection reset", *job.Error)
+	// The last finished batch is kept, so a new run could skip ahead.
+	assert.Equal(t, "100", job.Cursor)
+	assert.Equal(t, int64(100), job.Processed)
+}
+
+func TestRunJob_UnsavedProgressLeavesJobRunning(t *testing.T) {
+	backfill := &countingBackfill{size: 250, batch: 100}
+	svc, repo, _ := setupJobService(backfill)
+	job := &domain.BackgroundJob{ID: uuid.New(), Kind: "counting", Status: domain.BackgroundJobRunning}
+
+	repo.On("Update", mock.Anything, job).Return(errors.New("connection refused"))
+
+	svc.run(context.Background(), job, backfill)
+
+	assert.Equal(t, []string{""}, backfill.cursors)
+	assert.Equal(t, domain.BackgroundJobRunning, job.Status)
+	assert.Nil(t, job.FinishedAt)
+}
+
+func TestRunJob_StopsWhenCancelled(t *testing.T) {
+	backfill := &countingBackfill{size: 250, batch: 100}
+	svc, repo, _ := setupJobService(backfill)
+	svc.throttle = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &domain.BackgroundJob{ID: uuid.New(), Kind: "counting", Status: domain.BackgroundJobRunning}
+
+	repo.On("Update", mock.Anything, job).Run(func(mock.Arguments) { cancel() }).Return(nil)
+
+	svc.run(ctx, job, backfill)
+
+	assert.Equal(t, []string{""}, backfill.cursors)
+	assert.Equal(t, domain.BackgroundJobRunning, job.Status)
+	assert.Equal(t, "100", job.Cursor)
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	svc, repo, _ := setupJobService(&countingBackfill{})
+	id := uuid.New()
+
+	repo.On("GetByID", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Get(context.Background(), id)
+
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestRatingBackfill_Step(t *testing.T) {
+	reviewRepo := new(MockReviewRepository)
+	backfill := NewRatingBackfill(reviewRepo)
+	ctx := context.Background()
+	first, last := uuid.New(), uuid.New()
+
+	reviewRepo.On("RefreshRestaurantRatings", ctx, uuid.Nil, 100).Return([]uuid.UUID{first, last}, nil).Once()
+	reviewRepo.On("RefreshRestaurantRatings", ctx, last, 100).Return([]uuid.UUID{}, nil).Once()
+
+	next, processed, err := backfill.Step(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, last.String(), next)
+	assert.Equal(t, 2, processed)
+
+	next, processed, err = backfill.Step(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, last.String(), next)
+	assert.Zero(t, processed)
+	reviewRepo.AssertExpectations(t)
+}
+
+func TestRatingBackfill_Remaining(t *testing.T) {
+	reviewRepo := new(MockReviewRepository)
+	backfill := NewRatingBackfill(reviewRepo)
+	after := uuid.New()
+
+	reviewRepo.On("CountRestaurantsAfter", mock.Anything, after).Return(int64(42), nil)
+
+	remaining, err := backfill.Remaining(context.Background(), after.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), remaining)
+
+	_, err = backfill.Remaining(context.Background(), "not-a-uuid")
+	assert.Error(t, err)
+}
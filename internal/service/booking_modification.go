@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"restaurant-booking/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookingNotModifiable = errors.New("only pending or confirmed bookings can be modified")
+	ErrBookingStarted       = errors.New("booking has started, please contact the restaurant to change it")
+)
+
+// ModifyBookingRequest is a change to a booking made by UserID. A nil
+// TableID keeps the booking's table.
+type ModifyBookingRequest struct {
+	BookingID   uuid.UUID
+	UserID      uuid.UUID
+	StartTime   time.Time
+	EndTime     time.Time
+	GuestsCount int
+	TableID     *uuid.UUID
+}
+
+func (s *BookingService) ModifyBooking(ctx context.Context, req ModifyBookingRequest) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, req.BookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	restaurant := booking.Restaurant
+	if restaurant == nil {
+		return nil, ErrBookingNotFound
+	}
+
+	now := s.now()
+	byStaff := true
+	if err := s.authz.CanStaffRestaurant(ctx, restaurant, req.UserID, "booking.modify"); err != nil {
+		if !errors.Is(err, ErrNotRestaurantStaff) || !booking.BookedBy(req.UserID) {
+			return nil, err
+		}
+		if !now.Before(booking.StartTime) {
+			return nil, ErrBookingStarted
+		}
+		byStaff = false
+	}
+
+	if booking.Status != domain.BookingStatusPending && booking.Status != domain.BookingStatusConfirmed {
+		return nil, ErrBookingNotModifiable
+	}
+
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidBookingPeriod
+	}
+	// Staff may change the party or the end of a booking under way, but
+	// nobody may move it into the past.
+	if !req.StartTime.Equal(booking.StartTime) && !req.StartTime.After(now) {
+		return nil, ErrPastBooking
+	}
+
+	localStart := req.StartTime.In(restaurant.Location())
+	if err := ValidateLastSeating(restaurant, localStart); err != nil {
+		return nil, err
+	}
+	if !canSeatAt(restaurant, localStart) {
+		return nil, ErrOutsideWorkingHours
+	}
+
+	table := booking.Table
+	if req.TableID != nil && *req.TableID != booking.TableID {
+		table, err = s.tableRepo.GetByID(ctx, *req.TableID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTableNotFound
+			}
+			return nil, err
+		}
+		if table.RestaurantID != restaurant.ID || !table.IsActive {
+			return nil, ErrTableNotFound
+		}
+	}
+	if table == nil {
+		return nil, ErrTableNotFound
+	}
+
+	if req.GuestsCount < table.MinCapacity || req.GuestsCount > table.MaxCapacity {
+		return nil, fmt.Errorf("%w, it seats %d to %d guests", ErrGuestsExceedCapacity, table.MinCapacity, table.MaxCapacity)
+	}
+
+	if err := ValidateBookingDuration(restaurant, table, req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	previous := *booking
+	booking.TableID = table.ID
+	booking.Table = table
+	booking.BookingDate = time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, localStart.Location())
+	booking.StartTime = req.StartTime
+	booking.EndTime = req.EndTime
+	booking.GuestsCount = req.GuestsCount
+	// The table and the restaurant's guest limit are checked under the same
+	// locks as a new booking, so a modification cannot double-book either.
+	updated, err := s.bookingRepo.UpdateIfAvailable(ctx, booking)
+	if err != nil || !updated {
+		*booking = previous
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrTableNotAvailable
+	}
+
+	s.recordModification(ctx, req.UserID, &previous, booking, byStaff)
+	s.notifyModification(booking, &previous, byStaff)
+	return booking, nil
+}
+
+// recordModification keeps the booking's previous values in the audit log.
+// The change is already saved, so a failure here is only logged.
+func (s *BookingService) recordModification(ctx context.Context, userID uuid.UUID, previous, booking *domain.Booking, byStaff bool) {
+	err := s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		Action:     AuditActionBookingModify,
+		TargetType: "booking",
+		TargetID:   booking.ID,
+		Metadata: map[string]interface{}{
+			"by_staff": byStaff,
+			"previous": bookingTerms(previous),
+			"current":  bookingTerms(booking),
+		},
+		CreatedAt: s.now(),
+	})
+	if err != nil {
+		log.Printf("Record booking modification error: %v", err)
+	}
+}
+
+func bookingTerms(booking *domain.Booking) map[string]interface{} {
+	return map[string]interface{}{
+		"table_id":     booking.TableID.String(),
+		"start_time":   booking.StartTime.UTC().Format(time.RFC3339),
+		"end_time":     booking.EndTime.UTC().Format(time.RFC3339),
+		"guests_count": booking.GuestsCount,
+	}
+}
+
+// notifyModification tells the other party about the change: the customer
+// when staff made it, the restaurant when the customer did.
+func (s *BookingService) notifyModification(booking, previous *domain.Booking, byStaff bool) {
+	restaurant := booking.Restaurant
+	locale := domain.DefaultLocale
+	if byStaff {
+		if booking.User == nil {
+			log.Printf("Cannot notify booking %s modification without the user", booking.ID)
+			return
+		}
+		locale = booking.User.Locale
+	}
+
+	rendered, err := RenderNotification(TemplateBookingModified, locale, restaurant.Location(), ModifiedNotificationData{
+		BookingNotificationData: BookingNotificationData{
+			RestaurantName: restaurant.Name,
+			BookingID:      booking.ID,
+			StartTime:      booking.StartTime,
+			EndTime:        booking.EndTime,
+			GuestCount:     booking.GuestsCount,
+		},
+		ByStaff:            byStaff,
+		TableNumber:        booking.Table.TableNumber,
+		PreviousStartTime:  previous.StartTime,
+		PreviousGuestCount: previous.GuestsCount,
+	})
+	if err != nil {
+		log.Printf("Render booking %s modification notice error: %v", booking.ID, err)
+		return
+	}
+
+	if byStaff {
+		err = s.notificationSvc.SendEmail(booking.User.Email, rendered.Subject, rendered.Body)
+	} else {
+		err = s.notificationSvc.SendSMS(restaurant.Phone, rendered.Body)
+	}
+	if err != nil {
+		log.Printf("Send booking %s modification notice error: %v", booking.ID, err)
+	}
+}
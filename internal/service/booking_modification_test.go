@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// modifyFixture is statusFixture's confirmed booking for two at table T1,
+// starting tomorrow at 19:00, with notifications sent to a channel.
+func modifyFixture(t *testing.T) (*BookingService, *BookingMockBookingRepository, *domain.Booking, map[string]uuid.UUID, chan Notification) {
+	t.Helper()
+	service, mockBookingRepo, booking, users := statusFixture()
+	now := time.Date(2024, time.June, 3, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	sent := make(chan Notification, 10)
+	service.notificationSvc = newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+	t.Cleanup(service.notificationSvc.Shutdown)
+
+	booking.Restaurant.Name = "Osteria"
+	booking.Restaurant.Phone = "+77010000000"
+	booking.Table = &domain.Table{ID: uuid.New(), RestaurantID: booking.RestaurantID, TableNumber: "T1", MinCapacity: 1, MaxCapacity: 4, IsActive: true}
+	booking.TableID = booking.Table.ID
+	booking.StartTime = time.Date(2024, time.June, 4, 14, 0, 0, 0, time.UTC)
+	booking.EndTime = booking.StartTime.Add(2 * time.Hour)
+	booking.GuestsCount = 2
+	booking.User = &domain.User{ID: *booking.UserID, Email: "guest@example.com", Locale: domain.LocaleEnglish}
+	return service, mockBookingRepo, booking, users, sent
+}
+
+func TestModifyBooking_CustomerMovesIt(t *testing.T) {
+	service, mockBookingRepo, booking, users, sent := modifyFixture(t)
+	previousStart := booking.StartTime
+	start := booking.StartTime.Add(time.Hour)
+	end := start.Add(2 * time.Hour)
+	audit := service.audit.(*MockAuditRecorder)
+
+	mockBookingRepo.On("UpdateIfAvailable", tmock.Anything, booking).Return(true, nil)
+	audit.On("Record", tmock.Anything, tmock.MatchedBy(func(entry AuditEntry) bool {
+		previous := entry.Metadata["previous"].(map[string]interface{})
+		return entry.Action == AuditActionBookingModify && entry.TargetID == booking.ID &&
+			previous["start_time"] == previousStart.Format(time.RFC3339) && previous["guests_count"] == 2
+	})).Return(nil)
+
+	modified, err := service.ModifyBooking(context.Background(), ModifyBookingRequest{
+		BookingID:   booking.ID,
+		UserID:      users["customer"],
+		StartTime:   start,
+		EndTime:     end,
+		GuestsCount: 3,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, start, modified.StartTime)
+	assert.Equal(t, end, modified.EndTime)
+	assert.Equal(t, 3, modified.GuestsCount)
+	mockBookingRepo.AssertCalled(t, "UpdateIfAvailable", tmock.Anything, booking)
+	audit.AssertExpectations(t)
+
+	notification := receiveNotifications(t, sent, 1)[0]
+	assert.Equal(t, NotificationSMS, notification.Type)
+	assert.Equal(t, "+77010000000", notification.Recipient)
+	assert.Contains(t, notification.Message, "changed by the guest")
+}
+
+func TestModifyBooking_StaffMovesItToAnotherTable(t *testing.T) {
+	service, mockBookingRepo, booking, users, sent := modifyFixture(t)
+	mockTableRepo := service.tableRepo.(*BookingMockTableRepository)
+	table := &domain.Table{ID: uuid.New(), RestaurantID: booking.RestaurantID, TableNumber: "T7", MinCapacity: 4, MaxCapacity: 8, IsActive: true}
+
+	mockTableRepo.On("GetByID", tmock.Anything, table.ID).Return(table, nil)
+	mockBookingRepo.On("UpdateIfAvailable", tmock.Anything, booking).Return(true, nil)
+	service.audit.(*MockAuditRecorder).On("Record", tmock.Anything, tmock.Anything).Return(nil)
+
+	modified, err := service.ModifyBooking(context.Background(), ModifyBookingRequest{
+		BookingID:   booking.ID,
+		UserID:      users["manager"],
+		StartTime:   booking.StartTime,
+		EndTime:     booking.EndTime,
+		GuestsCount: 6,
+		TableID:     &table.ID,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, table.ID, modified.TableID)
+	assert.Equal(t, 6, modified.GuestsCount)
+
+	notification := receiveNotifications(t, sent, 1)[0]
+	assert.Equal(t, NotificationEmail, notification.Type)
+	assert.Equal(t, "guest@example.com", notification.Recipient)
+	assert.Contains(t, notification.Message, "at table T7 for 6 guests")
+}
+
+func TestModifyBooking_Rejections(t *testing.T) {
+	otherRestaurantTable := &domain.Table{ID: uuid.New(), RestaurantID: uuid.New(), MinCapacity: 1, MaxCapacity: 4, IsActive: true}
+	cases := map[string]struct {
+		user    string
+		arrange func(booking *domain.Booking, req *ModifyBookingRequest)
+		taken   bool
+		want    error
+	}{
+		"stranger": {"stranger", nil, false, ErrNotRestaurantStaff},
+		"customer after the start": {"customer", func(booking *domain.Booking, req *ModifyBookingRequest) {
+			booking.StartTime = time.Date(2024, time.June, 3, 11, 0, 0, 0, time.UTC)
+			req.StartTime, req.EndTime = booking.StartTime, booking.StartTime.Add(3*time.Hour)
+		}, false, ErrBookingStarted},
+		"cancelled": {"owner", func(booking *domain.Booking, req *ModifyBookingRequest) {
+			booking.Status = domain.BookingStatusCancelled
+		}, false, ErrBookingNotModifiable},
+		"into the past": {"owner", func(booking *domain.Booking, req *ModifyBookingRequest) {
+			req.StartTime = time.Date(2024, time.June, 3, 10, 0, 0, 0, time.UTC)
+			req.EndTime = req.StartTime.Add(2 * time.Hour)
+		}, false, ErrPastBooking},
+		"too many guests": {"customer", func(booking *domain.Booking, req *ModifyBookingRequest) {
+			req.GuestsCount = 5
+		}, false, ErrGuestsExceedCapacity},
+		"table of another restaurant": {"owner", func(booking *domain.Booking, req *ModifyBookingRequest) {
+			req.TableID = &otherRestaurantTable.ID
+		}, false, ErrTableNotFound},
+		"table taken": {"customer", nil, true, ErrTableNotAvailable},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mockBookingRepo, booking, users, _ := modifyFixture(t)
+			service.tableRepo.(*BookingMockTableRepository).On("GetByID", tmock.Anything, otherRestaurantTable.ID).Return(otherRestaurantTable, nil)
+			mockBookingRepo.On("UpdateIfAvailable", tmock.Anything, booking).Return(!tc.taken, nil).Maybe()
+			req := ModifyBookingRequest{
+				BookingID:   booking.ID,
+				UserID:      users[tc.user],
+				StartTime:   booking.StartTime.Add(time.Hour),
+				EndTime:     booking.EndTime.Add(time.Hour),
+				GuestsCount: 2,
+			}
+			if tc.arrange != nil {
+				tc.arrange(booking, &req)
+			}
+
+			_, err := service.ModifyBooking(context.Background(), req)
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.Equal(t, 2, booking.GuestsCount)
+			mockBookingRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+		})
+	}
+}
+
+func TestModifyBooking_RestaurantFull(t *testing.T) {
+	service, mockBookingRepo, booking, users, _ := modifyFixture(t)
+	previous := *booking
+	full := fmt.Errorf("%w: 38 of 40 guests already booked for that time", ErrGuestLimitReached)
+	mockBookingRepo.On("UpdateIfAvailable", tmock.Anything, booking).Return(false, full)
+
+	_, err := service.ModifyBooking(context.Background(), ModifyBookingRequest{
+		BookingID:   booking.ID,
+		UserID:      users["customer"],
+		StartTime:   booking.StartTime,
+		EndTime:     booking.EndTime,
+		GuestsCount: 4,
+	})
+
+	assert.ErrorIs(t, err, ErrGuestLimitReached)
+	assert.Equal(t, previous, *booking)
+	service.audit.(*MockAuditRecorder).AssertNotCalled(t, "Record", tmock.Anything, tmock.Anything)
+}
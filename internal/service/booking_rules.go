@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidBookingRules = errors.New("invalid booking rules")
+	ErrDurationTooShort    = errors.New("booking is shorter than the table's minimum duration")
+)
+
+// Booking rules for restaurants that do not set their own.
+const (
+	DefaultMinDurationMinutes     = 30
+	DefaultSlotGranularityMinutes = 30
+)
+
+var defaultBookingRules = domain.BookingRules{
+	MinDurationMinutes:     DefaultMinDurationMinutes,
+	SlotGranularityMinutes: DefaultSlotGranularityMinutes,
+}
+
+const (
+	// slotGranularityStep keeps slot grids on round times.
+	slotGranularityStep = 5
+	// maxBookingRuleMinutes caps both rules at half a day.
+	maxBookingRuleMinutes = 12 * 60
+)
+
+// RestaurantRules returns the restaurant-wide booking rules, with defaults
+// for whatever the restaurant does not set.
+func RestaurantRules(restaurant *domain.Restaurant) domain.BookingRules {
+	return restaurant.BookingRules.BookingRules.Inherit(defaultBookingRules)
+}
+
+// TableRules returns the booking rules the table is booked under: its own
+// overrides, then its zone's defaults, then the restaurant's rules.
+func TableRules(restaurant *domain.Restaurant, table *domain.Table) domain.BookingRules {
+	zone := restaurant.BookingRules.Zones[table.LocationType]
+	return table.BookingRules().Inherit(zone.Inherit(RestaurantRules(restaurant)))
+}
+
+func minDuration(rules domain.BookingRules) time.Duration {
+	return time.Duration(rules.MinDurationMinutes) * time.Minute
+}
+
+func slotGranularity(rules domain.BookingRules) time.Duration {
+	return time.Duration(rules.SlotGranularityMinutes) * time.Minute
+}
+
+// ValidateBookingDuration returns ErrDurationTooShort, naming the minimum,
+// when start to end is shorter than the table allows.
+func ValidateBookingDuration(restaurant *domain.Restaurant, table *domain.Table, start, end time.Time) error {
+	rules := TableRules(restaurant, table)
+	if end.Sub(start) < minDuration(rules) {
+		return fmt.Errorf("%w, minimum is %d minutes", ErrDurationTooShort, rules.MinDurationMinutes)
+	}
+	return nil
+}
+
+// validateRestaurantBookingRules checks the restaurant-wide rules and that
+// no zone allows bookings shorter than the restaurant does.
+func validateRestaurantBookingRules(rules domain.RestaurantBookingRules) error {
+	if err := validateBookingRules("", rules.BookingRules); err != nil {
+		return err
+	}
+	base := rules.BookingRules.Inherit(defaultBookingRules)
+	for zone, zoneRules := range rules.Zones {
+		if !slices.Contains(domain.LocationTypes, zone) {
+			return fmt.Errorf("%w: unknown zone %q", ErrInvalidBookingRules, zone)
+		}
+		if err := validateOverride(fmt.Sprintf("zones.%s.", zone), zoneRules, base); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTableBookingRules checks a table's overrides against the rules of
+// its restaurant and zone.
+func validateTableBookingRules(restaurant *domain.Restaurant, table *domain.Table) error {
+	zone := restaurant.BookingRules.Zones[table.LocationType]
+	return validateOverride("", table.BookingRules(), zone.Inherit(RestaurantRules(restaurant)))
+}
+
+// validateOverride checks rules that override parent: an override may ask
+// for longer bookings than its parent but not for shorter ones.
+func validateOverride(prefix string, rules, parent domain.BookingRules) error {
+	if err := validateBookingRules(prefix, rules); err != nil {
+		return err
+	}
+	if rules.MinDurationMinutes != 0 && rules.MinDurationMinutes < parent.MinDurationMinutes {
+		return fmt.Errorf("%w: %smin_duration_minutes cannot be below the inherited minimum of %d", ErrInvalidBookingRules, prefix, parent.MinDurationMinutes)
+	}
+	return nil
+}
+
+func validateBookingRules(prefix string, rules domain.BookingRules) error {
+	switch {
+	case rules.MinDurationMinutes < 0 || rules.MinDurationMinutes > maxBookingRuleMinutes:
+		return fmt.Errorf("%w: %smin_duration_minutes must be between 0 and %d", ErrInvalidBookingRules, prefix, maxBookingRuleMinutes)
+	case rules.SlotGranularityMinutes < 0 || rules.SlotGranularityMinutes > maxBookingRuleMinutes:
+		return fmt.Errorf("%w: %sslot_granularity_minutes must be between 0 and %d", ErrInvalidBookingRules, prefix, maxBookingRuleMinutes)
+	case rules.SlotGranularityMinutes%slotGranularityStep != 0:
+		return fmt.Errorf("%w: %sslot_granularity_minutes must be a multiple of %d", ErrInvalidBookingRules, prefix, slotGranularityStep)
+	}
+	return nil
+}
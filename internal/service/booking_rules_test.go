@@ -0,0 +1,102 @@
+package service
+
+import (
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestTableRules_Inheritance(t *testing.T) {
+	restaurant := &domain.Restaurant{BookingRules: domain.RestaurantBookingRules{
+		BookingRules: domain.BookingRules{SlotGranularityMinutes: 15},
+		Zones: map[domain.LocationType]domain.BookingRules{
+			domain.LocationVIP: {MinDurationMinutes: 120, SlotGranularityMinutes: 60},
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		table *domain.Table
+		want  domain.BookingRules
+	}{
+		{"restaurant with defaults", &domain.Table{LocationType: domain.LocationRegular},
+			domain.BookingRules{MinDurationMinutes: DefaultMinDurationMinutes, SlotGranularityMinutes: 15}},
+		{"zone default", &domain.Table{LocationType: domain.LocationVIP},
+			domain.BookingRules{MinDurationMinutes: 120, SlotGranularityMinutes: 60}},
+		{"table override on top of zone", &domain.Table{LocationType: domain.LocationVIP, MinDurationMinutes: intPtr(180), SlotGranularityMinutes: intPtr(180)},
+			domain.BookingRules{MinDurationMinutes: 180, SlotGranularityMinutes: 180}},
+		{"partial table override", &domain.Table{LocationType: domain.LocationRegular, SlotGranularityMinutes: intPtr(45)},
+			domain.BookingRules{MinDurationMinutes: DefaultMinDurationMinutes, SlotGranularityMinutes: 45}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TableRules(restaurant, tt.table))
+		})
+	}
+}
+
+func TestValidateBookingDuration(t *testing.T) {
+	restaurant := &domain.Restaurant{}
+	privateRoom := &domain.Table{MinDurationMinutes: intPtr(180)}
+	start := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, ValidateBookingDuration(restaurant, privateRoom, start, start.Add(3*time.Hour)))
+
+	err := ValidateBookingDuration(restaurant, privateRoom, start, start.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrDurationTooShort)
+	assert.Contains(t, err.Error(), "minimum is 180 minutes")
+
+	assert.ErrorIs(t, ValidateBookingDuration(restaurant, &domain.Table{}, start, start.Add(15*time.Minute)), ErrDurationTooShort)
+}
+
+func TestValidateRestaurantBookingRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   domain.RestaurantBookingRules
+		wantErr bool
+	}{
+		{"empty uses defaults", domain.RestaurantBookingRules{}, false},
+		{"zone asks for longer bookings", domain.RestaurantBookingRules{
+			Zones: map[domain.LocationType]domain.BookingRules{domain.LocationVIP: {MinDurationMinutes: 180}},
+		}, false},
+		{"zone below restaurant minimum", domain.RestaurantBookingRules{
+			BookingRules: domain.BookingRules{MinDurationMinutes: 60},
+			Zones:        map[domain.LocationType]domain.BookingRules{domain.LocationOutdoor: {MinDurationMinutes: 45}},
+		}, true},
+		{"unknown zone", domain.RestaurantBookingRules{
+			Zones: map[domain.LocationType]domain.BookingRules{"rooftop": {MinDurationMinutes: 60}},
+		}, true},
+		{"granularity off the 5-minute step", domain.RestaurantBookingRules{BookingRules: domain.BookingRules{SlotGranularityMinutes: 7}}, true},
+		{"negative minimum", domain.RestaurantBookingRules{BookingRules: domain.BookingRules{MinDurationMinutes: -30}}, true},
+		{"minimum over half a day", domain.RestaurantBookingRules{BookingRules: domain.BookingRules{MinDurationMinutes: 13 * 60}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRestaurantBookingRules(tt.rules)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidBookingRules)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTableBookingRules(t *testing.T) {
+	restaurant := &domain.Restaurant{BookingRules: domain.RestaurantBookingRules{
+		Zones: map[domain.LocationType]domain.BookingRules{domain.LocationVIP: {MinDurationMinutes: 120}},
+	}}
+
+	assert.NoError(t, validateTableBookingRules(restaurant, &domain.Table{LocationType: domain.LocationRegular, MinDurationMinutes: intPtr(45), SlotGranularityMinutes: intPtr(45)}))
+	assert.NoError(t, validateTableBookingRules(restaurant, &domain.Table{LocationType: domain.LocationVIP, MinDurationMinutes: intPtr(180)}))
+
+	err := validateTableBookingRules(restaurant, &domain.Table{LocationType: domain.LocationVIP, MinDurationMinutes: intPtr(60)})
+	assert.ErrorIs(t, err, ErrInvalidBookingRules)
+	assert.Contains(t, err.Error(), "inherited minimum of 120")
+}
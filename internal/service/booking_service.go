@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,30 +13,272 @@ import (
 	"restaurant-booking/internal/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidBookingPeriod   = errors.New("booking must end after it starts")
+	ErrPastBooking            = errors.New("booking must start in the future")
+	ErrOutsideWorkingHours    = errors.New("restaurant is not seating guests at the selected time")
+	ErrGuestsExceedCapacity   = errors.New("guests_count does not fit the table")
+	ErrDepositPaymentRequired = errors.New("payment_method is required, the booking needs a deposit")
+	ErrDepositUnpaid          = errors.New("booking cannot be confirmed before its deposit is paid")
+	ErrTableNotAvailable      = errors.New("table is not available for the selected time")
+	ErrGuestLimitReached      = repository.ErrGuestLimitReached
+	ErrBookingNotFound        = errors.New("booking not found")
+	ErrCustomerCanOnlyCancel  = errors.New("customers can only cancel their bookings")
+)
+
+// CreateBookingRequest is a booking a customer asks for. PaymentMethod pays
+// the deposit and must be set when the table or restaurant asks for one.
+type CreateBookingRequest struct {
+	RestaurantID  uuid.UUID
+	TableID       uuid.UUID
+	BookingDate   time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	GuestsCount   int
+	SpecialNote   string
+	PaymentMethod domain.PaymentMethod
+}
+
+// BookingStatusChange is an applied status update. ByStaff is false when
+// the customer cancelled their own booking.
+type BookingStatusChange struct {
+	Booking  *domain.Booking
+	Previous domain.BookingStatus
+	ByStaff  bool
+}
+
+// BookingWriter creates bookings and moves them between statuses.
+// *BookingService implements it.
+type BookingWriter interface {
+	// CreateBooking checks the booking against its table and restaurant and
+	// saves it pending under the table's cancellation policy, with Table
+	// set. Taking the policy's deposit is left to the caller; the booking is
+	// confirmed once that payment completes. The booking is held by userID,
+	// the authenticated customer, never by a user named in the request.
+	CreateBooking(ctx context.Context, userID uuid.UUID, req CreateBookingRequest) (*domain.Booking, error)
+	// UpdateBookingStatus sets the booking's status on behalf of userID.
+	// Staff of the booking's restaurant may set any status. The customer who
+	// made the booking may only cancel it and gets ErrCustomerCanOnlyCancel
+	// otherwise; anyone else gets ErrNotRestaurantStaff. A booking with a
+	// deposit cannot be confirmed before the deposit is paid, and cancelling
+	// it voids the deposit payment still pending.
+	UpdateBookingStatus(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID, status domain.BookingStatus) (*BookingStatusChange, error)
+	// ModifyBooking moves a pending or confirmed booking to new times, a
+	// new party size or another table of the restaurant, checked like a new
+	// booking against everything but itself. Staff of the restaurant may
+	// modify it, and so may its customer until it starts. The previous
+	// values are kept in the audit log and the other party is notified.
+	ModifyBooking(ctx context.Context, req ModifyBookingRequest) (*domain.Booking, error)
+	// CreateGuestBooking books a table for a guest without an account on
+	// behalf of staff of the restaurant. The booking is confirmed, has no
+	// user and keeps the guest's name and phone until it is claimed.
+	CreateGuestBooking(ctx context.Context, req GuestBookingRequest) (*domain.Booking, error)
+	// CanViewRestaurantBookings allows staff of the restaurant to list its
+	// bookings, guest names and phone numbers included, and returns
+	// ErrNotRestaurantStaff for anyone else.
+	CanViewRestaurantBookings(ctx context.Context, restaurant *domain.Restaurant, userID uuid.UUID) error
+}
+
 type BookingService struct {
 	bookingRepo     repository.BookingRepository
 	tableRepo       repository.TableRepository
 	restaurantRepo  repository.RestaurantRepository
+	paymentRepo     repository.PaymentRepository
+	authz           RestaurantAuthorizer
+	audit           AuditRecorder
 	notificationSvc *NotificationService
-	mu              sync.RWMutex
+	// phoneCountryCode is assumed for guest phone numbers without one.
+	phoneCountryCode string
+	mu               sync.RWMutex
+	now              func() time.Time
 }
 
 func NewBookingService(
 	bookingRepo repository.BookingRepository,
 	tableRepo repository.TableRepository,
 	restaurantRepo repository.RestaurantRepository,
+	paymentRepo repository.PaymentRepository,
+	authz RestaurantAuthorizer,
+	audit AuditRecorder,
 	notificationSvc *NotificationService,
+	phoneCountryCode string,
 ) *BookingService {
 	return &BookingService{
-		bookingRepo:     bookingRepo,
-		tableRepo:       tableRepo,
-		restaurantRepo:  restaurantRepo,
-		notificationSvc: notificationSvc,
+		bookingRepo:      bookingRepo,
+		tableRepo:        tableRepo,
+		restaurantRepo:   restaurantRepo,
+		paymentRepo:      paymentRepo,
+		authz:            authz,
+		audit:            audit,
+		notificationSvc:  notificationSvc,
+		phoneCountryCode: phoneCountryCode,
+		now:              time.Now,
 	}
 }
 
+func (s *BookingService) CreateBooking(ctx context.Context, userID uuid.UUID, req CreateBookingRequest) (*domain.Booking, error) {
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidBookingPeriod
+	}
+	if !req.StartTime.After(s.now()) {
+		return nil, ErrPastBooking
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	table, err := s.checkSlot(ctx, restaurant, req.TableID, req.StartTime, req.EndTime, req.GuestsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := TablePolicy(restaurant, table)
+	if policy.DepositAmount > 0 && req.PaymentMethod == "" {
+		return nil, ErrDepositPaymentRequired
+	}
+
+	booking := &domain.Booking{
+		RestaurantID: req.RestaurantID,
+		TableID:      req.TableID,
+		UserID:       &userID,
+		BookingDate:  req.BookingDate,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		GuestsCount:  req.GuestsCount,
+		SpecialNote:  req.SpecialNote,
+		Status:       domain.BookingStatusPending,
+	}
+	booking.ApplyPolicy(policy)
+
+	created, err := s.bookingRepo.CreateIfAvailable(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrTableNotAvailable
+	}
+
+	// Set after Create, so saving the booking does not touch the table.
+	booking.Table = table
+	return booking, nil
+}
+
+// checkSlot checks that the restaurant seats guests at start and returns
+// the table when it is one of the restaurant's, fits guests and the
+// booking is long enough. Whether the table is free is left to the insert.
+func (s *BookingService) checkSlot(ctx context.Context, restaurant *domain.Restaurant, tableID uuid.UUID, start, end time.Time, guests int) (*domain.Table, error) {
+	// Working hours are on the restaurant's clock, whatever offset the
+	// client sent.
+	localStart := start.In(restaurant.Location())
+	if err := ValidateLastSeating(restaurant, localStart); err != nil {
+		return nil, err
+	}
+	if !canSeatAt(restaurant, localStart) {
+		return nil, ErrOutsideWorkingHours
+	}
+
+	table, err := s.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, err
+	}
+	if table.RestaurantID != restaurant.ID || !table.IsActive {
+		return nil, ErrTableNotFound
+	}
+
+	if guests < table.MinCapacity || guests > table.MaxCapacity {
+		return nil, fmt.Errorf("%w, it seats %d to %d guests", ErrGuestsExceedCapacity, table.MinCapacity, table.MaxCapacity)
+	}
+
+	if err := ValidateBookingDuration(restaurant, table, start, end); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s *BookingService) UpdateBookingStatus(ctx context.Context, bookingID uuid.UUID, userID uuid.UUID, status domain.BookingStatus) (*BookingStatusChange, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	if booking.Restaurant == nil {
+		return nil, ErrBookingNotFound
+	}
+
+	byStaff := true
+	if err := s.authz.CanStaffRestaurant(ctx, booking.Restaurant, userID, "booking.update_status"); err != nil {
+		if !errors.Is(err, ErrNotRestaurantStaff) || !booking.BookedBy(userID) {
+			return nil, err
+		}
+		if status != domain.BookingStatusCancelled {
+			return nil, ErrCustomerCanOnlyCancel
+		}
+		byStaff = false
+	}
+
+	if status == domain.BookingStatusConfirmed && booking.Status != domain.BookingStatusConfirmed {
+		paid, err := s.depositPaid(ctx, booking)
+		if err != nil {
+			return nil, err
+		}
+		if !paid {
+			return nil, ErrDepositUnpaid
+		}
+	}
+
+	change := &BookingStatusChange{Booking: booking, Previous: booking.Status, ByStaff: byStaff}
+	booking.Status = status
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	if status == domain.BookingStatusCancelled {
+		if _, err := s.paymentRepo.VoidPendingByBooking(ctx, booking.ID); err != nil {
+			return nil, err
+		}
+	}
+	return change, nil
+}
+
+func (s *BookingService) CanViewRestaurantBookings(ctx context.Context, restaurant *domain.Restaurant, userID uuid.UUID) error {
+	return s.authz.CanStaffRestaurant(ctx, restaurant, userID, "booking.list")
+}
+
+// maxDepositPayments bounds how many completed payments depositPaid reads
+// for a booking; a customer pays a deposit once, or a few times at most.
+const maxDepositPayments = 20
+
+// depositPaid reports whether the booking needs no deposit or has a
+// completed payment that settles it, made by its customer for at least the
+// deposit.
+func (s *BookingService) depositPaid(ctx context.Context, booking *domain.Booking) (bool, error) {
+	if booking.CancellationPolicy == nil || booking.CancellationPolicy.DepositAmount == 0 {
+		return true, nil
+	}
+	if booking.UserID == nil {
+		return false, nil
+	}
+	completed := domain.PaymentStatusCompleted
+	payments, err := s.paymentRepo.List(ctx, repository.PaymentFilter{UserID: booking.UserID, BookingID: &booking.ID, Status: &completed}, maxDepositPayments, 0)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(payments, booking.PaidBy), nil
+}
+
 type BookingResult struct {
 	Booking *domain.Booking
 	Error   error
@@ -55,7 +299,7 @@ func (s *BookingService) CreateBookingWithNotification(
 	go func() {
 		booking := &domain.Booking{
 			ID:        uuid.New(),
-			UserID:    userID,
+			UserID:    &userID,
 			TableID:   tableID,
 			StartTime: startTime,
 			EndTime:   endTime,
@@ -89,48 +333,6 @@ func (s *BookingService) CreateBookingWithNotification(
 	return result.Booking, nil
 }
 
-func (s *BookingService) CheckMultipleTablesAvailability(
-	ctx context.Context,
-	tableIDs []uuid.UUID,
-	startTime, endTime time.Time,
-) map[uuid.UUID]bool {
-	results := make(map[uuid.UUID]bool)
-	resultsChan := make(chan struct {
-		TableID   uuid.UUID
-		Available bool
-	}, len(tableIDs))
-
-	var wg sync.WaitGroup
-
-	for _, tableID := range tableIDs {
-		wg.Add(1)
-		go func(tid uuid.UUID) {
-			defer wg.Done()
-
-			time.Sleep(50 * time.Millisecond)
-
-			available := true
-
-			resultsChan <- struct {
-				TableID   uuid.UUID
-				Available bool
-			}{TableID: tid, Available: available}
-		}(tableID)
-	}
-
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-
-	for result := range resultsChan {
-		results[result.TableID] = result.Available
-		log.Printf("Table %s availability: %v", result.TableID, result.Available)
-	}
-
-	return results
-}
-
 func (s *BookingService) ProcessBulkBookings(
 	ctx context.Context,
 	bookings []domain.Booking,
@@ -175,37 +377,56 @@ func (s *BookingService) processBooking(ctx context.Context, booking *domain.Boo
 	}
 }
 
+// RestaurantTableSearch is what SearchAvailableTablesParallel found in one
+// restaurant. TimedOut is set when ctx ended before the search finished;
+// Tables then holds the free tables found until then. Err is set when
+// loading or checking the tables failed for another reason.
+type RestaurantTableSearch struct {
+	Tables   []*domain.Table
+	TimedOut bool
+	Err      error
+}
+
+// SearchAvailableTablesParallel searches the restaurants concurrently for
+// active tables that seat guestCount and are free from startTime to
+// endTime. Every restaurant gets a result, also when ctx ends first.
 func (s *BookingService) SearchAvailableTablesParallel(
 	ctx context.Context,
 	restaurantIDs []uuid.UUID,
 	startTime, endTime time.Time,
 	guestCount int,
-) map[uuid.UUID][]uuid.UUID {
+) map[uuid.UUID]RestaurantTableSearch {
 	type RestaurantTables struct {
 		RestaurantID uuid.UUID
-		TableIDs     []uuid.UUID
+		Search       RestaurantTableSearch
 	}
 
 	resultsChan := make(chan RestaurantTables, len(restaurantIDs))
 	var wg sync.WaitGroup
 
+	// found keeps the tables each search has found so far, for the
+	// searches still running when ctx ends.
+	var mu sync.Mutex
+	found := make(map[uuid.UUID][]*domain.Table, len(restaurantIDs))
+
 	for _, restaurantID := range restaurantIDs {
 		wg.Add(1)
 
 		go func(rid uuid.UUID) {
 			defer wg.Done()
 
-			time.Sleep(100 * time.Millisecond)
-
-			availableTables := []uuid.UUID{uuid.New(), uuid.New()}
-
+			search := s.searchRestaurantTables(ctx, rid, startTime, endTime, guestCount, func(table *domain.Table) {
+				mu.Lock()
+				defer mu.Unlock()
+				found[rid] = append(found[rid], table)
+			})
 			resultsChan <- RestaurantTables{
 				RestaurantID: rid,
-				TableIDs:     availableTables,
+				Search:       search,
 			}
 
 			log.Printf("Found %d available tables in restaurant %s",
-				len(availableTables), rid)
+				len(search.Tables), rid)
 		}(restaurantID)
 	}
 
@@ -214,12 +435,63 @@ func (s *BookingService) SearchAvailableTablesParallel(
 		close(resultsChan)
 	}()
 
-	results := make(map[uuid.UUID][]uuid.UUID)
-	for rt := range resultsChan {
-		results[rt.RestaurantID] = rt.TableIDs
+	results := make(map[uuid.UUID]RestaurantTableSearch, len(restaurantIDs))
+	for {
+		select {
+		case rt, ok := <-resultsChan:
+			if !ok {
+				return results
+			}
+			results[rt.RestaurantID] = rt.Search
+		case <-ctx.Done():
+			// A search stuck in a query that ignores ctx must not hold up
+			// the others.
+			mu.Lock()
+			defer mu.Unlock()
+			for _, rid := range restaurantIDs {
+				if _, ok := results[rid]; !ok {
+					results[rid] = RestaurantTableSearch{Tables: found[rid], TimedOut: true}
+				}
+			}
+			return results
+		}
+	}
+}
+
+// searchRestaurantTables checks the restaurant's tables one by one and
+// reports each free one to onFound as well as in the result.
+func (s *BookingService) searchRestaurantTables(ctx context.Context, restaurantID uuid.UUID, startTime, endTime time.Time, guestCount int, onFound func(*domain.Table)) RestaurantTableSearch {
+	var search RestaurantTableSearch
+	failed := func(err error) RestaurantTableSearch {
+		if ctx.Err() != nil {
+			search.TimedOut = true
+		} else {
+			search.Err = err
+		}
+		return search
 	}
 
-	return results
+	tables, err := s.tableRepo.GetByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		return failed(err)
+	}
+	for _, table := range tables {
+		if !table.IsActive || guestCount < table.MinCapacity || guestCount > table.MaxCapacity {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return failed(err)
+		}
+		available, err := s.bookingRepo.CheckTableAvailability(ctx, table.ID, startTime, endTime)
+		if err != nil {
+			return failed(err)
+		}
+		if available {
+			search.Tables = append(search.Tables, table)
+			onFound(table)
+		}
+	}
+	return search
 }
 
 func (s *BookingService) CancelBookingWithRefund(
@@ -269,45 +541,77 @@ func (s *BookingService) CancelBookingWithRefund(
 	return nil
 }
 
-func (s *BookingService) GetBookingStatistics(ctx context.Context, restaurantID uuid.UUID) (map[string]int, error) {
+// BookingPeriod is the span [From, To) of booking start times
+// GetBookingStatistics covers.
+type BookingPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// BookingStatistics counts a restaurant's bookings in total, active
+// (pending or confirmed), completed and cancelled. Daily breaks the same
+// counts down by the day the bookings start on the restaurant's clock,
+// keyed "2006-01-02", and is only set for a period.
+type BookingStatistics struct {
+	Counts map[string]int
+	Daily  map[string]map[string]int
+}
+
+// GetBookingStatistics counts the restaurant's bookings, all of them or
+// those that start in period when it is not nil. The four counts run
+// concurrently and the first one that fails fails the call.
+func (s *BookingService) GetBookingStatistics(ctx context.Context, restaurantID uuid.UUID, period *BookingPeriod) (*BookingStatistics, error) {
 	type StatResult struct {
 		Key   string
 		Value int
+		Daily map[string]int64
 		Error error
 	}
 
+	zone := ""
+	if period != nil {
+		if !period.From.Before(period.To) {
+			return nil, ErrInvalidStatsRange
+		}
+		restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrRestaurantNotFound
+			}
+			return nil, err
+		}
+		zone = restaurant.Location().String()
+	}
+
 	statsChan := make(chan StatResult, 4)
 	var wg sync.WaitGroup
 
 	stats := []struct {
-		key  string
-		calc func() (int, error)
+		key      string
+		statuses []domain.BookingStatus
 	}{
-		{"total_bookings", func() (int, error) {
-			time.Sleep(50 * time.Millisecond)
-			return 150, nil
-		}},
-		{"active_bookings", func() (int, error) {
-			time.Sleep(30 * time.Millisecond)
-			return 25, nil
-		}},
-		{"completed_bookings", func() (int, error) {
-			time.Sleep(40 * time.Millisecond)
-			return 100, nil
-		}},
-		{"cancelled_bookings", func() (int, error) {
-			time.Sleep(35 * time.Millisecond)
-			return 25, nil
-		}},
+		{"total_bookings", nil},
+		{"active_bookings", []domain.BookingStatus{domain.BookingStatusPending, domain.BookingStatusConfirmed}},
+		{"completed_bookings", []domain.BookingStatus{domain.BookingStatusCompleted}},
+		{"cancelled_bookings", []domain.BookingStatus{domain.BookingStatusCancelled}},
 	}
 
 	for _, stat := range stats {
 		wg.Add(1)
-		go func(key string, calcFunc func() (int, error)) {
+		go func(key string, statuses []domain.BookingStatus) {
 			defer wg.Done()
-			value, err := calcFunc()
-			statsChan <- StatResult{Key: key, Value: value, Error: err}
-		}(stat.key, stat.calc)
+			if period == nil {
+				count, err := s.bookingRepo.CountWithStatus(ctx, restaurantID, statuses)
+				statsChan <- StatResult{Key: key, Value: int(count), Error: err}
+				return
+			}
+			daily, err := s.bookingRepo.CountWithStatusByDay(ctx, restaurantID, statuses, period.From, period.To, zone)
+			var total int64
+			for _, count := range daily {
+				total += count
+			}
+			statsChan <- StatResult{Key: key, Value: int(total), Daily: daily, Error: err}
+		}(stat.key, stat.statuses)
 	}
 
 	go func() {
@@ -315,14 +619,27 @@ func (s *BookingService) GetBookingStatistics(ctx context.Context, restaurantID
 		close(statsChan)
 	}()
 
-	results := make(map[string]int)
+	results := &BookingStatistics{Counts: make(map[string]int, len(stats))}
+	if period != nil {
+		results.Daily = make(map[string]map[string]int)
+	}
 	for result := range statsChan {
 		if result.Error != nil {
-			return nil, result.Error
+			return nil, fmt.Errorf("count %s: %w", result.Key, result.Error)
+		}
+		results.Counts[result.Key] = result.Value
+		for day, count := range result.Daily {
+			if results.Daily[day] == nil {
+				// Every day lists all four counts, zero or not.
+				results.Daily[day] = make(map[string]int, len(stats))
+				for _, stat := range stats {
+					results.Daily[day][stat.key] = 0
+				}
+			}
+			results.Daily[day][result.Key] = int(count)
 		}
-		results[result.Key] = result.Value
 	}
 
-	log.Printf("Statistics calculated for restaurant %s: %+v", restaurantID, results)
+	log.Printf("Statistics calculated for restaurant %s: %+v", restaurantID, results.Counts)
 	return results, nil
 }
@@ -0,0 +1,165 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestCreateBooking_ConcurrentSameSlot fires overlapping bookings of one
+// table at once and checks that only one of them is created.
+func TestCreateBooking_ConcurrentSameSlot(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+
+	table := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "C1", MinCapacity: 1, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	require.NoError(t, db.Create(table).Error)
+
+	authz := NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop()))
+	service := NewBookingService(repository.NewBookingRepository(db), repository.NewTableRepository(db), repository.NewRestaurantRepository(db), repository.NewPaymentRepository(db), authz, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7")
+
+	start := time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+	const attempts = 8
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every other attempt starts half an hour later, so the
+			// slots overlap without being identical.
+			begin := start.Add(time.Duration(i%2) * 30 * time.Minute)
+			_, errs[i] = service.CreateBooking(ctx, owner.ID, CreateBookingRequest{
+				RestaurantID: restaurant.ID,
+				TableID:      table.ID,
+				BookingDate:  start,
+				StartTime:    begin,
+				EndTime:      begin.Add(2 * time.Hour),
+				GuestsCount:  2,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrTableNotAvailable), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Booking{}).Where("table_id = ?", table.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestCreateBooking_ConcurrentGuestLimit books different tables of a
+// restaurant with a guest limit at once and checks that the limit holds.
+func TestCreateBooking_ConcurrentGuestLimit(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+	require.NoError(t, db.Model(restaurant).Update("max_concurrent_guests", 6).Error)
+
+	const attempts = 6
+	tables := make([]*domain.Table, attempts)
+	for i := range tables {
+		tables[i] = &domain.Table{RestaurantID: restaurant.ID, TableNumber: fmt.Sprintf("G%d", i), MinCapacity: 1, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+		require.NoError(t, db.Create(tables[i]).Error)
+	}
+
+	authz := NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop()))
+	service := NewBookingService(repository.NewBookingRepository(db), repository.NewTableRepository(db), repository.NewRestaurantRepository(db), repository.NewPaymentRepository(db), authz, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7")
+
+	start := time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.CreateBooking(ctx, owner.ID, CreateBookingRequest{
+				RestaurantID: restaurant.ID,
+				TableID:      tables[i].ID,
+				BookingDate:  start,
+				StartTime:    start,
+				EndTime:      start.Add(2 * time.Hour),
+				GuestsCount:  2,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrGuestLimitReached), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 3, succeeded)
+
+	var guests int64
+	require.NoError(t, db.Model(&domain.Booking{}).Where("restaurant_id = ?", restaurant.ID).Select("COALESCE(SUM(guests_count), 0)").Scan(&guests).Error)
+	assert.Equal(t, int64(6), guests)
+}
+
+// TestModifyBooking_GuestLimit grows parties at a restaurant with a guest
+// limit and checks that a booking's own guests are not counted twice.
+func TestModifyBooking_GuestLimit(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+	require.NoError(t, db.Model(restaurant).Update("max_concurrent_guests", 6).Error)
+
+	authz := NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop()))
+	service := NewBookingService(repository.NewBookingRepository(db), repository.NewTableRepository(db), repository.NewRestaurantRepository(db), repository.NewPaymentRepository(db), authz, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7")
+
+	start := time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+	bookings := make([]*domain.Booking, 2)
+	for i, guests := range []int{2, 3} {
+		table := &domain.Table{RestaurantID: restaurant.ID, TableNumber: fmt.Sprintf("M%d", i), MinCapacity: 1, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+		require.NoError(t, db.Create(table).Error)
+		booking, err := service.CreateBooking(ctx, owner.ID, CreateBookingRequest{
+			RestaurantID: restaurant.ID,
+			TableID:      table.ID,
+			BookingDate:  start,
+			StartTime:    start,
+			EndTime:      start.Add(2 * time.Hour),
+			GuestsCount:  guests,
+		})
+		require.NoError(t, err)
+		bookings[i] = booking
+	}
+
+	modify := func(booking *domain.Booking, guests int) error {
+		_, err := service.ModifyBooking(ctx, ModifyBookingRequest{
+			BookingID:   booking.ID,
+			UserID:      owner.ID,
+			StartTime:   booking.StartTime,
+			EndTime:     booking.EndTime,
+			GuestsCount: guests,
+		})
+		return err
+	}
+	// 2 + 4 fills the restaurant, which the three already booked at the
+	// second table must not count against.
+	assert.NoError(t, modify(bookings[1], 4))
+	assert.ErrorIs(t, modify(bookings[0], 3), ErrGuestLimitReached)
+}
@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 type BookingMockBookingRepository struct {
@@ -20,6 +26,16 @@ func (m *BookingMockBookingRepository) Create(ctx context.Context, booking *doma
 	return args.Error(0)
 }
 
+func (m *BookingMockBookingRepository) CreateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error) {
+	args := m.Called(ctx, booking)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) UpdateIfAvailable(ctx context.Context, booking *domain.Booking) (bool, error) {
+	args := m.Called(ctx, booking)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *BookingMockBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -28,8 +44,16 @@ func (m *BookingMockBookingRepository) GetByID(ctx context.Context, id uuid.UUID
 	return args.Get(0).(*domain.Booking), args.Error(1)
 }
 
-func (m *BookingMockBookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Booking, error) {
-	args := m.Called(ctx, userID)
+func (m *BookingMockBookingRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter repository.UserBookingFilter, limit, offset int) ([]*domain.Booking, error) {
+	args := m.Called(ctx, userID, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetCalendarByUser(ctx context.Context, userID uuid.UUID, from time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, userID, from)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -44,6 +68,14 @@ func (m *BookingMockBookingRepository) GetByRestaurantID(ctx context.Context, re
 	return args.Get(0).([]*domain.Booking), args.Error(1)
 }
 
+func (m *BookingMockBookingRepository) GetByRestaurantIDRange(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, status *domain.BookingStatus, limit, offset int) ([]*domain.Booking, error) {
+	args := m.Called(ctx, restaurantID, from, to, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
 func (m *BookingMockBookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
 	args := m.Called(ctx, booking)
 	return args.Error(0)
@@ -59,6 +91,111 @@ func (m *BookingMockBookingRepository) CheckTableAvailability(ctx context.Contex
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *BookingMockBookingRepository) CheckTableAvailabilityExcept(ctx context.Context, tableID uuid.UUID, startTime, endTime time.Time, bookingID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, tableID, startTime, endTime, bookingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetHistory(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, restaurantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) CountByStatus(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (map[domain.BookingStatus]int64, error) {
+	args := m.Called(ctx, restaurantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.BookingStatus]int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) AverageGuestCount(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (float64, error) {
+	args := m.Called(ctx, restaurantID, from, to)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) CountWithStatus(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus) (int64, error) {
+	args := m.Called(ctx, restaurantID, statuses)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) CountWithStatusByDay(ctx context.Context, restaurantID uuid.UUID, statuses []domain.BookingStatus, from, to time.Time, zone string) (map[string]int64, error) {
+	args := m.Called(ctx, restaurantID, statuses, from, to, zone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetOverlapping(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, restaurantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetOverlappingByTable(ctx context.Context, tableID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, tableID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetUpcomingByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, tableID, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) CancelPendingByUser(ctx context.Context, userID uuid.UUID, from time.Time) (int64, error) {
+	args := m.Called(ctx, userID, from)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) GetStalePending(ctx context.Context, createdBefore time.Time, limit int) ([]*domain.Booking, error) {
+	args := m.Called(ctx, createdBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) ExpirePending(ctx context.Context, id uuid.UUID, note string) (bool, error) {
+	args := m.Called(ctx, id, note)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) ConfirmPending(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) MarkNoShow(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) ClaimGuestBookings(ctx context.Context, phone string, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, phone, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) CountNoShowsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockBookingRepository) WithTx(tx *gorm.DB) repository.BookingRepository {
+	return m
+}
+
 type BookingMockTableRepository struct {
 	tmock.Mock
 }
@@ -94,7 +231,15 @@ func (m *BookingMockTableRepository) Delete(ctx context.Context, id uuid.UUID) e
 	return args.Error(0)
 }
 
-func (m *BookingMockTableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
+func (m *BookingMockTableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error) {
+	args := m.Called(ctx, restaurantID, minCapacity, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Table), args.Error(1)
+}
+
+func (m *BookingMockTableRepository) GetByMinCapacity(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
 	args := m.Called(ctx, restaurantID, minCapacity)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -102,6 +247,14 @@ func (m *BookingMockTableRepository) GetAvailableTables(ctx context.Context, res
 	return args.Get(0).([]*domain.Table), args.Error(1)
 }
 
+func (m *BookingMockTableRepository) GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]repository.FloorPlanTable, error) {
+	args := m.Called(ctx, restaurantID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FloorPlanTable), args.Error(1)
+}
+
 func (m *BookingMockTableRepository) List(ctx context.Context, limit, offset int) ([]*domain.Table, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -110,6 +263,10 @@ func (m *BookingMockTableRepository) List(ctx context.Context, limit, offset int
 	return args.Get(0).([]*domain.Table), args.Error(1)
 }
 
+func (m *BookingMockTableRepository) WithTx(tx *gorm.DB) repository.TableRepository {
+	return m
+}
+
 type BookingMockRestaurantRepository struct {
 	tmock.Mock
 }
@@ -161,22 +318,117 @@ func (m *BookingMockRestaurantRepository) GetByOwnerID(ctx context.Context, owne
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
-func (m *BookingMockRestaurantRepository) List(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *BookingMockRestaurantRepository) ListFiltered(ctx context.Context, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) ListColumns(ctx context.Context, columns []string, withMainImage bool, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, columns, withMainImage, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) Count(ctx context.Context, filter repository.RestaurantFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, cuisineType, minRating, openAt, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
-func (m *BookingMockRestaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, limit, offset int) ([]*domain.Restaurant, error) {
-	args := m.Called(ctx, cuisineType, minRating, limit, offset)
+func (m *BookingMockRestaurantRepository) ListNearby(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*repository.NearbyRestaurant, error) {
+	args := m.Called(ctx, lat, lng, radiusKm, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.NearbyRestaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) CountByCuisine(ctx context.Context) ([]*repository.CuisineCount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.CuisineCount), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) GetByAddressKey(ctx context.Context, key string) (*domain.Restaurant, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Restaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) GetBySlug(ctx context.Context, slug string) (*domain.Restaurant, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Restaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) ListSlugs(ctx context.Context, base string, except uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, base, except)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) ListWithoutSlug(ctx context.Context, after uuid.UUID, limit int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, after, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
+func (m *BookingMockRestaurantRepository) CountWithoutSlug(ctx context.Context, after uuid.UUID) (int64, error) {
+	args := m.Called(ctx, after)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) SetSlug(ctx context.Context, id uuid.UUID, slug string) error {
+	args := m.Called(ctx, id, slug)
+	return args.Error(0)
+}
+
+func (m *BookingMockRestaurantRepository) ListByStatus(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) CountByStatus(ctx context.Context, status domain.RestaurantStatus) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BookingMockRestaurantRepository) SetStatus(ctx context.Context, restaurant *domain.Restaurant, from domain.RestaurantStatus) error {
+	args := m.Called(ctx, restaurant, from)
+	return args.Error(0)
+}
+
+func (m *BookingMockRestaurantRepository) WithTx(tx *gorm.DB) repository.RestaurantRepository {
+	return m
+}
+
+func uuidPtr(id uuid.UUID) *uuid.UUID { return &id }
+
 func setupBookingService() (*BookingService, *BookingMockBookingRepository, *BookingMockTableRepository, *BookingMockRestaurantRepository, *NotificationService) {
 	mockBookingRepo := new(BookingMockBookingRepository)
 	mockTableRepo := new(BookingMockTableRepository)
@@ -187,7 +439,11 @@ func setupBookingService() (*BookingService, *BookingMockBookingRepository, *Boo
 		mockBookingRepo,
 		mockTableRepo,
 		mockRestaurantRepo,
+		new(MockPaymentRepository),
+		NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder)),
+		new(MockAuditRecorder),
 		notificationSvc,
+		"7",
 	)
 
 	return service, mockBookingRepo, mockTableRepo, mockRestaurantRepo, notificationSvc
@@ -209,48 +465,13 @@ func TestCreateBookingWithNotification_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, booking)
-	assert.Equal(t, userID, booking.UserID)
+	assert.Equal(t, &userID, booking.UserID)
 	assert.Equal(t, tableID, booking.TableID)
 	assert.Equal(t, domain.BookingStatusPending, booking.Status)
 
 	time.Sleep(50 * time.Millisecond)
 }
 
-func TestCheckMultipleTablesAvailability_Success(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
-	defer notificationSvc.Shutdown()
-
-	ctx := context.Background()
-	tableIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
-	startTime := time.Now().Add(24 * time.Hour)
-	endTime := startTime.Add(2 * time.Hour)
-
-	results := service.CheckMultipleTablesAvailability(ctx, tableIDs, startTime, endTime)
-
-	assert.NotNil(t, results)
-	assert.Equal(t, len(tableIDs), len(results))
-
-	for _, tableID := range tableIDs {
-		availability, exists := results[tableID]
-		assert.True(t, exists)
-		assert.True(t, availability)
-	}
-}
-
-func TestCheckMultipleTablesAvailability_EmptyList(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
-	defer notificationSvc.Shutdown()
-
-	ctx := context.Background()
-	startTime := time.Now().Add(24 * time.Hour)
-	endTime := startTime.Add(2 * time.Hour)
-
-	results := service.CheckMultipleTablesAvailability(ctx, []uuid.UUID{}, startTime, endTime)
-
-	assert.NotNil(t, results)
-	assert.Equal(t, 0, len(results))
-}
-
 func TestProcessBulkBookings_Success(t *testing.T) {
 	service, _, _, _, notificationSvc := setupBookingService()
 	defer notificationSvc.Shutdown()
@@ -259,7 +480,7 @@ func TestProcessBulkBookings_Success(t *testing.T) {
 	bookings := []domain.Booking{
 		{
 			ID:        uuid.New(),
-			UserID:    uuid.New(),
+			UserID:    uuidPtr(uuid.New()),
 			TableID:   uuid.New(),
 			StartTime: time.Now().Add(24 * time.Hour),
 			EndTime:   time.Now().Add(26 * time.Hour),
@@ -267,7 +488,7 @@ func TestProcessBulkBookings_Success(t *testing.T) {
 		},
 		{
 			ID:        uuid.New(),
-			UserID:    uuid.New(),
+			UserID:    uuidPtr(uuid.New()),
 			TableID:   uuid.New(),
 			StartTime: time.Now().Add(48 * time.Hour),
 			EndTime:   time.Now().Add(50 * time.Hour),
@@ -307,7 +528,7 @@ func TestProcessBulkBookings_WithConcurrencyLimit(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		bookings[i] = domain.Booking{
 			ID:        uuid.New(),
-			UserID:    uuid.New(),
+			UserID:    uuidPtr(uuid.New()),
 			TableID:   uuid.New(),
 			StartTime: time.Now().Add(time.Duration(i*24) * time.Hour),
 			EndTime:   time.Now().Add(time.Duration(i*24+2) * time.Hour),
@@ -322,25 +543,67 @@ func TestProcessBulkBookings_WithConcurrencyLimit(t *testing.T) {
 }
 
 func TestSearchAvailableTablesParallel_Success(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
+	service, bookingRepo, tableRepo, _, notificationSvc := setupBookingService()
 	defer notificationSvc.Shutdown()
 
 	ctx := context.Background()
-	restaurantIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	open, broken := uuid.New(), uuid.New()
 	startTime := time.Now().Add(24 * time.Hour)
 	endTime := startTime.Add(2 * time.Hour)
-	guestCount := 4
 
-	results := service.SearchAvailableTablesParallel(ctx, restaurantIDs, startTime, endTime, guestCount)
+	free := &domain.Table{ID: uuid.New(), TableNumber: "T1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationWindow, IsActive: true}
+	booked := &domain.Table{ID: uuid.New(), TableNumber: "T2", MinCapacity: 2, MaxCapacity: 6, IsActive: true}
+	tooSmall := &domain.Table{ID: uuid.New(), TableNumber: "T3", MinCapacity: 1, MaxCapacity: 2, IsActive: true}
+	tooLarge := &domain.Table{ID: uuid.New(), TableNumber: "T4", MinCapacity: 6, MaxCapacity: 10, IsActive: true}
+	tableRepo.On("GetByRestaurantID", ctx, open).Return([]*domain.Table{free, booked, tooSmall, tooLarge}, nil)
+	tableRepo.On("GetByRestaurantID", ctx, broken).Return(nil, errors.New("connection reset"))
+	bookingRepo.On("CheckTableAvailability", ctx, free.ID, startTime, endTime).Return(true, nil)
+	bookingRepo.On("CheckTableAvailability", ctx, booked.ID, startTime, endTime).Return(false, nil)
+
+	results := service.SearchAvailableTablesParallel(ctx, []uuid.UUID{open, broken}, startTime, endTime, 4)
+
+	assert.Equal(t, RestaurantTableSearch{Tables: []*domain.Table{free}}, results[open])
+	assert.False(t, results[broken].TimedOut)
+	assert.Empty(t, results[broken].Tables)
+	assert.EqualError(t, results[broken].Err, "connection reset")
+	bookingRepo.AssertNotCalled(t, "CheckTableAvailability", ctx, tooSmall.ID, startTime, endTime)
+	bookingRepo.AssertNotCalled(t, "CheckTableAvailability", ctx, tooLarge.ID, startTime, endTime)
+}
+
+func TestSearchAvailableTablesParallel_TimeoutKeepsPartialResults(t *testing.T) {
+	service, bookingRepo, tableRepo, _, notificationSvc := setupBookingService()
+	defer notificationSvc.Shutdown()
 
-	assert.NotNil(t, results)
-	assert.Equal(t, len(restaurantIDs), len(results))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	slow, stuck := uuid.New(), uuid.New()
+	startTime := time.Now().Add(24 * time.Hour)
+	endTime := startTime.Add(2 * time.Hour)
 
-	for _, restaurantID := range restaurantIDs {
-		tables, exists := results[restaurantID]
-		assert.True(t, exists)
-		assert.NotEmpty(t, tables)
-	}
+	first := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	second := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	tableRepo.On("GetByRestaurantID", ctx, slow).Return([]*domain.Table{first, second}, nil)
+	bookingRepo.On("CheckTableAvailability", ctx, first.ID, startTime, endTime).Return(true, nil)
+	// The second check runs past the deadline and fails with it.
+	bookingRepo.On("CheckTableAvailability", ctx, second.ID, startTime, endTime).
+		Run(func(tmock.Arguments) { <-ctx.Done() }).
+		Return(false, context.DeadlineExceeded)
+
+	// The stuck restaurant's query ignores ctx altogether.
+	release := make(chan struct{})
+	defer close(release)
+	tableRepo.On("GetByRestaurantID", ctx, stuck).
+		Run(func(tmock.Arguments) { <-release }).
+		Return([]*domain.Table{}, nil)
+
+	results := service.SearchAvailableTablesParallel(ctx, []uuid.UUID{slow, stuck}, startTime, endTime, 4)
+
+	assert.Len(t, results, 2)
+	assert.True(t, results[slow].TimedOut)
+	assert.Equal(t, []*domain.Table{first}, results[slow].Tables)
+	assert.NoError(t, results[slow].Err)
+	assert.True(t, results[stuck].TimedOut)
+	assert.Empty(t, results[stuck].Tables)
 }
 
 func TestSearchAvailableTablesParallel_EmptyRestaurantList(t *testing.T) {
@@ -371,21 +634,128 @@ func TestCancelBookingWithRefund_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// setupBookingStatistics runs GetBookingStatistics against the real
+// repository on sqlmock. The four counts run concurrently, so the queries
+// are matched in any order.
+func setupBookingStatistics(t *testing.T) (*BookingService, sqlmock.Sqlmock, *BookingMockRestaurantRepository) {
+	sqlDB, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	dbMock.MatchExpectationsInOrder(false)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	require.NoError(t, err)
+
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	notificationSvc := NewNotificationService(1, 10)
+	t.Cleanup(notificationSvc.Shutdown)
+	service := NewBookingService(repository.NewBookingRepository(db), new(BookingMockTableRepository), restaurantRepo,
+		new(MockPaymentRepository), nil, new(MockAuditRecorder), notificationSvc, "7")
+	return service, dbMock, restaurantRepo
+}
+
 func TestGetBookingStatistics_Success(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
-	defer notificationSvc.Shutdown()
+	service, dbMock, _ := setupBookingStatistics(t)
+	restaurantID := uuid.New()
+
+	countQuery := `SELECT count\(\*\) FROM "bookings" WHERE restaurant_id = \$1`
+	dbMock.ExpectQuery(countQuery + `$`).WithArgs(restaurantID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(150))
+	dbMock.ExpectQuery(countQuery+` AND status IN \(\$2,\$3\)$`).
+		WithArgs(restaurantID, domain.BookingStatusPending, domain.BookingStatusConfirmed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+	dbMock.ExpectQuery(countQuery+` AND status IN \(\$2\)$`).WithArgs(restaurantID, domain.BookingStatusCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(100))
+	dbMock.ExpectQuery(countQuery+` AND status IN \(\$2\)$`).WithArgs(restaurantID, domain.BookingStatusCancelled).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(20))
+
+	stats, err := service.GetBookingStatistics(context.Background(), restaurantID, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"total_bookings":     150,
+		"active_bookings":    25,
+		"completed_bookings": 100,
+		"cancelled_bookings": 20,
+	}, stats.Counts)
+	assert.Nil(t, stats.Daily)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
 
+func TestGetBookingStatistics_ByDay(t *testing.T) {
+	service, dbMock, restaurantRepo := setupBookingStatistics(t)
 	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), Timezone: "Asia/Almaty"}
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dayQuery := `SELECT TO_CHAR\(start_time AT TIME ZONE \$1, 'YYYY-MM-DD'\) AS day, COUNT\(\*\) AS count FROM "bookings" WHERE `
+	inPeriod := `restaurant_id = \$2 AND start_time >= \$3 AND start_time < \$4`
+	days := func(counts ...interface{}) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"day", "count"})
+		for i := 0; i < len(counts); i += 2 {
+			rows.AddRow(counts[i], counts[i+1])
+		}
+		return rows
+	}
+	dbMock.ExpectQuery(dayQuery+inPeriod+` GROUP BY "day"$`).WithArgs("Asia/Almaty", restaurant.ID, from, to).
+		WillReturnRows(days("2024-06-01", 3, "2024-06-02", 1))
+	dbMock.ExpectQuery(dayQuery+`\(`+inPeriod+`\) AND status IN \(\$5,\$6\) GROUP BY "day"$`).
+		WithArgs("Asia/Almaty", restaurant.ID, from, to, domain.BookingStatusPending, domain.BookingStatusConfirmed).
+		WillReturnRows(days("2024-06-02", 1))
+	dbMock.ExpectQuery(dayQuery+`\(`+inPeriod+`\) AND status IN \(\$5\) GROUP BY "day"$`).
+		WithArgs("Asia/Almaty", restaurant.ID, from, to, domain.BookingStatusCompleted).
+		WillReturnRows(days("2024-06-01", 2))
+	dbMock.ExpectQuery(dayQuery+`\(`+inPeriod+`\) AND status IN \(\$5\) GROUP BY "day"$`).
+		WithArgs("Asia/Almaty", restaurant.ID, from, to, domain.BookingStatusCancelled).
+		WillReturnRows(days("2024-06-01", 1))
+
+	stats, err := service.GetBookingStatistics(ctx, restaurant.ID, &BookingPeriod{From: from, To: to})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"total_bookings":     4,
+		"active_bookings":    1,
+		"completed_bookings": 2,
+		"cancelled_bookings": 1,
+	}, stats.Counts)
+	assert.Equal(t, map[string]map[string]int{
+		"2024-06-01": {"total_bookings": 3, "active_bookings": 0, "completed_bookings": 2, "cancelled_bookings": 1},
+		"2024-06-02": {"total_bookings": 1, "active_bookings": 1, "completed_bookings": 0, "cancelled_bookings": 0},
+	}, stats.Daily)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetBookingStatistics_QueryFails(t *testing.T) {
+	service, dbMock, _ := setupBookingStatistics(t)
 	restaurantID := uuid.New()
 
-	stats, err := service.GetBookingStatistics(ctx, restaurantID)
+	dbMock.ExpectQuery(`SELECT count`).WithArgs(restaurantID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	dbMock.ExpectQuery(`SELECT count`).WithArgs(restaurantID, domain.BookingStatusPending, domain.BookingStatusConfirmed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	dbMock.ExpectQuery(`SELECT count`).WithArgs(restaurantID, domain.BookingStatusCompleted).
+		WillReturnError(errors.New("connection reset"))
+	dbMock.ExpectQuery(`SELECT count`).WithArgs(restaurantID, domain.BookingStatusCancelled).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	assert.NoError(t, err)
-	assert.NotNil(t, stats)
-	assert.Equal(t, 150, stats["total_bookings"])
-	assert.Equal(t, 25, stats["active_bookings"])
-	assert.Equal(t, 100, stats["completed_bookings"])
-	assert.Equal(t, 25, stats["cancelled_bookings"])
+	stats, err := service.GetBookingStatistics(context.Background(), restaurantID, nil)
+
+	assert.Nil(t, stats)
+	assert.ErrorContains(t, err, "count completed_bookings: connection reset")
+}
+
+func TestGetBookingStatistics_InvalidPeriod(t *testing.T) {
+	service, dbMock, restaurantRepo := setupBookingStatistics(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := service.GetBookingStatistics(ctx, uuid.New(), &BookingPeriod{From: now, To: now})
+	assert.ErrorIs(t, err, ErrInvalidStatsRange)
+
+	restaurantID := uuid.New()
+	restaurantRepo.On("GetByID", ctx, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+	_, err = service.GetBookingStatistics(ctx, restaurantID, &BookingPeriod{From: now, To: now.Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
 }
 
 func TestProcessBooking_Success(t *testing.T) {
@@ -395,7 +765,7 @@ func TestProcessBooking_Success(t *testing.T) {
 	ctx := context.Background()
 	booking := &domain.Booking{
 		ID:        uuid.New(),
-		UserID:    uuid.New(),
+		UserID:    uuidPtr(uuid.New()),
 		TableID:   uuid.New(),
 		StartTime: time.Now().Add(24 * time.Hour),
 		EndTime:   time.Now().Add(26 * time.Hour),
@@ -416,7 +786,7 @@ func TestProcessBooking_ContextCancelled(t *testing.T) {
 
 	booking := &domain.Booking{
 		ID:        uuid.New(),
-		UserID:    uuid.New(),
+		UserID:    uuidPtr(uuid.New()),
 		TableID:   uuid.New(),
 		StartTime: time.Now().Add(24 * time.Hour),
 		EndTime:   time.Now().Add(26 * time.Hour),
@@ -456,40 +826,278 @@ func TestConcurrentBookingOperations(t *testing.T) {
 	}
 }
 
-func TestLargeScaleTableAvailabilityCheck(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
+func TestMultipleRestaurantSearch(t *testing.T) {
+	service, bookingRepo, tableRepo, _, notificationSvc := setupBookingService()
 	defer notificationSvc.Shutdown()
 
 	ctx := context.Background()
-	tableIDs := make([]uuid.UUID, 50)
-	for i := 0; i < 50; i++ {
-		tableIDs[i] = uuid.New()
-	}
-
 	startTime := time.Now().Add(24 * time.Hour)
 	endTime := startTime.Add(2 * time.Hour)
 
-	results := service.CheckMultipleTablesAvailability(ctx, tableIDs, startTime, endTime)
-
-	assert.NotNil(t, results)
-	assert.Equal(t, 50, len(results))
-}
-
-func TestMultipleRestaurantSearch(t *testing.T) {
-	service, _, _, _, notificationSvc := setupBookingService()
-	defer notificationSvc.Shutdown()
-
-	ctx := context.Background()
 	restaurantIDs := make([]uuid.UUID, 10)
 	for i := 0; i < 10; i++ {
 		restaurantIDs[i] = uuid.New()
+		table := &domain.Table{ID: uuid.New(), RestaurantID: restaurantIDs[i], MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+		tableRepo.On("GetByRestaurantID", ctx, restaurantIDs[i]).Return([]*domain.Table{table}, nil)
+		bookingRepo.On("CheckTableAvailability", ctx, table.ID, startTime, endTime).Return(true, nil)
 	}
 
-	startTime := time.Now().Add(24 * time.Hour)
-	endTime := startTime.Add(2 * time.Hour)
-
 	results := service.SearchAvailableTablesParallel(ctx, restaurantIDs, startTime, endTime, 4)
 
-	assert.NotNil(t, results)
 	assert.Equal(t, 10, len(results))
+	for _, restaurantID := range restaurantIDs {
+		assert.Len(t, results[restaurantID].Tables, 1)
+		assert.False(t, results[restaurantID].TimedOut)
+	}
+}
+
+// bookingFixture is an Almaty restaurant open 10:00 to 23:00 every day with
+// a table for 2 to 4 guests, and a service whose clock reads Monday
+// 2024-06-03 09:00 in Almaty.
+func bookingFixture() (*BookingService, *BookingMockBookingRepository, *BookingMockTableRepository, *BookingMockRestaurantRepository, *domain.Restaurant, *domain.Table) {
+	service, mockBookingRepo, mockTableRepo, mockRestaurantRepo, _ := setupBookingService()
+	service.now = func() time.Time { return time.Date(2024, 6, 3, 4, 0, 0, 0, time.UTC) }
+
+	hours := domain.WorkingHours{}
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		hours[day] = domain.DaySchedule{OpenTime: "10:00", CloseTime: "23:00"}
+	}
+	restaurant := &domain.Restaurant{ID: uuid.New(), IsActive: true, Timezone: "Asia/Almaty", WorkingHours: hours, LastSeatingOffsetMinutes: 60}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+
+	mockRestaurantRepo.On("GetByID", tmock.Anything, restaurant.ID).Return(restaurant, nil).Maybe()
+	mockTableRepo.On("GetByID", tmock.Anything, table.ID).Return(table, nil).Maybe()
+	return service, mockBookingRepo, mockTableRepo, mockRestaurantRepo, restaurant, table
+}
+
+// bookingRequest asks for the table from 11:00 to 13:00 Almaty time on
+// Tuesday, sent in UTC as clients echoing API timestamps do.
+func bookingRequest(restaurant *domain.Restaurant, table *domain.Table) CreateBookingRequest {
+	start := time.Date(2024, 6, 4, 6, 0, 0, 0, time.UTC)
+	return CreateBookingRequest{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		BookingDate:  start,
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+		GuestsCount:  2,
+	}
+}
+
+func TestCreateBooking_Success(t *testing.T) {
+	service, mockBookingRepo, _, _, restaurant, table := bookingFixture()
+	table.DepositAmount = 10000
+	req := bookingRequest(restaurant, table)
+	req.PaymentMethod = domain.PaymentMethodKaspi
+
+	mockBookingRepo.On("CreateIfAvailable", tmock.Anything, tmock.MatchedBy(func(b *domain.Booking) bool {
+		return b.Table == nil && b.TableID == table.ID && b.StartTime.Equal(req.StartTime) && b.EndTime.Equal(req.EndTime)
+	})).Return(true, nil)
+
+	customerID := uuid.New()
+	booking, err := service.CreateBooking(context.Background(), customerID, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BookingStatusPending, booking.Status)
+	assert.Equal(t, &customerID, booking.UserID)
+	assert.Equal(t, table, booking.Table)
+	if assert.NotNil(t, booking.CancellationPolicy) {
+		assert.Equal(t, 10000, booking.CancellationPolicy.DepositAmount)
+	}
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestCreateBooking_Rejections(t *testing.T) {
+	almaty, _ := time.LoadLocation("Asia/Almaty")
+
+	cases := map[string]struct {
+		change func(req *CreateBookingRequest, table *domain.Table)
+		want   error
+	}{
+		"ends before it starts": {func(req *CreateBookingRequest, _ *domain.Table) { req.EndTime = req.StartTime }, ErrInvalidBookingPeriod},
+		"in the past": {func(req *CreateBookingRequest, _ *domain.Table) {
+			req.StartTime = time.Date(2024, 6, 3, 8, 0, 0, 0, almaty)
+			req.EndTime = req.StartTime.Add(2 * time.Hour)
+		}, ErrPastBooking},
+		"unknown restaurant": {func(req *CreateBookingRequest, _ *domain.Table) { req.RestaurantID = uuid.New() }, ErrRestaurantNotFound},
+		"after the last seating": {func(req *CreateBookingRequest, _ *domain.Table) {
+			req.StartTime = time.Date(2024, 6, 4, 22, 30, 0, 0, almaty)
+			req.EndTime = req.StartTime.Add(time.Hour)
+		}, ErrAfterLastSeating},
+		"while closed": {func(req *CreateBookingRequest, _ *domain.Table) {
+			req.StartTime = time.Date(2024, 6, 4, 2, 0, 0, 0, almaty)
+			req.EndTime = req.StartTime.Add(2 * time.Hour)
+		}, ErrOutsideWorkingHours},
+		"unknown table":             {func(req *CreateBookingRequest, _ *domain.Table) { req.TableID = uuid.New() }, ErrTableNotFound},
+		"table of another place":    {func(_ *CreateBookingRequest, table *domain.Table) { table.RestaurantID = uuid.New() }, ErrTableNotFound},
+		"inactive table":            {func(_ *CreateBookingRequest, table *domain.Table) { table.IsActive = false }, ErrTableNotFound},
+		"too many guests":           {func(req *CreateBookingRequest, _ *domain.Table) { req.GuestsCount = 5 }, ErrGuestsExceedCapacity},
+		"too few guests":            {func(req *CreateBookingRequest, _ *domain.Table) { req.GuestsCount = 1 }, ErrGuestsExceedCapacity},
+		"too short":                 {func(req *CreateBookingRequest, _ *domain.Table) { req.EndTime = req.StartTime.Add(15 * time.Minute) }, ErrDurationTooShort},
+		"deposit without a payment": {func(_ *CreateBookingRequest, table *domain.Table) { table.DepositAmount = 10000 }, ErrDepositPaymentRequired},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mockBookingRepo, mockTableRepo, mockRestaurantRepo, restaurant, table := bookingFixture()
+			mockRestaurantRepo.On("GetByID", tmock.Anything, tmock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+			mockTableRepo.On("GetByID", tmock.Anything, tmock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+			req := bookingRequest(restaurant, table)
+			tc.change(&req, table)
+
+			booking, err := service.CreateBooking(context.Background(), uuid.New(), req)
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.Nil(t, booking)
+			mockBookingRepo.AssertNotCalled(t, "CreateIfAvailable", tmock.Anything, tmock.Anything)
+		})
+	}
+}
+
+func TestCreateBooking_TableTaken(t *testing.T) {
+	service, mockBookingRepo, _, _, restaurant, table := bookingFixture()
+	req := bookingRequest(restaurant, table)
+	mockBookingRepo.On("CreateIfAvailable", tmock.Anything, tmock.AnythingOfType("*domain.Booking")).Return(false, nil)
+
+	booking, err := service.CreateBooking(context.Background(), uuid.New(), req)
+
+	assert.ErrorIs(t, err, ErrTableNotAvailable)
+	assert.Nil(t, booking)
+}
+
+// statusFixture is a confirmed booking of a customer at a restaurant with an
+// owner and one manager. users maps owner, manager, customer and stranger to
+// their IDs.
+func statusFixture() (*BookingService, *BookingMockBookingRepository, *domain.Booking, map[string]uuid.UUID) {
+	service, mockBookingRepo, _, _, _ := setupBookingService()
+	users := map[string]uuid.UUID{"owner": uuid.New(), "manager": uuid.New(), "customer": uuid.New(), "stranger": uuid.New()}
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: users["owner"]}
+	booking := &domain.Booking{ID: uuid.New(), RestaurantID: restaurant.ID, UserID: uuidPtr(users["customer"]), Status: domain.BookingStatusConfirmed, Restaurant: restaurant}
+
+	managerRepo := new(MockRestaurantManagerRepository)
+	managerRepo.On("IsManager", tmock.Anything, users["manager"], restaurant.ID).Return(true, nil)
+	managerRepo.On("IsManager", tmock.Anything, tmock.Anything, restaurant.ID).Return(false, nil)
+	service.authz = NewRestaurantAuthorizer(managerRepo, new(MockAuditRecorder))
+
+	mockBookingRepo.On("GetByID", tmock.Anything, booking.ID).Return(booking, nil)
+	mockBookingRepo.On("Update", tmock.Anything, booking).Return(nil).Maybe()
+	service.paymentRepo.(*MockPaymentRepository).On("VoidPendingByBooking", tmock.Anything, booking.ID).Return(int64(0), nil).Maybe()
+	return service, mockBookingRepo, booking, users
+}
+
+func TestUpdateBookingStatus_Permissions(t *testing.T) {
+	cases := map[string]struct {
+		user    string
+		status  domain.BookingStatus
+		want    error
+		byStaff bool
+	}{
+		"owner completes":       {"owner", domain.BookingStatusCompleted, nil, true},
+		"manager marks no-show": {"manager", domain.BookingStatusNoShow, nil, true},
+		"customer cancels":      {"customer", domain.BookingStatusCancelled, nil, false},
+		"customer completes":    {"customer", domain.BookingStatusCompleted, ErrCustomerCanOnlyCancel, false},
+		"stranger cancels":      {"stranger", domain.BookingStatusCancelled, ErrNotRestaurantStaff, false},
+		"stranger confirms":     {"stranger", domain.BookingStatusConfirmed, ErrNotRestaurantStaff, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mockBookingRepo, booking, users := statusFixture()
+
+			change, err := service.UpdateBookingStatus(context.Background(), booking.ID, users[tc.user], tc.status)
+
+			if tc.want != nil {
+				assert.ErrorIs(t, err, tc.want)
+				assert.Nil(t, change)
+				assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+				mockBookingRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, change.Booking.Status)
+			assert.Equal(t, domain.BookingStatusConfirmed, change.Previous)
+			assert.Equal(t, tc.byStaff, change.ByStaff)
+			mockBookingRepo.AssertCalled(t, "Update", tmock.Anything, booking)
+		})
+	}
+}
+
+func TestUpdateBookingStatus_BookingNotFound(t *testing.T) {
+	service, mockBookingRepo, _, _, _ := setupBookingService()
+	id := uuid.New()
+	mockBookingRepo.On("GetByID", tmock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	change, err := service.UpdateBookingStatus(context.Background(), id, uuid.New(), domain.BookingStatusCancelled)
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+	assert.Nil(t, change)
+}
+
+func TestUpdateBookingStatus_DepositMustBePaid(t *testing.T) {
+	completed := domain.PaymentStatusCompleted
+	for name, tc := range map[string]struct {
+		payer  string
+		amount int
+		want   error
+	}{
+		"unpaid":          {"", 0, ErrDepositUnpaid},
+		"paid":            {"customer", 10000, nil},
+		"below deposit":   {"customer", 1, ErrDepositUnpaid},
+		"paid by someone": {"stranger", 10000, ErrDepositUnpaid},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, mockBookingRepo, booking, users := statusFixture()
+			booking.Status = domain.BookingStatusPending
+			booking.CancellationPolicy = &domain.CancellationPolicy{DepositAmount: 10000}
+			payments := []*domain.Payment{}
+			if tc.payer != "" {
+				payments = append(payments, &domain.Payment{ID: uuid.New(), UserID: users[tc.payer], Amount: tc.amount, PaymentStatus: completed})
+			}
+			mockPaymentRepo := service.paymentRepo.(*MockPaymentRepository)
+			mockPaymentRepo.On("List", tmock.Anything, repository.PaymentFilter{UserID: booking.UserID, BookingID: &booking.ID, Status: &completed}, maxDepositPayments, 0).Return(payments, nil)
+
+			_, err := service.UpdateBookingStatus(context.Background(), booking.ID, users["owner"], domain.BookingStatusConfirmed)
+
+			if tc.want != nil {
+				assert.ErrorIs(t, err, tc.want)
+				assert.Equal(t, domain.BookingStatusPending, booking.Status)
+				mockBookingRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+		})
+	}
+}
+
+func TestUpdateBookingStatus_CancelVoidsDeposit(t *testing.T) {
+	service, _, booking, users := statusFixture()
+	booking.Status = domain.BookingStatusPending
+	booking.CancellationPolicy = &domain.CancellationPolicy{DepositAmount: 10000}
+
+	_, err := service.UpdateBookingStatus(context.Background(), booking.ID, users["customer"], domain.BookingStatusCancelled)
+
+	assert.NoError(t, err)
+	service.paymentRepo.(*MockPaymentRepository).AssertCalled(t, "VoidPendingByBooking", tmock.Anything, booking.ID)
+}
+
+func TestUpdateBookingStatus_StaffCancelVoidsDeposit(t *testing.T) {
+	service, _, booking, users := statusFixture()
+	booking.CancellationPolicy = &domain.CancellationPolicy{DepositAmount: 10000}
+
+	change, err := service.UpdateBookingStatus(context.Background(), booking.ID, users["manager"], domain.BookingStatusCancelled)
+
+	assert.NoError(t, err)
+	assert.True(t, change.ByStaff)
+	service.paymentRepo.(*MockPaymentRepository).AssertCalled(t, "VoidPendingByBooking", tmock.Anything, booking.ID)
+}
+
+func TestUpdateBookingStatus_RejectedCancelKeepsDeposit(t *testing.T) {
+	service, _, booking, users := statusFixture()
+	booking.Status = domain.BookingStatusPending
+	booking.CancellationPolicy = &domain.CancellationPolicy{DepositAmount: 10000}
+
+	_, err := service.UpdateBookingStatus(context.Background(), booking.ID, users["stranger"], domain.BookingStatusCancelled)
+
+	assert.ErrorIs(t, err, ErrNotRestaurantStaff)
+	assert.Equal(t, domain.BookingStatusPending, booking.Status)
+	service.paymentRepo.(*MockPaymentRepository).AssertNotCalled(t, "VoidPendingByBooking", tmock.Anything, tmock.Anything)
 }
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// BusynessHistory is how far back bookings count towards busyness.
+	BusynessHistory = 8 * 7 * 24 * time.Hour
+	// MinBusynessBookings is the history below which no labels are shown.
+	MinBusynessBookings = 20
+
+	// busynessPercentile picks the occupancy a restaurant usually has at a
+	// given weekday and hour out of the weeks in BusynessHistory.
+	busynessPercentile = 0.5
+	busynessPageSize   = 100
+)
+
+type BusynessLevel string
+
+const (
+	BusynessQuiet    BusynessLevel = "quiet"
+	BusynessModerate BusynessLevel = "moderate"
+	BusynessBusy     BusynessLevel = "busy"
+)
+
+// BusynessThresholds are the occupancy percentages from which an hour counts
+// as moderate or busy.
+type BusynessThresholds struct {
+	Moderate int
+	Busy     int
+}
+
+func DefaultBusynessThresholds() BusynessThresholds {
+	return BusynessThresholds{Moderate: 40, Busy: 70}
+}
+
+func (t BusynessThresholds) Level(occupancy int) BusynessLevel {
+	switch {
+	case occupancy >= t.Busy:
+		return BusynessBusy
+	case occupancy >= t.Moderate:
+		return BusynessModerate
+	default:
+		return BusynessQuiet
+	}
+}
+
+// BusynessDay labels each hour of Date. Hours is empty when InsufficientData
+// is set.
+type BusynessDay struct {
+	Date             time.Time
+	InsufficientData bool
+	Hours            []BusynessLevel
+}
+
+type BusynessService interface {
+	// Recompute rebuilds the stored occupancy grid of one restaurant from
+	// the bookings in the BusynessHistory before now.
+	Recompute(ctx context.Context, restaurantID uuid.UUID, now time.Time) error
+	// RecomputeAll runs Recompute for every restaurant. A failing restaurant
+	// is logged and skipped.
+	RecomputeAll(ctx context.Context, now time.Time) error
+	ForDay(ctx context.Context, restaurantID uuid.UUID, day time.Time) (*BusynessDay, error)
+}
+
+type busynessService struct {
+	busynessRepo   repository.BusynessRepository
+	bookingRepo    repository.BookingRepository
+	tableRepo      repository.TableRepository
+	restaurantRepo repository.RestaurantRepository
+	thresholds     BusynessThresholds
+	log            logger.Logger
+}
+
+func NewBusynessService(
+	busynessRepo repository.BusynessRepository,
+	bookingRepo repository.BookingRepository,
+	tableRepo repository.TableRepository,
+	restaurantRepo repository.RestaurantRepository,
+	thresholds BusynessThresholds,
+	log logger.Logger,
+) BusynessService {
+	return &busynessService{
+		busynessRepo:   busynessRepo,
+		bookingRepo:    bookingRepo,
+		tableRepo:      tableRepo,
+		restaurantRepo: restaurantRepo,
+		thresholds:     thresholds,
+		log:            log,
+	}
+}
+
+func (s *busynessService) Recompute(ctx context.Context, restaurantID uuid.UUID, now time.Time) error {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := to.Add(-BusynessHistory)
+
+	tables, err := s.tableRepo.GetByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	bookings, err := s.bookingRepo.GetHistory(ctx, restaurantID, from, to)
+	if err != nil {
+		return err
+	}
+
+	return s.busynessRepo.Upsert(ctx, &domain.RestaurantBusyness{
+		RestaurantID: restaurantID,
+		Occupancy:    OccupancyGrid(bookings, len(tables), from, to, busynessPercentile),
+		BookingCount: len(bookings),
+		ComputedAt:   now,
+	})
+}
+
+func (s *busynessService) RecomputeAll(ctx context.Context, now time.Time) error {
+	for offset := 0; ; offset += busynessPageSize {
+		restaurants, err := s.restaurantRepo.ListColumns(ctx, []string{"id"}, false, repository.RestaurantFilter{}, busynessPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, restaurant := range restaurants {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.Recompute(ctx, restaurant.ID, now); err != nil {
+				s.log.Warn("failed to recompute restaurant busyness",
+					zap.String("restaurant_id", restaurant.ID.String()),
+					zap.Error(err))
+			}
+		}
+
+		if len(restaurants) < busynessPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *busynessService) ForDay(ctx context.Context, restaurantID uuid.UUID, day time.Time) (*BusynessDay, error) {
+	result := &BusynessDay{Date: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())}
+
+	busyness, err := s.busynessRepo.GetByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.InsufficientData = true
+			return result, nil
+		}
+		return nil, err
+	}
+	if busyness.BookingCount < MinBusynessBookings {
+		result.InsufficientData = true
+		return result, nil
+	}
+
+	result.Hours = make([]BusynessLevel, 24)
+	for hour, occupancy := range busyness.Occupancy[day.Weekday()] {
+		result.Hours[hour] = s.thresholds.Level(occupancy)
+	}
+	return result, nil
+}
+
+// OccupancyGrid computes, for every weekday and hour, the given percentile
+// of the share of tables booked during that hour across the days in
+// [from, to). Days and hours follow from's location. A table counts as
+// booked for an hour if any booking on it overlaps that hour.
+func OccupancyGrid(bookings []*domain.Booking, tableCount int, from, to time.Time, percentile float64) domain.OccupancyGrid {
+	var grid domain.OccupancyGrid
+	if tableCount <= 0 {
+		return grid
+	}
+
+	loc := from.Location()
+	// Keyed by the Unix time of the hour start.
+	booked := make(map[int64]map[uuid.UUID]struct{})
+	for _, booking := range bookings {
+		start := booking.StartTime.In(loc)
+		hour := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, loc)
+		for ; hour.Before(booking.EndTime); hour = hour.Add(time.Hour) {
+			key := hour.Unix()
+			if booked[key] == nil {
+				booked[key] = make(map[uuid.UUID]struct{})
+			}
+			booked[key][booking.TableID] = struct{}{}
+		}
+	}
+
+	var samples [7][24][]int
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for hour := 0; hour < 24; hour++ {
+			tables := len(booked[time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, loc).Unix()])
+			occupancy := tables * 100 / tableCount
+			if occupancy > 100 {
+				occupancy = 100
+			}
+			samples[day.Weekday()][hour] = append(samples[day.Weekday()][hour], occupancy)
+		}
+	}
+
+	for weekday := range samples {
+		for hour, values := range samples[weekday] {
+			grid[weekday][hour] = percentileOf(values, percentile)
+		}
+	}
+	return grid
+}
+
+// percentileOf returns the nearest-rank percentile of values, or 0 for none.
+func percentileOf(values []int, percentile float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	rank := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
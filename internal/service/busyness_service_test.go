@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MockBusynessRepository struct {
+	mock.Mock
+}
+
+func (m *MockBusynessRepository) Upsert(ctx context.Context, busyness *domain.RestaurantBusyness) error {
+	args := m.Called(ctx, busyness)
+	return args.Error(0)
+}
+
+func (m *MockBusynessRepository) GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantBusyness, error) {
+	args := m.Called(ctx, restaurantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RestaurantBusyness), args.Error(1)
+}
+
+func setupBusynessService() (BusynessService, *MockBusynessRepository, *MockTableRepository, *BookingMockBookingRepository) {
+	busynessRepo := new(MockBusynessRepository)
+	tableRepo := new(MockTableRepository)
+	bookingRepo := new(BookingMockBookingRepository)
+	service := NewBusynessService(busynessRepo, bookingRepo, tableRepo, new(BookingMockRestaurantRepository), DefaultBusynessThresholds(), zap.NewNop())
+	return service, busynessRepo, tableRepo, bookingRepo
+}
+
+func TestOccupancyGrid(t *testing.T) {
+	// Four Saturdays, 2024-06-01 to 2024-06-22.
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 28)
+	tableA, tableB := uuid.New(), uuid.New()
+
+	var bookings []*domain.Booking
+	for week := 0; week < 3; week++ {
+		start := from.AddDate(0, 0, 7*week).Add(19*time.Hour + 30*time.Minute)
+		bookings = append(bookings,
+			&domain.Booking{TableID: tableA, StartTime: start, EndTime: start.Add(2 * time.Hour)},
+			&domain.Booking{TableID: tableB, StartTime: start, EndTime: start.Add(time.Hour)},
+		)
+	}
+	// A second booking on the same table in the same hour counts once.
+	late := from.Add(19*time.Hour + 50*time.Minute)
+	bookings = append(bookings, &domain.Booking{TableID: tableA, StartTime: late, EndTime: late.Add(5 * time.Minute)})
+
+	grid := OccupancyGrid(bookings, 2, from, to, 0.5)
+
+	saturday := grid[time.Saturday]
+	assert.Equal(t, 0, saturday[18])
+	assert.Equal(t, 100, saturday[19])
+	assert.Equal(t, 100, saturday[20])
+	assert.Equal(t, 50, saturday[21])
+	assert.Equal(t, 0, saturday[22])
+	assert.Equal(t, domain.OccupancyGrid{}[time.Sunday], grid[time.Sunday])
+
+	// Only three of the four Saturdays were busy: the busiest one is full
+	// and the quietest empty.
+	assert.Equal(t, 100, OccupancyGrid(bookings, 2, from, to, 1)[time.Saturday][19])
+	assert.Equal(t, 0, OccupancyGrid(bookings, 2, from, to, 0)[time.Saturday][19])
+}
+
+func TestOccupancyGrid_NoTables(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	bookings := []*domain.Booking{{TableID: uuid.New(), StartTime: from.Add(19 * time.Hour), EndTime: from.Add(21 * time.Hour)}}
+
+	assert.Equal(t, domain.OccupancyGrid{}, OccupancyGrid(bookings, 0, from, from.AddDate(0, 0, 7), 0.5))
+}
+
+func TestBusynessThresholds_Level(t *testing.T) {
+	thresholds := BusynessThresholds{Moderate: 40, Busy: 70}
+
+	assert.Equal(t, BusynessQuiet, thresholds.Level(39))
+	assert.Equal(t, BusynessModerate, thresholds.Level(40))
+	assert.Equal(t, BusynessModerate, thresholds.Level(69))
+	assert.Equal(t, BusynessBusy, thresholds.Level(70))
+}
+
+func TestBusynessForDay_Labels(t *testing.T) {
+	service, busynessRepo, _, _ := setupBusynessService()
+	ctx := context.Background()
+	restaurantID := uuid.New()
+
+	var grid domain.OccupancyGrid
+	grid[time.Saturday][13] = 45
+	grid[time.Saturday][20] = 90
+	busynessRepo.On("GetByRestaurantID", ctx, restaurantID).
+		Return(&domain.RestaurantBusyness{RestaurantID: restaurantID, Occupancy: grid, BookingCount: MinBusynessBookings}, nil)
+
+	day, err := service.ForDay(ctx, restaurantID, time.Date(2024, 6, 1, 15, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.False(t, day.InsufficientData)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), day.Date)
+	require.Len(t, day.Hours, 24)
+	assert.Equal(t, BusynessQuiet, day.Hours[12])
+	assert.Equal(t, BusynessModerate, day.Hours[13])
+	assert.Equal(t, BusynessBusy, day.Hours[20])
+}
+
+func TestBusynessForDay_InsufficientData(t *testing.T) {
+	service, busynessRepo, _, _ := setupBusynessService()
+	ctx := context.Background()
+	sparse, missing := uuid.New(), uuid.New()
+
+	busynessRepo.On("GetByRestaurantID", ctx, sparse).
+		Return(&domain.RestaurantBusyness{RestaurantID: sparse, BookingCount: MinBusynessBookings - 1}, nil)
+	busynessRepo.On("GetByRestaurantID", ctx, missing).Return(nil, gorm.ErrRecordNotFound)
+
+	for _, id := range []uuid.UUID{sparse, missing} {
+		day, err := service.ForDay(ctx, id, time.Now())
+
+		require.NoError(t, err)
+		assert.True(t, day.InsufficientData)
+		assert.Empty(t, day.Hours)
+	}
+}
+
+func TestBusynessRecompute(t *testing.T) {
+	service, busynessRepo, tableRepo, bookingRepo := setupBusynessService()
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	now := time.Date(2024, 7, 27, 3, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 27, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-BusynessHistory)
+
+	table := &domain.Table{ID: uuid.New()}
+	start := time.Date(2024, 7, 20, 19, 0, 0, 0, time.UTC)
+	tableRepo.On("GetByRestaurantID", ctx, restaurantID).Return([]*domain.Table{table}, nil)
+	bookingRepo.On("GetHistory", ctx, restaurantID, from, to).Return([]*domain.Booking{
+		{TableID: table.ID, StartTime: start, EndTime: start.Add(time.Hour)},
+	}, nil)
+	busynessRepo.On("Upsert", ctx, mock.MatchedBy(func(busyness *domain.RestaurantBusyness) bool {
+		return busyness.RestaurantID == restaurantID &&
+			busyness.BookingCount == 1 &&
+			busyness.ComputedAt.Equal(now)
+	})).Return(nil)
+
+	require.NoError(t, service.Recompute(ctx, restaurantID, now))
+	busynessRepo.AssertExpectations(t)
+}
+
+func TestNextAnalyticsRun(t *testing.T) {
+	before := time.Date(2024, 6, 1, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC), nextAnalyticsRun(before, 3))
+
+	at := time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC), nextAnalyticsRun(at, 3))
+}
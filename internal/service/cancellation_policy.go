@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"strings"
+)
+
+var ErrInvalidCancellationPolicy = errors.New("invalid cancellation policy")
+
+// maxFreeCancellationHours keeps the cutoff within a month of the booking.
+const maxFreeCancellationHours = 30 * 24
+
+// DefaultLanguage is used for policy summaries when none of the requested
+// languages is in the catalog.
+const DefaultLanguage = "en"
+
+// policyMessages is the message catalog for cancellation policy summaries.
+var policyMessages = map[string]map[string]string{
+	"en": {
+		"free_until_start":  "Free cancellation until the booking starts.",
+		"free_until_cutoff": "Free cancellation up to %d hours before the booking.",
+		"late_fee":          "Cancelling after that costs %d KZT.",
+		"no_deposit":        "No deposit is required.",
+		"deposit_refunded":  "A deposit of %d KZT is required and is refunded if you cancel in time.",
+		"deposit_kept":      "A deposit of %d KZT is required and is not refunded.",
+		"no_show_fee":       "Not showing up costs %d KZT.",
+	},
+	"ru": {
+		"free_until_start":  "Бесплатная отмена до начала бронирования.",
+		"free_until_cutoff": "Бесплатная отмена не позднее чем за %d ч до начала бронирования.",
+		"late_fee":          "Более поздняя отмена стоит %d KZT.",
+		"no_deposit":        "Депозит не требуется.",
+		"deposit_refunded":  "Требуется депозит %d KZT, он возвращается при своевременной отмене.",
+		"deposit_kept":      "Требуется депозит %d KZT, он не возвращается.",
+		"no_show_fee":       "Неявка стоит %d KZT.",
+	},
+}
+
+func validateCancellationPolicy(policy domain.CancellationPolicy) error {
+	switch {
+	case policy.FreeCancellationHours < 0 || policy.FreeCancellationHours > maxFreeCancellationHours:
+		return fmt.Errorf("%w: free_cancellation_hours must be between 0 and %d", ErrInvalidCancellationPolicy, maxFreeCancellationHours)
+	case policy.LateCancellationFee < 0:
+		return fmt.Errorf("%w: late_cancellation_fee cannot be negative", ErrInvalidCancellationPolicy)
+	case policy.DepositAmount < 0:
+		return fmt.Errorf("%w: deposit_amount cannot be negative", ErrInvalidCancellationPolicy)
+	case policy.NoShowFee < 0:
+		return fmt.Errorf("%w: no_show_fee cannot be negative", ErrInvalidCancellationPolicy)
+	}
+	return nil
+}
+
+// TablePolicy is the policy a booking of table is made under: the
+// restaurant's, with the table's own deposit in place of the restaurant's
+// when it has one.
+func TablePolicy(restaurant *domain.Restaurant, table *domain.Table) domain.CancellationPolicy {
+	policy := restaurant.CancellationPolicy
+	if table.DepositAmount > 0 {
+		policy.DepositAmount = table.DepositAmount
+	}
+	return policy
+}
+
+// PolicySummary is the policy as customers read it before booking or
+// paying, in the first of languages the catalog has, or in DefaultLanguage.
+func PolicySummary(policy domain.CancellationPolicy, languages []string) string {
+	messages := policyMessages[DefaultLanguage]
+	for _, lang := range languages {
+		if catalog, ok := policyMessages[lang]; ok {
+			messages = catalog
+			break
+		}
+	}
+
+	var sentences []string
+	if policy.FreeCancellationHours > 0 {
+		sentences = append(sentences, fmt.Sprintf(messages["free_until_cutoff"], policy.FreeCancellationHours))
+	} else {
+		sentences = append(sentences, messages["free_until_start"])
+	}
+	if policy.LateCancellationFee > 0 {
+		sentences = append(sentences, fmt.Sprintf(messages["late_fee"], policy.LateCancellationFee))
+	}
+	switch {
+	case policy.DepositAmount == 0:
+		sentences = append(sentences, messages["no_deposit"])
+	case policy.DepositRefundable:
+		sentences = append(sentences, fmt.Sprintf(messages["deposit_refunded"], policy.DepositAmount))
+	default:
+		sentences = append(sentences, fmt.Sprintf(messages["deposit_kept"], policy.DepositAmount))
+	}
+	if policy.NoShowFee > 0 {
+		sentences = append(sentences, fmt.Sprintf(messages["no_show_fee"], policy.NoShowFee))
+	}
+	return strings.Join(sentences, " ")
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicySummary(t *testing.T) {
+	strict := domain.CancellationPolicy{FreeCancellationHours: 24, LateCancellationFee: 5000, DepositAmount: 10000}
+	refundable := domain.CancellationPolicy{FreeCancellationHours: 2, DepositAmount: 5000, DepositRefundable: true}
+
+	tests := []struct {
+		name      string
+		policy    domain.CancellationPolicy
+		languages []string
+		want      string
+	}{
+		{"default policy", domain.CancellationPolicy{}, nil,
+			"Free cancellation until the booking starts. No deposit is required."},
+		{"fee and kept deposit", strict, []string{"en"},
+			"Free cancellation up to 24 hours before the booking. Cancelling after that costs 5000 KZT. A deposit of 10000 KZT is required and is not refunded."},
+		{"refundable deposit", refundable, nil,
+			"Free cancellation up to 2 hours before the booking. A deposit of 5000 KZT is required and is refunded if you cancel in time."},
+		{"russian", strict, []string{"ru"},
+			"Бесплатная отмена не позднее чем за 24 ч до начала бронирования. Более поздняя отмена стоит 5000 KZT. Требуется депозит 10000 KZT, он не возвращается."},
+		{"first language in the catalog", domain.CancellationPolicy{}, []string{"kk", "ru", "en"},
+			"Бесплатная отмена до начала бронирования. Депозит не требуется."},
+		{"unknown language", domain.CancellationPolicy{}, []string{"de"},
+			"Free cancellation until the booking starts. No deposit is required."},
+		{"no-show fee", domain.CancellationPolicy{NoShowFee: 3000}, nil,
+			"Free cancellation until the booking starts. No deposit is required. Not showing up costs 3000 KZT."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PolicySummary(tt.policy, tt.languages))
+		})
+	}
+}
+
+func TestCancellationPolicyVersion(t *testing.T) {
+	policy := domain.CancellationPolicy{FreeCancellationHours: 24, DepositAmount: 5000}
+	same := policy
+	changed := policy
+	changed.DepositRefundable = true
+
+	assert.Len(t, policy.Version(), 16)
+	assert.Equal(t, policy.Version(), same.Version())
+	assert.NotEqual(t, policy.Version(), changed.Version())
+
+	booking := &domain.Booking{}
+	booking.ApplyPolicy(policy)
+	policy.DepositAmount = 0
+	assert.Equal(t, 5000, booking.CancellationPolicy.DepositAmount, "the booking keeps its own copy")
+	assert.Equal(t, same.Version(), booking.PolicyVersion)
+}
+
+func TestValidateCancellationPolicy(t *testing.T) {
+	assert.NoError(t, validateCancellationPolicy(domain.CancellationPolicy{}))
+	assert.NoError(t, validateCancellationPolicy(domain.CancellationPolicy{FreeCancellationHours: maxFreeCancellationHours}))
+	for _, policy := range []domain.CancellationPolicy{
+		{FreeCancellationHours: -1},
+		{FreeCancellationHours: maxFreeCancellationHours + 1},
+		{LateCancellationFee: -100},
+		{DepositAmount: -100},
+		{NoShowFee: -100},
+	} {
+		assert.ErrorIs(t, validateCancellationPolicy(policy), ErrInvalidCancellationPolicy)
+	}
+}
+
+func TestTablePolicy(t *testing.T) {
+	restaurant := &domain.Restaurant{CancellationPolicy: domain.CancellationPolicy{FreeCancellationHours: 24, DepositAmount: 5000, DepositRefundable: true}}
+
+	assert.Equal(t, restaurant.CancellationPolicy, TablePolicy(restaurant, &domain.Table{}))
+
+	vip := TablePolicy(restaurant, &domain.Table{DepositAmount: 10000})
+	assert.Equal(t, 10000, vip.DepositAmount)
+	assert.Equal(t, 24, vip.FreeCancellationHours)
+	assert.True(t, vip.DepositRefundable)
+	assert.Equal(t, 5000, restaurant.CancellationPolicy.DepositAmount)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FavoriteService interface {
+	// AddFavorite bookmarks an active restaurant for the user. Adding a
+	// favorite the user already has changes nothing.
+	AddFavorite(ctx context.Context, userID, restaurantID uuid.UUID) error
+	// RemoveFavorite succeeds whether or not the restaurant was a favorite.
+	RemoveFavorite(ctx context.Context, userID, restaurantID uuid.UUID) error
+	// ListFavorites leaves out deactivated restaurants.
+	ListFavorites(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Favorite, error)
+	IsFavorite(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error)
+}
+
+type favoriteService struct {
+	favoriteRepo   repository.FavoriteRepository
+	restaurantRepo repository.RestaurantRepository
+}
+
+func NewFavoriteService(favoriteRepo repository.FavoriteRepository, restaurantRepo repository.RestaurantRepository) FavoriteService {
+	return &favoriteService{favoriteRepo: favoriteRepo, restaurantRepo: restaurantRepo}
+}
+
+func (s *favoriteService) AddFavorite(ctx context.Context, userID, restaurantID uuid.UUID) error {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRestaurantNotFound
+		}
+		return err
+	}
+	if !restaurant.IsActive {
+		return ErrRestaurantNotFound
+	}
+
+	return s.favoriteRepo.Add(ctx, &domain.Favorite{UserID: userID, RestaurantID: restaurantID})
+}
+
+func (s *favoriteService) RemoveFavorite(ctx context.Context, userID, restaurantID uuid.UUID) error {
+	return s.favoriteRepo.Remove(ctx, userID, restaurantID)
+}
+
+func (s *favoriteService) ListFavorites(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Favorite, error) {
+	return s.favoriteRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *favoriteService) IsFavorite(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error) {
+	return s.favoriteRepo.Exists(ctx, userID, restaurantID)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockFavoriteRepository struct {
+	mock.Mock
+}
+
+func (m *MockFavoriteRepository) Add(ctx context.Context, favorite *domain.Favorite) error {
+	args := m.Called(ctx, favorite)
+	return args.Error(0)
+}
+
+func (m *MockFavoriteRepository) Remove(ctx context.Context, userID, restaurantID uuid.UUID) error {
+	args := m.Called(ctx, userID, restaurantID)
+	return args.Error(0)
+}
+
+func (m *MockFavoriteRepository) Exists(ctx context.Context, userID, restaurantID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, restaurantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Favorite, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]*domain.Favorite), args.Error(1)
+}
+
+func TestAddFavorite_ActiveRestaurant(t *testing.T) {
+	favoriteRepo := new(MockFavoriteRepository)
+	restaurantRepo := new(MockRestaurantRepository)
+	service := NewFavoriteService(favoriteRepo, restaurantRepo)
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+
+	restaurantRepo.On("GetByID", ctx, restaurantID).Return(&domain.Restaurant{ID: restaurantID, IsActive: true}, nil)
+	favoriteRepo.On("Add", ctx, &domain.Favorite{UserID: userID, RestaurantID: restaurantID}).Return(nil)
+
+	assert.NoError(t, service.AddFavorite(ctx, userID, restaurantID))
+	favoriteRepo.AssertExpectations(t)
+}
+
+func TestAddFavorite_MissingOrInactiveRestaurant(t *testing.T) {
+	favoriteRepo := new(MockFavoriteRepository)
+	restaurantRepo := new(MockRestaurantRepository)
+	service := NewFavoriteService(favoriteRepo, restaurantRepo)
+	ctx := context.Background()
+	missingID, inactiveID := uuid.New(), uuid.New()
+
+	restaurantRepo.On("GetByID", ctx, missingID).Return(nil, gorm.ErrRecordNotFound)
+	restaurantRepo.On("GetByID", ctx, inactiveID).Return(&domain.Restaurant{ID: inactiveID, IsActive: false}, nil)
+
+	assert.ErrorIs(t, service.AddFavorite(ctx, uuid.New(), missingID), ErrRestaurantNotFound)
+	assert.ErrorIs(t, service.AddFavorite(ctx, uuid.New(), inactiveID), ErrRestaurantNotFound)
+	favoriteRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGuestNameRequired  = errors.New("guest_name is required")
+	ErrGuestPhoneRequired = errors.New("guest_phone is required")
+)
+
+// GuestBookingRequest is a booking staff member StaffID takes for a guest
+// without an account, such as a reservation made over the phone.
+type GuestBookingRequest struct {
+	RestaurantID uuid.UUID
+	TableID      uuid.UUID
+	StaffID      uuid.UUID
+	GuestName    string
+	GuestPhone   string
+	StartTime    time.Time
+	EndTime      time.Time
+	GuestsCount  int
+	SpecialNote  string
+}
+
+// CreateGuestBooking books the table for a guest on behalf of staff of the
+// restaurant, checked like any other booking. Staff take it themselves, so
+// it is saved confirmed, and no deposit is asked for since the guest has no
+// wallet to pay it from.
+func (s *BookingService) CreateGuestBooking(ctx context.Context, req GuestBookingRequest) (*domain.Booking, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if err := s.authz.CanStaffRestaurant(ctx, restaurant, req.StaffID, "booking.create_guest"); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.GuestName)
+	if name == "" {
+		return nil, ErrGuestNameRequired
+	}
+	phone, err := normalizePhone(strings.TrimSpace(req.GuestPhone), s.phoneCountryCode)
+	if err != nil {
+		return nil, err
+	}
+	if phone == "" {
+		return nil, ErrGuestPhoneRequired
+	}
+
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidBookingPeriod
+	}
+	if !req.StartTime.After(s.now()) {
+		return nil, ErrPastBooking
+	}
+
+	table, err := s.checkSlot(ctx, restaurant, req.TableID, req.StartTime, req.EndTime, req.GuestsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	localStart := req.StartTime.In(restaurant.Location())
+	booking := &domain.Booking{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		BookingDate:  time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, localStart.Location()),
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		GuestsCount:  req.GuestsCount,
+		SpecialNote:  req.SpecialNote,
+		Status:       domain.BookingStatusConfirmed,
+		IsGuest:      true,
+		GuestName:    name,
+		GuestPhone:   phone,
+	}
+	policy := TablePolicy(restaurant, table)
+	policy.DepositAmount = 0
+	booking.ApplyPolicy(policy)
+
+	created, err := s.bookingRepo.CreateIfAvailable(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrTableNotAvailable
+	}
+
+	// Set after Create, so saving the booking does not touch the table.
+	booking.Table = table
+	return booking, nil
+}
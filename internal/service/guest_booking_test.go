@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// guestBookingRequest is bookingRequest taken by the restaurant's owner
+// for a guest without an account.
+func guestBookingRequest(restaurant *domain.Restaurant, table *domain.Table) GuestBookingRequest {
+	req := bookingRequest(restaurant, table)
+	return GuestBookingRequest{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		StaffID:      restaurant.OwnerID,
+		GuestName:    "Aigerim",
+		GuestPhone:   "8 701 123 45 67",
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		GuestsCount:  req.GuestsCount,
+	}
+}
+
+func TestCreateGuestBooking_Success(t *testing.T) {
+	service, mockBookingRepo, _, _, restaurant, table := bookingFixture()
+	restaurant.OwnerID = uuid.New()
+	table.DepositAmount = 10000
+	req := guestBookingRequest(restaurant, table)
+
+	mockBookingRepo.On("CreateIfAvailable", tmock.Anything, tmock.MatchedBy(func(b *domain.Booking) bool {
+		return b.UserID == nil && b.Table == nil
+	})).Return(true, nil)
+
+	booking, err := service.CreateGuestBooking(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.True(t, booking.IsGuest)
+	assert.Nil(t, booking.UserID)
+	assert.Equal(t, "Aigerim", booking.GuestName)
+	assert.Equal(t, "+77011234567", booking.GuestPhone)
+	assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+	assert.Equal(t, 0, booking.CancellationPolicy.DepositAmount)
+	assert.Equal(t, table, booking.Table)
+	assert.Equal(t, 4, booking.BookingDate.Day())
+}
+
+func TestCreateGuestBooking_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		change func(req *GuestBookingRequest)
+		taken  bool
+		want   error
+	}{
+		"not staff":       {func(req *GuestBookingRequest) { req.StaffID = uuid.New() }, false, ErrNotRestaurantStaff},
+		"no name":         {func(req *GuestBookingRequest) { req.GuestName = "  " }, false, ErrGuestNameRequired},
+		"no phone":        {func(req *GuestBookingRequest) { req.GuestPhone = "" }, false, ErrGuestPhoneRequired},
+		"bad phone":       {func(req *GuestBookingRequest) { req.GuestPhone = "call me" }, false, ErrInvalidPhone},
+		"in the past":     {func(req *GuestBookingRequest) { req.StartTime = req.StartTime.AddDate(0, 0, -2) }, false, ErrPastBooking},
+		"too many guests": {func(req *GuestBookingRequest) { req.GuestsCount = 5 }, false, ErrGuestsExceedCapacity},
+		"table taken":     {func(req *GuestBookingRequest) {}, true, ErrTableNotAvailable},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mockBookingRepo, _, _, restaurant, table := bookingFixture()
+			restaurant.OwnerID = uuid.New()
+			managerRepo := new(MockRestaurantManagerRepository)
+			managerRepo.On("IsManager", tmock.Anything, tmock.Anything, restaurant.ID).Return(false, nil)
+			service.authz = NewRestaurantAuthorizer(managerRepo, new(MockAuditRecorder))
+			mockBookingRepo.On("CreateIfAvailable", tmock.Anything, tmock.Anything).Return(!tc.taken, nil).Maybe()
+			req := guestBookingRequest(restaurant, table)
+			tc.change(&req)
+
+			booking, err := service.CreateGuestBooking(context.Background(), req)
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.Nil(t, booking)
+		})
+	}
+}
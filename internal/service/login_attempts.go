@@ -0,0 +1,119 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginAttemptStore keeps failed login attempts per key (an email or an IP).
+// The in-memory store is enough for a single instance; a shared backend such
+// as Redis can implement the same interface later.
+type LoginAttemptStore interface {
+	// AddFailure records a failure at the given time and returns how many
+	// failures for the key fall within the window ending at that time.
+	AddFailure(key string, at time.Time, window time.Duration) (int, error)
+	Lock(key string, until time.Time) error
+	LockedUntil(key string) (time.Time, error)
+	Reset(key string) error
+}
+
+// LoginPolicy limits failed logins within a sliding window.
+type LoginPolicy struct {
+	MaxAttempts      int
+	MaxAttemptsPerIP int
+	Window           time.Duration
+	LockoutDuration  time.Duration
+}
+
+func DefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{
+		MaxAttempts:      5,
+		MaxAttemptsPerIP: 20,
+		Window:           15 * time.Minute,
+		LockoutDuration:  15 * time.Minute,
+	}
+}
+
+type inMemoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	failures  map[string][]time.Time
+	locks     map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewInMemoryLoginAttemptStore() LoginAttemptStore {
+	return &inMemoryLoginAttemptStore{
+		failures: make(map[string][]time.Time),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (s *inMemoryLoginAttemptStore) AddFailure(key string, at time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := at.Add(-window)
+	if at.Sub(s.lastSweep) >= window {
+		s.sweep(cutoff, at)
+		s.lastSweep = at
+	}
+
+	recent := s.failures[key][:0]
+	for _, failedAt := range s.failures[key] {
+		if failedAt.After(cutoff) {
+			recent = append(recent, failedAt)
+		}
+	}
+	recent = append(recent, at)
+	s.failures[key] = recent
+
+	return len(recent), nil
+}
+
+// sweep drops keys that have no failures inside the window and no active
+// lock, so one-off emails and IPs do not accumulate forever.
+func (s *inMemoryLoginAttemptStore) sweep(cutoff, now time.Time) {
+	for key, until := range s.locks {
+		if !now.Before(until) {
+			delete(s.locks, key)
+		}
+	}
+	for key, failures := range s.failures {
+		if _, locked := s.locks[key]; locked {
+			continue
+		}
+		if len(failures) == 0 || !failures[len(failures)-1].After(cutoff) {
+			delete(s.failures, key)
+		}
+	}
+}
+
+func (s *inMemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[key] = until
+	return nil
+}
+
+func (s *inMemoryLoginAttemptStore) LockedUntil(key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.locks[key]
+	if ok && !time.Now().Before(until) {
+		delete(s.locks, key)
+		delete(s.failures, key)
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+func (s *inMemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	delete(s.locks, key)
+	return nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryLoginAttemptStore_SlidingWindow(t *testing.T) {
+	store := NewInMemoryLoginAttemptStore()
+	start := time.Now()
+
+	count, err := store.AddFailure("email:test@example.com", start, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, _ = store.AddFailure("email:test@example.com", start.Add(30*time.Second), time.Minute)
+	assert.Equal(t, 2, count)
+
+	count, _ = store.AddFailure("email:test@example.com", start.Add(75*time.Second), time.Minute)
+	assert.Equal(t, 2, count)
+
+	count, _ = store.AddFailure("ip:127.0.0.1", start, time.Minute)
+	assert.Equal(t, 1, count)
+}
+
+func TestInMemoryLoginAttemptStore_LockAndReset(t *testing.T) {
+	store := NewInMemoryLoginAttemptStore()
+	until := time.Now().Add(time.Minute)
+
+	assert.NoError(t, store.Lock("email:test@example.com", until))
+
+	lockedUntil, err := store.LockedUntil("email:test@example.com")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.Equal(until))
+
+	assert.NoError(t, store.Reset("email:test@example.com"))
+
+	lockedUntil, _ = store.LockedUntil("email:test@example.com")
+	assert.True(t, lockedUntil.IsZero())
+}
+
+func TestInMemoryLoginAttemptStore_ExpiredLockClearsFailures(t *testing.T) {
+	store := NewInMemoryLoginAttemptStore()
+	now := time.Now()
+
+	store.AddFailure("email:test@example.com", now, time.Hour)
+	store.Lock("email:test@example.com", now.Add(-time.Second))
+
+	lockedUntil, _ := store.LockedUntil("email:test@example.com")
+	assert.True(t, lockedUntil.IsZero())
+
+	count, _ := store.AddFailure("email:test@example.com", now, time.Hour)
+	assert.Equal(t, 1, count)
+}
+
+func TestInMemoryLoginAttemptStore_SweepsStaleKeys(t *testing.T) {
+	store := NewInMemoryLoginAttemptStore().(*inMemoryLoginAttemptStore)
+	start := time.Now()
+
+	store.AddFailure("ip:10.0.0.1", start, time.Minute)
+	store.AddFailure("ip:10.0.0.2", start.Add(2*time.Minute), time.Minute)
+
+	assert.NotContains(t, store.failures, "ip:10.0.0.1")
+	assert.Contains(t, store.failures, "ip:10.0.0.2")
+}
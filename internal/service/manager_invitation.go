@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ManagerInvitationTTL is how long an invitation can be answered.
+const ManagerInvitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrInvitationNotFound       = errors.New("invitation not found")
+	ErrInvitationExpired        = errors.New("invitation has expired")
+	ErrInvitationAnswered       = errors.New("invitation has already been answered")
+	ErrInvitationAlreadyPending = errors.New("a pending invitation already exists for this email")
+	ErrInvitationEmailMismatch  = errors.New("invitation was sent to a different email")
+	ErrInvalidInvitationRole    = errors.New("invitations can only grant the manager role")
+)
+
+type InviteManagerRequest struct {
+	Email string
+	// Role defaults to manager, the only role an invitation can grant.
+	Role domain.UserRole
+}
+
+func (s *managerService) InviteManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req InviteManagerRequest) (*domain.ManagerInvitation, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !isValidEmail(email) {
+		return nil, ErrInvalidEmail
+	}
+	role := req.Role
+	if role == "" {
+		role = domain.UserRoleManager
+	}
+	if role != domain.UserRoleManager {
+		return nil, ErrInvalidInvitationRole
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "manager.invite"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	existing, err := s.invitationRepo.GetPending(ctx, restaurantID, email)
+	switch {
+	case err == nil && now.Before(existing.ExpiresAt):
+		return nil, ErrInvitationAlreadyPending
+	case err == nil:
+		// Free the pending slot held by the stale invitation.
+		existing.Status = domain.InvitationStatusExpired
+		if err := s.invitationRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user != nil {
+		isManager, err := s.managerRepo.IsManager(ctx, user.ID, restaurantID)
+		if err != nil {
+			return nil, err
+		}
+		if isManager {
+			return nil, ErrManagerAlreadyExists
+		}
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := &domain.ManagerInvitation{
+		RestaurantID: restaurantID,
+		Email:        email,
+		Role:         role,
+		Token:        token,
+		InvitedBy:    ownerID,
+		Status:       domain.InvitationStatusPending,
+		ExpiresAt:    now.Add(ManagerInvitationTTL),
+		CreatedAt:    now,
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		if errors.Is(err, repository.ErrDuplicatePendingInvitation) {
+			return nil, ErrInvitationAlreadyPending
+		}
+		return nil, err
+	}
+
+	s.notifyInvitee(restaurant, invitation, user)
+	return invitation, nil
+}
+
+func (s *managerService) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID) (*domain.RestaurantManager, error) {
+	invitation, err := s.pendingInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, invitation.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	manager, err := s.assignManager(ctx, restaurant, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.answerInvitation(ctx, invitation, domain.InvitationStatusAccepted)
+	return manager, nil
+}
+
+func (s *managerService) DeclineInvitation(ctx context.Context, token string) error {
+	invitation, err := s.pendingInvitation(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invitation.Status = domain.InvitationStatusDeclined
+	invitation.RespondedAt = &now
+	return s.invitationRepo.Update(ctx, invitation)
+}
+
+func (s *managerService) ListPendingInvitations(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID) ([]*domain.ManagerInvitation, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "manager.list_invitations"); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.ListPendingByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pending := make([]*domain.ManagerInvitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		if now.Before(invitation.ExpiresAt) {
+			pending = append(pending, invitation)
+		}
+	}
+	return pending, nil
+}
+
+// pendingInvitation looks up an invitation that can still be answered.
+func (s *managerService) pendingInvitation(ctx context.Context, token string) (*domain.ManagerInvitation, error) {
+	invitation, err := s.invitationRepo.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if invitation.Status != domain.InvitationStatusPending {
+		return nil, ErrInvitationAnswered
+	}
+	if !time.Now().Before(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+// answerInvitation closes an accepted invitation. The manager row is already
+// created, so a failure here is only logged.
+func (s *managerService) answerInvitation(ctx context.Context, invitation *domain.ManagerInvitation, status domain.InvitationStatus) {
+	now := time.Now()
+	invitation.Status = status
+	invitation.RespondedAt = &now
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		s.log.Warn("failed to update manager invitation",
+			zap.String("invitation_id", invitation.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// notifyInvitee tells the invitee about the invitation. user is nil when the
+// email has no account yet. The invitation is already saved, so a failure
+// here is only logged.
+func (s *managerService) notifyInvitee(restaurant *domain.Restaurant, invitation *domain.ManagerInvitation, user *domain.User) {
+	subject := fmt.Sprintf("You are invited to manage %s", restaurant.Name)
+	expires := invitation.ExpiresAt.Format(time.RFC1123)
+
+	var message string
+	if user != nil {
+		message = fmt.Sprintf(
+			"You have been invited to manage %s. Accept with POST /api/manager-invitations/%s/accept or decline with POST /api/manager-invitations/%s/decline before %s.",
+			restaurant.Name, invitation.Token, invitation.Token, expires)
+
+		if err := s.notificationSvc.Send(Notification{
+			ID:        uuid.New(),
+			Type:      NotificationPush,
+			Recipient: user.ID.String(),
+			Subject:   subject,
+			Message:   fmt.Sprintf("You have been invited to manage %s.", restaurant.Name),
+			CreatedAt: time.Now(),
+		}); err != nil {
+			s.log.Warn("failed to send manager invitation notification",
+				zap.String("invitation_id", invitation.ID.String()),
+				zap.Error(err))
+		}
+	} else {
+		message = fmt.Sprintf(
+			"You have been invited to manage %s. Register with the invitation token %s to accept, or decline with POST /api/manager-invitations/%s/decline before %s.",
+			restaurant.Name, invitation.Token, invitation.Token, expires)
+	}
+
+	if err := s.notificationSvc.SendEmail(invitation.Email, subject, message); err != nil {
+		s.log.Warn("failed to send manager invitation email",
+			zap.String("invitation_id", invitation.ID.String()),
+			zap.Error(err))
+	}
+}
+
+func generateInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
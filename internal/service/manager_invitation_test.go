@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockManagerInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockManagerInvitationRepository) Create(ctx context.Context, invitation *domain.ManagerInvitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockManagerInvitationRepository) GetByToken(ctx context.Context, token string) (*domain.ManagerInvitation, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ManagerInvitation), args.Error(1)
+}
+
+func (m *MockManagerInvitationRepository) GetPending(ctx context.Context, restaurantID uuid.UUID, email string) (*domain.ManagerInvitation, error) {
+	args := m.Called(ctx, restaurantID, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ManagerInvitation), args.Error(1)
+}
+
+func (m *MockManagerInvitationRepository) ListPendingByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.ManagerInvitation, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Get(0).([]*domain.ManagerInvitation), args.Error(1)
+}
+
+func (m *MockManagerInvitationRepository) Update(ctx context.Context, invitation *domain.ManagerInvitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+// setupInvitationService returns a manager service whose notifications are
+// delivered to the returned channel.
+func setupInvitationService() (*managerService, *MockManagerInvitationRepository, *MockRestaurantManagerRepository, *MockRestaurantRepository, *MockUserRepository, chan Notification) {
+	service, mockManagerRepo, mockRestaurantRepo, mockUserRepo := setupManagerService()
+	invitationRepo := new(MockManagerInvitationRepository)
+	service.invitationRepo = invitationRepo
+
+	sent := make(chan Notification, 10)
+	service.notificationSvc = newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+	return service, invitationRepo, mockManagerRepo, mockRestaurantRepo, mockUserRepo, sent
+}
+
+func receiveNotifications(t *testing.T, sent chan Notification, n int) []Notification {
+	t.Helper()
+	var notifications []Notification
+	for len(notifications) < n {
+		select {
+		case notification := <-sent:
+			notifications = append(notifications, notification)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d notifications, want %d", len(notifications), n)
+		}
+	}
+	return notifications
+}
+
+func TestInviteManager_ExistingUser(t *testing.T) {
+	service, invitationRepo, managerRepo, restaurantRepo, userRepo, sent := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Dastarkhan"}
+	user := &domain.User{ID: uuid.New(), Email: "manager@test.com"}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, "manager@test.com").Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("GetByEmail", "manager@test.com").Return(user, nil)
+	managerRepo.On("IsManager", ctx, user.ID, restaurant.ID).Return(false, nil)
+	invitationRepo.On("Create", ctx, mock.AnythingOfType("*domain.ManagerInvitation")).Return(nil)
+
+	invitation, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: "  Manager@Test.com "})
+
+	require.NoError(t, err)
+	assert.Equal(t, "manager@test.com", invitation.Email)
+	assert.Equal(t, domain.UserRoleManager, invitation.Role)
+	assert.Equal(t, domain.InvitationStatusPending, invitation.Status)
+	assert.NotEmpty(t, invitation.Token)
+	assert.WithinDuration(t, time.Now().Add(ManagerInvitationTTL), invitation.ExpiresAt, time.Minute)
+
+	byType := map[NotificationType]Notification{}
+	for _, n := range receiveNotifications(t, sent, 2) {
+		byType[n.Type] = n
+	}
+	assert.Equal(t, user.ID.String(), byType[NotificationPush].Recipient)
+	assert.Equal(t, "manager@test.com", byType[NotificationEmail].Recipient)
+	assert.Contains(t, byType[NotificationEmail].Message, "/api/manager-invitations/"+invitation.Token+"/accept")
+	invitationRepo.AssertExpectations(t)
+}
+
+func TestInviteManager_UnknownEmailGetsRegistrationToken(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, userRepo, sent := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Dastarkhan"}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, "new@test.com").Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("GetByEmail", "new@test.com").Return(nil, gorm.ErrRecordNotFound)
+	invitationRepo.On("Create", ctx, mock.AnythingOfType("*domain.ManagerInvitation")).Return(nil)
+
+	invitation, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: "new@test.com"})
+
+	require.NoError(t, err)
+	notifications := receiveNotifications(t, sent, 1)
+	assert.Equal(t, NotificationEmail, notifications[0].Type)
+	assert.Contains(t, notifications[0].Message, "Register with the invitation token "+invitation.Token)
+}
+
+func TestInviteManager_DuplicatePending(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, _, _ := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, "manager@test.com").
+		Return(&domain.ManagerInvitation{Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	_, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: "manager@test.com"})
+
+	assert.ErrorIs(t, err, ErrInvitationAlreadyPending)
+	invitationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestInviteManager_ConcurrentDuplicate(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, userRepo, _ := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, "new@test.com").Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("GetByEmail", "new@test.com").Return(nil, gorm.ErrRecordNotFound)
+	invitationRepo.On("Create", ctx, mock.Anything).Return(repository.ErrDuplicatePendingInvitation)
+
+	_, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: "new@test.com"})
+
+	assert.ErrorIs(t, err, ErrInvitationAlreadyPending)
+}
+
+func TestInviteManager_ReplacesExpiredInvitation(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, userRepo, _ := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	stale := &domain.ManagerInvitation{ID: uuid.New(), Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(-time.Hour)}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, "new@test.com").Return(stale, nil)
+	invitationRepo.On("Update", ctx, stale).Return(nil)
+	userRepo.On("GetByEmail", "new@test.com").Return(nil, gorm.ErrRecordNotFound)
+	invitationRepo.On("Create", ctx, mock.AnythingOfType("*domain.ManagerInvitation")).Return(nil)
+
+	_, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: "new@test.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.InvitationStatusExpired, stale.Status)
+	invitationRepo.AssertExpectations(t)
+}
+
+func TestInviteManager_AlreadyManager(t *testing.T) {
+	service, invitationRepo, managerRepo, restaurantRepo, userRepo, _ := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	user := &domain.User{ID: uuid.New(), Email: "manager@test.com"}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("GetPending", ctx, restaurant.ID, user.Email).Return(nil, gorm.ErrRecordNotFound)
+	userRepo.On("GetByEmail", user.Email).Return(user, nil)
+	managerRepo.On("IsManager", ctx, user.ID, restaurant.ID).Return(true, nil)
+
+	_, err := service.InviteManager(ctx, restaurant.ID, ownerID, InviteManagerRequest{Email: user.Email})
+
+	assert.ErrorIs(t, err, ErrManagerAlreadyExists)
+}
+
+func TestInviteManager_Validation(t *testing.T) {
+	service, _, _, _, _, _ := setupInvitationService()
+	ctx := context.Background()
+
+	_, err := service.InviteManager(ctx, uuid.New(), uuid.New(), InviteManagerRequest{Email: "not-an-email"})
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+
+	_, err = service.InviteManager(ctx, uuid.New(), uuid.New(), InviteManagerRequest{Email: "a@test.com", Role: domain.UserRoleOwner})
+	assert.ErrorIs(t, err, ErrInvalidInvitationRole)
+}
+
+func TestInviteManager_Unauthorized(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, _, _ := setupInvitationService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, err := service.InviteManager(ctx, restaurant.ID, uuid.New(), InviteManagerRequest{Email: "a@test.com"})
+
+	assert.Equal(t, ErrUnauthorized, err)
+	invitationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAcceptInvitation_Success(t *testing.T) {
+	service, invitationRepo, managerRepo, restaurantRepo, userRepo, _ := setupInvitationService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	user := &domain.User{ID: uuid.New(), Email: "Manager@Test.com"}
+	invitation := &domain.ManagerInvitation{
+		ID:           uuid.New(),
+		RestaurantID: restaurant.ID,
+		Email:        "manager@test.com",
+		Status:       domain.InvitationStatusPending,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	invitationRepo.On("GetByToken", ctx, "token").Return(invitation, nil)
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	managerRepo.On("IsManager", ctx, user.ID, restaurant.ID).Return(false, nil)
+	managerRepo.On("Create", ctx, mock.AnythingOfType("*domain.RestaurantManager")).Return(nil)
+	invitationRepo.On("Update", ctx, invitation).Return(nil)
+
+	manager, err := service.AcceptInvitation(ctx, "token", user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, manager.UserID)
+	assert.Equal(t, restaurant.ID, manager.RestaurantID)
+	assert.Equal(t, domain.InvitationStatusAccepted, invitation.Status)
+	assert.NotNil(t, invitation.RespondedAt)
+	managerRepo.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_EmailMismatch(t *testing.T) {
+	service, invitationRepo, managerRepo, _, userRepo, _ := setupInvitationService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	invitationRepo.On("GetByToken", ctx, "token").Return(&domain.ManagerInvitation{
+		Email:     "manager@test.com",
+		Status:    domain.InvitationStatusPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	userRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Email: "other@test.com"}, nil)
+
+	_, err := service.AcceptInvitation(ctx, "token", userID)
+
+	assert.ErrorIs(t, err, ErrInvitationEmailMismatch)
+	managerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAcceptInvitation_NotAnswerable(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name       string
+		invitation *domain.ManagerInvitation
+		err        error
+		want       error
+	}{
+		{"not found", nil, gorm.ErrRecordNotFound, ErrInvitationNotFound},
+		{"expired", &domain.ManagerInvitation{Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(-time.Minute)}, nil, ErrInvitationExpired},
+		{"declined", &domain.ManagerInvitation{Status: domain.InvitationStatusDeclined, ExpiresAt: time.Now().Add(time.Hour)}, nil, ErrInvitationAnswered},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, invitationRepo, _, _, _, _ := setupInvitationService()
+			if tc.invitation == nil {
+				invitationRepo.On("GetByToken", ctx, "token").Return(nil, tc.err)
+			} else {
+				invitationRepo.On("GetByToken", ctx, "token").Return(tc.invitation, tc.err)
+			}
+
+			_, err := service.AcceptInvitation(ctx, "token", uuid.New())
+
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestDeclineInvitation_Success(t *testing.T) {
+	service, invitationRepo, _, _, _, _ := setupInvitationService()
+	ctx := context.Background()
+	invitation := &domain.ManagerInvitation{Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(time.Hour)}
+
+	invitationRepo.On("GetByToken", ctx, "token").Return(invitation, nil)
+	invitationRepo.On("Update", ctx, invitation).Return(nil)
+
+	err := service.DeclineInvitation(ctx, "token")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.InvitationStatusDeclined, invitation.Status)
+	assert.NotNil(t, invitation.RespondedAt)
+}
+
+func TestListPendingInvitations_SkipsExpired(t *testing.T) {
+	service, invitationRepo, _, restaurantRepo, _, _ := setupInvitationService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	valid := &domain.ManagerInvitation{ID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &domain.ManagerInvitation{ID: uuid.New(), ExpiresAt: time.Now().Add(-time.Hour)}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	invitationRepo.On("ListPendingByRestaurant", ctx, restaurant.ID).Return([]*domain.ManagerInvitation{valid, expired}, nil)
+
+	invitations, err := service.ListPendingInvitations(ctx, restaurant.ID, ownerID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.ManagerInvitation{valid}, invitations)
+}
@@ -21,30 +21,62 @@ type AddManagerRequest struct {
 	UserID uuid.UUID
 }
 
+// ManagedRestaurant is a restaurant the user can work on, with the role
+// they hold there: domain.UserRoleOwner or domain.UserRoleManager.
+type ManagedRestaurant struct {
+	Restaurant *domain.Restaurant
+	Role       domain.UserRole
+}
+
 type ManagerService interface {
 	AddManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req AddManagerRequest) (*domain.RestaurantManager, error)
 	RemoveManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, userID uuid.UUID) error
 	GetManagers(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantManager, error)
+	// ListManagedRestaurants returns the restaurants userID owns followed by
+	// the ones they manage. A restaurant the user both owns and manages is
+	// listed once, as owner.
+	ListManagedRestaurants(ctx context.Context, userID uuid.UUID) ([]*ManagedRestaurant, error)
+
+	// InviteManager invites an email address to manage the restaurant. The
+	// invitee is notified in-app and by email when they already have an
+	// account, and by email with a registration token otherwise.
+	InviteManager(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req InviteManagerRequest) (*domain.ManagerInvitation, error)
+	// AcceptInvitation makes userID a manager of the invited restaurant. The
+	// user's email has to match the invitation.
+	AcceptInvitation(ctx context.Context, token string, userID uuid.UUID) (*domain.RestaurantManager, error)
+	DeclineInvitation(ctx context.Context, token string) error
+	// ListPendingInvitations returns the unanswered invitations that have
+	// not expired yet.
+	ListPendingInvitations(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID) ([]*domain.ManagerInvitation, error)
 }
 
 type managerService struct {
-	managerRepo    repository.RestaurantManagerRepository
-	restaurantRepo repository.RestaurantRepository
-	userRepo       repository.UserRepository
-	log            logger.Logger
+	managerRepo     repository.RestaurantManagerRepository
+	invitationRepo  repository.ManagerInvitationRepository
+	restaurantRepo  repository.RestaurantRepository
+	userRepo        repository.UserRepository
+	authz           RestaurantAuthorizer
+	notificationSvc *NotificationService
+	log             logger.Logger
 }
 
 func NewManagerService(
 	managerRepo repository.RestaurantManagerRepository,
+	invitationRepo repository.ManagerInvitationRepository,
 	restaurantRepo repository.RestaurantRepository,
 	userRepo repository.UserRepository,
+	authz RestaurantAuthorizer,
+	notificationSvc *NotificationService,
 	log logger.Logger,
 ) ManagerService {
 	return &managerService{
-		managerRepo:    managerRepo,
-		restaurantRepo: restaurantRepo,
-		userRepo:       userRepo,
-		log:            log,
+		managerRepo:     managerRepo,
+		invitationRepo:  invitationRepo,
+		restaurantRepo:  restaurantRepo,
+		userRepo:        userRepo,
+		authz:           authz,
+		notificationSvc: notificationSvc,
+		log:             log,
 	}
 }
 
@@ -58,10 +90,17 @@ func (s *managerService) AddManager(ctx context.Context, restaurantID uuid.UUID,
 		return nil, err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "manager.add"); err != nil {
+		return nil, err
 	}
-	user, err := s.userRepo.GetByID(req.UserID)
+	return s.assignManager(ctx, restaurant, req.UserID)
+}
+
+// assignManager makes userID a manager of restaurant once the caller has
+// been authorized.
+func (s *managerService) assignManager(ctx context.Context, restaurant *domain.Restaurant, userID uuid.UUID) (*domain.RestaurantManager, error) {
+	restaurantID := restaurant.ID
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
@@ -103,8 +142,8 @@ func (s *managerService) RemoveManager(ctx context.Context, restaurantID uuid.UU
 		return err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "manager.remove"); err != nil {
+		return err
 	}
 
 	isManager, err := s.managerRepo.IsManager(ctx, userID, restaurantID)
@@ -131,3 +170,30 @@ func (s *managerService) GetManagers(ctx context.Context, restaurantID uuid.UUID
 
 	return s.managerRepo.GetManagersByRestaurant(ctx, restaurantID)
 }
+
+func (s *managerService) ListManagedRestaurants(ctx context.Context, userID uuid.UUID) ([]*ManagedRestaurant, error) {
+	owned, err := s.restaurantRepo.GetByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.managerRepo.GetRestaurantsByManager(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(owned)+len(assignments))
+	result := make([]*ManagedRestaurant, 0, len(owned)+len(assignments))
+	for _, restaurant := range owned {
+		seen[restaurant.ID] = true
+		result = append(result, &ManagedRestaurant{Restaurant: restaurant, Role: domain.UserRoleOwner})
+	}
+	for _, assignment := range assignments {
+		// Restaurant is nil when the restaurant row is gone.
+		if assignment.Restaurant == nil || seen[assignment.RestaurantID] {
+			continue
+		}
+		seen[assignment.RestaurantID] = true
+		result = append(result, &ManagedRestaurant{Restaurant: assignment.Restaurant, Role: domain.UserRoleManager})
+	}
+	return result, nil
+}
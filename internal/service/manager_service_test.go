@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -49,6 +51,10 @@ func (m *MockRestaurantManagerRepository) IsManager(ctx context.Context, userID,
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockRestaurantManagerRepository) WithTx(tx *gorm.DB) repository.RestaurantManagerRepository {
+	return m
+}
+
 // setupManagerService creates a manager service instance with mock repositories
 func setupManagerService() (*managerService, *MockRestaurantManagerRepository, *MockRestaurantRepository, *MockUserRepository) {
 	mockManagerRepo := new(MockRestaurantManagerRepository)
@@ -56,9 +62,13 @@ func setupManagerService() (*managerService, *MockRestaurantManagerRepository, *
 	mockUserRepo := new(MockUserRepository)
 
 	service := &managerService{
-		managerRepo:    mockManagerRepo,
-		restaurantRepo: mockRestaurantRepo,
-		userRepo:       mockUserRepo,
+		managerRepo:     mockManagerRepo,
+		invitationRepo:  new(MockManagerInvitationRepository),
+		restaurantRepo:  mockRestaurantRepo,
+		userRepo:        mockUserRepo,
+		authz:           NewRestaurantAuthorizer(mockManagerRepo, new(MockAuditRecorder)),
+		notificationSvc: NewNotificationService(1, 10),
+		log:             zap.NewNop(),
 	}
 
 	return service, mockManagerRepo, mockRestaurantRepo, mockUserRepo
@@ -407,6 +417,37 @@ func TestRemoveManager_Success(t *testing.T) {
 	mockManagerRepo.AssertExpectations(t)
 }
 
+// TestRemoveManager_AdminOverride tests that an admin can remove a manager without owning the restaurant
+func TestRemoveManager_AdminOverride(t *testing.T) {
+	service, mockManagerRepo, mockRestaurantRepo, _ := setupManagerService()
+	audit := new(MockAuditRecorder)
+	service.authz = NewRestaurantAuthorizer(mockManagerRepo, audit)
+
+	restaurantID := uuid.New()
+	adminID := uuid.New()
+	userID := uuid.New()
+	ctx := adminContext(adminID)
+
+	restaurant := &domain.Restaurant{
+		ID:      restaurantID,
+		OwnerID: uuid.New(),
+		Name:    "Test Restaurant",
+	}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	mockManagerRepo.On("IsManager", ctx, userID, restaurantID).Return(true, nil)
+	mockManagerRepo.On("Delete", ctx, userID, restaurantID).Return(nil)
+	expectAdminOverride(audit, adminID, restaurantID, "manager.remove")
+
+	err := service.RemoveManager(ctx, restaurantID, adminID, userID)
+
+	assert.NoError(t, err)
+
+	mockRestaurantRepo.AssertExpectations(t)
+	mockManagerRepo.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
 // TestRemoveManager_RestaurantNotFound tests removing manager when restaurant doesn't exist
 func TestRemoveManager_RestaurantNotFound(t *testing.T) {
 	service, _, mockRestaurantRepo, _ := setupManagerService()
@@ -687,13 +728,75 @@ func TestGetManagers_EmptyList(t *testing.T) {
 	mockManagerRepo.AssertExpectations(t)
 }
 
+func TestListManagedRestaurants_MergesOwnedAndManaged(t *testing.T) {
+	service, mockManagerRepo, mockRestaurantRepo, _ := setupManagerService()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	owned := &domain.Restaurant{ID: uuid.New(), OwnerID: userID, Name: "Owned"}
+	managed := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Name: "Managed"}
+
+	mockRestaurantRepo.On("GetByOwnerID", ctx, userID).Return([]*domain.Restaurant{owned}, nil)
+	mockManagerRepo.On("GetRestaurantsByManager", ctx, userID).Return([]*domain.RestaurantManager{
+		{UserID: userID, RestaurantID: managed.ID, Restaurant: managed},
+		// Owners can be assigned as managers of their own restaurant too.
+		{UserID: userID, RestaurantID: owned.ID, Restaurant: owned},
+		{UserID: userID, RestaurantID: uuid.New()},
+	}, nil)
+
+	result, err := service.ListManagedRestaurants(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ManagedRestaurant{
+		{Restaurant: owned, Role: domain.UserRoleOwner},
+		{Restaurant: managed, Role: domain.UserRoleManager},
+	}, result)
+}
+
+func TestListManagedRestaurants_NoRestaurants(t *testing.T) {
+	service, mockManagerRepo, mockRestaurantRepo, _ := setupManagerService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRestaurantRepo.On("GetByOwnerID", ctx, userID).Return([]*domain.Restaurant{}, nil)
+	mockManagerRepo.On("GetRestaurantsByManager", ctx, userID).Return([]*domain.RestaurantManager{}, nil)
+
+	result, err := service.ListManagedRestaurants(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+func TestListManagedRestaurants_ManagerRepoError(t *testing.T) {
+	service, mockManagerRepo, mockRestaurantRepo, _ := setupManagerService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRestaurantRepo.On("GetByOwnerID", ctx, userID).Return([]*domain.Restaurant{}, nil)
+	mockManagerRepo.On("GetRestaurantsByManager", ctx, userID).Return(nil, errors.New("database error"))
+
+	result, err := service.ListManagedRestaurants(ctx, userID)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
 // TestNewManagerService tests the service constructor
 func TestNewManagerService(t *testing.T) {
 	mockManagerRepo := new(MockRestaurantManagerRepository)
 	mockRestaurantRepo := new(MockRestaurantRepository)
 	mockUserRepo := new(MockUserRepository)
 
-	service := NewManagerService(mockManagerRepo, mockRestaurantRepo, mockUserRepo)
+	service := NewManagerService(
+		mockManagerRepo,
+		new(MockManagerInvitationRepository),
+		mockRestaurantRepo,
+		mockUserRepo,
+		NewRestaurantAuthorizer(mockManagerRepo, new(MockAuditRecorder)),
+		NewNotificationService(1, 10),
+		zap.NewNop(),
+	)
 
 	assert.NotNil(t, service)
 	assert.IsType(t, &managerService{}, service)
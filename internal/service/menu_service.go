@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMenuCategoryNotFound = errors.New("menu category not found")
+	ErrMenuItemNotFound     = errors.New("menu item not found")
+	ErrMenuCategoryNotEmpty = errors.New("menu category still has items; move or delete them first")
+	ErrInvalidMenuName      = errors.New("name cannot be empty")
+	ErrInvalidMenuPrice     = errors.New("price cannot be negative")
+	ErrInvalidPhotoURL      = errors.New("photo_url must be an http or https URL")
+	ErrInvalidMenuOrder     = errors.New("order must list every entry exactly once")
+)
+
+type CreateMenuCategoryRequest struct {
+	Name        string
+	Description *string
+}
+
+// UpdateMenuCategoryRequest changes the fields that are set. An empty
+// Description clears it.
+type UpdateMenuCategoryRequest struct {
+	Name        *string
+	Description *string
+}
+
+type CreateMenuItemRequest struct {
+	Name        string
+	Description *string
+	Price       int
+	PhotoURL    *string
+	// IsAvailable defaults to true.
+	IsAvailable *bool
+}
+
+// UpdateMenuItemRequest changes the fields that are set. An empty
+// Description or PhotoURL clears it. Setting CategoryID moves the item to
+// the end of that category.
+type UpdateMenuItemRequest struct {
+	CategoryID  *uuid.UUID
+	Name        *string
+	Description *string
+	Price       *int
+	PhotoURL    *string
+	IsAvailable *bool
+}
+
+// MenuService manages restaurant menus. Anyone may read a menu; the owner
+// and managers of the restaurant may change it.
+type MenuService interface {
+	// GetMenu returns the restaurant's categories with their items, in menu
+	// order.
+	GetMenu(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error)
+	// CreateCategory adds a category at the end of the menu.
+	CreateCategory(ctx context.Context, restaurantID, staffID uuid.UUID, req CreateMenuCategoryRequest) (*domain.MenuCategory, error)
+	UpdateCategory(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, req UpdateMenuCategoryRequest) (*domain.MenuCategory, error)
+	// DeleteCategory fails with ErrMenuCategoryNotEmpty while the category
+	// has items.
+	DeleteCategory(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID) error
+	// ReorderCategories stores the order given by categoryIDs, which must
+	// list every category of the restaurant exactly once.
+	ReorderCategories(ctx context.Context, restaurantID, staffID uuid.UUID, categoryIDs []uuid.UUID) ([]*domain.MenuCategory, error)
+	// CreateItem adds an item at the end of the category.
+	CreateItem(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, req CreateMenuItemRequest) (*domain.MenuItem, error)
+	UpdateItem(ctx context.Context, restaurantID, itemID, staffID uuid.UUID, req UpdateMenuItemRequest) (*domain.MenuItem, error)
+	DeleteItem(ctx context.Context, restaurantID, itemID, staffID uuid.UUID) error
+	// ReorderItems stores the order given by itemIDs, which must list every
+	// item of the category exactly once.
+	ReorderItems(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, itemIDs []uuid.UUID) ([]*domain.MenuItem, error)
+}
+
+type menuService struct {
+	menuRepo       repository.MenuRepository
+	restaurantRepo repository.RestaurantRepository
+	authz          RestaurantAuthorizer
+	db             *gorm.DB
+}
+
+func NewMenuService(menuRepo repository.MenuRepository, restaurantRepo repository.RestaurantRepository, authz RestaurantAuthorizer, db *gorm.DB) MenuService {
+	return &menuService{
+		menuRepo:       menuRepo,
+		restaurantRepo: restaurantRepo,
+		authz:          authz,
+		db:             db,
+	}
+}
+
+func (s *menuService) GetMenu(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error) {
+	if _, err := s.getRestaurant(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+	return s.menuRepo.ListCategories(ctx, restaurantID)
+}
+
+func (s *menuService) CreateCategory(ctx context.Context, restaurantID, staffID uuid.UUID, req CreateMenuCategoryRequest) (*domain.MenuCategory, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.category_create"); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrInvalidMenuName
+	}
+
+	category := &domain.MenuCategory{
+		RestaurantID: restaurantID,
+		Name:         name,
+		Description:  optionalText(req.Description),
+		Items:        []*domain.MenuItem{},
+	}
+	err := s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		position, err := menuRepo.NextCategoryPosition(ctx, restaurantID)
+		if err != nil {
+			return err
+		}
+		category.Position = position
+		return menuRepo.CreateCategory(ctx, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *menuService) UpdateCategory(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, req UpdateMenuCategoryRequest) (*domain.MenuCategory, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.category_update"); err != nil {
+		return nil, err
+	}
+
+	category, err := s.getCategory(ctx, s.menuRepo, restaurantID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, ErrInvalidMenuName
+		}
+		category.Name = name
+	}
+	if req.Description != nil {
+		category.Description = optionalText(req.Description)
+	}
+
+	if err := s.menuRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *menuService) DeleteCategory(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID) error {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.category_delete"); err != nil {
+		return err
+	}
+
+	return s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		if _, err := s.getCategory(ctx, menuRepo, restaurantID, categoryID); err != nil {
+			return err
+		}
+
+		count, err := menuRepo.CountItems(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrMenuCategoryNotEmpty
+		}
+		return menuRepo.DeleteCategory(ctx, categoryID)
+	})
+}
+
+func (s *menuService) ReorderCategories(ctx context.Context, restaurantID, staffID uuid.UUID, categoryIDs []uuid.UUID) ([]*domain.MenuCategory, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.category_reorder"); err != nil {
+		return nil, err
+	}
+
+	var ordered []*domain.MenuCategory
+	err := s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		categories, err := menuRepo.ListCategories(ctx, restaurantID)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[uuid.UUID]*domain.MenuCategory, len(categories))
+		for _, category := range categories {
+			byID[category.ID] = category
+		}
+		ordered, err = inOrder(byID, categoryIDs)
+		if err != nil {
+			return err
+		}
+		for position, category := range ordered {
+			category.Position = position
+		}
+		return menuRepo.SetCategoryPositions(ctx, categoryIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ordered, nil
+}
+
+func (s *menuService) CreateItem(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, req CreateMenuItemRequest) (*domain.MenuItem, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.item_create"); err != nil {
+		return nil, err
+	}
+
+	item := &domain.MenuItem{
+		CategoryID:   categoryID,
+		RestaurantID: restaurantID,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  optionalText(req.Description),
+		Price:        req.Price,
+		PhotoURL:     optionalText(req.PhotoURL),
+		IsAvailable:  true,
+	}
+	if req.IsAvailable != nil {
+		item.IsAvailable = *req.IsAvailable
+	}
+	if err := validateMenuItem(item); err != nil {
+		return nil, err
+	}
+
+	err := s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		if _, err := s.getCategory(ctx, menuRepo, restaurantID, categoryID); err != nil {
+			return err
+		}
+
+		position, err := menuRepo.NextItemPosition(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+		item.Position = position
+		return menuRepo.CreateItem(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *menuService) UpdateItem(ctx context.Context, restaurantID, itemID, staffID uuid.UUID, req UpdateMenuItemRequest) (*domain.MenuItem, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.item_update"); err != nil {
+		return nil, err
+	}
+
+	var item *domain.MenuItem
+	err := s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		var err error
+		item, err = menuRepo.GetItem(ctx, restaurantID, itemID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMenuItemNotFound
+			}
+			return err
+		}
+
+		if req.Name != nil {
+			item.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			item.Description = optionalText(req.Description)
+		}
+		if req.Price != nil {
+			item.Price = *req.Price
+		}
+		if req.PhotoURL != nil {
+			item.PhotoURL = optionalText(req.PhotoURL)
+		}
+		if req.IsAvailable != nil {
+			item.IsAvailable = *req.IsAvailable
+		}
+		if err := validateMenuItem(item); err != nil {
+			return err
+		}
+
+		if req.CategoryID != nil && *req.CategoryID != item.CategoryID {
+			if _, err := s.getCategory(ctx, menuRepo, restaurantID, *req.CategoryID); err != nil {
+				return err
+			}
+			position, err := menuRepo.NextItemPosition(ctx, *req.CategoryID)
+			if err != nil {
+				return err
+			}
+			item.CategoryID = *req.CategoryID
+			item.Position = position
+		}
+
+		return menuRepo.UpdateItem(ctx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *menuService) DeleteItem(ctx context.Context, restaurantID, itemID, staffID uuid.UUID) error {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.item_delete"); err != nil {
+		return err
+	}
+
+	if _, err := s.menuRepo.GetItem(ctx, restaurantID, itemID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMenuItemNotFound
+		}
+		return err
+	}
+
+	return s.menuRepo.DeleteItem(ctx, itemID)
+}
+
+func (s *menuService) ReorderItems(ctx context.Context, restaurantID, categoryID, staffID uuid.UUID, itemIDs []uuid.UUID) ([]*domain.MenuItem, error) {
+	if err := s.authorize(ctx, restaurantID, staffID, "menu.item_reorder"); err != nil {
+		return nil, err
+	}
+
+	var ordered []*domain.MenuItem
+	err := s.withMenuLock(ctx, restaurantID, func(menuRepo repository.MenuRepository) error {
+		if _, err := s.getCategory(ctx, menuRepo, restaurantID, categoryID); err != nil {
+			return err
+		}
+
+		items, err := menuRepo.ListItems(ctx, categoryID)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[uuid.UUID]*domain.MenuItem, len(items))
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+		ordered, err = inOrder(byID, itemIDs)
+		if err != nil {
+			return err
+		}
+		for position, item := range ordered {
+			item.Position = position
+		}
+		return menuRepo.SetItemPositions(ctx, itemIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ordered, nil
+}
+
+func (s *menuService) getRestaurant(ctx context.Context, restaurantID uuid.UUID) (*domain.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	return restaurant, nil
+}
+
+func (s *menuService) authorize(ctx context.Context, restaurantID, staffID uuid.UUID, action string) error {
+	restaurant, err := s.getRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	return s.authz.CanStaffRestaurant(ctx, restaurant, staffID, action)
+}
+
+func (s *menuService) getCategory(ctx context.Context, menuRepo repository.MenuRepository, restaurantID, categoryID uuid.UUID) (*domain.MenuCategory, error) {
+	category, err := menuRepo.GetCategory(ctx, restaurantID, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// withMenuLock runs fn in a transaction holding the restaurant's menu lock.
+func (s *menuService) withMenuLock(ctx context.Context, restaurantID uuid.UUID, fn func(menuRepo repository.MenuRepository) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		menuRepo := s.menuRepo.WithTx(tx)
+		if err := menuRepo.LockMenu(ctx, restaurantID); err != nil {
+			return err
+		}
+		return fn(menuRepo)
+	})
+}
+
+// inOrder returns the entries of byID in the order of ids, which must name
+// each of them exactly once.
+func inOrder[T any](byID map[uuid.UUID]T, ids []uuid.UUID) ([]T, error) {
+	if len(ids) != len(byID) {
+		return nil, ErrInvalidMenuOrder
+	}
+
+	ordered := make([]T, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for i, id := range ids {
+		entry, ok := byID[id]
+		if !ok || seen[id] {
+			return nil, ErrInvalidMenuOrder
+		}
+		seen[id] = true
+		ordered[i] = entry
+	}
+	return ordered, nil
+}
+
+func validateMenuItem(item *domain.MenuItem) error {
+	if item.Name == "" {
+		return ErrInvalidMenuName
+	}
+	if item.Price < 0 {
+		return ErrInvalidMenuPrice
+	}
+	if item.PhotoURL != nil {
+		parsed, err := url.Parse(*item.PhotoURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidPhotoURL
+		}
+	}
+	return nil
+}
+
+// optionalText trims value and returns nil when nothing is left.
+func optionalText(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
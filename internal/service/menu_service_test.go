@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockMenuRepository struct {
+	mock.Mock
+}
+
+func (m *MockMenuRepository) LockMenu(ctx context.Context, restaurantID uuid.UUID) error {
+	args := m.Called(ctx, restaurantID)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) ListCategories(ctx context.Context, restaurantID uuid.UUID) ([]*domain.MenuCategory, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Get(0).([]*domain.MenuCategory), args.Error(1)
+}
+
+func (m *MockMenuRepository) GetCategory(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuCategory, error) {
+	args := m.Called(ctx, restaurantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MenuCategory), args.Error(1)
+}
+
+func (m *MockMenuRepository) CreateCategory(ctx context.Context, category *domain.MenuCategory) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) UpdateCategory(ctx context.Context, category *domain.MenuCategory) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) NextCategoryPosition(ctx context.Context, restaurantID uuid.UUID) (int, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMenuRepository) SetCategoryPositions(ctx context.Context, ids []uuid.UUID) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) ListItems(ctx context.Context, categoryID uuid.UUID) ([]*domain.MenuItem, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).([]*domain.MenuItem), args.Error(1)
+}
+
+func (m *MockMenuRepository) CountItems(ctx context.Context, categoryID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMenuRepository) GetItem(ctx context.Context, restaurantID, id uuid.UUID) (*domain.MenuItem, error) {
+	args := m.Called(ctx, restaurantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MenuItem), args.Error(1)
+}
+
+func (m *MockMenuRepository) CreateItem(ctx context.Context, item *domain.MenuItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) UpdateItem(ctx context.Context, item *domain.MenuItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) NextItemPosition(ctx context.Context, categoryID uuid.UUID) (int, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMenuRepository) SetItemPositions(ctx context.Context, ids []uuid.UUID) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockMenuRepository) WithTx(tx *gorm.DB) repository.MenuRepository {
+	return m
+}
+
+type menuTestDeps struct {
+	menuRepo    *MockMenuRepository
+	managerRepo *MockRestaurantManagerRepository
+	dbMock      sqlmock.Sqlmock
+	restaurant  *domain.Restaurant
+}
+
+// setupMenuService returns a service for a restaurant whose owner is the
+// only staff member unless the test marks others as managers.
+func setupMenuService() (*menuService, menuTestDeps) {
+	restaurantRepo := new(MockRestaurantRepository)
+	deps := menuTestDeps{
+		menuRepo:    new(MockMenuRepository),
+		managerRepo: new(MockRestaurantManagerRepository),
+		restaurant:  &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Name: "Navat"},
+	}
+	restaurantRepo.On("GetByID", mock.Anything, deps.restaurant.ID).Return(deps.restaurant, nil)
+	restaurantRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	deps.managerRepo.On("IsManager", mock.Anything, mock.Anything, deps.restaurant.ID).Return(false, nil).Maybe()
+	deps.menuRepo.On("LockMenu", mock.Anything, deps.restaurant.ID).Return(nil).Maybe()
+
+	sqlDB, dbMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	deps.dbMock = dbMock
+
+	authz := NewRestaurantAuthorizer(deps.managerRepo, new(MockAuditRecorder))
+	return NewMenuService(deps.menuRepo, restaurantRepo, authz, db).(*menuService), deps
+}
+
+func TestGetMenu_RestaurantNotFound(t *testing.T) {
+	svc, deps := setupMenuService()
+
+	_, err := svc.GetMenu(context.Background(), uuid.New())
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+	deps.menuRepo.AssertNotCalled(t, "ListCategories", mock.Anything, mock.Anything)
+}
+
+func TestCreateMenuCategory_ManagerAppendsToMenu(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	managerID := uuid.New()
+	description := "  Hot and cold  "
+
+	deps.managerRepo.ExpectedCalls = nil
+	deps.managerRepo.On("IsManager", ctx, managerID, deps.restaurant.ID).Return(true, nil)
+	deps.menuRepo.On("NextCategoryPosition", ctx, deps.restaurant.ID).Return(3, nil)
+	deps.menuRepo.On("CreateCategory", ctx, mock.AnythingOfType("*domain.MenuCategory")).Return(nil)
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	category, err := svc.CreateCategory(ctx, deps.restaurant.ID, managerID, CreateMenuCategoryRequest{Name: " Starters ", Description: &description})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Starters", category.Name)
+	assert.Equal(t, "Hot and cold", *category.Description)
+	assert.Equal(t, 3, category.Position)
+	assert.NoError(t, deps.dbMock.ExpectationsWereMet())
+}
+
+func TestCreateMenuCategory_NotStaff(t *testing.T) {
+	svc, deps := setupMenuService()
+
+	_, err := svc.CreateCategory(context.Background(), deps.restaurant.ID, uuid.New(), CreateMenuCategoryRequest{Name: "Starters"})
+
+	assert.ErrorIs(t, err, ErrNotRestaurantStaff)
+	deps.menuRepo.AssertNotCalled(t, "CreateCategory", mock.Anything, mock.Anything)
+}
+
+func TestCreateMenuCategory_BlankName(t *testing.T) {
+	svc, deps := setupMenuService()
+
+	_, err := svc.CreateCategory(context.Background(), deps.restaurant.ID, deps.restaurant.OwnerID, CreateMenuCategoryRequest{Name: "   "})
+
+	assert.ErrorIs(t, err, ErrInvalidMenuName)
+	deps.menuRepo.AssertNotCalled(t, "CreateCategory", mock.Anything, mock.Anything)
+}
+
+func TestDeleteMenuCategory_BlockedWhileItHasItems(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	category := &domain.MenuCategory{ID: uuid.New(), RestaurantID: deps.restaurant.ID}
+
+	deps.menuRepo.On("GetCategory", ctx, deps.restaurant.ID, category.ID).Return(category, nil)
+	deps.menuRepo.On("CountItems", ctx, category.ID).Return(int64(2), nil)
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectRollback()
+
+	err := svc.DeleteCategory(ctx, deps.restaurant.ID, category.ID, deps.restaurant.OwnerID)
+
+	assert.ErrorIs(t, err, ErrMenuCategoryNotEmpty)
+	deps.menuRepo.AssertNotCalled(t, "DeleteCategory", mock.Anything, mock.Anything)
+	assert.NoError(t, deps.dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteMenuCategory_Empty(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	category := &domain.MenuCategory{ID: uuid.New(), RestaurantID: deps.restaurant.ID}
+
+	deps.menuRepo.On("GetCategory", ctx, deps.restaurant.ID, category.ID).Return(category, nil)
+	deps.menuRepo.On("CountItems", ctx, category.ID).Return(int64(0), nil)
+	deps.menuRepo.On("DeleteCategory", ctx, category.ID).Return(nil).Once()
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	err := svc.DeleteCategory(ctx, deps.restaurant.ID, category.ID, deps.restaurant.OwnerID)
+
+	require.NoError(t, err)
+	deps.menuRepo.AssertExpectations(t)
+}
+
+func TestDeleteMenuCategory_OtherRestaurant(t *testing.T) {
+	svc, deps := setupMenuService()
+	categoryID := uuid.New()
+
+	deps.menuRepo.On("GetCategory", mock.Anything, deps.restaurant.ID, categoryID).Return(nil, gorm.ErrRecordNotFound)
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectRollback()
+
+	err := svc.DeleteCategory(context.Background(), deps.restaurant.ID, categoryID, deps.restaurant.OwnerID)
+
+	assert.ErrorIs(t, err, ErrMenuCategoryNotFound)
+}
+
+func TestReorderMenuCategories(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	starters := &domain.MenuCategory{ID: uuid.New(), Name: "Starters", Position: 0}
+	mains := &domain.MenuCategory{ID: uuid.New(), Name: "Mains", Position: 1}
+	drinks := &domain.MenuCategory{ID: uuid.New(), Name: "Drinks", Position: 2}
+	order := []uuid.UUID{drinks.ID, starters.ID, mains.ID}
+
+	deps.menuRepo.On("ListCategories", ctx, deps.restaurant.ID).Return([]*domain.MenuCategory{starters, mains, drinks}, nil)
+	deps.menuRepo.On("SetCategoryPositions", ctx, order).Return(nil).Once()
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	ordered, err := svc.ReorderCategories(ctx, deps.restaurant.ID, deps.restaurant.OwnerID, order)
+
+	require.NoError(t, err)
+	require.Len(t, ordered, 3)
+	assert.Equal(t, []string{"Drinks", "Starters", "Mains"}, []string{ordered[0].Name, ordered[1].Name, ordered[2].Name})
+	assert.Equal(t, []int{0, 1, 2}, []int{ordered[0].Position, ordered[1].Position, ordered[2].Position})
+	deps.menuRepo.AssertExpectations(t)
+}
+
+func TestReorderMenuCategories_InvalidOrder(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	for name, order := range map[string][]uuid.UUID{
+		"missing one":  {first},
+		"listed twice": {first, first},
+		"unknown":      {first, uuid.New()},
+		"extra":        {first, second, uuid.New()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, deps := setupMenuService()
+			deps.menuRepo.On("ListCategories", mock.Anything, deps.restaurant.ID).Return([]*domain.MenuCategory{{ID: first}, {ID: second}}, nil)
+			deps.dbMock.ExpectBegin()
+			deps.dbMock.ExpectRollback()
+
+			_, err := svc.ReorderCategories(context.Background(), deps.restaurant.ID, deps.restaurant.OwnerID, order)
+
+			assert.ErrorIs(t, err, ErrInvalidMenuOrder)
+			deps.menuRepo.AssertNotCalled(t, "SetCategoryPositions", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateMenuItem_Defaults(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	categoryID := uuid.New()
+	photo := "https://cdn.example.com/beshbarmak.jpg"
+
+	deps.menuRepo.On("GetCategory", ctx, deps.restaurant.ID, categoryID).Return(&domain.MenuCategory{ID: categoryID}, nil)
+	deps.menuRepo.On("NextItemPosition", ctx, categoryID).Return(5, nil)
+	deps.menuRepo.On("CreateItem", ctx, mock.AnythingOfType("*domain.MenuItem")).Return(nil)
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	item, err := svc.CreateItem(ctx, deps.restaurant.ID, categoryID, deps.restaurant.OwnerID, CreateMenuItemRequest{
+		Name: "Beshbarmak", Price: 4500, PhotoURL: &photo,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, item.IsAvailable)
+	assert.Equal(t, 5, item.Position)
+	assert.Equal(t, deps.restaurant.ID, item.RestaurantID)
+	assert.Equal(t, photo, *item.PhotoURL)
+}
+
+func TestCreateMenuItem_InvalidInput(t *testing.T) {
+	ftp, relative := "ftp://example.com/a.jpg", "/uploads/a.jpg"
+
+	cases := map[string]struct {
+		req  CreateMenuItemRequest
+		want error
+	}{
+		"blank name":     {CreateMenuItemRequest{Name: " ", Price: 100}, ErrInvalidMenuName},
+		"negative price": {CreateMenuItemRequest{Name: "Tea", Price: -1}, ErrInvalidMenuPrice},
+		"ftp photo":      {CreateMenuItemRequest{Name: "Tea", Price: 100, PhotoURL: &ftp}, ErrInvalidPhotoURL},
+		"relative photo": {CreateMenuItemRequest{Name: "Tea", Price: 100, PhotoURL: &relative}, ErrInvalidPhotoURL},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, deps := setupMenuService()
+
+			_, err := svc.CreateItem(context.Background(), deps.restaurant.ID, uuid.New(), deps.restaurant.OwnerID, tc.req)
+
+			assert.ErrorIs(t, err, tc.want)
+			deps.menuRepo.AssertNotCalled(t, "CreateItem", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateMenuItem_MoveToOtherCategory(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	from, to := uuid.New(), uuid.New()
+	photo := "https://cdn.example.com/tea.jpg"
+	item := &domain.MenuItem{ID: uuid.New(), CategoryID: from, RestaurantID: deps.restaurant.ID, Name: "Tea", Price: 500, PhotoURL: &photo, IsAvailable: true, Position: 0}
+	cleared, unavailable := "", false
+
+	deps.menuRepo.On("GetItem", ctx, deps.restaurant.ID, item.ID).Return(item, nil)
+	deps.menuRepo.On("GetCategory", ctx, deps.restaurant.ID, to).Return(&domain.MenuCategory{ID: to}, nil)
+	deps.menuRepo.On("NextItemPosition", ctx, to).Return(4, nil)
+	deps.menuRepo.On("UpdateItem", ctx, item).Return(nil).Once()
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	updated, err := svc.UpdateItem(ctx, deps.restaurant.ID, item.ID, deps.restaurant.OwnerID, UpdateMenuItemRequest{
+		CategoryID: &to, PhotoURL: &cleared, IsAvailable: &unavailable,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, to, updated.CategoryID)
+	assert.Equal(t, 4, updated.Position)
+	assert.Nil(t, updated.PhotoURL)
+	assert.False(t, updated.IsAvailable)
+	deps.menuRepo.AssertExpectations(t)
+}
+
+func TestUpdateMenuItem_NotFound(t *testing.T) {
+	svc, deps := setupMenuService()
+	itemID := uuid.New()
+
+	deps.menuRepo.On("GetItem", mock.Anything, deps.restaurant.ID, itemID).Return(nil, gorm.ErrRecordNotFound)
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectRollback()
+
+	_, err := svc.UpdateItem(context.Background(), deps.restaurant.ID, itemID, deps.restaurant.OwnerID, UpdateMenuItemRequest{})
+
+	assert.ErrorIs(t, err, ErrMenuItemNotFound)
+}
+
+func TestReorderMenuItems(t *testing.T) {
+	svc, deps := setupMenuService()
+	ctx := context.Background()
+	categoryID := uuid.New()
+	tea := &domain.MenuItem{ID: uuid.New(), Name: "Tea"}
+	coffee := &domain.MenuItem{ID: uuid.New(), Name: "Coffee", Position: 1}
+	order := []uuid.UUID{coffee.ID, tea.ID}
+
+	deps.menuRepo.On("GetCategory", ctx, deps.restaurant.ID, categoryID).Return(&domain.MenuCategory{ID: categoryID}, nil)
+	deps.menuRepo.On("ListItems", ctx, categoryID).Return([]*domain.MenuItem{tea, coffee}, nil)
+	deps.menuRepo.On("SetItemPositions", ctx, order).Return(nil).Once()
+	deps.dbMock.ExpectBegin()
+	deps.dbMock.ExpectCommit()
+
+	ordered, err := svc.ReorderItems(ctx, deps.restaurant.ID, categoryID, deps.restaurant.OwnerID, order)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.MenuItem{coffee, tea}, ordered)
+	assert.Equal(t, 0, coffee.Position)
+	assert.Equal(t, 1, tea.Position)
+	deps.menuRepo.AssertExpectations(t)
+}
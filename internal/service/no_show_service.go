@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoShowBeforeStart = errors.New("a booking can only be marked a no-show after it starts")
+	ErrNotNoShowable     = errors.New("only pending or confirmed bookings can be marked a no-show")
+)
+
+// NoShowResult is a booking marked a no-show. Charge is nil when its policy
+// has no no-show fee or it is a guest booking. NoShowCount is how many
+// no-shows the customer has, this one included, and 0 for a guest.
+type NoShowResult struct {
+	Booking     *domain.Booking
+	Charge      *domain.NoShowCharge
+	NoShowCount int64
+}
+
+// NoShowService marks bookings whose customer never came and charges the
+// policy's no-show fee.
+type NoShowService interface {
+	// MarkNoShow marks the pending or confirmed booking a no-show on behalf
+	// of staffID, who must be staff of its restaurant, once it has started.
+	// The fee is taken from the customer's wallet, or recorded as owed when
+	// the balance does not cover it, and the customer is emailed about it.
+	MarkNoShow(ctx context.Context, bookingID uuid.UUID, staffID uuid.UUID) (*NoShowResult, error)
+}
+
+type noShowService struct {
+	bookingRepo     repository.BookingRepository
+	chargeRepo      repository.NoShowChargeRepository
+	walletService   WalletService
+	authz           RestaurantAuthorizer
+	notificationSvc *NotificationService
+	db              *gorm.DB
+	log             logger.Logger
+	now             func() time.Time
+}
+
+func NewNoShowService(
+	bookingRepo repository.BookingRepository,
+	chargeRepo repository.NoShowChargeRepository,
+	walletService WalletService,
+	authz RestaurantAuthorizer,
+	notificationSvc *NotificationService,
+	db *gorm.DB,
+	log logger.Logger,
+) NoShowService {
+	return &noShowService{
+		bookingRepo:     bookingRepo,
+		chargeRepo:      chargeRepo,
+		walletService:   walletService,
+		authz:           authz,
+		notificationSvc: notificationSvc,
+		db:              db,
+		log:             log,
+		now:             time.Now,
+	}
+}
+
+func (s *noShowService) MarkNoShow(ctx context.Context, bookingID uuid.UUID, staffID uuid.UUID) (*NoShowResult, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	if booking.Restaurant == nil {
+		return nil, ErrBookingNotFound
+	}
+
+	if err := s.authz.CanStaffRestaurant(ctx, booking.Restaurant, staffID, "booking.no_show"); err != nil {
+		return nil, err
+	}
+
+	if booking.Status != domain.BookingStatusPending && booking.Status != domain.BookingStatusConfirmed {
+		return nil, ErrNotNoShowable
+	}
+	now := s.now()
+	if !now.After(booking.StartTime) {
+		return nil, ErrNoShowBeforeStart
+	}
+
+	// The status, the wallet charge and the charge record are written
+	// together, so a failure leaves the booking as it was. The status only
+	// changes once, so of two concurrent calls only one charges the fee.
+	result := &NoShowResult{Booking: booking}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		marked, err := s.bookingRepo.WithTx(tx).MarkNoShow(ctx, booking.ID, now)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			return ErrNotNoShowable
+		}
+		// A guest has no account to charge, and not every policy has a fee.
+		if booking.UserID == nil || booking.CancellationPolicy == nil || booking.CancellationPolicy.NoShowFee <= 0 {
+			return nil
+		}
+		result.Charge, err = s.charge(ctx, tx, booking, booking.CancellationPolicy.NoShowFee)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	booking.Status = domain.BookingStatusNoShow
+
+	// Nor does a guest have one to count no-shows against.
+	if booking.UserID == nil {
+		return result, nil
+	}
+
+	result.NoShowCount, err = s.bookingRepo.CountNoShowsByUser(ctx, *booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(booking, result.Charge)
+	return result, nil
+}
+
+// charge takes fee from the customer's wallet and records the charge, both
+// inside tx. When the wallet cannot pay it, the booking is still a no-show,
+// so the fee is recorded as owed instead of failing.
+func (s *noShowService) charge(ctx context.Context, tx *gorm.DB, booking *domain.Booking, fee int) (*domain.NoShowCharge, error) {
+	charge := &domain.NoShowCharge{
+		BookingID:    booking.ID,
+		UserID:       *booking.UserID,
+		RestaurantID: booking.RestaurantID,
+		Amount:       fee,
+		Status:       domain.NoShowChargePaid,
+	}
+
+	err := s.walletService.WithTx(tx).ChargeForBooking(ctx, *booking.UserID, fee, booking.ID)
+	if err != nil {
+		charge.Status = domain.NoShowChargeOwed
+		if !errors.Is(err, ErrInsufficientBalance) && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.log.Warn("failed to charge no-show fee, recording it as owed",
+				zap.String("booking_id", booking.ID.String()),
+				zap.Error(err))
+		}
+	}
+
+	if err := s.chargeRepo.WithTx(tx).Create(ctx, charge); err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+func (s *noShowService) notify(booking *domain.Booking, charge *domain.NoShowCharge) {
+	if booking.User == nil {
+		s.log.Warn("cannot email no-show notice without the user",
+			zap.String("booking_id", booking.ID.String()))
+		return
+	}
+
+	data := NoShowNotificationData{
+		BookingNotificationData: BookingNotificationData{
+			RestaurantName: booking.Restaurant.Name,
+			BookingID:      booking.ID,
+			StartTime:      booking.StartTime,
+			EndTime:        booking.EndTime,
+			GuestCount:     booking.GuestsCount,
+		},
+	}
+	if charge != nil {
+		data.Fee = charge.Amount
+		data.Owed = charge.Status == domain.NoShowChargeOwed
+	}
+
+	rendered, err := RenderNotification(TemplateBookingNoShow, booking.User.Locale, booking.Restaurant.Location(), data)
+	if err != nil {
+		s.log.Warn("failed to render no-show email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.notificationSvc.SendEmail(booking.User.Email, rendered.Subject, rendered.Body); err != nil {
+		s.log.Warn("failed to send no-show email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+}
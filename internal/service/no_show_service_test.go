@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockNoShowChargeRepository struct {
+	mock.Mock
+}
+
+func (m *MockNoShowChargeRepository) Create(ctx context.Context, charge *domain.NoShowCharge) error {
+	args := m.Called(ctx, charge)
+	return args.Error(0)
+}
+
+func (m *MockNoShowChargeRepository) WithTx(tx *gorm.DB) repository.NoShowChargeRepository {
+	return m
+}
+
+type noShowFixture struct {
+	svc         *noShowService
+	bookingRepo *BookingMockBookingRepository
+	chargeRepo  *MockNoShowChargeRepository
+	wallet      *MockWalletService
+	sqlMock     sqlmock.Sqlmock
+	booking     *domain.Booking
+	ownerID     uuid.UUID
+	now         time.Time
+	sent        chan Notification
+}
+
+// setupNoShowService has a confirmed booking that started ten minutes ago
+// under a policy with fee as its no-show fee.
+func setupNoShowService(fee int) *noShowFixture {
+	f := &noShowFixture{
+		bookingRepo: new(BookingMockBookingRepository),
+		chargeRepo:  new(MockNoShowChargeRepository),
+		wallet:      new(MockWalletService),
+		ownerID:     uuid.New(),
+		now:         time.Date(2024, time.June, 1, 14, 10, 0, 0, time.UTC),
+		sent:        make(chan Notification, 10),
+	}
+	notificationSvc := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		f.sent <- n
+		return nil
+	})
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	f.sqlMock = sqlMock
+	managerRepo := new(MockRestaurantManagerRepository)
+	managerRepo.On("IsManager", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	f.svc = NewNoShowService(f.bookingRepo, f.chargeRepo, f.wallet,
+		NewRestaurantAuthorizer(managerRepo, new(MockAuditRecorder)), notificationSvc, db, zap.NewNop()).(*noShowService)
+	f.svc.now = func() time.Time { return f.now }
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: f.ownerID, Name: "Osteria"}
+	f.booking = &domain.Booking{
+		ID:                 uuid.New(),
+		RestaurantID:       restaurant.ID,
+		UserID:             uuidPtr(uuid.New()),
+		Status:             domain.BookingStatusConfirmed,
+		StartTime:          f.now.Add(-10 * time.Minute),
+		EndTime:            f.now.Add(110 * time.Minute),
+		CancellationPolicy: &domain.CancellationPolicy{NoShowFee: fee},
+		Restaurant:         restaurant,
+		User:               &domain.User{Email: "guest@example.com", Locale: domain.LocaleEnglish},
+	}
+	f.bookingRepo.On("GetByID", mock.Anything, f.booking.ID).Return(f.booking, nil)
+	return f
+}
+
+func TestMarkNoShow_ChargesTheWallet(t *testing.T) {
+	f := setupNoShowService(3000)
+	ctx := context.Background()
+	f.sqlMock.ExpectBegin()
+	f.bookingRepo.On("MarkNoShow", ctx, f.booking.ID, f.now).Return(true, nil)
+	f.wallet.On("ChargeForBooking", ctx, *f.booking.UserID, 3000, f.booking.ID).Return(nil)
+	f.chargeRepo.On("Create", ctx, mock.AnythingOfType("*domain.NoShowCharge")).Return(nil)
+	f.sqlMock.ExpectCommit()
+	f.bookingRepo.On("CountNoShowsByUser", ctx, *f.booking.UserID).Return(int64(2), nil)
+
+	result, err := f.svc.MarkNoShow(ctx, f.booking.ID, f.ownerID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.BookingStatusNoShow, result.Booking.Status)
+	require.NotNil(t, result.Charge)
+	assert.Equal(t, 3000, result.Charge.Amount)
+	assert.Equal(t, domain.NoShowChargePaid, result.Charge.Status)
+	assert.Equal(t, *f.booking.UserID, result.Charge.UserID)
+	assert.Equal(t, int64(2), result.NoShowCount)
+
+	notification := receiveNotifications(t, f.sent, 1)[0]
+	assert.Equal(t, "guest@example.com", notification.Recipient)
+	assert.Contains(t, notification.Message, "3000 KZT, which has been charged to your wallet")
+}
+
+func TestMarkNoShow_RecordsDebtWhenBalanceIsShort(t *testing.T) {
+	f := setupNoShowService(3000)
+	ctx := context.Background()
+	f.sqlMock.ExpectBegin()
+	f.bookingRepo.On("MarkNoShow", ctx, f.booking.ID, f.now).Return(true, nil)
+	f.wallet.On("ChargeForBooking", ctx, *f.booking.UserID, 3000, f.booking.ID).Return(ErrInsufficientBalance)
+	f.chargeRepo.On("Create", ctx, mock.AnythingOfType("*domain.NoShowCharge")).Return(nil)
+	f.sqlMock.ExpectCommit()
+	f.bookingRepo.On("CountNoShowsByUser", ctx, *f.booking.UserID).Return(int64(1), nil)
+
+	result, err := f.svc.MarkNoShow(ctx, f.booking.ID, f.ownerID)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Charge)
+	assert.Equal(t, domain.NoShowChargeOwed, result.Charge.Status)
+	assert.Contains(t, receiveNotifications(t, f.sent, 1)[0].Message, "recorded as owed")
+}
+
+func TestMarkNoShow_WithoutFee(t *testing.T) {
+	f := setupNoShowService(0)
+	ctx := context.Background()
+	f.sqlMock.ExpectBegin()
+	f.bookingRepo.On("MarkNoShow", ctx, f.booking.ID, f.now).Return(true, nil)
+	f.sqlMock.ExpectCommit()
+	f.bookingRepo.On("CountNoShowsByUser", ctx, *f.booking.UserID).Return(int64(1), nil)
+
+	result, err := f.svc.MarkNoShow(ctx, f.booking.ID, f.ownerID)
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Charge)
+	assert.NotContains(t, receiveNotifications(t, f.sent, 1)[0].Message, "KZT")
+	f.wallet.AssertNotCalled(t, "ChargeForBooking", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.chargeRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMarkNoShow_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		arrange func(f *noShowFixture)
+		want    error
+	}{
+		"not staff": {func(f *noShowFixture) { f.ownerID = *f.booking.UserID }, ErrNotRestaurantStaff},
+		"before the start": {func(f *noShowFixture) { f.booking.StartTime = f.now.Add(time.Minute) },
+			ErrNoShowBeforeStart},
+		"cancelled": {func(f *noShowFixture) { f.booking.Status = domain.BookingStatusCancelled }, ErrNotNoShowable},
+		"already marked": {func(f *noShowFixture) {
+			f.sqlMock.ExpectBegin()
+			f.bookingRepo.On("MarkNoShow", mock.Anything, f.booking.ID, f.now).Return(false, nil)
+			f.sqlMock.ExpectRollback()
+		}, ErrNotNoShowable},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := setupNoShowService(3000)
+			tc.arrange(f)
+
+			_, err := f.svc.MarkNoShow(context.Background(), f.booking.ID, f.ownerID)
+
+			assert.ErrorIs(t, err, tc.want)
+			f.wallet.AssertNotCalled(t, "ChargeForBooking", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMarkNoShow_GuestBookingIsNotCharged(t *testing.T) {
+	f := setupNoShowService(3000)
+	f.booking.UserID = nil
+	f.booking.User = nil
+	f.booking.IsGuest = true
+	ctx := context.Background()
+	f.sqlMock.ExpectBegin()
+	f.bookingRepo.On("MarkNoShow", ctx, f.booking.ID, f.now).Return(true, nil)
+	f.sqlMock.ExpectCommit()
+
+	result, err := f.svc.MarkNoShow(ctx, f.booking.ID, f.ownerID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.BookingStatusNoShow, result.Booking.Status)
+	assert.Nil(t, result.Charge)
+	f.wallet.AssertNotCalled(t, "ChargeForBooking", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.bookingRepo.AssertNotCalled(t, "CountNoShowsByUser", mock.Anything, mock.Anything)
+}
+
+func TestMarkNoShow_FailedChargeRecordRollsBack(t *testing.T) {
+	f := setupNoShowService(3000)
+	ctx := context.Background()
+	f.sqlMock.ExpectBegin()
+	f.bookingRepo.On("MarkNoShow", ctx, f.booking.ID, f.now).Return(true, nil)
+	f.wallet.On("ChargeForBooking", ctx, *f.booking.UserID, 3000, f.booking.ID).Return(nil)
+	f.chargeRepo.On("Create", ctx, mock.AnythingOfType("*domain.NoShowCharge")).Return(errors.New("connection reset"))
+	f.sqlMock.ExpectRollback()
+
+	result, err := f.svc.MarkNoShow(ctx, f.booking.ID, f.ownerID)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, domain.BookingStatusConfirmed, f.booking.Status)
+	assert.NoError(t, f.sqlMock.ExpectationsWereMet())
+	f.bookingRepo.AssertNotCalled(t, "CountNoShowsByUser", mock.Anything, mock.Anything)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,40 +28,240 @@ type Notification struct {
 	CreatedAt time.Time
 }
 
+const (
+	// latencyWindow is how far back QueueStats looks for P95Latency.
+	latencyWindow = 5 * time.Minute
+	// latencySampleSize is how many processed-item latencies are kept. Under
+	// heavier load the window effectively shrinks to the newest samples.
+	latencySampleSize = 1024
+)
+
+// NotificationQueueStats describes how far behind the queue is.
+type NotificationQueueStats struct {
+	Depth int
+	// OldestAge is how long the oldest notification still queued or being
+	// sent has been waiting. It is zero when nothing is pending.
+	OldestAge time.Duration
+	// P95Latency is the 95th percentile of the time from Send to the end of
+	// delivery, over notifications processed in the last latencyWindow.
+	P95Latency time.Duration
+	// Processed is the number of notifications P95Latency is taken over.
+	Processed int
+}
+
+// queuedNotification is a notification with its place in the queue.
+type queuedNotification struct {
+	Notification
+	seq        uint64
+	enqueuedAt time.Time
+}
+
+type latencySample struct {
+	processedAt time.Time
+	latency     time.Duration
+}
+
+// NotificationPoolConfig bounds the elastic worker pool. The supervisor adds a
+// worker when the queue depth stays at or above HighWaterMark for
+// SustainDuration and retires one when it stays at or below LowWaterMark.
+type NotificationPoolConfig struct {
+	MinWorkers      int
+	MaxWorkers      int
+	HighWaterMark   int
+	LowWaterMark    int
+	SustainDuration time.Duration
+	CheckInterval   time.Duration
+}
+
+func (c NotificationPoolConfig) withDefaults(bufferSize int) NotificationPoolConfig {
+	if c.MinWorkers < 1 {
+		c.MinWorkers = 1
+	}
+	if c.MaxWorkers < c.MinWorkers {
+		c.MaxWorkers = c.MinWorkers
+	}
+	if c.HighWaterMark <= 0 {
+		c.HighWaterMark = bufferSize * 3 / 4
+		if c.HighWaterMark < 1 {
+			c.HighWaterMark = 1
+		}
+	}
+	if c.LowWaterMark < 0 || c.LowWaterMark >= c.HighWaterMark {
+		c.LowWaterMark = bufferSize / 10
+		if c.LowWaterMark >= c.HighWaterMark {
+			c.LowWaterMark = 0
+		}
+	}
+	if c.SustainDuration <= 0 {
+		c.SustainDuration = 10 * time.Second
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = time.Second
+	}
+	return c
+}
+
 type NotificationService struct {
-	notifications chan Notification
-	workers       int
+	notifications chan queuedNotification
+	pool          NotificationPoolConfig
+	send          func(Notification) error
+	now           func() time.Time
 	wg            sync.WaitGroup
 	ctx           context.Context
 	cancel        context.CancelFunc
-	mu            sync.RWMutex
-	sent          int
-	failed        int
+
+	// mu guards the counters, the pending set and the latency ring buffer.
+	mu      sync.RWMutex
+	sent    int
+	failed  int
+	nextSeq uint64
+	// pending holds the enqueue time of every notification that is queued
+	// or being sent, by sequence number.
+	pending       map[uint64]time.Time
+	latencies     [latencySampleSize]latencySample
+	latencyNext   int
+	latencyFilled bool
+
+	// lifecycle guards the worker count and the closed flag so that scaling
+	// and Send never race with Shutdown closing the queue.
+	lifecycle      sync.RWMutex
+	workers        int
+	nextWorkerID   int
+	closed         bool
+	retire         chan struct{}
+	supervisorDone chan struct{}
 }
 
+// NewNotificationService starts a fixed pool of workers.
 func NewNotificationService(workers int, bufferSize int) *NotificationService {
+	return NewElasticNotificationService(NotificationPoolConfig{
+		MinWorkers: workers,
+		MaxWorkers: workers,
+	}, bufferSize)
+}
+
+// NewElasticNotificationService starts pool.MinWorkers workers and, when
+// pool.MaxWorkers is larger, a supervisor that scales between the two.
+func NewElasticNotificationService(pool NotificationPoolConfig, bufferSize int) *NotificationService {
+	return newNotificationService(pool, bufferSize, nil)
+}
+
+func newNotificationService(pool NotificationPoolConfig, bufferSize int, send func(Notification) error) *NotificationService {
+	return newNotificationServiceWithClock(pool, bufferSize, send, time.Now)
+}
+
+func newNotificationServiceWithClock(pool NotificationPoolConfig, bufferSize int, send func(Notification) error, now func() time.Time) *NotificationService {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	ns := &NotificationService{
-		notifications: make(chan Notification, bufferSize),
-		workers:       workers,
+		notifications: make(chan queuedNotification, bufferSize),
+		pool:          pool.withDefaults(bufferSize),
+		send:          send,
+		now:           now,
 		ctx:           ctx,
 		cancel:        cancel,
 		sent:          0,
 		failed:        0,
+		pending:       make(map[uint64]time.Time),
+		retire:        make(chan struct{}),
+	}
+	if ns.send == nil {
+		ns.send = ns.sendNotification
 	}
 
-	for i := 0; i < workers; i++ {
-		ns.wg.Add(1)
-		go ns.worker(i)
+	for i := 0; i < ns.pool.MinWorkers; i++ {
+		ns.spawnWorker()
+	}
+
+	if ns.pool.MaxWorkers > ns.pool.MinWorkers {
+		ns.supervisorDone = make(chan struct{})
+		go ns.supervise()
+		log.Printf("Notification service started with %d workers (max %d)", ns.pool.MinWorkers, ns.pool.MaxWorkers)
+	} else {
+		log.Printf("Notification service started with %d workers", ns.pool.MinWorkers)
 	}
 
-	log.Printf("Notification service started with %d workers", workers)
 	return ns
 }
 
+// spawnWorker starts one more worker unless the service is shutting down.
+func (ns *NotificationService) spawnWorker() bool {
+	ns.lifecycle.Lock()
+	defer ns.lifecycle.Unlock()
+
+	if ns.closed {
+		return false
+	}
+
+	id := ns.nextWorkerID
+	ns.nextWorkerID++
+	ns.workers++
+	ns.wg.Add(1)
+	go ns.worker(id)
+
+	return true
+}
+
+// retireWorker hands a retire signal to the next idle worker. Workers only
+// pick it up between notifications, so the in-flight item always finishes.
+func (ns *NotificationService) retireWorker() bool {
+	select {
+	case ns.retire <- struct{}{}:
+		return true
+	case <-ns.ctx.Done():
+		return false
+	}
+}
+
+func (ns *NotificationService) supervise() {
+	defer close(ns.supervisorDone)
+
+	ticker := time.NewTicker(ns.pool.CheckInterval)
+	defer ticker.Stop()
+
+	var highSince, lowSince time.Time
+
+	for {
+		select {
+		case <-ns.ctx.Done():
+			return
+		case now := <-ticker.C:
+			depth := len(ns.notifications)
+
+			switch {
+			case depth >= ns.pool.HighWaterMark:
+				lowSince = time.Time{}
+				if highSince.IsZero() {
+					highSince = now
+				}
+				if now.Sub(highSince) >= ns.pool.SustainDuration && ns.WorkerCount() < ns.pool.MaxWorkers {
+					if ns.spawnWorker() {
+						log.Printf("Notification queue depth %d, scaled up to %d workers", depth, ns.WorkerCount())
+					}
+					highSince = now
+				}
+			case depth <= ns.pool.LowWaterMark:
+				highSince = time.Time{}
+				if lowSince.IsZero() {
+					lowSince = now
+				}
+				if now.Sub(lowSince) >= ns.pool.SustainDuration && ns.WorkerCount() > ns.pool.MinWorkers {
+					if ns.retireWorker() {
+						log.Printf("Notification queue depth %d, retiring a worker", depth)
+					}
+					lowSince = now
+				}
+			default:
+				highSince = time.Time{}
+				lowSince = time.Time{}
+			}
+		}
+	}
+}
+
 func (ns *NotificationService) worker(id int) {
 	defer ns.wg.Done()
+	defer ns.workerExited()
 
 	log.Printf("Notification worker %d started", id)
 
@@ -69,13 +270,16 @@ func (ns *NotificationService) worker(id int) {
 		case <-ns.ctx.Done():
 			log.Printf("Notification worker %d stopping", id)
 			return
+		case <-ns.retire:
+			log.Printf("Notification worker %d retired", id)
+			return
 		case notification, ok := <-ns.notifications:
 			if !ok {
 				log.Printf("Notification worker %d: channel closed", id)
 				return
 			}
 
-			if err := ns.sendNotification(notification); err != nil {
+			if err := ns.send(notification.Notification); err != nil {
 				log.Printf("Worker %d: Failed to send notification %s: %v", id, notification.ID, err)
 				ns.incrementFailed()
 			} else {
@@ -83,10 +287,17 @@ func (ns *NotificationService) worker(id int) {
 					id, notification.Type, notification.Recipient)
 				ns.incrementSent()
 			}
+			ns.recordProcessed(notification)
 		}
 	}
 }
 
+func (ns *NotificationService) workerExited() {
+	ns.lifecycle.Lock()
+	defer ns.lifecycle.Unlock()
+	ns.workers--
+}
+
 func (ns *NotificationService) sendNotification(n Notification) error {
 
 	time.Sleep(100 * time.Millisecond)
@@ -95,22 +306,29 @@ func (ns *NotificationService) sendNotification(n Notification) error {
 		return fmt.Errorf("simulated network error")
 	}
 
-	log.Printf("Sent %s notification to %s: %s", n.Type, n.Recipient, n.Message)
+	// The message is left out: it can carry verification links and codes.
+	log.Printf("Sent %s notification %s to %s", n.Type, n.ID, n.Recipient)
 	return nil
 }
 
 func (ns *NotificationService) Send(notification Notification) error {
-	select {
-	case <-ns.ctx.Done():
+	ns.lifecycle.RLock()
+	defer ns.lifecycle.RUnlock()
+
+	if ns.closed {
 		return fmt.Errorf("notification service is shutting down")
-	default:
 	}
 
+	// The item is pending before it is queued, so a worker can never finish
+	// it before it is tracked.
+	item := ns.track(notification)
+
 	select {
-	case ns.notifications <- notification:
+	case ns.notifications <- item:
 		log.Printf("Notification %s queued for sending", notification.ID)
 		return nil
 	default:
+		ns.untrack(item.seq)
 		return fmt.Errorf("notification queue is full")
 	}
 }
@@ -162,6 +380,97 @@ func (ns *NotificationService) GetStats() (sent int, failed int) {
 	return ns.sent, ns.failed
 }
 
+// WorkerCount returns the number of running workers.
+func (ns *NotificationService) WorkerCount() int {
+	ns.lifecycle.RLock()
+	defer ns.lifecycle.RUnlock()
+	return ns.workers
+}
+
+// QueueDepth returns the number of notifications waiting for a worker.
+func (ns *NotificationService) QueueDepth() int {
+	return len(ns.notifications)
+}
+
+// QueueStats reports the queue depth, how long the oldest pending
+// notification has waited and the recent p95 latency.
+func (ns *NotificationService) QueueStats() NotificationQueueStats {
+	now := ns.now()
+
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	stats := NotificationQueueStats{Depth: len(ns.notifications)}
+
+	for _, enqueuedAt := range ns.pending {
+		if age := now.Sub(enqueuedAt); age > stats.OldestAge {
+			stats.OldestAge = age
+		}
+	}
+
+	count := ns.latencyNext
+	if ns.latencyFilled {
+		count = latencySampleSize
+	}
+	recent := make([]time.Duration, 0, count)
+	for _, sample := range ns.latencies[:count] {
+		if now.Sub(sample.processedAt) <= latencyWindow {
+			recent = append(recent, sample.latency)
+		}
+	}
+	stats.Processed = len(recent)
+	stats.P95Latency = percentile(recent, 95)
+
+	return stats
+}
+
+// percentile returns the nearest-rank p-th percentile of values, or zero
+// for no values. It sorts values in place.
+func percentile(values []time.Duration, p int) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := (p*len(values) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+func (ns *NotificationService) track(notification Notification) queuedNotification {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	ns.nextSeq++
+	item := queuedNotification{Notification: notification, seq: ns.nextSeq, enqueuedAt: ns.now()}
+	ns.pending[item.seq] = item.enqueuedAt
+	return item
+}
+
+func (ns *NotificationService) untrack(seq uint64) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	delete(ns.pending, seq)
+}
+
+// recordProcessed drops the item from the pending set and adds its latency
+// to the ring buffer, overwriting the oldest sample once it is full.
+func (ns *NotificationService) recordProcessed(item queuedNotification) {
+	now := ns.now()
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	delete(ns.pending, item.seq)
+	ns.latencies[ns.latencyNext] = latencySample{processedAt: now, latency: now.Sub(item.enqueuedAt)}
+	ns.latencyNext++
+	if ns.latencyNext == latencySampleSize {
+		ns.latencyNext = 0
+		ns.latencyFilled = true
+	}
+}
+
 func (ns *NotificationService) incrementSent() {
 	ns.mu.Lock()
 	defer ns.mu.Unlock()
@@ -175,10 +484,22 @@ func (ns *NotificationService) incrementFailed() {
 }
 
 func (ns *NotificationService) Shutdown() {
+	ns.lifecycle.Lock()
+	if ns.closed {
+		ns.lifecycle.Unlock()
+		return
+	}
+	ns.closed = true
+	ns.lifecycle.Unlock()
+
 	log.Println("Shutting down notification service...")
 
 	ns.cancel()
 
+	if ns.supervisorDone != nil {
+		<-ns.supervisorDone
+	}
+
 	close(ns.notifications)
 
 	ns.wg.Wait()
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -288,3 +289,293 @@ func TestContextCancellation(t *testing.T) {
 
 	ns.Shutdown()
 }
+
+func testPoolConfig(minWorkers, maxWorkers int) NotificationPoolConfig {
+	return NotificationPoolConfig{
+		MinWorkers:      minWorkers,
+		MaxWorkers:      maxWorkers,
+		HighWaterMark:   5,
+		LowWaterMark:    0,
+		SustainDuration: 20 * time.Millisecond,
+		CheckInterval:   5 * time.Millisecond,
+	}
+}
+
+func TestNewElasticNotificationService_Defaults(t *testing.T) {
+	ns := NewElasticNotificationService(NotificationPoolConfig{MinWorkers: 2, MaxWorkers: 1}, 100)
+	defer ns.Shutdown()
+
+	assert.Equal(t, 2, ns.WorkerCount())
+	assert.Equal(t, 2, ns.pool.MaxWorkers)
+	assert.Equal(t, 75, ns.pool.HighWaterMark)
+	assert.Equal(t, 0, ns.pool.LowWaterMark)
+	assert.Equal(t, 10*time.Second, ns.pool.SustainDuration)
+}
+
+func TestElasticPool_ScalesUpUnderSustainedLoad(t *testing.T) {
+	release := make(chan struct{})
+	ns := newNotificationService(testPoolConfig(1, 3), 20, func(Notification) error {
+		<-release
+		return nil
+	})
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, ns.SendEmail("test@example.com", "Test", "Message"))
+	}
+
+	assert.Eventually(t, func() bool { return ns.WorkerCount() == 3 }, time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 3, ns.WorkerCount())
+
+	close(release)
+	ns.Shutdown()
+}
+
+func TestElasticPool_RetiresIdleWorkersAfterInFlightItem(t *testing.T) {
+	release := make(chan struct{})
+	ns := newNotificationService(testPoolConfig(1, 3), 20, func(Notification) error {
+		<-release
+		return nil
+	})
+	defer ns.Shutdown()
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, ns.SendEmail("test@example.com", "Test", "Message"))
+	}
+	assert.Eventually(t, func() bool { return ns.WorkerCount() == 3 }, time.Second, 5*time.Millisecond)
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return ns.WorkerCount() == 1 }, time.Second, 5*time.Millisecond)
+
+	sent, failed := ns.GetStats()
+	assert.Equal(t, 20, sent)
+	assert.Equal(t, 0, failed)
+}
+
+func TestElasticPool_ScaleUpDuringShutdown(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pool := testPoolConfig(1, 8)
+		pool.SustainDuration = time.Millisecond
+		pool.CheckInterval = time.Millisecond
+		ns := newNotificationService(pool, 50, func(Notification) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = ns.SendEmail("test@example.com", "Test", "Message")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Duration(i) * time.Millisecond)
+			ns.Shutdown()
+		}()
+		wg.Wait()
+
+		assert.Equal(t, 0, ns.WorkerCount())
+		err := ns.SendEmail("test@example.com", "Test", "Message")
+		assert.Error(t, err)
+
+		ns.Shutdown()
+	}
+}
+
+func TestElasticPool_Soak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping soak test in short mode")
+	}
+
+	const total = 10000
+
+	ns := newNotificationService(NotificationPoolConfig{
+		MinWorkers:      2,
+		MaxWorkers:      16,
+		HighWaterMark:   128,
+		LowWaterMark:    8,
+		SustainDuration: 10 * time.Millisecond,
+		CheckInterval:   2 * time.Millisecond,
+	}, 256, func(Notification) error {
+		time.Sleep(100 * time.Microsecond)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < total/4; i++ {
+				for ns.SendEmail("test@example.com", "Soak", "Message") != nil {
+					time.Sleep(50 * time.Microsecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		sent, failed := ns.GetStats()
+		return sent+failed == total
+	}, 30*time.Second, 10*time.Millisecond)
+
+	assert.LessOrEqual(t, ns.WorkerCount(), 16)
+	assert.GreaterOrEqual(t, ns.WorkerCount(), 2)
+
+	ns.Shutdown()
+
+	sent, failed := ns.GetStats()
+	assert.Equal(t, total, sent)
+	assert.Equal(t, 0, failed)
+	assert.Equal(t, 0, ns.WorkerCount())
+}
+
+// testClock is a controllable clock for queue age and latency tests.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestQueueStats_OldestAgeCountsInFlightAndQueued(t *testing.T) {
+	clock := newTestClock()
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	ns := newNotificationServiceWithClock(testPoolConfig(1, 1), 10, func(Notification) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, clock.Now)
+
+	assert.Equal(t, time.Duration(0), ns.QueueStats().OldestAge)
+
+	assert.NoError(t, ns.SendEmail("first@example.com", "Test", "Message"))
+	<-started
+	clock.Advance(10 * time.Second)
+	assert.NoError(t, ns.SendEmail("second@example.com", "Test", "Message"))
+	clock.Advance(20 * time.Second)
+
+	// The first notification is stuck in the worker, the second in the queue.
+	stats := ns.QueueStats()
+	assert.Equal(t, 30*time.Second, stats.OldestAge)
+	assert.Equal(t, 1, stats.Depth)
+
+	release <- struct{}{}
+	<-started
+	assert.Eventually(t, func() bool { return ns.QueueStats().OldestAge == 20*time.Second }, time.Second, 5*time.Millisecond)
+
+	stats = ns.QueueStats()
+	assert.Equal(t, 1, stats.Processed)
+	assert.Equal(t, 30*time.Second, stats.P95Latency)
+
+	close(release)
+	ns.Shutdown()
+}
+
+func TestQueueStats_FullQueueIsNotPending(t *testing.T) {
+	clock := newTestClock()
+	release := make(chan struct{})
+	ns := newNotificationServiceWithClock(testPoolConfig(1, 1), 1, func(Notification) error {
+		<-release
+		return nil
+	}, clock.Now)
+
+	assert.NoError(t, ns.SendEmail("first@example.com", "Test", "Message"))
+	assert.Eventually(t, func() bool { return ns.QueueDepth() == 0 }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, ns.SendEmail("second@example.com", "Test", "Message"))
+	assert.Error(t, ns.SendEmail("third@example.com", "Test", "Message"))
+
+	ns.mu.RLock()
+	pending := len(ns.pending)
+	ns.mu.RUnlock()
+	assert.Equal(t, 2, pending)
+
+	close(release)
+	ns.Shutdown()
+}
+
+func TestQueueStats_P95OverLastFiveMinutes(t *testing.T) {
+	clock := newTestClock()
+	ns := newNotificationServiceWithClock(testPoolConfig(1, 1), 10, nil, clock.Now)
+	defer ns.Shutdown()
+
+	processed := func(latency time.Duration) {
+		ns.recordProcessed(queuedNotification{enqueuedAt: clock.Now().Add(-latency)})
+	}
+
+	// Old samples that drop out of the window once the clock moves on.
+	for i := 0; i < 50; i++ {
+		processed(time.Hour)
+	}
+	clock.Advance(latencyWindow + time.Second)
+
+	for i := 1; i <= 100; i++ {
+		processed(time.Duration(i) * time.Millisecond)
+	}
+
+	stats := ns.QueueStats()
+	assert.Equal(t, 100, stats.Processed)
+	assert.Equal(t, 95*time.Millisecond, stats.P95Latency)
+
+	clock.Advance(latencyWindow + time.Second)
+	stats = ns.QueueStats()
+	assert.Equal(t, 0, stats.Processed)
+	assert.Equal(t, time.Duration(0), stats.P95Latency)
+}
+
+func TestQueueStats_RingBufferKeepsNewestSamples(t *testing.T) {
+	clock := newTestClock()
+	ns := newNotificationServiceWithClock(testPoolConfig(1, 1), 10, nil, clock.Now)
+	defer ns.Shutdown()
+
+	for i := 0; i < latencySampleSize; i++ {
+		ns.recordProcessed(queuedNotification{enqueuedAt: clock.Now().Add(-time.Second)})
+	}
+	for i := 0; i < latencySampleSize/2; i++ {
+		ns.recordProcessed(queuedNotification{enqueuedAt: clock.Now().Add(-time.Millisecond)})
+	}
+
+	stats := ns.QueueStats()
+	assert.Equal(t, latencySampleSize, stats.Processed)
+	// Half the buffer now holds 1ms samples, so the p95 is still 1s; once
+	// the rest is overwritten too it drops.
+	assert.Equal(t, time.Second, stats.P95Latency)
+
+	for i := 0; i < latencySampleSize/2; i++ {
+		ns.recordProcessed(queuedNotification{enqueuedAt: clock.Now().Add(-time.Millisecond)})
+	}
+	assert.Equal(t, time.Millisecond, ns.QueueStats().P95Latency)
+}
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, time.Duration(0), percentile(nil, 95))
+	assert.Equal(t, 7*time.Second, percentile([]time.Duration{7 * time.Second}, 95))
+	assert.Equal(t, 19*time.Second, percentile([]time.Duration{
+		5 * time.Second, 1 * time.Second, 19 * time.Second, 3 * time.Second,
+		2 * time.Second, 4 * time.Second, 6 * time.Second, 8 * time.Second,
+		7 * time.Second, 9 * time.Second, 10 * time.Second, 11 * time.Second,
+		12 * time.Second, 13 * time.Second, 14 * time.Second, 15 * time.Second,
+		16 * time.Second, 17 * time.Second, 18 * time.Second, 20 * time.Second,
+	}, 95))
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"embed"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationTemplate names a message template. Every locale has its own
+// copy in templates/notifications/<locale>/<name>.tmpl, defining a
+// "subject" and a "body" template.
+type NotificationTemplate string
+
+const (
+	TemplateBookingConfirmation NotificationTemplate = "booking_confirmation"
+	TemplateBookingReminder     NotificationTemplate = "booking_reminder"
+	TemplateBookingCancellation NotificationTemplate = "booking_cancellation"
+	TemplateBookingDigest       NotificationTemplate = "booking_digest"
+	TemplateBookingRescheduled  NotificationTemplate = "booking_rescheduled"
+	TemplateBookingExpired      NotificationTemplate = "booking_expired"
+	TemplateBookingNoShow       NotificationTemplate = "booking_no_show"
+	TemplateBookingModified     NotificationTemplate = "booking_modified"
+	TemplateWaitlistOffer       NotificationTemplate = "waitlist_offer"
+)
+
+// NotificationTemplates lists every template, which each locale must define.
+var NotificationTemplates = []NotificationTemplate{
+	TemplateBookingConfirmation,
+	TemplateBookingReminder,
+	TemplateBookingCancellation,
+	TemplateBookingDigest,
+	TemplateBookingRescheduled,
+	TemplateBookingExpired,
+	TemplateBookingNoShow,
+	TemplateBookingModified,
+	TemplateWaitlistOffer,
+}
+
+// BookingNotificationData fills the confirmation and expiry templates.
+type BookingNotificationData struct {
+	RestaurantName string
+	BookingID      uuid.UUID
+	StartTime      time.Time
+	EndTime        time.Time
+	GuestCount     int
+}
+
+// ReminderNotificationData fills the reminder template. RescheduleToken is
+// empty when the booking cannot be moved through a reschedule link.
+type ReminderNotificationData struct {
+	BookingNotificationData
+	RescheduleToken string
+}
+
+// RescheduledNotificationData fills the notice telling the restaurant that
+// a customer moved their booking from PreviousStartTime.
+type RescheduledNotificationData struct {
+	BookingNotificationData
+	PreviousStartTime time.Time
+}
+
+// ModifiedNotificationData fills the notice of a changed booking. It goes
+// to the customer when ByStaff is set and to the restaurant otherwise. The
+// Previous fields are the booking before the change.
+type ModifiedNotificationData struct {
+	BookingNotificationData
+	ByStaff            bool
+	TableNumber        string
+	PreviousStartTime  time.Time
+	PreviousGuestCount int
+}
+
+// CancellationNotificationData fills the cancellation template. Offer is nil
+// when there was nothing to rebook onto.
+type CancellationNotificationData struct {
+	BookingNotificationData
+	Offer *domain.RebookingOffer
+}
+
+// WaitlistOfferNotificationData fills the offer of a freed table to a
+// customer on the waitlist. ClaimToken books it until ExpiresAt.
+type WaitlistOfferNotificationData struct {
+	RestaurantName string
+	StartTime      time.Time
+	EndTime        time.Time
+	GuestCount     int
+	ClaimToken     string
+	ExpiresAt      time.Time
+}
+
+// NoShowNotificationData fills the notice of a booking marked a no-show.
+// Fee is 0 when the policy has no no-show fee. Owed is set when the fee
+// could not be taken from the wallet.
+type NoShowNotificationData struct {
+	BookingNotificationData
+	Fee  int
+	Owed bool
+}
+
+// DigestNotificationData fills the digest of a restaurant's bookings for a
+// day.
+type DigestNotificationData struct {
+	RestaurantName string
+	Day            time.Time
+	Bookings       []DigestBooking
+}
+
+type DigestBooking struct {
+	StartTime  time.Time
+	EndTime    time.Time
+	GuestCount int
+	GuestName  string
+}
+
+//go:embed templates/notifications
+var notificationTemplateFS embed.FS
+
+// notificationTemplates holds the parsed templates by locale and name.
+// Templates are parsed against placeholder helpers; RenderNotification
+// binds the real ones for the recipient.
+var notificationTemplates = parseNotificationTemplates()
+
+func parseNotificationTemplates() map[domain.Locale]map[NotificationTemplate]*template.Template {
+	placeholders := localeFormatter{locale: domain.DefaultLocale, loc: time.UTC}.funcs()
+	parsed := make(map[domain.Locale]map[NotificationTemplate]*template.Template, len(domain.Locales))
+	for _, locale := range domain.Locales {
+		parsed[locale] = make(map[NotificationTemplate]*template.Template, len(NotificationTemplates))
+		for _, name := range NotificationTemplates {
+			path := fmt.Sprintf("templates/notifications/%s/%s.tmpl", locale, name)
+			parsed[locale][name] = template.Must(template.New(string(name)).
+				Funcs(placeholders).
+				Option("missingkey=error").
+				ParseFS(notificationTemplateFS, path))
+		}
+	}
+	return parsed
+}
+
+// RenderedNotification is a rendered template, ready to send.
+type RenderedNotification struct {
+	Subject string
+	Body    string
+}
+
+// RenderNotification renders the template in locale, showing times on loc's
+// clock. Unknown locales get the English templates.
+func RenderNotification(name NotificationTemplate, locale domain.Locale, loc *time.Location, data interface{}) (*RenderedNotification, error) {
+	templates, ok := notificationTemplates[locale]
+	if !ok {
+		locale = domain.DefaultLocale
+		templates = notificationTemplates[locale]
+	}
+	parsed, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification template %q", name)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	tmpl, err := parsed.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(localeFormatter{locale: locale, loc: loc}.funcs())
+
+	var subject, body strings.Builder
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, fmt.Errorf("render %s body: %w", name, err)
+	}
+	return &RenderedNotification{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+	}, nil
+}
+
+var (
+	englishMonths = [...]string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	englishWeekdays = [...]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+	// Russian dates use the genitive: 1 июня.
+	russianMonths = [...]string{"января", "февраля", "марта", "апреля", "мая", "июня",
+		"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+	russianWeekdays = [...]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+	kazakhMonths = [...]string{"қаңтар", "ақпан", "наурыз", "сәуір", "мамыр", "маусым",
+		"шілде", "тамыз", "қыркүйек", "қазан", "қараша", "желтоқсан"}
+	kazakhWeekdays = [...]string{"жексенбі", "дүйсенбі", "сейсенбі", "сәрсенбі", "бейсенбі", "жұма", "сенбі"}
+)
+
+// localeFormatter implements the template helpers for one recipient:
+//
+//	date t         1 June 2024 / 1 июня 2024 / 2024 жылғы 1 маусым
+//	time t         19:00, always 24-hour
+//	datetime t     date and time, comma separated
+//	weekday t      Saturday / суббота / сенбі
+//	plural n forms the form of a noun that goes with n
+//	inc n          n + 1, for numbering from 1
+type localeFormatter struct {
+	locale domain.Locale
+	loc    *time.Location
+}
+
+func (f localeFormatter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"date":     f.date,
+		"time":     f.clock,
+		"datetime": f.datetime,
+		"weekday":  f.weekday,
+		"plural":   f.plural,
+		"inc":      func(n int) int { return n + 1 },
+	}
+}
+
+func (f localeFormatter) date(t time.Time) string {
+	t = t.In(f.loc)
+	month := t.Month() - 1
+	switch f.locale {
+	case domain.LocaleRussian:
+		return fmt.Sprintf("%d %s %d", t.Day(), russianMonths[month], t.Year())
+	case domain.LocaleKazakh:
+		return fmt.Sprintf("%d жылғы %d %s", t.Year(), t.Day(), kazakhMonths[month])
+	default:
+		return fmt.Sprintf("%d %s %d", t.Day(), englishMonths[month], t.Year())
+	}
+}
+
+func (f localeFormatter) clock(t time.Time) string {
+	return t.In(f.loc).Format("15:04")
+}
+
+func (f localeFormatter) datetime(t time.Time) string {
+	return f.date(t) + ", " + f.clock(t)
+}
+
+func (f localeFormatter) weekday(t time.Time) string {
+	day := t.In(f.loc).Weekday()
+	switch f.locale {
+	case domain.LocaleRussian:
+		return russianWeekdays[day]
+	case domain.LocaleKazakh:
+		return kazakhWeekdays[day]
+	default:
+		return englishWeekdays[day]
+	}
+}
+
+// plural picks the form of a noun for n. English takes singular and plural
+// forms, Russian the forms for 1, 2 and 5 (гость, гостя, гостей); Kazakh
+// nouns do not change after numerals, so it takes one.
+func (f localeFormatter) plural(n int, forms ...string) (string, error) {
+	index := 0
+	switch f.locale {
+	case domain.LocaleRussian:
+		switch mod10, mod100 := n%10, n%100; {
+		case mod10 == 1 && mod100 != 11:
+			index = 0
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			index = 1
+		default:
+			index = 2
+		}
+	case domain.LocaleKazakh:
+		index = 0
+	default:
+		if n != 1 {
+			index = 1
+		}
+	}
+	if index >= len(forms) {
+		return "", fmt.Errorf("plural: %s needs form %d for %d, got %d forms", f.locale, index+1, n, len(forms))
+	}
+	return forms[index], nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// templateZone stands in for the restaurant's zone; a fixed offset keeps the
+// golden files independent of the host's tz database.
+var templateZone = time.FixedZone("UTC+5", 5*60*60)
+
+// Saturday 1 June 2024, 19:00 in templateZone.
+var templateStart = time.Date(2024, time.June, 1, 14, 0, 0, 0, time.UTC)
+
+func templateFixtures() map[NotificationTemplate]interface{} {
+	booking := BookingNotificationData{
+		RestaurantName: "Osteria",
+		BookingID:      uuid.MustParse("7b0e6a52-4c1d-4f7e-9a55-0d3c2f8b9e11"),
+		StartTime:      templateStart,
+		EndTime:        templateStart.Add(2 * time.Hour),
+		GuestCount:     2,
+	}
+	return map[NotificationTemplate]interface{}{
+		TemplateBookingConfirmation: booking,
+		TemplateBookingExpired:      booking,
+		TemplateBookingReminder: ReminderNotificationData{
+			BookingNotificationData: booking,
+			RescheduleToken:         "Zm9yLXRoZS1nb2xkZW4tZmlsZXM",
+		},
+		TemplateBookingCancellation: CancellationNotificationData{
+			BookingNotificationData: booking,
+			Offer: &domain.RebookingOffer{
+				ID:        uuid.MustParse("c3f1d9a0-2b6e-4a8f-8d47-5e9b1a0c7f22"),
+				ExpiresAt: templateStart.Add(-3 * time.Hour),
+				Options: []domain.RebookingOption{
+					{RestaurantName: "Osteria", StartTime: templateStart.Add(24 * time.Hour)},
+					{RestaurantName: "Trattoria", StartTime: templateStart.Add(30 * time.Minute)},
+				},
+			},
+		},
+		TemplateBookingNoShow: NoShowNotificationData{
+			BookingNotificationData: booking,
+			Fee:                     3000,
+			Owed:                    true,
+		},
+		TemplateBookingModified: ModifiedNotificationData{
+			BookingNotificationData: booking,
+			ByStaff:                 true,
+			TableNumber:             "T4",
+			PreviousStartTime:       templateStart.Add(-time.Hour),
+			PreviousGuestCount:      4,
+		},
+		TemplateBookingRescheduled: RescheduledNotificationData{
+			BookingNotificationData: booking,
+			PreviousStartTime:       templateStart.Add(-26 * time.Hour),
+		},
+		TemplateBookingDigest: DigestNotificationData{
+			RestaurantName: "Osteria",
+			Day:            templateStart,
+			Bookings: []DigestBooking{
+				{StartTime: templateStart.Add(-time.Hour), EndTime: templateStart.Add(time.Hour), GuestCount: 1, GuestName: "Aigerim S."},
+				{StartTime: templateStart, EndTime: templateStart.Add(2 * time.Hour), GuestCount: 5, GuestName: "Ivan P."},
+			},
+		},
+		TemplateWaitlistOffer: WaitlistOfferNotificationData{
+			RestaurantName: "Osteria",
+			StartTime:      templateStart,
+			EndTime:        templateStart.Add(2 * time.Hour),
+			GuestCount:     4,
+			ClaimToken:     "q7Xb2mN9rT4kLw8v",
+			ExpiresAt:      templateStart.Add(-3*time.Hour + 15*time.Minute),
+		},
+	}
+}
+
+func renderForGolden(t *testing.T, name NotificationTemplate, locale domain.Locale, data interface{}) string {
+	t.Helper()
+	rendered, err := RenderNotification(name, locale, templateZone, data)
+	require.NoError(t, err)
+	return fmt.Sprintf("Subject: %s\n\n%s\n", rendered.Subject, rendered.Body)
+}
+
+func TestRenderNotification_Golden(t *testing.T) {
+	fixtures := templateFixtures()
+	for _, name := range NotificationTemplates {
+		for _, locale := range domain.Locales {
+			t.Run(fmt.Sprintf("%s/%s", name, locale), func(t *testing.T) {
+				got := renderForGolden(t, name, locale, fixtures[name])
+
+				path := filepath.Join("testdata", "notifications", fmt.Sprintf("%s.%s.golden", name, locale))
+				if *updateGolden {
+					require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+					require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+				}
+				want, err := os.ReadFile(path)
+				require.NoError(t, err, "run go test ./internal/service -run Golden -update to create it")
+				assert.Equal(t, string(want), got)
+			})
+		}
+	}
+}
+
+func TestRenderNotification_UnknownLocaleFallsBackToEnglish(t *testing.T) {
+	fixtures := templateFixtures()
+	for _, name := range NotificationTemplates {
+		english := renderForGolden(t, name, domain.LocaleEnglish, fixtures[name])
+		assert.Equal(t, english, renderForGolden(t, name, domain.Locale("de"), fixtures[name]), name)
+		assert.Equal(t, english, renderForGolden(t, name, "", fixtures[name]), name)
+	}
+}
+
+func TestRenderNotification_CancellationWithoutOffer(t *testing.T) {
+	data := templateFixtures()[TemplateBookingCancellation].(CancellationNotificationData)
+	data.Offer = nil
+
+	rendered, err := RenderNotification(TemplateBookingCancellation, domain.LocaleEnglish, templateZone, data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Osteria had to cancel your booking for Saturday, 1 June 2024, 19:00.", rendered.Body)
+}
+
+func TestRenderNotification_ReminderWithoutRescheduleLink(t *testing.T) {
+	data := templateFixtures()[TemplateBookingReminder].(ReminderNotificationData)
+	data.RescheduleToken = ""
+
+	rendered, err := RenderNotification(TemplateBookingReminder, domain.LocaleEnglish, templateZone, data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "This is a reminder of your booking at Osteria on Saturday, 1 June 2024, 19:00, for 2 guests.\n\nBooking ID: 7b0e6a52-4c1d-4f7e-9a55-0d3c2f8b9e11", rendered.Body)
+}
+
+func TestRenderNotification_EmptyDigest(t *testing.T) {
+	data := DigestNotificationData{RestaurantName: "Osteria", Day: templateStart}
+
+	rendered, err := RenderNotification(TemplateBookingDigest, domain.LocaleRussian, templateZone, data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "В Osteria на 1 июня 2024 (суббота) броней нет.", rendered.Body)
+}
+
+func TestRenderNotification_UnknownTemplate(t *testing.T) {
+	_, err := RenderNotification("welcome", domain.LocaleEnglish, templateZone, nil)
+
+	assert.Error(t, err)
+}
+
+func TestLocaleFormatter_Plural(t *testing.T) {
+	russian := localeFormatter{locale: domain.LocaleRussian, loc: time.UTC}
+	cases := map[int]string{1: "гость", 2: "гостя", 4: "гостя", 5: "гостей", 11: "гостей", 12: "гостей", 21: "гость", 22: "гостя", 111: "гостей"}
+	for n, want := range cases {
+		got, err := russian.plural(n, "гость", "гостя", "гостей")
+		require.NoError(t, err)
+		assert.Equal(t, want, got, n)
+	}
+
+	_, err := russian.plural(5, "гость")
+	assert.Error(t, err)
+}
+
+func TestLocaleFormatter_ConvertsToZone(t *testing.T) {
+	f := localeFormatter{locale: domain.LocaleKazakh, loc: templateZone}
+	// 21:30 UTC on a Sunday is already Monday in UTC+5.
+	at := time.Date(2024, time.December, 29, 21, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "2024 жылғы 30 желтоқсан, 02:30", f.datetime(at))
+	assert.Equal(t, "дүйсенбі", f.weekday(at))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlreadyRestaurantOwner = errors.New("user already owns this restaurant")
+	ErrNewOwnerNotOwnerRole   = errors.New("new owner must have the owner role")
+	ErrNewOwnerInactive       = errors.New("new owner's account is deactivated")
+	ErrOwnershipChanged       = errors.New("restaurant changed owner in the meantime")
+)
+
+type TransferOwnershipRequest struct {
+	NewOwnerID uuid.UUID
+	// KeepPreviousOwner makes the previous owner a manager of the
+	// restaurant.
+	KeepPreviousOwner bool
+}
+
+type OwnershipService interface {
+	// TransferOwnership hands the restaurant to another owner. Only the
+	// current owner, or an admin, may do so.
+	TransferOwnership(ctx context.Context, restaurantID, actorID uuid.UUID, req TransferOwnershipRequest) (*domain.RestaurantOwnershipTransfer, error)
+}
+
+type ownershipService struct {
+	restaurantRepo repository.RestaurantRepository
+	userRepo       repository.UserRepository
+	managerRepo    repository.RestaurantManagerRepository
+	transferRepo   repository.RestaurantOwnershipTransferRepository
+	authz          RestaurantAuthorizer
+	audit          AuditRecorder
+	db             *gorm.DB
+	log            logger.Logger
+	now            func() time.Time
+}
+
+func NewOwnershipService(
+	restaurantRepo repository.RestaurantRepository,
+	userRepo repository.UserRepository,
+	managerRepo repository.RestaurantManagerRepository,
+	transferRepo repository.RestaurantOwnershipTransferRepository,
+	authz RestaurantAuthorizer,
+	audit AuditRecorder,
+	db *gorm.DB,
+	log logger.Logger,
+) OwnershipService {
+	return &ownershipService{
+		restaurantRepo: restaurantRepo,
+		userRepo:       userRepo,
+		managerRepo:    managerRepo,
+		transferRepo:   transferRepo,
+		authz:          authz,
+		audit:          audit,
+		db:             db,
+		log:            log,
+		now:            time.Now,
+	}
+}
+
+func (s *ownershipService) TransferOwnership(ctx context.Context, restaurantID, actorID uuid.UUID, req TransferOwnershipRequest) (*domain.RestaurantOwnershipTransfer, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, actorID, "restaurant.transfer_ownership"); err != nil {
+		return nil, err
+	}
+
+	previousOwnerID := restaurant.OwnerID
+	if req.NewOwnerID == previousOwnerID {
+		return nil, ErrAlreadyRestaurantOwner
+	}
+
+	newOwner, err := s.userRepo.GetByID(req.NewOwnerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if newOwner.Role != domain.UserRoleOwner {
+		return nil, ErrNewOwnerNotOwnerRole
+	}
+	if !newOwner.IsActive {
+		return nil, ErrNewOwnerInactive
+	}
+
+	now := s.now()
+	transfer := &domain.RestaurantOwnershipTransfer{
+		RestaurantID:      restaurantID,
+		PreviousOwnerID:   previousOwnerID,
+		NewOwnerID:        newOwner.ID,
+		TransferredBy:     actorID,
+		PreviousOwnerKept: req.KeepPreviousOwner,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		changes := map[string]interface{}{"owner_id": newOwner.ID}
+		// A restaurant the previous owner switched off stays switched off
+		// by its owner, so the new owner can turn it back on.
+		if restaurant.DeactivatedBy != nil && *restaurant.DeactivatedBy == previousOwnerID {
+			changes["deactivated_by"] = newOwner.ID
+		}
+		// The owner checked above must still be the owner.
+		result := tx.Model(&domain.Restaurant{}).
+			Where("id = ? AND owner_id = ?", restaurantID, previousOwnerID).
+			Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOwnershipChanged
+		}
+
+		managerRepo := s.managerRepo.WithTx(tx)
+		// Owning the restaurant covers everything managing it did.
+		if err := managerRepo.Delete(ctx, newOwner.ID, restaurantID); err != nil {
+			return err
+		}
+		if req.KeepPreviousOwner {
+			if err := managerRepo.Create(ctx, &domain.RestaurantManager{
+				UserID:       previousOwnerID,
+				RestaurantID: restaurantID,
+				AssignedAt:   now,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return s.transferRepo.WithTx(tx).Create(ctx, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    actorID,
+		Action:     AuditActionOwnershipTransfer,
+		TargetType: "restaurant",
+		TargetID:   restaurantID,
+		Metadata: map[string]interface{}{
+			"previous_owner_id":   previousOwnerID.String(),
+			"new_owner_id":        newOwner.ID.String(),
+			"previous_owner_kept": req.KeepPreviousOwner,
+		},
+	})
+
+	return transfer, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockRestaurantOwnershipTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockRestaurantOwnershipTransferRepository) Create(ctx context.Context, transfer *domain.RestaurantOwnershipTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockRestaurantOwnershipTransferRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.RestaurantOwnershipTransfer, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Get(0).([]*domain.RestaurantOwnershipTransfer), args.Error(1)
+}
+
+func (m *MockRestaurantOwnershipTransferRepository) WithTx(tx *gorm.DB) repository.RestaurantOwnershipTransferRepository {
+	return m
+}
+
+var transferNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+type ownershipMocks struct {
+	restaurants *MockRestaurantRepository
+	users       *MockUserRepository
+	managers    *MockRestaurantManagerRepository
+	transfers   *MockRestaurantOwnershipTransferRepository
+	audit       *MockAuditRecorder
+	db          sqlmock.Sqlmock
+}
+
+func setupOwnershipService() (*ownershipService, *ownershipMocks) {
+	m := &ownershipMocks{
+		restaurants: new(MockRestaurantRepository),
+		users:       new(MockUserRepository),
+		managers:    new(MockRestaurantManagerRepository),
+		transfers:   new(MockRestaurantOwnershipTransferRepository),
+		audit:       new(MockAuditRecorder),
+	}
+	sqlDB, dbMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	m.db = dbMock
+
+	svc := NewOwnershipService(m.restaurants, m.users, m.managers, m.transfers,
+		NewRestaurantAuthorizer(m.managers, m.audit), m.audit, db, zap.NewNop()).(*ownershipService)
+	svc.now = func() time.Time { return transferNow }
+	return svc, m
+}
+
+func TestTransferOwnership_KeepsPreviousOwnerAsManager(t *testing.T) {
+	svc, m := setupOwnershipService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	newOwner := &domain.User{ID: uuid.New(), Role: domain.UserRoleOwner, IsActive: true}
+
+	m.restaurants.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	m.users.On("GetByID", newOwner.ID).Return(newOwner, nil)
+	m.db.ExpectBegin()
+	m.db.ExpectExec(`UPDATE "restaurants" SET "owner_id"=.* WHERE id = .* AND owner_id = .*`).
+		WithArgs(newOwner.ID, sqlmock.AnyArg(), restaurant.ID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.db.ExpectCommit()
+	m.managers.On("Delete", ctx, newOwner.ID, restaurant.ID).Return(nil)
+	m.managers.On("Create", ctx, &domain.RestaurantManager{UserID: ownerID, RestaurantID: restaurant.ID, AssignedAt: transferNow}).Return(nil)
+	m.transfers.On("Create", ctx, mock.AnythingOfType("*domain.RestaurantOwnershipTransfer")).Return(nil)
+	m.audit.On("Record", ctx, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionOwnershipTransfer && entry.TargetID == restaurant.ID
+	})).Return(nil)
+
+	transfer, err := svc.TransferOwnership(ctx, restaurant.ID, ownerID, TransferOwnershipRequest{NewOwnerID: newOwner.ID, KeepPreviousOwner: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, transfer.PreviousOwnerID)
+	assert.Equal(t, newOwner.ID, transfer.NewOwnerID)
+	assert.Equal(t, ownerID, transfer.TransferredBy)
+	assert.True(t, transfer.PreviousOwnerKept)
+	assert.NoError(t, m.db.ExpectationsWereMet())
+	m.managers.AssertExpectations(t)
+	m.transfers.AssertExpectations(t)
+	m.audit.AssertExpectations(t)
+}
+
+func TestTransferOwnership_CarriesOwnerDeactivation(t *testing.T) {
+	svc, m := setupOwnershipService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, DeactivatedBy: &ownerID}
+	newOwner := &domain.User{ID: uuid.New(), Role: domain.UserRoleOwner, IsActive: true}
+
+	m.restaurants.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	m.users.On("GetByID", newOwner.ID).Return(newOwner, nil)
+	m.db.ExpectBegin()
+	m.db.ExpectExec(`UPDATE "restaurants" SET "deactivated_by"=.*,"owner_id"=.*`).
+		WithArgs(newOwner.ID, newOwner.ID, sqlmock.AnyArg(), restaurant.ID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.db.ExpectCommit()
+	m.managers.On("Delete", ctx, newOwner.ID, restaurant.ID).Return(nil)
+	m.transfers.On("Create", ctx, mock.Anything).Return(nil)
+	m.audit.On("Record", ctx, mock.Anything).Return(nil)
+
+	_, err := svc.TransferOwnership(ctx, restaurant.ID, ownerID, TransferOwnershipRequest{NewOwnerID: newOwner.ID})
+
+	require.NoError(t, err)
+	assert.NoError(t, m.db.ExpectationsWereMet())
+	m.managers.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransferOwnership_InvalidTarget(t *testing.T) {
+	ownerID := uuid.New()
+
+	cases := map[string]struct {
+		target *domain.User
+		want   error
+	}{
+		"customer":       {&domain.User{ID: uuid.New(), Role: domain.UserRoleCustomer, IsActive: true}, ErrNewOwnerNotOwnerRole},
+		"deactivated":    {&domain.User{ID: uuid.New(), Role: domain.UserRoleOwner}, ErrNewOwnerInactive},
+		"current owner":  {&domain.User{ID: ownerID, Role: domain.UserRoleOwner, IsActive: true}, ErrAlreadyRestaurantOwner},
+		"missing target": {&domain.User{ID: uuid.New()}, ErrUserNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, m := setupOwnershipService()
+			restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+
+			m.restaurants.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+			if tc.want == ErrUserNotFound {
+				m.users.On("GetByID", tc.target.ID).Return(nil, gorm.ErrRecordNotFound)
+			} else {
+				m.users.On("GetByID", tc.target.ID).Return(tc.target, nil)
+			}
+
+			_, err := svc.TransferOwnership(context.Background(), restaurant.ID, ownerID, TransferOwnershipRequest{NewOwnerID: tc.target.ID})
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.NoError(t, m.db.ExpectationsWereMet())
+			m.transfers.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransferOwnership_RequiresOwner(t *testing.T) {
+	svc, m := setupOwnershipService()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	m.restaurants.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+
+	_, err := svc.TransferOwnership(context.Background(), restaurant.ID, uuid.New(), TransferOwnershipRequest{NewOwnerID: uuid.New()})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	m.users.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestTransferOwnership_RestaurantNotFound(t *testing.T) {
+	svc, m := setupOwnershipService()
+	id := uuid.New()
+
+	m.restaurants.On("GetByID", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.TransferOwnership(context.Background(), id, uuid.New(), TransferOwnershipRequest{NewOwnerID: uuid.New()})
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+}
+
+func TestTransferOwnership_OwnerChangedConcurrently(t *testing.T) {
+	svc, m := setupOwnershipService()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	newOwner := &domain.User{ID: uuid.New(), Role: domain.UserRoleOwner, IsActive: true}
+
+	m.restaurants.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+	m.users.On("GetByID", newOwner.ID).Return(newOwner, nil)
+	m.db.ExpectBegin()
+	m.db.ExpectExec(`UPDATE "restaurants"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	m.db.ExpectRollback()
+
+	_, err := svc.TransferOwnership(context.Background(), restaurant.ID, ownerID, TransferOwnershipRequest{NewOwnerID: newOwner.ID, KeepPreviousOwner: true})
+
+	assert.ErrorIs(t, err, ErrOwnershipChanged)
+	assert.NoError(t, m.db.ExpectationsWereMet())
+	m.managers.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.transfers.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
 	"restaurant-booking/pkg/logger"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -16,34 +18,77 @@ var (
 	ErrPaymentNotFound         = errors.New("payment not found")
 	ErrInvalidPaymentStatus    = errors.New("invalid payment status")
 	ErrPaymentAlreadyProcessed = errors.New("payment already processed")
+	ErrNotPaymentOwner         = errors.New("forbidden: not the payer of this payment")
 )
 
+// ServiceFeeRule is the platform fee for one payment method, as a percent of
+// the amount clamped to [Min, Max]. A zero Max means no upper cap.
+type ServiceFeeRule struct {
+	Percent float64
+	Min     int
+	Max     int
+}
+
+// ServiceFeePolicy holds the fee rule of each payment method. Methods without
+// a rule, such as the wallet, are charged no fee.
+type ServiceFeePolicy map[domain.PaymentMethod]ServiceFeeRule
+
+func (p ServiceFeePolicy) Fee(method domain.PaymentMethod, amount int) int {
+	rule, ok := p[method]
+	if !ok {
+		return 0
+	}
+
+	fee := int(math.Round(float64(amount) * rule.Percent / 100))
+	if fee < rule.Min {
+		fee = rule.Min
+	}
+	if rule.Max > 0 && fee > rule.Max {
+		fee = rule.Max
+	}
+	return fee
+}
+
+// PaymentService takes payments. A completed payment for a booking, such as
+// its deposit, confirms the booking when it is still pending.
 type PaymentService interface {
 	CreatePayment(ctx context.Context, userID uuid.UUID, amount int, method domain.PaymentMethod, bookingID *uuid.UUID) (*domain.Payment, error)
 	ProcessWalletPayment(ctx context.Context, paymentID uuid.UUID) error
 	CreateHalykPayment(ctx context.Context, paymentID uuid.UUID) (string, error)
 	CreateKaspiPayment(ctx context.Context, paymentID uuid.UUID) (string, error)
 	ProcessExternalPaymentCallback(ctx context.Context, externalPaymentID string, success bool) error
-	RefundPayment(ctx context.Context, paymentID uuid.UUID) error
-	GetPaymentsByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Payment, error)
+	// RefundPayment returns a completed payment in full, service fee
+	// included, and cancels the booking it paid for. Only the payer or an
+	// admin may refund it.
+	RefundPayment(ctx context.Context, paymentID, userID uuid.UUID) error
+	ListPayments(ctx context.Context, filter repository.PaymentFilter, limit, offset int) ([]*domain.Payment, *repository.PaymentSummary, error)
 }
 
 type paymentService struct {
 	paymentRepo   repository.PaymentRepository
+	bookingRepo   repository.BookingRepository
 	walletService WalletService
+	fees          ServiceFeePolicy
+	audit         AuditRecorder
 	db            *gorm.DB
 	log           logger.Logger
 }
 
 func NewPaymentService(
 	paymentRepo repository.PaymentRepository,
+	bookingRepo repository.BookingRepository,
 	walletService WalletService,
+	fees ServiceFeePolicy,
+	audit AuditRecorder,
 	db *gorm.DB,
 	log logger.Logger,
 ) PaymentService {
 	return &paymentService{
 		paymentRepo:   paymentRepo,
+		bookingRepo:   bookingRepo,
 		walletService: walletService,
+		fees:          fees,
+		audit:         audit,
 		db:            db,
 		log:           log,
 	}
@@ -56,11 +101,12 @@ func (s *paymentService) CreatePayment(ctx context.Context, userID uuid.UUID, am
 	}
 
 	payment := &domain.Payment{
-		UserID:        userID,
-		BookingID:     bookingID,
-		Amount:        amount,
-		PaymentMethod: method,
-		PaymentStatus: domain.PaymentStatusPending,
+		UserID:           userID,
+		BookingID:        bookingID,
+		Amount:           amount,
+		ServiceFeeAmount: s.fees.Fee(method, amount),
+		PaymentMethod:    method,
+		PaymentStatus:    domain.PaymentStatusPending,
 	}
 
 	if err := s.paymentRepo.Create(ctx, payment); err != nil {
@@ -98,7 +144,7 @@ func (s *paymentService) ProcessWalletPayment(ctx context.Context, paymentID uui
 			bookingID = *payment.BookingID
 		}
 
-		if err := s.walletService.ChargeForBooking(ctx, payment.UserID, payment.Amount, bookingID); err != nil {
+		if err := s.walletService.ChargeForBooking(ctx, payment.UserID, payment.TotalCharged(), bookingID); err != nil {
 			payment.PaymentStatus = domain.PaymentStatusFailed
 			errMsg := err.Error()
 			payment.ErrorMessage = &errMsg
@@ -107,7 +153,10 @@ func (s *paymentService) ProcessWalletPayment(ctx context.Context, paymentID uui
 		}
 
 		payment.PaymentStatus = domain.PaymentStatusCompleted
-		return s.paymentRepo.Update(ctx, payment)
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+		return s.confirmBooking(ctx, payment)
 	})
 }
 
@@ -182,7 +231,13 @@ func (s *paymentService) ProcessExternalPaymentCallback(ctx context.Context, ext
 				return err
 			}
 
+			// A booking's payment pays the booking and is not a top-up.
+			if payment.BookingID != nil {
+				return s.confirmBooking(ctx, payment)
+			}
+
 			if payment.PaymentMethod == domain.PaymentMethodHalyk || payment.PaymentMethod == domain.PaymentMethodKaspi {
+				// The service fee is platform revenue and is not credited.
 				desc := fmt.Sprintf("Top-up via %s (Payment ID: %s)", payment.PaymentMethod, payment.ID)
 				return s.walletService.Deposit(ctx, payment.UserID, payment.Amount, desc)
 			}
@@ -197,13 +252,47 @@ func (s *paymentService) ProcessExternalPaymentCallback(ctx context.Context, ext
 	})
 }
 
-func (s *paymentService) RefundPayment(ctx context.Context, paymentID uuid.UUID) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+// confirmBooking confirms the booking the completed payment was for. A
+// booking that is no longer pending, e.g. one staff confirmed already, is
+// left as it is, and so is one the payment does not settle: the booking and
+// amount come from the request, so a payment by someone else or below the
+// deposit must not confirm it.
+func (s *paymentService) confirmBooking(ctx context.Context, payment *domain.Payment) error {
+	if payment.BookingID == nil {
+		return nil
+	}
+	booking, err := s.bookingRepo.GetByID(ctx, *payment.BookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !booking.PaidBy(payment) {
+		s.log.Warn("payment does not settle its booking",
+			zap.String("payment_id", payment.ID.String()),
+			zap.String("booking_id", booking.ID.String()))
+		return nil
+	}
+	_, err = s.bookingRepo.ConfirmPending(ctx, booking.ID)
+	return err
+}
+
+func (s *paymentService) RefundPayment(ctx context.Context, paymentID, userID uuid.UUID) error {
+	var refunded *domain.Payment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		wallets := s.walletService.WithTx(tx)
+
+		payment, err := paymentRepo.GetByID(ctx, paymentID)
 		if err != nil {
 			return err
 		}
 
+		if payment.UserID != userID && !isAdmin(ctx, userID) {
+			return ErrNotPaymentOwner
+		}
+
 		if payment.PaymentStatus == domain.PaymentStatusRefunded {
 			return nil
 		}
@@ -212,21 +301,102 @@ func (s *paymentService) RefundPayment(ctx context.Context, paymentID uuid.UUID)
 			return errors.New("can only refund completed payments")
 		}
 
-		var bookingID uuid.UUID
-		if payment.BookingID != nil {
-			bookingID = *payment.BookingID
+		reason := fmt.Sprintf("Refund for payment %s", payment.ID)
+		if payment.BookingID == nil && payment.PaymentMethod != domain.PaymentMethodWallet {
+			// A card top-up already credited its amount to the wallet. That
+			// credit is taken back and the card charge, fee included, is
+			// reversed with the provider instead.
+			if err := wallets.Withdraw(ctx, payment.UserID, payment.Amount, reason); err != nil {
+				return err
+			}
+			if err := s.reverseExternalPayment(ctx, payment); err != nil {
+				return err
+			}
+		} else {
+			// A booking payment is refunded to the wallet in full, service
+			// fee included, whichever way it was paid.
+			var bookingID uuid.UUID
+			if payment.BookingID != nil {
+				bookingID = *payment.BookingID
+			}
+			if err := wallets.RefundBooking(ctx, payment.UserID, payment.TotalCharged(), bookingID, reason); err != nil {
+				return err
+			}
+			if err := s.cancelRefundedBooking(ctx, tx, payment); err != nil {
+				return err
+			}
 		}
 
-		reason := fmt.Sprintf("Refund for payment %s", payment.ID)
-		if err := s.walletService.RefundBooking(ctx, payment.UserID, payment.Amount, bookingID, reason); err != nil {
+		payment.PaymentStatus = domain.PaymentStatusRefunded
+		if err := paymentRepo.Update(ctx, payment); err != nil {
 			return err
 		}
+		refunded = payment
+		return nil
+	})
+	if err != nil || refunded == nil {
+		return err
+	}
 
-		payment.PaymentStatus = domain.PaymentStatusRefunded
-		return s.paymentRepo.Update(ctx, payment)
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:       userID,
+		Action:        AuditActionPaymentRefund,
+		TargetType:    "payment",
+		TargetID:      refunded.ID,
+		AdminOverride: refunded.UserID != userID,
+		Metadata:      map[string]interface{}{"amount": refunded.Amount, "service_fee": refunded.ServiceFeeAmount},
 	})
+	return nil
 }
 
-func (s *paymentService) GetPaymentsByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Payment, error) {
-	return s.paymentRepo.GetByUserID(ctx, userID, limit, offset)
+// cancelRefundedBooking cancels the pending or confirmed booking a refunded
+// payment was for, since the booking is no longer paid. Bookings that are
+// already over or cancelled are left as they are.
+func (s *paymentService) cancelRefundedBooking(ctx context.Context, tx *gorm.DB, payment *domain.Payment) error {
+	if payment.BookingID == nil {
+		return nil
+	}
+	bookingRepo := s.bookingRepo.WithTx(tx)
+	booking, err := bookingRepo.GetByID(ctx, *payment.BookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if booking.Status != domain.BookingStatusPending && booking.Status != domain.BookingStatusConfirmed {
+		return nil
+	}
+	booking.Status = domain.BookingStatusCancelled
+	return bookingRepo.Update(ctx, booking)
+}
+
+// reverseExternalPayment asks the card provider to return the whole charge.
+// Like the payment pages, the providers are mocked.
+func (s *paymentService) reverseExternalPayment(ctx context.Context, payment *domain.Payment) error {
+	if payment.ExternalPaymentID == nil {
+		return fmt.Errorf("%w: payment has no %s charge to reverse", ErrInvalidPaymentStatus, payment.PaymentMethod)
+	}
+	s.log.Info("card charge reversed with provider",
+		zap.String("payment_id", payment.ID.String()),
+		zap.String("provider", string(payment.PaymentMethod)),
+		zap.String("external_payment_id", *payment.ExternalPaymentID),
+		zap.Int("amount", payment.TotalCharged()))
+	return nil
+}
+
+// ListPayments returns one page of payments matching filter together with a
+// summary of every matching payment.
+func (s *paymentService) ListPayments(ctx context.Context, filter repository.PaymentFilter, limit, offset int) ([]*domain.Payment, *repository.PaymentSummary, error) {
+	payments, err := s.paymentRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary, err := s.paymentRepo.Summarize(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payments, summary, nil
 }
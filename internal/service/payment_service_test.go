@@ -4,12 +4,15 @@ import (
 	"context"
 	_ "errors"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	tmock "github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -39,19 +42,46 @@ func (m *MockPaymentRepository) GetByExternalID(ctx context.Context, externalID
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.Payment, error) {
-	args := m.Called(ctx, userID, limit, offset)
+func (m *MockPaymentRepository) List(ctx context.Context, filter repository.PaymentFilter, limit, offset int) ([]*domain.Payment, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) Summarize(ctx context.Context, filter repository.PaymentFilter) (*repository.PaymentSummary, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PaymentSummary), args.Error(1)
+}
+
 func (m *MockPaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
 	args := m.Called(ctx, payment)
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, fromBookingID, toBookingID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPaymentRepository) VoidPendingByBooking(ctx context.Context, bookingID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, bookingID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPaymentRepository) RestaurantRevenue(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (int64, error) {
+	args := m.Called(ctx, restaurantID, from, to)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPaymentRepository) WithTx(tx *gorm.DB) repository.PaymentRepository {
+	return m
+}
+
 type MockWalletService struct {
 	tmock.Mock
 }
@@ -97,6 +127,15 @@ func (m *MockWalletService) GetTransactions(ctx context.Context, userID uuid.UUI
 	return args.Get(0).([]*domain.WalletTransaction), args.Error(1)
 }
 
+func (m *MockWalletService) WithTx(tx *gorm.DB) WalletService {
+	return m
+}
+
+// depositBooking is a pending booking held by userID with a deposit.
+func depositBooking(id, userID uuid.UUID, deposit int) *domain.Booking {
+	return &domain.Booking{ID: id, UserID: &userID, Status: domain.BookingStatusPending, CancellationPolicy: &domain.CancellationPolicy{DepositAmount: deposit}}
+}
+
 func setupPaymentService() (*paymentService, *MockPaymentRepository, *MockWalletService, sqlmock.Sqlmock, *gorm.DB) {
 	mockPaymentRepo := new(MockPaymentRepository)
 	mockWalletService := new(MockWalletService)
@@ -110,8 +149,12 @@ func setupPaymentService() (*paymentService, *MockPaymentRepository, *MockWallet
 
 	service := &paymentService{
 		paymentRepo:   mockPaymentRepo,
+		bookingRepo:   new(BookingMockBookingRepository),
 		walletService: mockWalletService,
+		fees:          ServiceFeePolicy{domain.PaymentMethodHalyk: {Percent: 2}},
+		audit:         NewLogAuditRecorder(zap.NewNop()),
 		db:            db,
+		log:           zap.NewNop(),
 	}
 
 	return service, mockPaymentRepo, mockWalletService, sqlMock, db
@@ -126,16 +169,20 @@ func TestCreatePayment_WalletPayment_Success(t *testing.T) {
 	amount := 10000
 
 	sqlMock.ExpectBegin()
-	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
-	mockPaymentRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(&domain.Payment{
+	mockPaymentRepo.On("Create", ctx, tmock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockPaymentRepo.On("GetByID", ctx, tmock.AnythingOfType("uuid.UUID")).Return(&domain.Payment{
 		ID:            uuid.New(),
 		UserID:        userID,
+		BookingID:     &bookingID,
 		Amount:        amount,
 		PaymentMethod: domain.PaymentMethodWallet,
-		PaymentStatus: domain.PaymentStatusCompleted,
+		PaymentStatus: domain.PaymentStatusPending,
 	}, nil)
 	mockWalletService.On("ChargeForBooking", ctx, userID, amount, bookingID).Return(nil)
-	mockPaymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockPaymentRepo.On("Update", ctx, tmock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
+	mockBookingRepo.On("GetByID", ctx, bookingID).Return(depositBooking(bookingID, userID, 10000), nil)
+	mockBookingRepo.On("ConfirmPending", ctx, bookingID).Return(true, nil)
 	sqlMock.ExpectCommit()
 
 	payment, err := service.CreatePayment(ctx, userID, amount, domain.PaymentMethodWallet, &bookingID)
@@ -144,6 +191,31 @@ func TestCreatePayment_WalletPayment_Success(t *testing.T) {
 	assert.NotNil(t, payment)
 	assert.Equal(t, amount, payment.Amount)
 	mockPaymentRepo.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestCreatePayment_CardPaymentStoresServiceFee(t *testing.T) {
+	service, mockPaymentRepo, _, _, _ := setupPaymentService()
+	ctx := context.Background()
+
+	mockPaymentRepo.On("Create", ctx, tmock.AnythingOfType("*domain.Payment")).Return(nil)
+
+	payment, err := service.CreatePayment(ctx, uuid.New(), 10000, domain.PaymentMethodHalyk, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, payment.Amount)
+	assert.Equal(t, 200, payment.ServiceFeeAmount)
+	assert.Equal(t, 10200, payment.TotalCharged())
+}
+
+func TestServiceFeePolicy_Fee(t *testing.T) {
+	policy := ServiceFeePolicy{domain.PaymentMethodKaspi: {Percent: 2, Min: 100, Max: 500}}
+
+	assert.Equal(t, 0, policy.Fee(domain.PaymentMethodWallet, 10000))
+	assert.Equal(t, 200, policy.Fee(domain.PaymentMethodKaspi, 10000))
+	assert.Equal(t, 100, policy.Fee(domain.PaymentMethodKaspi, 1000), "min cap")
+	assert.Equal(t, 500, policy.Fee(domain.PaymentMethodKaspi, 100000), "max cap")
+	assert.Equal(t, 3, ServiceFeePolicy{domain.PaymentMethodKaspi: {Percent: 2}}.Fee(domain.PaymentMethodKaspi, 149), "rounded")
 }
 
 func TestCreatePayment_InvalidAmount(t *testing.T) {
@@ -179,6 +251,9 @@ func TestProcessWalletPayment_Success(t *testing.T) {
 	mockPaymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
 	mockWalletService.On("ChargeForBooking", ctx, userID, amount, bookingID).Return(nil)
 	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
+	mockBookingRepo.On("GetByID", ctx, bookingID).Return(depositBooking(bookingID, userID, 10000), nil)
+	mockBookingRepo.On("ConfirmPending", ctx, bookingID).Return(true, nil)
 	sqlMock.ExpectCommit()
 
 	err := service.ProcessWalletPayment(ctx, paymentID)
@@ -187,6 +262,50 @@ func TestProcessWalletPayment_Success(t *testing.T) {
 	assert.Equal(t, domain.PaymentStatusCompleted, payment.PaymentStatus)
 	mockPaymentRepo.AssertExpectations(t)
 	mockWalletService.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestProcessWalletPayment_DoesNotConfirmBookingItDoesNotSettle(t *testing.T) {
+	for name, tc := range map[string]struct {
+		holder uuid.UUID
+		amount int
+	}{
+		"someone else's booking": {uuid.New(), 10000},
+		"below the deposit":      {uuid.Nil, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+			ctx := context.Background()
+
+			userID := uuid.New()
+			bookingID := uuid.New()
+			holder := tc.holder
+			if holder == uuid.Nil {
+				holder = userID
+			}
+			payment := &domain.Payment{
+				ID:            uuid.New(),
+				UserID:        userID,
+				BookingID:     &bookingID,
+				Amount:        tc.amount,
+				PaymentMethod: domain.PaymentMethodWallet,
+				PaymentStatus: domain.PaymentStatusPending,
+			}
+
+			sqlMock.ExpectBegin()
+			mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+			mockWalletService.On("ChargeForBooking", ctx, userID, tc.amount, bookingID).Return(nil)
+			mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+			mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
+			mockBookingRepo.On("GetByID", ctx, bookingID).Return(depositBooking(bookingID, holder, 10000), nil)
+			sqlMock.ExpectCommit()
+
+			err := service.ProcessWalletPayment(ctx, payment.ID)
+
+			assert.NoError(t, err)
+			mockBookingRepo.AssertNotCalled(t, "ConfirmPending", tmock.Anything, tmock.Anything)
+		})
+	}
 }
 
 func TestProcessWalletPayment_InsufficientBalance(t *testing.T) {
@@ -289,7 +408,7 @@ func TestProcessExternalPaymentCallback_Success(t *testing.T) {
 	sqlMock.ExpectBegin()
 	mockPaymentRepo.On("GetByExternalID", ctx, externalID).Return(payment, nil)
 	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
-	mockWalletService.On("Deposit", ctx, userID, amount, mock.AnythingOfType("string")).Return(nil)
+	mockWalletService.On("Deposit", ctx, userID, amount, tmock.AnythingOfType("string")).Return(nil)
 	sqlMock.ExpectCommit()
 
 	err := service.ProcessExternalPaymentCallback(ctx, externalID, true)
@@ -300,6 +419,63 @@ func TestProcessExternalPaymentCallback_Success(t *testing.T) {
 	mockWalletService.AssertExpectations(t)
 }
 
+func TestProcessExternalPaymentCallback_DepositConfirmsBooking(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	externalID := "external-456"
+	bookingID := uuid.New()
+	payment := &domain.Payment{
+		ID:                uuid.New(),
+		UserID:            uuid.New(),
+		BookingID:         &bookingID,
+		Amount:            10000,
+		PaymentMethod:     domain.PaymentMethodKaspi,
+		PaymentStatus:     domain.PaymentStatusPending,
+		ExternalPaymentID: &externalID,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByExternalID", ctx, externalID).Return(payment, nil)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
+	mockBookingRepo.On("GetByID", ctx, bookingID).Return(depositBooking(bookingID, payment.UserID, 10000), nil)
+	mockBookingRepo.On("ConfirmPending", ctx, bookingID).Return(true, nil)
+	sqlMock.ExpectCommit()
+
+	err := service.ProcessExternalPaymentCallback(ctx, externalID, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusCompleted, payment.PaymentStatus)
+	mockBookingRepo.AssertExpectations(t)
+	mockWalletService.AssertNotCalled(t, "Deposit", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+}
+
+func TestProcessExternalPaymentCallback_VoidedPayment(t *testing.T) {
+	service, mockPaymentRepo, _, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	externalID := "external-789"
+	bookingID := uuid.New()
+	payment := &domain.Payment{
+		ID:                uuid.New(),
+		BookingID:         &bookingID,
+		PaymentMethod:     domain.PaymentMethodKaspi,
+		PaymentStatus:     domain.PaymentStatusVoided,
+		ExternalPaymentID: &externalID,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByExternalID", ctx, externalID).Return(payment, nil)
+	sqlMock.ExpectRollback()
+
+	err := service.ProcessExternalPaymentCallback(ctx, externalID, true)
+
+	assert.ErrorIs(t, err, ErrInvalidPaymentStatus)
+	mockPaymentRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+	service.bookingRepo.(*BookingMockBookingRepository).AssertNotCalled(t, "ConfirmPending", tmock.Anything, tmock.Anything)
+}
+
 func TestProcessExternalPaymentCallback_Failed(t *testing.T) {
 	service, mockPaymentRepo, _, sqlMock, _ := setupPaymentService()
 	ctx := context.Background()
@@ -327,6 +503,7 @@ func TestProcessExternalPaymentCallback_Failed(t *testing.T) {
 
 func TestRefundPayment_Success(t *testing.T) {
 	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
 	ctx := context.Background()
 
 	paymentID := uuid.New()
@@ -341,19 +518,214 @@ func TestRefundPayment_Success(t *testing.T) {
 		Amount:        amount,
 		PaymentStatus: domain.PaymentStatusCompleted,
 	}
+	booking := &domain.Booking{ID: bookingID, UserID: &userID, Status: domain.BookingStatusConfirmed}
 
 	sqlMock.ExpectBegin()
 	mockPaymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
 	mockWalletService.On("RefundBooking", ctx, userID, amount, bookingID, tmock.AnythingOfType("string")).Return(nil)
+	mockBookingRepo.On("GetByID", ctx, bookingID).Return(booking, nil)
+	mockBookingRepo.On("Update", ctx, booking).Return(nil)
 	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
 	sqlMock.ExpectCommit()
 
-	err := service.RefundPayment(ctx, paymentID)
+	err := service.RefundPayment(ctx, paymentID, userID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, domain.PaymentStatusRefunded, payment.PaymentStatus)
+	assert.Equal(t, domain.BookingStatusCancelled, booking.Status)
 	mockPaymentRepo.AssertExpectations(t)
 	mockWalletService.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestRefundPayment_FinishedBookingIsLeftAlone(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	mockBookingRepo := service.bookingRepo.(*BookingMockBookingRepository)
+	ctx := context.Background()
+
+	bookingID := uuid.New()
+	payment := &domain.Payment{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		BookingID:     &bookingID,
+		Amount:        10000,
+		PaymentMethod: domain.PaymentMethodWallet,
+		PaymentStatus: domain.PaymentStatusCompleted,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	mockWalletService.On("RefundBooking", ctx, payment.UserID, 10000, bookingID, tmock.AnythingOfType("string")).Return(nil)
+	mockBookingRepo.On("GetByID", ctx, bookingID).Return(&domain.Booking{ID: bookingID, Status: domain.BookingStatusCompleted}, nil)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := service.RefundPayment(ctx, payment.ID, payment.UserID)
+
+	assert.NoError(t, err)
+	mockBookingRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+}
+
+func TestRefundPayment_WalletPaymentReturnsServiceFee(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	bookingID := uuid.New()
+	payment := &domain.Payment{
+		ID:               uuid.New(),
+		UserID:           uuid.New(),
+		BookingID:        &bookingID,
+		Amount:           10000,
+		ServiceFeeAmount: 200,
+		PaymentMethod:    domain.PaymentMethodWallet,
+		PaymentStatus:    domain.PaymentStatusCompleted,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	mockWalletService.On("RefundBooking", ctx, payment.UserID, 10200, bookingID, tmock.AnythingOfType("string")).Return(nil)
+	service.bookingRepo.(*BookingMockBookingRepository).On("GetByID", ctx, bookingID).Return(nil, gorm.ErrRecordNotFound)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := service.RefundPayment(ctx, payment.ID, payment.UserID)
+
+	assert.NoError(t, err)
+	mockWalletService.AssertExpectations(t)
+}
+
+func TestRefundPayment_CardBookingPaymentReturnsServiceFee(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	bookingID := uuid.New()
+	payment := &domain.Payment{
+		ID:               uuid.New(),
+		UserID:           uuid.New(),
+		BookingID:        &bookingID,
+		Amount:           10000,
+		ServiceFeeAmount: 200,
+		PaymentMethod:    domain.PaymentMethodKaspi,
+		PaymentStatus:    domain.PaymentStatusCompleted,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	mockWalletService.On("RefundBooking", ctx, payment.UserID, 10200, bookingID, tmock.AnythingOfType("string")).Return(nil)
+	service.bookingRepo.(*BookingMockBookingRepository).On("GetByID", ctx, bookingID).Return(nil, gorm.ErrRecordNotFound)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := service.RefundPayment(ctx, payment.ID, payment.UserID)
+
+	assert.NoError(t, err)
+	mockWalletService.AssertExpectations(t)
+}
+
+func TestRefundPayment_CardTopUpIsReversed(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	externalID := uuid.NewString()
+	payment := &domain.Payment{
+		ID:                uuid.New(),
+		UserID:            uuid.New(),
+		Amount:            10000,
+		ServiceFeeAmount:  200,
+		PaymentMethod:     domain.PaymentMethodHalyk,
+		PaymentStatus:     domain.PaymentStatusCompleted,
+		ExternalPaymentID: &externalID,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	// Only the amount reached the wallet; nothing is credited to it.
+	mockWalletService.On("Withdraw", ctx, payment.UserID, 10000, tmock.AnythingOfType("string")).Return(nil)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := service.RefundPayment(ctx, payment.ID, payment.UserID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, payment.PaymentStatus)
+	mockWalletService.AssertExpectations(t)
+	mockWalletService.AssertNotCalled(t, "RefundBooking", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+}
+
+func TestRefundPayment_CardTopUpAlreadySpent(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	externalID := uuid.NewString()
+	payment := &domain.Payment{
+		ID:                uuid.New(),
+		UserID:            uuid.New(),
+		Amount:            10000,
+		PaymentMethod:     domain.PaymentMethodKaspi,
+		PaymentStatus:     domain.PaymentStatusCompleted,
+		ExternalPaymentID: &externalID,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	mockWalletService.On("Withdraw", ctx, payment.UserID, 10000, tmock.AnythingOfType("string")).Return(ErrInsufficientBalance)
+	sqlMock.ExpectRollback()
+
+	err := service.RefundPayment(ctx, payment.ID, payment.UserID)
+
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Equal(t, domain.PaymentStatusCompleted, payment.PaymentStatus)
+	mockPaymentRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+}
+
+func TestRefundPayment_NotThePayer(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	ctx := context.Background()
+
+	payment := &domain.Payment{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		Amount:        10000,
+		PaymentMethod: domain.PaymentMethodWallet,
+		PaymentStatus: domain.PaymentStatusCompleted,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	sqlMock.ExpectRollback()
+
+	err := service.RefundPayment(ctx, payment.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrNotPaymentOwner)
+	assert.Equal(t, domain.PaymentStatusCompleted, payment.PaymentStatus)
+	mockWalletService.AssertNotCalled(t, "RefundBooking", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+	mockPaymentRepo.AssertNotCalled(t, "Update", tmock.Anything, tmock.Anything)
+}
+
+func TestRefundPayment_AdminRefundsAnotherUsersPayment(t *testing.T) {
+	service, mockPaymentRepo, mockWalletService, sqlMock, _ := setupPaymentService()
+	adminID := uuid.New()
+	ctx := WithActor(context.Background(), Actor{ID: adminID, Role: domain.UserRoleAdmin})
+
+	payment := &domain.Payment{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		Amount:        10000,
+		PaymentMethod: domain.PaymentMethodWallet,
+		PaymentStatus: domain.PaymentStatusCompleted,
+	}
+
+	sqlMock.ExpectBegin()
+	mockPaymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+	mockWalletService.On("RefundBooking", ctx, payment.UserID, 10000, uuid.Nil, tmock.AnythingOfType("string")).Return(nil)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := service.RefundPayment(ctx, payment.ID, adminID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, payment.PaymentStatus)
+	mockWalletService.AssertExpectations(t)
 }
 
 func TestRefundPayment_AlreadyRefunded(t *testing.T) {
@@ -363,6 +735,7 @@ func TestRefundPayment_AlreadyRefunded(t *testing.T) {
 	paymentID := uuid.New()
 	payment := &domain.Payment{
 		ID:            paymentID,
+		UserID:        uuid.New(),
 		PaymentStatus: domain.PaymentStatusRefunded,
 	}
 
@@ -370,7 +743,7 @@ func TestRefundPayment_AlreadyRefunded(t *testing.T) {
 	mockPaymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
 	sqlMock.ExpectCommit()
 
-	err := service.RefundPayment(ctx, paymentID)
+	err := service.RefundPayment(ctx, paymentID, payment.UserID)
 
 	assert.NoError(t, err)
 	mockPaymentRepo.AssertExpectations(t)
@@ -383,6 +756,7 @@ func TestRefundPayment_InvalidStatus(t *testing.T) {
 	paymentID := uuid.New()
 	payment := &domain.Payment{
 		ID:            paymentID,
+		UserID:        uuid.New(),
 		PaymentStatus: domain.PaymentStatusPending,
 	}
 
@@ -390,28 +764,36 @@ func TestRefundPayment_InvalidStatus(t *testing.T) {
 	mockPaymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
 	sqlMock.ExpectRollback()
 
-	err := service.RefundPayment(ctx, paymentID)
+	err := service.RefundPayment(ctx, paymentID, payment.UserID)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "can only refund completed payments")
 	mockPaymentRepo.AssertExpectations(t)
 }
 
-func TestGetPaymentsByUser(t *testing.T) {
+func TestListPayments(t *testing.T) {
 	service, mockPaymentRepo, _, _, _ := setupPaymentService()
 	ctx := context.Background()
 
 	userID := uuid.New()
+	status := domain.PaymentStatusCompleted
+	filter := repository.PaymentFilter{UserID: &userID, Status: &status}
 	payments := []*domain.Payment{
 		{ID: uuid.New(), UserID: userID},
 		{ID: uuid.New(), UserID: userID},
 	}
+	summary := &repository.PaymentSummary{
+		TotalPaid:     15000,
+		CountByStatus: map[domain.PaymentStatus]int64{domain.PaymentStatusCompleted: 2},
+	}
 
-	mockPaymentRepo.On("GetByUserID", ctx, userID, 10, 0).Return(payments, nil)
+	mockPaymentRepo.On("List", ctx, filter, 10, 0).Return(payments, nil)
+	mockPaymentRepo.On("Summarize", ctx, filter).Return(summary, nil)
 
-	result, err := service.GetPaymentsByUser(ctx, userID, 10, 0)
+	result, resultSummary, err := service.ListPayments(ctx, filter, 10, 0)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
+	assert.Equal(t, summary, resultSummary)
 	mockPaymentRepo.AssertExpectations(t)
 }
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// PendingExpiryInterval is how often pending bookings are checked for
+	// expiry.
+	PendingExpiryInterval = time.Minute
+	// PendingExpiryBatchSize is how many bookings one query of the job
+	// loads.
+	PendingExpiryBatchSize = 100
+)
+
+// PendingExpiryNote is the cancellation note of a booking the restaurant
+// did not confirm in time.
+const PendingExpiryNote = "Cancelled automatically: the restaurant did not confirm the booking in time."
+
+// PendingExpiryJob cancels bookings still pending TTL after they were made,
+// freeing their tables for the waitlist and voiding their unpaid deposits,
+// and emails their customers.
+type PendingExpiryJob struct {
+	bookingRepo     repository.BookingRepository
+	paymentRepo     repository.PaymentRepository
+	notificationSvc *NotificationService
+	waitlist        WaitlistService
+	ttl             time.Duration
+	batchSize       int
+	log             logger.Logger
+}
+
+func NewPendingExpiryJob(bookingRepo repository.BookingRepository, paymentRepo repository.PaymentRepository, notificationSvc *NotificationService, waitlist WaitlistService, ttl time.Duration, log logger.Logger) *PendingExpiryJob {
+	return &PendingExpiryJob{
+		bookingRepo:     bookingRepo,
+		paymentRepo:     paymentRepo,
+		notificationSvc: notificationSvc,
+		waitlist:        waitlist,
+		ttl:             ttl,
+		batchSize:       PendingExpiryBatchSize,
+		log:             log,
+	}
+}
+
+// Start runs the job in the background until ctx is cancelled.
+func (j *PendingExpiryJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(PendingExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				j.Run(ctx, now)
+			}
+		}
+	}()
+}
+
+// Run performs one pass of the job.
+func (j *PendingExpiryJob) Run(ctx context.Context, now time.Time) {
+	expired := j.expire(ctx, now.Add(-j.ttl))
+	j.log.Info("pending expiry job finished", zap.Int("bookings_expired", expired))
+}
+
+// expire cancels the bookings pending since before createdBefore, batch by
+// batch, and returns how many it cancelled. A booking confirmed or cancelled
+// since it was loaded is left alone, so passes may overlap.
+func (j *PendingExpiryJob) expire(ctx context.Context, createdBefore time.Time) int {
+	var expired int
+	for {
+		bookings, err := j.bookingRepo.GetStalePending(ctx, createdBefore, j.batchSize)
+		if err != nil {
+			j.log.Warn("pending expiry job: loading pending bookings failed", zap.Error(err))
+			return expired
+		}
+
+		for _, booking := range bookings {
+			ok, err := j.bookingRepo.ExpirePending(ctx, booking.ID, PendingExpiryNote)
+			if err != nil {
+				// The booking would come back in the next batch, so the
+				// pass ends here and the next one retries it.
+				j.log.Warn("pending expiry job: cancelling booking failed",
+					zap.String("booking_id", booking.ID.String()),
+					zap.Error(err))
+				return expired
+			}
+			if !ok {
+				continue
+			}
+			expired++
+			if _, err := j.paymentRepo.VoidPendingByBooking(ctx, booking.ID); err != nil {
+				j.log.Warn("pending expiry job: voiding deposit failed",
+					zap.String("booking_id", booking.ID.String()),
+					zap.Error(err))
+			}
+			j.waitlist.SlotFreed(ctx, booking)
+			j.notify(booking)
+		}
+
+		if len(bookings) < j.batchSize {
+			return expired
+		}
+	}
+}
+
+// notify emails the customer that their booking expired. The cancellation
+// is already saved, so a failure here is only logged.
+func (j *PendingExpiryJob) notify(booking *domain.Booking) {
+	if booking.User == nil || booking.Restaurant == nil {
+		j.log.Warn("cannot email booking expiry without the user and restaurant",
+			zap.String("booking_id", booking.ID.String()))
+		return
+	}
+
+	rendered, err := RenderNotification(TemplateBookingExpired, booking.User.Locale, booking.Restaurant.Location(), BookingNotificationData{
+		RestaurantName: booking.Restaurant.Name,
+		BookingID:      booking.ID,
+		StartTime:      booking.StartTime,
+		EndTime:        booking.EndTime,
+		GuestCount:     booking.GuestsCount,
+	})
+	if err != nil {
+		j.log.Warn("failed to render booking expiry email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := j.notificationSvc.SendEmail(booking.User.Email, rendered.Subject, rendered.Body); err != nil {
+		j.log.Warn("failed to send booking expiry email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+}
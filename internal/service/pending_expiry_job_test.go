@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func setupPendingExpiryJob(batchSize int) (*PendingExpiryJob, *BookingMockBookingRepository, *MockPaymentRepository, chan Notification) {
+	bookingRepo := new(BookingMockBookingRepository)
+	paymentRepo := new(MockPaymentRepository)
+	sent := make(chan Notification, 10)
+	notificationSvc := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+	job := NewPendingExpiryJob(bookingRepo, paymentRepo, notificationSvc, &stubWaitlist{}, 30*time.Minute, zap.NewNop())
+	job.batchSize = batchSize
+	return job, bookingRepo, paymentRepo, sent
+}
+
+func pendingBooking(email string) *domain.Booking {
+	return &domain.Booking{
+		ID:         uuid.New(),
+		Status:     domain.BookingStatusPending,
+		StartTime:  time.Date(2024, time.June, 1, 14, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(2024, time.June, 1, 16, 0, 0, 0, time.UTC),
+		Restaurant: &domain.Restaurant{Name: "Osteria"},
+		User:       &domain.User{Email: email, Locale: domain.LocaleEnglish},
+	}
+}
+
+func TestPendingExpiryJob_ExpiresInBatches(t *testing.T) {
+	job, bookingRepo, paymentRepo, sent := setupPendingExpiryJob(2)
+	ctx := context.Background()
+	now := time.Date(2024, time.May, 30, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-30 * time.Minute)
+	first, confirmed, last := pendingBooking("first@example.com"), pendingBooking("confirmed@example.com"), pendingBooking("last@example.com")
+
+	bookingRepo.On("GetStalePending", ctx, cutoff, 2).Return([]*domain.Booking{first, confirmed}, nil).Once()
+	bookingRepo.On("GetStalePending", ctx, cutoff, 2).Return([]*domain.Booking{last}, nil).Once()
+	bookingRepo.On("ExpirePending", ctx, first.ID, PendingExpiryNote).Return(true, nil)
+	// Confirmed by the restaurant after the batch was loaded.
+	bookingRepo.On("ExpirePending", ctx, confirmed.ID, PendingExpiryNote).Return(false, nil)
+	bookingRepo.On("ExpirePending", ctx, last.ID, PendingExpiryNote).Return(true, nil)
+	paymentRepo.On("VoidPendingByBooking", ctx, first.ID).Return(int64(1), nil)
+	paymentRepo.On("VoidPendingByBooking", ctx, last.ID).Return(int64(0), nil)
+
+	job.Run(ctx, now)
+
+	notifications := receiveNotifications(t, sent, 2)
+	recipients := []string{notifications[0].Recipient, notifications[1].Recipient}
+	assert.ElementsMatch(t, []string{"first@example.com", "last@example.com"}, recipients)
+	assert.Equal(t, "Your booking at Osteria was not confirmed", notifications[0].Subject)
+	assert.Contains(t, notifications[0].Message, "the table released")
+	bookingRepo.AssertExpectations(t)
+	paymentRepo.AssertExpectations(t)
+	paymentRepo.AssertNotCalled(t, "VoidPendingByBooking", ctx, confirmed.ID)
+	assert.Equal(t, []*domain.Booking{first, last}, job.waitlist.(*stubWaitlist).freed)
+}
+
+func TestPendingExpiryJob_NothingToExpire(t *testing.T) {
+	job, bookingRepo, paymentRepo, sent := setupPendingExpiryJob(2)
+	ctx := context.Background()
+
+	bookingRepo.On("GetStalePending", ctx, mock.AnythingOfType("time.Time"), 2).Return([]*domain.Booking{}, nil).Once()
+
+	job.Run(ctx, time.Now())
+
+	bookingRepo.AssertNotCalled(t, "ExpirePending", mock.Anything, mock.Anything, mock.Anything)
+	paymentRepo.AssertNotCalled(t, "VoidPendingByBooking", mock.Anything, mock.Anything)
+	assert.Empty(t, sent)
+}
+
+func TestPendingExpiryJob_StopsWhenCancellingFails(t *testing.T) {
+	job, bookingRepo, paymentRepo, sent := setupPendingExpiryJob(2)
+	ctx := context.Background()
+	failing, next := pendingBooking("failing@example.com"), pendingBooking("next@example.com")
+
+	bookingRepo.On("GetStalePending", ctx, mock.AnythingOfType("time.Time"), 2).Return([]*domain.Booking{failing, next}, nil).Once()
+	bookingRepo.On("ExpirePending", ctx, failing.ID, PendingExpiryNote).Return(false, errors.New("connection refused"))
+
+	job.Run(ctx, time.Now())
+
+	bookingRepo.AssertNotCalled(t, "ExpirePending", ctx, next.ID, PendingExpiryNote)
+	bookingRepo.AssertNumberOfCalls(t, "GetStalePending", 1)
+	paymentRepo.AssertNotCalled(t, "VoidPendingByBooking", mock.Anything, mock.Anything)
+	assert.Empty(t, sent)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrNoPhone              = errors.New("the account has no phone number")
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+	ErrPhoneCodeCooldown    = errors.New("a code was sent recently, please wait before requesting another")
+	// ErrInvalidPhoneCode covers wrong, expired and used up codes alike, so
+	// a guess tells nothing about the code.
+	ErrInvalidPhoneCode = errors.New("invalid or expired verification code")
+	ErrPhoneNotVerified = errors.New("verify your phone number to claim guest bookings")
+)
+
+const (
+	phoneCodeTTL      = 10 * time.Minute
+	phoneCodeCooldown = time.Minute
+	// phoneCodeMaxAttempts wrong codes void the code; a new one has to be
+	// requested.
+	phoneCodeMaxAttempts = 5
+)
+
+func (s *userService) SendPhoneCode(ctx context.Context, id uuid.UUID) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user.Phone == "" {
+		return ErrNoPhone
+	}
+	if user.PhoneVerified {
+		return ErrPhoneAlreadyVerified
+	}
+	if user.PhoneCodeExpiresAt != nil && time.Until(*user.PhoneCodeExpiresAt) > phoneCodeTTL-phoneCodeCooldown {
+		return ErrPhoneCodeCooldown
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(phoneCodeTTL)
+	user.PhoneCodeHash = string(hash)
+	user.PhoneCodeExpiresAt = &expiresAt
+	user.PhoneCodeAttempts = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	return s.notificationSvc.SendSMS(user.Phone, fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(phoneCodeTTL.Minutes())))
+}
+
+func (s *userService) VerifyPhone(ctx context.Context, id uuid.UUID, code string) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user.PhoneVerified {
+		return ErrPhoneAlreadyVerified
+	}
+	if user.PhoneCodeHash == "" || user.PhoneCodeExpiresAt == nil || time.Now().After(*user.PhoneCodeExpiresAt) {
+		return ErrInvalidPhoneCode
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PhoneCodeHash), []byte(code)) != nil {
+		user.PhoneCodeAttempts++
+		if user.PhoneCodeAttempts >= phoneCodeMaxAttempts {
+			user.ClearPhoneCode()
+		}
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+		return ErrInvalidPhoneCode
+	}
+
+	user.PhoneVerified = true
+	user.ClearPhoneCode()
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	s.log.Info("phone verified", zap.String("user_id", user.ID.String()))
+	return nil
+}
+
+// generatePhoneCode returns a random six-digit code.
+func generatePhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupPhoneVerification() (*userService, *MockUserRepositoryForUserService, chan Notification) {
+	userRepo := new(MockUserRepositoryForUserService)
+	sent := make(chan Notification, 10)
+	service := NewUserService(userRepo, new(BookingMockBookingRepository), new(MockSessionRevoker), NewLogAuditRecorder(zap.NewNop()), nil, "7", zap.NewNop()).(*userService)
+	service.notificationSvc = newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+	return service, userRepo, sent
+}
+
+func TestVerifyPhone_WithTheTextedCode(t *testing.T) {
+	service, userRepo, sent := setupPhoneVerification()
+	ctx := context.Background()
+
+	user := &domain.User{ID: uuid.New(), Phone: "+77011234567"}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+
+	require.NoError(t, service.SendPhoneCode(ctx, user.ID))
+	sms := receiveNotifications(t, sent, 1)[0]
+	assert.Equal(t, NotificationSMS, sms.Type)
+	assert.Equal(t, "+77011234567", sms.Recipient)
+	code := regexp.MustCompile(`\d{6}`).FindString(sms.Message)
+	require.NotEmpty(t, code)
+
+	require.NoError(t, service.VerifyPhone(ctx, user.ID, code))
+	assert.True(t, user.PhoneVerified)
+	assert.Empty(t, user.PhoneCodeHash)
+	assert.ErrorIs(t, service.VerifyPhone(ctx, user.ID, code), ErrPhoneAlreadyVerified)
+}
+
+func TestSendPhoneCode_Cooldown(t *testing.T) {
+	service, userRepo, _ := setupPhoneVerification()
+	ctx := context.Background()
+
+	user := &domain.User{ID: uuid.New(), Phone: "+77011234567"}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+
+	require.NoError(t, service.SendPhoneCode(ctx, user.ID))
+	assert.ErrorIs(t, service.SendPhoneCode(ctx, user.ID), ErrPhoneCodeCooldown)
+}
+
+func TestSendPhoneCode_NeedsAPhone(t *testing.T) {
+	service, userRepo, _ := setupPhoneVerification()
+
+	user := &domain.User{ID: uuid.New()}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+
+	assert.ErrorIs(t, service.SendPhoneCode(context.Background(), user.ID), ErrNoPhone)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestVerifyPhone_WrongCodesVoidTheCode(t *testing.T) {
+	service, userRepo, sent := setupPhoneVerification()
+	ctx := context.Background()
+
+	user := &domain.User{ID: uuid.New(), Phone: "+77011234567"}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+
+	require.NoError(t, service.SendPhoneCode(ctx, user.ID))
+	code := regexp.MustCompile(`\d{6}`).FindString(receiveNotifications(t, sent, 1)[0].Message)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 0; i < phoneCodeMaxAttempts; i++ {
+		assert.ErrorIs(t, service.VerifyPhone(ctx, user.ID, wrong), ErrInvalidPhoneCode)
+	}
+	assert.ErrorIs(t, service.VerifyPhone(ctx, user.ID, code), ErrInvalidPhoneCode)
+	assert.False(t, user.PhoneVerified)
+}
+
+func TestVerifyPhone_ExpiredCode(t *testing.T) {
+	service, userRepo, sent := setupPhoneVerification()
+	ctx := context.Background()
+
+	user := &domain.User{ID: uuid.New(), Phone: "+77011234567"}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+
+	require.NoError(t, service.SendPhoneCode(ctx, user.ID))
+	code := regexp.MustCompile(`\d{6}`).FindString(receiveNotifications(t, sent, 1)[0].Message)
+	expired := time.Now().Add(-time.Second)
+	user.PhoneCodeExpiresAt = &expired
+
+	assert.ErrorIs(t, service.VerifyPhone(ctx, user.ID, code), ErrInvalidPhoneCode)
+	assert.False(t, user.PhoneVerified)
+}
+
+func TestUpdateUser_NewPhoneNeedsVerifying(t *testing.T) {
+	service, userRepo, _ := setupPhoneVerification()
+
+	user := &domain.User{ID: uuid.New(), Phone: "+77011234567", PhoneVerified: true}
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+
+	_, err := service.UpdateUser(user.ID, "Jane", "Doe", "+77011234567", domain.LocaleEnglish)
+	require.NoError(t, err)
+	assert.True(t, user.PhoneVerified)
+
+	_, err = service.UpdateUser(user.ID, "Jane", "Doe", "8 701 765 43 21", domain.LocaleEnglish)
+	require.NoError(t, err)
+	assert.False(t, user.PhoneVerified)
+}
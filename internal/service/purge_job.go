@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PurgeJob removes data past its retention period once a night, at Hour
+// local time.
+type PurgeJob struct {
+	samples RequestSampleService
+	hour    int
+	log     logger.Logger
+}
+
+func NewPurgeJob(samples RequestSampleService, hour int, log logger.Logger) *PurgeJob {
+	return &PurgeJob{samples: samples, hour: hour, log: log}
+}
+
+// Start runs the job in the background until ctx is cancelled.
+func (j *PurgeJob) Start(ctx context.Context) {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextAnalyticsRun(time.Now(), j.hour)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case now := <-timer.C:
+				j.Run(ctx, now)
+			}
+		}
+	}()
+}
+
+// Run performs one pass of the job.
+func (j *PurgeJob) Run(ctx context.Context, now time.Time) {
+	removed, err := j.samples.Purge(ctx, now)
+	if err != nil {
+		j.log.Warn("purge job: request sample purge failed", zap.Error(err))
+		return
+	}
+	j.log.Info("purge job finished", zap.Int64("request_samples_removed", removed))
+}
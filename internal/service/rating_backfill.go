@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"restaurant-booking/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// JobKindRecomputeRatings recomputes every restaurant's rating and review
+// count from its visible reviews.
+const JobKindRecomputeRatings = "recompute_ratings"
+
+const ratingBackfillBatchSize = 100
+
+// ratingBackfill walks the restaurants by ID. Its cursor is the last ID it
+// recomputed.
+type ratingBackfill struct {
+	reviewRepo repository.ReviewRepository
+}
+
+func NewRatingBackfill(reviewRepo repository.ReviewRepository) Backfill {
+	return &ratingBackfill{reviewRepo: reviewRepo}
+}
+
+func (b *ratingBackfill) Kind() string {
+	return JobKindRecomputeRatings
+}
+
+func (b *ratingBackfill) Remaining(ctx context.Context, cursor string) (int64, error) {
+	after, err := parseRestaurantCursor(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return b.reviewRepo.CountRestaurantsAfter(ctx, after)
+}
+
+func (b *ratingBackfill) Step(ctx context.Context, cursor string) (string, int, error) {
+	after, err := parseRestaurantCursor(cursor)
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	ids, err := b.reviewRepo.RefreshRestaurantRatings(ctx, after, ratingBackfillBatchSize)
+	if err != nil {
+		return cursor, 0, err
+	}
+	if len(ids) == 0 {
+		return cursor, 0, nil
+	}
+	return ids[len(ids)-1].String(), len(ids), nil
+}
+
+func parseRestaurantCursor(cursor string) (uuid.UUID, error) {
+	if cursor == "" {
+		return uuid.Nil, nil
+	}
+	after, err := uuid.Parse(cursor)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid restaurant cursor %q: %w", cursor, err)
+	}
+	return after, nil
+}
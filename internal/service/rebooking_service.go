@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RebookingOfferTTL is how long a rebooking offer can be accepted.
+const RebookingOfferTTL = 72 * time.Hour
+
+const (
+	// rebookingOptionsPerKind caps the slots offered at the same restaurant
+	// and the similar restaurants offered, each.
+	rebookingOptionsPerKind = 3
+	// rebookingSearchDays is how many days either side of the cancelled
+	// booking are searched for another slot at the same restaurant.
+	rebookingSearchDays = 14
+	// similarRestaurantRadiusKm and similarRestaurantCandidates bound the
+	// nearby search for similar restaurants.
+	similarRestaurantRadiusKm   = 10
+	similarRestaurantCandidates = 50
+	// similarPriceTolerance is how far, as a fraction, a similar restaurant's
+	// average price may be from the original one.
+	similarPriceTolerance = 0.3
+)
+
+var (
+	ErrRebookingOfferNotFound = errors.New("rebooking offer not found")
+	ErrRebookingOfferExpired  = errors.New("rebooking offer has expired")
+	ErrRebookingOfferAnswered = errors.New("rebooking offer has already been accepted")
+	ErrInvalidRebookingOption = errors.New("invalid rebooking option")
+	ErrRebookingSlotTaken     = errors.New("the selected slot is no longer available")
+)
+
+// RebookingReport summarises the offers created in a period. RecoveryRate is
+// the share of offers that were accepted.
+type RebookingReport struct {
+	Offered      int64
+	Accepted     int64
+	Expired      int64
+	Pending      int64
+	RecoveryRate float64
+}
+
+type RebookingService interface {
+	// OfferRebooking tells the customer that the restaurant cancelled their
+	// booking and offers alternatives when any are free. It returns a nil
+	// offer when there was nothing to offer.
+	OfferRebooking(ctx context.Context, booking *domain.Booking) (*domain.RebookingOffer, error)
+	// GetOffer returns the offer only to the customer it was made to.
+	GetOffer(ctx context.Context, id, userID uuid.UUID) (*domain.RebookingOffer, error)
+	// AcceptOffer books the option at index option and moves the completed
+	// payments of the cancelled booking to the new one.
+	AcceptOffer(ctx context.Context, id, userID uuid.UUID, option int) (*domain.Booking, error)
+	Report(ctx context.Context, from, to time.Time) (*RebookingReport, error)
+}
+
+type rebookingService struct {
+	offerRepo       repository.RebookingOfferRepository
+	bookingRepo     repository.BookingRepository
+	tableRepo       repository.TableRepository
+	restaurantRepo  repository.RestaurantRepository
+	paymentRepo     repository.PaymentRepository
+	notificationSvc *NotificationService
+	db              *gorm.DB
+	log             logger.Logger
+}
+
+func NewRebookingService(
+	offerRepo repository.RebookingOfferRepository,
+	bookingRepo repository.BookingRepository,
+	tableRepo repository.TableRepository,
+	restaurantRepo repository.RestaurantRepository,
+	paymentRepo repository.PaymentRepository,
+	notificationSvc *NotificationService,
+	db *gorm.DB,
+	log logger.Logger,
+) RebookingService {
+	return &rebookingService{
+		offerRepo:       offerRepo,
+		bookingRepo:     bookingRepo,
+		tableRepo:       tableRepo,
+		restaurantRepo:  restaurantRepo,
+		paymentRepo:     paymentRepo,
+		notificationSvc: notificationSvc,
+		db:              db,
+		log:             log,
+	}
+}
+
+func (s *rebookingService) OfferRebooking(ctx context.Context, booking *domain.Booking) (*domain.RebookingOffer, error) {
+	// A guest has no account to take the offer.
+	if booking.UserID == nil {
+		return nil, nil
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, booking.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	options, err := s.sameRestaurantOptions(ctx, restaurant, booking, now)
+	if err != nil {
+		return nil, err
+	}
+	similar, err := s.similarRestaurantOptions(ctx, restaurant, booking, now)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, similar...)
+
+	var offer *domain.RebookingOffer
+	if len(options) > 0 {
+		offer = &domain.RebookingOffer{
+			BookingID:    booking.ID,
+			UserID:       *booking.UserID,
+			RestaurantID: booking.RestaurantID,
+			GuestsCount:  booking.GuestsCount,
+			Options:      options,
+			Status:       domain.RebookingOfferStatusPending,
+			ExpiresAt:    now.Add(RebookingOfferTTL),
+			CreatedAt:    now,
+		}
+		if err := s.offerRepo.Create(ctx, offer); err != nil {
+			return nil, err
+		}
+	}
+
+	notifyCancellation(s.notificationSvc, s.log, restaurant, booking, offer)
+	return offer, nil
+}
+
+func (s *rebookingService) GetOffer(ctx context.Context, id, userID uuid.UUID) (*domain.RebookingOffer, error) {
+	offer, err := s.offerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRebookingOfferNotFound
+		}
+		return nil, err
+	}
+	// Someone else's offer is reported as missing rather than forbidden.
+	if offer.UserID != userID {
+		return nil, ErrRebookingOfferNotFound
+	}
+	return offer, nil
+}
+
+func (s *rebookingService) AcceptOffer(ctx context.Context, id, userID uuid.UUID, option int) (*domain.Booking, error) {
+	offer, err := s.GetOffer(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case offer.Status == domain.RebookingOfferStatusAccepted:
+		return nil, ErrRebookingOfferAnswered
+	case offer.Status == domain.RebookingOfferStatusExpired:
+		return nil, ErrRebookingOfferExpired
+	case !now.Before(offer.ExpiresAt):
+		offer.Status = domain.RebookingOfferStatusExpired
+		if err := s.offerRepo.Update(ctx, offer); err != nil {
+			s.log.Warn("failed to expire rebooking offer",
+				zap.String("offer_id", offer.ID.String()),
+				zap.Error(err))
+		}
+		return nil, ErrRebookingOfferExpired
+	}
+	if option < 0 || option >= len(offer.Options) {
+		return nil, ErrInvalidRebookingOption
+	}
+	chosen := offer.Options[option]
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, chosen.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	table, err := s.freeTable(ctx, restaurant, chosen.StartTime, chosen.EndTime, offer.GuestsCount)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, ErrRebookingSlotTaken
+	}
+
+	start := chosen.StartTime
+	booking := &domain.Booking{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		UserID:       &offer.UserID,
+		BookingDate:  time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()),
+		StartTime:    chosen.StartTime,
+		EndTime:      chosen.EndTime,
+		GuestsCount:  offer.GuestsCount,
+		// The replacement is offered by the platform, so it needs no second
+		// confirmation from the restaurant.
+		Status: domain.BookingStatusConfirmed,
+	}
+	booking.ApplyPolicy(restaurant.CancellationPolicy)
+
+	// The table was free when it was picked, but another booking may take
+	// it or the restaurant's last seats before this one is written.
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created, err := s.bookingRepo.WithTx(tx).CreateIfAvailable(ctx, booking)
+		if errors.Is(err, ErrGuestLimitReached) || (err == nil && !created) {
+			return ErrRebookingSlotTaken
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.paymentRepo.WithTx(tx).ReassignBooking(ctx, offer.BookingID, booking.ID); err != nil {
+			return err
+		}
+
+		offer.Status = domain.RebookingOfferStatusAccepted
+		offer.AcceptedBookingID = &booking.ID
+		offer.RespondedAt = &now
+		return s.offerRepo.WithTx(tx).Update(ctx, offer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func (s *rebookingService) Report(ctx context.Context, from, to time.Time) (*RebookingReport, error) {
+	// Expiry is recorded lazily, so settle it before counting.
+	if _, err := s.offerRepo.ExpirePending(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.offerRepo.CountByStatus(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RebookingReport{
+		Accepted: counts[domain.RebookingOfferStatusAccepted],
+		Expired:  counts[domain.RebookingOfferStatusExpired],
+		Pending:  counts[domain.RebookingOfferStatusPending],
+	}
+	report.Offered = report.Accepted + report.Expired + report.Pending
+	if report.Offered > 0 {
+		report.RecoveryRate = float64(report.Accepted) / float64(report.Offered)
+	}
+	return report, nil
+}
+
+// sameRestaurantOptions looks for the cancelled slot's time of day on the
+// nearest other days, alternating later and earlier, skipping the past.
+func (s *rebookingService) sameRestaurantOptions(ctx context.Context, restaurant *domain.Restaurant, booking *domain.Booking, now time.Time) ([]domain.RebookingOption, error) {
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, restaurant.ID, booking.GuestsCount)
+	if err != nil {
+		return nil, err
+	}
+	tables = fittingTables(tables, booking.GuestsCount)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	duration := booking.EndTime.Sub(booking.StartTime)
+	from := booking.StartTime.AddDate(0, 0, -rebookingSearchDays)
+	to := booking.StartTime.AddDate(0, 0, rebookingSearchDays).Add(duration)
+	// One query covers every candidate day.
+	bookings, err := s.bookingRepo.GetOverlapping(ctx, restaurant.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	busy := bookingsByTable(bookings)
+
+	var options []domain.RebookingOption
+	for day := 1; day <= rebookingSearchDays && len(options) < rebookingOptionsPerKind; day++ {
+		for _, offset := range []int{day, -day} {
+			start := booking.StartTime.AddDate(0, 0, offset)
+			if !start.After(now) || len(options) == rebookingOptionsPerKind {
+				continue
+			}
+			end := start.Add(duration)
+			if len(freeTablesBetween(restaurant, tables, busy, start, end)) > 0 {
+				options = append(options, rebookingOption(domain.RebookingOptionSameRestaurant, restaurant, start, end))
+			}
+		}
+	}
+	return options, nil
+}
+
+// similarRestaurantOptions offers the cancelled slot at the nearest
+// restaurants of the same cuisine and a similar average price.
+func (s *rebookingService) similarRestaurantOptions(ctx context.Context, restaurant *domain.Restaurant, booking *domain.Booking, now time.Time) ([]domain.RebookingOption, error) {
+	if restaurant.Latitude == nil || restaurant.Longitude == nil || !booking.StartTime.After(now) {
+		return nil, nil
+	}
+
+	nearby, err := s.restaurantRepo.ListNearby(ctx, *restaurant.Latitude, *restaurant.Longitude,
+		similarRestaurantRadiusKm, similarRestaurantCandidates, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []domain.RebookingOption
+	for _, candidate := range nearby {
+		if len(options) == rebookingOptionsPerKind {
+			break
+		}
+		if candidate.ID == restaurant.ID || !similarRestaurant(restaurant, &candidate.Restaurant) {
+			continue
+		}
+
+		table, err := s.freeTable(ctx, &candidate.Restaurant, booking.StartTime, booking.EndTime, booking.GuestsCount)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil {
+			options = append(options, rebookingOption(domain.RebookingOptionSimilarRestaurant, &candidate.Restaurant, booking.StartTime, booking.EndTime))
+		}
+	}
+	return options, nil
+}
+
+// freeTable returns the best-fitting table of the restaurant free for guests
+// between start and end, or nil.
+func (s *rebookingService) freeTable(ctx context.Context, restaurant *domain.Restaurant, start, end time.Time, guests int) (*domain.Table, error) {
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, restaurant.ID, guests)
+	if err != nil {
+		return nil, err
+	}
+	tables = fittingTables(tables, guests)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	bookings, err := s.bookingRepo.GetOverlapping(ctx, restaurant.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return bestFitTable(freeTablesBetween(restaurant, tables, bookingsByTable(bookings), start, end)), nil
+}
+
+func similarRestaurant(original, candidate *domain.Restaurant) bool {
+	if candidate.CuisineType != original.CuisineType {
+		return false
+	}
+	if original.AveragePrice == 0 {
+		return true
+	}
+	diff := math.Abs(float64(candidate.AveragePrice - original.AveragePrice))
+	return diff <= similarPriceTolerance*float64(original.AveragePrice)
+}
+
+func rebookingOption(kind domain.RebookingOptionKind, restaurant *domain.Restaurant, start, end time.Time) domain.RebookingOption {
+	return domain.RebookingOption{
+		Kind:           kind,
+		RestaurantID:   restaurant.ID,
+		RestaurantName: restaurant.Name,
+		StartTime:      start,
+		EndTime:        end,
+	}
+}
+
+// notifyCancellation emails the customer about the cancellation, listing the
+// offer's options when there is one. The cancellation is already saved, so
+// a failure here is only logged.
+func notifyCancellation(notificationSvc *NotificationService, log logger.Logger, restaurant *domain.Restaurant, booking *domain.Booking, offer *domain.RebookingOffer) {
+	if booking.User == nil {
+		log.Warn("cannot email booking cancellation without the user",
+			zap.String("booking_id", booking.ID.String()))
+		return
+	}
+
+	rendered, err := RenderNotification(TemplateBookingCancellation, booking.User.Locale, restaurant.Location(), CancellationNotificationData{
+		BookingNotificationData: BookingNotificationData{
+			RestaurantName: restaurant.Name,
+			BookingID:      booking.ID,
+			StartTime:      booking.StartTime,
+			EndTime:        booking.EndTime,
+			GuestCount:     booking.GuestsCount,
+		},
+		Offer: offer,
+	})
+	if err != nil {
+		log.Warn("failed to render booking cancellation email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := notificationSvc.SendEmail(booking.User.Email, rendered.Subject, rendered.Body); err != nil {
+		log.Warn("failed to send booking cancellation email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+}
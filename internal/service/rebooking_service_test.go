@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockRebookingOfferRepository struct {
+	mock.Mock
+}
+
+func (m *MockRebookingOfferRepository) Create(ctx context.Context, offer *domain.RebookingOffer) error {
+	args := m.Called(ctx, offer)
+	return args.Error(0)
+}
+
+func (m *MockRebookingOfferRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RebookingOffer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RebookingOffer), args.Error(1)
+}
+
+func (m *MockRebookingOfferRepository) Update(ctx context.Context, offer *domain.RebookingOffer) error {
+	args := m.Called(ctx, offer)
+	return args.Error(0)
+}
+
+func (m *MockRebookingOfferRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRebookingOfferRepository) CountByStatus(ctx context.Context, from, to time.Time) (map[domain.RebookingOfferStatus]int64, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(map[domain.RebookingOfferStatus]int64), args.Error(1)
+}
+
+func (m *MockRebookingOfferRepository) WithTx(tx *gorm.DB) repository.RebookingOfferRepository {
+	return m
+}
+
+type rebookingMocks struct {
+	offerRepo      *MockRebookingOfferRepository
+	bookingRepo    *BookingMockBookingRepository
+	tableRepo      *MockTableRepository
+	restaurantRepo *BookingMockRestaurantRepository
+	paymentRepo    *MockPaymentRepository
+	sqlMock        sqlmock.Sqlmock
+	sent           chan Notification
+}
+
+func setupRebookingService() (RebookingService, *rebookingMocks) {
+	mocks := &rebookingMocks{
+		offerRepo:      new(MockRebookingOfferRepository),
+		bookingRepo:    new(BookingMockBookingRepository),
+		tableRepo:      new(MockTableRepository),
+		restaurantRepo: new(BookingMockRestaurantRepository),
+		paymentRepo:    new(MockPaymentRepository),
+		sent:           make(chan Notification, 10),
+	}
+
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	mocks.sqlMock = sqlMock
+
+	notifications := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		mocks.sent <- n
+		return nil
+	})
+
+	service := NewRebookingService(mocks.offerRepo, mocks.bookingRepo, mocks.tableRepo, mocks.restaurantRepo,
+		mocks.paymentRepo, notifications, db, zap.NewNop())
+	return service, mocks
+}
+
+// cancelledBooking is a booking for four three days from now, inside
+// availabilityRestaurant's working hours.
+func cancelledBooking(restaurantID uuid.UUID) *domain.Booking {
+	day := time.Now().UTC().AddDate(0, 0, 3)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 19, 0, 0, 0, time.UTC)
+	return &domain.Booking{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		UserID:       uuidPtr(uuid.New()),
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+		GuestsCount:  4,
+		Status:       domain.BookingStatusCancelled,
+		User:         &domain.User{Email: "guest@example.com"},
+	}
+}
+
+func TestOfferRebooking_BuildsOptions(t *testing.T) {
+	service, mocks := setupRebookingService()
+	ctx := context.Background()
+
+	lat, lng := 43.238, 76.945
+	restaurant := availabilityRestaurant()
+	restaurant.Name = "Trattoria"
+	restaurant.Latitude, restaurant.Longitude = &lat, &lng
+	restaurant.CuisineType = domain.CuisineTypeItalian
+	restaurant.AveragePrice = 10000
+	booking := cancelledBooking(restaurant.ID)
+
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 4).Return([]*domain.Table{table}, nil)
+	// The next day is fully booked.
+	nextDay := booking.StartTime.AddDate(0, 0, 1)
+	mocks.bookingRepo.On("GetOverlapping", ctx, restaurant.ID, mock.Anything, mock.Anything).Return([]*domain.Booking{
+		{TableID: table.ID, StartTime: nextDay, EndTime: nextDay.Add(2 * time.Hour)},
+	}, nil)
+
+	similar := availabilityRestaurant()
+	similar.Name = "Osteria"
+	similar.CuisineType = domain.CuisineTypeItalian
+	similar.AveragePrice = 11000
+	otherCuisine := availabilityRestaurant()
+	otherCuisine.CuisineType = domain.CuisineTypeJapanese
+	otherCuisine.AveragePrice = 10000
+	pricier := availabilityRestaurant()
+	pricier.CuisineType = domain.CuisineTypeItalian
+	pricier.AveragePrice = 20000
+	full := availabilityRestaurant()
+	full.CuisineType = domain.CuisineTypeItalian
+	full.AveragePrice = 9000
+
+	mocks.restaurantRepo.On("ListNearby", ctx, lat, lng, float64(similarRestaurantRadiusKm), similarRestaurantCandidates, 0).
+		Return([]*repository.NearbyRestaurant{
+			{Restaurant: *restaurant},
+			{Restaurant: *otherCuisine},
+			{Restaurant: *pricier},
+			{Restaurant: *full},
+			{Restaurant: *similar},
+		}, nil)
+	fullTable := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 6}
+	mocks.tableRepo.On("GetByMinCapacity", ctx, full.ID, 4).Return([]*domain.Table{fullTable}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, full.ID, booking.StartTime, booking.EndTime).Return([]*domain.Booking{
+		{TableID: fullTable.ID, StartTime: booking.StartTime, EndTime: booking.EndTime},
+	}, nil)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, similar.ID, 4).Return([]*domain.Table{{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, similar.ID, booking.StartTime, booking.EndTime).Return([]*domain.Booking{}, nil)
+
+	var created *domain.RebookingOffer
+	mocks.offerRepo.On("Create", ctx, mock.AnythingOfType("*domain.RebookingOffer")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.RebookingOffer) }).
+		Return(nil)
+
+	offer, err := service.OfferRebooking(ctx, booking)
+
+	require.NoError(t, err)
+	require.Same(t, created, offer)
+	assert.Equal(t, *booking.UserID, offer.UserID)
+	assert.Equal(t, domain.RebookingOfferStatusPending, offer.Status)
+	assert.WithinDuration(t, time.Now().Add(RebookingOfferTTL), offer.ExpiresAt, time.Minute)
+
+	require.Len(t, offer.Options, 4)
+	for i, offset := range []int{-1, 2, -2} {
+		assert.Equal(t, domain.RebookingOptionSameRestaurant, offer.Options[i].Kind)
+		assert.Equal(t, booking.StartTime.AddDate(0, 0, offset), offer.Options[i].StartTime)
+	}
+	assert.Equal(t, domain.RebookingOptionSimilarRestaurant, offer.Options[3].Kind)
+	assert.Equal(t, similar.ID, offer.Options[3].RestaurantID)
+	assert.Equal(t, booking.StartTime, offer.Options[3].StartTime)
+
+	email := receiveNotifications(t, mocks.sent, 1)[0]
+	assert.Equal(t, "guest@example.com", email.Recipient)
+	assert.Contains(t, email.Message, "/api/rebooking-offers/"+offer.ID.String()+"/accept")
+	assert.Contains(t, email.Message, "Osteria")
+}
+
+func TestOfferRebooking_NothingToOffer(t *testing.T) {
+	service, mocks := setupRebookingService()
+	ctx := context.Background()
+
+	restaurant := availabilityRestaurant()
+	booking := cancelledBooking(restaurant.ID)
+
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 4).Return([]*domain.Table{}, nil)
+
+	offer, err := service.OfferRebooking(ctx, booking)
+
+	require.NoError(t, err)
+	assert.Nil(t, offer)
+	mocks.offerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	email := receiveNotifications(t, mocks.sent, 1)[0]
+	assert.NotContains(t, email.Message, "/api/rebooking-offers/")
+}
+
+func pendingOffer(restaurant *domain.Restaurant) *domain.RebookingOffer {
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	return &domain.RebookingOffer{
+		ID:           uuid.New(),
+		BookingID:    uuid.New(),
+		UserID:       uuid.New(),
+		RestaurantID: restaurant.ID,
+		GuestsCount:  2,
+		Options: []domain.RebookingOption{{
+			Kind:         domain.RebookingOptionSameRestaurant,
+			RestaurantID: restaurant.ID,
+			StartTime:    start,
+			EndTime:      start.Add(2 * time.Hour),
+		}},
+		Status:    domain.RebookingOfferStatusPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestAcceptOffer_BooksAndMovesDeposit(t *testing.T) {
+	service, mocks := setupRebookingService()
+	ctx := context.Background()
+
+	// No schedule, so any start time is accepted.
+	restaurant := &domain.Restaurant{ID: uuid.New()}
+	offer := pendingOffer(restaurant)
+	option := offer.Options[0]
+	table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 2}
+
+	mocks.offerRepo.On("GetByID", ctx, offer.ID).Return(offer, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, restaurant.ID, option.StartTime, option.EndTime).Return([]*domain.Booking{}, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).Return(true, nil)
+	mocks.paymentRepo.On("ReassignBooking", ctx, offer.BookingID, mock.AnythingOfType("uuid.UUID")).Return(int64(1), nil)
+	mocks.offerRepo.On("Update", ctx, offer).Return(nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	booking, err := service.AcceptOffer(ctx, offer.ID, offer.UserID, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, table.ID, booking.TableID)
+	assert.Equal(t, &offer.UserID, booking.UserID)
+	assert.Equal(t, domain.BookingStatusConfirmed, booking.Status)
+	assert.Equal(t, domain.RebookingOfferStatusAccepted, offer.Status)
+	assert.Equal(t, &booking.ID, offer.AcceptedBookingID)
+	mocks.paymentRepo.AssertCalled(t, "ReassignBooking", ctx, offer.BookingID, booking.ID)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestAcceptOffer_Expired(t *testing.T) {
+	service, mocks := setupRebookingService()
+	ctx := context.Background()
+
+	offer := pendingOffer(&domain.Restaurant{ID: uuid.New()})
+	offer.ExpiresAt = time.Now().Add(-time.Minute)
+	mocks.offerRepo.On("GetByID", ctx, offer.ID).Return(offer, nil)
+	mocks.offerRepo.On("Update", ctx, offer).Return(nil)
+
+	_, err := service.AcceptOffer(ctx, offer.ID, offer.UserID, 0)
+
+	assert.ErrorIs(t, err, ErrRebookingOfferExpired)
+	assert.Equal(t, domain.RebookingOfferStatusExpired, offer.Status)
+	mocks.bookingRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything, mock.Anything)
+}
+
+func TestAcceptOffer_SlotTakenMeanwhile(t *testing.T) {
+	for name, tc := range map[string]struct {
+		created bool
+		err     error
+	}{
+		"table booked":    {false, nil},
+		"restaurant full": {false, fmt.Errorf("%w: 38 of 40 guests already booked for that time", ErrGuestLimitReached)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, mocks := setupRebookingService()
+			ctx := context.Background()
+
+			restaurant := &domain.Restaurant{ID: uuid.New()}
+			offer := pendingOffer(restaurant)
+			option := offer.Options[0]
+			table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 2}
+
+			mocks.offerRepo.On("GetByID", ctx, offer.ID).Return(offer, nil)
+			mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+			mocks.tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+			mocks.bookingRepo.On("GetOverlapping", ctx, restaurant.ID, option.StartTime, option.EndTime).Return([]*domain.Booking{}, nil)
+			mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).Return(tc.created, tc.err)
+			mocks.sqlMock.ExpectBegin()
+			mocks.sqlMock.ExpectRollback()
+
+			_, err := service.AcceptOffer(ctx, offer.ID, offer.UserID, 0)
+
+			assert.ErrorIs(t, err, ErrRebookingSlotTaken)
+			assert.Equal(t, domain.RebookingOfferStatusPending, offer.Status)
+			mocks.paymentRepo.AssertNotCalled(t, "ReassignBooking", mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAcceptOffer_Rejections(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New()}
+
+	t.Run("other user", func(t *testing.T) {
+		service, mocks := setupRebookingService()
+		offer := pendingOffer(restaurant)
+		mocks.offerRepo.On("GetByID", mock.Anything, offer.ID).Return(offer, nil)
+
+		_, err := service.AcceptOffer(context.Background(), offer.ID, uuid.New(), 0)
+		assert.ErrorIs(t, err, ErrRebookingOfferNotFound)
+	})
+
+	t.Run("already accepted", func(t *testing.T) {
+		service, mocks := setupRebookingService()
+		offer := pendingOffer(restaurant)
+		offer.Status = domain.RebookingOfferStatusAccepted
+		mocks.offerRepo.On("GetByID", mock.Anything, offer.ID).Return(offer, nil)
+
+		_, err := service.AcceptOffer(context.Background(), offer.ID, offer.UserID, 0)
+		assert.ErrorIs(t, err, ErrRebookingOfferAnswered)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		service, mocks := setupRebookingService()
+		offer := pendingOffer(restaurant)
+		mocks.offerRepo.On("GetByID", mock.Anything, offer.ID).Return(offer, nil)
+
+		_, err := service.AcceptOffer(context.Background(), offer.ID, offer.UserID, 1)
+		assert.ErrorIs(t, err, ErrInvalidRebookingOption)
+	})
+
+	t.Run("slot taken", func(t *testing.T) {
+		service, mocks := setupRebookingService()
+		offer := pendingOffer(restaurant)
+		option := offer.Options[0]
+		table := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 2}
+		mocks.offerRepo.On("GetByID", mock.Anything, offer.ID).Return(offer, nil)
+		mocks.restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+		mocks.tableRepo.On("GetByMinCapacity", mock.Anything, restaurant.ID, 2).Return([]*domain.Table{table}, nil)
+		mocks.bookingRepo.On("GetOverlapping", mock.Anything, restaurant.ID, option.StartTime, option.EndTime).Return([]*domain.Booking{
+			{TableID: table.ID, StartTime: option.StartTime, EndTime: option.EndTime},
+		}, nil)
+
+		_, err := service.AcceptOffer(context.Background(), offer.ID, offer.UserID, 0)
+		assert.ErrorIs(t, err, ErrRebookingSlotTaken)
+	})
+}
+
+func TestRebookingReport_RecoveryRate(t *testing.T) {
+	service, mocks := setupRebookingService()
+	ctx := context.Background()
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+
+	mocks.offerRepo.On("ExpirePending", ctx, mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+	mocks.offerRepo.On("CountByStatus", ctx, from, to).Return(map[domain.RebookingOfferStatus]int64{
+		domain.RebookingOfferStatusAccepted: 3,
+		domain.RebookingOfferStatusExpired:  4,
+		domain.RebookingOfferStatusPending:  1,
+	}, nil)
+
+	report, err := service.Report(ctx, from, to)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), report.Offered)
+	assert.Equal(t, int64(3), report.Accepted)
+	assert.Equal(t, int64(4), report.Expired)
+	assert.InDelta(t, 0.375, report.RecoveryRate, 1e-9)
+	mocks.offerRepo.AssertExpectations(t)
+}
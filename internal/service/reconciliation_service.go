@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReconciliationStatus says whether a booking's money has reached the
+// restaurant.
+type ReconciliationStatus string
+
+const (
+	// ReconciliationFullySettled means the restaurant was paid exactly what
+	// it is due.
+	ReconciliationFullySettled ReconciliationStatus = "fully_settled"
+	// ReconciliationPending means part of what is due has not been paid out
+	// yet.
+	ReconciliationPending ReconciliationStatus = "pending"
+	// ReconciliationDiscrepancy means the ledger contradicts the payments,
+	// or the restaurant was paid more than it is due.
+	ReconciliationDiscrepancy ReconciliationStatus = "discrepancy"
+)
+
+// ReconciliationRow is the money trail of one booking, in KZT. Charged
+// counts captured payments including the service fee, and Fees the service
+// fees among them. Commission is the part of Fees the platform keeps, which
+// is all of it except for refunded payments. Due is what the restaurant is
+// owed, Charged - Refunded - Commission, and Settled what it was paid out
+// through payment_to_restaurant ledger entries.
+type ReconciliationRow struct {
+	BookingID     uuid.UUID
+	StartTime     time.Time
+	BookingStatus domain.BookingStatus
+	Charged       int
+	Refunded      int
+	Fees          int
+	Commission    int
+	Due           int
+	Settled       int
+	Status        ReconciliationStatus
+	// Problems, PaymentIDs and LedgerEntries are only filled in for
+	// discrepancies, so support can go straight to the entries involved.
+	Problems      []string
+	PaymentIDs    []uuid.UUID
+	LedgerEntries []*domain.WalletTransaction
+}
+
+// ReconciliationTotals sums the rows of a month.
+type ReconciliationTotals struct {
+	Charged       int
+	Refunded      int
+	Fees          int
+	Commission    int
+	Due           int
+	Settled       int
+	Discrepancies int
+}
+
+type Reconciliation struct {
+	RestaurantID uuid.UUID
+	Month        string
+	Rows         []*ReconciliationRow
+	Totals       ReconciliationTotals
+}
+
+type ReconciliationService interface {
+	// MonthlyReport reconciles every booking of the restaurant starting in
+	// the calendar month of month, in UTC. Only people who can manage the
+	// restaurant may see it.
+	MonthlyReport(ctx context.Context, restaurantID, userID uuid.UUID, month time.Time) (*Reconciliation, error)
+}
+
+type reconciliationService struct {
+	reconciliationRepo repository.ReconciliationRepository
+	restaurantRepo     repository.RestaurantRepository
+	authz              RestaurantAuthorizer
+}
+
+func NewReconciliationService(
+	reconciliationRepo repository.ReconciliationRepository,
+	restaurantRepo repository.RestaurantRepository,
+	authz RestaurantAuthorizer,
+) ReconciliationService {
+	return &reconciliationService{
+		reconciliationRepo: reconciliationRepo,
+		restaurantRepo:     restaurantRepo,
+		authz:              authz,
+	}
+}
+
+func (s *reconciliationService) MonthlyReport(ctx context.Context, restaurantID, userID uuid.UUID, month time.Time) (*Reconciliation, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, userID, "reconciliation.view"); err != nil {
+		return nil, err
+	}
+
+	month = month.UTC()
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	bookings, err := s.reconciliationRepo.Bookings(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.reconciliationRepo.Payments(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.reconciliationRepo.LedgerEntries(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentsByBooking := make(map[uuid.UUID][]*domain.Payment)
+	for _, payment := range payments {
+		if payment.BookingID != nil {
+			paymentsByBooking[*payment.BookingID] = append(paymentsByBooking[*payment.BookingID], payment)
+		}
+	}
+	entriesByBooking := make(map[uuid.UUID][]*domain.WalletTransaction)
+	for _, entry := range entries {
+		if entry.BookingID != nil {
+			entriesByBooking[*entry.BookingID] = append(entriesByBooking[*entry.BookingID], entry)
+		}
+	}
+
+	report := &Reconciliation{
+		RestaurantID: restaurantID,
+		Month:        from.Format("2006-01"),
+		Rows:         make([]*ReconciliationRow, 0, len(bookings)),
+	}
+	for _, booking := range bookings {
+		row := reconcileBooking(booking, paymentsByBooking[booking.ID], entriesByBooking[booking.ID])
+		report.Rows = append(report.Rows, row)
+
+		report.Totals.Charged += row.Charged
+		report.Totals.Refunded += row.Refunded
+		report.Totals.Fees += row.Fees
+		report.Totals.Commission += row.Commission
+		report.Totals.Due += row.Due
+		report.Totals.Settled += row.Settled
+		if row.Status == ReconciliationDiscrepancy {
+			report.Totals.Discrepancies++
+		}
+	}
+	return report, nil
+}
+
+// reconcileBooking works out one row from the booking's payments and ledger
+// entries and cross-checks the two.
+func reconcileBooking(booking *domain.Booking, payments []*domain.Payment, entries []*domain.WalletTransaction) *ReconciliationRow {
+	row := &ReconciliationRow{
+		BookingID:     booking.ID,
+		StartTime:     booking.StartTime,
+		BookingStatus: booking.Status,
+	}
+
+	var refundedPayments, walletPayments int
+	for _, payment := range payments {
+		switch payment.PaymentStatus {
+		case domain.PaymentStatusCompleted:
+			row.Commission += payment.ServiceFeeAmount
+		case domain.PaymentStatusRefunded:
+			refundedPayments += payment.TotalCharged()
+		default:
+			// Pending and failed payments never moved any money.
+			continue
+		}
+		row.Charged += payment.TotalCharged()
+		row.Fees += payment.ServiceFeeAmount
+		if payment.PaymentMethod == domain.PaymentMethodWallet {
+			walletPayments += payment.TotalCharged()
+		}
+	}
+
+	var walletCharges int
+	for _, entry := range entries {
+		switch entry.Type {
+		case domain.TransactionBookingCharge:
+			walletCharges += entry.Amount
+		case domain.TransactionRefund:
+			row.Refunded += entry.Amount
+		case domain.TransactionPaymentToRestaurant:
+			row.Settled += entry.Amount
+		}
+	}
+
+	row.Due = max(row.Charged-row.Refunded-row.Commission, 0)
+
+	if walletCharges != walletPayments {
+		row.Problems = append(row.Problems, fmt.Sprintf("wallet charges of %d do not match wallet payments of %d", walletCharges, walletPayments))
+	}
+	if row.Refunded < refundedPayments {
+		row.Problems = append(row.Problems, fmt.Sprintf("refunded payments of %d have only %d in refund entries", refundedPayments, row.Refunded))
+	}
+	if row.Refunded > row.Charged {
+		row.Problems = append(row.Problems, fmt.Sprintf("refunds of %d exceed the %d charged", row.Refunded, row.Charged))
+	}
+	if row.Settled > row.Due {
+		row.Problems = append(row.Problems, fmt.Sprintf("settled %d but only %d is due", row.Settled, row.Due))
+	}
+
+	switch {
+	case len(row.Problems) > 0:
+		row.Status = ReconciliationDiscrepancy
+		for _, payment := range payments {
+			row.PaymentIDs = append(row.PaymentIDs, payment.ID)
+		}
+		row.LedgerEntries = entries
+	case row.Settled == row.Due:
+		row.Status = ReconciliationFullySettled
+	default:
+		row.Status = ReconciliationPending
+	}
+	return row
+}
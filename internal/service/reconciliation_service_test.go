@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// seededReconciliationRepository answers reconciliation queries from
+// in-memory bookings, payments and ledger entries, filtering them the way
+// the SQL does.
+type seededReconciliationRepository struct {
+	bookings []*domain.Booking
+	payments []*domain.Payment
+	entries  []*domain.WalletTransaction
+}
+
+var _ repository.ReconciliationRepository = (*seededReconciliationRepository)(nil)
+
+func (r *seededReconciliationRepository) inRange(bookingID *uuid.UUID, restaurantID uuid.UUID, from, to time.Time) bool {
+	if bookingID == nil {
+		return false
+	}
+	for _, booking := range r.bookings {
+		if booking.ID == *bookingID {
+			return booking.RestaurantID == restaurantID && !booking.StartTime.Before(from) && booking.StartTime.Before(to)
+		}
+	}
+	return false
+}
+
+func (r *seededReconciliationRepository) Bookings(_ context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Booking, error) {
+	var bookings []*domain.Booking
+	for _, booking := range r.bookings {
+		if r.inRange(&booking.ID, restaurantID, from, to) {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *seededReconciliationRepository) Payments(_ context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.Payment, error) {
+	var payments []*domain.Payment
+	for _, payment := range r.payments {
+		if r.inRange(payment.BookingID, restaurantID, from, to) {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (r *seededReconciliationRepository) LedgerEntries(_ context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*domain.WalletTransaction, error) {
+	var entries []*domain.WalletTransaction
+	for _, entry := range r.entries {
+		if r.inRange(entry.BookingID, restaurantID, from, to) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *seededReconciliationRepository) booking(restaurantID uuid.UUID, start time.Time, status domain.BookingStatus) uuid.UUID {
+	booking := &domain.Booking{ID: uuid.New(), RestaurantID: restaurantID, StartTime: start, Status: status}
+	r.bookings = append(r.bookings, booking)
+	return booking.ID
+}
+
+func (r *seededReconciliationRepository) payment(bookingID uuid.UUID, method domain.PaymentMethod, status domain.PaymentStatus, amount, fee int) *domain.Payment {
+	payment := &domain.Payment{
+		ID:               uuid.New(),
+		BookingID:        &bookingID,
+		Amount:           amount,
+		ServiceFeeAmount: fee,
+		PaymentMethod:    method,
+		PaymentStatus:    status,
+	}
+	r.payments = append(r.payments, payment)
+	return payment
+}
+
+func (r *seededReconciliationRepository) entry(bookingID uuid.UUID, kind domain.TransactionType, amount int) *domain.WalletTransaction {
+	entry := &domain.WalletTransaction{ID: uuid.New(), BookingID: &bookingID, Type: kind, Amount: amount}
+	r.entries = append(r.entries, entry)
+	return entry
+}
+
+func setupReconciliationService(restaurant *domain.Restaurant, repo *seededReconciliationRepository) ReconciliationService {
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil).Maybe()
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+	return NewReconciliationService(repo, restaurantRepo, authz)
+}
+
+var reconciliationMonth = time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+func reconciliationRow(t *testing.T, report *Reconciliation, bookingID uuid.UUID) *ReconciliationRow {
+	t.Helper()
+	for _, row := range report.Rows {
+		if row.BookingID == bookingID {
+			return row
+		}
+	}
+	t.Fatalf("no row for booking %s", bookingID)
+	return nil
+}
+
+func TestMonthlyReport_FullySettledAndPending(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo := &seededReconciliationRepository{}
+
+	settled := repo.booking(restaurant.ID, reconciliationMonth.Add(36*time.Hour), domain.BookingStatusCompleted)
+	repo.payment(settled, domain.PaymentMethodWallet, domain.PaymentStatusCompleted, 10000, 500)
+	repo.entry(settled, domain.TransactionBookingCharge, 10500)
+	repo.entry(settled, domain.TransactionPaymentToRestaurant, 10000)
+
+	pending := repo.booking(restaurant.ID, reconciliationMonth.AddDate(0, 0, 20), domain.BookingStatusConfirmed)
+	repo.payment(pending, domain.PaymentMethodKaspi, domain.PaymentStatusCompleted, 8000, 400)
+	repo.payment(pending, domain.PaymentMethodKaspi, domain.PaymentStatusFailed, 8000, 400)
+
+	// Bookings outside the month or at another restaurant are left out.
+	repo.booking(restaurant.ID, reconciliationMonth.AddDate(0, 1, 0), domain.BookingStatusCompleted)
+	repo.booking(uuid.New(), reconciliationMonth.Add(time.Hour), domain.BookingStatusCompleted)
+
+	service := setupReconciliationService(restaurant, repo)
+	report, err := service.MonthlyReport(context.Background(), restaurant.ID, restaurant.OwnerID, reconciliationMonth.AddDate(0, 0, 14))
+
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", report.Month)
+	require.Len(t, report.Rows, 2)
+
+	row := reconciliationRow(t, report, settled)
+	assert.Equal(t, 10500, row.Charged)
+	assert.Equal(t, 500, row.Fees)
+	assert.Equal(t, 500, row.Commission)
+	assert.Equal(t, 10000, row.Due)
+	assert.Equal(t, 10000, row.Settled)
+	assert.Equal(t, ReconciliationFullySettled, row.Status)
+	assert.Empty(t, row.LedgerEntries)
+
+	row = reconciliationRow(t, report, pending)
+	assert.Equal(t, 8400, row.Charged, "failed payments are not counted")
+	assert.Equal(t, 8000, row.Due)
+	assert.Equal(t, 0, row.Settled)
+	assert.Equal(t, ReconciliationPending, row.Status)
+
+	assert.Equal(t, ReconciliationTotals{
+		Charged:    18900,
+		Fees:       900,
+		Commission: 900,
+		Due:        18000,
+		Settled:    10000,
+	}, report.Totals)
+}
+
+func TestMonthlyReport_PartialRefund(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo := &seededReconciliationRepository{}
+
+	// Paid out the remainder after a 3000 refund.
+	reconciled := repo.booking(restaurant.ID, reconciliationMonth.Add(time.Hour), domain.BookingStatusCompleted)
+	repo.payment(reconciled, domain.PaymentMethodHalyk, domain.PaymentStatusCompleted, 10000, 500)
+	repo.entry(reconciled, domain.TransactionRefund, 3000)
+	repo.entry(reconciled, domain.TransactionPaymentToRestaurant, 7000)
+
+	// Paid out in full before the refund.
+	overpaid := repo.booking(restaurant.ID, reconciliationMonth.Add(2*time.Hour), domain.BookingStatusCompleted)
+	repo.payment(overpaid, domain.PaymentMethodHalyk, domain.PaymentStatusCompleted, 10000, 500)
+	payout := repo.entry(overpaid, domain.TransactionPaymentToRestaurant, 10000)
+	refund := repo.entry(overpaid, domain.TransactionRefund, 3000)
+
+	service := setupReconciliationService(restaurant, repo)
+	report, err := service.MonthlyReport(context.Background(), restaurant.ID, restaurant.OwnerID, reconciliationMonth)
+
+	require.NoError(t, err)
+
+	row := reconciliationRow(t, report, reconciled)
+	assert.Equal(t, 10500, row.Charged)
+	assert.Equal(t, 3000, row.Refunded)
+	assert.Equal(t, 500, row.Commission)
+	assert.Equal(t, 7000, row.Due)
+	assert.Equal(t, ReconciliationFullySettled, row.Status)
+
+	row = reconciliationRow(t, report, overpaid)
+	assert.Equal(t, 7000, row.Due)
+	assert.Equal(t, 10000, row.Settled)
+	assert.Equal(t, ReconciliationDiscrepancy, row.Status)
+	assert.Equal(t, []string{"settled 10000 but only 7000 is due"}, row.Problems)
+	assert.Equal(t, []*domain.WalletTransaction{payout, refund}, row.LedgerEntries)
+	assert.Equal(t, 1, report.Totals.Discrepancies)
+}
+
+func TestMonthlyReport_RefundAfterSettlement(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo := &seededReconciliationRepository{}
+
+	bookingID := repo.booking(restaurant.ID, reconciliationMonth.AddDate(0, 0, 3), domain.BookingStatusCancelled)
+	payment := repo.payment(bookingID, domain.PaymentMethodWallet, domain.PaymentStatusRefunded, 10000, 500)
+	charge := repo.entry(bookingID, domain.TransactionBookingCharge, 10500)
+	payout := repo.entry(bookingID, domain.TransactionPaymentToRestaurant, 10000)
+	refund := repo.entry(bookingID, domain.TransactionRefund, 10500)
+
+	service := setupReconciliationService(restaurant, repo)
+	report, err := service.MonthlyReport(context.Background(), restaurant.ID, restaurant.OwnerID, reconciliationMonth)
+
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 1)
+
+	row := report.Rows[0]
+	assert.Equal(t, 10500, row.Charged)
+	assert.Equal(t, 10500, row.Refunded)
+	assert.Equal(t, 0, row.Commission, "the fee of a refunded payment is not kept")
+	assert.Equal(t, 0, row.Due)
+	assert.Equal(t, 10000, row.Settled)
+	assert.Equal(t, ReconciliationDiscrepancy, row.Status)
+	assert.Equal(t, []uuid.UUID{payment.ID}, row.PaymentIDs)
+	assert.Equal(t, []*domain.WalletTransaction{charge, payout, refund}, row.LedgerEntries)
+}
+
+func TestMonthlyReport_MissingRefundEntry(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo := &seededReconciliationRepository{}
+
+	bookingID := repo.booking(restaurant.ID, reconciliationMonth, domain.BookingStatusCancelled)
+	repo.payment(bookingID, domain.PaymentMethodKaspi, domain.PaymentStatusRefunded, 6000, 300)
+
+	service := setupReconciliationService(restaurant, repo)
+	report, err := service.MonthlyReport(context.Background(), restaurant.ID, restaurant.OwnerID, reconciliationMonth)
+
+	require.NoError(t, err)
+	row := report.Rows[0]
+	assert.Equal(t, ReconciliationDiscrepancy, row.Status)
+	assert.Equal(t, []string{"refunded payments of 6300 have only 0 in refund entries"}, row.Problems)
+}
+
+func TestMonthlyReport_RequiresManager(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	service := setupReconciliationService(restaurant, &seededReconciliationRepository{})
+
+	_, err := service.MonthlyReport(context.Background(), restaurant.ID, uuid.New(), reconciliationMonth)
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestMonthlyReport_RestaurantNotFound(t *testing.T) {
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantID := uuid.New()
+	restaurantRepo.On("GetByID", mock.Anything, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+	service := NewReconciliationService(&seededReconciliationRepository{}, restaurantRepo, authz)
+
+	_, err := service.MonthlyReport(context.Background(), restaurantID, uuid.New(), reconciliationMonth)
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+}
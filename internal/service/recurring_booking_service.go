@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// RecurringBookingInterval is how often the occurrences that came within
+	// the horizon are booked.
+	RecurringBookingInterval = time.Hour
+	// recurringBatchSize is how many series one query of the job loads.
+	recurringBatchSize = 100
+)
+
+var (
+	ErrRecurringBookingNotFound  = errors.New("recurring booking not found")
+	ErrRecurringBookingCancelled = errors.New("recurring booking is already cancelled")
+	ErrInvalidRecurrenceClock    = errors.New("start_time and end_time must be HH:MM")
+	ErrInvalidRecurrenceWeekday  = errors.New("weekday must be 0 (Sunday) to 6 (Saturday)")
+	ErrRecurrenceEndRequired     = errors.New("exactly one of ends_on and occurrence_count is required")
+	ErrNoUpcomingOccurrence      = errors.New("recurring booking has no upcoming occurrence")
+)
+
+// errOccurrenceExpanded is returned inside the booking transaction when
+// another run saved the occurrence first, to roll back the booking made for
+// it.
+var errOccurrenceExpanded = errors.New("occurrence already expanded")
+
+// CreateRecurringBookingRequest is a weekly booking UserID asks for. Only the
+// date of StartsOn and EndsOn is used, read on the restaurant's clock.
+type CreateRecurringBookingRequest struct {
+	RestaurantID    uuid.UUID
+	TableID         uuid.UUID
+	UserID          uuid.UUID
+	Weekday         time.Weekday
+	StartClock      string
+	EndClock        string
+	GuestsCount     int
+	StartsOn        time.Time
+	EndsOn          *time.Time
+	OccurrenceCount *int
+	SpecialNote     string
+}
+
+// RecurringCancellation is a cancelled series with the bookings cancelled
+// along with it.
+type RecurringCancellation struct {
+	Series   *domain.RecurringBooking
+	Bookings []*domain.Booking
+}
+
+type RecurringBookingService interface {
+	// Create saves the series and books its occurrences within the horizon
+	// straight away. The table, party size and times are checked like a
+	// booking against the first upcoming occurrence. Occurrences that
+	// cannot be booked, such as a week the table is already taken, are
+	// skipped and saved with the reason.
+	Create(ctx context.Context, req CreateRecurringBookingRequest) (*domain.RecurringBooking, error)
+	// List returns the user's series with their occurrences.
+	List(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error)
+	// Cancel cancels the user's series, which stops its expansion, and the
+	// bookings made for it that have not started yet.
+	Cancel(ctx context.Context, id, userID uuid.UUID) (*RecurringCancellation, error)
+	// Start runs Run every RecurringBookingInterval until ctx is cancelled.
+	Start(ctx context.Context)
+	// Run books the occurrences of every active series that start within
+	// the horizon after now. Each occurrence is saved once, so a run that
+	// is repeated or interrupted books nothing twice.
+	Run(ctx context.Context, now time.Time)
+}
+
+type recurringBookingService struct {
+	recurringRepo  repository.RecurringBookingRepository
+	bookingRepo    repository.BookingRepository
+	restaurantRepo repository.RestaurantRepository
+	// bookings checks occurrences like any other booking.
+	bookings *BookingService
+	db       *gorm.DB
+	horizon  time.Duration
+	log      logger.Logger
+	now      func() time.Time
+}
+
+// NewRecurringBookingService books occurrences up to horizonWeeks ahead.
+func NewRecurringBookingService(
+	recurringRepo repository.RecurringBookingRepository,
+	bookingRepo repository.BookingRepository,
+	restaurantRepo repository.RestaurantRepository,
+	bookings *BookingService,
+	db *gorm.DB,
+	horizonWeeks int,
+	log logger.Logger,
+) RecurringBookingService {
+	return &recurringBookingService{
+		recurringRepo:  recurringRepo,
+		bookingRepo:    bookingRepo,
+		restaurantRepo: restaurantRepo,
+		bookings:       bookings,
+		db:             db,
+		horizon:        time.Duration(horizonWeeks) * 7 * 24 * time.Hour,
+		log:            log,
+		now:            time.Now,
+	}
+}
+
+func (s *recurringBookingService) Create(ctx context.Context, req CreateRecurringBookingRequest) (*domain.RecurringBooking, error) {
+	if req.Weekday < time.Sunday || req.Weekday > time.Saturday {
+		return nil, ErrInvalidRecurrenceWeekday
+	}
+	if _, err := time.Parse("15:04", req.StartClock); err != nil {
+		return nil, ErrInvalidRecurrenceClock
+	}
+	if _, err := time.Parse("15:04", req.EndClock); err != nil {
+		return nil, ErrInvalidRecurrenceClock
+	}
+	if (req.EndsOn == nil) == (req.OccurrenceCount == nil) {
+		return nil, ErrRecurrenceEndRequired
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	series := &domain.RecurringBooking{
+		RestaurantID:    restaurant.ID,
+		TableID:         req.TableID,
+		UserID:          req.UserID,
+		Weekday:         req.Weekday,
+		StartClock:      req.StartClock,
+		EndClock:        req.EndClock,
+		GuestsCount:     req.GuestsCount,
+		StartsOn:        dateOf(req.StartsOn),
+		OccurrenceCount: req.OccurrenceCount,
+		SpecialNote:     req.SpecialNote,
+		Status:          domain.RecurringBookingStatusActive,
+	}
+	if req.EndsOn != nil {
+		endsOn := dateOf(*req.EndsOn)
+		series.EndsOn = &endsOn
+	}
+
+	now := s.now()
+	loc := restaurant.Location()
+	for n := 0; ; n++ {
+		start, end, ok := occurrenceAt(series, loc, n)
+		if !ok {
+			return nil, ErrNoUpcomingOccurrence
+		}
+		if !start.After(now) {
+			continue
+		}
+		if _, err := s.bookings.checkSlot(ctx, restaurant, series.TableID, start, end, series.GuestsCount); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if err := s.recurringRepo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+	// The series is saved, so an occurrence that fails here is booked by
+	// the next run of the job.
+	series.Occurrences, err = s.expand(ctx, restaurant, series, now)
+	if err != nil {
+		s.log.Warn("failed to book recurring booking occurrences",
+			zap.String("recurring_booking_id", series.ID.String()),
+			zap.Error(err))
+	}
+	return series, nil
+}
+
+func (s *recurringBookingService) List(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error) {
+	return s.recurringRepo.ListByUser(ctx, userID)
+}
+
+func (s *recurringBookingService) Cancel(ctx context.Context, id, userID uuid.UUID) (*RecurringCancellation, error) {
+	series, err := s.recurringRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecurringBookingNotFound
+		}
+		return nil, err
+	}
+	// Someone else's series is reported as missing rather than forbidden.
+	if series.UserID != userID {
+		return nil, ErrRecurringBookingNotFound
+	}
+	if series.Status != domain.RecurringBookingStatusActive {
+		return nil, ErrRecurringBookingCancelled
+	}
+
+	now := s.now()
+	var bookings []*domain.Booking
+	// Cancelling the series first locks it, so the job cannot book another
+	// occurrence in between.
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+		cancelled, err := recurringRepo.Cancel(ctx, series.ID, now)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return ErrRecurringBookingCancelled
+		}
+		bookings, err = recurringRepo.CancelUpcomingBookings(ctx, series.ID, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series.Status = domain.RecurringBookingStatusCancelled
+	series.CancelledAt = &now
+	return &RecurringCancellation{Series: series, Bookings: bookings}, nil
+}
+
+// Start runs the job in the background until ctx is cancelled.
+func (s *recurringBookingService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(RecurringBookingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.Run(ctx, now)
+			}
+		}
+	}()
+}
+
+func (s *recurringBookingService) Run(ctx context.Context, now time.Time) {
+	var booked, skipped int
+	after := uuid.Nil
+	for {
+		batch, err := s.recurringRepo.ListActive(ctx, after, recurringBatchSize)
+		if err != nil {
+			s.log.Warn("recurring booking job: loading series failed", zap.Error(err))
+			break
+		}
+		for _, series := range batch {
+			restaurant, err := s.restaurantRepo.GetByID(ctx, series.RestaurantID)
+			if err != nil {
+				s.log.Warn("recurring booking job: loading restaurant failed",
+					zap.String("recurring_booking_id", series.ID.String()),
+					zap.Error(err))
+				continue
+			}
+			occurrences, err := s.expand(ctx, restaurant, series, now)
+			if err != nil {
+				s.log.Warn("recurring booking job: booking occurrences failed",
+					zap.String("recurring_booking_id", series.ID.String()),
+					zap.Error(err))
+			}
+			for _, occurrence := range occurrences {
+				if occurrence.Status == domain.RecurringOccurrenceBooked {
+					booked++
+				} else {
+					skipped++
+				}
+			}
+		}
+		if len(batch) < recurringBatchSize {
+			break
+		}
+		after = batch[len(batch)-1].ID
+	}
+	s.log.Info("recurring booking job finished",
+		zap.Int("occurrences_booked", booked),
+		zap.Int("occurrences_skipped", skipped))
+}
+
+// expand saves the occurrences of the series that start after now and
+// within the horizon and have not been saved yet, and returns them. It
+// stops at the first error, keeping the occurrences saved before it.
+func (s *recurringBookingService) expand(ctx context.Context, restaurant *domain.Restaurant, series *domain.RecurringBooking, now time.Time) ([]domain.RecurringOccurrence, error) {
+	starts, err := s.recurringRepo.GetOccurrenceStarts(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[int64]bool, len(starts))
+	for _, start := range starts {
+		saved[start.Unix()] = true
+	}
+
+	var occurrences []domain.RecurringOccurrence
+	until := now.Add(s.horizon)
+	loc := restaurant.Location()
+	for n := 0; ; n++ {
+		start, end, ok := occurrenceAt(series, loc, n)
+		if !ok || start.After(until) {
+			break
+		}
+		if !start.After(now) || saved[start.Unix()] {
+			continue
+		}
+
+		occurrence, err := s.bookOccurrence(ctx, restaurant, series, start, end)
+		if errors.Is(err, errOccurrenceExpanded) {
+			continue
+		}
+		if errors.Is(err, ErrRecurringBookingCancelled) {
+			break
+		}
+		if err != nil {
+			return occurrences, err
+		}
+		if occurrence.Status == domain.RecurringOccurrenceSkipped {
+			s.log.Info("recurring booking occurrence skipped",
+				zap.String("recurring_booking_id", series.ID.String()),
+				zap.Time("start_time", start),
+				zap.String("reason", occurrence.SkipReason))
+		}
+		occurrences = append(occurrences, *occurrence)
+	}
+	return occurrences, nil
+}
+
+// bookOccurrence books the table for one occurrence, or records why it
+// cannot, and saves the occurrence in the same transaction. The booking is
+// confirmed and carries no deposit: the customer agreed to the series, and
+// nobody is there to pay when the job books it.
+func (s *recurringBookingService) bookOccurrence(ctx context.Context, restaurant *domain.Restaurant, series *domain.RecurringBooking, start, end time.Time) (*domain.RecurringOccurrence, error) {
+	occurrence := &domain.RecurringOccurrence{
+		RecurringBookingID: series.ID,
+		StartTime:          start,
+		EndTime:            end,
+		Status:             domain.RecurringOccurrenceBooked,
+	}
+	skip := func(err error) {
+		occurrence.Status = domain.RecurringOccurrenceSkipped
+		occurrence.SkipReason = err.Error()
+	}
+
+	table, err := s.bookings.checkSlot(ctx, restaurant, series.TableID, start, end, series.GuestsCount)
+	switch {
+	case slotRejected(err):
+		skip(err)
+	case err != nil:
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		recurringRepo := s.recurringRepo.WithTx(tx)
+		active, err := recurringRepo.LockActive(ctx, series.ID)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return ErrRecurringBookingCancelled
+		}
+
+		if occurrence.Status == domain.RecurringOccurrenceBooked {
+			local := start.In(restaurant.Location())
+			booking := &domain.Booking{
+				RestaurantID: restaurant.ID,
+				TableID:      table.ID,
+				UserID:       &series.UserID,
+				BookingDate:  time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location()),
+				StartTime:    start,
+				EndTime:      end,
+				GuestsCount:  series.GuestsCount,
+				SpecialNote:  series.SpecialNote,
+				Status:       domain.BookingStatusConfirmed,
+			}
+			policy := TablePolicy(restaurant, table)
+			policy.DepositAmount = 0
+			booking.ApplyPolicy(policy)
+
+			created, err := s.bookingRepo.WithTx(tx).CreateIfAvailable(ctx, booking)
+			switch {
+			case errors.Is(err, ErrGuestLimitReached):
+				skip(err)
+			case err != nil:
+				return err
+			case !created:
+				skip(ErrTableNotAvailable)
+			default:
+				occurrence.BookingID = &booking.ID
+			}
+		}
+
+		inserted, err := recurringRepo.CreateOccurrence(ctx, occurrence)
+		if err != nil {
+			return err
+		}
+		if !inserted {
+			return errOccurrenceExpanded
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return occurrence, nil
+}
+
+// slotRejected reports whether checkSlot turned the occurrence down, as
+// opposed to failing to check it.
+func slotRejected(err error) bool {
+	return errors.Is(err, ErrAfterLastSeating) || errors.Is(err, ErrOutsideWorkingHours) ||
+		errors.Is(err, ErrTableNotFound) || errors.Is(err, ErrGuestsExceedCapacity) ||
+		errors.Is(err, ErrDurationTooShort)
+}
+
+// occurrenceAt returns the times of the series' nth occurrence, counting
+// from 0. They are worked out on loc's clock and returned in UTC. ok is
+// false once the series has ended.
+func occurrenceAt(series *domain.RecurringBooking, loc *time.Location, n int) (start, end time.Time, ok bool) {
+	if series.OccurrenceCount != nil && n >= *series.OccurrenceCount {
+		return time.Time{}, time.Time{}, false
+	}
+	startClock, errStart := time.Parse("15:04", series.StartClock)
+	endClock, errEnd := time.Parse("15:04", series.EndClock)
+	if errStart != nil || errEnd != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	first := time.Date(series.StartsOn.Year(), series.StartsOn.Month(), series.StartsOn.Day(), 0, 0, 0, 0, loc)
+	day := first.AddDate(0, 0, (int(series.Weekday)-int(first.Weekday())+7)%7+7*n)
+	if series.EndsOn != nil && day.After(time.Date(series.EndsOn.Year(), series.EndsOn.Month(), series.EndsOn.Day(), 0, 0, 0, 0, loc)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start = time.Date(day.Year(), day.Month(), day.Day(), startClock.Hour(), startClock.Minute(), 0, 0, loc)
+	end = time.Date(day.Year(), day.Month(), day.Day(), endClock.Hour(), endClock.Minute(), 0, 0, loc)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start.UTC(), end.UTC(), true
+}
+
+// dateOf returns the date of t as midnight UTC, the way a DATE column reads
+// back.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
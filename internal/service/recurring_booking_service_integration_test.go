@@ -0,0 +1,67 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestRecurringBookingJob_ConcurrentRuns expands one series from several
+// runs at once and checks that every occurrence is booked exactly once.
+func TestRecurringBookingJob_ConcurrentRuns(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+
+	table := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "R1", MinCapacity: 1, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	require.NoError(t, db.Create(table).Error)
+
+	count := 3
+	series := &domain.RecurringBooking{
+		RestaurantID:    restaurant.ID,
+		TableID:         table.ID,
+		UserID:          owner.ID,
+		Weekday:         time.Friday,
+		StartClock:      "19:00",
+		EndClock:        "21:00",
+		GuestsCount:     2,
+		StartsOn:        time.Date(2030, time.May, 27, 0, 0, 0, 0, time.UTC),
+		OccurrenceCount: &count,
+		Status:          domain.RecurringBookingStatusActive,
+	}
+	require.NoError(t, db.Create(series).Error)
+	t.Cleanup(func() {
+		db.Where("recurring_booking_id = ?", series.ID).Delete(&domain.RecurringOccurrence{})
+		db.Delete(series)
+	})
+
+	authz := NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop()))
+	bookings := NewBookingService(repository.NewBookingRepository(db), repository.NewTableRepository(db), repository.NewRestaurantRepository(db), repository.NewPaymentRepository(db), authz, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7")
+	service := NewRecurringBookingService(repository.NewRecurringBookingRepository(db), repository.NewBookingRepository(db), repository.NewRestaurantRepository(db), bookings, db, 8, zap.NewNop())
+
+	now := time.Date(2030, time.May, 20, 12, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.Run(ctx, now)
+		}()
+	}
+	wg.Wait()
+
+	var occurrences, booked int64
+	require.NoError(t, db.Model(&domain.RecurringOccurrence{}).Where("recurring_booking_id = ?", series.ID).Count(&occurrences).Error)
+	require.NoError(t, db.Model(&domain.Booking{}).Where("table_id = ?", table.ID).Count(&booked).Error)
+	assert.Equal(t, int64(3), occurrences)
+	assert.Equal(t, int64(3), booked)
+}
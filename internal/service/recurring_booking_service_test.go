@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockRecurringBookingRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecurringBookingRepository) Create(ctx context.Context, series *domain.RecurringBooking) error {
+	args := m.Called(ctx, series)
+	return args.Error(0)
+}
+
+func (m *MockRecurringBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RecurringBooking, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RecurringBooking), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.RecurringBooking, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*domain.RecurringBooking), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) ListActive(ctx context.Context, after uuid.UUID, limit int) ([]*domain.RecurringBooking, error) {
+	args := m.Called(ctx, after, limit)
+	return args.Get(0).([]*domain.RecurringBooking), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) GetOccurrenceStarts(ctx context.Context, id uuid.UUID) ([]time.Time, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) LockActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) CreateOccurrence(ctx context.Context, occurrence *domain.RecurringOccurrence) (bool, error) {
+	args := m.Called(ctx, occurrence)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) Cancel(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) CancelUpcomingBookings(ctx context.Context, id uuid.UUID, now time.Time) ([]*domain.Booking, error) {
+	args := m.Called(ctx, id, now)
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *MockRecurringBookingRepository) WithTx(tx *gorm.DB) repository.RecurringBookingRepository {
+	return m
+}
+
+type recurringMocks struct {
+	recurringRepo  *MockRecurringBookingRepository
+	bookingRepo    *BookingMockBookingRepository
+	tableRepo      *BookingMockTableRepository
+	restaurantRepo *BookingMockRestaurantRepository
+	sqlMock        sqlmock.Sqlmock
+}
+
+// recurringNow is waitlistNow, a Thursday. With a two week horizon the
+// Fridays 31 May and 7 June are booked, 14 June is not yet.
+var (
+	recurringNow    = waitlistNow
+	recurringFriday = time.Date(2030, time.May, 31, 19, 0, 0, 0, time.UTC)
+)
+
+func setupRecurringBookingService() (*recurringBookingService, *recurringMocks) {
+	bookings, bookingRepo, tableRepo, restaurantRepo, _ := setupBookingService()
+	mocks := &recurringMocks{
+		recurringRepo:  new(MockRecurringBookingRepository),
+		bookingRepo:    bookingRepo,
+		tableRepo:      tableRepo,
+		restaurantRepo: restaurantRepo,
+	}
+
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	mocks.sqlMock = sqlMock
+
+	service := NewRecurringBookingService(mocks.recurringRepo, bookingRepo, restaurantRepo, bookings, db, 2, zap.NewNop()).(*recurringBookingService)
+	service.now = func() time.Time { return recurringNow }
+	bookings.now = service.now
+	return service, mocks
+}
+
+func recurringRequest(restaurantID, tableID uuid.UUID) CreateRecurringBookingRequest {
+	count := 4
+	return CreateRecurringBookingRequest{
+		RestaurantID:    restaurantID,
+		TableID:         tableID,
+		UserID:          uuid.New(),
+		Weekday:         time.Friday,
+		StartClock:      "19:00",
+		EndClock:        "21:00",
+		GuestsCount:     4,
+		StartsOn:        time.Date(2030, time.May, 27, 0, 0, 0, 0, time.UTC),
+		OccurrenceCount: &count,
+	}
+}
+
+func activeSeries(restaurantID, tableID uuid.UUID) *domain.RecurringBooking {
+	req := recurringRequest(restaurantID, tableID)
+	return &domain.RecurringBooking{
+		ID:              uuid.New(),
+		RestaurantID:    restaurantID,
+		TableID:         tableID,
+		UserID:          req.UserID,
+		Weekday:         req.Weekday,
+		StartClock:      req.StartClock,
+		EndClock:        req.EndClock,
+		GuestsCount:     req.GuestsCount,
+		StartsOn:        req.StartsOn,
+		OccurrenceCount: req.OccurrenceCount,
+		Status:          domain.RecurringBookingStatusActive,
+	}
+}
+
+func isBookingAt(start time.Time) interface{} {
+	return mock.MatchedBy(func(b *domain.Booking) bool {
+		return b.StartTime.Equal(start) && b.Status == domain.BookingStatusConfirmed && b.CancellationPolicy.DepositAmount == 0
+	})
+}
+
+func TestOccurrenceAt(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	endsOn := time.Date(2030, time.June, 14, 0, 0, 0, 0, time.UTC)
+	series := &domain.RecurringBooking{
+		Weekday:    time.Friday,
+		StartClock: "22:00",
+		EndClock:   "01:00",
+		StartsOn:   time.Date(2030, time.May, 31, 0, 0, 0, 0, time.UTC),
+		EndsOn:     &endsOn,
+	}
+
+	start, end, ok := occurrenceAt(series, loc, 0)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2030, time.May, 31, 17, 0, 0, 0, time.UTC), start)
+	// Ending at 01:00 means the next morning.
+	assert.Equal(t, time.Date(2030, time.May, 31, 20, 0, 0, 0, time.UTC), end)
+
+	start, _, ok = occurrenceAt(series, loc, 2)
+	require.True(t, ok, "ends_on is inclusive")
+	assert.Equal(t, time.Date(2030, time.June, 14, 17, 0, 0, 0, time.UTC), start)
+
+	_, _, ok = occurrenceAt(series, loc, 3)
+	assert.False(t, ok)
+
+	count := 1
+	series.EndsOn, series.OccurrenceCount = nil, &count
+	series.StartsOn = time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	start, _, ok = occurrenceAt(series, loc, 0)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2030, time.June, 7, 17, 0, 0, 0, time.UTC), start, "the first Friday on or after starts_on")
+	_, _, ok = occurrenceAt(series, loc, 1)
+	assert.False(t, ok)
+}
+
+func TestCreateRecurringBooking_BooksWithinHorizon(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	req := recurringRequest(restaurant.ID, table.ID)
+	nextFriday := recurringFriday.AddDate(0, 0, 7)
+
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.recurringRepo.On("Create", ctx, mock.AnythingOfType("*domain.RecurringBooking")).Return(nil)
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, mock.Anything).Return([]time.Time{}, nil)
+	mocks.recurringRepo.On("LockActive", ctx, mock.Anything).Return(true, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, isBookingAt(recurringFriday)).Return(true, nil)
+	// The second Friday the table is taken.
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, isBookingAt(nextFriday)).Return(false, nil)
+	mocks.recurringRepo.On("CreateOccurrence", ctx, mock.AnythingOfType("*domain.RecurringOccurrence")).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	series, err := service.Create(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.RecurringBookingStatusActive, series.Status)
+	require.Len(t, series.Occurrences, 2)
+	assert.Equal(t, recurringFriday, series.Occurrences[0].StartTime)
+	assert.Equal(t, domain.RecurringOccurrenceBooked, series.Occurrences[0].Status)
+	assert.Equal(t, nextFriday, series.Occurrences[1].StartTime)
+	assert.Equal(t, domain.RecurringOccurrenceSkipped, series.Occurrences[1].Status)
+	assert.Equal(t, ErrTableNotAvailable.Error(), series.Occurrences[1].SkipReason)
+	assert.Nil(t, series.Occurrences[1].BookingID)
+	mocks.bookingRepo.AssertNumberOfCalls(t, "CreateIfAvailable", 2)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateRecurringBooking_Rejections(t *testing.T) {
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	endsOn := time.Date(2030, time.May, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		change func(req *CreateRecurringBookingRequest)
+		want   error
+	}{
+		"bad clock":      {func(req *CreateRecurringBookingRequest) { req.StartClock = "7pm" }, ErrInvalidRecurrenceClock},
+		"bad weekday":    {func(req *CreateRecurringBookingRequest) { req.Weekday = 7 }, ErrInvalidRecurrenceWeekday},
+		"two ends":       {func(req *CreateRecurringBookingRequest) { req.EndsOn = &endsOn }, ErrRecurrenceEndRequired},
+		"no end":         {func(req *CreateRecurringBookingRequest) { req.OccurrenceCount = nil }, ErrRecurrenceEndRequired},
+		"already over":   {func(req *CreateRecurringBookingRequest) { req.OccurrenceCount, req.EndsOn = nil, &endsOn }, ErrNoUpcomingOccurrence},
+		"too many":       {func(req *CreateRecurringBookingRequest) { req.GuestsCount = 6 }, ErrGuestsExceedCapacity},
+		"before opening": {func(req *CreateRecurringBookingRequest) { req.StartClock = "09:00" }, ErrOutsideWorkingHours},
+		"unknown table":  {func(req *CreateRecurringBookingRequest) { req.TableID = uuid.New() }, ErrTableNotFound},
+		"last seating":   {func(req *CreateRecurringBookingRequest) { req.StartClock = "22:30" }, ErrAfterLastSeating},
+		"unknown place":  {func(req *CreateRecurringBookingRequest) { req.RestaurantID = uuid.New() }, ErrRestaurantNotFound},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mocks := setupRecurringBookingService()
+			ctx := context.Background()
+			mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil).Maybe()
+			mocks.restaurantRepo.On("GetByID", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+			mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil).Maybe()
+			mocks.tableRepo.On("GetByID", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+			req := recurringRequest(restaurant.ID, table.ID)
+			tc.change(&req)
+
+			_, err := service.Create(ctx, req)
+
+			assert.ErrorIs(t, err, tc.want)
+			mocks.recurringRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRecurringBookingJob_SkipsSavedOccurrences(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	series := activeSeries(restaurant.ID, table.ID)
+	nextFriday := recurringFriday.AddDate(0, 0, 7)
+
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{series}, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	// The first Friday was booked by an earlier run.
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, series.ID).Return([]time.Time{recurringFriday}, nil)
+	mocks.recurringRepo.On("LockActive", ctx, series.ID).Return(true, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, isBookingAt(nextFriday)).Return(true, nil)
+	mocks.recurringRepo.On("CreateOccurrence", ctx, mock.MatchedBy(func(o *domain.RecurringOccurrence) bool {
+		return o.StartTime.Equal(nextFriday) && o.Status == domain.RecurringOccurrenceBooked
+	})).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	service.Run(ctx, recurringNow)
+
+	mocks.bookingRepo.AssertNumberOfCalls(t, "CreateIfAvailable", 1)
+	mocks.recurringRepo.AssertExpectations(t)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingJob_ConcurrentRunRollsBack(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	series := activeSeries(restaurant.ID, table.ID)
+
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{series}, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, series.ID).Return([]time.Time{}, nil)
+	mocks.recurringRepo.On("LockActive", ctx, series.ID).Return(true, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).Return(true, nil)
+	// Another run saved both occurrences after this one loaded them.
+	mocks.recurringRepo.On("CreateOccurrence", ctx, mock.AnythingOfType("*domain.RecurringOccurrence")).Return(false, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+
+	service.Run(ctx, recurringNow)
+
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingJob_SkipReasons(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	series := activeSeries(restaurant.ID, table.ID)
+	nextFriday := recurringFriday.AddDate(0, 0, 7)
+	// The restaurant stopped opening on Fridays after the first one.
+	restaurant.WorkingHours["friday"] = domain.DaySchedule{IsClosed: true}
+	series.StartsOn = nextFriday
+	count := 2
+	series.OccurrenceCount = &count
+	service.horizon = 3 * 7 * 24 * time.Hour
+
+	var saved []*domain.RecurringOccurrence
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{series}, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, series.ID).Return([]time.Time{}, nil)
+	mocks.recurringRepo.On("LockActive", ctx, series.ID).Return(true, nil)
+	mocks.recurringRepo.On("CreateOccurrence", ctx, mock.AnythingOfType("*domain.RecurringOccurrence")).
+		Run(func(args mock.Arguments) { saved = append(saved, args.Get(1).(*domain.RecurringOccurrence)) }).
+		Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	service.Run(ctx, recurringNow)
+
+	require.Len(t, saved, 2)
+	for _, occurrence := range saved {
+		assert.Equal(t, domain.RecurringOccurrenceSkipped, occurrence.Status)
+		assert.Equal(t, ErrOutsideWorkingHours.Error(), occurrence.SkipReason)
+	}
+	mocks.bookingRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything, mock.Anything)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingJob_GuestLimitSkips(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	series := activeSeries(restaurant.ID, table.ID)
+	count := 1
+	series.OccurrenceCount = &count
+	full := fmt.Errorf("%w: 38 of 40 guests already booked for that time", ErrGuestLimitReached)
+
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{series}, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, series.ID).Return([]time.Time{}, nil)
+	mocks.recurringRepo.On("LockActive", ctx, series.ID).Return(true, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).Return(false, full)
+	mocks.recurringRepo.On("CreateOccurrence", ctx, mock.MatchedBy(func(o *domain.RecurringOccurrence) bool {
+		return o.Status == domain.RecurringOccurrenceSkipped && o.SkipReason == full.Error()
+	})).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	service.Run(ctx, recurringNow)
+
+	mocks.recurringRepo.AssertExpectations(t)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingJob_StopsWhenSeriesCancelled(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	series := activeSeries(restaurant.ID, table.ID)
+
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{series}, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.recurringRepo.On("GetOccurrenceStarts", ctx, series.ID).Return([]time.Time{}, nil)
+	// Cancelled after the job loaded it.
+	mocks.recurringRepo.On("LockActive", ctx, series.ID).Return(false, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+
+	service.Run(ctx, recurringNow)
+
+	mocks.bookingRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything, mock.Anything)
+	mocks.recurringRepo.AssertNotCalled(t, "CreateOccurrence", mock.Anything, mock.Anything)
+	mocks.recurringRepo.AssertNumberOfCalls(t, "LockActive", 1)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingJob_LoadFails(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+
+	mocks.recurringRepo.On("ListActive", ctx, uuid.Nil, recurringBatchSize).Return([]*domain.RecurringBooking{}, errors.New("connection refused"))
+
+	service.Run(ctx, recurringNow)
+
+	mocks.recurringRepo.AssertNotCalled(t, "GetOccurrenceStarts", mock.Anything, mock.Anything)
+}
+
+func TestCancelRecurringBooking(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	series := activeSeries(uuid.New(), uuid.New())
+	upcoming := []*domain.Booking{{ID: uuid.New(), StartTime: recurringFriday, Status: domain.BookingStatusCancelled}}
+
+	mocks.recurringRepo.On("GetByID", ctx, series.ID).Return(series, nil)
+	mocks.recurringRepo.On("Cancel", ctx, series.ID, recurringNow).Return(true, nil)
+	mocks.recurringRepo.On("CancelUpcomingBookings", ctx, series.ID, recurringNow).Return(upcoming, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	cancellation, err := service.Cancel(ctx, series.ID, series.UserID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.RecurringBookingStatusCancelled, cancellation.Series.Status)
+	assert.Equal(t, &recurringNow, cancellation.Series.CancelledAt)
+	assert.Equal(t, upcoming, cancellation.Bookings)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestCancelRecurringBooking_Rejections(t *testing.T) {
+	cases := map[string]struct {
+		series func(series *domain.RecurringBooking)
+		userID func(series *domain.RecurringBooking) uuid.UUID
+		want   error
+	}{
+		"someone else's": {func(*domain.RecurringBooking) {}, func(*domain.RecurringBooking) uuid.UUID { return uuid.New() }, ErrRecurringBookingNotFound},
+		"already cancelled": {func(series *domain.RecurringBooking) { series.Status = domain.RecurringBookingStatusCancelled },
+			func(series *domain.RecurringBooking) uuid.UUID { return series.UserID }, ErrRecurringBookingCancelled},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mocks := setupRecurringBookingService()
+			ctx := context.Background()
+			series := activeSeries(uuid.New(), uuid.New())
+			tc.series(series)
+			mocks.recurringRepo.On("GetByID", ctx, series.ID).Return(series, nil)
+
+			_, err := service.Cancel(ctx, series.ID, tc.userID(series))
+
+			assert.ErrorIs(t, err, tc.want)
+			mocks.recurringRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCancelRecurringBooking_NotFound(t *testing.T) {
+	service, mocks := setupRecurringBookingService()
+	ctx := context.Background()
+	id := uuid.New()
+
+	mocks.recurringRepo.On("GetByID", ctx, id).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.Cancel(ctx, id, uuid.New())
+
+	assert.ErrorIs(t, err, ErrRecurringBookingNotFound)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ReminderInterval is how often due booking reminders are sent.
+	ReminderInterval = 5 * time.Minute
+	// ReminderBatchSize is how many bookings one query for due reminders
+	// loads.
+	ReminderBatchSize = 100
+)
+
+// ReminderService reminds customers of their confirmed bookings ahead of
+// time. Each lead gets its own reminder, which is recorded before it is sent
+// so a restart never sends it twice.
+type ReminderService interface {
+	// SendDue sends the reminders due at now and returns how many it sent.
+	SendDue(ctx context.Context, now time.Time) (int, error)
+	// Start sends due reminders every ReminderInterval until ctx is
+	// cancelled.
+	Start(ctx context.Context)
+}
+
+type reminderService struct {
+	reminderRepo    repository.BookingReminderRepository
+	rescheduleSvc   RescheduleService
+	notificationSvc *NotificationService
+	// leads are sorted from the shortest. A booking that starts within a
+	// lead only gets its reminder when it does not start within the next
+	// shorter one, so a late booking is not reminded several times at once.
+	leads     []time.Duration
+	batchSize int
+	log       logger.Logger
+}
+
+func NewReminderService(
+	reminderRepo repository.BookingReminderRepository,
+	rescheduleSvc RescheduleService,
+	notificationSvc *NotificationService,
+	leads []time.Duration,
+	log logger.Logger,
+) ReminderService {
+	return &reminderService{
+		reminderRepo:    reminderRepo,
+		rescheduleSvc:   rescheduleSvc,
+		notificationSvc: notificationSvc,
+		leads:           slices.Sorted(slices.Values(leads)),
+		batchSize:       ReminderBatchSize,
+		log:             log,
+	}
+}
+
+func (s *reminderService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(ReminderInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				sent, err := s.SendDue(ctx, now)
+				if err != nil {
+					s.log.Warn("sending booking reminders failed", zap.Int("sent", sent), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (s *reminderService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	var sent int
+	var shorter time.Duration
+	for _, lead := range s.leads {
+		n, err := s.sendLead(ctx, now, lead, shorter)
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+		shorter = lead
+	}
+	return sent, nil
+}
+
+// sendLead sends the reminders of lead to the bookings that start between
+// shorter and lead from now, batch by batch.
+func (s *reminderService) sendLead(ctx context.Context, now time.Time, lead, shorter time.Duration) (int, error) {
+	leadMinutes := int(lead / time.Minute)
+	var sent int
+	for {
+		bookings, err := s.reminderRepo.GetDue(ctx, leadMinutes, now.Add(shorter), now.Add(lead), s.batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, booking := range bookings {
+			claimed, err := s.reminderRepo.Claim(ctx, booking.ID, leadMinutes, now)
+			if err != nil {
+				return sent, err
+			}
+			if !claimed {
+				continue
+			}
+			s.remind(ctx, booking)
+			sent++
+		}
+
+		if len(bookings) < s.batchSize {
+			return sent, nil
+		}
+	}
+}
+
+// remind emails the customer about the booking, with a reschedule link when
+// it can still be moved. The reminder is already recorded, so a failure here
+// is only logged.
+func (s *reminderService) remind(ctx context.Context, booking *domain.Booking) {
+	if booking.User == nil || booking.Restaurant == nil {
+		s.log.Warn("cannot email booking reminder without the user and restaurant",
+			zap.String("booking_id", booking.ID.String()))
+		return
+	}
+
+	data := ReminderNotificationData{
+		BookingNotificationData: BookingNotificationData{
+			RestaurantName: booking.Restaurant.Name,
+			BookingID:      booking.ID,
+			StartTime:      booking.StartTime,
+			EndTime:        booking.EndTime,
+			GuestCount:     booking.GuestsCount,
+		},
+	}
+	link, err := s.rescheduleSvc.IssueLink(ctx, booking)
+	if err != nil {
+		s.log.Warn("failed to issue reschedule link for booking reminder",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+	if link != nil {
+		data.RescheduleToken = link.Token
+	}
+
+	rendered, err := RenderNotification(TemplateBookingReminder, booking.User.Locale, booking.Restaurant.Location(), data)
+	if err != nil {
+		s.log.Warn("failed to render booking reminder email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.notificationSvc.SendEmail(booking.User.Email, rendered.Subject, rendered.Body); err != nil {
+		s.log.Warn("failed to send booking reminder email",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockBookingReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockBookingReminderRepository) GetDue(ctx context.Context, leadMinutes int, from, to time.Time, limit int) ([]*domain.Booking, error) {
+	args := m.Called(ctx, leadMinutes, from, to, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Booking), args.Error(1)
+}
+
+func (m *MockBookingReminderRepository) Claim(ctx context.Context, bookingID uuid.UUID, leadMinutes int, now time.Time) (bool, error) {
+	args := m.Called(ctx, bookingID, leadMinutes, now)
+	return args.Bool(0), args.Error(1)
+}
+
+// stubRescheduleLinks issues a link for every booking it is asked about,
+// unless token is empty.
+type stubRescheduleLinks struct {
+	RescheduleService
+	token string
+}
+
+func (s *stubRescheduleLinks) IssueLink(ctx context.Context, booking *domain.Booking) (*domain.BookingRescheduleToken, error) {
+	if s.token == "" {
+		return nil, nil
+	}
+	return &domain.BookingRescheduleToken{BookingID: booking.ID, Token: s.token}, nil
+}
+
+func setupReminderService(token string) (*reminderService, *MockBookingReminderRepository, chan Notification) {
+	reminderRepo := new(MockBookingReminderRepository)
+	sent := make(chan Notification, 10)
+	notificationSvc := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+	svc := NewReminderService(reminderRepo, &stubRescheduleLinks{token: token}, notificationSvc,
+		[]time.Duration{24 * time.Hour, 2 * time.Hour}, zap.NewNop()).(*reminderService)
+	return svc, reminderRepo, sent
+}
+
+func confirmedBooking(email string, start time.Time) *domain.Booking {
+	return &domain.Booking{
+		ID:          uuid.New(),
+		Status:      domain.BookingStatusConfirmed,
+		StartTime:   start,
+		EndTime:     start.Add(2 * time.Hour),
+		GuestsCount: 2,
+		Restaurant:  &domain.Restaurant{Name: "Osteria"},
+		User:        &domain.User{Email: email, Locale: domain.LocaleEnglish},
+	}
+}
+
+func TestSendDue_RemindsOncePerLead(t *testing.T) {
+	svc, reminderRepo, sent := setupReminderService("Zm9yLXJlbWluZGVycw")
+	ctx := context.Background()
+	now := time.Date(2024, time.May, 30, 12, 0, 0, 0, time.UTC)
+	soon := confirmedBooking("soon@example.com", now.Add(100*time.Minute))
+	tomorrow := confirmedBooking("tomorrow@example.com", now.Add(23*time.Hour))
+	reminded := confirmedBooking("reminded@example.com", now.Add(22*time.Hour))
+
+	// The shorter lead comes first, and the longer one only looks past it.
+	reminderRepo.On("GetDue", ctx, 120, now, now.Add(2*time.Hour), ReminderBatchSize).Return([]*domain.Booking{soon}, nil).Once()
+	reminderRepo.On("GetDue", ctx, 1440, now.Add(2*time.Hour), now.Add(24*time.Hour), ReminderBatchSize).Return([]*domain.Booking{reminded, tomorrow}, nil).Once()
+	reminderRepo.On("Claim", ctx, soon.ID, 120, now).Return(true, nil)
+	// Sent by a run that overlapped this one.
+	reminderRepo.On("Claim", ctx, reminded.ID, 1440, now).Return(false, nil)
+	reminderRepo.On("Claim", ctx, tomorrow.ID, 1440, now).Return(true, nil)
+
+	count, err := svc.SendDue(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	notifications := receiveNotifications(t, sent, 2)
+	recipients := []string{notifications[0].Recipient, notifications[1].Recipient}
+	assert.ElementsMatch(t, []string{"soon@example.com", "tomorrow@example.com"}, recipients)
+	assert.Equal(t, "Reminder: your booking at Osteria", notifications[0].Subject)
+	assert.Contains(t, notifications[0].Message, "GET /api/reschedule/Zm9yLXJlbWluZGVycw")
+	reminderRepo.AssertExpectations(t)
+}
+
+func TestSendDue_Batches(t *testing.T) {
+	svc, reminderRepo, sent := setupReminderService("")
+	svc.leads = []time.Duration{2 * time.Hour}
+	svc.batchSize = 2
+	ctx := context.Background()
+	now := time.Date(2024, time.May, 30, 12, 0, 0, 0, time.UTC)
+	first, second, third := confirmedBooking("a@example.com", now.Add(time.Hour)),
+		confirmedBooking("b@example.com", now.Add(time.Hour)),
+		confirmedBooking("c@example.com", now.Add(90*time.Minute))
+
+	reminderRepo.On("GetDue", ctx, 120, now, now.Add(2*time.Hour), 2).Return([]*domain.Booking{first, second}, nil).Once()
+	reminderRepo.On("GetDue", ctx, 120, now, now.Add(2*time.Hour), 2).Return([]*domain.Booking{third}, nil).Once()
+	reminderRepo.On("Claim", ctx, mock.Anything, 120, now).Return(true, nil)
+
+	count, err := svc.SendDue(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	for _, notification := range receiveNotifications(t, sent, 3) {
+		assert.NotContains(t, notification.Message, "/api/reschedule/")
+	}
+	reminderRepo.AssertExpectations(t)
+}
+
+func TestSendDue_StopsOnRepositoryError(t *testing.T) {
+	svc, reminderRepo, sent := setupReminderService("")
+	ctx := context.Background()
+	now := time.Date(2024, time.May, 30, 12, 0, 0, 0, time.UTC)
+	booking := confirmedBooking("soon@example.com", now.Add(time.Hour))
+
+	reminderRepo.On("GetDue", ctx, 120, now, now.Add(2*time.Hour), ReminderBatchSize).Return([]*domain.Booking{booking}, nil).Once()
+	reminderRepo.On("Claim", ctx, booking.ID, 120, now).Return(false, errors.New("connection refused"))
+
+	count, err := svc.SendDue(ctx, now)
+
+	assert.Error(t, err)
+	assert.Zero(t, count)
+	reminderRepo.AssertNotCalled(t, "GetDue", ctx, 1440, mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, sent)
+}
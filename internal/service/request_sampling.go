@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RequestSampleRetention is how long request samples are kept before the
+// purge job removes them.
+const RequestSampleRetention = 14 * 24 * time.Hour
+
+// sampledRequestFields are the request body keys a sample may keep. Anything
+// else is dropped, so a field added to a request later is not stored until
+// someone decides it is safe. Free-text fields such as special_note stay out
+// because customers put phone numbers and names in them.
+var sampledRequestFields = map[string]bool{
+	"amount":         true,
+	"booking_date":   true,
+	"booking_id":     true,
+	"end_time":       true,
+	"guests_count":   true,
+	"is_visible":     true,
+	"option":         true,
+	"payment_method": true,
+	"rating":         true,
+	"restaurant_id":  true,
+	"start_time":     true,
+	"status":         true,
+	"table_id":       true,
+	"user_id":        true,
+}
+
+// SanitizeRequestBody returns the allow-listed fields of a JSON object body,
+// applying the same rule to nested objects and arrays of objects. Bodies that
+// are not a JSON object yield nil.
+func SanitizeRequestBody(body []byte) map[string]interface{} {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil
+	}
+	return sanitizeObject(decoded)
+}
+
+func sanitizeObject(object map[string]interface{}) map[string]interface{} {
+	kept := make(map[string]interface{})
+	for key, value := range object {
+		if !sampledRequestFields[key] {
+			continue
+		}
+		kept[key] = sanitizeValue(value)
+	}
+	return kept
+}
+
+func sanitizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return sanitizeObject(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = sanitizeValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+type RequestSampleService interface {
+	// Record stores sample. Sampling must never fail a request, so errors
+	// are only logged.
+	Record(ctx context.Context, sample *domain.RequestSample)
+	// Purge removes samples older than RequestSampleRetention and returns how
+	// many were removed.
+	Purge(ctx context.Context, now time.Time) (int64, error)
+}
+
+type requestSampleService struct {
+	repo repository.RequestSampleRepository
+	log  logger.Logger
+}
+
+func NewRequestSampleService(repo repository.RequestSampleRepository, log logger.Logger) RequestSampleService {
+	return &requestSampleService{repo: repo, log: log}
+}
+
+func (s *requestSampleService) Record(ctx context.Context, sample *domain.RequestSample) {
+	if err := s.repo.Create(ctx, sample); err != nil {
+		s.log.Warn("failed to record request sample",
+			zap.String("route", sample.Route),
+			zap.Int("status", sample.Status),
+			zap.Error(err))
+	}
+}
+
+func (s *requestSampleService) Purge(ctx context.Context, now time.Time) (int64, error) {
+	return s.repo.DeleteBefore(ctx, now.Add(-RequestSampleRetention))
+}
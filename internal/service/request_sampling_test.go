@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockRequestSampleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRequestSampleRepository) Create(ctx context.Context, sample *domain.RequestSample) error {
+	args := m.Called(ctx, sample)
+	return args.Error(0)
+}
+
+func (m *MockRequestSampleRepository) List(ctx context.Context, filter repository.RequestSampleFilter, limit, offset int) ([]*domain.RequestSample, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]*domain.RequestSample), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRequestSampleRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestSanitizeRequestBody_KeepsOnlyAllowedFields(t *testing.T) {
+	body := []byte(`{
+		"restaurant_id": "3f1c6a52-8f55-4c5e-9a0f-7b2d3c4e5f60",
+		"guests_count": 4,
+		"special_note": "call me on +7 701 000 0000",
+		"password": "hunter2",
+		"email": "guest@example.com"
+	}`)
+
+	sanitized := SanitizeRequestBody(body)
+
+	assert.Equal(t, map[string]interface{}{
+		"restaurant_id": "3f1c6a52-8f55-4c5e-9a0f-7b2d3c4e5f60",
+		"guests_count":  float64(4),
+	}, sanitized)
+}
+
+func TestSanitizeRequestBody_SecretsNeverSurvive(t *testing.T) {
+	body := []byte(`{
+		"token": "tok_live_123",
+		"refresh_token": "rt_456",
+		"card_number": "4400430012345678",
+		"cvv": "123",
+		"amount": 5000,
+		"status": {"password": "nested-secret", "status": "confirmed"},
+		"booking_id": [{"access_token": "in-array-secret", "booking_id": "b1"}, "plain"]
+	}`)
+
+	sanitized := SanitizeRequestBody(body)
+	stored, err := json.Marshal(sanitized)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"tok_live_123", "rt_456", "4400430012345678", "123\"", "nested-secret", "in-array-secret", "password", "token", "card_number", "cvv"} {
+		assert.NotContains(t, string(stored), secret)
+	}
+	assert.Equal(t, float64(5000), sanitized["amount"])
+	assert.Equal(t, map[string]interface{}{"status": "confirmed"}, sanitized["status"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"booking_id": "b1"}, "plain"}, sanitized["booking_id"])
+}
+
+func TestSanitizeRequestBody_NonObjectBodies(t *testing.T) {
+	for _, body := range []string{``, `not json`, `["password"]`, `"token"`, `null`} {
+		assert.Empty(t, SanitizeRequestBody([]byte(body)), body)
+	}
+}
+
+func TestRequestSampleService_RecordSwallowsErrors(t *testing.T) {
+	repo := new(MockRequestSampleRepository)
+	svc := NewRequestSampleService(repo, zap.NewNop())
+	sample := &domain.RequestSample{Route: "/api/bookings", Status: 500}
+
+	repo.On("Create", mock.Anything, sample).Return(errors.New("db down"))
+
+	assert.NotPanics(t, func() { svc.Record(context.Background(), sample) })
+	repo.AssertExpectations(t)
+}
+
+func TestRequestSampleService_PurgeUsesRetention(t *testing.T) {
+	repo := new(MockRequestSampleRepository)
+	svc := NewRequestSampleService(repo, zap.NewNop())
+	now := time.Date(2024, 6, 15, 4, 0, 0, 0, time.UTC)
+
+	repo.On("DeleteBefore", mock.Anything, time.Date(2024, 6, 1, 4, 0, 0, 0, time.UTC)).Return(int64(7), nil)
+
+	removed, err := svc.Purge(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), removed)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RescheduleLimit is how often a booking may be moved through reschedule
+// links. Further changes go through the restaurant.
+const RescheduleLimit = 2
+
+const (
+	// rescheduleAlternatives caps the slots a reschedule link offers.
+	rescheduleAlternatives = 10
+	// rescheduleSearchDays is how far ahead of now alternatives are looked
+	// for.
+	rescheduleSearchDays = 14
+)
+
+var (
+	ErrRescheduleLinkNotFound  = errors.New("reschedule link not found")
+	ErrRescheduleLinkExpired   = errors.New("reschedule link has expired")
+	ErrRescheduleLinkUsed      = errors.New("reschedule link has already been used")
+	ErrRescheduleLimitReached  = errors.New("booking cannot be rescheduled online any more")
+	ErrBookingNotReschedulable = errors.New("booking can no longer be rescheduled")
+	ErrInvalidRescheduleTime   = errors.New("new start time must be in the future")
+	ErrRestaurantClosedAt      = errors.New("restaurant is not seating guests at the selected time")
+	ErrRescheduleSlotTaken     = errors.New("no table is available for the selected time")
+)
+
+// RescheduleSlot is a start time the booking can be moved to. The booking
+// keeps its length.
+type RescheduleSlot struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// ReschedulePreview is what a reschedule link shows: the booking and the
+// nearest slots it could move to.
+type ReschedulePreview struct {
+	Booking              *domain.Booking
+	ExpiresAt            time.Time
+	RemainingReschedules int
+	Alternatives         []RescheduleSlot
+}
+
+type RescheduleService interface {
+	// IssueLink creates a reschedule token for the booking, valid until it
+	// starts. It returns a nil token when the booking cannot be moved
+	// through a link any more, so there is nothing to send.
+	IssueLink(ctx context.Context, booking *domain.Booking) (*domain.BookingRescheduleToken, error)
+	// Preview returns the booking behind token and up to
+	// rescheduleAlternatives free slots for it.
+	Preview(ctx context.Context, token string) (*ReschedulePreview, error)
+	// Reschedule moves the booking behind token to newStart, on whichever
+	// fitting table is free, uses up the booking's links and tells the
+	// restaurant.
+	Reschedule(ctx context.Context, token string, newStart time.Time) (*domain.Booking, error)
+}
+
+type rescheduleService struct {
+	tokenRepo       repository.BookingRescheduleTokenRepository
+	bookingRepo     repository.BookingRepository
+	tableRepo       repository.TableRepository
+	blockRepo       repository.TableBlockRepository
+	notificationSvc *NotificationService
+	db              *gorm.DB
+	log             logger.Logger
+	now             func() time.Time
+}
+
+func NewRescheduleService(
+	tokenRepo repository.BookingRescheduleTokenRepository,
+	bookingRepo repository.BookingRepository,
+	tableRepo repository.TableRepository,
+	blockRepo repository.TableBlockRepository,
+	notificationSvc *NotificationService,
+	db *gorm.DB,
+	log logger.Logger,
+) RescheduleService {
+	return &rescheduleService{
+		tokenRepo:       tokenRepo,
+		bookingRepo:     bookingRepo,
+		tableRepo:       tableRepo,
+		blockRepo:       blockRepo,
+		notificationSvc: notificationSvc,
+		db:              db,
+		log:             log,
+		now:             time.Now,
+	}
+}
+
+func (s *rescheduleService) IssueLink(ctx context.Context, booking *domain.Booking) (*domain.BookingRescheduleToken, error) {
+	now := s.now()
+	if !reschedulable(booking) || booking.RescheduleCount >= RescheduleLimit || !booking.StartTime.After(now) {
+		return nil, nil
+	}
+
+	secret, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &domain.BookingRescheduleToken{
+		BookingID: booking.ID,
+		Token:     secret,
+		ExpiresAt: booking.StartTime,
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *rescheduleService) Preview(ctx context.Context, token string) (*ReschedulePreview, error) {
+	link, booking, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives, err := s.alternatives(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReschedulePreview{
+		Booking:              booking,
+		ExpiresAt:            link.ExpiresAt,
+		RemainingReschedules: RescheduleLimit - booking.RescheduleCount,
+		Alternatives:         alternatives,
+	}, nil
+}
+
+func (s *rescheduleService) Reschedule(ctx context.Context, token string, newStart time.Time) (*domain.Booking, error) {
+	link, booking, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !newStart.After(now) {
+		return nil, ErrInvalidRescheduleTime
+	}
+	restaurant := booking.Restaurant
+	if err := ValidateLastSeating(restaurant, newStart); err != nil {
+		return nil, err
+	}
+	if !canSeatAt(restaurant, newStart) {
+		return nil, ErrRestaurantClosedAt
+	}
+	newEnd := newStart.Add(booking.EndTime.Sub(booking.StartTime))
+
+	table, err := s.freeTable(ctx, booking, newStart, newEnd)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, ErrRescheduleSlotTaken
+	}
+
+	previousStart := booking.StartTime
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tokens := s.tokenRepo.WithTx(tx)
+		used, err := tokens.Use(ctx, link.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrRescheduleLinkUsed
+		}
+		if err := tokens.UseAllForBooking(ctx, booking.ID, now); err != nil {
+			return err
+		}
+
+		booking.TableID = table.ID
+		booking.Table = table
+		booking.BookingDate = time.Date(newStart.Year(), newStart.Month(), newStart.Day(), 0, 0, 0, 0, newStart.Location())
+		booking.StartTime = newStart
+		booking.EndTime = newEnd
+		booking.RescheduleCount++
+		return s.bookingRepo.WithTx(tx).Update(ctx, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyRestaurant(booking, previousStart)
+	return booking, nil
+}
+
+// resolve returns the token and its booking when the token can still be
+// used to move it.
+func (s *rescheduleService) resolve(ctx context.Context, token string) (*domain.BookingRescheduleToken, *domain.Booking, error) {
+	link, err := s.tokenRepo.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrRescheduleLinkNotFound
+		}
+		return nil, nil, err
+	}
+	if link.UsedAt != nil {
+		return nil, nil, ErrRescheduleLinkUsed
+	}
+	if !s.now().Before(link.ExpiresAt) {
+		return nil, nil, ErrRescheduleLinkExpired
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, link.BookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrRescheduleLinkNotFound
+		}
+		return nil, nil, err
+	}
+	if booking.Restaurant == nil {
+		return nil, nil, ErrRestaurantNotFound
+	}
+	if !reschedulable(booking) {
+		return nil, nil, ErrBookingNotReschedulable
+	}
+	if booking.RescheduleCount >= RescheduleLimit {
+		return nil, nil, fmt.Errorf("%w, please contact %s at %s",
+			ErrRescheduleLimitReached, booking.Restaurant.Name, booking.Restaurant.Phone)
+	}
+	return link, booking, nil
+}
+
+func reschedulable(booking *domain.Booking) bool {
+	return booking.Status == domain.BookingStatusPending || booking.Status == domain.BookingStatusConfirmed
+}
+
+// alternatives walks the restaurant's slot grid from now, skipping the
+// booking's own start, and returns the first slots with a free table.
+func (s *rescheduleService) alternatives(ctx context.Context, booking *domain.Booking) ([]RescheduleSlot, error) {
+	restaurant := booking.Restaurant
+	step := slotGranularity(RestaurantRules(restaurant))
+	from := s.now().Truncate(step).Add(step)
+	to := from.AddDate(0, 0, rescheduleSearchDays)
+
+	tables, busy, blocks, err := s.candidates(ctx, booking, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := booking.EndTime.Sub(booking.StartTime)
+	alternatives := []RescheduleSlot{}
+	for start := from; start.Before(to) && len(alternatives) < rescheduleAlternatives; start = start.Add(step) {
+		if start.Equal(booking.StartTime) {
+			continue
+		}
+		end := start.Add(duration)
+		if len(unblockedTables(freeTablesBetween(restaurant, tables, busy, start, end), blocks, start, end)) > 0 {
+			alternatives = append(alternatives, RescheduleSlot{StartTime: start, EndTime: end})
+		}
+	}
+	return alternatives, nil
+}
+
+// freeTable returns the table to move the booking to between start and
+// end: its own table when that is free, else the best-fitting free one, or
+// nil.
+func (s *rescheduleService) freeTable(ctx context.Context, booking *domain.Booking, start, end time.Time) (*domain.Table, error) {
+	tables, busy, blocks, err := s.candidates(ctx, booking, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	free := unblockedTables(freeTablesBetween(booking.Restaurant, tables, busy, start, end), blocks, start, end)
+	for _, table := range free {
+		if table.ID == booking.TableID {
+			return table, nil
+		}
+	}
+	return bestFitTable(free), nil
+}
+
+// candidates loads the tables that fit the booking's party, with their
+// bookings other than this one and their blocks, for slots starting between
+// from and to.
+func (s *rescheduleService) candidates(ctx context.Context, booking *domain.Booking, from, to time.Time) ([]*domain.Table, map[uuid.UUID][]*domain.Booking, map[uuid.UUID][]*domain.TableBlock, error) {
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, booking.RestaurantID, booking.GuestsCount)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tables = fittingTables(tables, booking.GuestsCount)
+	if len(tables) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	// The booking may overlap its own new slot, so it does not count.
+	overlapping, err := s.bookingRepo.GetOverlapping(ctx, booking.RestaurantID, from, to.Add(booking.EndTime.Sub(booking.StartTime)))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	others := make([]*domain.Booking, 0, len(overlapping))
+	for _, other := range overlapping {
+		if other.ID != booking.ID {
+			others = append(others, other)
+		}
+	}
+
+	blocks := make(map[uuid.UUID][]*domain.TableBlock, len(tables))
+	for _, table := range tables {
+		tableBlocks, err := s.blockRepo.ListByTable(ctx, table.ID, from)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		blocks[table.ID] = tableBlocks
+	}
+	return tables, bookingsByTable(others), blocks, nil
+}
+
+func unblockedTables(tables []*domain.Table, blocks map[uuid.UUID][]*domain.TableBlock, start, end time.Time) []*domain.Table {
+	var unblocked []*domain.Table
+	for _, table := range tables {
+		if !overlapsBlock(blocks[table.ID], start, end) {
+			unblocked = append(unblocked, table)
+		}
+	}
+	return unblocked
+}
+
+// notifyRestaurant texts the restaurant that the customer moved the
+// booking. The move is already saved, so a failure here is only logged.
+func (s *rescheduleService) notifyRestaurant(booking *domain.Booking, previousStart time.Time) {
+	restaurant := booking.Restaurant
+	rendered, err := RenderNotification(TemplateBookingRescheduled, domain.DefaultLocale, restaurant.Location(), RescheduledNotificationData{
+		BookingNotificationData: BookingNotificationData{
+			RestaurantName: restaurant.Name,
+			BookingID:      booking.ID,
+			StartTime:      booking.StartTime,
+			EndTime:        booking.EndTime,
+			GuestCount:     booking.GuestsCount,
+		},
+		PreviousStartTime: previousStart,
+	})
+	if err != nil {
+		s.log.Warn("failed to render booking reschedule notice",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.notificationSvc.SendSMS(restaurant.Phone, rendered.Body); err != nil {
+		s.log.Warn("failed to send booking reschedule notice",
+			zap.String("booking_id", booking.ID.String()),
+			zap.Error(err))
+	}
+}
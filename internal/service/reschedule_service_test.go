@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockBookingRescheduleTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockBookingRescheduleTokenRepository) Create(ctx context.Context, token *domain.BookingRescheduleToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockBookingRescheduleTokenRepository) GetByToken(ctx context.Context, token string) (*domain.BookingRescheduleToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BookingRescheduleToken), args.Error(1)
+}
+
+func (m *MockBookingRescheduleTokenRepository) Use(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBookingRescheduleTokenRepository) UseAllForBooking(ctx context.Context, bookingID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, bookingID, now)
+	return args.Error(0)
+}
+
+func (m *MockBookingRescheduleTokenRepository) WithTx(tx *gorm.DB) repository.BookingRescheduleTokenRepository {
+	return m
+}
+
+// rescheduleNow is a Saturday noon; the test restaurant seats from 10:00
+// until 22:00.
+var rescheduleNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+type rescheduleMocks struct {
+	tokenRepo   *MockBookingRescheduleTokenRepository
+	bookingRepo *BookingMockBookingRepository
+	tableRepo   *MockTableRepository
+	blockRepo   *MockTableBlockRepository
+	sqlMock     sqlmock.Sqlmock
+	sent        chan Notification
+}
+
+func setupRescheduleService() (*rescheduleService, *rescheduleMocks) {
+	mocks := &rescheduleMocks{
+		tokenRepo:   new(MockBookingRescheduleTokenRepository),
+		bookingRepo: new(BookingMockBookingRepository),
+		tableRepo:   new(MockTableRepository),
+		blockRepo:   new(MockTableBlockRepository),
+		sent:        make(chan Notification, 10),
+	}
+
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	mocks.sqlMock = sqlMock
+
+	notifications := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		mocks.sent <- n
+		return nil
+	})
+
+	svc := NewRescheduleService(mocks.tokenRepo, mocks.bookingRepo, mocks.tableRepo, mocks.blockRepo,
+		notifications, db, zap.NewNop()).(*rescheduleService)
+	svc.now = func() time.Time { return rescheduleNow }
+	return svc, mocks
+}
+
+// reschedulableBooking is a confirmed booking for two, tonight from 19:00
+// to 21:00, with a link to it.
+func reschedulableBooking() (*domain.Booking, *domain.BookingRescheduleToken) {
+	restaurant := availabilityRestaurant()
+	restaurant.Name = "Osteria"
+	restaurant.Phone = "+77010000000"
+	restaurant.Timezone = "UTC"
+	start := time.Date(2024, time.June, 1, 19, 0, 0, 0, time.UTC)
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 1, MaxCapacity: 2}
+	booking := &domain.Booking{
+		ID:           uuid.New(),
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+		GuestsCount:  2,
+		Status:       domain.BookingStatusConfirmed,
+		Restaurant:   restaurant,
+		Table:        table,
+	}
+	token := &domain.BookingRescheduleToken{ID: uuid.New(), BookingID: booking.ID, Token: "secret", ExpiresAt: start}
+	return booking, token
+}
+
+func (m *rescheduleMocks) expectLink(booking *domain.Booking, token *domain.BookingRescheduleToken) {
+	m.tokenRepo.On("GetByToken", mock.Anything, token.Token).Return(token, nil)
+	m.bookingRepo.On("GetByID", mock.Anything, booking.ID).Return(booking, nil)
+}
+
+func TestIssueRescheduleLink(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	booking, _ := reschedulableBooking()
+
+	mocks.tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.BookingRescheduleToken")).Return(nil)
+
+	token, err := svc.IssueLink(context.Background(), booking)
+
+	require.NoError(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, booking.ID, token.BookingID)
+	assert.Equal(t, booking.StartTime, token.ExpiresAt)
+	assert.Len(t, token.Token, 43)
+}
+
+func TestIssueRescheduleLink_NothingToIssue(t *testing.T) {
+	cases := map[string]func(*domain.Booking){
+		"limit reached": func(b *domain.Booking) { b.RescheduleCount = RescheduleLimit },
+		"cancelled":     func(b *domain.Booking) { b.Status = domain.BookingStatusCancelled },
+		"started":       func(b *domain.Booking) { b.StartTime = rescheduleNow },
+	}
+	for name, edit := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, mocks := setupRescheduleService()
+			booking, _ := reschedulableBooking()
+			edit(booking)
+
+			token, err := svc.IssueLink(context.Background(), booking)
+
+			require.NoError(t, err)
+			assert.Nil(t, token)
+			mocks.tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPreviewReschedule_ListsNextFreeSlots(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	ctx := context.Background()
+	booking, token := reschedulableBooking()
+	booking.RescheduleCount = 1
+	day := booking.StartTime.Truncate(24 * time.Hour)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	mocks.expectLink(booking, token)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, booking.RestaurantID, 2).Return([]*domain.Table{booking.Table}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, booking.RestaurantID, at(12, 30), mock.Anything).Return([]*domain.Booking{
+		booking,
+		{ID: uuid.New(), TableID: booking.TableID, StartTime: at(12, 30), EndTime: at(14, 0)},
+	}, nil)
+	mocks.blockRepo.On("ListByTable", ctx, booking.TableID, at(12, 30)).Return([]*domain.TableBlock{
+		{TableID: booking.TableID, StartsAt: at(16, 0), EndsAt: at(17, 0)},
+	}, nil)
+
+	preview, err := svc.Preview(ctx, token.Token)
+
+	require.NoError(t, err)
+	assert.Same(t, booking, preview.Booking)
+	assert.Equal(t, 1, preview.RemainingReschedules)
+	assert.Equal(t, token.ExpiresAt, preview.ExpiresAt)
+
+	// The booking's own slot is neither offered nor in the way.
+	want := []time.Time{at(14, 0), at(17, 0), at(17, 30), at(18, 0), at(18, 30), at(19, 30), at(20, 0), at(20, 30), at(21, 0), at(21, 30)}
+	require.Len(t, preview.Alternatives, len(want))
+	for i, start := range want {
+		assert.Equal(t, start, preview.Alternatives[i].StartTime, i)
+		assert.Equal(t, start.Add(2*time.Hour), preview.Alternatives[i].EndTime, i)
+	}
+}
+
+func TestReschedule_MovesToFreeTable(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	ctx := context.Background()
+	booking, token := reschedulableBooking()
+	newStart := time.Date(2024, time.June, 2, 19, 30, 0, 0, time.UTC)
+	larger := &domain.Table{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 6}
+
+	mocks.expectLink(booking, token)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, booking.RestaurantID, 2).Return([]*domain.Table{booking.Table, larger}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, booking.RestaurantID, newStart, mock.Anything).Return([]*domain.Booking{
+		{ID: uuid.New(), TableID: booking.TableID, StartTime: newStart.Add(-time.Hour), EndTime: newStart.Add(time.Hour)},
+	}, nil)
+	mocks.blockRepo.On("ListByTable", ctx, mock.Anything, newStart).Return([]*domain.TableBlock{}, nil)
+	mocks.tokenRepo.On("Use", ctx, token.ID, rescheduleNow).Return(true, nil)
+	mocks.tokenRepo.On("UseAllForBooking", ctx, booking.ID, rescheduleNow).Return(nil)
+	mocks.bookingRepo.On("Update", ctx, booking).Return(nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	moved, err := svc.Reschedule(ctx, token.Token, newStart)
+
+	require.NoError(t, err)
+	assert.Equal(t, larger.ID, moved.TableID)
+	assert.Same(t, larger, moved.Table)
+	assert.Equal(t, newStart, moved.StartTime)
+	assert.Equal(t, newStart.Add(2*time.Hour), moved.EndTime)
+	assert.Equal(t, time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC), moved.BookingDate)
+	assert.Equal(t, 1, moved.RescheduleCount)
+	mocks.tokenRepo.AssertExpectations(t)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+
+	sms := receiveNotifications(t, mocks.sent, 1)[0]
+	assert.Equal(t, NotificationSMS, sms.Type)
+	assert.Equal(t, "+77010000000", sms.Recipient)
+	assert.Contains(t, sms.Message, booking.ID.String())
+	assert.Contains(t, sms.Message, "from 1 June 2024, 19:00 to Sunday, 2 June 2024, 19:30")
+}
+
+func TestReschedule_KeepsOwnTableWhenFree(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	ctx := context.Background()
+	booking, token := reschedulableBooking()
+	// Overlaps the booking's current slot, which it frees.
+	newStart := booking.StartTime.Add(time.Hour)
+	larger := &domain.Table{ID: uuid.New(), MinCapacity: 1, MaxCapacity: 1}
+
+	mocks.expectLink(booking, token)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, booking.RestaurantID, 2).Return([]*domain.Table{larger, booking.Table}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, booking.RestaurantID, newStart, mock.Anything).Return([]*domain.Booking{booking}, nil)
+	mocks.blockRepo.On("ListByTable", ctx, mock.Anything, newStart).Return([]*domain.TableBlock{}, nil)
+	mocks.tokenRepo.On("Use", ctx, token.ID, rescheduleNow).Return(true, nil)
+	mocks.tokenRepo.On("UseAllForBooking", ctx, booking.ID, rescheduleNow).Return(nil)
+	mocks.bookingRepo.On("Update", ctx, booking).Return(nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	moved, err := svc.Reschedule(ctx, token.Token, newStart)
+
+	require.NoError(t, err)
+	assert.Equal(t, booking.Table.ID, moved.TableID)
+	assert.Equal(t, newStart, moved.StartTime)
+}
+
+func TestReschedule_LinkUsedConcurrently(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	ctx := context.Background()
+	booking, token := reschedulableBooking()
+	newStart := booking.StartTime.Add(time.Hour)
+
+	mocks.expectLink(booking, token)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, booking.RestaurantID, 2).Return([]*domain.Table{booking.Table}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, booking.RestaurantID, newStart, mock.Anything).Return([]*domain.Booking{}, nil)
+	mocks.blockRepo.On("ListByTable", ctx, booking.TableID, newStart).Return([]*domain.TableBlock{}, nil)
+	mocks.tokenRepo.On("Use", ctx, token.ID, rescheduleNow).Return(false, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+
+	_, err := svc.Reschedule(ctx, token.Token, newStart)
+
+	assert.ErrorIs(t, err, ErrRescheduleLinkUsed)
+	mocks.bookingRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.Equal(t, 0, booking.RescheduleCount)
+}
+
+func TestReschedule_Rejected(t *testing.T) {
+	tomorrow := func(hour, minute int) time.Time {
+		return time.Date(2024, time.June, 2, hour, minute, 0, 0, time.UTC)
+	}
+	now := rescheduleNow
+
+	cases := map[string]struct {
+		edit     func(*domain.Booking, *domain.BookingRescheduleToken)
+		newStart time.Time
+		want     error
+	}{
+		"used link":         {func(_ *domain.Booking, l *domain.BookingRescheduleToken) { l.UsedAt = &now }, tomorrow(19, 0), ErrRescheduleLinkUsed},
+		"expired link":      {func(_ *domain.Booking, l *domain.BookingRescheduleToken) { l.ExpiresAt = now }, tomorrow(19, 0), ErrRescheduleLinkExpired},
+		"cancelled booking": {func(b *domain.Booking, _ *domain.BookingRescheduleToken) { b.Status = domain.BookingStatusCancelled }, tomorrow(19, 0), ErrBookingNotReschedulable},
+		"limit reached":     {func(b *domain.Booking, _ *domain.BookingRescheduleToken) { b.RescheduleCount = RescheduleLimit }, tomorrow(19, 0), ErrRescheduleLimitReached},
+		"in the past":       {nil, now.Add(-time.Hour), ErrInvalidRescheduleTime},
+		"after last seat":   {nil, tomorrow(22, 30), ErrAfterLastSeating},
+		"before opening":    {nil, tomorrow(9, 0), ErrRestaurantClosedAt},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, mocks := setupRescheduleService()
+			booking, token := reschedulableBooking()
+			if tc.edit != nil {
+				tc.edit(booking, token)
+			}
+			mocks.expectLink(booking, token)
+
+			_, err := svc.Reschedule(context.Background(), token.Token, tc.newStart)
+
+			assert.ErrorIs(t, err, tc.want)
+			mocks.tokenRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReschedule_LimitReachedNamesRestaurant(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	booking, token := reschedulableBooking()
+	booking.RescheduleCount = RescheduleLimit
+	mocks.expectLink(booking, token)
+
+	_, err := svc.Preview(context.Background(), token.Token)
+
+	require.ErrorIs(t, err, ErrRescheduleLimitReached)
+	assert.Contains(t, err.Error(), "please contact Osteria at +77010000000")
+}
+
+func TestReschedule_UnknownLink(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	mocks.tokenRepo.On("GetByToken", mock.Anything, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Reschedule(context.Background(), "missing", rescheduleNow.Add(24*time.Hour))
+
+	assert.ErrorIs(t, err, ErrRescheduleLinkNotFound)
+}
+
+func TestReschedule_SlotTaken(t *testing.T) {
+	svc, mocks := setupRescheduleService()
+	ctx := context.Background()
+	booking, token := reschedulableBooking()
+	newStart := time.Date(2024, time.June, 2, 19, 0, 0, 0, time.UTC)
+
+	mocks.expectLink(booking, token)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, booking.RestaurantID, 2).Return([]*domain.Table{booking.Table}, nil)
+	mocks.bookingRepo.On("GetOverlapping", ctx, booking.RestaurantID, newStart, mock.Anything).Return([]*domain.Booking{}, nil)
+	mocks.blockRepo.On("ListByTable", ctx, booking.TableID, newStart).Return([]*domain.TableBlock{
+		{TableID: booking.TableID, StartsAt: newStart.Add(time.Hour), EndsAt: newStart.Add(3 * time.Hour)},
+	}, nil)
+
+	_, err := svc.Reschedule(ctx, token.Token, newStart)
+
+	assert.ErrorIs(t, err, ErrRescheduleSlotTaken)
+	mocks.tokenRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRestaurantStatus = errors.New("status must be one of pending, approved, rejected")
+	ErrRejectionReasonRequired = errors.New("a reason is required to reject a restaurant")
+	// ErrRestaurantStatusUnchanged is returned when the restaurant already
+	// has the status a decision would give it.
+	ErrRestaurantStatusUnchanged = errors.New("restaurant already has this status")
+	// ErrRestaurantStatusChanged is returned when another admin decided on
+	// the restaurant at the same time.
+	ErrRestaurantStatusChanged = errors.New("restaurant status changed in the meantime")
+)
+
+// RestaurantApprovalService lets admins decide which restaurants appear in
+// public listings. New restaurants start out pending and stay hidden until
+// one is approved.
+type RestaurantApprovalService interface {
+	// ListRestaurants returns a page of the restaurants in status, oldest
+	// first, and how many there are in all.
+	ListRestaurants(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, int64, error)
+	Approve(ctx context.Context, restaurantID, adminID uuid.UUID) (*domain.Restaurant, error)
+	// Reject takes the restaurant out of public listings, or keeps it out,
+	// and tells the owner why.
+	Reject(ctx context.Context, restaurantID, adminID uuid.UUID, reason string) (*domain.Restaurant, error)
+}
+
+type restaurantApprovalService struct {
+	restaurantRepo  repository.RestaurantRepository
+	userRepo        repository.UserRepository
+	notificationSvc *NotificationService
+	audit           AuditRecorder
+	log             logger.Logger
+	now             func() time.Time
+}
+
+func NewRestaurantApprovalService(
+	restaurantRepo repository.RestaurantRepository,
+	userRepo repository.UserRepository,
+	notificationSvc *NotificationService,
+	audit AuditRecorder,
+	log logger.Logger,
+) RestaurantApprovalService {
+	return &restaurantApprovalService{
+		restaurantRepo:  restaurantRepo,
+		userRepo:        userRepo,
+		notificationSvc: notificationSvc,
+		audit:           audit,
+		log:             log,
+		now:             time.Now,
+	}
+}
+
+func (s *restaurantApprovalService) ListRestaurants(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, int64, error) {
+	if !slices.Contains(domain.RestaurantStatuses, status) {
+		return nil, 0, ErrInvalidRestaurantStatus
+	}
+
+	restaurants, err := s.restaurantRepo.ListByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.restaurantRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+	return restaurants, total, nil
+}
+
+func (s *restaurantApprovalService) Approve(ctx context.Context, restaurantID, adminID uuid.UUID) (*domain.Restaurant, error) {
+	return s.decide(ctx, restaurantID, adminID, domain.RestaurantStatusApproved, nil)
+}
+
+func (s *restaurantApprovalService) Reject(ctx context.Context, restaurantID, adminID uuid.UUID, reason string) (*domain.Restaurant, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+	return s.decide(ctx, restaurantID, adminID, domain.RestaurantStatusRejected, &reason)
+}
+
+func (s *restaurantApprovalService) decide(ctx context.Context, restaurantID, adminID uuid.UUID, status domain.RestaurantStatus, reason *string) (*domain.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if restaurant.Status == status {
+		return nil, ErrRestaurantStatusUnchanged
+	}
+
+	previous := restaurant.Status
+	now := s.now()
+	restaurant.Status = status
+	restaurant.RejectionReason = reason
+	restaurant.ReviewedBy = &adminID
+	restaurant.ReviewedAt = &now
+
+	if err := s.restaurantRepo.SetStatus(ctx, restaurant, previous); err != nil {
+		if errors.Is(err, repository.ErrRestaurantStatusChanged) {
+			return nil, ErrRestaurantStatusChanged
+		}
+		return nil, err
+	}
+
+	action := AuditActionRestaurantApprove
+	metadata := map[string]interface{}{"previous_status": string(previous)}
+	if reason != nil {
+		action = AuditActionRestaurantReject
+		metadata["reason"] = *reason
+	}
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: "restaurant",
+		TargetID:   restaurant.ID,
+		Metadata:   metadata,
+	})
+
+	s.notifyOwner(restaurant)
+	return restaurant, nil
+}
+
+// notifyOwner tells the owner about the decision. The decision is already
+// saved, so a failure here is only logged.
+func (s *restaurantApprovalService) notifyOwner(restaurant *domain.Restaurant) {
+	owner, err := s.userRepo.GetByID(restaurant.OwnerID)
+	if err != nil {
+		s.log.Warn("failed to load owner for restaurant decision",
+			zap.String("restaurant_id", restaurant.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	subject := fmt.Sprintf("%s has been approved", restaurant.Name)
+	message := fmt.Sprintf("%s is now listed and guests can find it.", restaurant.Name)
+	if restaurant.Status == domain.RestaurantStatusRejected {
+		subject = fmt.Sprintf("%s has not been approved", restaurant.Name)
+		message = fmt.Sprintf("%s will not be listed. Reason: %s", restaurant.Name, *restaurant.RejectionReason)
+	}
+
+	if err := s.notificationSvc.Send(Notification{
+		ID:        uuid.New(),
+		Type:      NotificationPush,
+		Recipient: owner.ID.String(),
+		Subject:   subject,
+		Message:   message,
+		CreatedAt: s.now(),
+	}); err != nil {
+		s.log.Warn("failed to send restaurant decision notification",
+			zap.String("restaurant_id", restaurant.ID.String()),
+			zap.Error(err))
+	}
+	if err := s.notificationSvc.SendEmail(owner.Email, subject, message); err != nil {
+		s.log.Warn("failed to send restaurant decision email",
+			zap.String("restaurant_id", restaurant.ID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var approvalNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// setupApprovalService returns an approval service whose notifications are
+// delivered to the returned channel.
+func setupApprovalService() (*restaurantApprovalService, *MockRestaurantRepository, *MockUserRepository, chan Notification) {
+	restaurantRepo := new(MockRestaurantRepository)
+	userRepo := new(MockUserRepository)
+	sent := make(chan Notification, 10)
+	notifications := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		sent <- n
+		return nil
+	})
+
+	svc := NewRestaurantApprovalService(restaurantRepo, userRepo, notifications, NewLogAuditRecorder(zap.NewNop()), zap.NewNop()).(*restaurantApprovalService)
+	svc.now = func() time.Time { return approvalNow }
+	return svc, restaurantRepo, userRepo, sent
+}
+
+func pendingRestaurant() (*domain.Restaurant, *domain.User) {
+	owner := &domain.User{ID: uuid.New(), Email: "owner@example.com", Role: domain.UserRoleOwner}
+	return &domain.Restaurant{ID: uuid.New(), OwnerID: owner.ID, Name: "Navat", Status: domain.RestaurantStatusPending}, owner
+}
+
+func TestApproveRestaurant(t *testing.T) {
+	svc, restaurantRepo, userRepo, sent := setupApprovalService()
+	ctx := context.Background()
+	adminID := uuid.New()
+	restaurant, owner := pendingRestaurant()
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	restaurantRepo.On("SetStatus", ctx, mock.MatchedBy(func(r *domain.Restaurant) bool {
+		return r.Status == domain.RestaurantStatusApproved && *r.ReviewedBy == adminID && r.ReviewedAt.Equal(approvalNow)
+	}), domain.RestaurantStatusPending).Return(nil)
+	userRepo.On("GetByID", owner.ID).Return(owner, nil)
+
+	approved, err := svc.Approve(ctx, restaurant.ID, adminID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.RestaurantStatusApproved, approved.Status)
+	assert.Nil(t, approved.RejectionReason)
+	notifications := receiveNotifications(t, sent, 2)
+	recipients := []string{notifications[0].Recipient, notifications[1].Recipient}
+	assert.ElementsMatch(t, []string{owner.ID.String(), owner.Email}, recipients)
+	assert.Contains(t, notifications[0].Subject, "Navat has been approved")
+	restaurantRepo.AssertExpectations(t)
+}
+
+func TestRejectRestaurant_SendsReason(t *testing.T) {
+	svc, restaurantRepo, userRepo, sent := setupApprovalService()
+	ctx := context.Background()
+	restaurant, owner := pendingRestaurant()
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	restaurantRepo.On("SetStatus", ctx, restaurant, domain.RestaurantStatusPending).Return(nil)
+	userRepo.On("GetByID", owner.ID).Return(owner, nil)
+
+	rejected, err := svc.Reject(ctx, restaurant.ID, uuid.New(), "  Address could not be verified ")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.RestaurantStatusRejected, rejected.Status)
+	require.NotNil(t, rejected.RejectionReason)
+	assert.Equal(t, "Address could not be verified", *rejected.RejectionReason)
+	for _, notification := range receiveNotifications(t, sent, 2) {
+		assert.Contains(t, notification.Message, "Reason: Address could not be verified")
+	}
+}
+
+func TestRejectRestaurant_RequiresReason(t *testing.T) {
+	svc, restaurantRepo, _, _ := setupApprovalService()
+
+	_, err := svc.Reject(context.Background(), uuid.New(), uuid.New(), " ")
+
+	assert.ErrorIs(t, err, ErrRejectionReasonRequired)
+	restaurantRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestApproveRestaurant_AlreadyApproved(t *testing.T) {
+	svc, restaurantRepo, _, _ := setupApprovalService()
+	restaurant, _ := pendingRestaurant()
+	restaurant.Status = domain.RestaurantStatusApproved
+
+	restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+
+	_, err := svc.Approve(context.Background(), restaurant.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrRestaurantStatusUnchanged)
+	restaurantRepo.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApproveRestaurant_DecidedConcurrently(t *testing.T) {
+	svc, restaurantRepo, userRepo, _ := setupApprovalService()
+	restaurant, _ := pendingRestaurant()
+
+	restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+	restaurantRepo.On("SetStatus", mock.Anything, restaurant, domain.RestaurantStatusPending).Return(repository.ErrRestaurantStatusChanged)
+
+	_, err := svc.Approve(context.Background(), restaurant.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrRestaurantStatusChanged)
+	userRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestApproveRestaurant_NotFound(t *testing.T) {
+	svc, restaurantRepo, _, _ := setupApprovalService()
+	id := uuid.New()
+
+	restaurantRepo.On("GetByID", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Approve(context.Background(), id, uuid.New())
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+}
+
+func TestListRestaurantsByStatus(t *testing.T) {
+	svc, restaurantRepo, _, _ := setupApprovalService()
+	ctx := context.Background()
+	restaurant, _ := pendingRestaurant()
+
+	restaurantRepo.On("ListByStatus", ctx, domain.RestaurantStatusPending, 20, 0).Return([]*domain.Restaurant{restaurant}, nil)
+	restaurantRepo.On("CountByStatus", ctx, domain.RestaurantStatusPending).Return(int64(3), nil)
+
+	restaurants, total, err := svc.ListRestaurants(ctx, domain.RestaurantStatusPending, 20, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Restaurant{restaurant}, restaurants)
+	assert.Equal(t, int64(3), total)
+
+	_, _, err = svc.ListRestaurants(ctx, domain.RestaurantStatus("archived"), 20, 0)
+	assert.ErrorIs(t, err, ErrInvalidRestaurantStatus)
+}
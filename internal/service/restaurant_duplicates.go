@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateRestaurant is wrapped by DuplicateRestaurantError.
+var ErrDuplicateRestaurant = errors.New("restaurant looks like one that already exists")
+
+// DuplicateMatch is what a new restaurant has in common with an existing one.
+type DuplicateMatch string
+
+const (
+	// DuplicateMatchAddress is a restaurant of any owner at the same
+	// normalized address.
+	DuplicateMatchAddress DuplicateMatch = "address"
+	// DuplicateMatchName is a restaurant of the same owner with a similar
+	// name.
+	DuplicateMatchName DuplicateMatch = "name"
+)
+
+// DuplicateRestaurantError is returned by CreateRestaurant when the new
+// restaurant looks like RestaurantID. Creating it anyway takes Force.
+type DuplicateRestaurantError struct {
+	RestaurantID uuid.UUID
+	Match        DuplicateMatch
+}
+
+func (e *DuplicateRestaurantError) Error() string {
+	return fmt.Sprintf("%s: restaurant %s has the same %s", ErrDuplicateRestaurant, e.RestaurantID, e.Match)
+}
+
+func (e *DuplicateRestaurantError) Unwrap() error {
+	return ErrDuplicateRestaurant
+}
+
+// maxNameDistanceRatio is how many edits per character of the longer name
+// two names of the same owner may differ by and still count as similar.
+const maxNameDistanceRatio = 0.2
+
+// findDuplicate looks for a restaurant that a new one named name at address,
+// owned by ownerID, would duplicate. It returns nil when there is none.
+func (s *restaurantService) findDuplicate(ctx context.Context, ownerID uuid.UUID, name, address string) (*DuplicateRestaurantError, error) {
+	if key := normalizeAddress(address); key != "" {
+		existing, err := s.restaurantRepo.GetByAddressKey(ctx, key)
+		switch {
+		case err == nil:
+			return &DuplicateRestaurantError{RestaurantID: existing.ID, Match: DuplicateMatchAddress}, nil
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	owned, err := s.restaurantRepo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, restaurant := range owned {
+		if similarNames(name, restaurant.Name) {
+			return &DuplicateRestaurantError{RestaurantID: restaurant.ID, Match: DuplicateMatchName}, nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeAddress lowercases s, reads ё as е and turns every run of
+// punctuation, symbols and spaces into a single space, so that
+// "ул. Абая, 10" and "УЛ АБАЯ 10" compare equal. The address_key backfill
+// in the migrations approximates it in SQL.
+func normalizeAddress(s string) string {
+	var b strings.Builder
+	gap := false
+	for _, r := range strings.ToLower(s) {
+		if r == 'ё' {
+			r = 'е'
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			gap = true
+			continue
+		}
+		if gap && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		gap = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// similarNames reports whether two restaurant names, once normalized like
+// addresses, are equal or differ by at most maxNameDistanceRatio edits per
+// character.
+func similarNames(a, b string) bool {
+	x, y := []rune(normalizeAddress(a)), []rune(normalizeAddress(b))
+	if len(x) == 0 || len(y) == 0 {
+		return false
+	}
+	longer := max(len(x), len(y))
+	return float64(editDistance(x, y)) <= maxNameDistanceRatio*float64(longer)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// setAddress stores address and the key duplicates are looked up by.
+func setAddress(restaurant *domain.Restaurant, address string) {
+	restaurant.Address = address
+	restaurant.AddressKey = normalizeAddress(address)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ул. Абая, 10", "ул абая 10"},
+		{"  УЛ АБАЯ 10 ", "ул абая 10"},
+		{"Алматы, пр. Достык 5/1 (Esentai)", "алматы пр достык 5 1 esentai"},
+		{"Жолдасбеков 9А", "жолдасбеков 9а"},
+		{"ул. Тёплая, 3", "ул теплая 3"},
+		{"--- , .", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, normalizeAddress(tt.in), tt.in)
+	}
+}
+
+func TestSimilarNames(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Del Papa", "del papa", true},
+		{"Del Papa", "Del Pappa", true},
+		{"Кафе Ёлка", "кафе елка", true},
+		{"Navat", "Nawat", true},
+		{"Navat", "Nomad", false},
+		{"Del Papa", "Del Papa Express", false},
+		{"Del Papa", "", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, similarNames(tt.a, tt.b), "%q vs %q", tt.a, tt.b)
+	}
+}
+
+func TestCreateRestaurant_DuplicateAddress(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+	existing := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Name: "Navat"}
+
+	repo.On("GetByAddressKey", ctx, "ул абая 10").Return(existing, nil)
+
+	_, err := service.CreateRestaurant(ctx, uuid.New(), CreateRestaurantRequest{
+		Name:         "Чайхана",
+		Address:      "УЛ. АБАЯ, 10",
+		WorkingHours: weekOfHours("10:00", "22:00"),
+	})
+
+	var duplicate *DuplicateRestaurantError
+	require.True(t, errors.As(err, &duplicate))
+	assert.ErrorIs(t, err, ErrDuplicateRestaurant)
+	assert.Equal(t, existing.ID, duplicate.RestaurantID)
+	assert.Equal(t, DuplicateMatchAddress, duplicate.Match)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateRestaurant_DuplicateOwnerName(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	existing := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Del Papa"}
+
+	repo.On("GetByAddressKey", ctx, "достык 5").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("GetByOwnerID", ctx, ownerID).Return([]*domain.Restaurant{
+		{ID: uuid.New(), OwnerID: ownerID, Name: "Navat"},
+		existing,
+	}, nil)
+
+	_, err := service.CreateRestaurant(ctx, ownerID, CreateRestaurantRequest{
+		Name:         "Del Pappa",
+		Address:      "Достык 5",
+		WorkingHours: weekOfHours("10:00", "22:00"),
+	})
+
+	var duplicate *DuplicateRestaurantError
+	require.True(t, errors.As(err, &duplicate))
+	assert.Equal(t, existing.ID, duplicate.RestaurantID)
+	assert.Equal(t, DuplicateMatchName, duplicate.Match)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateRestaurant_ForceSkipsDuplicateCheck(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	repo.On("ListSlugs", ctx, "del-papa", uuid.Nil).Return([]string{}, nil)
+	dbMock.ExpectBegin()
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.Restaurant")).Return(nil)
+	versionRepo.On("Append", ctx, mock.Anything, maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
+
+	restaurant, err := service.CreateRestaurant(ctx, uuid.New(), CreateRestaurantRequest{
+		Name:         "Del Papa",
+		Address:      "Достык 5",
+		WorkingHours: weekOfHours("10:00", "22:00"),
+		Force:        true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "достык 5", restaurant.AddressKey)
+	repo.AssertNotCalled(t, "GetByAddressKey", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "GetByOwnerID", mock.Anything, mock.Anything)
+}
@@ -3,12 +3,19 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/imagestorage"
 	"restaurant-booking/pkg/logger"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -17,8 +24,35 @@ var (
 	ErrUnauthorized          = errors.New("unauthorized: not the owner")
 	ErrInvalidRestaurantName = errors.New("restaurant name cannot be empty")
 	ErrImageNotFound         = errors.New("image not found")
+	ErrInvalidImageOrder     = errors.New("image order must list every image of the restaurant exactly once")
+	ErrConfigVersionNotFound = errors.New("config version not found")
+	ErrInvalidCuisineType    = errors.New("invalid cuisine type")
+	ErrInvalidMinRating      = errors.New("min rating must be between 0 and 5")
+	ErrInvalidCoordinates    = errors.New("lat must be between -90 and 90 and lng between -180 and 180")
+	ErrInvalidRadius         = errors.New("radius must be positive")
+	ErrInvalidWorkingHours   = errors.New("invalid working hours")
+	ErrInvalidTimezone       = errors.New("timezone must be an IANA time zone name such as Asia/Almaty")
+	ErrInvalidPriceRange     = errors.New("min_price and max_price cannot be negative and min_price cannot exceed max_price")
+	ErrInvalidSort           = errors.New("sort must be one of rating, price, created_at")
+	// ErrRestaurantDeactivatedByAdmin is returned when an owner tries to
+	// reactivate a restaurant an admin took down.
+	ErrRestaurantDeactivatedByAdmin = errors.New("restaurant was deactivated by an admin and can only be restored by an admin")
 )
 
+// weekDays are the WorkingHours keys every restaurant has to define.
+var weekDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// MaxNearbyRadiusKm caps how far NearbyRestaurants searches, so a huge radius
+// cannot turn into a scan of every restaurant.
+const MaxNearbyRadiusKm = 50.0
+
+// imageDeleteTimeout bounds removing a file from image storage, which runs
+// after the request's own work is done.
+const imageDeleteTimeout = 10 * time.Second
+
+// maxRestaurantConfigVersions is how many config versions are kept per restaurant.
+const maxRestaurantConfigVersions = 50
+
 type CreateRestaurantRequest struct {
 	Name                string
 	Address             string
@@ -32,51 +66,129 @@ type CreateRestaurantRequest struct {
 	AveragePrice        int
 	MaxCombinableTables int
 	WorkingHours        domain.WorkingHours
+	// MaxConcurrentGuests of 0 leaves the restaurant without a limit.
+	MaxConcurrentGuests int
+	// LastSeatingOffsetMinutes defaults to DefaultLastSeatingOffsetMinutes.
+	LastSeatingOffsetMinutes *int
+	CancellationPolicy       domain.CancellationPolicy
+	BookingRules             domain.RestaurantBookingRules
+	// Timezone defaults to domain.DefaultTimezone.
+	Timezone string
+	// Force creates the restaurant even if it looks like a duplicate.
+	Force bool
 }
 
 type UpdateRestaurantRequest struct {
-	Name                *string
-	Address             *string
-	Latitude            *float64
-	Longitude           *float64
-	Description         *string
-	Phone               *string
-	Instagram           *string
-	Website             *string
-	CuisineType         *domain.CuisineType
-	AveragePrice        *int
-	MaxCombinableTables *int
-	WorkingHours        *domain.WorkingHours
-	IsActive            *bool
+	Name                     *string
+	Address                  *string
+	Latitude                 *float64
+	Longitude                *float64
+	Description              *string
+	Phone                    *string
+	Instagram                *string
+	Website                  *string
+	CuisineType              *domain.CuisineType
+	AveragePrice             *int
+	MaxCombinableTables      *int
+	WorkingHours             *domain.WorkingHours
+	LastSeatingOffsetMinutes *int
+	MaxConcurrentGuests      *int
+	CancellationPolicy       *domain.CancellationPolicy
+	BookingRules             *domain.RestaurantBookingRules
+	Timezone                 *string
+	IsActive                 *bool
+	// RegenerateSlug replaces the slug with one made from the name, after
+	// any rename in the same request. Renaming alone keeps the slug.
+	RegenerateSlug bool
 }
 
 type AddImageRequest struct {
-	CloudinaryURL      string
-	CloudinaryPublicID string
-	IsMain             bool
+	File   imagestorage.File
+	IsMain bool
+}
+
+// AddImageResult is the outcome of storing one file of AddImages. Exactly
+// one of Image and Err is set.
+type AddImageResult struct {
+	Image *domain.RestaurantImage
+	Err   error
 }
 
 type RestaurantService interface {
+	// CreateRestaurant returns a *DuplicateRestaurantError, unless req.Force
+	// is set, when a restaurant already exists at the same address or the
+	// owner already has one with a similar name.
 	CreateRestaurant(ctx context.Context, ownerID uuid.UUID, req CreateRestaurantRequest) (*domain.Restaurant, error)
 	GetRestaurant(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error)
-	GetRestaurants(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error)
+	// GetRestaurantBySlug is GetRestaurant for the restaurant's slug.
+	GetRestaurantBySlug(ctx context.Context, slug string) (*domain.Restaurant, error)
+	// GetRestaurants, GetRestaurantsWithColumns and SearchRestaurants list
+	// active restaurants an admin approved. A non-nil openAt keeps only those open at that
+	// time, as IsOpenAt decides, before the page is cut. GetRestaurants and
+	// GetRestaurantsWithColumns take it from filter, which also bounds the
+	// average price and picks the order.
+	GetRestaurants(ctx context.Context, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error)
+	GetRestaurantsWithColumns(ctx context.Context, columns []string, withMainImage bool, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error)
+	// CountRestaurants counts the restaurants GetRestaurants pages through
+	// for filter.
+	CountRestaurants(ctx context.Context, filter repository.RestaurantFilter) (int64, error)
+	// SearchRestaurants filters listed restaurants by cuisine and minimum
+	// rating. A nil cuisineType matches every cuisine.
+	SearchRestaurants(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error)
+	// NearbyRestaurants returns restaurants within radiusKm of (lat, lng),
+	// nearest first. radiusKm is capped at MaxNearbyRadiusKm.
+	NearbyRestaurants(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*repository.NearbyRestaurant, error)
+	// ListCuisines returns every cuisine type, in enum order, with how many
+	// listed restaurants serve it.
+	ListCuisines(ctx context.Context) ([]*repository.CuisineCount, error)
 	UpdateRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req UpdateRestaurantRequest) (*domain.Restaurant, error)
 	DeleteRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error
+	// RestoreRestaurant reactivates a deactivated restaurant. Tables that were
+	// deactivated on their own stay inactive.
+	RestoreRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) (*domain.Restaurant, error)
+	// ListOwnedRestaurants returns the owner's restaurants, leaving out
+	// deactivated ones unless includeInactive is set.
+	ListOwnedRestaurants(ctx context.Context, ownerID uuid.UUID, includeInactive bool) ([]*domain.Restaurant, error)
 	AddImage(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req AddImageRequest) (*domain.RestaurantImage, error)
+	// AddImages appends files to the gallery in order, none of them main.
+	// A file that fails does not stop the others; its result says why. The
+	// error is only for the request as a whole, such as ErrUnauthorized.
+	AddImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, files []imagestorage.File) ([]AddImageResult, error)
 	DeleteImage(ctx context.Context, imageID uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) error
+	// SetMainImage makes the image the restaurant's only main image.
+	SetMainImage(ctx context.Context, imageID uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) (*domain.RestaurantImage, error)
+	// ReorderImages stores the gallery order given by imageIDs, which must
+	// list every image of the restaurant once, and returns the images in
+	// that order.
+	ReorderImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, imageIDs []uuid.UUID) ([]*domain.RestaurantImage, error)
+	ListConfigVersions(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, limit, offset int) ([]*domain.RestaurantConfigVersion, error)
+	RollbackConfig(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, version int) (*domain.Restaurant, error)
 }
 
 type restaurantService struct {
-	restaurantRepo repository.RestaurantRepository
-	db             *gorm.DB
-	log            logger.Logger
+	restaurantRepo    repository.RestaurantRepository
+	configVersionRepo repository.RestaurantConfigVersionRepository
+	authz             RestaurantAuthorizer
+	images            imagestorage.ImageStorage
+	db                *gorm.DB
+	log               logger.Logger
 }
 
-func NewRestaurantService(restaurantRepo repository.RestaurantRepository, db *gorm.DB, log logger.Logger) RestaurantService {
+func NewRestaurantService(
+	restaurantRepo repository.RestaurantRepository,
+	configVersionRepo repository.RestaurantConfigVersionRepository,
+	authz RestaurantAuthorizer,
+	images imagestorage.ImageStorage,
+	db *gorm.DB,
+	log logger.Logger,
+) RestaurantService {
 	return &restaurantService{
-		restaurantRepo: restaurantRepo,
-		db:             db,
-		log:            log,
+		restaurantRepo:    restaurantRepo,
+		configVersionRepo: configVersionRepo,
+		authz:             authz,
+		images:            images,
+		db:                db,
+		log:               log,
 	}
 }
 
@@ -85,31 +197,132 @@ func (s *restaurantService) CreateRestaurant(ctx context.Context, ownerID uuid.U
 	if strings.TrimSpace(req.Name) == "" {
 		return nil, ErrInvalidRestaurantName
 	}
+	if err := validateWorkingHours(req.WorkingHours); err != nil {
+		return nil, err
+	}
+	if err := validateCancellationPolicy(req.CancellationPolicy); err != nil {
+		return nil, err
+	}
+	if err := validateRestaurantBookingRules(req.BookingRules); err != nil {
+		return nil, err
+	}
+	timezone := domain.DefaultTimezone
+	if req.Timezone != "" {
+		if err := validateTimezone(req.Timezone); err != nil {
+			return nil, err
+		}
+		timezone = req.Timezone
+	}
+	if !req.Force {
+		duplicate, err := s.findDuplicate(ctx, ownerID, req.Name, req.Address)
+		if err != nil {
+			return nil, err
+		}
+		if duplicate != nil {
+			return nil, duplicate
+		}
+	}
 
 	restaurant := &domain.Restaurant{
-		OwnerID:             ownerID,
-		Name:                req.Name,
-		Address:             req.Address,
-		Latitude:            req.Latitude,
-		Longitude:           req.Longitude,
-		Description:         req.Description,
-		Phone:               req.Phone,
-		Instagram:           req.Instagram,
-		Website:             req.Website,
-		CuisineType:         req.CuisineType,
-		AveragePrice:        req.AveragePrice,
-		MaxCombinableTables: req.MaxCombinableTables,
-		WorkingHours:        req.WorkingHours,
-		IsActive:            true,
-	}
-
-	if err := s.restaurantRepo.Create(ctx, restaurant); err != nil {
+		OwnerID:                  ownerID,
+		Name:                     req.Name,
+		Latitude:                 req.Latitude,
+		Longitude:                req.Longitude,
+		Description:              req.Description,
+		Phone:                    req.Phone,
+		Instagram:                req.Instagram,
+		Website:                  req.Website,
+		CuisineType:              req.CuisineType,
+		AveragePrice:             req.AveragePrice,
+		MaxCombinableTables:      req.MaxCombinableTables,
+		WorkingHours:             req.WorkingHours,
+		Timezone:                 timezone,
+		LastSeatingOffsetMinutes: DefaultLastSeatingOffsetMinutes,
+		MaxConcurrentGuests:      req.MaxConcurrentGuests,
+		CancellationPolicy:       req.CancellationPolicy,
+		BookingRules:             req.BookingRules,
+		IsActive:                 true,
+		Status:                   domain.RestaurantStatusPending,
+	}
+	if req.LastSeatingOffsetMinutes != nil {
+		restaurant.LastSeatingOffsetMinutes = *req.LastSeatingOffsetMinutes
+	}
+	setAddress(restaurant, req.Address)
+
+	picked, err := pickSlug(ctx, s.restaurantRepo, uuid.Nil, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	restaurant.Slug = &picked
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.restaurantRepo.WithTx(tx).Create(ctx, restaurant); err != nil {
+			return err
+		}
+		return s.configVersionRepo.WithTx(tx).Append(ctx, &domain.RestaurantConfigVersion{
+			RestaurantID: restaurant.ID,
+			ActorID:      ownerID,
+			Snapshot:     restaurant.Config(),
+			Changes:      "initial configuration",
+		}, maxRestaurantConfigVersions)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return restaurant, nil
 }
 
+// validateTimezone accepts IANA zone names. "Local" is rejected because it
+// depends on the server's zone rather than the restaurant's.
+func validateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// validateWorkingHours requires a schedule for every day of the week. A day
+// is either closed or has HH:MM open and close times, with close after open
+// unless the day is marked overnight, in which case close is before open.
+func validateWorkingHours(hours domain.WorkingHours) error {
+	for day := range hours {
+		if !slices.Contains(weekDays, day) {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidWorkingHours, day)
+		}
+	}
+
+	for _, day := range weekDays {
+		schedule, ok := hours[day]
+		if !ok {
+			return fmt.Errorf("%w: %s is missing", ErrInvalidWorkingHours, day)
+		}
+		if schedule.IsClosed {
+			continue
+		}
+
+		opens, err := time.Parse("15:04", schedule.OpenTime)
+		if err != nil {
+			return fmt.Errorf("%w: %s open_time must be HH:MM", ErrInvalidWorkingHours, day)
+		}
+		closes, err := time.Parse("15:04", schedule.CloseTime)
+		if err != nil {
+			return fmt.Errorf("%w: %s close_time must be HH:MM", ErrInvalidWorkingHours, day)
+		}
+
+		switch {
+		case schedule.Overnight && !closes.Before(opens):
+			return fmt.Errorf("%w: %s is overnight, so close_time must be before open_time", ErrInvalidWorkingHours, day)
+		case !schedule.Overnight && !closes.After(opens):
+			return fmt.Errorf("%w: %s close_time must be after open_time, or mark the day overnight", ErrInvalidWorkingHours, day)
+		}
+	}
+	return nil
+}
+
 func (s *restaurantService) GetRestaurant(ctx context.Context, id uuid.UUID) (*domain.Restaurant, error) {
 	restaurant, err := s.restaurantRepo.GetByID(ctx, id)
 	if err != nil {
@@ -121,22 +334,107 @@ func (s *restaurantService) GetRestaurant(ctx context.Context, id uuid.UUID) (*d
 	return restaurant, nil
 }
 
-func (s *restaurantService) GetRestaurants(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error) {
-	return s.restaurantRepo.List(ctx, limit, offset)
-}
-
-func (s *restaurantService) UpdateRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req UpdateRestaurantRequest) (*domain.Restaurant, error) {
-	restaurant, err := s.restaurantRepo.GetByID(ctx, id)
+func (s *restaurantService) GetRestaurantBySlug(ctx context.Context, slug string) (*domain.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetBySlug(ctx, slug)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRestaurantNotFound
 		}
 		return nil, err
 	}
+	return restaurant, nil
+}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+func (s *restaurantService) GetRestaurants(ctx context.Context, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	if err := validateRestaurantFilter(filter); err != nil {
+		return nil, err
 	}
+	return s.restaurantRepo.ListFiltered(ctx, filter, limit, offset)
+}
+
+func (s *restaurantService) GetRestaurantsWithColumns(ctx context.Context, columns []string, withMainImage bool, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	if err := validateRestaurantFilter(filter); err != nil {
+		return nil, err
+	}
+	if withMainImage && !slices.Contains(columns, "id") {
+		columns = append([]string{"id"}, columns...)
+	}
+	return s.restaurantRepo.ListColumns(ctx, columns, withMainImage, filter, limit, offset)
+}
+
+func (s *restaurantService) CountRestaurants(ctx context.Context, filter repository.RestaurantFilter) (int64, error) {
+	if err := validateRestaurantFilter(filter); err != nil {
+		return 0, err
+	}
+	return s.restaurantRepo.Count(ctx, filter)
+}
+
+func validateRestaurantFilter(filter repository.RestaurantFilter) error {
+	if filter.Sort != "" && !filter.Sort.Valid() {
+		return ErrInvalidSort
+	}
+	if (filter.MinPrice != nil && *filter.MinPrice < 0) || (filter.MaxPrice != nil && *filter.MaxPrice < 0) {
+		return ErrInvalidPriceRange
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return ErrInvalidPriceRange
+	}
+	return nil
+}
+
+func (s *restaurantService) SearchRestaurants(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error) {
+	if cuisineType != nil && !slices.Contains(domain.CuisineTypes, *cuisineType) {
+		return nil, ErrInvalidCuisineType
+	}
+	if minRating < 0 || minRating > 5 {
+		return nil, ErrInvalidMinRating
+	}
+	return s.restaurantRepo.Search(ctx, cuisineType, minRating, openAt, limit, offset)
+}
+
+func (s *restaurantService) NearbyRestaurants(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*repository.NearbyRestaurant, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, ErrInvalidCoordinates
+	}
+	if radiusKm <= 0 {
+		return nil, ErrInvalidRadius
+	}
+	radiusKm = math.Min(radiusKm, MaxNearbyRadiusKm)
+	return s.restaurantRepo.ListNearby(ctx, lat, lng, radiusKm, limit, offset)
+}
+
+func (s *restaurantService) ListCuisines(ctx context.Context) ([]*repository.CuisineCount, error) {
+	counts, err := s.restaurantRepo.CountByCuisine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCuisine := make(map[domain.CuisineType]int64, len(counts))
+	for _, count := range counts {
+		byCuisine[count.CuisineType] = count.RestaurantCount
+	}
+
+	cuisines := make([]*repository.CuisineCount, 0, len(domain.CuisineTypes))
+	for _, cuisine := range domain.CuisineTypes {
+		cuisines = append(cuisines, &repository.CuisineCount{CuisineType: cuisine, RestaurantCount: byCuisine[cuisine]})
+	}
+	return cuisines, nil
+}
+
+func (s *restaurantService) UpdateRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req UpdateRestaurantRequest) (*domain.Restaurant, error) {
+	restaurant, err := s.getOwnedRestaurant(ctx, id, ownerID, "restaurant.update")
+	if err != nil {
+		return nil, err
+	}
+	return s.updateRestaurant(ctx, restaurant, ownerID, req, "")
+}
+
+// updateRestaurant applies req to an already authorized restaurant and records
+// a config version when anything changed. note prefixes the diff summary of
+// that version.
+func (s *restaurantService) updateRestaurant(ctx context.Context, restaurant *domain.Restaurant, actorID uuid.UUID, req UpdateRestaurantRequest, note string) (*domain.Restaurant, error) {
+	id := restaurant.ID
+	before := restaurant.Config()
 
 	if req.Name != nil {
 		if strings.TrimSpace(*req.Name) == "" {
@@ -144,8 +442,15 @@ func (s *restaurantService) UpdateRestaurant(ctx context.Context, id uuid.UUID,
 		}
 		restaurant.Name = *req.Name
 	}
+	if req.RegenerateSlug {
+		picked, err := pickSlug(ctx, s.restaurantRepo, id, restaurant.Name)
+		if err != nil {
+			return nil, err
+		}
+		restaurant.Slug = &picked
+	}
 	if req.Address != nil {
-		restaurant.Address = *req.Address
+		setAddress(restaurant, *req.Address)
 	}
 	if req.Latitude != nil {
 		restaurant.Latitude = req.Latitude
@@ -175,55 +480,329 @@ func (s *restaurantService) UpdateRestaurant(ctx context.Context, id uuid.UUID,
 		restaurant.MaxCombinableTables = *req.MaxCombinableTables
 	}
 	if req.WorkingHours != nil {
+		if err := validateWorkingHours(*req.WorkingHours); err != nil {
+			return nil, err
+		}
 		restaurant.WorkingHours = *req.WorkingHours
 	}
+	if req.LastSeatingOffsetMinutes != nil {
+		restaurant.LastSeatingOffsetMinutes = *req.LastSeatingOffsetMinutes
+	}
+	if req.MaxConcurrentGuests != nil {
+		restaurant.MaxConcurrentGuests = *req.MaxConcurrentGuests
+	}
+	if req.CancellationPolicy != nil {
+		if err := validateCancellationPolicy(*req.CancellationPolicy); err != nil {
+			return nil, err
+		}
+		restaurant.CancellationPolicy = *req.CancellationPolicy
+	}
+	if req.BookingRules != nil {
+		if err := validateRestaurantBookingRules(*req.BookingRules); err != nil {
+			return nil, err
+		}
+		restaurant.BookingRules = *req.BookingRules
+	}
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			return nil, err
+		}
+		restaurant.Timezone = *req.Timezone
+	}
 	if req.IsActive != nil {
-		restaurant.IsActive = *req.IsActive
+		if err := s.setActive(ctx, restaurant, actorID, *req.IsActive); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.restaurantRepo.Update(ctx, restaurant); err != nil {
+	after := restaurant.Config()
+	changes := diffRestaurantConfig(before, after)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.restaurantRepo.WithTx(tx).Update(ctx, restaurant); err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		versions := s.configVersionRepo.WithTx(tx)
+
+		// Restaurants created before config versioning get their current
+		// configuration recorded first so the first change can be undone.
+		if _, err := versions.Latest(ctx, id); errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := versions.Append(ctx, &domain.RestaurantConfigVersion{
+				RestaurantID: id,
+				ActorID:      restaurant.OwnerID,
+				Snapshot:     before,
+				Changes:      "initial configuration",
+			}, maxRestaurantConfigVersions); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		summary := strings.Join(changes, "; ")
+		if note != "" {
+			summary = note + ": " + summary
+		}
+
+		return versions.Append(ctx, &domain.RestaurantConfigVersion{
+			RestaurantID: id,
+			ActorID:      actorID,
+			Snapshot:     after,
+			Changes:      summary,
+		}, maxRestaurantConfigVersions)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return restaurant, nil
 }
 
-func (s *restaurantService) DeleteRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error {
+func (s *restaurantService) ListConfigVersions(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, limit, offset int) ([]*domain.RestaurantConfigVersion, error) {
+	if _, err := s.getOwnedRestaurant(ctx, id, ownerID, "config_version.list"); err != nil {
+		return nil, err
+	}
+	return s.configVersionRepo.ListByRestaurant(ctx, id, limit, offset)
+}
+
+// RollbackConfig re-applies a stored snapshot through the regular update path,
+// so validation still runs and the rollback becomes a version of its own.
+// Optional fields that were empty in the snapshot are left as they are.
+func (s *restaurantService) RollbackConfig(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, version int) (*domain.Restaurant, error) {
+	restaurant, err := s.getOwnedRestaurant(ctx, id, ownerID, "config_version.rollback")
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.configVersionRepo.GetByVersion(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConfigVersionNotFound
+		}
+		return nil, err
+	}
+
+	snapshot := target.Snapshot
+	req := UpdateRestaurantRequest{
+		Name:                     &snapshot.Name,
+		Address:                  &snapshot.Address,
+		Latitude:                 snapshot.Latitude,
+		Longitude:                snapshot.Longitude,
+		Description:              &snapshot.Description,
+		Phone:                    &snapshot.Phone,
+		Instagram:                snapshot.Instagram,
+		Website:                  snapshot.Website,
+		CuisineType:              &snapshot.CuisineType,
+		AveragePrice:             &snapshot.AveragePrice,
+		MaxCombinableTables:      &snapshot.MaxCombinableTables,
+		WorkingHours:             &snapshot.WorkingHours,
+		LastSeatingOffsetMinutes: snapshot.LastSeatingOffsetMinutes,
+		MaxConcurrentGuests:      snapshot.MaxConcurrentGuests,
+		CancellationPolicy:       snapshot.CancellationPolicy,
+		BookingRules:             snapshot.BookingRules,
+		IsActive:                 &snapshot.IsActive,
+	}
+
+	return s.updateRestaurant(ctx, restaurant, ownerID, req, fmt.Sprintf("rollback to version %d", version))
+}
+
+func (s *restaurantService) getOwnedRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, action string) (*domain.Restaurant, error) {
 	restaurant, err := s.restaurantRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrRestaurantNotFound
+			return nil, ErrRestaurantNotFound
 		}
+		return nil, err
+	}
+
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, action); err != nil {
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+// diffRestaurantConfig describes every field that differs between two configs.
+func diffRestaurantConfig(before, after domain.RestaurantConfig) []string {
+	var changes []string
+	changed := func(field string, from, to interface{}) {
+		changes = append(changes, fmt.Sprintf("%s: %v -> %v", field, from, to))
+	}
+
+	if before.Name != after.Name {
+		changed("name", fmt.Sprintf("%q", before.Name), fmt.Sprintf("%q", after.Name))
+	}
+	if before.Address != after.Address {
+		changed("address", fmt.Sprintf("%q", before.Address), fmt.Sprintf("%q", after.Address))
+	}
+	if !reflect.DeepEqual(before.Latitude, after.Latitude) || !reflect.DeepEqual(before.Longitude, after.Longitude) {
+		changes = append(changes, "location changed")
+	}
+	if before.Description != after.Description {
+		changes = append(changes, "description changed")
+	}
+	if before.Phone != after.Phone {
+		changed("phone", fmt.Sprintf("%q", before.Phone), fmt.Sprintf("%q", after.Phone))
+	}
+	if !reflect.DeepEqual(before.Instagram, after.Instagram) {
+		changes = append(changes, "instagram changed")
+	}
+	if !reflect.DeepEqual(before.Website, after.Website) {
+		changes = append(changes, "website changed")
+	}
+	if before.CuisineType != after.CuisineType {
+		changed("cuisine_type", before.CuisineType, after.CuisineType)
+	}
+	if before.AveragePrice != after.AveragePrice {
+		changed("average_price", before.AveragePrice, after.AveragePrice)
+	}
+	if before.MaxCombinableTables != after.MaxCombinableTables {
+		changed("max_combinable_tables", before.MaxCombinableTables, after.MaxCombinableTables)
+	}
+	if !reflect.DeepEqual(before.WorkingHours, after.WorkingHours) {
+		changes = append(changes, "working_hours changed")
+	}
+	if before.LastSeatingOffsetMinutes != nil && after.LastSeatingOffsetMinutes != nil &&
+		*before.LastSeatingOffsetMinutes != *after.LastSeatingOffsetMinutes {
+		changed("last_seating_offset_minutes", *before.LastSeatingOffsetMinutes, *after.LastSeatingOffsetMinutes)
+	}
+	if before.MaxConcurrentGuests != nil && after.MaxConcurrentGuests != nil &&
+		*before.MaxConcurrentGuests != *after.MaxConcurrentGuests {
+		changed("max_concurrent_guests", *before.MaxConcurrentGuests, *after.MaxConcurrentGuests)
+	}
+	if before.CancellationPolicy != nil && after.CancellationPolicy != nil &&
+		*before.CancellationPolicy != *after.CancellationPolicy {
+		changed("cancellation_policy", before.CancellationPolicy.Version(), after.CancellationPolicy.Version())
+	}
+	if before.BookingRules != nil && after.BookingRules != nil &&
+		!reflect.DeepEqual(*before.BookingRules, *after.BookingRules) {
+		changes = append(changes, "booking_rules changed")
+	}
+	if before.IsActive != after.IsActive {
+		changed("is_active", before.IsActive, after.IsActive)
+	}
+
+	return changes
+}
+
+func (s *restaurantService) DeleteRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error {
+	restaurant, err := s.getOwnedRestaurant(ctx, id, ownerID, "restaurant.delete")
+	if err != nil {
 		return err
 	}
-	if restaurant.OwnerID != ownerID {
-		return ErrUnauthorized
+	if err := s.setActive(ctx, restaurant, ownerID, false); err != nil {
+		return err
 	}
-	restaurant.IsActive = false
 	return s.restaurantRepo.Update(ctx, restaurant)
 }
 
-func (s *restaurantService) AddImage(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req AddImageRequest) (*domain.RestaurantImage, error) {
-	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+func (s *restaurantService) RestoreRestaurant(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) (*domain.Restaurant, error) {
+	restaurant, err := s.getOwnedRestaurant(ctx, id, ownerID, "restaurant.restore")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrRestaurantNotFound
+		return nil, err
+	}
+	if restaurant.IsActive {
+		return restaurant, nil
+	}
+	if err := s.setActive(ctx, restaurant, ownerID, true); err != nil {
+		return nil, err
+	}
+	if err := s.restaurantRepo.Update(ctx, restaurant); err != nil {
+		return nil, err
+	}
+	return restaurant, nil
+}
+
+// setActive switches the restaurant on or off and remembers who switched it
+// off, so that an owner cannot undo an admin's deactivation.
+func (s *restaurantService) setActive(ctx context.Context, restaurant *domain.Restaurant, actorID uuid.UUID, active bool) error {
+	if restaurant.IsActive == active {
+		return nil
+	}
+	if active {
+		if restaurant.DeactivatedByAdmin() && !s.authz.IsAdmin(ctx, actorID) {
+			return ErrRestaurantDeactivatedByAdmin
 		}
+		restaurant.DeactivatedBy = nil
+	} else {
+		restaurant.DeactivatedBy = &actorID
+	}
+	restaurant.IsActive = active
+	return nil
+}
+
+func (s *restaurantService) ListOwnedRestaurants(ctx context.Context, ownerID uuid.UUID, includeInactive bool) ([]*domain.Restaurant, error) {
+	restaurants, err := s.restaurantRepo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
 		return nil, err
 	}
+	if includeInactive {
+		return restaurants, nil
+	}
+	return slices.DeleteFunc(restaurants, func(r *domain.Restaurant) bool {
+		return !r.IsActive
+	}), nil
+}
+
+func (s *restaurantService) AddImage(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req AddImageRequest) (*domain.RestaurantImage, error) {
+	if _, err := s.getOwnedRestaurant(ctx, restaurantID, ownerID, "image.add"); err != nil {
+		return nil, err
+	}
+	return s.addImage(ctx, restaurantID, req)
+}
+
+func (s *restaurantService) AddImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, files []imagestorage.File) ([]AddImageResult, error) {
+	if _, err := s.getOwnedRestaurant(ctx, restaurantID, ownerID, "image.add"); err != nil {
+		return nil, err
+	}
+
+	results := make([]AddImageResult, len(files))
+	for i, file := range files {
+		results[i].Image, results[i].Err = s.addImage(ctx, restaurantID, AddImageRequest{File: file})
+	}
+	return results, nil
+}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+// addImage stores an image of an already authorized restaurant.
+func (s *restaurantService) addImage(ctx context.Context, restaurantID uuid.UUID, req AddImageRequest) (*domain.RestaurantImage, error) {
+	url, publicID, err := s.images.Upload(ctx, req.File)
+	if err != nil {
+		return nil, fmt.Errorf("upload image: %w", err)
 	}
 
 	image := &domain.RestaurantImage{
 		RestaurantID:       restaurantID,
-		CloudinaryURL:      req.CloudinaryURL,
-		CloudinaryPublicID: req.CloudinaryPublicID,
+		CloudinaryURL:      url,
+		CloudinaryPublicID: publicID,
 		IsMain:             req.IsMain,
 	}
 
-	if err := s.db.WithContext(ctx).Create(image).Error; err != nil {
+	err = s.withImageLock(ctx, restaurantID, func(tx *gorm.DB) error {
+		// New images go to the end of the gallery.
+		var last int
+		if err := tx.Model(&domain.RestaurantImage{}).
+			Where("restaurant_id = ?", restaurantID).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		image.Position = last + 1
+
+		if image.IsMain {
+			if err := unsetMainImages(tx, restaurantID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(image).Error
+	})
+	if err != nil {
+		// Nothing references the upload, so remove it rather than leave an
+		// orphan in storage.
+		s.deleteStoredImage(publicID)
 		return nil, err
 	}
 
@@ -231,18 +810,10 @@ func (s *restaurantService) AddImage(ctx context.Context, restaurantID uuid.UUID
 }
 
 func (s *restaurantService) DeleteImage(ctx context.Context, imageID uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) error {
-	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrRestaurantNotFound
-		}
+	if _, err := s.getOwnedRestaurant(ctx, restaurantID, ownerID, "image.delete"); err != nil {
 		return err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return ErrUnauthorized
-	}
-
 	var image domain.RestaurantImage
 	if err := s.db.WithContext(ctx).
 		Where("id = ? AND restaurant_id = ?", imageID, restaurantID).
@@ -253,5 +824,108 @@ func (s *restaurantService) DeleteImage(ctx context.Context, imageID uuid.UUID,
 		return err
 	}
 
-	return s.db.WithContext(ctx).Delete(&image).Error
+	if err := s.db.WithContext(ctx).Delete(&image).Error; err != nil {
+		return err
+	}
+	s.deleteStoredImage(image.CloudinaryPublicID)
+	return nil
+}
+
+// deleteStoredImage removes a file whose row is gone. The row is what
+// clients see, so a failure here is only logged.
+func (s *restaurantService) deleteStoredImage(publicID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), imageDeleteTimeout)
+	defer cancel()
+	if err := s.images.Delete(ctx, publicID); err != nil {
+		s.log.Warn("failed to delete stored image", zap.String("public_id", publicID), zap.Error(err))
+	}
+}
+
+func (s *restaurantService) SetMainImage(ctx context.Context, imageID uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) (*domain.RestaurantImage, error) {
+	if _, err := s.getOwnedRestaurant(ctx, restaurantID, ownerID, "image.set_main"); err != nil {
+		return nil, err
+	}
+
+	var image domain.RestaurantImage
+	err := s.withImageLock(ctx, restaurantID, func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND restaurant_id = ?", imageID, restaurantID).First(&image).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImageNotFound
+			}
+			return err
+		}
+
+		if err := unsetMainImages(tx, restaurantID); err != nil {
+			return err
+		}
+		image.IsMain = true
+		return tx.Model(&image).Update("is_main", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+func (s *restaurantService) ReorderImages(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, imageIDs []uuid.UUID) ([]*domain.RestaurantImage, error) {
+	if _, err := s.getOwnedRestaurant(ctx, restaurantID, ownerID, "image.reorder"); err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*domain.RestaurantImage, len(imageIDs))
+	err := s.withImageLock(ctx, restaurantID, func(tx *gorm.DB) error {
+		var images []*domain.RestaurantImage
+		if err := tx.Where("restaurant_id = ?", restaurantID).Find(&images).Error; err != nil {
+			return err
+		}
+		if len(images) != len(imageIDs) {
+			return ErrInvalidImageOrder
+		}
+
+		byID := make(map[uuid.UUID]*domain.RestaurantImage, len(images))
+		for _, image := range images {
+			byID[image.ID] = image
+		}
+		for position, id := range imageIDs {
+			image, ok := byID[id]
+			if !ok {
+				return ErrInvalidImageOrder
+			}
+			// Removing the image also catches IDs listed twice.
+			delete(byID, id)
+			image.Position = position
+			ordered[position] = image
+		}
+
+		for _, image := range ordered {
+			if err := tx.Model(image).Update("position", image.Position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ordered, nil
+}
+
+// withImageLock runs fn in a transaction holding a Postgres advisory lock
+// keyed by the restaurant, so concurrent changes to its gallery do not
+// interleave and leave two main images or duplicate positions behind.
+func (s *restaurantService) withImageLock(ctx context.Context, restaurantID uuid.UUID, fn func(tx *gorm.DB) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "restaurant_images:"+restaurantID.String()).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+func unsetMainImages(tx *gorm.DB, restaurantID uuid.UUID) error {
+	return tx.Model(&domain.RestaurantImage{}).
+		Where("restaurant_id = ? AND is_main = ?", restaurantID, true).
+		Update("is_main", false).Error
 }
@@ -2,15 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/imagestorage"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -43,14 +51,27 @@ func (m *MockRestaurantRepository) GetByOwnerID(ctx context.Context, ownerID uui
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
-func (m *MockRestaurantRepository) List(ctx context.Context, limit, offset int) ([]*domain.Restaurant, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockRestaurantRepository) ListFiltered(ctx context.Context, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
+func (m *MockRestaurantRepository) ListColumns(ctx context.Context, columns []string, withMainImage bool, filter repository.RestaurantFilter, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, columns, withMainImage, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) Count(ctx context.Context, filter repository.RestaurantFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockRestaurantRepository) Update(ctx context.Context, r *domain.Restaurant) error {
 	return m.Called(ctx, r).Error(0)
 }
@@ -61,23 +82,151 @@ func (m *MockRestaurantRepository) Delete(ctx context.Context, id uuid.UUID) err
 }
 
 // !!! ДОБАВЛЕН МЕТОД SEARCH (обычно он тоже нужен для полного соответствия интерфейсу) !!!
-func (m *MockRestaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, limit, offset int) ([]*domain.Restaurant, error) {
-	args := m.Called(ctx, cuisineType, minRating, limit, offset)
+func (m *MockRestaurantRepository) Search(ctx context.Context, cuisineType *domain.CuisineType, minRating float64, openAt *time.Time, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, cuisineType, minRating, openAt, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) ListNearby(ctx context.Context, lat, lng, radiusKm float64, limit, offset int) ([]*repository.NearbyRestaurant, error) {
+	args := m.Called(ctx, lat, lng, radiusKm, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.NearbyRestaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) CountByCuisine(ctx context.Context) ([]*repository.CuisineCount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.CuisineCount), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) GetByAddressKey(ctx context.Context, key string) (*domain.Restaurant, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Restaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) GetBySlug(ctx context.Context, slug string) (*domain.Restaurant, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Restaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) ListSlugs(ctx context.Context, base string, except uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, base, except)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) ListWithoutSlug(ctx context.Context, after uuid.UUID, limit int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Restaurant), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) CountWithoutSlug(ctx context.Context, after uuid.UUID) (int64, error) {
+	args := m.Called(ctx, after)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) SetSlug(ctx context.Context, id uuid.UUID, slug string) error {
+	args := m.Called(ctx, id, slug)
+	return args.Error(0)
+}
+
+func (m *MockRestaurantRepository) ListByStatus(ctx context.Context, status domain.RestaurantStatus, limit, offset int) ([]*domain.Restaurant, error) {
+	args := m.Called(ctx, status, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.Restaurant), args.Error(1)
 }
 
+func (m *MockRestaurantRepository) CountByStatus(ctx context.Context, status domain.RestaurantStatus) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRestaurantRepository) SetStatus(ctx context.Context, restaurant *domain.Restaurant, from domain.RestaurantStatus) error {
+	args := m.Called(ctx, restaurant, from)
+	return args.Error(0)
+}
+
+func (m *MockRestaurantRepository) WithTx(tx *gorm.DB) repository.RestaurantRepository {
+	return m
+}
+
 // Проверка, что мок реализует интерфейс
 var _ repository.RestaurantRepository = (*MockRestaurantRepository)(nil)
 
+//
+// Mock RestaurantConfigVersionRepository
+//
+
+type MockRestaurantConfigVersionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRestaurantConfigVersionRepository) Append(ctx context.Context, version *domain.RestaurantConfigVersion, keep int) error {
+	return m.Called(ctx, version, keep).Error(0)
+}
+
+func (m *MockRestaurantConfigVersionRepository) Latest(ctx context.Context, restaurantID uuid.UUID) (*domain.RestaurantConfigVersion, error) {
+	args := m.Called(ctx, restaurantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RestaurantConfigVersion), args.Error(1)
+}
+
+func (m *MockRestaurantConfigVersionRepository) GetByVersion(ctx context.Context, restaurantID uuid.UUID, version int) (*domain.RestaurantConfigVersion, error) {
+	args := m.Called(ctx, restaurantID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RestaurantConfigVersion), args.Error(1)
+}
+
+func (m *MockRestaurantConfigVersionRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.RestaurantConfigVersion, error) {
+	args := m.Called(ctx, restaurantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RestaurantConfigVersion), args.Error(1)
+}
+
+func (m *MockRestaurantConfigVersionRepository) WithTx(tx *gorm.DB) repository.RestaurantConfigVersionRepository {
+	return m
+}
+
+var _ repository.RestaurantConfigVersionRepository = (*MockRestaurantConfigVersionRepository)(nil)
+
 //
 // Helper
 //
 
 func setupRestaurantService() (*restaurantService, *MockRestaurantRepository, sqlmock.Sqlmock) {
+	service, repo, _, dbMock := setupRestaurantServiceWithVersions()
+	return service, repo, dbMock
+}
+
+func setupRestaurantServiceWithVersions() (*restaurantService, *MockRestaurantRepository, *MockRestaurantConfigVersionRepository, sqlmock.Sqlmock) {
 	repo := new(MockRestaurantRepository)
+	versionRepo := new(MockRestaurantConfigVersionRepository)
 
 	// Переименовали переменную в dbMock, чтобы не конфликтовать с пакетом mock
 	sqlDB, dbMock, _ := sqlmock.New()
@@ -88,11 +237,51 @@ func setupRestaurantService() (*restaurantService, *MockRestaurantRepository, sq
 	db, _ := gorm.Open(dialector, &gorm.Config{})
 
 	service := &restaurantService{
-		restaurantRepo: repo,
-		db:             db,
+		restaurantRepo:    repo,
+		configVersionRepo: versionRepo,
+		authz:             NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder)),
+		db:                db,
+		log:               zap.NewNop(),
 	}
 
-	return service, repo, dbMock
+	return service, repo, versionRepo, dbMock
+}
+
+// useLocalImages points the service's image storage at a temporary
+// directory and returns it.
+func useLocalImages(t *testing.T, service *restaurantService) string {
+	dir := t.TempDir()
+	images, err := imagestorage.NewLocalDisk(dir, "/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.images = images
+	return dir
+}
+
+func storedImages(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names
+}
+
+func imageFile(name string) imagestorage.File {
+	return imagestorage.File{Name: name, Content: strings.NewReader("\x89PNG image bytes")}
+}
+
+// weekOfHours returns working hours with the same opening every day.
+func weekOfHours(open, close string) domain.WorkingHours {
+	hours := domain.WorkingHours{}
+	for _, day := range weekDays {
+		hours[day] = domain.DaySchedule{OpenTime: open, CloseTime: close}
+	}
+	return hours
 }
 
 //
@@ -100,7 +289,7 @@ func setupRestaurantService() (*restaurantService, *MockRestaurantRepository, sq
 //
 
 func TestCreateRestaurant_Success(t *testing.T) {
-	service, repo, _ := setupRestaurantService()
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
 	ctx := context.Background()
 
 	ownerID := uuid.New()
@@ -109,9 +298,18 @@ func TestCreateRestaurant_Success(t *testing.T) {
 		Name:         "Test Restaurant",
 		Address:      "Test Address",
 		AveragePrice: 5000,
+		WorkingHours: weekOfHours("10:00", "22:00"),
 	}
 
+	repo.On("GetByAddressKey", ctx, "test address").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("GetByOwnerID", ctx, ownerID).Return([]*domain.Restaurant{}, nil)
+	repo.On("ListSlugs", ctx, "test-restaurant", uuid.Nil).Return([]string{"test-restaurant"}, nil)
+	dbMock.ExpectBegin()
 	repo.On("Create", ctx, mock.AnythingOfType("*domain.Restaurant")).Return(nil)
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return v.ActorID == ownerID && v.Snapshot.Name == "Test Restaurant" && v.Changes == "initial configuration"
+	}), maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
 
 	restaurant, err := service.CreateRestaurant(ctx, ownerID, req)
 
@@ -120,6 +318,11 @@ func TestCreateRestaurant_Success(t *testing.T) {
 	assert.Equal(t, ownerID, restaurant.OwnerID)
 	assert.Equal(t, "Test Restaurant", restaurant.Name)
 	assert.True(t, restaurant.IsActive)
+	assert.Equal(t, domain.RestaurantStatusPending, restaurant.Status)
+	assert.Equal(t, "test address", restaurant.AddressKey)
+	assert.Equal(t, "test-restaurant-2", *restaurant.Slug)
+	assert.Equal(t, DefaultLastSeatingOffsetMinutes, restaurant.LastSeatingOffsetMinutes)
+	assert.Equal(t, domain.DefaultTimezone, restaurant.Timezone)
 	repo.AssertExpectations(t)
 }
 
@@ -135,6 +338,73 @@ func TestCreateRestaurant_InvalidName(t *testing.T) {
 	assert.Equal(t, ErrInvalidRestaurantName, err)
 }
 
+func TestCreateRestaurant_InvalidWorkingHours(t *testing.T) {
+	service, _, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	_, err := service.CreateRestaurant(ctx, uuid.New(), CreateRestaurantRequest{
+		Name:         "Test Restaurant",
+		WorkingHours: domain.WorkingHours{"monday": {OpenTime: "10:00", CloseTime: "22:00"}},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidWorkingHours)
+}
+
+func TestCreateRestaurant_InvalidTimezone(t *testing.T) {
+	service, _, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	for _, timezone := range []string{"Almaty", "Local", "UTC+5"} {
+		_, err := service.CreateRestaurant(ctx, uuid.New(), CreateRestaurantRequest{
+			Name:         "Test Restaurant",
+			WorkingHours: weekOfHours("10:00", "22:00"),
+			Timezone:     timezone,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidTimezone, timezone)
+	}
+}
+
+func TestValidateWorkingHours(t *testing.T) {
+	withDay := func(day string, schedule domain.DaySchedule) domain.WorkingHours {
+		hours := weekOfHours("10:00", "22:00")
+		hours[day] = schedule
+		return hours
+	}
+	missingSunday := weekOfHours("10:00", "22:00")
+	delete(missingSunday, "sunday")
+
+	tests := []struct {
+		name  string
+		hours domain.WorkingHours
+		valid bool
+	}{
+		{"regular week", weekOfHours("10:00", "22:00"), true},
+		{"closed day without times", withDay("monday", domain.DaySchedule{IsClosed: true}), true},
+		{"overnight 18:00-02:00", withDay("friday", domain.DaySchedule{OpenTime: "18:00", CloseTime: "02:00", Overnight: true}), true},
+		{"overnight till midnight", withDay("friday", domain.DaySchedule{OpenTime: "18:00", CloseTime: "00:00", Overnight: true}), true},
+		{"close before open without overnight", withDay("friday", domain.DaySchedule{OpenTime: "18:00", CloseTime: "02:00"}), false},
+		{"overnight that closes after opening", withDay("friday", domain.DaySchedule{OpenTime: "10:00", CloseTime: "22:00", Overnight: true}), false},
+		{"open equals close", withDay("monday", domain.DaySchedule{OpenTime: "10:00", CloseTime: "10:00"}), false},
+		{"unparseable time", withDay("monday", domain.DaySchedule{OpenTime: "10am", CloseTime: "22:00"}), false},
+		{"out of range time", withDay("monday", domain.DaySchedule{OpenTime: "10:00", CloseTime: "24:30"}), false},
+		{"missing day", missingSunday, false},
+		{"unknown day", withDay("Monday", domain.DaySchedule{IsClosed: true}), false},
+		{"empty", domain.WorkingHours{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkingHours(tt.hours)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidWorkingHours)
+			}
+		})
+	}
+}
+
 func TestGetRestaurant_Success(t *testing.T) {
 	service, repo, _ := setupRestaurantService()
 	ctx := context.Background()
@@ -175,15 +445,178 @@ func TestGetRestaurants_Success(t *testing.T) {
 		{ID: uuid.New()},
 	}
 
-	repo.On("List", ctx, 10, 0).Return(list, nil)
+	repo.On("ListFiltered", ctx, repository.RestaurantFilter{}, 10, 0).Return(list, nil)
 
-	result, err := service.GetRestaurants(ctx, 10, 0)
+	result, err := service.GetRestaurants(ctx, repository.RestaurantFilter{}, 10, 0)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
 	repo.AssertExpectations(t)
 }
 
+func TestSearchRestaurants_Success(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+	cuisine := domain.CuisineTypeItalian
+
+	list := []*domain.Restaurant{{ID: uuid.New(), CuisineType: cuisine}}
+	openAt := time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)
+	repo.On("Search", ctx, &cuisine, 4.0, &openAt, 10, 0).Return(list, nil)
+
+	result, err := service.SearchRestaurants(ctx, &cuisine, 4, &openAt, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, list, result)
+	repo.AssertExpectations(t)
+}
+
+func TestSearchRestaurants_Validation(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+	unknown := domain.CuisineType("Martian")
+
+	_, err := service.SearchRestaurants(ctx, &unknown, 0, nil, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidCuisineType)
+
+	_, err = service.SearchRestaurants(ctx, nil, 5.5, nil, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidMinRating)
+
+	_, err = service.SearchRestaurants(ctx, nil, -1, nil, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidMinRating)
+
+	repo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNearbyRestaurants_CapsRadius(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	nearby := []*repository.NearbyRestaurant{{Restaurant: domain.Restaurant{ID: uuid.New()}, DistanceKm: 1.2}}
+	repo.On("ListNearby", ctx, 43.25, 76.9, MaxNearbyRadiusKm, 10, 0).Return(nearby, nil)
+
+	result, err := service.NearbyRestaurants(ctx, 43.25, 76.9, 500, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, nearby, result)
+	repo.AssertExpectations(t)
+}
+
+func TestNearbyRestaurants_Validation(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	_, err := service.NearbyRestaurants(ctx, 91, 0, 5, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidCoordinates)
+
+	_, err = service.NearbyRestaurants(ctx, 0, -181, 5, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidCoordinates)
+
+	_, err = service.NearbyRestaurants(ctx, 43.25, 76.9, 0, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidRadius)
+
+	repo.AssertNotCalled(t, "ListNearby", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListCuisines_FillsMissingCuisines(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	repo.On("CountByCuisine", ctx).Return([]*repository.CuisineCount{
+		{CuisineType: domain.CuisineTypeKazakh, RestaurantCount: 4},
+		{CuisineType: domain.CuisineTypeItalian, RestaurantCount: 2},
+	}, nil)
+
+	cuisines, err := service.ListCuisines(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, cuisines, len(domain.CuisineTypes))
+	for i, cuisine := range cuisines {
+		assert.Equal(t, domain.CuisineTypes[i], cuisine.CuisineType)
+	}
+	counts := make(map[domain.CuisineType]int64)
+	for _, cuisine := range cuisines {
+		counts[cuisine.CuisineType] = cuisine.RestaurantCount
+	}
+	assert.Equal(t, int64(4), counts[domain.CuisineTypeKazakh])
+	assert.Equal(t, int64(2), counts[domain.CuisineTypeItalian])
+	assert.Equal(t, int64(0), counts[domain.CuisineTypeThai])
+}
+
+func TestGetRestaurants_InvalidFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter repository.RestaurantFilter
+		err    error
+	}{
+		{"unknown sort", repository.RestaurantFilter{Sort: "name"}, ErrInvalidSort},
+		{"negative price", repository.RestaurantFilter{MinPrice: intPtr(-1)}, ErrInvalidPriceRange},
+		{"inverted range", repository.RestaurantFilter{MinPrice: intPtr(5000), MaxPrice: intPtr(3000)}, ErrInvalidPriceRange},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo, _ := setupRestaurantService()
+
+			_, err := service.GetRestaurants(context.Background(), tc.filter, 10, 0)
+
+			assert.ErrorIs(t, err, tc.err)
+			repo.AssertNotCalled(t, "ListFiltered", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCountRestaurants(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+	filter := repository.RestaurantFilter{MinPrice: intPtr(2000), Sort: repository.RestaurantSortRating}
+
+	repo.On("Count", ctx, filter).Return(int64(12), nil)
+
+	total, err := service.CountRestaurants(ctx, filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+
+	_, err = service.CountRestaurants(ctx, repository.RestaurantFilter{Sort: "name"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	repo.AssertNumberOfCalls(t, "Count", 1)
+}
+
+func TestGetRestaurants_PassesFilter(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	filter := repository.RestaurantFilter{
+		MinPrice:  intPtr(3000),
+		MaxPrice:  intPtr(3000),
+		Sort:      repository.RestaurantSortRating,
+		Ascending: true,
+	}
+	list := []*domain.Restaurant{{ID: uuid.New()}}
+	repo.On("ListFiltered", ctx, filter, 10, 0).Return(list, nil)
+
+	result, err := service.GetRestaurants(ctx, filter, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, list, result)
+	repo.AssertExpectations(t)
+}
+
+func TestGetRestaurantsWithColumns_AddsIDForMainImage(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	list := []*domain.Restaurant{{ID: uuid.New()}}
+
+	repo.On("ListColumns", ctx, []string{"id", "name"}, true, repository.RestaurantFilter{}, 10, 0).Return(list, nil)
+
+	result, err := service.GetRestaurantsWithColumns(ctx, []string{"name"}, true, repository.RestaurantFilter{}, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	repo.AssertExpectations(t)
+}
+
 func TestUpdateRestaurant_Unauthorized(t *testing.T) {
 	service, repo, _ := setupRestaurantService()
 	ctx := context.Background()
@@ -204,78 +637,744 @@ func TestUpdateRestaurant_Unauthorized(t *testing.T) {
 	assert.Equal(t, ErrUnauthorized, err)
 }
 
-func TestDeleteRestaurant_Success(t *testing.T) {
+func TestUpdateRestaurant_InvalidWorkingHours(t *testing.T) {
 	service, repo, _ := setupRestaurantService()
 	ctx := context.Background()
 
 	id := uuid.New()
 	ownerID := uuid.New()
-
-	restaurant := &domain.Restaurant{
-		ID:       id,
-		OwnerID:  ownerID,
-		IsActive: true,
-	}
+	original := weekOfHours("10:00", "22:00")
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Name", WorkingHours: original}
 
 	repo.On("GetByID", ctx, id).Return(restaurant, nil)
-	repo.On("Update", ctx, restaurant).Return(nil)
 
-	err := service.DeleteRestaurant(ctx, id, ownerID)
+	hours := weekOfHours("10:00", "22:00")
+	hours["saturday"] = domain.DaySchedule{OpenTime: "18:00", CloseTime: "02:00"}
+	_, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{WorkingHours: &hours})
 
-	assert.NoError(t, err)
-	assert.False(t, restaurant.IsActive)
-	repo.AssertExpectations(t)
+	assert.ErrorIs(t, err, ErrInvalidWorkingHours)
+	assert.Equal(t, "22:00", restaurant.WorkingHours["saturday"].CloseTime)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 }
 
-func TestAddImage_Success(t *testing.T) {
-	service, repo, dbMock := setupRestaurantService()
+func TestUpdateRestaurant_RecordsConfigVersion(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
 	ctx := context.Background()
 
-	restaurantID := uuid.New()
+	id := uuid.New()
 	ownerID := uuid.New()
 
 	restaurant := &domain.Restaurant{
-		ID:      restaurantID,
-		OwnerID: ownerID,
+		ID:           id,
+		OwnerID:      ownerID,
+		Name:         "Old Name",
+		AveragePrice: 5000,
 	}
 
-	repo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	newName := "New Name"
+	newPrice := 7000
 
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
 	dbMock.ExpectBegin()
-	dbMock.ExpectQuery(`INSERT`).WillReturnRows(
-		sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()),
-	)
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, id).Return(&domain.RestaurantConfigVersion{Version: 3}, nil)
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return v.RestaurantID == id &&
+			v.ActorID == ownerID &&
+			v.Snapshot.Name == "New Name" &&
+			v.Snapshot.AveragePrice == 7000 &&
+			v.Changes == `name: "Old Name" -> "New Name"; average_price: 5000 -> 7000`
+	}), maxRestaurantConfigVersions).Return(nil)
 	dbMock.ExpectCommit()
 
-	image, err := service.AddImage(ctx, restaurantID, ownerID, AddImageRequest{
-		CloudinaryURL:      "http://image",
-		CloudinaryPublicID: "public-id",
-		IsMain:             true,
+	updated, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{
+		Name:         &newName,
+		AveragePrice: &newPrice,
 	})
 
 	assert.NoError(t, err)
-	assert.NotNil(t, image)
+	assert.Equal(t, "New Name", updated.Name)
+	versionRepo.AssertExpectations(t)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
 }
 
-func TestDeleteImage_NotFound(t *testing.T) {
-	service, repo, dbMock := setupRestaurantService()
+func TestUpdateRestaurant_CancellationPolicyBumpsVersion(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
 	ctx := context.Background()
 
-	restaurantID := uuid.New()
-	imageID := uuid.New()
+	id := uuid.New()
 	ownerID := uuid.New()
+	before := domain.CancellationPolicy{FreeCancellationHours: 24}
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Name", CancellationPolicy: before}
+	policy := domain.CancellationPolicy{FreeCancellationHours: 24, LateCancellationFee: 3000}
 
-	restaurant := &domain.Restaurant{
-		ID:      restaurantID,
-		OwnerID: ownerID,
-	}
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, id).Return(&domain.RestaurantConfigVersion{Version: 1}, nil)
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return *v.Snapshot.CancellationPolicy == policy &&
+			v.Changes == "cancellation_policy: "+before.Version()+" -> "+policy.Version()
+	}), maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
 
-	repo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	updated, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{CancellationPolicy: &policy})
 
-	dbMock.ExpectQuery(`SELECT`).WillReturnError(gorm.ErrRecordNotFound)
+	assert.NoError(t, err)
+	assert.Equal(t, policy, updated.CancellationPolicy)
+	assert.NotEqual(t, before.Version(), updated.CancellationPolicy.Version())
+	versionRepo.AssertExpectations(t)
+}
 
-	err := service.DeleteImage(ctx, imageID, restaurantID, ownerID)
+func TestUpdateRestaurant_InvalidCancellationPolicy(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
 
-	assert.Error(t, err)
-	assert.Equal(t, ErrImageNotFound, err)
+	id := uuid.New()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Name"}
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+
+	policy := domain.CancellationPolicy{DepositAmount: -1}
+	_, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{CancellationPolicy: &policy})
+
+	assert.ErrorIs(t, err, ErrInvalidCancellationPolicy)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateRestaurant_RecordsBaselineForUnversionedRestaurant(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Old Name"}
+	newName := "New Name"
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, id).Return(nil, gorm.ErrRecordNotFound)
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return v.Snapshot.Name == "Old Name" && v.Changes == "initial configuration"
+	}), maxRestaurantConfigVersions).Return(nil).Once()
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return v.Snapshot.Name == "New Name"
+	}), maxRestaurantConfigVersions).Return(nil).Once()
+	dbMock.ExpectCommit()
+
+	_, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{Name: &newName})
+
+	assert.NoError(t, err)
+	versionRepo.AssertExpectations(t)
+}
+
+func TestUpdateRestaurant_NoChangesSkipsVersion(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Same"}
+	sameName := "Same"
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	dbMock.ExpectCommit()
+
+	_, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{Name: &sameName})
+
+	assert.NoError(t, err)
+	versionRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRollbackConfig_ReappliesSnapshot(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{
+		ID:           id,
+		OwnerID:      ownerID,
+		Name:         "Broken Name",
+		AveragePrice: 1,
+		WorkingHours: domain.WorkingHours{"monday": {IsClosed: true}},
+	}
+	snapshot := domain.RestaurantConfig{
+		Name:         "Good Name",
+		AveragePrice: 5000,
+		WorkingHours: weekOfHours("10:00", "22:00"),
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	versionRepo.On("GetByVersion", ctx, id, 2).Return(&domain.RestaurantConfigVersion{Version: 2, Snapshot: snapshot}, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, id).Return(&domain.RestaurantConfigVersion{Version: 4}, nil)
+	versionRepo.On("Append", ctx, mock.MatchedBy(func(v *domain.RestaurantConfigVersion) bool {
+		return v.Snapshot.Name == "Good Name" && strings.HasPrefix(v.Changes, "rollback to version 2: ")
+	}), maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
+
+	updated, err := service.RollbackConfig(ctx, id, ownerID, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Good Name", updated.Name)
+	assert.Equal(t, 5000, updated.AveragePrice)
+	assert.Equal(t, "10:00", updated.WorkingHours["monday"].OpenTime)
+	versionRepo.AssertExpectations(t)
+}
+
+func TestRollbackConfig_InvalidSnapshotRejected(t *testing.T) {
+	service, repo, versionRepo, _ := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	repo.On("GetByID", ctx, id).Return(&domain.Restaurant{ID: id, OwnerID: ownerID, Name: "Name"}, nil)
+	versionRepo.On("GetByVersion", ctx, id, 1).Return(&domain.RestaurantConfigVersion{Version: 1, Snapshot: domain.RestaurantConfig{Name: " "}}, nil)
+
+	_, err := service.RollbackConfig(ctx, id, ownerID, 1)
+
+	assert.Equal(t, ErrInvalidRestaurantName, err)
+	versionRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRollbackConfig_VersionNotFound(t *testing.T) {
+	service, repo, versionRepo, _ := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	repo.On("GetByID", ctx, id).Return(&domain.Restaurant{ID: id, OwnerID: ownerID}, nil)
+	versionRepo.On("GetByVersion", ctx, id, 9).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.RollbackConfig(ctx, id, ownerID, 9)
+
+	assert.Equal(t, ErrConfigVersionNotFound, err)
+}
+
+func TestListConfigVersions_Unauthorized(t *testing.T) {
+	service, repo, versionRepo, _ := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+
+	id := uuid.New()
+	repo.On("GetByID", ctx, id).Return(&domain.Restaurant{ID: id, OwnerID: uuid.New()}, nil)
+
+	_, err := service.ListConfigVersions(ctx, id, uuid.New(), 20, 0)
+
+	assert.Equal(t, ErrUnauthorized, err)
+	versionRepo.AssertNotCalled(t, "ListByRestaurant", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteRestaurant_Success(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{
+		ID:       id,
+		OwnerID:  ownerID,
+		IsActive: true,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	repo.On("Update", ctx, restaurant).Return(nil)
+
+	err := service.DeleteRestaurant(ctx, id, ownerID)
+
+	assert.NoError(t, err)
+	assert.False(t, restaurant.IsActive)
+	assert.Equal(t, &ownerID, restaurant.DeactivatedBy)
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteRestaurant_AdminOverride(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	audit := new(MockAuditRecorder)
+	service.authz = NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+
+	id := uuid.New()
+	adminID := uuid.New()
+	ctx := adminContext(adminID)
+
+	restaurant := &domain.Restaurant{
+		ID:       id,
+		OwnerID:  uuid.New(),
+		IsActive: true,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	repo.On("Update", ctx, restaurant).Return(nil)
+	expectAdminOverride(audit, adminID, id, "restaurant.delete")
+
+	err := service.DeleteRestaurant(ctx, id, adminID)
+
+	assert.NoError(t, err)
+	assert.False(t, restaurant.IsActive)
+	repo.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestRestoreRestaurant_Owner(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{
+		ID:            id,
+		OwnerID:       ownerID,
+		IsActive:      false,
+		DeactivatedBy: &ownerID,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	repo.On("Update", ctx, restaurant).Return(nil)
+
+	restored, err := service.RestoreRestaurant(ctx, id, ownerID)
+
+	assert.NoError(t, err)
+	assert.True(t, restored.IsActive)
+	assert.Nil(t, restored.DeactivatedBy)
+	repo.AssertExpectations(t)
+}
+
+func TestRestoreRestaurant_AlreadyActive(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: id, OwnerID: ownerID, IsActive: true}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+
+	restored, err := service.RestoreRestaurant(ctx, id, ownerID)
+
+	assert.NoError(t, err)
+	assert.True(t, restored.IsActive)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRestoreRestaurant_DeactivatedByAdmin(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+	adminID := uuid.New()
+	restaurant := &domain.Restaurant{
+		ID:            id,
+		OwnerID:       ownerID,
+		IsActive:      false,
+		DeactivatedBy: &adminID,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+
+	_, err := service.RestoreRestaurant(ctx, id, ownerID)
+
+	assert.ErrorIs(t, err, ErrRestaurantDeactivatedByAdmin)
+	assert.False(t, restaurant.IsActive)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRestoreRestaurant_AdminRestoresAdminDeactivation(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	audit := new(MockAuditRecorder)
+	service.authz = NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+
+	id := uuid.New()
+	adminID := uuid.New()
+	ctx := adminContext(adminID)
+	restaurant := &domain.Restaurant{
+		ID:            id,
+		OwnerID:       uuid.New(),
+		IsActive:      false,
+		DeactivatedBy: &adminID,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+	repo.On("Update", ctx, restaurant).Return(nil)
+	expectAdminOverride(audit, adminID, id, "restaurant.restore")
+
+	restored, err := service.RestoreRestaurant(ctx, id, adminID)
+
+	assert.NoError(t, err)
+	assert.True(t, restored.IsActive)
+	assert.Nil(t, restored.DeactivatedBy)
+	repo.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
+
+func TestUpdateRestaurant_OwnerCannotReactivateAdminDeactivation(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	id := uuid.New()
+	ownerID := uuid.New()
+	adminID := uuid.New()
+	restaurant := &domain.Restaurant{
+		ID:            id,
+		OwnerID:       ownerID,
+		IsActive:      false,
+		DeactivatedBy: &adminID,
+	}
+
+	repo.On("GetByID", ctx, id).Return(restaurant, nil)
+
+	active := true
+	_, err := service.UpdateRestaurant(ctx, id, ownerID, UpdateRestaurantRequest{IsActive: &active})
+
+	assert.ErrorIs(t, err, ErrRestaurantDeactivatedByAdmin)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestListOwnedRestaurants_FiltersInactive(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	active := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, IsActive: true}
+	inactive := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, IsActive: false}
+
+	t.Run("active only", func(t *testing.T) {
+		service, repo, _ := setupRestaurantService()
+		repo.On("GetByOwnerID", ctx, ownerID).Return([]*domain.Restaurant{active, inactive}, nil)
+
+		restaurants, err := service.ListOwnedRestaurants(ctx, ownerID, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.Restaurant{active}, restaurants)
+	})
+
+	t.Run("include inactive", func(t *testing.T) {
+		service, repo, _ := setupRestaurantService()
+		repo.On("GetByOwnerID", ctx, ownerID).Return([]*domain.Restaurant{active, inactive}, nil)
+
+		restaurants, err := service.ListOwnedRestaurants(ctx, ownerID, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.Restaurant{active, inactive}, restaurants)
+	})
+}
+
+func TestAddImage_Success(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	dir := useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurantID := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{
+		ID:      restaurantID,
+		OwnerID: ownerID,
+	}
+
+	repo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT COALESCE\(MAX\(position\), -1\) FROM "restaurant_images"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(2))
+	dbMock.ExpectExec(`UPDATE "restaurant_images" SET "is_main"=\$1 WHERE restaurant_id = \$2 AND is_main = \$3`).
+		WithArgs(false, restaurantID, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(`INSERT`).WillReturnRows(
+		sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()),
+	)
+	dbMock.ExpectCommit()
+
+	image, err := service.AddImage(ctx, restaurantID, ownerID, AddImageRequest{
+		File:   imageFile("Photo.PNG"),
+		IsMain: true,
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, image)
+	assert.True(t, image.IsMain)
+	assert.Equal(t, 3, image.Position)
+	assert.Equal(t, []string{image.CloudinaryPublicID}, storedImages(t, dir))
+	assert.Equal(t, "/uploads/"+image.CloudinaryPublicID, image.CloudinaryURL)
+	assert.True(t, strings.HasSuffix(image.CloudinaryPublicID, ".png"))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAddImage_InsertFailureRemovesUpload(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	dir := useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT COALESCE`).WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(-1))
+	dbMock.ExpectQuery(`INSERT`).WillReturnError(errors.New("insert failed"))
+	dbMock.ExpectRollback()
+
+	image, err := service.AddImage(ctx, restaurant.ID, restaurant.OwnerID, AddImageRequest{File: imageFile("photo.jpg")})
+
+	assert.Error(t, err)
+	assert.Nil(t, image)
+	assert.Empty(t, storedImages(t, dir))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAddImage_UploadFailureSkipsDatabase(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, err := service.AddImage(ctx, restaurant.ID, restaurant.OwnerID, AddImageRequest{
+		File: imagestorage.File{Name: "empty.png", Content: strings.NewReader("")},
+	})
+
+	assert.ErrorIs(t, err, imagestorage.ErrEmptyFile)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAddImages_ReportsEachFile(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	dir := useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	for position := 0; position < 2; position++ {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(`SELECT COALESCE`).WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(position - 1))
+		dbMock.ExpectQuery(`INSERT`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		dbMock.ExpectCommit()
+	}
+
+	results, err := service.AddImages(ctx, restaurant.ID, restaurant.OwnerID, []imagestorage.File{
+		imageFile("first.png"),
+		{Name: "empty.png", Content: strings.NewReader("")},
+		imageFile("second.png"),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 0, results[0].Image.Position)
+	assert.False(t, results[0].Image.IsMain)
+	assert.ErrorIs(t, results[1].Err, imagestorage.ErrEmptyFile)
+	assert.Nil(t, results[1].Image)
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, 1, results[2].Image.Position)
+	assert.Len(t, storedImages(t, dir), 2)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAddImages_Unauthorized(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	dir := useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	results, err := service.AddImages(ctx, restaurant.ID, uuid.New(), []imagestorage.File{imageFile("photo.png")})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Nil(t, results)
+	assert.Empty(t, storedImages(t, dir))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAddImage_NotMainKeepsCurrentMain(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT COALESCE`).WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(-1))
+	dbMock.ExpectQuery(`INSERT`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	dbMock.ExpectCommit()
+
+	image, err := service.AddImage(ctx, restaurant.ID, restaurant.OwnerID, AddImageRequest{File: imageFile("photo.jpg")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, image.Position)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestSetMainImage_UnsetsOtherImagesFirst(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	imageID := uuid.New()
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT \* FROM "restaurant_images" WHERE id = \$1 AND restaurant_id = \$2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "is_main"}).AddRow(imageID, restaurant.ID, false))
+	dbMock.ExpectExec(`UPDATE "restaurant_images" SET "is_main"=\$1 WHERE restaurant_id = \$2 AND is_main = \$3`).
+		WithArgs(false, restaurant.ID, true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	dbMock.ExpectExec(`UPDATE "restaurant_images" SET "is_main"=\$1 WHERE "id" = \$2`).
+		WithArgs(true, imageID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	image, err := service.SetMainImage(ctx, imageID, restaurant.ID, restaurant.OwnerID)
+
+	assert.NoError(t, err)
+	assert.True(t, image.IsMain)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestSetMainImage_NotFound(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT`).WillReturnError(gorm.ErrRecordNotFound)
+	dbMock.ExpectRollback()
+
+	_, err := service.SetMainImage(ctx, uuid.New(), restaurant.ID, restaurant.OwnerID)
+
+	assert.ErrorIs(t, err, ErrImageNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReorderImages_StoresPositions(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	first, second := uuid.New(), uuid.New()
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT \* FROM "restaurant_images" WHERE restaurant_id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "position"}).
+			AddRow(first, restaurant.ID, 0).
+			AddRow(second, restaurant.ID, 1))
+	dbMock.ExpectExec(`UPDATE "restaurant_images" SET "position"=\$1 WHERE "id" = \$2`).
+		WithArgs(0, second).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(`UPDATE "restaurant_images" SET "position"=\$1 WHERE "id" = \$2`).
+		WithArgs(1, first).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	images, err := service.ReorderImages(ctx, restaurant.ID, restaurant.OwnerID, []uuid.UUID{second, first})
+
+	assert.NoError(t, err)
+	if assert.Len(t, images, 2) {
+		assert.Equal(t, second, images[0].ID)
+		assert.Equal(t, 1, images[1].Position)
+	}
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReorderImages_InvalidOrder(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	cases := []struct {
+		name string
+		ids  []uuid.UUID
+	}{
+		{"missing image", []uuid.UUID{first}},
+		{"unknown image", []uuid.UUID{first, uuid.New()}},
+		{"duplicate image", []uuid.UUID{first, first}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo, dbMock := setupRestaurantService()
+			ctx := context.Background()
+
+			restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+			repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+			dbMock.ExpectBegin()
+			dbMock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+			dbMock.ExpectQuery(`SELECT`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+			dbMock.ExpectRollback()
+
+			_, err := service.ReorderImages(ctx, restaurant.ID, restaurant.OwnerID, tc.ids)
+
+			assert.ErrorIs(t, err, ErrInvalidImageOrder)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteImage_NotFound(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	ctx := context.Background()
+
+	restaurantID := uuid.New()
+	imageID := uuid.New()
+	ownerID := uuid.New()
+
+	restaurant := &domain.Restaurant{
+		ID:      restaurantID,
+		OwnerID: ownerID,
+	}
+
+	repo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+
+	dbMock.ExpectQuery(`SELECT`).WillReturnError(gorm.ErrRecordNotFound)
+
+	err := service.DeleteImage(ctx, imageID, restaurantID, ownerID)
+
+	assert.Error(t, err)
+	assert.Equal(t, ErrImageNotFound, err)
+}
+
+func TestDeleteImage_RemovesStoredFile(t *testing.T) {
+	service, repo, dbMock := setupRestaurantService()
+	dir := useLocalImages(t, service)
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	imageID := uuid.New()
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, publicID, err := service.images.Upload(ctx, imageFile("photo.png"))
+	assert.NoError(t, err)
+	other := filepath.Join(dir, "other.png")
+	assert.NoError(t, os.WriteFile(other, []byte("x"), 0o644))
+
+	dbMock.ExpectQuery(`SELECT \* FROM "restaurant_images" WHERE id = \$1 AND restaurant_id = \$2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id", "cloudinary_public_id"}).AddRow(imageID, restaurant.ID, publicID))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`DELETE FROM "restaurant_images"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	err = service.DeleteImage(ctx, imageID, restaurant.ID, restaurant.OwnerID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other.png"}, storedImages(t, dir))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
 }
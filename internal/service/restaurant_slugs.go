@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/slug"
+
+	"github.com/google/uuid"
+)
+
+// ErrRestaurantSlugTaken is returned when another restaurant saved the same
+// slug first. Trying again picks the next free one.
+var ErrRestaurantSlugTaken = repository.ErrRestaurantSlugTaken
+
+// fallbackSlug is the slug of restaurants whose name has nothing that
+// transliterates to ASCII letters or digits.
+const fallbackSlug = "restaurant"
+
+// reservedSlugs are the fixed paths under /api/restaurants, which win over
+// GET /api/restaurants/:id.
+var reservedSlugs = []string{"search", "nearby", "cuisines"}
+
+// restaurantSlugBase is the slug a restaurant named name gets unless another
+// has it already.
+func restaurantSlugBase(name string) string {
+	base := slug.Make(name)
+	if base == "" {
+		return fallbackSlug
+	}
+	// GetRestaurant reads anything that parses as a UUID as an ID.
+	if _, err := uuid.Parse(base); err == nil {
+		return fallbackSlug + "-" + base
+	}
+	return base
+}
+
+// pickSlug returns a slug for the restaurant id named name that no other
+// restaurant has. id is uuid.Nil for one that does not exist yet.
+func pickSlug(ctx context.Context, repo repository.RestaurantRepository, id uuid.UUID, name string) (string, error) {
+	base := restaurantSlugBase(name)
+	taken, err := repo.ListSlugs(ctx, base, id)
+	if err != nil {
+		return "", err
+	}
+	return slug.Unique(base, append(taken, reservedSlugs...)), nil
+}
+
+// JobKindAssignSlugs gives every restaurant created before slugs existed
+// one made from its name.
+const JobKindAssignSlugs = "assign_restaurant_slugs"
+
+const slugBackfillBatchSize = 100
+
+// slugBackfill walks the restaurants without a slug by ID. Its cursor is the
+// last ID it gave a slug.
+type slugBackfill struct {
+	restaurantRepo repository.RestaurantRepository
+}
+
+func NewSlugBackfill(restaurantRepo repository.RestaurantRepository) Backfill {
+	return &slugBackfill{restaurantRepo: restaurantRepo}
+}
+
+func (b *slugBackfill) Kind() string {
+	return JobKindAssignSlugs
+}
+
+func (b *slugBackfill) Remaining(ctx context.Context, cursor string) (int64, error) {
+	after, err := parseRestaurantCursor(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return b.restaurantRepo.CountWithoutSlug(ctx, after)
+}
+
+func (b *slugBackfill) Step(ctx context.Context, cursor string) (string, int, error) {
+	after, err := parseRestaurantCursor(cursor)
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	restaurants, err := b.restaurantRepo.ListWithoutSlug(ctx, after, slugBackfillBatchSize)
+	if err != nil {
+		return cursor, 0, err
+	}
+	for _, restaurant := range restaurants {
+		picked, err := pickSlug(ctx, b.restaurantRepo, restaurant.ID, restaurant.Name)
+		if err != nil {
+			return cursor, 0, err
+		}
+		if err := b.restaurantRepo.SetSlug(ctx, restaurant.ID, picked); err != nil {
+			return cursor, 0, err
+		}
+	}
+	if len(restaurants) == 0 {
+		return cursor, 0, nil
+	}
+	return restaurants[len(restaurants)-1].ID.String(), len(restaurants), nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func slugOf(s string) *string {
+	return &s
+}
+
+func TestRestaurantSlugBase(t *testing.T) {
+	cases := map[string]string{
+		"La Piazza Almaty":                     "la-piazza-almaty",
+		"Ла Пьяцца":                            "la-pyatstsa",
+		"★ ★ ★":                                "restaurant",
+		"寿司":                                   "restaurant",
+		"123e4567-e89b-12d3-a456-426614174000": "restaurant-123e4567-e89b-12d3-a456-426614174000",
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, restaurantSlugBase(name), name)
+	}
+}
+
+func TestPickSlug(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	cases := []struct {
+		name  string
+		base  string
+		taken []string
+		want  string
+	}{
+		{"Navat", "navat", nil, "navat"},
+		{"Navat", "navat", []string{"navat", "navat-2", "navat-almaty"}, "navat-3"},
+		{"寿司", "restaurant", []string{"restaurant"}, "restaurant-2"},
+		{"Search", "search", nil, "search-2"},
+	}
+	for _, tc := range cases {
+		repo := new(MockRestaurantRepository)
+		repo.On("ListSlugs", ctx, tc.base, id).Return(tc.taken, nil)
+
+		picked, err := pickSlug(ctx, repo, id, tc.name)
+
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, picked, tc.name)
+	}
+}
+
+func TestUpdateRestaurant_RenameKeepsSlug(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Name: "Navat", Slug: slugOf("navat")}
+	newName := "Navat Almaty"
+
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, restaurant.ID).Return(&domain.RestaurantConfigVersion{Version: 1}, nil)
+	versionRepo.On("Append", ctx, mock.Anything, maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
+
+	updated, err := service.UpdateRestaurant(ctx, restaurant.ID, restaurant.OwnerID, UpdateRestaurantRequest{Name: &newName})
+
+	require.NoError(t, err)
+	assert.Equal(t, "navat", *updated.Slug)
+	repo.AssertNotCalled(t, "ListSlugs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRestaurant_RegenerateSlug(t *testing.T) {
+	service, repo, versionRepo, dbMock := setupRestaurantServiceWithVersions()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Name: "Navat", Slug: slugOf("navat-2")}
+	newName := "Чайхана Нават"
+
+	repo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	repo.On("ListSlugs", ctx, "chaykhana-navat", restaurant.ID).Return([]string{"chaykhana-navat"}, nil)
+	dbMock.ExpectBegin()
+	repo.On("Update", ctx, restaurant).Return(nil)
+	versionRepo.On("Latest", ctx, restaurant.ID).Return(&domain.RestaurantConfigVersion{Version: 1}, nil)
+	versionRepo.On("Append", ctx, mock.Anything, maxRestaurantConfigVersions).Return(nil)
+	dbMock.ExpectCommit()
+
+	updated, err := service.UpdateRestaurant(ctx, restaurant.ID, restaurant.OwnerID, UpdateRestaurantRequest{Name: &newName, RegenerateSlug: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "chaykhana-navat-2", *updated.Slug)
+}
+
+func TestGetRestaurantBySlug_NotFound(t *testing.T) {
+	service, repo, _ := setupRestaurantService()
+	ctx := context.Background()
+
+	repo.On("GetBySlug", ctx, "navat").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.GetRestaurantBySlug(ctx, "navat")
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+}
+
+func TestSlugBackfill_Step(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRestaurantRepository)
+	first := &domain.Restaurant{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Name: "Navat"}
+	second := &domain.Restaurant{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Name: "!!!"}
+
+	repo.On("ListWithoutSlug", ctx, uuid.Nil, slugBackfillBatchSize).Return([]*domain.Restaurant{first, second}, nil)
+	repo.On("ListSlugs", ctx, "navat", first.ID).Return([]string{"navat"}, nil)
+	repo.On("ListSlugs", ctx, "restaurant", second.ID).Return([]string{}, nil)
+	repo.On("SetSlug", ctx, first.ID, "navat-2").Return(nil)
+	repo.On("SetSlug", ctx, second.ID, "restaurant").Return(nil)
+
+	next, processed, err := NewSlugBackfill(repo).Step(ctx, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, second.ID.String(), next)
+	repo.AssertExpectations(t)
+}
+
+func TestSlugBackfill_StepFailureKeepsCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRestaurantRepository)
+	after := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), Name: "Navat"}
+
+	repo.On("ListWithoutSlug", ctx, after, slugBackfillBatchSize).Return([]*domain.Restaurant{restaurant}, nil)
+	repo.On("ListSlugs", ctx, "navat", restaurant.ID).Return([]string{}, nil)
+	repo.On("SetSlug", ctx, restaurant.ID, "navat").Return(ErrRestaurantSlugTaken)
+
+	next, processed, err := NewSlugBackfill(repo).Step(ctx, after.String())
+
+	assert.ErrorIs(t, err, ErrRestaurantSlugTaken)
+	assert.Equal(t, 0, processed)
+	assert.Equal(t, after.String(), next)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultStatsRange is how far back the statistics reach when no range is
+// given.
+const DefaultStatsRange = 30 * 24 * time.Hour
+
+var ErrInvalidStatsRange = errors.New("from must be before to")
+
+// RestaurantStats is a restaurant's dashboard. BookingsToday and
+// BookingsThisWeek count the bookings that are not cancelled starting on the
+// current day and in the current Monday-to-Sunday week on the restaurant's
+// clock; the other figures cover bookings and payments in [From, To).
+type RestaurantStats struct {
+	RestaurantID      uuid.UUID
+	From              time.Time
+	To                time.Time
+	BookingsToday     int64
+	BookingsThisWeek  int64
+	Bookings          int64
+	CancelledBookings int64
+	// CancellationRate is CancelledBookings / Bookings, or 0 without
+	// bookings.
+	CancellationRate float64
+	// AveragePartySize leaves out cancelled bookings.
+	AveragePartySize float64
+	// Revenue sums the completed payments made in the range, in KZT,
+	// without service fees.
+	Revenue int64
+}
+
+type RestaurantStatsService interface {
+	// Stats computes the dashboard of a restaurant for people who can
+	// manage it. A zero to means now and a zero from DefaultStatsRange
+	// before to.
+	Stats(ctx context.Context, restaurantID, userID uuid.UUID, from, to time.Time) (*RestaurantStats, error)
+}
+
+type restaurantStatsService struct {
+	bookingRepo    repository.BookingRepository
+	paymentRepo    repository.PaymentRepository
+	restaurantRepo repository.RestaurantRepository
+	authz          RestaurantAuthorizer
+	now            func() time.Time
+}
+
+func NewRestaurantStatsService(
+	bookingRepo repository.BookingRepository,
+	paymentRepo repository.PaymentRepository,
+	restaurantRepo repository.RestaurantRepository,
+	authz RestaurantAuthorizer,
+) RestaurantStatsService {
+	return &restaurantStatsService{
+		bookingRepo:    bookingRepo,
+		paymentRepo:    paymentRepo,
+		restaurantRepo: restaurantRepo,
+		authz:          authz,
+		now:            time.Now,
+	}
+}
+
+func (s *restaurantStatsService) Stats(ctx context.Context, restaurantID, userID uuid.UUID, from, to time.Time) (*RestaurantStats, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, userID, "stats.view"); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultStatsRange)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidStatsRange
+	}
+
+	local := now.In(restaurant.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	// Weekday counts from Sunday; the week starts on Monday.
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	stats := &RestaurantStats{RestaurantID: restaurantID, From: from, To: to}
+	var todayCounts, weekCounts, rangeCounts map[domain.BookingStatus]int64
+
+	// The queries are independent, so they run side by side and the first
+	// error wins.
+	queries := []func() error{
+		func() (err error) {
+			todayCounts, err = s.bookingRepo.CountByStatus(ctx, restaurantID, today, today.AddDate(0, 0, 1))
+			return err
+		},
+		func() (err error) {
+			weekCounts, err = s.bookingRepo.CountByStatus(ctx, restaurantID, weekStart, weekStart.AddDate(0, 0, 7))
+			return err
+		},
+		func() (err error) {
+			rangeCounts, err = s.bookingRepo.CountByStatus(ctx, restaurantID, from, to)
+			return err
+		},
+		func() (err error) {
+			stats.AveragePartySize, err = s.bookingRepo.AverageGuestCount(ctx, restaurantID, from, to)
+			return err
+		},
+		func() (err error) {
+			stats.Revenue, err = s.paymentRepo.RestaurantRevenue(ctx, restaurantID, from, to)
+			return err
+		},
+	}
+
+	errs := make(chan error, len(queries))
+	var wg sync.WaitGroup
+	for _, query := range queries {
+		wg.Add(1)
+		go func(query func() error) {
+			defer wg.Done()
+			errs <- query()
+		}(query)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats.BookingsToday = sumCounts(todayCounts, domain.BookingStatusCancelled)
+	stats.BookingsThisWeek = sumCounts(weekCounts, domain.BookingStatusCancelled)
+	stats.Bookings = sumCounts(rangeCounts)
+	stats.CancelledBookings = rangeCounts[domain.BookingStatusCancelled]
+	if stats.Bookings > 0 {
+		stats.CancellationRate = float64(stats.CancelledBookings) / float64(stats.Bookings)
+	}
+	return stats, nil
+}
+
+// sumCounts adds up the counts of every status except the excluded ones.
+func sumCounts(counts map[domain.BookingStatus]int64, exclude ...domain.BookingStatus) int64 {
+	var total int64
+	for status, count := range counts {
+		if !slices.Contains(exclude, status) {
+			total += count
+		}
+	}
+	return total
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// statsNow is Wednesday 5 June 2024, 21:00 UTC, which is already Thursday
+// 02:00 in Tashkent (UTC+5 all year).
+var statsNow = time.Date(2024, time.June, 5, 21, 0, 0, 0, time.UTC)
+
+func setupRestaurantStatsService(restaurant *domain.Restaurant) (*restaurantStatsService, *BookingMockBookingRepository, *MockPaymentRepository) {
+	bookingRepo := new(BookingMockBookingRepository)
+	paymentRepo := new(MockPaymentRepository)
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil).Maybe()
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+
+	svc := NewRestaurantStatsService(bookingRepo, paymentRepo, restaurantRepo, authz).(*restaurantStatsService)
+	svc.now = func() time.Time { return statsNow }
+	return svc, bookingRepo, paymentRepo
+}
+
+func TestRestaurantStats(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New(), Timezone: "Asia/Tashkent"}
+	svc, bookingRepo, paymentRepo := setupRestaurantStatsService(restaurant)
+	ctx := context.Background()
+
+	today := time.Date(2024, time.June, 5, 19, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, time.June, 2, 19, 0, 0, 0, time.UTC)
+	from := statsNow.Add(-DefaultStatsRange)
+
+	bookingRepo.On("CountByStatus", ctx, restaurant.ID, mock.MatchedBy(today.Equal), mock.MatchedBy(today.AddDate(0, 0, 1).Equal)).
+		Return(map[domain.BookingStatus]int64{domain.BookingStatusConfirmed: 3, domain.BookingStatusCancelled: 1}, nil)
+	bookingRepo.On("CountByStatus", ctx, restaurant.ID, mock.MatchedBy(monday.Equal), mock.MatchedBy(monday.AddDate(0, 0, 7).Equal)).
+		Return(map[domain.BookingStatus]int64{domain.BookingStatusConfirmed: 8, domain.BookingStatusCompleted: 4, domain.BookingStatusCancelled: 2}, nil)
+	bookingRepo.On("CountByStatus", ctx, restaurant.ID, from, statsNow).
+		Return(map[domain.BookingStatus]int64{domain.BookingStatusCompleted: 30, domain.BookingStatusNoShow: 2, domain.BookingStatusCancelled: 8}, nil)
+	bookingRepo.On("AverageGuestCount", ctx, restaurant.ID, from, statsNow).Return(3.5, nil)
+	paymentRepo.On("RestaurantRevenue", ctx, restaurant.ID, from, statsNow).Return(int64(420000), nil)
+
+	stats, err := svc.Stats(ctx, restaurant.ID, restaurant.OwnerID, time.Time{}, time.Time{})
+
+	require.NoError(t, err)
+	assert.Equal(t, from, stats.From)
+	assert.Equal(t, statsNow, stats.To)
+	assert.Equal(t, int64(3), stats.BookingsToday)
+	assert.Equal(t, int64(12), stats.BookingsThisWeek)
+	assert.Equal(t, int64(40), stats.Bookings)
+	assert.Equal(t, int64(8), stats.CancelledBookings)
+	assert.InDelta(t, 0.2, stats.CancellationRate, 1e-9)
+	assert.Equal(t, 3.5, stats.AveragePartySize)
+	assert.Equal(t, int64(420000), stats.Revenue)
+	bookingRepo.AssertExpectations(t)
+	paymentRepo.AssertExpectations(t)
+}
+
+func TestRestaurantStats_NoBookings(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	svc, bookingRepo, paymentRepo := setupRestaurantStatsService(restaurant)
+
+	bookingRepo.On("CountByStatus", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(map[domain.BookingStatus]int64{}, nil)
+	bookingRepo.On("AverageGuestCount", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(0.0, nil)
+	paymentRepo.On("RestaurantRevenue", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	stats, err := svc.Stats(context.Background(), restaurant.ID, restaurant.OwnerID, time.Time{}, time.Time{})
+
+	require.NoError(t, err)
+	assert.Zero(t, stats.Bookings)
+	assert.Zero(t, stats.CancellationRate)
+}
+
+func TestRestaurantStats_QueryError(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	svc, bookingRepo, paymentRepo := setupRestaurantStatsService(restaurant)
+	dbErr := errors.New("connection reset")
+
+	bookingRepo.On("CountByStatus", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(map[domain.BookingStatus]int64{}, nil)
+	bookingRepo.On("AverageGuestCount", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(0.0, nil)
+	paymentRepo.On("RestaurantRevenue", mock.Anything, restaurant.ID, mock.Anything, mock.Anything).Return(int64(0), dbErr)
+
+	_, err := svc.Stats(context.Background(), restaurant.ID, restaurant.OwnerID, time.Time{}, time.Time{})
+
+	assert.ErrorIs(t, err, dbErr)
+}
+
+func TestRestaurantStats_InvalidRange(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	svc, _, _ := setupRestaurantStatsService(restaurant)
+
+	_, err := svc.Stats(context.Background(), restaurant.ID, restaurant.OwnerID, statsNow, statsNow.Add(-time.Hour))
+
+	assert.ErrorIs(t, err, ErrInvalidStatsRange)
+}
+
+func TestRestaurantStats_RequiresManager(t *testing.T) {
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	svc, _, _ := setupRestaurantStatsService(restaurant)
+
+	_, err := svc.Stats(context.Background(), restaurant.ID, uuid.New(), time.Time{}, time.Time{})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestRestaurantStats_RestaurantNotFound(t *testing.T) {
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantID := uuid.New()
+	restaurantRepo.On("GetByID", mock.Anything, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+	authz := NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder))
+	svc := NewRestaurantStatsService(new(BookingMockBookingRepository), new(MockPaymentRepository), restaurantRepo, authz)
+
+	_, err := svc.Stats(context.Background(), restaurantID, uuid.New(), time.Time{}, time.Time{})
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+}
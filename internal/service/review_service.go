@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewWindow is how long a user has to wait before posting another review
+// for the same restaurant. Within it they edit their existing review instead.
+const ReviewWindow = 30 * 24 * time.Hour
+
+var (
+	ErrReviewLimitReached = errors.New("restaurant already reviewed in the last 30 days, edit the existing review instead")
+	ErrReviewNotFound     = errors.New("review not found")
+	ErrNotReviewAuthor    = errors.New("forbidden: not the author of this review")
+)
+
+type CreateReviewRequest struct {
+	RestaurantID uuid.UUID
+	BookingID    *uuid.UUID
+	Rating       int
+	Comment      string
+}
+
+// ReviewService owns every change to reviews. Each change recomputes the
+// restaurant's rating and reviews_count in the same transaction.
+type ReviewService interface {
+	// CreateReview returns the existing review together with
+	// ErrReviewLimitReached when the user reviewed the restaurant within
+	// ReviewWindow.
+	CreateReview(ctx context.Context, userID uuid.UUID, req CreateReviewRequest) (*domain.Review, error)
+	// UpsertMyReview edits the user's review still inside ReviewWindow, or
+	// creates a new one. created reports which happened.
+	UpsertMyReview(ctx context.Context, userID, restaurantID uuid.UUID, rating int, comment string) (review *domain.Review, created bool, err error)
+	// UpdateReview changes the fields that are not nil on behalf of userID.
+	// Only the review's author and admins may, anyone else gets
+	// ErrNotReviewAuthor.
+	UpdateReview(ctx context.Context, id, userID uuid.UUID, rating *int, comment *string) (*domain.Review, error)
+	// DeleteReview deletes the review on behalf of userID, allowed like
+	// UpdateReview.
+	DeleteReview(ctx context.Context, id, userID uuid.UUID) error
+	// SetReviewVisibility hides or shows a review. Hidden reviews do not
+	// count towards the restaurant rating.
+	SetReviewVisibility(ctx context.Context, id uuid.UUID, visible bool) (*domain.Review, error)
+}
+
+type reviewService struct {
+	reviewRepo     repository.ReviewRepository
+	restaurantRepo repository.RestaurantRepository
+	db             *gorm.DB
+	log            logger.Logger
+}
+
+func NewReviewService(reviewRepo repository.ReviewRepository, restaurantRepo repository.RestaurantRepository, db *gorm.DB, log logger.Logger) ReviewService {
+	return &reviewService{
+		reviewRepo:     reviewRepo,
+		restaurantRepo: restaurantRepo,
+		db:             db,
+		log:            log,
+	}
+}
+
+func (s *reviewService) CreateReview(ctx context.Context, userID uuid.UUID, req CreateReviewRequest) (*domain.Review, error) {
+	if _, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	existing, err := s.reviewInWindow(ctx, userID, req.RestaurantID, now)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, ErrReviewLimitReached
+	}
+
+	review := &domain.Review{
+		RestaurantID: req.RestaurantID,
+		UserID:       userID,
+		BookingID:    req.BookingID,
+		Rating:       req.Rating,
+		Comment:      req.Comment,
+		IsVisible:    true,
+		CreatedAt:    now,
+		WindowEndsAt: now.Add(ReviewWindow),
+	}
+
+	err = s.withRatingRefresh(ctx, req.RestaurantID, func(reviewRepo repository.ReviewRepository) error {
+		return reviewRepo.Create(ctx, review)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrReviewWindowOverlap) {
+			// A concurrent request won the race past the check above.
+			existing, findErr := s.reviewRepo.GetLatestByUserAndRestaurant(ctx, userID, req.RestaurantID)
+			if findErr != nil {
+				return nil, findErr
+			}
+			return existing, ErrReviewLimitReached
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *reviewService) UpsertMyReview(ctx context.Context, userID, restaurantID uuid.UUID, rating int, comment string) (*domain.Review, bool, error) {
+	existing, err := s.reviewInWindow(ctx, userID, restaurantID, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		review, err := s.CreateReview(ctx, userID, CreateReviewRequest{
+			RestaurantID: restaurantID,
+			Rating:       rating,
+			Comment:      comment,
+		})
+		if errors.Is(err, ErrReviewLimitReached) {
+			existing = review
+		} else {
+			return review, err == nil, err
+		}
+	}
+
+	existing.Rating = rating
+	existing.Comment = comment
+	err = s.withRatingRefresh(ctx, restaurantID, func(reviewRepo repository.ReviewRepository) error {
+		return reviewRepo.Update(ctx, existing)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (s *reviewService) UpdateReview(ctx context.Context, id, userID uuid.UUID, rating *int, comment *string) (*domain.Review, error) {
+	review, err := s.getAuthoredReview(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rating != nil {
+		review.Rating = *rating
+	}
+	if comment != nil {
+		review.Comment = *comment
+	}
+
+	err = s.withRatingRefresh(ctx, review.RestaurantID, func(reviewRepo repository.ReviewRepository) error {
+		return reviewRepo.Update(ctx, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *reviewService) DeleteReview(ctx context.Context, id, userID uuid.UUID) error {
+	review, err := s.getAuthoredReview(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.withRatingRefresh(ctx, review.RestaurantID, func(reviewRepo repository.ReviewRepository) error {
+		return reviewRepo.Delete(ctx, id)
+	})
+}
+
+func (s *reviewService) SetReviewVisibility(ctx context.Context, id uuid.UUID, visible bool) (*domain.Review, error) {
+	review, err := s.getReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review.IsVisible = visible
+	err = s.withRatingRefresh(ctx, review.RestaurantID, func(reviewRepo repository.ReviewRepository) error {
+		return reviewRepo.Update(ctx, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// getAuthoredReview returns the review if userID wrote it or is an admin.
+func (s *reviewService) getAuthoredReview(ctx context.Context, id, userID uuid.UUID) (*domain.Review, error) {
+	review, err := s.getReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID && !isAdmin(ctx, userID) {
+		return nil, ErrNotReviewAuthor
+	}
+	return review, nil
+}
+
+func (s *reviewService) getReview(ctx context.Context, id uuid.UUID) (*domain.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+// reviewInWindow returns the user's latest review of the restaurant if its
+// window is still open at now, or nil.
+func (s *reviewService) reviewInWindow(ctx context.Context, userID, restaurantID uuid.UUID, now time.Time) (*domain.Review, error) {
+	latest, err := s.reviewRepo.GetLatestByUserAndRestaurant(ctx, userID, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !now.Before(latest.WindowEndsAt) {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// withRatingRefresh runs fn and recomputes the restaurant rating in one
+// transaction, so the aggregate never disagrees with the reviews.
+func (s *reviewService) withRatingRefresh(ctx context.Context, restaurantID uuid.UUID, fn func(reviewRepo repository.ReviewRepository) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reviewRepo := s.reviewRepo.WithTx(tx)
+		if err := fn(reviewRepo); err != nil {
+			return err
+		}
+		return reviewRepo.RefreshRestaurantRating(ctx, restaurantID)
+	})
+}
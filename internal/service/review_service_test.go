@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(ctx context.Context, review *domain.Review) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Review, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID, limit, offset int) ([]*domain.Review, error) {
+	args := m.Called(ctx, restaurantID, limit, offset)
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) CountByRestaurantID(ctx context.Context, restaurantID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, restaurantID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.Review, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetLatestByUserAndRestaurant(ctx context.Context, userID, restaurantID uuid.UUID) (*domain.Review, error) {
+	args := m.Called(ctx, userID, restaurantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Update(ctx context.Context, review *domain.Review) error {
+	args := m.Called(ctx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) RefreshRestaurantRating(ctx context.Context, restaurantID uuid.UUID) error {
+	args := m.Called(ctx, restaurantID)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) RefreshRestaurantRatings(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockReviewRepository) CountRestaurantsAfter(ctx context.Context, after uuid.UUID) (int64, error) {
+	args := m.Called(ctx, after)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReviewRepository) WithTx(tx *gorm.DB) repository.ReviewRepository {
+	return m
+}
+
+func setupReviewService(restaurantID uuid.UUID) (ReviewService, *MockReviewRepository, sqlmock.Sqlmock) {
+	reviewRepo := new(MockReviewRepository)
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantRepo.On("GetByID", mock.Anything, restaurantID).Return(&domain.Restaurant{ID: restaurantID}, nil).Maybe()
+
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+
+	return NewReviewService(reviewRepo, restaurantRepo, db, zap.NewNop()), reviewRepo, sqlMock
+}
+
+func TestCreateReview_Success(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+	reviewRepo.On("Create", ctx, mock.AnythingOfType("*domain.Review")).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	review, err := service.CreateReview(ctx, userID, CreateReviewRequest{RestaurantID: restaurantID, Rating: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, review.UserID)
+	assert.Equal(t, ReviewWindow, review.WindowEndsAt.Sub(review.CreatedAt))
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateReview_WithinWindow(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, _ := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), WindowEndsAt: time.Now().Add(24 * time.Hour)}
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(existing, nil)
+
+	review, err := service.CreateReview(ctx, userID, CreateReviewRequest{RestaurantID: restaurantID, Rating: 1})
+
+	assert.ErrorIs(t, err, ErrReviewLimitReached)
+	assert.Equal(t, existing.ID, review.ID)
+	reviewRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateReview_AfterWindow(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).
+		Return(&domain.Review{ID: uuid.New(), WindowEndsAt: time.Now().Add(-time.Hour)}, nil)
+	reviewRepo.On("Create", ctx, mock.AnythingOfType("*domain.Review")).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	_, err := service.CreateReview(ctx, userID, CreateReviewRequest{RestaurantID: restaurantID, Rating: 5})
+
+	assert.NoError(t, err)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateReview_ConcurrentOverlap(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	winner := &domain.Review{ID: uuid.New(), WindowEndsAt: time.Now().Add(ReviewWindow)}
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(nil, gorm.ErrRecordNotFound).Once()
+	reviewRepo.On("Create", ctx, mock.AnythingOfType("*domain.Review")).Return(repository.ErrReviewWindowOverlap)
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(winner, nil).Once()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	review, err := service.CreateReview(ctx, userID, CreateReviewRequest{RestaurantID: restaurantID, Rating: 1})
+
+	assert.ErrorIs(t, err, ErrReviewLimitReached)
+	assert.Equal(t, winner.ID, review.ID)
+	reviewRepo.AssertNotCalled(t, "RefreshRestaurantRating", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateReview_RestaurantNotFound(t *testing.T) {
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	reviewRepo := new(MockReviewRepository)
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	restaurantRepo.On("GetByID", ctx, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := NewReviewService(reviewRepo, restaurantRepo, nil, zap.NewNop()).
+		CreateReview(ctx, uuid.New(), CreateReviewRequest{RestaurantID: restaurantID, Rating: 3})
+
+	assert.Equal(t, ErrRestaurantNotFound, err)
+}
+
+func TestUpsertMyReview_EditsReviewInWindow(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), Rating: 2, Comment: "slow", WindowEndsAt: time.Now().Add(time.Hour)}
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(existing, nil)
+	reviewRepo.On("Update", ctx, existing).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	review, created, err := service.UpsertMyReview(ctx, userID, restaurantID, 4, "better now")
+
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, existing.ID, review.ID)
+	assert.Equal(t, 4, review.Rating)
+	assert.Equal(t, "better now", review.Comment)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpsertMyReview_CreatesWhenNoneInWindow(t *testing.T) {
+	ctx := context.Background()
+	userID, restaurantID := uuid.New(), uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	reviewRepo.On("GetLatestByUserAndRestaurant", ctx, userID, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+	reviewRepo.On("Create", ctx, mock.AnythingOfType("*domain.Review")).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	review, created, err := service.UpsertMyReview(ctx, userID, restaurantID, 5, "")
+
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 5, review.Rating)
+	reviewRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateReview_RefreshesRatingInTransaction(t *testing.T) {
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), RestaurantID: restaurantID, Rating: 2, Comment: "cold"}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	reviewRepo.On("Update", ctx, existing).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	rating := 5
+	review, err := service.UpdateReview(ctx, existing.ID, existing.UserID, &rating, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, review.Rating)
+	assert.Equal(t, "cold", review.Comment)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateReview_RollsBackWhenRefreshFails(t *testing.T) {
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), RestaurantID: restaurantID, Rating: 2}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	reviewRepo.On("Update", ctx, existing).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(errors.New("connection reset"))
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	rating := 4
+	_, err := service.UpdateReview(ctx, existing.ID, existing.UserID, &rating, nil)
+
+	assert.Error(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateReview_NotFound(t *testing.T) {
+	ctx := context.Background()
+	service, reviewRepo, _ := setupReviewService(uuid.New())
+
+	id := uuid.New()
+	reviewRepo.On("GetByID", ctx, id).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.UpdateReview(ctx, id, uuid.New(), nil, nil)
+
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+	reviewRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDeleteReview_RefreshesRatingInTransaction(t *testing.T) {
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), RestaurantID: restaurantID}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	reviewRepo.On("Delete", ctx, existing.ID).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	err := service.DeleteReview(ctx, existing.ID, existing.UserID)
+
+	require.NoError(t, err)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateReview_NotAuthor(t *testing.T) {
+	ctx := context.Background()
+	service, reviewRepo, _ := setupReviewService(uuid.New())
+
+	existing := &domain.Review{ID: uuid.New(), UserID: uuid.New(), Rating: 5}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+
+	rating := 1
+	_, err := service.UpdateReview(ctx, existing.ID, uuid.New(), &rating, nil)
+
+	assert.ErrorIs(t, err, ErrNotReviewAuthor)
+	assert.Equal(t, 5, existing.Rating)
+	reviewRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDeleteReview_NotAuthor(t *testing.T) {
+	ctx := context.Background()
+	service, reviewRepo, _ := setupReviewService(uuid.New())
+
+	existing := &domain.Review{ID: uuid.New(), UserID: uuid.New()}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+
+	err := service.DeleteReview(ctx, existing.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrNotReviewAuthor)
+	reviewRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestDeleteReview_Admin(t *testing.T) {
+	restaurantID := uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+	adminID := uuid.New()
+	ctx := WithActor(context.Background(), Actor{ID: adminID, Role: domain.UserRoleAdmin})
+
+	existing := &domain.Review{ID: uuid.New(), UserID: uuid.New(), RestaurantID: restaurantID}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	reviewRepo.On("Delete", ctx, existing.ID).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	err := service.DeleteReview(ctx, existing.ID, adminID)
+
+	require.NoError(t, err)
+	reviewRepo.AssertExpectations(t)
+}
+
+func TestSetReviewVisibility_HidesReview(t *testing.T) {
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	service, reviewRepo, sqlMock := setupReviewService(restaurantID)
+
+	existing := &domain.Review{ID: uuid.New(), RestaurantID: restaurantID, IsVisible: true}
+	reviewRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	reviewRepo.On("Update", ctx, existing).Return(nil)
+	reviewRepo.On("RefreshRestaurantRating", ctx, restaurantID).Return(nil)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	review, err := service.SetReviewVisibility(ctx, existing.ID, false)
+
+	require.NoError(t, err)
+	assert.False(t, review.IsVisible)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SponsoredFlushInterval is how often recorded impressions and clicks are
+// written to the placements.
+const SponsoredFlushInterval = 10 * time.Second
+
+var (
+	ErrSponsoredPlacementNotFound = errors.New("sponsored placement not found")
+	ErrInvalidSponsoredPlacement  = errors.New("invalid sponsored placement")
+)
+
+// SponsoredEventType is what a client reports about a sponsored result.
+type SponsoredEventType string
+
+const (
+	SponsoredEventImpression SponsoredEventType = "impression"
+	SponsoredEventClick      SponsoredEventType = "click"
+)
+
+// CreateSponsoredPlacementRequest describes a new placement. City and
+// CuisineType are optional scopes; Weight defaults to 1.
+type CreateSponsoredPlacementRequest struct {
+	RestaurantID uuid.UUID
+	City         *string
+	CuisineType  *domain.CuisineType
+	Weight       int
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Budget       int
+	CostPerClick int
+}
+
+// UpdateSponsoredPlacementRequest changes the fields that are set. An empty
+// City or CuisineType removes that scope.
+type UpdateSponsoredPlacementRequest struct {
+	City         *string
+	CuisineType  *domain.CuisineType
+	Weight       *int
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	Budget       *int
+	CostPerClick *int
+}
+
+type SponsoredPlacementService interface {
+	Create(ctx context.Context, adminID uuid.UUID, req CreateSponsoredPlacementRequest) (*domain.SponsoredPlacement, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.SponsoredPlacement, error)
+	List(ctx context.Context, restaurantID *uuid.UUID, limit, offset int) ([]*domain.SponsoredPlacement, error)
+	Update(ctx context.Context, adminID, id uuid.UUID, req UpdateSponsoredPlacementRequest) (*domain.SponsoredPlacement, error)
+	Delete(ctx context.Context, adminID, id uuid.UUID) error
+	// Blend mixes the placements serving scope into the first page of a
+	// restaurant list; see BlendSponsored. Sponsored results must never
+	// break a list, so when they cannot be loaded the organic page comes
+	// back on its own.
+	Blend(ctx context.Context, organic []*domain.Restaurant, scope repository.SponsoredScope) []RankedRestaurant
+	// RecordEvent counts an impression or click. Counts are kept in memory and
+	// written every SponsoredFlushInterval once Start has been called.
+	RecordEvent(placementID uuid.UUID, event SponsoredEventType)
+	// Start flushes recorded events in the background until ctx is
+	// cancelled.
+	Start(ctx context.Context)
+	// Flush writes the events recorded so far.
+	Flush(ctx context.Context)
+}
+
+// sponsoredCounts are the events recorded for a placement since the last
+// flush.
+type sponsoredCounts struct {
+	impressions int64
+	clicks      int64
+}
+
+type sponsoredPlacementService struct {
+	placementRepo  repository.SponsoredPlacementRepository
+	restaurantRepo repository.RestaurantRepository
+	audit          AuditRecorder
+	log            logger.Logger
+	now            func() time.Time
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]sponsoredCounts
+}
+
+func NewSponsoredPlacementService(
+	placementRepo repository.SponsoredPlacementRepository,
+	restaurantRepo repository.RestaurantRepository,
+	audit AuditRecorder,
+	log logger.Logger,
+) SponsoredPlacementService {
+	return &sponsoredPlacementService{
+		placementRepo:  placementRepo,
+		restaurantRepo: restaurantRepo,
+		audit:          audit,
+		log:            log,
+		now:            time.Now,
+		pending:        make(map[uuid.UUID]sponsoredCounts),
+	}
+}
+
+func (s *sponsoredPlacementService) Create(ctx context.Context, adminID uuid.UUID, req CreateSponsoredPlacementRequest) (*domain.SponsoredPlacement, error) {
+	if _, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	placement := &domain.SponsoredPlacement{
+		RestaurantID: req.RestaurantID,
+		City:         sponsoredCity(req.City),
+		CuisineType:  sponsoredCuisine(req.CuisineType),
+		Weight:       req.Weight,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Budget:       req.Budget,
+		CostPerClick: req.CostPerClick,
+		CreatedBy:    adminID,
+	}
+	if placement.Weight == 0 {
+		placement.Weight = 1
+	}
+	if err := validateSponsoredPlacement(placement); err != nil {
+		return nil, err
+	}
+	if err := s.placementRepo.Create(ctx, placement); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, adminID, AuditActionSponsoredCreate, placement)
+	return placement, nil
+}
+
+func (s *sponsoredPlacementService) Get(ctx context.Context, id uuid.UUID) (*domain.SponsoredPlacement, error) {
+	placement, err := s.placementRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSponsoredPlacementNotFound
+		}
+		return nil, err
+	}
+	return placement, nil
+}
+
+func (s *sponsoredPlacementService) List(ctx context.Context, restaurantID *uuid.UUID, limit, offset int) ([]*domain.SponsoredPlacement, error) {
+	return s.placementRepo.List(ctx, restaurantID, limit, offset)
+}
+
+func (s *sponsoredPlacementService) Update(ctx context.Context, adminID, id uuid.UUID, req UpdateSponsoredPlacementRequest) (*domain.SponsoredPlacement, error) {
+	placement, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.City != nil {
+		placement.City = sponsoredCity(req.City)
+	}
+	if req.CuisineType != nil {
+		placement.CuisineType = sponsoredCuisine(req.CuisineType)
+	}
+	if req.Weight != nil {
+		placement.Weight = *req.Weight
+	}
+	if req.StartsAt != nil {
+		placement.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		placement.EndsAt = *req.EndsAt
+	}
+	if req.Budget != nil {
+		placement.Budget = *req.Budget
+	}
+	if req.CostPerClick != nil {
+		placement.CostPerClick = *req.CostPerClick
+	}
+	if err := validateSponsoredPlacement(placement); err != nil {
+		return nil, err
+	}
+	if err := s.placementRepo.Update(ctx, placement); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, adminID, AuditActionSponsoredUpdate, placement)
+	return placement, nil
+}
+
+func (s *sponsoredPlacementService) Delete(ctx context.Context, adminID, id uuid.UUID) error {
+	placement, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.placementRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSponsoredPlacementNotFound
+		}
+		return err
+	}
+
+	s.recordAudit(ctx, adminID, AuditActionSponsoredDelete, placement)
+	return nil
+}
+
+func (s *sponsoredPlacementService) recordAudit(ctx context.Context, adminID uuid.UUID, action string, placement *domain.SponsoredPlacement) {
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: "sponsored_placement",
+		TargetID:   placement.ID,
+		Metadata: map[string]interface{}{
+			"restaurant_id":  placement.RestaurantID.String(),
+			"budget":         placement.Budget,
+			"cost_per_click": placement.CostPerClick,
+		},
+	})
+}
+
+func (s *sponsoredPlacementService) Blend(ctx context.Context, organic []*domain.Restaurant, scope repository.SponsoredScope) []RankedRestaurant {
+	now := s.now()
+	var candidates []*domain.SponsoredPlacement
+	if len(organic) > 0 {
+		var err error
+		candidates, err = s.placementRepo.ListServing(ctx, scope, now)
+		if err != nil {
+			s.log.Warn("loading sponsored placements failed", zap.Error(err))
+			candidates = nil
+		}
+	}
+	return BlendSponsored(organic, candidates, now)
+}
+
+func (s *sponsoredPlacementService) RecordEvent(placementID uuid.UUID, event SponsoredEventType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := s.pending[placementID]
+	switch event {
+	case SponsoredEventImpression:
+		counts.impressions++
+	case SponsoredEventClick:
+		counts.clicks++
+	default:
+		return
+	}
+	s.pending[placementID] = counts
+}
+
+func (s *sponsoredPlacementService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(SponsoredFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Flush(ctx)
+			}
+		}
+	}()
+}
+
+func (s *sponsoredPlacementService) Flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[uuid.UUID]sponsoredCounts, len(pending))
+	s.mu.Unlock()
+
+	for id, counts := range pending {
+		if err := s.placementRepo.AddCounts(ctx, id, counts.impressions, counts.clicks); err != nil {
+			s.log.Warn("saving sponsored placement counts failed",
+				zap.String("placement_id", id.String()),
+				zap.Int64("impressions", counts.impressions),
+				zap.Int64("clicks", counts.clicks),
+				zap.Error(err))
+		}
+	}
+}
+
+// validateSponsoredPlacement requires a positive weight, budget and cost per
+// click, a window that ends after it starts, and a known cuisine scope.
+func validateSponsoredPlacement(placement *domain.SponsoredPlacement) error {
+	switch {
+	case placement.Weight <= 0, placement.Budget <= 0, placement.CostPerClick <= 0:
+		return ErrInvalidSponsoredPlacement
+	case placement.StartsAt.IsZero(), !placement.StartsAt.Before(placement.EndsAt):
+		return ErrInvalidSponsoredPlacement
+	case placement.CuisineType != nil && !slices.Contains(domain.CuisineTypes, *placement.CuisineType):
+		return ErrInvalidCuisineType
+	}
+	return nil
+}
+
+// sponsoredCity trims a city scope, turning an empty one into no scope.
+func sponsoredCity(city *string) *string {
+	if city == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*city)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// sponsoredCuisine turns an empty cuisine scope into no scope.
+func sponsoredCuisine(cuisine *domain.CuisineType) *domain.CuisineType {
+	if cuisine == nil || *cuisine == "" {
+		return nil
+	}
+	return cuisine
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MockSponsoredPlacementRepository struct {
+	mock.Mock
+}
+
+func (m *MockSponsoredPlacementRepository) Create(ctx context.Context, placement *domain.SponsoredPlacement) error {
+	args := m.Called(ctx, placement)
+	return args.Error(0)
+}
+
+func (m *MockSponsoredPlacementRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.SponsoredPlacement, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SponsoredPlacement), args.Error(1)
+}
+
+func (m *MockSponsoredPlacementRepository) List(ctx context.Context, restaurantID *uuid.UUID, limit, offset int) ([]*domain.SponsoredPlacement, error) {
+	args := m.Called(ctx, restaurantID, limit, offset)
+	return args.Get(0).([]*domain.SponsoredPlacement), args.Error(1)
+}
+
+func (m *MockSponsoredPlacementRepository) Update(ctx context.Context, placement *domain.SponsoredPlacement) error {
+	args := m.Called(ctx, placement)
+	return args.Error(0)
+}
+
+func (m *MockSponsoredPlacementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSponsoredPlacementRepository) ListServing(ctx context.Context, scope repository.SponsoredScope, now time.Time) ([]*domain.SponsoredPlacement, error) {
+	args := m.Called(ctx, scope, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SponsoredPlacement), args.Error(1)
+}
+
+func (m *MockSponsoredPlacementRepository) AddCounts(ctx context.Context, id uuid.UUID, impressions, clicks int64) error {
+	args := m.Called(ctx, id, impressions, clicks)
+	return args.Error(0)
+}
+
+func newTestSponsoredService() (*sponsoredPlacementService, *MockSponsoredPlacementRepository, *BookingMockRestaurantRepository) {
+	placementRepo := new(MockSponsoredPlacementRepository)
+	restaurantRepo := new(BookingMockRestaurantRepository)
+	svc := NewSponsoredPlacementService(placementRepo, restaurantRepo, NewLogAuditRecorder(zap.NewNop()), zap.NewNop()).(*sponsoredPlacementService)
+	svc.now = func() time.Time { return rankingNow }
+	return svc, placementRepo, restaurantRepo
+}
+
+func validSponsoredRequest(restaurantID uuid.UUID) CreateSponsoredPlacementRequest {
+	return CreateSponsoredPlacementRequest{
+		RestaurantID: restaurantID,
+		StartsAt:     rankingNow,
+		EndsAt:       rankingNow.Add(7 * 24 * time.Hour),
+		Budget:       50000,
+		CostPerClick: 150,
+	}
+}
+
+func TestCreateSponsoredPlacement_Defaults(t *testing.T) {
+	svc, placementRepo, restaurantRepo := newTestSponsoredService()
+	ctx := context.Background()
+	adminID, restaurantID := uuid.New(), uuid.New()
+	blank := "  "
+	noCuisine := domain.CuisineType("")
+
+	restaurantRepo.On("GetByID", ctx, restaurantID).Return(&domain.Restaurant{ID: restaurantID}, nil)
+	placementRepo.On("Create", ctx, mock.AnythingOfType("*domain.SponsoredPlacement")).Return(nil)
+
+	req := validSponsoredRequest(restaurantID)
+	req.City = &blank
+	req.CuisineType = &noCuisine
+	placement, err := svc.Create(ctx, adminID, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, placement.Weight)
+	assert.Nil(t, placement.City)
+	assert.Nil(t, placement.CuisineType)
+	assert.Equal(t, adminID, placement.CreatedBy)
+	placementRepo.AssertExpectations(t)
+}
+
+func TestCreateSponsoredPlacement_InvalidInput(t *testing.T) {
+	restaurantID := uuid.New()
+	unknownCuisine := domain.CuisineType("martian")
+
+	cases := map[string]struct {
+		edit func(*CreateSponsoredPlacementRequest)
+		want error
+	}{
+		"negative weight":   {func(r *CreateSponsoredPlacementRequest) { r.Weight = -1 }, ErrInvalidSponsoredPlacement},
+		"no budget":         {func(r *CreateSponsoredPlacementRequest) { r.Budget = 0 }, ErrInvalidSponsoredPlacement},
+		"no cost per click": {func(r *CreateSponsoredPlacementRequest) { r.CostPerClick = 0 }, ErrInvalidSponsoredPlacement},
+		"no start":          {func(r *CreateSponsoredPlacementRequest) { r.StartsAt = time.Time{} }, ErrInvalidSponsoredPlacement},
+		"ends at start":     {func(r *CreateSponsoredPlacementRequest) { r.EndsAt = r.StartsAt }, ErrInvalidSponsoredPlacement},
+		"unknown cuisine":   {func(r *CreateSponsoredPlacementRequest) { r.CuisineType = &unknownCuisine }, ErrInvalidCuisineType},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, placementRepo, restaurantRepo := newTestSponsoredService()
+			restaurantRepo.On("GetByID", mock.Anything, restaurantID).Return(&domain.Restaurant{ID: restaurantID}, nil)
+
+			req := validSponsoredRequest(restaurantID)
+			tc.edit(&req)
+			_, err := svc.Create(context.Background(), uuid.New(), req)
+
+			assert.ErrorIs(t, err, tc.want)
+			placementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateSponsoredPlacement_RestaurantNotFound(t *testing.T) {
+	svc, placementRepo, restaurantRepo := newTestSponsoredService()
+	restaurantID := uuid.New()
+
+	restaurantRepo.On("GetByID", mock.Anything, restaurantID).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.Create(context.Background(), uuid.New(), validSponsoredRequest(restaurantID))
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+	placementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateSponsoredPlacement(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+	ctx := context.Background()
+	city := "Almaty"
+	existing := servingPlacement("promoted", 1)
+	existing.City = &city
+	budget, cleared := 90000, ""
+
+	placementRepo.On("GetByID", ctx, existing.ID).Return(existing, nil)
+	placementRepo.On("Update", ctx, existing).Return(nil)
+
+	placement, err := svc.Update(ctx, uuid.New(), existing.ID, UpdateSponsoredPlacementRequest{Budget: &budget, City: &cleared})
+
+	require.NoError(t, err)
+	assert.Equal(t, 90000, placement.Budget)
+	assert.Nil(t, placement.City)
+	placementRepo.AssertExpectations(t)
+}
+
+func TestUpdateSponsoredPlacement_RejectsInvalidWindow(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+	existing := servingPlacement("promoted", 1)
+	endsAt := existing.StartsAt.Add(-time.Hour)
+
+	placementRepo.On("GetByID", mock.Anything, existing.ID).Return(existing, nil)
+
+	_, err := svc.Update(context.Background(), uuid.New(), existing.ID, UpdateSponsoredPlacementRequest{EndsAt: &endsAt})
+
+	assert.ErrorIs(t, err, ErrInvalidSponsoredPlacement)
+	placementRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDeleteSponsoredPlacement_NotFound(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+	id := uuid.New()
+
+	placementRepo.On("GetByID", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, svc.Delete(context.Background(), uuid.New(), id), ErrSponsoredPlacementNotFound)
+	placementRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestBlend_UsesServingPlacements(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+	ctx := context.Background()
+	cuisine := domain.CuisineTypeItalian
+	scope := repository.SponsoredScope{City: "Almaty", CuisineType: &cuisine}
+
+	placementRepo.On("ListServing", ctx, scope, rankingNow).Return([]*domain.SponsoredPlacement{servingPlacement("promoted", 1)}, nil)
+
+	ranked := svc.Blend(ctx, organicPage(2), scope)
+
+	assert.Equal(t, []string{"$promoted", "organic 0", "organic 1"}, names(ranked))
+}
+
+func TestBlend_FallsBackToOrganicOnError(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+
+	placementRepo.On("ListServing", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	ranked := svc.Blend(context.Background(), organicPage(2), repository.SponsoredScope{})
+
+	assert.Equal(t, []string{"organic 0", "organic 1"}, names(ranked))
+}
+
+func TestBlend_EmptyPageSkipsLookup(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+
+	assert.Empty(t, svc.Blend(context.Background(), nil, repository.SponsoredScope{}))
+	placementRepo.AssertNotCalled(t, "ListServing", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordEvent_FlushAggregates(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+	ctx := context.Background()
+	first, second := uuid.New(), uuid.New()
+
+	svc.RecordEvent(first, SponsoredEventImpression)
+	svc.RecordEvent(first, SponsoredEventImpression)
+	svc.RecordEvent(first, SponsoredEventClick)
+	svc.RecordEvent(second, SponsoredEventImpression)
+	svc.RecordEvent(second, SponsoredEventType("hover"))
+
+	placementRepo.On("AddCounts", ctx, first, int64(2), int64(1)).Return(nil).Once()
+	placementRepo.On("AddCounts", ctx, second, int64(1), int64(0)).Return(nil).Once()
+
+	svc.Flush(ctx)
+	svc.Flush(ctx)
+
+	placementRepo.AssertExpectations(t)
+}
+
+func TestRecordEvent_UnknownTypeIsIgnored(t *testing.T) {
+	svc, placementRepo, _ := newTestSponsoredService()
+
+	svc.RecordEvent(uuid.New(), SponsoredEventType("hover"))
+	svc.Flush(context.Background())
+
+	placementRepo.AssertNotCalled(t, "AddCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"restaurant-booking/internal/domain"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxSponsoredResults caps the sponsored results blended into a page.
+const MaxSponsoredResults = 2
+
+// sponsoredSlots are the positions of a blended page, counted from 0, that
+// sponsored results take, in order. At most two slots may come before the
+// first organic result, so the best organic result is never pushed below
+// the third position.
+var sponsoredSlots = []int{0, 3}
+
+// RankedRestaurant is an entry of a restaurant list. Placement is set for
+// sponsored results.
+type RankedRestaurant struct {
+	Restaurant *domain.Restaurant
+	Placement  *domain.SponsoredPlacement
+}
+
+// Sponsored reports whether the entry is a paid placement.
+func (r RankedRestaurant) Sponsored() bool {
+	return r.Placement != nil
+}
+
+// BlendSponsored mixes sponsored results into a page of organic results.
+//
+// Candidates that are not serving at now, whose restaurant is missing or
+// inactive, or whose restaurant is already on the page are dropped, as are
+// all but the best candidate of a restaurant. The rest are ranked by weight,
+// then by fewest impressions so equal weights take turns, then by ID, and
+// the first MaxSponsoredResults fill sponsoredSlots. A slot is only used
+// while organic results remain to follow it, so a page never ends with a
+// sponsored result, and an empty page stays empty. Organic results keep
+// their order.
+func BlendSponsored(organic []*domain.Restaurant, candidates []*domain.SponsoredPlacement, now time.Time) []RankedRestaurant {
+	blended := make([]RankedRestaurant, 0, len(organic)+MaxSponsoredResults)
+	sponsored := rankSponsored(organic, candidates, now)
+
+	next := 0
+	for _, restaurant := range organic {
+		for next < len(sponsored) && next < len(sponsoredSlots) && len(blended) == sponsoredSlots[next] {
+			blended = append(blended, RankedRestaurant{Restaurant: sponsored[next].Restaurant, Placement: sponsored[next]})
+			next++
+		}
+		blended = append(blended, RankedRestaurant{Restaurant: restaurant})
+	}
+	return blended
+}
+
+// rankSponsored returns the candidates BlendSponsored may show, best first,
+// at most MaxSponsoredResults of them.
+func rankSponsored(organic []*domain.Restaurant, candidates []*domain.SponsoredPlacement, now time.Time) []*domain.SponsoredPlacement {
+	onPage := make(map[uuid.UUID]bool, len(organic))
+	for _, restaurant := range organic {
+		onPage[restaurant.ID] = true
+	}
+
+	eligible := make([]*domain.SponsoredPlacement, 0, len(candidates))
+	for _, placement := range candidates {
+		if placement.Restaurant == nil || !placement.Restaurant.IsActive || !placement.IsServing(now) {
+			continue
+		}
+		if onPage[placement.Restaurant.ID] {
+			continue
+		}
+		eligible = append(eligible, placement)
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		a, b := eligible[i], eligible[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.Impressions != b.Impressions {
+			return a.Impressions < b.Impressions
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	ranked := make([]*domain.SponsoredPlacement, 0, MaxSponsoredResults)
+	seen := make(map[uuid.UUID]bool, MaxSponsoredResults)
+	for _, placement := range eligible {
+		if len(ranked) == MaxSponsoredResults {
+			break
+		}
+		if seen[placement.Restaurant.ID] {
+			continue
+		}
+		seen[placement.Restaurant.ID] = true
+		ranked = append(ranked, placement)
+	}
+	return ranked
+}
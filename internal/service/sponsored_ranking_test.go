@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rankingNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func organicPage(n int) []*domain.Restaurant {
+	page := make([]*domain.Restaurant, n)
+	for i := range page {
+		page[i] = &domain.Restaurant{ID: uuid.New(), Name: fmt.Sprintf("organic %d", i), IsActive: true}
+	}
+	return page
+}
+
+// servingPlacement is running at rankingNow with budget to spare.
+func servingPlacement(name string, weight int) *domain.SponsoredPlacement {
+	return &domain.SponsoredPlacement{
+		ID:           uuid.New(),
+		Weight:       weight,
+		StartsAt:     rankingNow.Add(-24 * time.Hour),
+		EndsAt:       rankingNow.Add(24 * time.Hour),
+		Budget:       10000,
+		CostPerClick: 100,
+		Restaurant:   &domain.Restaurant{ID: uuid.New(), Name: name, IsActive: true},
+	}
+}
+
+// names lists the entries of a blended page, sponsored ones with a "$"
+// prefix.
+func names(ranked []RankedRestaurant) []string {
+	out := make([]string, len(ranked))
+	for i, entry := range ranked {
+		out[i] = entry.Restaurant.Name
+		if entry.Sponsored() {
+			out[i] = "$" + out[i]
+		}
+	}
+	return out
+}
+
+func TestBlendSponsored_FillsSlots(t *testing.T) {
+	organic := organicPage(5)
+	ranked := BlendSponsored(organic, []*domain.SponsoredPlacement{servingPlacement("a", 5), servingPlacement("b", 3)}, rankingNow)
+
+	assert.Equal(t, []string{"$a", "organic 0", "organic 1", "$b", "organic 2", "organic 3", "organic 4"}, names(ranked))
+	assert.Equal(t, ranked[3].Placement.Restaurant, ranked[3].Restaurant)
+}
+
+func TestBlendSponsored_NoCandidates(t *testing.T) {
+	organic := organicPage(3)
+
+	assert.Equal(t, []string{"organic 0", "organic 1", "organic 2"}, names(BlendSponsored(organic, nil, rankingNow)))
+}
+
+func TestBlendSponsored_EmptyPageStaysEmpty(t *testing.T) {
+	assert.Empty(t, BlendSponsored(nil, []*domain.SponsoredPlacement{servingPlacement("a", 1)}, rankingNow))
+}
+
+func TestBlendSponsored_ShortPages(t *testing.T) {
+	candidates := func() []*domain.SponsoredPlacement {
+		return []*domain.SponsoredPlacement{servingPlacement("a", 2), servingPlacement("b", 1)}
+	}
+
+	cases := []struct {
+		organic int
+		want    []string
+	}{
+		{1, []string{"$a", "organic 0"}},
+		{2, []string{"$a", "organic 0", "organic 1"}},
+		{3, []string{"$a", "organic 0", "organic 1", "$b", "organic 2"}},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d organic", tc.organic), func(t *testing.T) {
+			assert.Equal(t, tc.want, names(BlendSponsored(organicPage(tc.organic), candidates(), rankingNow)))
+		})
+	}
+}
+
+func TestBlendSponsored_AtMostTwo(t *testing.T) {
+	candidates := []*domain.SponsoredPlacement{
+		servingPlacement("a", 1), servingPlacement("b", 9), servingPlacement("c", 5), servingPlacement("d", 7),
+	}
+
+	ranked := BlendSponsored(organicPage(10), candidates, rankingNow)
+
+	assert.Equal(t, []string{"$b", "organic 0", "organic 1", "$d"}, names(ranked)[:4])
+	assert.Len(t, ranked, 12)
+}
+
+func TestBlendSponsored_RankingTieBreaks(t *testing.T) {
+	seen := servingPlacement("seen", 4)
+	seen.Impressions = 500
+	fresh := servingPlacement("fresh", 4)
+	fresh.Impressions = 20
+
+	low := servingPlacement("low", 4)
+	high := servingPlacement("high", 4)
+	low.ID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high.ID = uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+
+	assert.Equal(t, []string{"$fresh", "organic 0", "organic 1", "$seen", "organic 2"},
+		names(BlendSponsored(organicPage(3), []*domain.SponsoredPlacement{seen, fresh}, rankingNow)))
+	assert.Equal(t, []string{"$low", "organic 0", "organic 1", "$high", "organic 2"},
+		names(BlendSponsored(organicPage(3), []*domain.SponsoredPlacement{high, low}, rankingNow)))
+}
+
+func TestBlendSponsored_SkipsPlacementsNotServing(t *testing.T) {
+	expired := servingPlacement("expired", 9)
+	expired.EndsAt = rankingNow
+	upcoming := servingPlacement("upcoming", 9)
+	upcoming.StartsAt = rankingNow.Add(time.Minute)
+	exhausted := servingPlacement("exhausted", 9)
+	exhausted.Clicks = 100 // 100 clicks at 100 spend the whole 10000
+	inactive := servingPlacement("inactive", 9)
+	inactive.Restaurant.IsActive = false
+	orphan := servingPlacement("orphan", 9)
+	orphan.Restaurant = nil
+	nearlySpent := servingPlacement("nearly spent", 1)
+	nearlySpent.Clicks = 99
+
+	ranked := BlendSponsored(organicPage(4), []*domain.SponsoredPlacement{expired, upcoming, exhausted, inactive, orphan, nearlySpent}, rankingNow)
+
+	assert.Equal(t, []string{"$nearly spent", "organic 0", "organic 1", "organic 2", "organic 3"}, names(ranked))
+}
+
+func TestBlendSponsored_StartsAtIsInclusive(t *testing.T) {
+	starting := servingPlacement("starting", 1)
+	starting.StartsAt = rankingNow
+
+	assert.True(t, BlendSponsored(organicPage(1), []*domain.SponsoredPlacement{starting}, rankingNow)[0].Sponsored())
+}
+
+func TestBlendSponsored_SkipsRestaurantsAlreadyOnPage(t *testing.T) {
+	organic := organicPage(4)
+	duplicate := servingPlacement("duplicate", 9)
+	duplicate.Restaurant = organic[2]
+
+	ranked := BlendSponsored(organic, []*domain.SponsoredPlacement{duplicate, servingPlacement("other", 1)}, rankingNow)
+
+	assert.Equal(t, []string{"$other", "organic 0", "organic 1", "organic 2", "organic 3"}, names(ranked))
+}
+
+func TestBlendSponsored_OnePlacementPerRestaurant(t *testing.T) {
+	first := servingPlacement("twice", 9)
+	second := servingPlacement("twice", 8)
+	second.Restaurant = first.Restaurant
+
+	ranked := BlendSponsored(organicPage(4), []*domain.SponsoredPlacement{second, first, servingPlacement("other", 1)}, rankingNow)
+
+	assert.Equal(t, []string{"$twice", "organic 0", "organic 1", "$other", "organic 2", "organic 3"}, names(ranked))
+	assert.Same(t, first, ranked[0].Placement)
+}
+
+// TestBlendSponsored_Invariants checks the blending rules over a range of
+// page sizes and candidate counts.
+func TestBlendSponsored_Invariants(t *testing.T) {
+	for organicCount := 0; organicCount <= 12; organicCount++ {
+		for candidateCount := 0; candidateCount <= 5; candidateCount++ {
+			organic := organicPage(organicCount)
+			candidates := make([]*domain.SponsoredPlacement, candidateCount)
+			for i := range candidates {
+				candidates[i] = servingPlacement(fmt.Sprintf("s%d", i), i+1)
+			}
+
+			ranked := BlendSponsored(organic, candidates, rankingNow)
+
+			name := fmt.Sprintf("%d organic, %d candidates", organicCount, candidateCount)
+			kept := make([]*domain.Restaurant, 0, organicCount)
+			sponsored := 0
+			seen := make(map[uuid.UUID]bool)
+			for _, entry := range ranked {
+				require.False(t, seen[entry.Restaurant.ID], "%s: restaurant listed twice", name)
+				seen[entry.Restaurant.ID] = true
+				if entry.Sponsored() {
+					sponsored++
+					continue
+				}
+				kept = append(kept, entry.Restaurant)
+			}
+
+			assert.Equal(t, organic, kept, name)
+			assert.LessOrEqual(t, sponsored, MaxSponsoredResults, name)
+			if organicCount == 0 {
+				assert.Empty(t, ranked, name)
+				continue
+			}
+			assert.False(t, ranked[len(ranked)-1].Sponsored(), "%s: page ends with a sponsored result", name)
+			top := 0
+			for ranked[top].Sponsored() {
+				top++
+			}
+			assert.LessOrEqual(t, top, 2, "%s: top organic result pushed below position 3", name)
+		}
+	}
+}
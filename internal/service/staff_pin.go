@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidStaffPinFormat = errors.New("staff PIN must be 4 to 6 digits")
+	ErrInvalidStaffPin       = errors.New("invalid staff PIN")
+	ErrStaffPinLocked        = errors.New("staff PIN entry is locked after too many failed attempts")
+	// ErrStaffPinAmbiguous means two staff members of the restaurant picked
+	// the same PIN, so the action cannot be attributed to either.
+	ErrStaffPinAmbiguous = errors.New("staff PIN matches more than one staff member, one of them has to change it")
+)
+
+const (
+	// staffPinMaxAttempts wrong PINs on one shared device lock PIN entry
+	// there for staffPinLockout.
+	staffPinMaxAttempts = 5
+	staffPinLockout     = 10 * time.Minute
+)
+
+var staffPinPattern = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+// StaffAttribution names the staff member behind a request sent from a
+// shared device, as resolved from their PIN. DeviceID is the API key the
+// device identified itself with.
+type StaffAttribution struct {
+	StaffID  uuid.UUID
+	DeviceID uuid.UUID
+}
+
+type staffAttributionContextKey struct{}
+
+func WithStaffAttribution(ctx context.Context, attribution StaffAttribution) context.Context {
+	return context.WithValue(ctx, staffAttributionContextKey{}, attribution)
+}
+
+func StaffAttributionFromContext(ctx context.Context) (StaffAttribution, bool) {
+	attribution, ok := ctx.Value(staffAttributionContextKey{}).(StaffAttribution)
+	return attribution, ok
+}
+
+type StaffPinService interface {
+	// SetPin replaces the user's own staff PIN.
+	SetPin(ctx context.Context, userID uuid.UUID, pin string) error
+	// Resolve returns the owner or manager of the device's restaurant whose
+	// PIN is pin. Wrong PINs count per device; once staffPinMaxAttempts of
+	// them fall within staffPinLockout, the device gets ErrStaffPinLocked
+	// until the lockout ends.
+	Resolve(ctx context.Context, device *domain.APIKey, pin string) (uuid.UUID, error)
+	// RecordAction audits a request made from a shared device. sessionUserID
+	// is the account the device is logged in with; the recorder attributes
+	// the entry to the staff member in ctx.
+	RecordAction(ctx context.Context, sessionUserID uuid.UUID, device *domain.APIKey, method, route string, status int)
+}
+
+type staffPinService struct {
+	userRepo       repository.UserRepository
+	managerRepo    repository.RestaurantManagerRepository
+	restaurantRepo repository.RestaurantRepository
+	attempts       LoginAttemptStore
+	audit          AuditRecorder
+	log            logger.Logger
+}
+
+func NewStaffPinService(
+	userRepo repository.UserRepository,
+	managerRepo repository.RestaurantManagerRepository,
+	restaurantRepo repository.RestaurantRepository,
+	attempts LoginAttemptStore,
+	audit AuditRecorder,
+	log logger.Logger,
+) StaffPinService {
+	return &staffPinService{
+		userRepo:       userRepo,
+		managerRepo:    managerRepo,
+		restaurantRepo: restaurantRepo,
+		attempts:       attempts,
+		audit:          audit,
+		log:            log,
+	}
+}
+
+func (s *staffPinService) SetPin(ctx context.Context, userID uuid.UUID, pin string) error {
+	if !staffPinPattern.MatchString(pin) {
+		return ErrInvalidStaffPinFormat
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.StaffPinHash = string(hash)
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    user.ID,
+		Action:     AuditActionStaffPinSet,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+	return nil
+}
+
+func (s *staffPinService) Resolve(ctx context.Context, device *domain.APIKey, pin string) (uuid.UUID, error) {
+	key := "staff_pin:" + device.ID.String()
+	now := time.Now()
+
+	lockedUntil, err := s.attempts.LockedUntil(key)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if now.Before(lockedUntil) {
+		return uuid.Nil, ErrStaffPinLocked
+	}
+
+	var matches []uuid.UUID
+	if staffPinPattern.MatchString(pin) {
+		staff, err := s.restaurantStaff(ctx, device.RestaurantID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		for _, user := range staff {
+			if user.IsActive && user.StaffPinHash != "" && bcrypt.CompareHashAndPassword([]byte(user.StaffPinHash), []byte(pin)) == nil {
+				matches = append(matches, user.ID)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		if err := s.attempts.Reset(key); err != nil {
+			return uuid.Nil, err
+		}
+		return matches[0], nil
+	case 0:
+		failures, err := s.attempts.AddFailure(key, now, staffPinLockout)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if failures >= staffPinMaxAttempts {
+			if err := s.attempts.Lock(key, now.Add(staffPinLockout)); err != nil {
+				return uuid.Nil, err
+			}
+			return uuid.Nil, ErrStaffPinLocked
+		}
+		return uuid.Nil, ErrInvalidStaffPin
+	default:
+		return uuid.Nil, ErrStaffPinAmbiguous
+	}
+}
+
+// restaurantStaff returns the owner and the managers of the restaurant.
+// Deactivated accounts are filtered out by the caller.
+func (s *staffPinService) restaurantStaff(ctx context.Context, restaurantID uuid.UUID) ([]*domain.User, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	owner, err := s.userRepo.GetByID(restaurant.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	staff := []*domain.User{owner}
+
+	managers, err := s.managerRepo.GetManagersByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, manager := range managers {
+		if manager.User != nil && manager.UserID != owner.ID {
+			staff = append(staff, manager.User)
+		}
+	}
+	return staff, nil
+}
+
+func (s *staffPinService) RecordAction(ctx context.Context, sessionUserID uuid.UUID, device *domain.APIKey, method, route string, status int) {
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    sessionUserID,
+		Action:     AuditActionStaffAction,
+		TargetType: "restaurant",
+		TargetID:   device.RestaurantID,
+		Metadata: map[string]interface{}{
+			"method": method,
+			"route":  route,
+			"status": status,
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func staffWithPin(t *testing.T, pin string) *domain.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	require.NoError(t, err)
+	return &domain.User{ID: uuid.New(), IsActive: true, Role: domain.UserRoleManager, StaffPinHash: string(hash)}
+}
+
+func setupStaffPinService(t *testing.T, owner *domain.User, managers ...*domain.User) (*staffPinService, *domain.APIKey, LoginAttemptStore) {
+	t.Helper()
+	userRepo := new(MockUserRepository)
+	managerRepo := new(MockRestaurantManagerRepository)
+	restaurantRepo := new(MockRestaurantRepository)
+	store := NewInMemoryLoginAttemptStore()
+
+	device := &domain.APIKey{ID: uuid.New(), RestaurantID: uuid.New()}
+	restaurantRepo.On("GetByID", mock.Anything, device.RestaurantID).Return(&domain.Restaurant{ID: device.RestaurantID, OwnerID: owner.ID}, nil)
+	userRepo.On("GetByID", owner.ID).Return(owner, nil)
+
+	assignments := make([]*domain.RestaurantManager, 0, len(managers))
+	for _, manager := range managers {
+		assignments = append(assignments, &domain.RestaurantManager{RestaurantID: device.RestaurantID, UserID: manager.ID, User: manager})
+	}
+	managerRepo.On("GetManagersByRestaurant", mock.Anything, device.RestaurantID).Return(assignments, nil)
+
+	service := NewStaffPinService(userRepo, managerRepo, restaurantRepo, store, new(MockAuditRecorder), zap.NewNop()).(*staffPinService)
+	return service, device, store
+}
+
+func TestStaffPinResolve_AttributesMatchingManager(t *testing.T) {
+	owner := staffWithPin(t, "1111")
+	alice := staffWithPin(t, "2222")
+	bob := staffWithPin(t, "3333")
+	service, device, _ := setupStaffPinService(t, owner, alice, bob)
+
+	staffID, err := service.Resolve(context.Background(), device, "3333")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, staffID)
+
+	staffID, err = service.Resolve(context.Background(), device, "1111")
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, staffID)
+}
+
+func TestStaffPinResolve_IgnoresDeactivatedStaff(t *testing.T) {
+	owner := staffWithPin(t, "1111")
+	former := staffWithPin(t, "2222")
+	former.IsActive = false
+	service, device, _ := setupStaffPinService(t, owner, former)
+
+	_, err := service.Resolve(context.Background(), device, "2222")
+	assert.ErrorIs(t, err, ErrInvalidStaffPin)
+}
+
+func TestStaffPinResolve_LocksDeviceAfterMaxAttempts(t *testing.T) {
+	owner := staffWithPin(t, "1111")
+	service, device, store := setupStaffPinService(t, owner)
+
+	for i := 0; i < staffPinMaxAttempts-1; i++ {
+		_, err := service.Resolve(context.Background(), device, "9999")
+		assert.ErrorIs(t, err, ErrInvalidStaffPin)
+	}
+	_, err := service.Resolve(context.Background(), device, "9999")
+	assert.ErrorIs(t, err, ErrStaffPinLocked)
+
+	_, err = service.Resolve(context.Background(), device, "1111")
+	assert.ErrorIs(t, err, ErrStaffPinLocked, "the right PIN must not get through a lockout")
+
+	lockedUntil, err := store.LockedUntil("staff_pin:" + device.ID.String())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(staffPinLockout), lockedUntil, 5*time.Second)
+}
+
+func TestStaffPinResolve_SuccessResetsFailures(t *testing.T) {
+	owner := staffWithPin(t, "1111")
+	service, device, _ := setupStaffPinService(t, owner)
+
+	for i := 0; i < staffPinMaxAttempts-1; i++ {
+		_, _ = service.Resolve(context.Background(), device, "9999")
+	}
+	_, err := service.Resolve(context.Background(), device, "1111")
+	require.NoError(t, err)
+
+	_, err = service.Resolve(context.Background(), device, "9999")
+	assert.ErrorIs(t, err, ErrInvalidStaffPin)
+}
+
+func TestStaffPinResolve_SharedPinIsAmbiguous(t *testing.T) {
+	owner := staffWithPin(t, "1111")
+	alice := staffWithPin(t, "2222")
+	bob := staffWithPin(t, "2222")
+	service, device, _ := setupStaffPinService(t, owner, alice, bob)
+
+	_, err := service.Resolve(context.Background(), device, "2222")
+	assert.ErrorIs(t, err, ErrStaffPinAmbiguous)
+}
+
+func TestSetStaffPin(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	audit := new(MockAuditRecorder)
+	service := NewStaffPinService(userRepo, new(MockRestaurantManagerRepository), new(MockRestaurantRepository), NewInMemoryLoginAttemptStore(), audit, zap.NewNop())
+	user := &domain.User{ID: uuid.New(), IsActive: true, Role: domain.UserRoleManager}
+
+	for _, pin := range []string{"123", "1234567", "12a4", ""} {
+		assert.ErrorIs(t, service.SetPin(context.Background(), user.ID, pin), ErrInvalidStaffPinFormat, pin)
+	}
+
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+	audit.On("Record", mock.Anything, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionStaffPinSet && entry.ActorID == user.ID
+	})).Return(nil)
+
+	require.NoError(t, service.SetPin(context.Background(), user.ID, "482100"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.StaffPinHash), []byte("482100")))
+	audit.AssertExpectations(t)
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxTableImportRows caps the data lines of one table import.
+const MaxTableImportRows = 1000
+
+var (
+	ErrInvalidTableImport  = errors.New("invalid table import")
+	ErrInvalidLocationType = errors.New("invalid location_type")
+)
+
+// tableImportColumns are the columns of a table import, in the order they are
+// documented. x and y may be left out of the header.
+var tableImportColumns = []string{"table_number", "min_capacity", "max_capacity", "location_type", "x", "y"}
+
+// TableImportRow is the outcome of one data line of an import. Line counts
+// the header as line 1. Table is set when the row was created, Err when it
+// was rejected.
+type TableImportRow struct {
+	Line        int
+	TableNumber string
+	Table       *domain.Table
+	Err         error
+}
+
+// TableImportError is returned by a strict import when some rows were
+// rejected. Rows are those rows; no table was created.
+type TableImportError struct {
+	Rows []TableImportRow
+}
+
+func (e *TableImportError) Error() string {
+	return fmt.Sprintf("%s: %d rows were rejected", ErrInvalidTableImport, len(e.Rows))
+}
+
+func (e *TableImportError) Unwrap() error {
+	return ErrInvalidTableImport
+}
+
+// tableImportLine is a parsed data line, with err set when its fields could
+// not be read.
+type tableImportLine struct {
+	line int
+	req  CreateTableRequest
+	err  error
+}
+
+func (s *tableService) ImportTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, r io.Reader, strict bool) ([]TableImportRow, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.import"); err != nil {
+		return nil, err
+	}
+
+	lines, err := parseTableImport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]TableImportRow, len(lines))
+	tableNumbers := make(map[string]int)
+	for i, line := range lines {
+		rows[i] = TableImportRow{Line: line.line, TableNumber: line.req.TableNumber, Err: line.err}
+		if rows[i].Err != nil {
+			continue
+		}
+		if err := validateNewTable(restaurant, line.req); err != nil {
+			rows[i].Err = err
+			continue
+		}
+		if first, ok := tableNumbers[line.req.TableNumber]; ok {
+			rows[i].Err = fmt.Errorf("table number '%s' is already on line %d: %w", line.req.TableNumber, first, ErrDuplicateTableNumber)
+			continue
+		}
+		tableNumbers[line.req.TableNumber] = line.line
+	}
+	if strict {
+		if rejected := rejectedImportRows(rows); len(rejected) > 0 {
+			return nil, &TableImportError{Rows: rejected}
+		}
+	}
+
+	err = s.withRestaurantLock(ctx, restaurantID, func(tx *gorm.DB, tableRepo repository.TableRepository) error {
+		for i := range rows {
+			if rows[i].Err != nil {
+				continue
+			}
+			err := s.checkDuplicateTableNumber(ctx, tx, restaurantID, rows[i].TableNumber, uuid.Nil)
+			if errors.Is(err, ErrDuplicateTableNumber) {
+				rows[i].Err = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if strict {
+			if rejected := rejectedImportRows(rows); len(rejected) > 0 {
+				return &TableImportError{Rows: rejected}
+			}
+		}
+
+		for i, line := range lines {
+			if rows[i].Err != nil {
+				continue
+			}
+			table := &domain.Table{
+				RestaurantID: restaurantID,
+				TableNumber:  line.req.TableNumber,
+				MinCapacity:  line.req.MinCapacity,
+				MaxCapacity:  line.req.MaxCapacity,
+				LocationType: line.req.LocationType,
+				XPosition:    line.req.XPosition,
+				YPosition:    line.req.YPosition,
+				IsActive:     true,
+			}
+			if err := tableRepo.Create(ctx, table); err != nil {
+				return fmt.Errorf("failed to create table on line %d: %w", line.line, err)
+			}
+			rows[i].Table = table
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// validateNewTable checks one table of a bulk creation or an import on its
+// own, before the restaurant's existing tables are looked at.
+func validateNewTable(restaurant *domain.Restaurant, req CreateTableRequest) error {
+	if strings.TrimSpace(req.TableNumber) == "" {
+		return ErrInvalidTableNumber
+	}
+
+	if req.MinCapacity > req.MaxCapacity {
+		return ErrInvalidCapacity
+	}
+
+	if !slices.Contains(domain.LocationTypes, req.LocationType) {
+		return fmt.Errorf("%w %q", ErrInvalidLocationType, req.LocationType)
+	}
+
+	if req.DepositAmount < 0 || req.MinSpend < 0 {
+		return ErrInvalidTableDeposit
+	}
+
+	rules := &domain.Table{
+		LocationType:           req.LocationType,
+		MinDurationMinutes:     ruleOverride(req.MinDurationMinutes),
+		SlotGranularityMinutes: ruleOverride(req.SlotGranularityMinutes),
+	}
+	return validateTableBookingRules(restaurant, rules)
+}
+
+func rejectedImportRows(rows []TableImportRow) []TableImportRow {
+	var rejected []TableImportRow
+	for _, row := range rows {
+		if row.Err != nil {
+			rejected = append(rejected, row)
+		}
+	}
+	return rejected
+}
+
+// parseTableImport reads a CSV whose header names its columns, in any order.
+// A data line whose fields cannot be read comes back with its error; a file
+// that cannot be read as a whole fails with ErrInvalidTableImport.
+func parseTableImport(r io.Reader) ([]tableImportLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidTableImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTableImport, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheets often save UTF-8 with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(tableImportColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidTableImport, name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrInvalidTableImport, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range tableImportColumns[:4] {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidTableImport, name)
+		}
+	}
+
+	var lines []tableImportLine
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTableImport, err)
+		}
+		if len(lines) == MaxTableImportRows {
+			return nil, fmt.Errorf("%w: at most %d tables can be imported at once", ErrInvalidTableImport, MaxTableImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		lines = append(lines, parseTableImportLine(line, columns, len(header), record))
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: the file has no tables", ErrInvalidTableImport)
+	}
+
+	return lines, nil
+}
+
+func parseTableImportLine(line int, columns map[string]int, width int, record []string) tableImportLine {
+	parsed := tableImportLine{line: line}
+	if len(record) != width {
+		parsed.err = fmt.Errorf("expected %d fields, got %d", width, len(record))
+		return parsed
+	}
+
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	parsed.req = CreateTableRequest{
+		TableNumber:  field("table_number"),
+		LocationType: domain.LocationType(strings.ToLower(field("location_type"))),
+	}
+	for _, capacity := range []struct {
+		name  string
+		value *int
+	}{
+		{"min_capacity", &parsed.req.MinCapacity},
+		{"max_capacity", &parsed.req.MaxCapacity},
+	} {
+		n, err := strconv.Atoi(field(capacity.name))
+		if err != nil || n < 1 {
+			parsed.err = fmt.Errorf("%s must be a positive integer", capacity.name)
+			return parsed
+		}
+		*capacity.value = n
+	}
+	for _, position := range []struct {
+		name  string
+		value **int
+	}{
+		{"x", &parsed.req.XPosition},
+		{"y", &parsed.req.YPosition},
+	} {
+		raw := field(position.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			parsed.err = fmt.Errorf("%s must be an integer", position.name)
+			return parsed
+		}
+		*position.value = &n
+	}
+
+	return parsed
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const tableImportCSV = "\ufefftable_number,min_capacity,max_capacity,location_type,x,y\n" +
+	"T1,2,4,window,10,20\n" +
+	"T2,4,2,regular,,\n" +
+	"T3,2,6,Terrace,,\n" +
+	"T1,2,4,vip,,\n" +
+	"T4,two,4,vip,,\n" +
+	"T5,4,8,outdoor\n" +
+	"T6,4,8,OUTDOOR,,\n"
+
+func TestImportTables_SkipsRejectedRows(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
+	// T1 is new, T6 is already an active table of the restaurant.
+	sqlMock.ExpectQuery(`SELECT (.+) FROM "tables"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	sqlMock.ExpectQuery(`SELECT (.+) FROM "tables"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	rows, err := service.ImportTables(ctx, restaurant.ID, restaurant.OwnerID, strings.NewReader(tableImportCSV), false)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 7)
+	for i, row := range rows {
+		assert.Equal(t, i+2, row.Line)
+	}
+
+	require.NotNil(t, rows[0].Table)
+	assert.Equal(t, "T1", rows[0].Table.TableNumber)
+	assert.Equal(t, domain.LocationWindow, rows[0].Table.LocationType)
+	assert.Equal(t, 10, *rows[0].Table.XPosition)
+	assert.Equal(t, 20, *rows[0].Table.YPosition)
+
+	assert.ErrorIs(t, rows[1].Err, ErrInvalidCapacity)
+	assert.ErrorIs(t, rows[2].Err, ErrInvalidLocationType)
+	assert.ErrorIs(t, rows[3].Err, ErrDuplicateTableNumber)
+	assert.ErrorContains(t, rows[3].Err, "line 2")
+	assert.ErrorContains(t, rows[4].Err, "min_capacity")
+	assert.ErrorContains(t, rows[5].Err, "expected 6 fields")
+	assert.ErrorIs(t, rows[6].Err, ErrDuplicateTableNumber)
+	for _, row := range rows[1:] {
+		assert.Nil(t, row.Table)
+	}
+
+	mockTableRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestImportTables_StrictCreatesNothing(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	rows, err := service.ImportTables(ctx, restaurant.ID, restaurant.OwnerID, strings.NewReader(tableImportCSV), true)
+
+	assert.Nil(t, rows)
+	var rejected *TableImportError
+	require.ErrorAs(t, err, &rejected)
+	assert.ErrorIs(t, err, ErrInvalidTableImport)
+	lines := make([]int, len(rejected.Rows))
+	for i, row := range rejected.Rows {
+		lines[i] = row.Line
+	}
+	assert.Equal(t, []int{3, 4, 5, 6, 7}, lines)
+	mockTableRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestImportTables_StrictRollsBackOnExistingNumber(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	csv := "location_type,table_number,min_capacity,max_capacity\nwindow,T1,2,4\nvip,T2,2,4\n"
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
+	sqlMock.ExpectQuery(`SELECT (.+) FROM "tables"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	sqlMock.ExpectQuery(`SELECT (.+) FROM "tables"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectRollback()
+
+	rows, err := service.ImportTables(ctx, restaurant.ID, restaurant.OwnerID, strings.NewReader(csv), true)
+
+	assert.Nil(t, rows)
+	var rejected *TableImportError
+	require.ErrorAs(t, err, &rejected)
+	require.Len(t, rejected.Rows, 1)
+	assert.Equal(t, 3, rejected.Rows[0].Line)
+	assert.Equal(t, "T2", rejected.Rows[0].TableNumber)
+	mockTableRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestImportTables_InvalidFile(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"header only":    "table_number,min_capacity,max_capacity,location_type\n",
+		"missing column": "table_number,min_capacity,location_type\nT1,2,window\n",
+		"unknown column": "table_number,min_capacity,max_capacity,location_type,seats\nT1,2,4,window,4\n",
+		"bad quoting":    "table_number,min_capacity,max_capacity,location_type\n\"T1,2,4,window\n",
+		"too many rows":  "table_number,min_capacity,max_capacity,location_type\n" + strings.Repeat("T,2,4,window\n", MaxTableImportRows+1),
+	}
+	for name, csv := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, _, mockRestaurantRepo, _, _ := setupTableService()
+			ctx := context.Background()
+			restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+			mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+			_, err := service.ImportTables(ctx, restaurant.ID, restaurant.OwnerID, strings.NewReader(csv), false)
+
+			assert.ErrorIs(t, err, ErrInvalidTableImport)
+		})
+	}
+}
+
+func TestImportTables_NotOwner(t *testing.T) {
+	service, _, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, err := service.ImportTables(ctx, restaurant.ID, uuid.New(), strings.NewReader(tableImportCSV), false)
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultQRCodeSize is the side of a table QR code in pixels when none
+	// is asked for.
+	DefaultQRCodeSize = 512
+	MinQRCodeSize     = 128
+	MaxQRCodeSize     = 2048
+)
+
+var ErrInvalidQRCodeSize = errors.New("size must be between 128 and 2048 pixels")
+
+// TableQRCode is the printable code of one table.
+type TableQRCode struct {
+	Table *domain.Table
+	PNG   []byte
+}
+
+// TableQRService renders the QR codes restaurants print for their tables.
+// Each code opens the public URL built from the configured template.
+type TableQRService interface {
+	// TableCode renders the code of one table. Staff of its restaurant may
+	// render it.
+	TableCode(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, size int) (*TableQRCode, error)
+	// RestaurantCodes renders the codes of every active table of the
+	// restaurant, ordered by table number.
+	RestaurantCodes(ctx context.Context, restaurantID uuid.UUID, staffID uuid.UUID, size int) ([]TableQRCode, error)
+}
+
+type tableQRService struct {
+	tableRepo      repository.TableRepository
+	restaurantRepo repository.RestaurantRepository
+	authz          RestaurantAuthorizer
+	urlTemplate    string
+}
+
+// NewTableQRService encodes urlTemplate with {restaurant_id} and {table_id}
+// replaced by the table's IDs.
+func NewTableQRService(tableRepo repository.TableRepository, restaurantRepo repository.RestaurantRepository, authz RestaurantAuthorizer, urlTemplate string) TableQRService {
+	return &tableQRService{
+		tableRepo:      tableRepo,
+		restaurantRepo: restaurantRepo,
+		authz:          authz,
+		urlTemplate:    urlTemplate,
+	}
+}
+
+func (s *tableQRService) TableCode(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, size int) (*TableQRCode, error) {
+	if err := validateQRCodeSize(size); err != nil {
+		return nil, err
+	}
+
+	table, err := s.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, err
+	}
+	if table.Restaurant == nil {
+		return nil, ErrRestaurantNotFound
+	}
+
+	if err := s.authz.CanStaffRestaurant(ctx, table.Restaurant, staffID, "table.qr_code"); err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(s.tableURL(table), qrcode.Medium, size)
+	if err != nil {
+		return nil, err
+	}
+	return &TableQRCode{Table: table, PNG: png}, nil
+}
+
+func (s *tableQRService) RestaurantCodes(ctx context.Context, restaurantID uuid.UUID, staffID uuid.UUID, size int) ([]TableQRCode, error) {
+	if err := validateQRCodeSize(size); err != nil {
+		return nil, err
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.authz.CanStaffRestaurant(ctx, restaurant, staffID, "table.qr_codes"); err != nil {
+		return nil, err
+	}
+
+	tables, err := s.tableRepo.GetByRestaurantID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]TableQRCode, len(tables))
+	for i, table := range tables {
+		png, err := qrcode.Encode(s.tableURL(table), qrcode.Medium, size)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = TableQRCode{Table: table, PNG: png}
+	}
+	return codes, nil
+}
+
+func (s *tableQRService) tableURL(table *domain.Table) string {
+	return strings.NewReplacer(
+		"{restaurant_id}", table.RestaurantID.String(),
+		"{table_id}", table.ID.String(),
+	).Replace(s.urlTemplate)
+}
+
+func validateQRCodeSize(size int) error {
+	if size < MinQRCodeSize || size > MaxQRCodeSize {
+		return ErrInvalidQRCodeSize
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"restaurant-booking/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testQRURLTemplate = "https://book.example.kz/r/{restaurant_id}?table={table_id}"
+
+func setupTableQRService() (*tableQRService, *MockTableRepository, *MockRestaurantRepository, *MockRestaurantManagerRepository) {
+	tableRepo := new(MockTableRepository)
+	restaurantRepo := new(MockRestaurantRepository)
+	managerRepo := new(MockRestaurantManagerRepository)
+	authz := NewRestaurantAuthorizer(managerRepo, new(MockAuditRecorder))
+	return NewTableQRService(tableRepo, restaurantRepo, authz, testQRURLTemplate).(*tableQRService), tableRepo, restaurantRepo, managerRepo
+}
+
+func TestTableCode_ManagerGetsPNG(t *testing.T) {
+	svc, tableRepo, _, managerRepo := setupTableQRService()
+	ctx := context.Background()
+	managerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", Restaurant: restaurant}
+
+	tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	managerRepo.On("IsManager", ctx, managerID, restaurant.ID).Return(true, nil)
+
+	code, err := svc.TableCode(ctx, table.ID, managerID, 256)
+
+	require.NoError(t, err)
+	assert.Same(t, table, code.Table)
+	img, err := png.Decode(bytes.NewReader(code.PNG))
+	require.NoError(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+}
+
+func TestTableCode_Rejections(t *testing.T) {
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	strangerID := uuid.New()
+
+	t.Run("not staff", func(t *testing.T) {
+		svc, tableRepo, _, managerRepo := setupTableQRService()
+		tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+		managerRepo.On("IsManager", ctx, strangerID, restaurant.ID).Return(false, nil)
+
+		_, err := svc.TableCode(ctx, table.ID, strangerID, DefaultQRCodeSize)
+
+		assert.ErrorIs(t, err, ErrNotRestaurantStaff)
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		svc, tableRepo, _, _ := setupTableQRService()
+		tableRepo.On("GetByID", ctx, table.ID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.TableCode(ctx, table.ID, strangerID, DefaultQRCodeSize)
+
+		assert.ErrorIs(t, err, ErrTableNotFound)
+	})
+
+	for _, size := range []int{MinQRCodeSize - 1, MaxQRCodeSize + 1} {
+		svc, tableRepo, _, _ := setupTableQRService()
+
+		_, err := svc.TableCode(ctx, table.ID, restaurant.OwnerID, size)
+
+		assert.ErrorIs(t, err, ErrInvalidQRCodeSize)
+		tableRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	}
+}
+
+func TestRestaurantCodes_OneCodePerTable(t *testing.T) {
+	svc, tableRepo, restaurantRepo, _ := setupTableQRService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	tables := []*domain.Table{
+		{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1"},
+		{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T2"},
+	}
+
+	restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	tableRepo.On("GetByRestaurantID", ctx, restaurant.ID).Return(tables, nil)
+
+	codes, err := svc.RestaurantCodes(ctx, restaurant.ID, restaurant.OwnerID, MinQRCodeSize)
+
+	require.NoError(t, err)
+	require.Len(t, codes, 2)
+	assert.Same(t, tables[1], codes[1].Table)
+	assert.NotEmpty(t, codes[1].PNG)
+}
+
+func TestTableURL_FillsTemplate(t *testing.T) {
+	svc, _, _, _ := setupTableQRService()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: uuid.New()}
+
+	assert.Equal(t, "https://book.example.kz/r/"+table.RestaurantID.String()+"?table="+table.ID.String(), svc.tableURL(table))
+}
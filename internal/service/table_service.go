@@ -4,19 +4,36 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrTableNotFound        = errors.New("table not found")
-	ErrInvalidTableNumber   = errors.New("table number cannot be empty")
-	ErrInvalidCapacity      = errors.New("min_capacity must be less than or equal to max_capacity")
-	ErrDuplicateTableNumber = errors.New("table number already exists for this restaurant")
+	ErrTableNotFound          = errors.New("table not found")
+	ErrInvalidTableNumber     = errors.New("table number cannot be empty")
+	ErrInvalidCapacity        = errors.New("min_capacity must be less than or equal to max_capacity")
+	ErrDuplicateTableNumber   = repository.ErrDuplicateTableNumber
+	ErrTableBlockNotFound     = errors.New("table block not found")
+	ErrInvalidBlockPeriod     = errors.New("block must end after it starts")
+	ErrInvalidTablePositions  = errors.New("invalid table positions")
+	ErrTableHasActiveBookings = errors.New("table has upcoming bookings")
+	ErrInvalidScheduleSlot    = errors.New("slot_minutes must be between 5 and 240")
+	ErrInvalidTableDeposit    = errors.New("deposit_amount and min_spend cannot be negative")
+)
+
+const (
+	// DefaultScheduleSlotMinutes is the slot length of a table's day
+	// schedule when none is asked for.
+	DefaultScheduleSlotMinutes = 30
+	MinScheduleSlotMinutes     = 5
+	MaxScheduleSlotMinutes     = 240
 )
 
 type CreateTableRequest struct {
@@ -26,6 +43,13 @@ type CreateTableRequest struct {
 	LocationType domain.LocationType
 	XPosition    *int
 	YPosition    *int
+	// MinDurationMinutes and SlotGranularityMinutes override the zone and
+	// restaurant booking rules when set.
+	MinDurationMinutes     *int
+	SlotGranularityMinutes *int
+	// DepositAmount and MinSpend of 0 mean the table has none.
+	DepositAmount int
+	MinSpend      int
 }
 
 type UpdateTableRequest struct {
@@ -36,31 +60,160 @@ type UpdateTableRequest struct {
 	XPosition    *int
 	YPosition    *int
 	IsActive     *bool
+	// Force cancels the table's upcoming bookings when IsActive takes it out
+	// of service, instead of failing with a *TableHasBookingsError.
+	Force bool
+	// MinDurationMinutes and SlotGranularityMinutes of 0 drop the table's
+	// override, so the zone and restaurant rules apply again.
+	MinDurationMinutes     *int
+	SlotGranularityMinutes *int
+	DepositAmount          *int
+	MinSpend               *int
 }
 
 type BulkCreateTablesRequest struct {
 	Tables []CreateTableRequest
 }
 
+// TablePosition places one table on the floor plan. Nil coordinates take the
+// table off the plan.
+type TablePosition struct {
+	TableID   uuid.UUID
+	XPosition *int
+	YPosition *int
+}
+
+// TablePositionIssue is why the entry at Index of a position update was
+// rejected.
+type TablePositionIssue struct {
+	Index   int
+	TableID uuid.UUID
+	Err     error
+}
+
+// TablePositionsError is returned by UpdateTablePositions when some entries
+// were rejected. None of the entries was applied.
+type TablePositionsError struct {
+	Issues []TablePositionIssue
+}
+
+func (e *TablePositionsError) Error() string {
+	return fmt.Sprintf("%s: %d of the entries were rejected", ErrInvalidTablePositions, len(e.Issues))
+}
+
+func (e *TablePositionsError) Unwrap() error {
+	return ErrInvalidTablePositions
+}
+
+// TableHasBookingsError is returned when a table with upcoming bookings would
+// be taken out of service. BookingIDs are those bookings, soonest first.
+type TableHasBookingsError struct {
+	BookingIDs []uuid.UUID
+}
+
+func (e *TableHasBookingsError) Error() string {
+	return fmt.Sprintf("%s: %d bookings", ErrTableHasActiveBookings, len(e.BookingIDs))
+}
+
+func (e *TableHasBookingsError) Unwrap() error {
+	return ErrTableHasActiveBookings
+}
+
+var (
+	errTableNotInRestaurant  = errors.New("not an active table of this restaurant")
+	errTablePositionRepeated = errors.New("table is listed more than once")
+)
+
+// TableSchedule is a table's day from opening to closing, divided into
+// slots. Day is the day's midnight in the restaurant's time zone, and the
+// slots are on the same clock.
+type TableSchedule struct {
+	Table *domain.Table
+	Day   time.Time
+	Slots []ScheduleSlot
+}
+
+// ScheduleSlot is free when BookingID is nil. Otherwise BookingID is the
+// earliest booking holding the table during the slot.
+type ScheduleSlot struct {
+	Start     time.Time
+	End       time.Time
+	BookingID *uuid.UUID
+}
+
+type BlockTableRequest struct {
+	StartsAt time.Time
+	EndsAt   time.Time
+	Reason   string
+}
+
 type TableService interface {
 	CreateTable(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req CreateTableRequest) (*domain.Table, error)
+	GetTable(ctx context.Context, id uuid.UUID) (*domain.Table, error)
 	GetTablesByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.Table, error)
+	// GetAvailableTables returns the restaurant's active tables seating at
+	// least minCapacity guests that no booking holds between start and end.
+	GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error)
+	// GetFloorPlan lays out the active tables of an active restaurant and
+	// whether each is booked at the given time.
+	GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]repository.FloorPlanTable, error)
 	UpdateTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, req UpdateTableRequest) (*domain.Table, error)
-	DeleteTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) error
+	// DeleteTable takes the table out of service. While it has upcoming
+	// bookings it fails with a *TableHasBookingsError, unless force is set:
+	// then the bookings are cancelled and their customers emailed.
+	DeleteTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, force bool) error
 	BulkCreateTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req BulkCreateTablesRequest) ([]*domain.Table, error)
+	// ImportTables creates the tables listed in a CSV with the columns
+	// table_number, min_capacity, max_capacity, location_type and optionally
+	// x and y, checked like BulkCreateTables. Rejected rows are skipped and
+	// reported, unless strict is set: then a *TableImportError creates none.
+	ImportTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, csv io.Reader, strict bool) ([]TableImportRow, error)
+	// UpdateTablePositions moves several tables of the restaurant at once,
+	// all or none. Entries naming a table that is not an active table of the
+	// restaurant make it fail with a *TablePositionsError.
+	UpdateTablePositions(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, positions []TablePosition) ([]*domain.Table, error)
+	// BlockTable takes the table out of service for maintenance. Staff of the
+	// restaurant may block its tables.
+	BlockTable(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, req BlockTableRequest) (*domain.TableBlock, error)
+	// ListTableBlocks returns the table's current and upcoming blocks.
+	ListTableBlocks(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID) ([]*domain.TableBlock, error)
+	UnblockTable(ctx context.Context, tableID uuid.UUID, blockID uuid.UUID, staffID uuid.UUID) error
+	// GetTableSchedule divides the table's opening on date, read as a day in
+	// the restaurant's time zone, into slots of slotMinutes. Staff of the
+	// restaurant may view it. A closed day has no slots.
+	GetTableSchedule(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, date time.Time, slotMinutes int) (*TableSchedule, error)
 }
 
 type tableService struct {
-	tableRepo      repository.TableRepository
-	restaurantRepo repository.RestaurantRepository
-	db             *gorm.DB
+	tableRepo       repository.TableRepository
+	blockRepo       repository.TableBlockRepository
+	bookingRepo     repository.BookingRepository
+	restaurantRepo  repository.RestaurantRepository
+	authz           RestaurantAuthorizer
+	notificationSvc *NotificationService
+	db              *gorm.DB
+	log             logger.Logger
 }
 
-func NewTableService(tableRepo repository.TableRepository, restaurantRepo repository.RestaurantRepository, db *gorm.DB) TableService {
+func NewTableService(
+	tableRepo repository.TableRepository,
+	blockRepo repository.TableBlockRepository,
+	bookingRepo repository.BookingRepository,
+	restaurantRepo repository.RestaurantRepository,
+	authz RestaurantAuthorizer,
+	notificationSvc *NotificationService,
+	db *gorm.DB,
+	log logger.Logger,
+) TableService {
 	return &tableService{
-		tableRepo:      tableRepo,
-		restaurantRepo: restaurantRepo,
-		db:             db,
+		tableRepo:       tableRepo,
+		blockRepo:       blockRepo,
+		bookingRepo:     bookingRepo,
+		restaurantRepo:  restaurantRepo,
+		authz:           authz,
+		notificationSvc: notificationSvc,
+		db:              db,
+		log:             log,
 	}
 }
 
@@ -73,8 +226,8 @@ func (s *tableService) CreateTable(ctx context.Context, restaurantID uuid.UUID,
 		return nil, err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.create"); err != nil {
+		return nil, err
 	}
 
 	if strings.TrimSpace(req.TableNumber) == "" {
@@ -85,32 +238,76 @@ func (s *tableService) CreateTable(ctx context.Context, restaurantID uuid.UUID,
 		return nil, ErrInvalidCapacity
 	}
 
-	if err := s.checkDuplicateTableNumber(ctx, restaurantID, req.TableNumber, uuid.Nil); err != nil {
-		return nil, err
+	if req.DepositAmount < 0 || req.MinSpend < 0 {
+		return nil, ErrInvalidTableDeposit
 	}
 
 	table := &domain.Table{
-		RestaurantID: restaurantID,
-		TableNumber:  req.TableNumber,
-		MinCapacity:  req.MinCapacity,
-		MaxCapacity:  req.MaxCapacity,
-		LocationType: req.LocationType,
-		XPosition:    req.XPosition,
-		YPosition:    req.YPosition,
-		IsActive:     true,
+		RestaurantID:  restaurantID,
+		TableNumber:   req.TableNumber,
+		MinCapacity:   req.MinCapacity,
+		MaxCapacity:   req.MaxCapacity,
+		LocationType:  req.LocationType,
+		XPosition:     req.XPosition,
+		YPosition:     req.YPosition,
+		DepositAmount: req.DepositAmount,
+		MinSpend:      req.MinSpend,
+		IsActive:      true,
+
+		MinDurationMinutes:     ruleOverride(req.MinDurationMinutes),
+		SlotGranularityMinutes: ruleOverride(req.SlotGranularityMinutes),
+	}
+	if err := validateTableBookingRules(restaurant, table); err != nil {
+		return nil, err
 	}
 
-	if err := s.tableRepo.Create(ctx, table); err != nil {
+	err = s.withRestaurantLock(ctx, restaurantID, func(tx *gorm.DB, tableRepo repository.TableRepository) error {
+		if err := s.checkDuplicateTableNumber(ctx, tx, restaurantID, req.TableNumber, uuid.Nil); err != nil {
+			return err
+		}
+		return tableRepo.Create(ctx, table)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return table, nil
 }
 
+func (s *tableService) GetTable(ctx context.Context, id uuid.UUID) (*domain.Table, error) {
+	table, err := s.tableRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, err
+	}
+	return table, nil
+}
+
 func (s *tableService) GetTablesByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*domain.Table, error) {
 	return s.tableRepo.GetByRestaurantID(ctx, restaurantID)
 }
 
+func (s *tableService) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error) {
+	return s.tableRepo.GetAvailableTables(ctx, restaurantID, minCapacity, start, end)
+}
+
+func (s *tableService) GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]repository.FloorPlanTable, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	if !restaurant.IsActive {
+		return nil, ErrRestaurantNotFound
+	}
+
+	return s.tableRepo.GetFloorPlan(ctx, restaurantID, at)
+}
+
 func (s *tableService) UpdateTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, req UpdateTableRequest) (*domain.Table, error) {
 
 	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
@@ -121,8 +318,8 @@ func (s *tableService) UpdateTable(ctx context.Context, id uuid.UUID, restaurant
 		return nil, err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.update"); err != nil {
+		return nil, err
 	}
 
 	table, err := s.tableRepo.GetByID(ctx, id)
@@ -141,9 +338,6 @@ func (s *tableService) UpdateTable(ctx context.Context, id uuid.UUID, restaurant
 		if strings.TrimSpace(*req.TableNumber) == "" {
 			return nil, ErrInvalidTableNumber
 		}
-		if err := s.checkDuplicateTableNumber(ctx, restaurantID, *req.TableNumber, id); err != nil {
-			return nil, err
-		}
 		table.TableNumber = *req.TableNumber
 	}
 
@@ -171,18 +365,59 @@ func (s *tableService) UpdateTable(ctx context.Context, id uuid.UUID, restaurant
 		table.YPosition = req.YPosition
 	}
 
+	deactivating := req.IsActive != nil && table.IsActive && !*req.IsActive
+	reactivating := req.IsActive != nil && !table.IsActive && *req.IsActive
 	if req.IsActive != nil {
 		table.IsActive = *req.IsActive
 	}
 
-	if err := s.tableRepo.Update(ctx, table); err != nil {
+	if req.MinDurationMinutes != nil {
+		table.MinDurationMinutes = ruleOverride(req.MinDurationMinutes)
+	}
+
+	if req.SlotGranularityMinutes != nil {
+		table.SlotGranularityMinutes = ruleOverride(req.SlotGranularityMinutes)
+	}
+
+	if req.DepositAmount != nil {
+		table.DepositAmount = *req.DepositAmount
+	}
+
+	if req.MinSpend != nil {
+		table.MinSpend = *req.MinSpend
+	}
+
+	if table.DepositAmount < 0 || table.MinSpend < 0 {
+		return nil, ErrInvalidTableDeposit
+	}
+
+	if err := validateTableBookingRules(restaurant, table); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case deactivating:
+		err = s.saveDeactivated(ctx, restaurant, table, req.Force)
+	case req.TableNumber != nil || reactivating:
+		// As in CreateTable, the lock keeps another table from taking the
+		// number between the check and the update.
+		err = s.withRestaurantLock(ctx, restaurantID, func(tx *gorm.DB, tableRepo repository.TableRepository) error {
+			if err := s.checkDuplicateTableNumber(ctx, tx, restaurantID, table.TableNumber, id); err != nil {
+				return err
+			}
+			return tableRepo.Update(ctx, table)
+		})
+	default:
+		err = s.tableRepo.Update(ctx, table)
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return table, nil
 }
 
-func (s *tableService) DeleteTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID) error {
+func (s *tableService) DeleteTable(ctx context.Context, id uuid.UUID, restaurantID uuid.UUID, ownerID uuid.UUID, force bool) error {
 	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -191,8 +426,8 @@ func (s *tableService) DeleteTable(ctx context.Context, id uuid.UUID, restaurant
 		return err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.delete"); err != nil {
+		return err
 	}
 
 	table, err := s.tableRepo.GetByID(ctx, id)
@@ -208,7 +443,48 @@ func (s *tableService) DeleteTable(ctx context.Context, id uuid.UUID, restaurant
 	}
 
 	table.IsActive = false
-	return s.tableRepo.Update(ctx, table)
+	return s.saveDeactivated(ctx, restaurant, table, force)
+}
+
+// saveDeactivated saves a table that was just taken out of service. Its
+// upcoming bookings make it fail with a *TableHasBookingsError, unless force
+// is set: then they are cancelled along with it and their customers emailed
+// once that is committed.
+func (s *tableService) saveDeactivated(ctx context.Context, restaurant *domain.Restaurant, table *domain.Table, force bool) error {
+	bookings, err := s.bookingRepo.GetUpcomingByTable(ctx, table.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(bookings) == 0 {
+		return s.tableRepo.Update(ctx, table)
+	}
+
+	if !force {
+		ids := make([]uuid.UUID, len(bookings))
+		for i, booking := range bookings {
+			ids[i] = booking.ID
+		}
+		return &TableHasBookingsError{BookingIDs: ids}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bookingRepo := s.bookingRepo.WithTx(tx)
+		for _, booking := range bookings {
+			booking.Status = domain.BookingStatusCancelled
+			if err := bookingRepo.Update(ctx, booking); err != nil {
+				return err
+			}
+		}
+		return s.tableRepo.WithTx(tx).Update(ctx, table)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, booking := range bookings {
+		notifyCancellation(s.notificationSvc, s.log, restaurant, booking, nil)
+	}
+	return nil
 }
 
 func (s *tableService) BulkCreateTables(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, req BulkCreateTablesRequest) ([]*domain.Table, error) {
@@ -220,58 +496,273 @@ func (s *tableService) BulkCreateTables(ctx context.Context, restaurantID uuid.U
 		return nil, err
 	}
 
-	if restaurant.OwnerID != ownerID {
-		return nil, ErrUnauthorized
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.bulk_create"); err != nil {
+		return nil, err
 	}
 
 	tableNumbers := make(map[string]bool)
 	for i, tableReq := range req.Tables {
-		if strings.TrimSpace(tableReq.TableNumber) == "" {
-			return nil, fmt.Errorf("table at index %d: %w", i, ErrInvalidTableNumber)
-		}
-
-		if tableReq.MinCapacity > tableReq.MaxCapacity {
-			return nil, fmt.Errorf("table at index %d: %w", i, ErrInvalidCapacity)
+		if err := validateNewTable(restaurant, tableReq); err != nil {
+			return nil, fmt.Errorf("table at index %d: %w", i, err)
 		}
 
 		if tableNumbers[tableReq.TableNumber] {
-			return nil, fmt.Errorf("table at index %d: duplicate table number '%s' in request", i, tableReq.TableNumber)
+			return nil, fmt.Errorf("table at index %d: duplicate table number '%s' in request: %w", i, tableReq.TableNumber, ErrDuplicateTableNumber)
 		}
 		tableNumbers[tableReq.TableNumber] = true
-
-		if err := s.checkDuplicateTableNumber(ctx, restaurantID, tableReq.TableNumber, uuid.Nil); err != nil {
-			return nil, fmt.Errorf("table at index %d: %w", i, err)
-		}
 	}
 
 	tables := make([]*domain.Table, len(req.Tables))
-	for i, tableReq := range req.Tables {
-		table := &domain.Table{
-			RestaurantID: restaurantID,
-			TableNumber:  tableReq.TableNumber,
-			MinCapacity:  tableReq.MinCapacity,
-			MaxCapacity:  tableReq.MaxCapacity,
-			LocationType: tableReq.LocationType,
-			XPosition:    tableReq.XPosition,
-			YPosition:    tableReq.YPosition,
-			IsActive:     true,
+	err = s.withRestaurantLock(ctx, restaurantID, func(tx *gorm.DB, tableRepo repository.TableRepository) error {
+		for i, tableReq := range req.Tables {
+			if err := s.checkDuplicateTableNumber(ctx, tx, restaurantID, tableReq.TableNumber, uuid.Nil); err != nil {
+				return fmt.Errorf("table at index %d: %w", i, err)
+			}
 		}
 
-		if err := s.tableRepo.Create(ctx, table); err != nil {
-			return nil, fmt.Errorf("failed to create table at index %d: %w", i, err)
+		for i, tableReq := range req.Tables {
+			table := &domain.Table{
+				RestaurantID:  restaurantID,
+				TableNumber:   tableReq.TableNumber,
+				MinCapacity:   tableReq.MinCapacity,
+				MaxCapacity:   tableReq.MaxCapacity,
+				LocationType:  tableReq.LocationType,
+				XPosition:     tableReq.XPosition,
+				YPosition:     tableReq.YPosition,
+				DepositAmount: tableReq.DepositAmount,
+				MinSpend:      tableReq.MinSpend,
+				IsActive:      true,
+
+				MinDurationMinutes:     ruleOverride(tableReq.MinDurationMinutes),
+				SlotGranularityMinutes: ruleOverride(tableReq.SlotGranularityMinutes),
+			}
+
+			if err := tableRepo.Create(ctx, table); err != nil {
+				return fmt.Errorf("failed to create table at index %d: %w", i, err)
+			}
+
+			tables[i] = table
 		}
 
-		tables[i] = table
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return tables, nil
 }
 
-func (s *tableService) checkDuplicateTableNumber(ctx context.Context, restaurantID uuid.UUID, tableNumber string, excludeID uuid.UUID) error {
+func (s *tableService) UpdateTablePositions(ctx context.Context, restaurantID uuid.UUID, ownerID uuid.UUID, positions []TablePosition) ([]*domain.Table, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.authz.CanManageRestaurant(ctx, restaurant, ownerID, "table.update_positions"); err != nil {
+		return nil, err
+	}
+
+	moved := make([]*domain.Table, len(positions))
+	// The lock keeps tables from being created or moved concurrently between
+	// loading them and saving the new positions.
+	err = s.withRestaurantLock(ctx, restaurantID, func(tx *gorm.DB, tableRepo repository.TableRepository) error {
+		tables, err := tableRepo.GetByRestaurantID(ctx, restaurantID)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uuid.UUID]*domain.Table, len(tables))
+		for _, table := range tables {
+			byID[table.ID] = table
+		}
+
+		var issues []TablePositionIssue
+		seen := make(map[uuid.UUID]bool, len(positions))
+		for i, position := range positions {
+			table, ok := byID[position.TableID]
+			switch {
+			case !ok:
+				issues = append(issues, TablePositionIssue{Index: i, TableID: position.TableID, Err: errTableNotInRestaurant})
+			case seen[position.TableID]:
+				issues = append(issues, TablePositionIssue{Index: i, TableID: position.TableID, Err: errTablePositionRepeated})
+			default:
+				moved[i] = table
+			}
+			seen[position.TableID] = true
+		}
+		if len(issues) > 0 {
+			return &TablePositionsError{Issues: issues}
+		}
+
+		for i, position := range positions {
+			moved[i].XPosition = position.XPosition
+			moved[i].YPosition = position.YPosition
+			if err := tableRepo.Update(ctx, moved[i]); err != nil {
+				return fmt.Errorf("failed to move table at index %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// ruleOverride stores a booking rule override, dropping it when it is 0.
+func ruleOverride(minutes *int) *int {
+	if minutes == nil || *minutes == 0 {
+		return nil
+	}
+	value := *minutes
+	return &value
+}
+
+func (s *tableService) BlockTable(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, req BlockTableRequest) (*domain.TableBlock, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, ErrInvalidBlockPeriod
+	}
+
+	if _, err := s.staffTable(ctx, tableID, staffID, "table.block"); err != nil {
+		return nil, err
+	}
+
+	block := &domain.TableBlock{
+		TableID:   tableID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: staffID,
+	}
+	if err := s.blockRepo.Create(ctx, block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+func (s *tableService) ListTableBlocks(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID) ([]*domain.TableBlock, error) {
+	if _, err := s.staffTable(ctx, tableID, staffID, "table.list_blocks"); err != nil {
+		return nil, err
+	}
+	return s.blockRepo.ListByTable(ctx, tableID, time.Now())
+}
+
+func (s *tableService) UnblockTable(ctx context.Context, tableID uuid.UUID, blockID uuid.UUID, staffID uuid.UUID) error {
+	if _, err := s.staffTable(ctx, tableID, staffID, "table.unblock"); err != nil {
+		return err
+	}
+
+	block, err := s.blockRepo.GetByID(ctx, blockID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTableBlockNotFound
+		}
+		return err
+	}
+	if block.TableID != tableID {
+		return ErrTableBlockNotFound
+	}
+
+	return s.blockRepo.Delete(ctx, blockID)
+}
+
+func (s *tableService) GetTableSchedule(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, date time.Time, slotMinutes int) (*TableSchedule, error) {
+	if slotMinutes < MinScheduleSlotMinutes || slotMinutes > MaxScheduleSlotMinutes {
+		return nil, ErrInvalidScheduleSlot
+	}
+
+	table, err := s.staffTable(ctx, tableID, staffID, "table.schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, table.Restaurant.Location())
+	schedule := &TableSchedule{Table: table, Day: day, Slots: []ScheduleSlot{}}
+	opens, closes, open := openingHours(table.Restaurant, day)
+	if !open {
+		return schedule, nil
+	}
+
+	// One query covers the whole day; a booking spanning several slots holds
+	// each of them.
+	bookings, err := s.bookingRepo.GetOverlappingByTable(ctx, tableID, opens, closes)
+	if err != nil {
+		return nil, err
+	}
+
+	length := time.Duration(slotMinutes) * time.Minute
+	for start := opens; start.Before(closes); start = start.Add(length) {
+		slot := ScheduleSlot{Start: start, End: start.Add(length)}
+		if slot.End.After(closes) {
+			slot.End = closes
+		}
+		for _, booking := range bookings {
+			if booking.StartTime.Before(slot.End) && booking.EndTime.After(slot.Start) {
+				slot.BookingID = &booking.ID
+				break
+			}
+		}
+		schedule.Slots = append(schedule.Slots, slot)
+	}
+	return schedule, nil
+}
+
+// openingHours returns when the restaurant opens and closes for the opening
+// that begins on day, a midnight. Like seatingWindow, a day without a usable
+// schedule is open around the clock.
+func openingHours(restaurant *domain.Restaurant, day time.Time) (opens, closes time.Time, open bool) {
+	opens, closes, closed, ok := dayHours(restaurant.WorkingHours, day)
+	switch {
+	case !ok:
+		return day, day.AddDate(0, 0, 1), true
+	case closed:
+		return time.Time{}, time.Time{}, false
+	}
+	return opens, closes, true
+}
+
+// staffTable loads the table and checks that staffID is staff of its
+// restaurant.
+func (s *tableService) staffTable(ctx context.Context, tableID uuid.UUID, staffID uuid.UUID, action string) (*domain.Table, error) {
+	table, err := s.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, err
+	}
+	if table.Restaurant == nil {
+		return nil, ErrRestaurantNotFound
+	}
+
+	if err := s.authz.CanStaffRestaurant(ctx, table.Restaurant, staffID, action); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// withRestaurantLock runs fn in a transaction holding a Postgres advisory lock
+// keyed by the restaurant, so concurrent table creation for the same restaurant
+// is serialized between the duplicate check and the insert.
+func (s *tableService) withRestaurantLock(ctx context.Context, restaurantID uuid.UUID, fn func(tx *gorm.DB, tableRepo repository.TableRepository) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", restaurantID.String()).Error; err != nil {
+			return err
+		}
+		return fn(tx, s.tableRepo.WithTx(tx))
+	})
+}
+
+func (s *tableService) checkDuplicateTableNumber(ctx context.Context, db *gorm.DB, restaurantID uuid.UUID, tableNumber string, excludeID uuid.UUID) error {
 	var count int64
-	query := s.db.WithContext(ctx).
+	query := db.WithContext(ctx).
 		Model(&domain.Table{}).
-		Where("restaurant_id = ? AND table_number = ?", restaurantID, tableNumber)
+		Where("restaurant_id = ? AND table_number = ? AND is_active = ?", restaurantID, tableNumber, true)
 
 	if excludeID != uuid.Nil {
 		query = query.Where("id != ?", excludeID)
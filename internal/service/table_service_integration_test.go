@@ -0,0 +1,271 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"restaurant-booking/internal/config"
+	"restaurant-booking/internal/database"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// setupIntegrationDB connects to the Postgres instance described by the DB_* env vars
+func setupIntegrationDB(t *testing.T) *gorm.DB {
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST is not set, skipping integration test")
+	}
+
+	db, err := database.InitDB(&config.Config{
+		DBHost:     os.Getenv("DB_HOST"),
+		DBPort:     os.Getenv("DB_PORT"),
+		DBUser:     os.Getenv("DB_USER"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     os.Getenv("DB_NAME"),
+	})
+	require.NoError(t, err)
+
+	return db
+}
+
+// TestBulkCreateTables_ConcurrentOverlap tests that concurrent bulk creates never produce clashing table numbers
+func TestBulkCreateTables_ConcurrentOverlap(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+
+	owner := &domain.User{
+		Email:     uuid.NewString() + "@example.com",
+		Password:  "hashed",
+		FirstName: "Test",
+		LastName:  "Owner",
+		Phone:     uuid.NewString(),
+		Role:      domain.UserRoleOwner,
+	}
+	require.NoError(t, db.Create(owner).Error)
+
+	restaurant := &domain.Restaurant{
+		OwnerID:      owner.ID,
+		Name:         "Concurrent Restaurant",
+		Address:      "Test street 1",
+		Phone:        "1234567890",
+		CuisineType:  domain.CuisineTypeOther,
+		AveragePrice: 1000,
+		WorkingHours: domain.WorkingHours{},
+	}
+	require.NoError(t, db.Create(restaurant).Error)
+
+	t.Cleanup(func() {
+		db.Where("restaurant_id = ?", restaurant.ID).Delete(&domain.Table{})
+		db.Delete(restaurant)
+		db.Delete(owner)
+	})
+
+	service := NewTableService(repository.NewTableRepository(db), repository.NewTableBlockRepository(db), repository.NewBookingRepository(db), repository.NewRestaurantRepository(db), NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop())), NewNotificationService(1, 10), db, zap.NewNop())
+
+	requests := []BulkCreateTablesRequest{
+		{Tables: []CreateTableRequest{
+			{TableNumber: "A1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular},
+			{TableNumber: "A2", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular},
+		}},
+		{Tables: []CreateTableRequest{
+			{TableNumber: "A2", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular},
+			{TableNumber: "A3", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular},
+		}},
+	}
+
+	errs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func(i int, req BulkCreateTablesRequest) {
+			defer wg.Done()
+			_, errs[i] = service.BulkCreateTables(ctx, restaurant.ID, owner.ID, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrDuplicateTableNumber), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Table{}).Where("restaurant_id = ?", restaurant.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+// createBookableRestaurant creates an active restaurant and its owner, removed
+// with their tables and bookings when the test ends.
+func createBookableRestaurant(t *testing.T, db *gorm.DB) (*domain.User, *domain.Restaurant) {
+	owner := &domain.User{
+		Email:     uuid.NewString() + "@example.com",
+		Password:  "hashed",
+		FirstName: "Test",
+		LastName:  "Owner",
+		Phone:     uuid.NewString(),
+		Role:      domain.UserRoleOwner,
+	}
+	require.NoError(t, db.Create(owner).Error)
+
+	restaurant := &domain.Restaurant{
+		OwnerID:      owner.ID,
+		Name:         "Bookable Restaurant",
+		Address:      "Test street 2",
+		Phone:        "1234567890",
+		CuisineType:  domain.CuisineTypeOther,
+		AveragePrice: 1000,
+		WorkingHours: domain.WorkingHours{},
+		IsActive:     true,
+	}
+	require.NoError(t, db.Create(restaurant).Error)
+
+	t.Cleanup(func() {
+		db.Where("restaurant_id = ?", restaurant.ID).Delete(&domain.Booking{})
+		db.Where("restaurant_id = ?", restaurant.ID).Delete(&domain.Table{})
+		db.Delete(restaurant)
+		db.Delete(owner)
+	})
+
+	return owner, restaurant
+}
+
+// TestGetFloorPlan_MarksBookedTables checks the booked flag against pending,
+// cancelled and finished bookings, and that unplaced tables keep null
+// coordinates.
+func TestGetFloorPlan_MarksBookedTables(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+
+	owner, restaurant := createBookableRestaurant(t, db)
+
+	x, y := 10, 20
+	booked := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "F1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationWindow, XPosition: &x, YPosition: &y, IsActive: true}
+	cancelled := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "F2", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	finished := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "F3", MinCapacity: 4, MaxCapacity: 6, LocationType: domain.LocationRegular, IsActive: true}
+	for _, table := range []*domain.Table{booked, cancelled, finished} {
+		require.NoError(t, db.Create(table).Error)
+	}
+
+	at := time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+	bookings := []*domain.Booking{
+		{TableID: booked.ID, StartTime: at.Add(-time.Hour), EndTime: at.Add(time.Hour), Status: domain.BookingStatusPending},
+		{TableID: cancelled.ID, StartTime: at.Add(-time.Hour), EndTime: at.Add(time.Hour), Status: domain.BookingStatusCancelled},
+		{TableID: finished.ID, StartTime: at.Add(-2 * time.Hour), EndTime: at, Status: domain.BookingStatusConfirmed},
+	}
+	for _, booking := range bookings {
+		booking.RestaurantID = restaurant.ID
+		booking.UserID = &owner.ID
+		booking.BookingDate = at
+		booking.GuestsCount = 2
+		require.NoError(t, db.Create(booking).Error)
+	}
+
+	service := NewTableService(repository.NewTableRepository(db), repository.NewTableBlockRepository(db), repository.NewBookingRepository(db), repository.NewRestaurantRepository(db), NewRestaurantAuthorizer(repository.NewRestaurantManagerRepository(db), NewLogAuditRecorder(zap.NewNop())), NewNotificationService(1, 10), db, zap.NewNop())
+
+	plan, err := service.GetFloorPlan(ctx, restaurant.ID, at)
+
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+	assert.Equal(t, booked.ID, plan[0].ID)
+	assert.True(t, plan[0].Booked)
+	assert.Equal(t, &x, plan[0].XPosition)
+	assert.False(t, plan[1].Booked)
+	assert.Nil(t, plan[1].XPosition)
+	assert.False(t, plan[2].Booked)
+}
+
+// TestGetAvailableTables_ExcludesOverlappingBookings checks the anti-join
+// against bookings, including back-to-back slots.
+func TestGetAvailableTables_ExcludesOverlappingBookings(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+
+	overlapping := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "A1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	backToBack := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "A2", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	cancelled := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "A3", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	small := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "A4", MinCapacity: 1, MaxCapacity: 2, LocationType: domain.LocationRegular, IsActive: true}
+	for _, table := range []*domain.Table{overlapping, backToBack, cancelled, small} {
+		require.NoError(t, db.Create(table).Error)
+	}
+
+	start := time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	bookings := []*domain.Booking{
+		{TableID: overlapping.ID, StartTime: start.Add(time.Hour), EndTime: end.Add(time.Hour), Status: domain.BookingStatusConfirmed},
+		{TableID: backToBack.ID, StartTime: start.Add(-2 * time.Hour), EndTime: start, Status: domain.BookingStatusConfirmed},
+		{TableID: backToBack.ID, StartTime: end, EndTime: end.Add(2 * time.Hour), Status: domain.BookingStatusPending},
+		{TableID: cancelled.ID, StartTime: start, EndTime: end, Status: domain.BookingStatusCancelled},
+	}
+	for _, booking := range bookings {
+		booking.RestaurantID = restaurant.ID
+		booking.UserID = &owner.ID
+		booking.BookingDate = start
+		booking.GuestsCount = 2
+		require.NoError(t, db.Create(booking).Error)
+	}
+
+	tables, err := repository.NewTableRepository(db).GetAvailableTables(ctx, restaurant.ID, 3, start, end)
+
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, len(tables))
+	for i, table := range tables {
+		ids[i] = table.ID
+	}
+	assert.ElementsMatch(t, []uuid.UUID{backToBack.ID, cancelled.ID}, ids)
+}
+
+// TestGetOverlappingByTable_KeepsBookingsCrossingTheWindow checks the
+// per-table query behind the day schedule.
+func TestGetOverlappingByTable_KeepsBookingsCrossingTheWindow(t *testing.T) {
+	db := setupIntegrationDB(t)
+	ctx := context.Background()
+	owner, restaurant := createBookableRestaurant(t, db)
+
+	table := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "S1", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	other := &domain.Table{RestaurantID: restaurant.ID, TableNumber: "S2", MinCapacity: 2, MaxCapacity: 4, LocationType: domain.LocationRegular, IsActive: true}
+	require.NoError(t, db.Create(table).Error)
+	require.NoError(t, db.Create(other).Error)
+
+	opens := time.Date(2030, time.June, 1, 10, 0, 0, 0, time.UTC)
+	closes := opens.Add(12 * time.Hour)
+	crossingOpen := &domain.Booking{TableID: table.ID, StartTime: opens.Add(-time.Hour), EndTime: opens.Add(time.Hour), Status: domain.BookingStatusConfirmed}
+	evening := &domain.Booking{TableID: table.ID, StartTime: closes.Add(-time.Hour), EndTime: closes.Add(time.Hour), Status: domain.BookingStatusPending}
+	bookings := []*domain.Booking{
+		evening,
+		crossingOpen,
+		{TableID: table.ID, StartTime: closes, EndTime: closes.Add(2 * time.Hour), Status: domain.BookingStatusConfirmed},
+		{TableID: table.ID, StartTime: opens.Add(3 * time.Hour), EndTime: opens.Add(5 * time.Hour), Status: domain.BookingStatusCancelled},
+		{TableID: other.ID, StartTime: opens.Add(3 * time.Hour), EndTime: opens.Add(5 * time.Hour), Status: domain.BookingStatusConfirmed},
+	}
+	for _, booking := range bookings {
+		booking.RestaurantID = restaurant.ID
+		booking.UserID = &owner.ID
+		booking.BookingDate = opens
+		booking.GuestsCount = 2
+		require.NoError(t, db.Create(booking).Error)
+	}
+
+	found, err := repository.NewBookingRepository(db).GetOverlappingByTable(ctx, table.ID, opens, closes)
+
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, crossingOpen.ID, found[0].ID)
+	assert.Equal(t, evening.ID, found[1].ID)
+}
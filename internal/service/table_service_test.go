@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -40,7 +45,15 @@ func (m *MockTableRepository) GetByRestaurantID(ctx context.Context, restaurantI
 	return args.Get(0).([]*domain.Table), args.Error(1)
 }
 
-func (m *MockTableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
+func (m *MockTableRepository) GetAvailableTables(ctx context.Context, restaurantID uuid.UUID, minCapacity int, start, end time.Time) ([]*domain.Table, error) {
+	args := m.Called(ctx, restaurantID, minCapacity, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Table), args.Error(1)
+}
+
+func (m *MockTableRepository) GetByMinCapacity(ctx context.Context, restaurantID uuid.UUID, minCapacity int) ([]*domain.Table, error) {
 	args := m.Called(ctx, restaurantID, minCapacity)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -58,6 +71,14 @@ func (m *MockTableRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockTableRepository) GetFloorPlan(ctx context.Context, restaurantID uuid.UUID, at time.Time) ([]repository.FloorPlanTable, error) {
+	args := m.Called(ctx, restaurantID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.FloorPlanTable), args.Error(1)
+}
+
 func (m *MockTableRepository) List(ctx context.Context, limit, offset int) ([]*domain.Table, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -66,10 +87,59 @@ func (m *MockTableRepository) List(ctx context.Context, limit, offset int) ([]*d
 	return args.Get(0).([]*domain.Table), args.Error(1)
 }
 
+func (m *MockTableRepository) WithTx(tx *gorm.DB) repository.TableRepository {
+	return m
+}
+
+type MockTableBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockTableBlockRepository) Create(ctx context.Context, block *domain.TableBlock) error {
+	args := m.Called(ctx, block)
+	return args.Error(0)
+}
+
+func (m *MockTableBlockRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TableBlock, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TableBlock), args.Error(1)
+}
+
+func (m *MockTableBlockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTableBlockRepository) ListByTable(ctx context.Context, tableID uuid.UUID, from time.Time) ([]*domain.TableBlock, error) {
+	args := m.Called(ctx, tableID, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TableBlock), args.Error(1)
+}
+
+func (m *MockTableBlockRepository) IsBlocked(ctx context.Context, tableID uuid.UUID, start, end time.Time) (bool, error) {
+	args := m.Called(ctx, tableID, start, end)
+	return args.Bool(0), args.Error(1)
+}
+
 // setupTableService creates a table service instance with mock repositories
 func setupTableService() (*tableService, *MockTableRepository, *MockRestaurantRepository, sqlmock.Sqlmock, *gorm.DB) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, sqlMock, _ := setupTableServiceWithBookings()
+	mockBookingRepo.On("GetUpcomingByTable", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.Booking{}, nil).Maybe()
+	return service, mockTableRepo, mockRestaurantRepo, sqlMock, service.db
+}
+
+// setupTableServiceWithBookings leaves the tables' upcoming bookings to the
+// test and also returns the emails the service sends.
+func setupTableServiceWithBookings() (*tableService, *MockTableRepository, *MockRestaurantRepository, *BookingMockBookingRepository, sqlmock.Sqlmock, chan Notification) {
 	mockTableRepo := new(MockTableRepository)
 	mockRestaurantRepo := new(MockRestaurantRepository)
+	mockBookingRepo := new(BookingMockBookingRepository)
+	sent := make(chan Notification, 10)
 
 	sqlDB, sqlMock, _ := sqlmock.New()
 	dialector := postgres.New(postgres.Config{
@@ -80,11 +150,25 @@ func setupTableService() (*tableService, *MockTableRepository, *MockRestaurantRe
 
 	service := &tableService{
 		tableRepo:      mockTableRepo,
+		bookingRepo:    mockBookingRepo,
 		restaurantRepo: mockRestaurantRepo,
-		db:             db,
+		authz:          NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder)),
+		notificationSvc: newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+			sent <- n
+			return nil
+		}),
+		db:  db,
+		log: zap.NewNop(),
 	}
 
-	return service, mockTableRepo, mockRestaurantRepo, sqlMock, db
+	return service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, sqlMock, sent
+}
+
+// expectRestaurantLock registers the transaction and advisory lock taken before table creation
+func expectRestaurantLock(sqlMock sqlmock.Sqlmock) {
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 }
 
 // TestNewTableService tests the service constructor
@@ -98,7 +182,8 @@ func TestNewTableService(t *testing.T) {
 	})
 	db, _ := gorm.Open(dialector, &gorm.Config{})
 
-	service := NewTableService(mockTableRepo, mockRestaurantRepo, db)
+	service := NewTableService(mockTableRepo, new(MockTableBlockRepository), new(BookingMockBookingRepository), mockRestaurantRepo,
+		NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), new(MockAuditRecorder)), NewNotificationService(1, 10), db, zap.NewNop())
 
 	assert.NotNil(t, service)
 	assert.IsType(t, &tableService{}, service)
@@ -131,9 +216,11 @@ func TestCreateTable_Success(t *testing.T) {
 	}
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(nil)
+	sqlMock.ExpectCommit()
 
 	result, err := service.CreateTable(ctx, restaurantID, ownerID, req)
 
@@ -149,6 +236,56 @@ func TestCreateTable_Success(t *testing.T) {
 	mockTableRepo.AssertExpectations(t)
 }
 
+func TestCreateTable_Deposit(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, err := service.CreateTable(ctx, restaurant.ID, ownerID, CreateTableRequest{TableNumber: "V1", MinCapacity: 2, MaxCapacity: 6, DepositAmount: -1})
+	assert.ErrorIs(t, err, ErrInvalidTableDeposit)
+
+	expectRestaurantLock(sqlMock)
+	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(nil)
+	sqlMock.ExpectCommit()
+
+	table, err := service.CreateTable(ctx, restaurant.ID, ownerID, CreateTableRequest{
+		TableNumber: "V1", MinCapacity: 2, MaxCapacity: 6, LocationType: domain.LocationVIP, DepositAmount: 10000, MinSpend: 50000,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 10000, table.DepositAmount)
+	assert.Equal(t, 50000, table.MinSpend)
+}
+
+func TestUpdateTable_Deposit(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "V1", MinCapacity: 2, MaxCapacity: 6, DepositAmount: 10000, MinSpend: 50000, IsActive: true}
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockTableRepo.On("Update", ctx, table).Return(nil)
+
+	negative := -500
+	_, err := service.UpdateTable(ctx, table.ID, restaurant.ID, ownerID, UpdateTableRequest{MinSpend: &negative})
+	assert.ErrorIs(t, err, ErrInvalidTableDeposit)
+
+	table.MinSpend = 50000
+	none := 0
+	updated, err := service.UpdateTable(ctx, table.ID, restaurant.ID, ownerID, UpdateTableRequest{DepositAmount: &none})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated.DepositAmount)
+	assert.Equal(t, 50000, updated.MinSpend)
+}
+
 // TestCreateTable_RestaurantNotFound tests creating table when restaurant doesn't exist
 func TestCreateTable_RestaurantNotFound(t *testing.T) {
 	service, _, mockRestaurantRepo, _, _ := setupTableService()
@@ -299,6 +436,35 @@ func TestCreateTable_InvalidCapacity(t *testing.T) {
 	mockRestaurantRepo.AssertExpectations(t)
 }
 
+func TestCreateTable_BookingRulesBelowZoneMinimum(t *testing.T) {
+	service, _, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{
+		ID:      uuid.New(),
+		OwnerID: uuid.New(),
+		BookingRules: domain.RestaurantBookingRules{
+			Zones: map[domain.LocationType]domain.BookingRules{domain.LocationVIP: {MinDurationMinutes: 180}},
+		},
+	}
+	oneHour := 60
+
+	req := CreateTableRequest{
+		TableNumber:        "P1",
+		MinCapacity:        2,
+		MaxCapacity:        10,
+		LocationType:       domain.LocationVIP,
+		MinDurationMinutes: &oneHour,
+	}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	result, err := service.CreateTable(ctx, restaurant.ID, restaurant.OwnerID, req)
+
+	assert.ErrorIs(t, err, ErrInvalidBookingRules)
+	assert.Nil(t, result)
+}
+
 // TestCreateTable_DuplicateTableNumber tests creating table with existing table number
 func TestCreateTable_DuplicateTableNumber(t *testing.T) {
 	service, _, mockRestaurantRepo, sqlMock, _ := setupTableService()
@@ -321,8 +487,10 @@ func TestCreateTable_DuplicateTableNumber(t *testing.T) {
 	}
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectRollback()
 
 	result, err := service.CreateTable(ctx, restaurantID, ownerID, req)
 
@@ -355,8 +523,10 @@ func TestCreateTable_DuplicateCheckError(t *testing.T) {
 	}
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnError(errors.New("database error"))
+	sqlMock.ExpectRollback()
 
 	result, err := service.CreateTable(ctx, restaurantID, ownerID, req)
 
@@ -389,9 +559,11 @@ func TestCreateTable_CreateError(t *testing.T) {
 
 	dbError := errors.New("database error")
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(dbError)
+	sqlMock.ExpectRollback()
 
 	result, err := service.CreateTable(ctx, restaurantID, ownerID, req)
 
@@ -404,6 +576,48 @@ func TestCreateTable_CreateError(t *testing.T) {
 }
 
 // TestGetTablesByRestaurant_Success tests successful retrieval of tables
+func TestGetTable_NotFound(t *testing.T) {
+	service, mockTableRepo, _, _, _ := setupTableService()
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockTableRepo.On("GetByID", ctx, id).Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := service.GetTable(ctx, id)
+
+	assert.ErrorIs(t, err, ErrTableNotFound)
+	assert.Nil(t, result)
+}
+
+func TestGetFloorPlan(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+	restaurantID := uuid.New()
+	at := time.Date(2024, time.June, 1, 19, 0, 0, 0, time.UTC)
+	plan := []repository.FloorPlanTable{{ID: uuid.New(), TableNumber: "T1", Booked: true}}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(&domain.Restaurant{ID: restaurantID, IsActive: true}, nil)
+	mockTableRepo.On("GetFloorPlan", ctx, restaurantID, at).Return(plan, nil)
+
+	result, err := service.GetFloorPlan(ctx, restaurantID, at)
+
+	assert.NoError(t, err)
+	assert.Equal(t, plan, result)
+}
+
+func TestGetFloorPlan_InactiveRestaurant(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+	restaurantID := uuid.New()
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(&domain.Restaurant{ID: restaurantID}, nil)
+
+	_, err := service.GetFloorPlan(ctx, restaurantID, time.Now())
+
+	assert.ErrorIs(t, err, ErrRestaurantNotFound)
+	mockTableRepo.AssertNotCalled(t, "GetFloorPlan", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetTablesByRestaurant_Success(t *testing.T) {
 	service, mockTableRepo, _, _, _ := setupTableService()
 	ctx := context.Background()
@@ -522,9 +736,11 @@ func TestUpdateTable_Success(t *testing.T) {
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mockTableRepo.On("Update", ctx, mock.AnythingOfType("*domain.Table")).Return(nil)
+	sqlMock.ExpectCommit()
 
 	result, err := service.UpdateTable(ctx, tableID, restaurantID, ownerID, req)
 
@@ -540,6 +756,36 @@ func TestUpdateTable_Success(t *testing.T) {
 	mockTableRepo.AssertExpectations(t)
 }
 
+func TestUpdateTable_BookingRuleOverrides(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{
+		ID:                 uuid.New(),
+		RestaurantID:       restaurant.ID,
+		MinCapacity:        1,
+		MaxCapacity:        2,
+		LocationType:       domain.LocationRegular,
+		MinDurationMinutes: intPtr(90),
+	}
+	granularity, inherit := 45, 0
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockTableRepo.On("Update", ctx, table).Return(nil)
+
+	result, err := service.UpdateTable(ctx, table.ID, restaurant.ID, restaurant.OwnerID, UpdateTableRequest{
+		MinDurationMinutes:     &inherit,
+		SlotGranularityMinutes: &granularity,
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.MinDurationMinutes)
+	assert.Equal(t, 45, *result.SlotGranularityMinutes)
+	mockTableRepo.AssertExpectations(t)
+}
+
 // TestUpdateTable_RestaurantNotFound tests updating table when restaurant doesn't exist
 func TestUpdateTable_RestaurantNotFound(t *testing.T) {
 	service, _, mockRestaurantRepo, _, _ := setupTableService()
@@ -783,8 +1029,10 @@ func TestUpdateTable_DuplicateTableNumber(t *testing.T) {
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectRollback()
 
 	result, err := service.UpdateTable(ctx, tableID, restaurantID, ownerID, req)
 
@@ -794,6 +1042,39 @@ func TestUpdateTable_DuplicateTableNumber(t *testing.T) {
 
 	mockRestaurantRepo.AssertExpectations(t)
 	mockTableRepo.AssertExpectations(t)
+	mockTableRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+// TestUpdateTable_ReactivateTakenNumber tests that a table cannot be turned
+// back on while an active table has its number
+func TestUpdateTable_ReactivateTakenNumber(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{
+		ID:           uuid.New(),
+		RestaurantID: restaurant.ID,
+		TableNumber:  "T1",
+		MinCapacity:  2,
+		MaxCapacity:  4,
+	}
+	active := true
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	expectRestaurantLock(sqlMock)
+	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectRollback()
+
+	result, err := service.UpdateTable(ctx, table.ID, restaurant.ID, restaurant.OwnerID, UpdateTableRequest{IsActive: &active})
+
+	assert.ErrorIs(t, err, ErrDuplicateTableNumber)
+	assert.Nil(t, result)
+	mockTableRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
 
 // TestUpdateTable_InvalidCapacity tests updating with invalid capacity
@@ -908,12 +1189,51 @@ func TestDeleteTable_Success(t *testing.T) {
 	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
 	mockTableRepo.On("Update", ctx, mock.AnythingOfType("*domain.Table")).Return(nil)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
+
+	assert.NoError(t, err)
+
+	mockRestaurantRepo.AssertExpectations(t)
+	mockTableRepo.AssertExpectations(t)
+}
+
+// TestDeleteTable_AdminOverride tests that an admin can delete a table of a restaurant they do not own
+func TestDeleteTable_AdminOverride(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, _, _ := setupTableService()
+	audit := new(MockAuditRecorder)
+	service.authz = NewRestaurantAuthorizer(new(MockRestaurantManagerRepository), audit)
+
+	tableID := uuid.New()
+	restaurantID := uuid.New()
+	adminID := uuid.New()
+	ctx := adminContext(adminID)
+
+	restaurant := &domain.Restaurant{
+		ID:      restaurantID,
+		OwnerID: uuid.New(),
+		Name:    "Test Restaurant",
+	}
+
+	existingTable := &domain.Table{
+		ID:           tableID,
+		RestaurantID: restaurantID,
+		TableNumber:  "T1",
+		IsActive:     true,
+	}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
+	mockTableRepo.On("Update", ctx, mock.AnythingOfType("*domain.Table")).Return(nil)
+	expectAdminOverride(audit, adminID, restaurantID, "table.delete")
+
+	err := service.DeleteTable(ctx, tableID, restaurantID, adminID, false)
 
 	assert.NoError(t, err)
+	assert.False(t, existingTable.IsActive)
 
 	mockRestaurantRepo.AssertExpectations(t)
 	mockTableRepo.AssertExpectations(t)
+	audit.AssertExpectations(t)
 }
 
 // TestDeleteTable_RestaurantNotFound tests deleting table when restaurant doesn't exist
@@ -927,7 +1247,7 @@ func TestDeleteTable_RestaurantNotFound(t *testing.T) {
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(nil, gorm.ErrRecordNotFound)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrRestaurantNotFound, err)
@@ -947,7 +1267,7 @@ func TestDeleteTable_RestaurantGetError(t *testing.T) {
 	dbError := errors.New("database error")
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(nil, dbError)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, dbError, err)
@@ -973,7 +1293,7 @@ func TestDeleteTable_Unauthorized(t *testing.T) {
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUnauthorized, err)
@@ -999,7 +1319,7 @@ func TestDeleteTable_TableNotFound(t *testing.T) {
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 	mockTableRepo.On("GetByID", ctx, tableID).Return(nil, gorm.ErrRecordNotFound)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrTableNotFound, err)
@@ -1027,7 +1347,7 @@ func TestDeleteTable_TableGetError(t *testing.T) {
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 	mockTableRepo.On("GetByID", ctx, tableID).Return(nil, dbError)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, dbError, err)
@@ -1061,7 +1381,7 @@ func TestDeleteTable_TableRestaurantMismatch(t *testing.T) {
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrUnauthorized, err)
@@ -1097,7 +1417,7 @@ func TestDeleteTable_UpdateError(t *testing.T) {
 	mockTableRepo.On("GetByID", ctx, tableID).Return(existingTable, nil)
 	mockTableRepo.On("Update", ctx, mock.AnythingOfType("*domain.Table")).Return(dbError)
 
-	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID)
+	err := service.DeleteTable(ctx, tableID, restaurantID, ownerID, false)
 
 	assert.Error(t, err)
 	assert.Equal(t, dbError, err)
@@ -1106,6 +1426,241 @@ func TestDeleteTable_UpdateError(t *testing.T) {
 	mockTableRepo.AssertExpectations(t)
 }
 
+// upcomingTableBookings is two bookings of the table tomorrow.
+func upcomingTableBookings(tableID uuid.UUID) []*domain.Booking {
+	start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Hour)
+	bookings := make([]*domain.Booking, 2)
+	for i := range bookings {
+		bookings[i] = &domain.Booking{
+			ID:          uuid.New(),
+			TableID:     tableID,
+			StartTime:   start.Add(time.Duration(i) * 3 * time.Hour),
+			EndTime:     start.Add(time.Duration(i)*3*time.Hour + 2*time.Hour),
+			GuestsCount: 2,
+			Status:      domain.BookingStatusConfirmed,
+			User:        &domain.User{Email: fmt.Sprintf("guest%d@example.com", i)},
+		}
+	}
+	return bookings
+}
+
+func TestDeleteTable_HasUpcomingBookings(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, _, _ := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Test Restaurant"}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", IsActive: true}
+	bookings := upcomingTableBookings(table.ID)
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBookingRepo.On("GetUpcomingByTable", ctx, table.ID, mock.AnythingOfType("time.Time")).Return(bookings, nil)
+
+	err := service.DeleteTable(ctx, table.ID, restaurant.ID, ownerID, false)
+
+	var hasBookings *TableHasBookingsError
+	require.ErrorAs(t, err, &hasBookings)
+	assert.ErrorIs(t, err, ErrTableHasActiveBookings)
+	assert.Equal(t, []uuid.UUID{bookings[0].ID, bookings[1].ID}, hasBookings.BookingIDs)
+	mockTableRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockBookingRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDeleteTable_ForceCancelsBookings(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, sqlMock, sent := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Osteria"}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", IsActive: true}
+	bookings := upcomingTableBookings(table.ID)
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBookingRepo.On("GetUpcomingByTable", ctx, table.ID, mock.AnythingOfType("time.Time")).Return(bookings, nil)
+	mockBookingRepo.On("Update", ctx, mock.MatchedBy(func(b *domain.Booking) bool {
+		return b.Status == domain.BookingStatusCancelled
+	})).Return(nil).Twice()
+	mockTableRepo.On("Update", ctx, mock.MatchedBy(func(t *domain.Table) bool { return !t.IsActive })).Return(nil).Once()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectCommit()
+
+	err := service.DeleteTable(ctx, table.ID, restaurant.ID, ownerID, true)
+
+	require.NoError(t, err)
+	require.NoError(t, sqlMock.ExpectationsWereMet())
+	mockBookingRepo.AssertExpectations(t)
+	mockTableRepo.AssertExpectations(t)
+
+	emails := receiveNotifications(t, sent, 2)
+	assert.ElementsMatch(t, []string{"guest0@example.com", "guest1@example.com"}, []string{emails[0].Recipient, emails[1].Recipient})
+	assert.Contains(t, emails[0].Message, "Osteria")
+}
+
+func TestDeleteTable_ForceRollsBackOnError(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, sqlMock, sent := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Test Restaurant"}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", IsActive: true}
+
+	dbError := errors.New("database error")
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBookingRepo.On("GetUpcomingByTable", ctx, table.ID, mock.AnythingOfType("time.Time")).Return(upcomingTableBookings(table.ID), nil)
+	mockBookingRepo.On("Update", ctx, mock.AnythingOfType("*domain.Booking")).Return(nil)
+	mockTableRepo.On("Update", ctx, mock.AnythingOfType("*domain.Table")).Return(dbError)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+
+	err := service.DeleteTable(ctx, table.ID, restaurant.ID, ownerID, true)
+
+	assert.ErrorIs(t, err, dbError)
+	require.NoError(t, sqlMock.ExpectationsWereMet())
+	select {
+	case n := <-sent:
+		t.Fatalf("unexpected email to %s", n.Recipient)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUpdateTable_DeactivateWithUpcomingBookings(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, _, _ := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Test Restaurant"}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBookingRepo.On("GetUpcomingByTable", ctx, table.ID, mock.AnythingOfType("time.Time")).Return(upcomingTableBookings(table.ID), nil)
+
+	inactive := false
+	_, err := service.UpdateTable(ctx, table.ID, restaurant.ID, ownerID, UpdateTableRequest{IsActive: &inactive})
+
+	assert.ErrorIs(t, err, ErrTableHasActiveBookings)
+	mockTableRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTable_AlreadyInactiveSkipsBookingCheck(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, mockBookingRepo, _, _ := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID, Name: "Test Restaurant"}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1", MinCapacity: 2, MaxCapacity: 4}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockTableRepo.On("Update", ctx, table).Return(nil)
+
+	inactive := false
+	_, err := service.UpdateTable(ctx, table.ID, restaurant.ID, ownerID, UpdateTableRequest{IsActive: &inactive})
+
+	require.NoError(t, err)
+	mockBookingRepo.AssertNotCalled(t, "GetUpcomingByTable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTablePositions_MovesAllTables(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	x := 40
+	first := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T1"}
+	second := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, TableNumber: "T2", XPosition: &x, YPosition: &x}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
+	mockTableRepo.On("GetByRestaurantID", ctx, restaurant.ID).Return([]*domain.Table{first, second}, nil)
+	mockTableRepo.On("Update", ctx, first).Return(nil).Once()
+	mockTableRepo.On("Update", ctx, second).Return(nil).Once()
+	sqlMock.ExpectCommit()
+
+	one, two := 1, 2
+	tables, err := service.UpdateTablePositions(ctx, restaurant.ID, ownerID, []TablePosition{
+		{TableID: second.ID},
+		{TableID: first.ID, XPosition: &one, YPosition: &two},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Table{second, first}, tables)
+	assert.Equal(t, &one, first.XPosition)
+	assert.Equal(t, &two, first.YPosition)
+	assert.Nil(t, second.XPosition)
+	mockTableRepo.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateTablePositions_ReportsRejectedEntries(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	own := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID}
+	foreignID := uuid.New()
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
+	mockTableRepo.On("GetByRestaurantID", ctx, restaurant.ID).Return([]*domain.Table{own}, nil)
+	sqlMock.ExpectRollback()
+
+	_, err := service.UpdateTablePositions(ctx, restaurant.ID, ownerID, []TablePosition{
+		{TableID: own.ID},
+		{TableID: foreignID},
+		{TableID: own.ID},
+	})
+
+	var rejected *TablePositionsError
+	require.ErrorAs(t, err, &rejected)
+	assert.ErrorIs(t, err, ErrInvalidTablePositions)
+	require.Len(t, rejected.Issues, 2)
+	assert.Equal(t, 1, rejected.Issues[0].Index)
+	assert.Equal(t, foreignID, rejected.Issues[0].TableID)
+	assert.Equal(t, 2, rejected.Issues[1].Index)
+	mockTableRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateTablePositions_UpdateErrorRollsBack(t *testing.T) {
+	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
+	ctx := context.Background()
+
+	ownerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: ownerID}
+	first := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID}
+	second := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
+	mockTableRepo.On("GetByRestaurantID", ctx, restaurant.ID).Return([]*domain.Table{first, second}, nil)
+	mockTableRepo.On("Update", ctx, first).Return(nil)
+	mockTableRepo.On("Update", ctx, second).Return(errors.New("connection reset"))
+	sqlMock.ExpectRollback()
+
+	_, err := service.UpdateTablePositions(ctx, restaurant.ID, ownerID, []TablePosition{{TableID: first.ID}, {TableID: second.ID}})
+
+	assert.ErrorContains(t, err, "table at index 1")
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateTablePositions_NotOwner(t *testing.T) {
+	service, _, mockRestaurantRepo, _, _ := setupTableService()
+	ctx := context.Background()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+
+	mockRestaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+
+	_, err := service.UpdateTablePositions(ctx, restaurant.ID, uuid.New(), []TablePosition{{TableID: uuid.New()}})
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
 // TestBulkCreateTables_Success tests successful bulk table creation
 func TestBulkCreateTables_Success(t *testing.T) {
 	service, mockTableRepo, mockRestaurantRepo, sqlMock, _ := setupTableService()
@@ -1139,6 +1694,7 @@ func TestBulkCreateTables_Success(t *testing.T) {
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
 
+	expectRestaurantLock(sqlMock)
 	// Expect duplicate check for T1
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
@@ -1147,6 +1703,7 @@ func TestBulkCreateTables_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(nil).Times(2)
+	sqlMock.ExpectCommit()
 
 	result, err := service.BulkCreateTables(ctx, restaurantID, ownerID, req)
 
@@ -1372,6 +1929,7 @@ func TestBulkCreateTables_DuplicateInRequest(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "table at index 1")
 	assert.Contains(t, err.Error(), "duplicate table number 'T1' in request")
+	assert.ErrorIs(t, err, ErrDuplicateTableNumber)
 
 	mockRestaurantRepo.AssertExpectations(t)
 }
@@ -1402,8 +1960,10 @@ func TestBulkCreateTables_DuplicateTableNumber(t *testing.T) {
 	}
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectRollback()
 
 	result, err := service.BulkCreateTables(ctx, restaurantID, ownerID, req)
 
@@ -1440,8 +2000,10 @@ func TestBulkCreateTables_DuplicateCheckError(t *testing.T) {
 	}
 
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnError(errors.New("database error"))
+	sqlMock.ExpectRollback()
 
 	result, err := service.BulkCreateTables(ctx, restaurantID, ownerID, req)
 
@@ -1478,9 +2040,11 @@ func TestBulkCreateTables_CreateError(t *testing.T) {
 
 	dbError := errors.New("database error")
 	mockRestaurantRepo.On("GetByID", ctx, restaurantID).Return(restaurant, nil)
+	expectRestaurantLock(sqlMock)
 	sqlMock.ExpectQuery("SELECT (.+) FROM \"tables\"").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mockTableRepo.On("Create", ctx, mock.AnythingOfType("*domain.Table")).Return(dbError)
+	sqlMock.ExpectRollback()
 
 	result, err := service.BulkCreateTables(ctx, restaurantID, ownerID, req)
 
@@ -1491,3 +2055,153 @@ func TestBulkCreateTables_CreateError(t *testing.T) {
 	mockRestaurantRepo.AssertExpectations(t)
 	mockTableRepo.AssertExpectations(t)
 }
+
+func setupTableBlockService() (*tableService, *MockTableRepository, *MockTableBlockRepository, *MockRestaurantManagerRepository) {
+	mockTableRepo := new(MockTableRepository)
+	mockBlockRepo := new(MockTableBlockRepository)
+	mockManagerRepo := new(MockRestaurantManagerRepository)
+
+	service := &tableService{
+		tableRepo: mockTableRepo,
+		blockRepo: mockBlockRepo,
+		authz:     NewRestaurantAuthorizer(mockManagerRepo, new(MockAuditRecorder)),
+	}
+
+	return service, mockTableRepo, mockBlockRepo, mockManagerRepo
+}
+
+func TestBlockTable_ManagerBlocksTable(t *testing.T) {
+	service, mockTableRepo, mockBlockRepo, mockManagerRepo := setupTableBlockService()
+	ctx := context.Background()
+
+	managerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockManagerRepo.On("IsManager", ctx, managerID, restaurant.ID).Return(true, nil)
+	mockBlockRepo.On("Create", ctx, mock.AnythingOfType("*domain.TableBlock")).Return(nil)
+
+	block, err := service.BlockTable(ctx, table.ID, managerID, BlockTableRequest{StartsAt: start, EndsAt: start.Add(3 * time.Hour), Reason: " broken leg "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, table.ID, block.TableID)
+	assert.Equal(t, managerID, block.CreatedBy)
+	assert.Equal(t, "broken leg", block.Reason)
+	mockBlockRepo.AssertExpectations(t)
+}
+
+func TestBlockTable_Rejections(t *testing.T) {
+	service, mockTableRepo, _, mockManagerRepo := setupTableBlockService()
+	ctx := context.Background()
+
+	strangerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockManagerRepo.On("IsManager", ctx, strangerID, restaurant.ID).Return(false, nil)
+
+	_, err := service.BlockTable(ctx, table.ID, restaurant.OwnerID, BlockTableRequest{StartsAt: start, EndsAt: start})
+	assert.ErrorIs(t, err, ErrInvalidBlockPeriod)
+
+	_, err = service.BlockTable(ctx, table.ID, strangerID, BlockTableRequest{StartsAt: start, EndsAt: start.Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrNotRestaurantStaff)
+}
+
+func TestUnblockTable_BlockOfAnotherTable(t *testing.T) {
+	service, mockTableRepo, mockBlockRepo, _ := setupTableBlockService()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	block := &domain.TableBlock{ID: uuid.New(), TableID: uuid.New()}
+
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBlockRepo.On("GetByID", ctx, block.ID).Return(block, nil)
+
+	err := service.UnblockTable(ctx, table.ID, block.ID, restaurant.OwnerID)
+
+	assert.ErrorIs(t, err, ErrTableBlockNotFound)
+	mockBlockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestGetTableSchedule_MarksBookedSlots(t *testing.T) {
+	service, mockTableRepo, _, mockBookingRepo, _, _ := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{
+		ID:           uuid.New(),
+		OwnerID:      uuid.New(),
+		Timezone:     "Asia/Tokyo",
+		WorkingHours: domain.WorkingHours{"monday": {OpenTime: "10:00", CloseTime: "13:45"}},
+	}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	tokyo := restaurant.Location()
+	opens := time.Date(2024, 6, 3, 10, 0, 0, 0, tokyo)
+	booking := &domain.Booking{ID: uuid.New(), StartTime: opens.Add(30 * time.Minute), EndTime: opens.Add(2*time.Hour + 15*time.Minute)}
+
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockBookingRepo.On("GetOverlappingByTable", ctx, table.ID,
+		mock.MatchedBy(func(from time.Time) bool { return from.Equal(opens) }),
+		mock.MatchedBy(func(to time.Time) bool { return to.Equal(opens.Add(3*time.Hour + 45*time.Minute)) }),
+	).Return([]*domain.Booking{booking}, nil).Once()
+
+	// Midnight UTC is already the 3rd in Tokyo; only the calendar day counts.
+	schedule, err := service.GetTableSchedule(ctx, table.ID, restaurant.OwnerID, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), 60)
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, tokyo), schedule.Day)
+	require.Len(t, schedule.Slots, 4)
+	for i, slot := range schedule.Slots {
+		assert.True(t, slot.Start.Equal(opens.Add(time.Duration(i)*time.Hour)), "slot %d", i)
+	}
+	assert.True(t, schedule.Slots[3].End.Equal(opens.Add(3*time.Hour+45*time.Minute)))
+	for _, i := range []int{0, 1, 2} {
+		assert.Equal(t, &booking.ID, schedule.Slots[i].BookingID, "slot %d", i)
+	}
+	assert.Nil(t, schedule.Slots[3].BookingID)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestGetTableSchedule_ClosedDay(t *testing.T) {
+	service, mockTableRepo, _, mockBookingRepo, _, _ := setupTableServiceWithBookings()
+	ctx := context.Background()
+
+	restaurant := &domain.Restaurant{
+		ID:           uuid.New(),
+		OwnerID:      uuid.New(),
+		WorkingHours: domain.WorkingHours{"monday": {IsClosed: true}},
+	}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+
+	schedule, err := service.GetTableSchedule(ctx, table.ID, restaurant.OwnerID, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), 30)
+
+	require.NoError(t, err)
+	assert.Empty(t, schedule.Slots)
+	mockBookingRepo.AssertNotCalled(t, "GetOverlappingByTable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetTableSchedule_Rejections(t *testing.T) {
+	service, mockTableRepo, _, _, _, _ := setupTableServiceWithBookings()
+	mockManagerRepo := new(MockRestaurantManagerRepository)
+	service.authz = NewRestaurantAuthorizer(mockManagerRepo, new(MockAuditRecorder))
+	ctx := context.Background()
+
+	strangerID := uuid.New()
+	restaurant := &domain.Restaurant{ID: uuid.New(), OwnerID: uuid.New()}
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, Restaurant: restaurant}
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	mockTableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mockManagerRepo.On("IsManager", ctx, strangerID, restaurant.ID).Return(false, nil)
+
+	_, err := service.GetTableSchedule(ctx, table.ID, restaurant.OwnerID, day, 4)
+	assert.ErrorIs(t, err, ErrInvalidScheduleSlot)
+
+	_, err = service.GetTableSchedule(ctx, table.ID, strangerID, day, 30)
+	assert.ErrorIs(t, err, ErrNotRestaurantStaff)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tokenBlacklistSweepInterval bounds how often expired entries are dropped.
+const tokenBlacklistSweepInterval = time.Minute
+
+// TokenBlacklist rejects access tokens before they expire. Entries only need
+// to live as long as the token they revoke, so every entry carries the
+// expiry after which it can be forgotten. The in-memory blacklist is enough
+// for a single instance; a shared backend such as Redis can implement the
+// same interface later.
+type TokenBlacklist interface {
+	// Revoke rejects the token with the given JTI until expiresAt.
+	Revoke(jti string, expiresAt time.Time) error
+	// RevokeUser rejects every token of the user issued before issuedBefore,
+	// until expiresAt. It covers tokens whose JTI is unknown, such as those
+	// of other devices on "log out everywhere". Token issue times only have
+	// whole seconds, so issuedBefore is truncated to the second: a token
+	// issued in the same second, such as the fresh login right after, stays
+	// valid.
+	RevokeUser(userID uuid.UUID, issuedBefore, expiresAt time.Time) error
+	IsRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+}
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+type inMemoryTokenBlacklist struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time
+	users     map[uuid.UUID]userRevocation
+	lastSweep time.Time
+}
+
+func NewInMemoryTokenBlacklist() TokenBlacklist {
+	return &inMemoryTokenBlacklist{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]userRevocation),
+	}
+}
+
+func (b *inMemoryTokenBlacklist) Revoke(jti string, expiresAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweepIfDue(time.Now())
+	b.tokens[jti] = expiresAt
+	return nil
+}
+
+func (b *inMemoryTokenBlacklist) RevokeUser(userID uuid.UUID, issuedBefore, expiresAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweepIfDue(time.Now())
+	issuedBefore = issuedBefore.Truncate(time.Second)
+	if current, ok := b.users[userID]; ok && current.issuedBefore.After(issuedBefore) {
+		issuedBefore = current.issuedBefore
+	}
+	b.users[userID] = userRevocation{issuedBefore: issuedBefore, expiresAt: expiresAt}
+	return nil
+}
+
+func (b *inMemoryTokenBlacklist) IsRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if jti != "" {
+		if expiresAt, ok := b.tokens[jti]; ok && now.Before(expiresAt) {
+			return true, nil
+		}
+	}
+	if revocation, ok := b.users[userID]; ok && now.Before(revocation.expiresAt) {
+		return issuedAt.Before(revocation.issuedBefore), nil
+	}
+	return false, nil
+}
+
+// sweepIfDue drops entries whose tokens have expired on their own, so the
+// blacklist does not grow forever.
+func (b *inMemoryTokenBlacklist) sweepIfDue(now time.Time) {
+	if now.Sub(b.lastSweep) < tokenBlacklistSweepInterval {
+		return
+	}
+	b.lastSweep = now
+
+	for jti, expiresAt := range b.tokens {
+		if !now.Before(expiresAt) {
+			delete(b.tokens, jti)
+		}
+	}
+	for userID, revocation := range b.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(b.users, userID)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBlacklist_RevokeByJTI(t *testing.T) {
+	blacklist := NewInMemoryTokenBlacklist()
+	userID := uuid.New()
+	issuedAt := time.Now().Add(-time.Minute)
+
+	require.NoError(t, blacklist.Revoke("jti-1", time.Now().Add(time.Hour)))
+
+	revoked, err := blacklist.IsRevoked("jti-1", userID, issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = blacklist.IsRevoked("jti-2", userID, issuedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenBlacklist_RevokeUser(t *testing.T) {
+	blacklist := NewInMemoryTokenBlacklist()
+	userID := uuid.New()
+	cutoff := time.Now()
+
+	require.NoError(t, blacklist.RevokeUser(userID, cutoff, cutoff.Add(time.Hour)))
+
+	revoked, _ := blacklist.IsRevoked("old", userID, cutoff.Add(-time.Minute))
+	assert.True(t, revoked)
+
+	revoked, _ = blacklist.IsRevoked("new", userID, cutoff.Add(time.Second))
+	assert.False(t, revoked)
+
+	revoked, _ = blacklist.IsRevoked("other", uuid.New(), cutoff.Add(-time.Minute))
+	assert.False(t, revoked)
+}
+
+func TestTokenBlacklist_RevokeUser_SameSecond(t *testing.T) {
+	blacklist := NewInMemoryTokenBlacklist()
+	userID := uuid.New()
+	cutoff := time.Date(2030, time.May, 30, 12, 0, 0, 700*int(time.Millisecond), time.UTC)
+
+	require.NoError(t, blacklist.RevokeUser(userID, cutoff, time.Now().Add(time.Hour)))
+
+	// A token's iat has whole seconds, so a login right after the logout
+	// carries the same second as the cutoff.
+	revoked, _ := blacklist.IsRevoked("fresh", userID, cutoff.Truncate(time.Second))
+	assert.False(t, revoked)
+
+	revoked, _ = blacklist.IsRevoked("old", userID, cutoff.Truncate(time.Second).Add(-time.Second))
+	assert.True(t, revoked)
+}
+
+func TestTokenBlacklist_EntriesExpire(t *testing.T) {
+	blacklist := NewInMemoryTokenBlacklist().(*inMemoryTokenBlacklist)
+	userID := uuid.New()
+	now := time.Now()
+
+	require.NoError(t, blacklist.Revoke("expired", now.Add(-time.Second)))
+	require.NoError(t, blacklist.RevokeUser(userID, now, now.Add(-time.Second)))
+
+	revoked, _ := blacklist.IsRevoked("expired", userID, now.Add(-time.Minute))
+	assert.False(t, revoked)
+
+	blacklist.lastSweep = time.Time{}
+	require.NoError(t, blacklist.Revoke("fresh", now.Add(time.Hour)))
+
+	assert.NotContains(t, blacklist.tokens, "expired")
+	assert.NotContains(t, blacklist.users, userID)
+	assert.Contains(t, blacklist.tokens, "fresh")
+}
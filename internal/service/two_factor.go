@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/pkg/totp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorIssuer    = "Restaurant Booking"
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrTwoFactorRequired       = errors.New("two-factor authentication code required")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor authentication code")
+	ErrInvalidTwoFactorToken   = errors.New("invalid or expired two-factor challenge")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorChallenge is returned by Login in place of tokens when the account
+// has two-factor authentication enabled. It matches ErrTwoFactorRequired, and
+// Token has to be exchanged with a valid code through VerifyTwoFactor.
+type TwoFactorChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (c *TwoFactorChallenge) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (c *TwoFactorChallenge) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+// SetupTwoFactor stores a new TOTP secret for the user. It has no effect on
+// login until EnableTwoFactor confirms a code generated from it.
+func (s *authService) SetupTwoFactor(userID uuid.UUID) (string, string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.AuthProvider == domain.AuthProviderGoogle {
+		return "", "", ErrOAuthAccount
+	}
+	if user.TwoFactorEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	user.TwoFactorSecret = &secret
+	if err := s.userRepo.Update(user); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.URL(twoFactorIssuer, user.Email, secret), nil
+}
+
+// EnableTwoFactor turns on two-factor authentication once code matches the
+// secret from SetupTwoFactor, and returns the recovery codes. They are only
+// ever shown here; the database keeps bcrypt hashes.
+func (s *authService) EnableTwoFactor(userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	if !totp.Validate(*user.TwoFactorSecret, code, time.Now()) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashed, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recoveryCodeRepo.ReplaceForUser(user.ID, hashed); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("two-factor authentication enabled", zap.String("user_id", user.ID.String()))
+	return codes, nil
+}
+
+// VerifyTwoFactor finishes a login started by Login. code is either the
+// current TOTP code or an unused recovery code. Failures count towards the
+// same lockout policy as passwords, and a challenge can only be used once.
+func (s *authService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, string, *domain.User, error) {
+	claims, err := s.jwtManager.ValidateTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return "", "", nil, ErrInvalidTwoFactorToken
+	}
+	if revoked, err := s.tokenBlacklist.IsRevoked(claims.ID, claims.UserID, claims.IssuedAt.Time); err != nil || revoked {
+		return "", "", nil, ErrInvalidTwoFactorToken
+	}
+
+	key := "2fa:" + claims.UserID.String()
+	if s.isLoginLocked(key) {
+		return "", "", nil, ErrAccountLocked
+	}
+
+	user, err := s.getUser(claims.UserID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !user.TwoFactorEnabled || user.TwoFactorSecret == nil {
+		return "", "", nil, ErrInvalidTwoFactorToken
+	}
+	if !user.IsActive {
+		return "", "", nil, ErrAccountDeactivated
+	}
+
+	valid, err := s.checkTwoFactorCode(user, code)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !valid {
+		if s.addLoginFailure(key, time.Now(), s.loginPolicy.MaxAttempts) {
+			s.log.Warn("two-factor verification locked after failed codes", zap.String("user_id", user.ID.String()))
+			s.auditLoginFailure(ctx, user, user.Email, "two_factor_locked")
+			return "", "", nil, ErrAccountLocked
+		}
+		s.auditLoginFailure(ctx, user, user.Email, "invalid_two_factor_code")
+		return "", "", nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.loginAttempts.Reset(key); err != nil {
+		s.log.Warn("failed to reset login attempts", zap.String("key", key), zap.Error(err))
+	}
+	if err := s.tokenBlacklist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return "", "", nil, err
+	}
+
+	accessToken, refreshToken, err := s.issueTokens(user)
+	if err != nil {
+		return "", "", nil, err
+	}
+	s.auditLogin(ctx, user, "two_factor")
+	return accessToken, refreshToken, user, nil
+}
+
+// issueTwoFactorChallenge is what Login and LoginWithGoogle return for
+// accounts with 2FA on.
+func (s *authService) issueTwoFactorChallenge(user *domain.User) error {
+	token, expiresAt, err := s.jwtManager.GenerateTwoFactorChallenge(user.ID)
+	if err != nil {
+		return err
+	}
+	return &TwoFactorChallenge{Token: token, ExpiresAt: expiresAt}
+}
+
+func (s *authService) checkTwoFactorCode(user *domain.User, code string) (bool, error) {
+	code = normalizeRecoveryCode(code)
+	if len(code) != recoveryCodeLength {
+		return totp.Validate(*user.TwoFactorSecret, code, time.Now()), nil
+	}
+
+	unused, err := s.recoveryCodeRepo.GetUnusedByUserID(user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, recovery := range unused {
+		if bcrypt.CompareHashAndPassword([]byte(recovery.CodeHash), []byte(code)) != nil {
+			continue
+		}
+		// A concurrent login may have used the same code first.
+		used, err := s.recoveryCodeRepo.MarkUsed(recovery.ID)
+		if err != nil {
+			return false, err
+		}
+		if used {
+			s.log.Info("recovery code used", zap.String("user_id", user.ID.String()), zap.Int("remaining", len(unused)-1))
+		}
+		return used, nil
+	}
+	return false, nil
+}
+
+func (s *authService) getUser(userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// generateRecoveryCodes returns the codes to show the user, formatted as
+// xxxxx-xxxxx, and their hashed records.
+func generateRecoveryCodes(userID uuid.UUID) ([]string, []*domain.TwoFactorRecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*domain.TwoFactorRecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength*5/8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		records = append(records, &domain.TwoFactorRecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  string(hash),
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return codes, records, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
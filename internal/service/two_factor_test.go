@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/pkg/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockTwoFactorRecoveryCodeRepository is a mock implementation of TwoFactorRecoveryCodeRepository
+type MockTwoFactorRecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) ReplaceForUser(userID uuid.UUID, codes []*domain.TwoFactorRecoveryCode) error {
+	args := m.Called(userID, codes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) GetUnusedByUserID(userID uuid.UUID) ([]*domain.TwoFactorRecoveryCode, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TwoFactorRecoveryCode), args.Error(1)
+}
+
+func (m *MockTwoFactorRecoveryCodeRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func setupTwoFactorService() (*authService, *MockUserRepository, *MockRefreshTokenRepository, *MockTwoFactorRecoveryCodeRepository) {
+	service, mockUserRepo, mockRefreshRepo := setupAuthService()
+	mockRecoveryRepo := new(MockTwoFactorRecoveryCodeRepository)
+	service.recoveryCodeRepo = mockRecoveryRepo
+	return service, mockUserRepo, mockRefreshRepo, mockRecoveryRepo
+}
+
+func twoFactorUser(t *testing.T, password string) *domain.User {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+
+	return &domain.User{
+		ID:               uuid.New(),
+		Email:            "owner@example.com",
+		Password:         string(hashedPassword),
+		Role:             domain.UserRoleOwner,
+		TwoFactorSecret:  &secret,
+		TwoFactorEnabled: true,
+		IsActive:         true,
+	}
+}
+
+func currentCode(t *testing.T, user *domain.User) string {
+	code, err := totp.Code(*user.TwoFactorSecret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestSetupTwoFactor_StoresSecret(t *testing.T) {
+	service, mockUserRepo, _, _ := setupTwoFactorService()
+
+	user := &domain.User{ID: uuid.New(), Email: "owner@example.com", AuthProvider: domain.AuthProviderPassword}
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool {
+		return u.TwoFactorSecret != nil && !u.TwoFactorEnabled
+	})).Return(nil)
+
+	secret, otpauthURL, err := service.SetupTwoFactor(user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, *user.TwoFactorSecret, secret)
+	assert.Contains(t, otpauthURL, "secret="+secret)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestSetupTwoFactor_AlreadyEnabled(t *testing.T) {
+	service, mockUserRepo, _, _ := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	_, _, err := service.SetupTwoFactor(user.ID)
+
+	assert.Equal(t, ErrTwoFactorAlreadyEnabled, err)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestEnableTwoFactor_StoresHashedRecoveryCodes(t *testing.T) {
+	service, mockUserRepo, _, mockRecoveryRepo := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	user.TwoFactorEnabled = false
+
+	var stored []*domain.TwoFactorRecoveryCode
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRecoveryRepo.On("ReplaceForUser", user.ID, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]*domain.TwoFactorRecoveryCode)
+	}).Return(nil)
+	mockUserRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool { return u.TwoFactorEnabled })).Return(nil)
+
+	codes, err := service.EnableTwoFactor(user.ID, currentCode(t, user))
+
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, stored, recoveryCodeCount)
+	for i, code := range codes {
+		assert.NotContains(t, stored[i].CodeHash, code)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored[i].CodeHash), []byte(strings.ReplaceAll(code, "-", ""))))
+	}
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestEnableTwoFactor_InvalidCode(t *testing.T) {
+	service, mockUserRepo, _, mockRecoveryRepo := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	user.TwoFactorEnabled = false
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	_, err := service.EnableTwoFactor(user.ID, "000000")
+
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+	assert.False(t, user.TwoFactorEnabled)
+	mockRecoveryRepo.AssertNotCalled(t, "ReplaceForUser", mock.Anything, mock.Anything)
+}
+
+func TestLogin_TwoFactorReturnsChallenge(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo, _ := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	mockUserRepo.On("GetByEmail", user.Email).Return(user, nil)
+
+	accessToken, refreshToken, _, err := service.Login(context.Background(), user.Email, "password123", "127.0.0.1")
+
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	assert.Empty(t, accessToken)
+	assert.Empty(t, refreshToken)
+	mockRefreshRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	challenge, ok := err.(*TwoFactorChallenge)
+	require.True(t, ok)
+	_, err = service.jwtManager.ValidateAccessToken(challenge.Token)
+	assert.Error(t, err, "a challenge must not work as an access token")
+}
+
+func TestLoginWithGoogle_TwoFactorReturnsChallenge(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo, _ := setupTwoFactorService()
+	service.googleVerifier = stubGoogleVerifier{claims: googleClaims()}
+
+	user := twoFactorUser(t, "password123")
+	user.Email = "user@gmail.com"
+	user.AuthProvider = domain.AuthProviderPassword
+	mockUserRepo.On("GetByEmail", user.Email).Return(user, nil)
+
+	accessToken, refreshToken, _, err := service.LoginWithGoogle(context.Background(), "id-token")
+
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	assert.Empty(t, accessToken)
+	assert.Empty(t, refreshToken)
+	mockRefreshRepo.AssertNotCalled(t, "Create", mock.Anything)
+	// The password account is not switched to Google sign-in.
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+	assert.Equal(t, domain.AuthProviderPassword, user.AuthProvider)
+	assert.Nil(t, user.GoogleID)
+}
+
+func TestVerifyTwoFactor_WithTOTP(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo, _ := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	challenge, _, err := service.jwtManager.GenerateTwoFactorChallenge(user.ID)
+	require.NoError(t, err)
+
+	accessToken, refreshToken, verified, err := service.VerifyTwoFactor(context.Background(), challenge, currentCode(t, user))
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, accessToken)
+	assert.NotEmpty(t, refreshToken)
+	assert.Equal(t, user.ID, verified.ID)
+
+	_, _, _, err = service.VerifyTwoFactor(context.Background(), challenge, currentCode(t, user))
+	assert.Equal(t, ErrInvalidTwoFactorToken, err, "a challenge can only be used once")
+}
+
+func TestVerifyTwoFactor_WithRecoveryCode(t *testing.T) {
+	service, mockUserRepo, mockRefreshRepo, mockRecoveryRepo := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	hash, _ := bcrypt.GenerateFromPassword([]byte("abcdefghij"), bcrypt.MinCost)
+	recovery := &domain.TwoFactorRecoveryCode{ID: uuid.New(), UserID: user.ID, CodeHash: string(hash)}
+
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockRecoveryRepo.On("GetUnusedByUserID", user.ID).Return([]*domain.TwoFactorRecoveryCode{recovery}, nil)
+	mockRecoveryRepo.On("MarkUsed", recovery.ID).Return(true, nil)
+	mockRefreshRepo.On("Create", mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	challenge, _, err := service.jwtManager.GenerateTwoFactorChallenge(user.ID)
+	require.NoError(t, err)
+
+	_, _, _, err = service.VerifyTwoFactor(context.Background(), challenge, "ABCDE-FGHIJ")
+
+	require.NoError(t, err)
+	mockRecoveryRepo.AssertExpectations(t)
+}
+
+func TestVerifyTwoFactor_LocksAfterFailedCodes(t *testing.T) {
+	service, mockUserRepo, _, _ := setupTwoFactorService()
+
+	user := twoFactorUser(t, "password123")
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	challenge, _, err := service.jwtManager.GenerateTwoFactorChallenge(user.ID)
+	require.NoError(t, err)
+
+	for i := 0; i < service.loginPolicy.MaxAttempts-1; i++ {
+		_, _, _, err := service.VerifyTwoFactor(context.Background(), challenge, "000000")
+		assert.Equal(t, ErrInvalidTwoFactorCode, err)
+	}
+
+	_, _, _, err = service.VerifyTwoFactor(context.Background(), challenge, "000000")
+	assert.Equal(t, ErrAccountLocked, err)
+
+	_, _, _, err = service.VerifyTwoFactor(context.Background(), challenge, currentCode(t, user))
+	assert.Equal(t, ErrAccountLocked, err)
+}
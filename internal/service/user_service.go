@@ -1,35 +1,76 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"restaurant-booking/internal/domain"
 	"restaurant-booking/internal/repository"
 	"restaurant-booking/pkg/logger"
+	"restaurant-booking/pkg/phone"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 var (
 	ErrOldPasswordIncorrect = errors.New("old password is incorrect")
+	ErrPhoneTaken           = repository.ErrDuplicatePhone
+	ErrInvalidPhone         = errors.New("invalid phone number")
+	ErrInvalidLocale        = errors.New("unsupported locale")
+	ErrNoPhoneToClaim       = errors.New("add a phone number to your account to claim guest bookings")
 )
 
 type UserService interface {
 	GetUserByID(id uuid.UUID) (*domain.User, error)
-	UpdateUser(id uuid.UUID, firstName, lastName, phone string) (*domain.User, error)
-	ChangePassword(id uuid.UUID, oldPassword, newPassword string) error
+	// UpdateUser replaces the user's profile. locale must be one of
+	// domain.Locales, or empty for domain.DefaultLocale.
+	UpdateUser(id uuid.UUID, firstName, lastName, phone string, locale domain.Locale) (*domain.User, error)
+	ChangePassword(ctx context.Context, id uuid.UUID, oldPassword, newPassword string) error
+	ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*domain.User, int64, error)
+	// DeactivateAccount disables the account, ends all its sessions and
+	// cancels its upcoming pending bookings, returning how many.
+	DeactivateAccount(ctx context.Context, id uuid.UUID) (int64, error)
+	ReactivateUser(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	// ClaimGuestBookings links the guest bookings staff made for the
+	// user's account phone to the user and returns how many. The phone must
+	// be verified, or anyone could take a guest's bookings by entering
+	// their number.
+	ClaimGuestBookings(ctx context.Context, id uuid.UUID) (int64, error)
+	// SendPhoneCode texts a verification code for the user's phone to it.
+	SendPhoneCode(ctx context.Context, id uuid.UUID) error
+	// VerifyPhone marks the user's phone verified if code is the last one
+	// sent to it and has not expired.
+	VerifyPhone(ctx context.Context, id uuid.UUID, code string) error
+}
+
+// SessionRevoker ends all sessions of a user. AuthService implements it.
+type SessionRevoker interface {
+	LogoutAll(userID uuid.UUID) (int64, error)
 }
 
 type userService struct {
-	userRepo repository.UserRepository
-	log      logger.Logger
+	userRepo         repository.UserRepository
+	bookingRepo      repository.BookingRepository
+	sessions         SessionRevoker
+	audit            AuditRecorder
+	notificationSvc  *NotificationService
+	phoneCountryCode string
+	log              logger.Logger
 }
 
-func NewUserService(userRepo repository.UserRepository, log logger.Logger) UserService {
+func NewUserService(userRepo repository.UserRepository, bookingRepo repository.BookingRepository, sessions SessionRevoker, audit AuditRecorder, notificationSvc *NotificationService, phoneCountryCode string, log logger.Logger) UserService {
 	return &userService{
-		userRepo: userRepo,
-		log:      log,
+		userRepo:         userRepo,
+		bookingRepo:      bookingRepo,
+		sessions:         sessions,
+		audit:            audit,
+		notificationSvc:  notificationSvc,
+		phoneCountryCode: phoneCountryCode,
+		log:              log,
 	}
 }
 
@@ -44,7 +85,11 @@ func (s *userService) GetUserByID(id uuid.UUID) (*domain.User, error) {
 	return user, nil
 }
 
-func (s *userService) UpdateUser(id uuid.UUID, firstName, lastName, phone string) (*domain.User, error) {
+func (s *userService) UpdateUser(id uuid.UUID, firstName, lastName, phone string, locale domain.Locale) (*domain.User, error) {
+	if locale != "" && !slices.Contains(domain.Locales, locale) {
+		return nil, ErrInvalidLocale
+	}
+
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -53,9 +98,22 @@ func (s *userService) UpdateUser(id uuid.UUID, firstName, lastName, phone string
 		return nil, err
 	}
 
+	// Numbers stored before normalization are kept as they are until changed.
+	if phone != user.Phone {
+		if phone, err = normalizePhone(phone, s.phoneCountryCode); err != nil {
+			return nil, err
+		}
+	}
+	// A new number has to be verified again.
+	if phone != user.Phone {
+		user.PhoneVerified = false
+		user.ClearPhoneCode()
+	}
+
 	user.FirstName = firstName
 	user.LastName = lastName
 	user.Phone = phone
+	user.Locale = supportedLocale(locale)
 
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, err
@@ -64,7 +122,7 @@ func (s *userService) UpdateUser(id uuid.UUID, firstName, lastName, phone string
 	return user, nil
 }
 
-func (s *userService) ChangePassword(id uuid.UUID, oldPassword, newPassword string) error {
+func (s *userService) ChangePassword(ctx context.Context, id uuid.UUID, oldPassword, newPassword string) error {
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -88,5 +146,121 @@ func (s *userService) ChangePassword(id uuid.UUID, oldPassword, newPassword stri
 
 	user.Password = string(hashedPassword)
 
-	return s.userRepo.Update(user)
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	terminated, err := s.sessions.LogoutAll(user.ID)
+	if err != nil {
+		return err
+	}
+	s.log.Info("password changed, sessions terminated",
+		zap.String("user_id", user.ID.String()), zap.Int64("sessions", terminated))
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    user.ID,
+		Action:     AuditActionPasswordChange,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   map[string]interface{}{"sessions_terminated": terminated},
+	})
+
+	return nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	return s.userRepo.List(ctx, filter, limit, offset)
+}
+
+func (s *userService) DeactivateAccount(ctx context.Context, id uuid.UUID) (int64, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if user.IsActive {
+		user.IsActive = false
+		if err := s.userRepo.Update(user); err != nil {
+			return 0, err
+		}
+	}
+
+	terminated, err := s.sessions.LogoutAll(user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled, err := s.bookingRepo.CancelPendingByUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Info("account deactivated",
+		zap.String("user_id", user.ID.String()),
+		zap.Int64("sessions", terminated),
+		zap.Int64("bookings_cancelled", cancelled))
+
+	return cancelled, nil
+}
+
+func (s *userService) ReactivateUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		user.IsActive = true
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+		s.log.Info("account reactivated", zap.String("user_id", user.ID.String()))
+	}
+
+	return user, nil
+}
+
+func (s *userService) ClaimGuestBookings(ctx context.Context, id uuid.UUID) (int64, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return 0, err
+	}
+	if user.Phone == "" {
+		return 0, ErrNoPhoneToClaim
+	}
+	if !user.PhoneVerified {
+		return 0, ErrPhoneNotVerified
+	}
+
+	claimed, err := s.bookingRepo.ClaimGuestBookings(ctx, user.Phone, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if claimed > 0 {
+		s.log.Info("guest bookings claimed",
+			zap.String("user_id", user.ID.String()),
+			zap.Int64("bookings", claimed))
+	}
+	return claimed, nil
+}
+
+// normalizePhone stores phone numbers in E.164 so the unique index compares
+// like with like. An empty number is kept for accounts that have none.
+func normalizePhone(raw, defaultCountryCode string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	normalized, err := phone.Normalize(raw, defaultCountryCode)
+	if err != nil {
+		return "", ErrInvalidPhone
+	}
+	return normalized, nil
+}
+
+// supportedLocale returns locale, or domain.DefaultLocale when it is not
+// one of domain.Locales.
+func supportedLocale(locale domain.Locale) domain.Locale {
+	if slices.Contains(domain.Locales, locale) {
+		return locale
+	}
+	return domain.DefaultLocale
 }
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -47,12 +50,37 @@ func (m *MockUserRepositoryForUserService) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepositoryForUserService) List(ctx context.Context, filter repository.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+// Mock SessionRevoker for user service tests
+type MockSessionRevoker struct {
+	mock.Mock
+}
+
+func (m *MockSessionRevoker) LogoutAll(userID uuid.UUID) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func setupUserService() (UserService, *MockUserRepositoryForUserService) {
-	mockUserRepo := new(MockUserRepositoryForUserService)
-	service := NewUserService(mockUserRepo)
+	service, mockUserRepo, _ := setupUserServiceWithSessions()
 	return service, mockUserRepo
 }
 
+func setupUserServiceWithSessions() (UserService, *MockUserRepositoryForUserService, *MockSessionRevoker) {
+	mockUserRepo := new(MockUserRepositoryForUserService)
+	mockSessions := new(MockSessionRevoker)
+	mockSessions.On("LogoutAll", mock.AnythingOfType("uuid.UUID")).Return(int64(0), nil).Maybe()
+	service := NewUserService(mockUserRepo, new(BookingMockBookingRepository), mockSessions, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7", zap.NewNop())
+	return service, mockUserRepo, mockSessions
+}
+
 // Test GetUserByID - Success
 func TestGetUserByID_Success(t *testing.T) {
 	service, mockUserRepo := setupUserService()
@@ -120,7 +148,7 @@ func TestUpdateUser_Success(t *testing.T) {
 
 	updatedFirstName := "Jane"
 	updatedLastName := "Smith"
-	updatedPhone := "0987654321"
+	updatedPhone := "+77017654321"
 
 	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
 	mockUserRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool {
@@ -131,7 +159,7 @@ func TestUpdateUser_Success(t *testing.T) {
 	})).Return(nil)
 
 	// Act
-	user, err := service.UpdateUser(userID, updatedFirstName, updatedLastName, updatedPhone)
+	user, err := service.UpdateUser(userID, updatedFirstName, updatedLastName, "8 701 765 43 21", domain.LocaleEnglish)
 
 	// Assert
 	assert.NoError(t, err)
@@ -152,7 +180,7 @@ func TestUpdateUser_UserNotFound(t *testing.T) {
 	mockUserRepo.On("GetByID", userID).Return(nil, gorm.ErrRecordNotFound)
 
 	// Act
-	user, err := service.UpdateUser(userID, "Jane", "Smith", "0987654321")
+	user, err := service.UpdateUser(userID, "Jane", "Smith", "0987654321", domain.LocaleEnglish)
 
 	// Assert
 	assert.Error(t, err)
@@ -162,6 +190,55 @@ func TestUpdateUser_UserNotFound(t *testing.T) {
 	mockUserRepo.AssertExpectations(t)
 }
 
+func TestUpdateUser_InvalidPhone(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Phone: "+77001234567"}, nil)
+
+	_, err := service.UpdateUser(userID, "Jane", "Doe", "+7 700 123", domain.LocaleEnglish)
+
+	assert.Equal(t, ErrInvalidPhone, err)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateUser_Locale(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	userID := uuid.New()
+	existingUser := &domain.User{ID: userID, Phone: "+77001234567", Locale: domain.LocaleEnglish}
+	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+
+	user, err := service.UpdateUser(userID, "Jane", "Doe", "+77001234567", domain.LocaleKazakh)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LocaleKazakh, user.Locale)
+}
+
+func TestUpdateUser_UnsupportedLocale(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	_, err := service.UpdateUser(uuid.New(), "Jane", "Doe", "+77001234567", domain.Locale("de"))
+
+	assert.Equal(t, ErrInvalidLocale, err)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateUser_KeepsUnchangedLegacyPhone(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	userID := uuid.New()
+	existingUser := &domain.User{ID: userID, Phone: "+1 (555) 0100"}
+	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+
+	user, err := service.UpdateUser(userID, "Jane", "Doe", "+1 (555) 0100", domain.LocaleEnglish)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "+1 (555) 0100", user.Phone)
+}
+
 // Test ChangePassword - Success
 func TestChangePassword_Success(t *testing.T) {
 	service, mockUserRepo := setupUserService()
@@ -187,7 +264,7 @@ func TestChangePassword_Success(t *testing.T) {
 	})).Return(nil)
 
 	// Act
-	err := service.ChangePassword(userID, oldPassword, newPassword)
+	err := service.ChangePassword(context.Background(), userID, oldPassword, newPassword)
 
 	// Assert
 	assert.NoError(t, err)
@@ -195,6 +272,40 @@ func TestChangePassword_Success(t *testing.T) {
 	mockUserRepo.AssertExpectations(t)
 }
 
+// Test ChangePassword - Terminates all sessions
+func TestChangePassword_LogsOutAllSessions(t *testing.T) {
+	service, mockUserRepo, mockSessions := setupUserServiceWithSessions()
+
+	userID := uuid.New()
+	hashedOldPassword, _ := bcrypt.GenerateFromPassword([]byte("oldpassword123"), bcrypt.MinCost)
+	existingUser := &domain.User{
+		ID:       userID,
+		Password: string(hashedOldPassword),
+	}
+
+	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+
+	err := service.ChangePassword(context.Background(), userID, "oldpassword123", "newpassword456")
+
+	assert.NoError(t, err)
+	mockSessions.AssertCalled(t, "LogoutAll", userID)
+}
+
+// Test ChangePassword - Wrong old password keeps sessions
+func TestChangePassword_WrongOldPasswordKeepsSessions(t *testing.T) {
+	service, mockUserRepo, mockSessions := setupUserServiceWithSessions()
+
+	userID := uuid.New()
+	hashedOldPassword, _ := bcrypt.GenerateFromPassword([]byte("oldpassword123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Password: string(hashedOldPassword)}, nil)
+
+	err := service.ChangePassword(context.Background(), userID, "wrongpassword", "newpassword456")
+
+	assert.Equal(t, ErrOldPasswordIncorrect, err)
+	mockSessions.AssertNotCalled(t, "LogoutAll", userID)
+}
+
 // Test ChangePassword - User Not Found
 func TestChangePassword_UserNotFound(t *testing.T) {
 	service, mockUserRepo := setupUserService()
@@ -204,7 +315,7 @@ func TestChangePassword_UserNotFound(t *testing.T) {
 	mockUserRepo.On("GetByID", userID).Return(nil, gorm.ErrRecordNotFound)
 
 	// Act
-	err := service.ChangePassword(userID, "oldpassword", "newpassword123")
+	err := service.ChangePassword(context.Background(), userID, "oldpassword", "newpassword123")
 
 	// Assert
 	assert.Error(t, err)
@@ -233,7 +344,7 @@ func TestChangePassword_WrongOldPassword(t *testing.T) {
 	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
 
 	// Act
-	err := service.ChangePassword(userID, wrongOldPassword, "newpassword123")
+	err := service.ChangePassword(context.Background(), userID, wrongOldPassword, "newpassword123")
 
 	// Assert
 	assert.Error(t, err)
@@ -261,7 +372,7 @@ func TestChangePassword_ShortNewPassword(t *testing.T) {
 	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
 
 	// Act
-	err := service.ChangePassword(userID, oldPassword, "short")
+	err := service.ChangePassword(context.Background(), userID, oldPassword, "short")
 
 	// Assert
 	assert.Error(t, err)
@@ -269,3 +380,122 @@ func TestChangePassword_ShortNewPassword(t *testing.T) {
 
 	mockUserRepo.AssertExpectations(t)
 }
+
+// Test ListUsers - passes filter and pagination through
+func TestListUsers_Success(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	// Arrange
+	role := domain.UserRoleOwner
+	filter := repository.UserFilter{Role: &role, Search: "john"}
+	users := []*domain.User{{ID: uuid.New(), Email: "john@example.com", Role: role}}
+
+	mockUserRepo.On("List", mock.Anything, filter, 20, 40).Return(users, int64(41), nil)
+
+	// Act
+	result, total, err := service.ListUsers(context.Background(), filter, 20, 40)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, int64(41), total)
+
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestDeactivateAccount_EndsSessionsAndCancelsBookings(t *testing.T) {
+	mockUserRepo := new(MockUserRepositoryForUserService)
+	mockBookingRepo := new(BookingMockBookingRepository)
+	mockSessions := new(MockSessionRevoker)
+	service := NewUserService(mockUserRepo, mockBookingRepo, mockSessions, NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7", zap.NewNop())
+	ctx := context.Background()
+
+	userID := uuid.New()
+	existingUser := &domain.User{ID: userID, IsActive: true}
+
+	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+	mockSessions.On("LogoutAll", userID).Return(int64(2), nil)
+	mockBookingRepo.On("CancelPendingByUser", ctx, userID, mock.AnythingOfType("time.Time")).Return(int64(3), nil)
+
+	cancelled, err := service.DeactivateAccount(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cancelled)
+	assert.False(t, existingUser.IsActive)
+	mockUserRepo.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestReactivateUser_Success(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	userID := uuid.New()
+	existingUser := &domain.User{ID: userID, IsActive: false}
+
+	mockUserRepo.On("GetByID", userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", existingUser).Return(nil)
+
+	user, err := service.ReactivateUser(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.True(t, user.IsActive)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestReactivateUser_UserNotFound(t *testing.T) {
+	service, mockUserRepo := setupUserService()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.ReactivateUser(context.Background(), userID)
+
+	assert.Equal(t, ErrUserNotFound, err)
+}
+
+func TestClaimGuestBookings_LinksByAccountPhone(t *testing.T) {
+	mockUserRepo := new(MockUserRepositoryForUserService)
+	mockBookingRepo := new(BookingMockBookingRepository)
+	service := NewUserService(mockUserRepo, mockBookingRepo, new(MockSessionRevoker), NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7", zap.NewNop())
+	ctx := context.Background()
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Phone: "+77011234567", PhoneVerified: true}, nil)
+	mockBookingRepo.On("ClaimGuestBookings", ctx, "+77011234567", userID).Return(int64(2), nil)
+
+	claimed, err := service.ClaimGuestBookings(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), claimed)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestClaimGuestBookings_NeedsAPhone(t *testing.T) {
+	mockUserRepo := new(MockUserRepositoryForUserService)
+	mockBookingRepo := new(BookingMockBookingRepository)
+	service := NewUserService(mockUserRepo, mockBookingRepo, new(MockSessionRevoker), NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7", zap.NewNop())
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID}, nil)
+
+	_, err := service.ClaimGuestBookings(context.Background(), userID)
+
+	assert.ErrorIs(t, err, ErrNoPhoneToClaim)
+	mockBookingRepo.AssertNotCalled(t, "ClaimGuestBookings", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGuestBookings_NeedsAVerifiedPhone(t *testing.T) {
+	mockUserRepo := new(MockUserRepositoryForUserService)
+	mockBookingRepo := new(BookingMockBookingRepository)
+	service := NewUserService(mockUserRepo, mockBookingRepo, new(MockSessionRevoker), NewLogAuditRecorder(zap.NewNop()), NewNotificationService(1, 10), "7", zap.NewNop())
+
+	userID := uuid.New()
+	mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Phone: "+77011234567"}, nil)
+
+	_, err := service.ClaimGuestBookings(context.Background(), userID)
+
+	assert.ErrorIs(t, err, ErrPhoneNotVerified)
+	mockBookingRepo.AssertNotCalled(t, "ClaimGuestBookings", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// WaitlistOfferTTL is how long a waitlist customer has to claim a freed
+	// table before it goes to the next entry.
+	WaitlistOfferTTL = 15 * time.Minute
+	// WaitlistInterval is how often lapsed offers are passed on.
+	WaitlistInterval = time.Minute
+	// waitlistCandidates caps the waiting entries checked for one freed
+	// table.
+	waitlistCandidates = 20
+	// waitlistBatchSize is how many lapsed offers one query of the job
+	// loads.
+	waitlistBatchSize = 100
+)
+
+var (
+	ErrWaitlistOfferNotFound = errors.New("waitlist offer not found")
+	ErrWaitlistOfferExpired  = errors.New("waitlist offer has expired")
+	ErrWaitlistOfferClaimed  = errors.New("waitlist offer has already been claimed")
+	ErrNoFittingTable        = errors.New("restaurant has no table for that many guests")
+)
+
+// JoinWaitlistRequest puts UserID in line for a table for GuestsCount from
+// StartTime to EndTime.
+type JoinWaitlistRequest struct {
+	RestaurantID uuid.UUID
+	UserID       uuid.UUID
+	StartTime    time.Time
+	EndTime      time.Time
+	GuestsCount  int
+}
+
+type WaitlistService interface {
+	// Join puts the user on the restaurant's waitlist. The request is
+	// checked like a booking, except that no table has to be free.
+	Join(ctx context.Context, req JoinWaitlistRequest) (*domain.WaitlistEntry, error)
+	// SlotFreed is called when the booking is cancelled. In the background,
+	// it offers the booking's table to the oldest waiting entry that fits
+	// it and overlaps the booking, provided the table is free for the
+	// entry's times, and emails that customer a claim link.
+	SlotFreed(ctx context.Context, booking *domain.Booking)
+	// Claim books the table offered to the entry behind token for its
+	// customer userID. The booking is saved pending under the table's
+	// cancellation policy like any other. An offer claimed too late is
+	// expired and its table goes to the next entry.
+	Claim(ctx context.Context, token string, userID uuid.UUID) (*domain.Booking, error)
+	// Start runs Run every WaitlistInterval until ctx is cancelled.
+	Start(ctx context.Context)
+	// Run expires the offers that lapsed by now and passes their tables on.
+	Run(ctx context.Context, now time.Time)
+}
+
+type waitlistService struct {
+	waitlistRepo    repository.WaitlistRepository
+	bookingRepo     repository.BookingRepository
+	tableRepo       repository.TableRepository
+	restaurantRepo  repository.RestaurantRepository
+	notificationSvc *NotificationService
+	db              *gorm.DB
+	log             logger.Logger
+	now             func() time.Time
+	launch          func(fn func())
+}
+
+func NewWaitlistService(
+	waitlistRepo repository.WaitlistRepository,
+	bookingRepo repository.BookingRepository,
+	tableRepo repository.TableRepository,
+	restaurantRepo repository.RestaurantRepository,
+	notificationSvc *NotificationService,
+	db *gorm.DB,
+	log logger.Logger,
+) WaitlistService {
+	return &waitlistService{
+		waitlistRepo:    waitlistRepo,
+		bookingRepo:     bookingRepo,
+		tableRepo:       tableRepo,
+		restaurantRepo:  restaurantRepo,
+		notificationSvc: notificationSvc,
+		db:              db,
+		log:             log,
+		now:             time.Now,
+		launch:          func(fn func()) { go fn() },
+	}
+}
+
+func (s *waitlistService) Join(ctx context.Context, req JoinWaitlistRequest) (*domain.WaitlistEntry, error) {
+	if !req.EndTime.After(req.StartTime) {
+		return nil, ErrInvalidBookingPeriod
+	}
+	now := s.now()
+	if !req.StartTime.After(now) {
+		return nil, ErrPastBooking
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, req.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	localStart := req.StartTime.In(restaurant.Location())
+	if err := ValidateLastSeating(restaurant, localStart); err != nil {
+		return nil, err
+	}
+	if !canSeatAt(restaurant, localStart) {
+		return nil, ErrOutsideWorkingHours
+	}
+
+	tables, err := s.tableRepo.GetByMinCapacity(ctx, restaurant.ID, req.GuestsCount)
+	if err != nil {
+		return nil, err
+	}
+	if len(fittingTables(tables, req.GuestsCount)) == 0 {
+		return nil, ErrNoFittingTable
+	}
+
+	entry := &domain.WaitlistEntry{
+		RestaurantID: restaurant.ID,
+		UserID:       req.UserID,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		GuestsCount:  req.GuestsCount,
+		Status:       domain.WaitlistStatusWaiting,
+		CreatedAt:    now,
+	}
+	if err := s.waitlistRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *waitlistService) SlotFreed(ctx context.Context, booking *domain.Booking) {
+	if !booking.EndTime.After(s.now()) {
+		return
+	}
+	// The offer outlives the request that cancelled the booking.
+	runCtx := context.WithoutCancel(ctx)
+	tableID, start, end := booking.TableID, booking.StartTime, booking.EndTime
+	s.launch(func() { s.offerTable(runCtx, tableID, start, end) })
+}
+
+func (s *waitlistService) Claim(ctx context.Context, token string, userID uuid.UUID) (*domain.Booking, error) {
+	entry, err := s.waitlistRepo.GetByClaimToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaitlistOfferNotFound
+		}
+		return nil, err
+	}
+	// Someone else's offer is reported as missing rather than forbidden.
+	if entry.UserID != userID {
+		return nil, ErrWaitlistOfferNotFound
+	}
+
+	now := s.now()
+	switch {
+	case entry.Status == domain.WaitlistStatusBooked:
+		return nil, ErrWaitlistOfferClaimed
+	case entry.Status != domain.WaitlistStatusOffered || entry.OfferTableID == nil:
+		return nil, ErrWaitlistOfferExpired
+	case !now.Before(*entry.OfferExpiresAt):
+		s.lapse(ctx, entry)
+		return nil, ErrWaitlistOfferExpired
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, entry.RestaurantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRestaurantNotFound
+		}
+		return nil, err
+	}
+	table, err := s.tableRepo.GetByID(ctx, *entry.OfferTableID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, err
+	}
+
+	localStart := entry.StartTime.In(restaurant.Location())
+	booking := &domain.Booking{
+		RestaurantID: restaurant.ID,
+		TableID:      table.ID,
+		UserID:       &entry.UserID,
+		BookingDate:  time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, localStart.Location()),
+		StartTime:    entry.StartTime,
+		EndTime:      entry.EndTime,
+		GuestsCount:  entry.GuestsCount,
+		Status:       domain.BookingStatusPending,
+	}
+	booking.ApplyPolicy(TablePolicy(restaurant, table))
+
+	// The booking and the claim are saved together, so a claim that loses
+	// a race leaves neither behind.
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created, err := s.bookingRepo.WithTx(tx).CreateIfAvailable(ctx, booking)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrTableNotAvailable
+		}
+		claimed, err := s.waitlistRepo.WithTx(tx).MarkBooked(ctx, entry.ID, booking.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrWaitlistOfferClaimed
+		}
+		return nil
+	})
+	if errors.Is(err, ErrTableNotAvailable) || errors.Is(err, ErrGuestLimitReached) {
+		// The table was booked, or the restaurant filled up, some other way,
+		// which is not the customer's fault, so they keep their place in line.
+		if _, err := s.waitlistRepo.ReturnToWaiting(ctx, entry.ID); err != nil {
+			s.log.Warn("failed to return waitlist entry to waiting",
+				zap.String("entry_id", entry.ID.String()),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Set after Create, so saving the booking does not touch the table.
+	booking.Table = table
+	return booking, nil
+}
+
+// Start runs the job in the background until ctx is cancelled.
+func (s *waitlistService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(WaitlistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.Run(ctx, now)
+			}
+		}
+	}()
+}
+
+func (s *waitlistService) Run(ctx context.Context, now time.Time) {
+	var lapsed int
+	for {
+		entries, err := s.waitlistRepo.GetLapsedOffers(ctx, now, waitlistBatchSize)
+		if err != nil {
+			s.log.Warn("waitlist job: loading lapsed offers failed", zap.Error(err))
+			break
+		}
+		for _, entry := range entries {
+			if s.lapse(ctx, entry) {
+				lapsed++
+			}
+		}
+		if len(entries) < waitlistBatchSize {
+			break
+		}
+	}
+	s.log.Info("waitlist job finished", zap.Int("offers_lapsed", lapsed))
+}
+
+// lapse expires the entry's offer and offers its table for the entry's
+// times to the next entry, reporting whether it expired the offer. An
+// offer claimed or expired meanwhile is left alone.
+func (s *waitlistService) lapse(ctx context.Context, entry *domain.WaitlistEntry) bool {
+	expired, err := s.waitlistRepo.ExpireOffer(ctx, entry.ID)
+	if err != nil {
+		s.log.Warn("failed to expire waitlist offer",
+			zap.String("entry_id", entry.ID.String()),
+			zap.Error(err))
+		return false
+	}
+	if !expired || entry.OfferTableID == nil {
+		return expired
+	}
+
+	runCtx := context.WithoutCancel(ctx)
+	tableID, start, end := *entry.OfferTableID, entry.StartTime, entry.EndTime
+	s.launch(func() { s.offerTable(runCtx, tableID, start, end) })
+	return true
+}
+
+// offerTable offers the table, freed between start and end, to the first
+// fitting waiting entry it is free for. Nobody is waiting on the outcome,
+// so failures are only logged.
+func (s *waitlistService) offerTable(ctx context.Context, tableID uuid.UUID, start, end time.Time) {
+	table, err := s.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		s.log.Warn("waitlist: loading freed table failed", zap.String("table_id", tableID.String()), zap.Error(err))
+		return
+	}
+	if !table.IsActive {
+		return
+	}
+	restaurant, err := s.restaurantRepo.GetByID(ctx, table.RestaurantID)
+	if err != nil {
+		s.log.Warn("waitlist: loading restaurant failed", zap.String("table_id", tableID.String()), zap.Error(err))
+		return
+	}
+
+	now := s.now()
+	entries, err := s.waitlistRepo.ListWaiting(ctx, restaurant.ID, start, end, table.MinCapacity, table.MaxCapacity, now, waitlistCandidates)
+	if err != nil {
+		s.log.Warn("waitlist: loading waiting entries failed", zap.String("table_id", tableID.String()), zap.Error(err))
+		return
+	}
+
+	for _, entry := range entries {
+		if ValidateBookingDuration(restaurant, table, entry.StartTime, entry.EndTime) != nil {
+			continue
+		}
+		free, err := s.bookingRepo.CheckTableAvailability(ctx, table.ID, entry.StartTime, entry.EndTime)
+		if err != nil {
+			s.log.Warn("waitlist: checking table availability failed", zap.String("table_id", tableID.String()), zap.Error(err))
+			return
+		}
+		if !free {
+			continue
+		}
+
+		secret, err := generateInvitationToken()
+		if err != nil {
+			s.log.Warn("waitlist: generating claim token failed", zap.Error(err))
+			return
+		}
+		// An offer never outlasts the start of the table it offers.
+		expiresAt := now.Add(WaitlistOfferTTL)
+		if entry.StartTime.Before(expiresAt) {
+			expiresAt = entry.StartTime
+		}
+		offered, err := s.waitlistRepo.MarkOffered(ctx, entry.ID, table.ID, secret, expiresAt)
+		if err != nil {
+			s.log.Warn("waitlist: saving offer failed", zap.String("entry_id", entry.ID.String()), zap.Error(err))
+			return
+		}
+		if !offered {
+			continue
+		}
+
+		s.notifyOffer(restaurant, entry, secret, expiresAt)
+		return
+	}
+}
+
+// notifyOffer emails the customer their claim link. The offer is already
+// saved, so a failure here is only logged; the offer then lapses and goes to
+// the next entry.
+func (s *waitlistService) notifyOffer(restaurant *domain.Restaurant, entry *domain.WaitlistEntry, token string, expiresAt time.Time) {
+	if entry.User == nil {
+		s.log.Warn("cannot email waitlist offer without the user",
+			zap.String("entry_id", entry.ID.String()))
+		return
+	}
+
+	rendered, err := RenderNotification(TemplateWaitlistOffer, entry.User.Locale, restaurant.Location(), WaitlistOfferNotificationData{
+		RestaurantName: restaurant.Name,
+		StartTime:      entry.StartTime,
+		EndTime:        entry.EndTime,
+		GuestCount:     entry.GuestsCount,
+		ClaimToken:     token,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		s.log.Warn("failed to render waitlist offer email",
+			zap.String("entry_id", entry.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	if err := s.notificationSvc.SendEmail(entry.User.Email, rendered.Subject, rendered.Body); err != nil {
+		s.log.Warn("failed to send waitlist offer email",
+			zap.String("entry_id", entry.ID.String()),
+			zap.Error(err))
+	}
+}
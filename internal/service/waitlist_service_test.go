@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockWaitlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWaitlistRepository) Create(ctx context.Context, entry *domain.WaitlistEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockWaitlistRepository) GetByClaimToken(ctx context.Context, token string) (*domain.WaitlistEntry, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) ListWaiting(ctx context.Context, restaurantID uuid.UUID, from, to time.Time, minGuests, maxGuests int, now time.Time, limit int) ([]*domain.WaitlistEntry, error) {
+	args := m.Called(ctx, restaurantID, from, to, minGuests, maxGuests, now, limit)
+	return args.Get(0).([]*domain.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) GetLapsedOffers(ctx context.Context, now time.Time, limit int) ([]*domain.WaitlistEntry, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]*domain.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) MarkOffered(ctx context.Context, id, tableID uuid.UUID, token string, expiresAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, tableID, token, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) MarkBooked(ctx context.Context, id, bookingID uuid.UUID, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, bookingID, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) ExpireOffer(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) ReturnToWaiting(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWaitlistRepository) WithTx(tx *gorm.DB) repository.WaitlistRepository {
+	return m
+}
+
+// stubWaitlist records the bookings other services report cancelled.
+type stubWaitlist struct {
+	WaitlistService
+	freed []*domain.Booking
+}
+
+func (s *stubWaitlist) SlotFreed(ctx context.Context, booking *domain.Booking) {
+	s.freed = append(s.freed, booking)
+}
+
+type waitlistMocks struct {
+	waitlistRepo   *MockWaitlistRepository
+	bookingRepo    *BookingMockBookingRepository
+	tableRepo      *MockTableRepository
+	restaurantRepo *BookingMockRestaurantRepository
+	sqlMock        sqlmock.Sqlmock
+	sent           chan Notification
+}
+
+// waitlistNow is a Thursday afternoon, two days before waitlistStart.
+var (
+	waitlistNow   = time.Date(2030, time.May, 30, 12, 0, 0, 0, time.UTC)
+	waitlistStart = time.Date(2030, time.June, 1, 19, 0, 0, 0, time.UTC)
+)
+
+// setupWaitlistService runs background work inline and pins the clock to
+// waitlistNow.
+func setupWaitlistService() (*waitlistService, *waitlistMocks) {
+	mocks := &waitlistMocks{
+		waitlistRepo:   new(MockWaitlistRepository),
+		bookingRepo:    new(BookingMockBookingRepository),
+		tableRepo:      new(MockTableRepository),
+		restaurantRepo: new(BookingMockRestaurantRepository),
+		sent:           make(chan Notification, 10),
+	}
+
+	sqlDB, sqlMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+	mocks.sqlMock = sqlMock
+
+	notifications := newNotificationService(testPoolConfig(1, 1), 10, func(n Notification) error {
+		mocks.sent <- n
+		return nil
+	})
+
+	service := NewWaitlistService(mocks.waitlistRepo, mocks.bookingRepo, mocks.tableRepo, mocks.restaurantRepo,
+		notifications, db, zap.NewNop()).(*waitlistService)
+	service.now = func() time.Time { return waitlistNow }
+	service.launch = func(fn func()) { fn() }
+	return service, mocks
+}
+
+func waitlistRestaurant() *domain.Restaurant {
+	restaurant := availabilityRestaurant()
+	restaurant.Timezone = "UTC"
+	return restaurant
+}
+
+func waitingEntry(restaurantID uuid.UUID, email string) *domain.WaitlistEntry {
+	return &domain.WaitlistEntry{
+		ID:           uuid.New(),
+		RestaurantID: restaurantID,
+		UserID:       uuid.New(),
+		StartTime:    waitlistStart,
+		EndTime:      waitlistStart.Add(2 * time.Hour),
+		GuestsCount:  4,
+		Status:       domain.WaitlistStatusWaiting,
+		User:         &domain.User{Email: email, Locale: domain.LocaleEnglish},
+	}
+}
+
+func offeredEntry(restaurantID, tableID uuid.UUID) *domain.WaitlistEntry {
+	entry := waitingEntry(restaurantID, "waiting@example.com")
+	expiresAt := waitlistNow.Add(5 * time.Minute)
+	entry.Status = domain.WaitlistStatusOffered
+	entry.OfferTableID = &tableID
+	entry.OfferExpiresAt = &expiresAt
+	return entry
+}
+
+func TestJoinWaitlist(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	userID := uuid.New()
+
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByMinCapacity", ctx, restaurant.ID, 4).Return([]*domain.Table{{ID: uuid.New(), MinCapacity: 2, MaxCapacity: 4}}, nil)
+	mocks.waitlistRepo.On("Create", ctx, mock.AnythingOfType("*domain.WaitlistEntry")).Return(nil)
+
+	entry, err := service.Join(ctx, JoinWaitlistRequest{
+		RestaurantID: restaurant.ID,
+		UserID:       userID,
+		StartTime:    waitlistStart,
+		EndTime:      waitlistStart.Add(2 * time.Hour),
+		GuestsCount:  4,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, entry.UserID)
+	assert.Equal(t, domain.WaitlistStatusWaiting, entry.Status)
+	assert.Equal(t, 4, entry.GuestsCount)
+	mocks.waitlistRepo.AssertExpectations(t)
+}
+
+func TestJoinWaitlist_Rejections(t *testing.T) {
+	restaurant := waitlistRestaurant()
+	cases := map[string]struct {
+		start  time.Time
+		end    time.Time
+		tables []*domain.Table
+		want   error
+	}{
+		"ends before it starts": {waitlistStart, waitlistStart.Add(-time.Hour), nil, ErrInvalidBookingPeriod},
+		"in the past":           {waitlistNow.Add(-time.Hour), waitlistNow.Add(time.Hour), nil, ErrPastBooking},
+		"after closing":         {waitlistStart.Add(5 * time.Hour), waitlistStart.Add(7 * time.Hour), nil, ErrOutsideWorkingHours},
+		"party too large":       {waitlistStart, waitlistStart.Add(2 * time.Hour), []*domain.Table{{MinCapacity: 6, MaxCapacity: 8}}, ErrNoFittingTable},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service, mocks := setupWaitlistService()
+			mocks.restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+			mocks.tableRepo.On("GetByMinCapacity", mock.Anything, restaurant.ID, 4).Return(tc.tables, nil)
+
+			_, err := service.Join(context.Background(), JoinWaitlistRequest{
+				RestaurantID: restaurant.ID,
+				UserID:       uuid.New(),
+				StartTime:    tc.start,
+				EndTime:      tc.end,
+				GuestsCount:  4,
+			})
+
+			assert.ErrorIs(t, err, tc.want)
+			mocks.waitlistRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSlotFreed_OffersFirstEntryTheTableIsFreeFor(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	restaurant.Name = "Osteria"
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	cancelled := &domain.Booking{ID: uuid.New(), RestaurantID: restaurant.ID, TableID: table.ID, StartTime: waitlistStart, EndTime: waitlistStart.Add(2 * time.Hour)}
+
+	// The oldest entry wants a later time the table is still booked for.
+	busy := waitingEntry(restaurant.ID, "busy@example.com")
+	busy.StartTime, busy.EndTime = waitlistStart.Add(time.Hour), waitlistStart.Add(3*time.Hour)
+	next := waitingEntry(restaurant.ID, "next@example.com")
+
+	mocks.tableRepo.On("GetByID", mock.Anything, table.ID).Return(table, nil)
+	mocks.restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+	mocks.waitlistRepo.On("ListWaiting", mock.Anything, restaurant.ID, cancelled.StartTime, cancelled.EndTime, 2, 4, waitlistNow, waitlistCandidates).
+		Return([]*domain.WaitlistEntry{busy, next}, nil)
+	mocks.bookingRepo.On("CheckTableAvailability", mock.Anything, table.ID, busy.StartTime, busy.EndTime).Return(false, nil)
+	mocks.bookingRepo.On("CheckTableAvailability", mock.Anything, table.ID, next.StartTime, next.EndTime).Return(true, nil)
+	mocks.waitlistRepo.On("MarkOffered", mock.Anything, next.ID, table.ID, mock.AnythingOfType("string"), waitlistNow.Add(WaitlistOfferTTL)).Return(true, nil)
+
+	service.SlotFreed(ctx, cancelled)
+
+	mocks.waitlistRepo.AssertExpectations(t)
+	token := mocks.waitlistRepo.Calls[1].Arguments.String(3)
+	assert.NotEmpty(t, token)
+
+	notifications := receiveNotifications(t, mocks.sent, 1)
+	assert.Equal(t, "next@example.com", notifications[0].Recipient)
+	assert.Equal(t, "A table is free at Osteria", notifications[0].Subject)
+	assert.Contains(t, notifications[0].Message, "POST /api/waitlist/claim/"+token)
+}
+
+func TestSlotFreed_PastBookingIsIgnored(t *testing.T) {
+	service, mocks := setupWaitlistService()
+
+	service.SlotFreed(context.Background(), &domain.Booking{TableID: uuid.New(), StartTime: waitlistNow.Add(-2 * time.Hour), EndTime: waitlistNow})
+
+	mocks.tableRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	mocks.waitlistRepo.AssertNotCalled(t, "ListWaiting", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimWaitlistOffer_Books(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	entry := offeredEntry(restaurant.ID, table.ID)
+	bookingID := uuid.New()
+
+	mocks.waitlistRepo.On("GetByClaimToken", ctx, "secret").Return(entry, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Booking).ID = bookingID }).
+		Return(true, nil)
+	mocks.waitlistRepo.On("MarkBooked", ctx, entry.ID, bookingID, waitlistNow).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectCommit()
+
+	booking, err := service.Claim(ctx, "secret", entry.UserID)
+
+	require.NoError(t, err)
+	assert.Equal(t, bookingID, booking.ID)
+	assert.Equal(t, table.ID, booking.TableID)
+	assert.Equal(t, &entry.UserID, booking.UserID)
+	assert.Equal(t, entry.StartTime, booking.StartTime)
+	assert.Equal(t, entry.GuestsCount, booking.GuestsCount)
+	assert.Equal(t, domain.BookingStatusPending, booking.Status)
+	assert.NotNil(t, booking.CancellationPolicy)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestClaimWaitlistOffer_TooLatePassesTheTableOn(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	entry := offeredEntry(restaurant.ID, table.ID)
+	lapsedAt := waitlistNow.Add(-time.Minute)
+	entry.OfferExpiresAt = &lapsedAt
+	next := waitingEntry(restaurant.ID, "next@example.com")
+
+	mocks.waitlistRepo.On("GetByClaimToken", ctx, "secret").Return(entry, nil)
+	mocks.waitlistRepo.On("ExpireOffer", ctx, entry.ID).Return(true, nil)
+	mocks.tableRepo.On("GetByID", mock.Anything, table.ID).Return(table, nil)
+	mocks.restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+	mocks.waitlistRepo.On("ListWaiting", mock.Anything, restaurant.ID, entry.StartTime, entry.EndTime, 2, 4, waitlistNow, waitlistCandidates).
+		Return([]*domain.WaitlistEntry{next}, nil)
+	mocks.bookingRepo.On("CheckTableAvailability", mock.Anything, table.ID, next.StartTime, next.EndTime).Return(true, nil)
+	mocks.waitlistRepo.On("MarkOffered", mock.Anything, next.ID, table.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	_, err := service.Claim(ctx, "secret", entry.UserID)
+
+	assert.ErrorIs(t, err, ErrWaitlistOfferExpired)
+	mocks.bookingRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything, mock.Anything)
+	mocks.waitlistRepo.AssertExpectations(t)
+	notifications := receiveNotifications(t, mocks.sent, 1)
+	assert.Equal(t, "next@example.com", notifications[0].Recipient)
+}
+
+func TestClaimWaitlistOffer_TableTakenKeepsPlaceInLine(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	entry := offeredEntry(restaurant.ID, table.ID)
+
+	mocks.waitlistRepo.On("GetByClaimToken", ctx, "secret").Return(entry, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).Return(false, nil)
+	mocks.waitlistRepo.On("ReturnToWaiting", ctx, entry.ID).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+
+	_, err := service.Claim(ctx, "secret", entry.UserID)
+
+	assert.ErrorIs(t, err, ErrTableNotAvailable)
+	mocks.waitlistRepo.AssertNotCalled(t, "MarkBooked", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mocks.waitlistRepo.AssertExpectations(t)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestClaimWaitlistOffer_RestaurantFullKeepsPlaceInLine(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	entry := offeredEntry(restaurant.ID, table.ID)
+
+	mocks.waitlistRepo.On("GetByClaimToken", ctx, "secret").Return(entry, nil)
+	mocks.restaurantRepo.On("GetByID", ctx, restaurant.ID).Return(restaurant, nil)
+	mocks.tableRepo.On("GetByID", ctx, table.ID).Return(table, nil)
+	mocks.bookingRepo.On("CreateIfAvailable", ctx, mock.AnythingOfType("*domain.Booking")).
+		Return(false, fmt.Errorf("%w: 38 of 40 guests already booked for that time", ErrGuestLimitReached))
+	mocks.waitlistRepo.On("ReturnToWaiting", ctx, entry.ID).Return(true, nil)
+	mocks.sqlMock.ExpectBegin()
+	mocks.sqlMock.ExpectRollback()
+
+	_, err := service.Claim(ctx, "secret", entry.UserID)
+
+	assert.ErrorIs(t, err, ErrGuestLimitReached)
+	assert.Contains(t, err.Error(), "38 of 40")
+	mocks.waitlistRepo.AssertExpectations(t)
+	assert.NoError(t, mocks.sqlMock.ExpectationsWereMet())
+}
+
+func TestClaimWaitlistOffer_Rejections(t *testing.T) {
+	restaurantID, tableID := uuid.New(), uuid.New()
+
+	t.Run("unknown token", func(t *testing.T) {
+		service, mocks := setupWaitlistService()
+		mocks.waitlistRepo.On("GetByClaimToken", mock.Anything, "secret").Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.Claim(context.Background(), "secret", uuid.New())
+		assert.ErrorIs(t, err, ErrWaitlistOfferNotFound)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		service, mocks := setupWaitlistService()
+		mocks.waitlistRepo.On("GetByClaimToken", mock.Anything, "secret").Return(offeredEntry(restaurantID, tableID), nil)
+
+		_, err := service.Claim(context.Background(), "secret", uuid.New())
+		assert.ErrorIs(t, err, ErrWaitlistOfferNotFound)
+	})
+
+	t.Run("already claimed", func(t *testing.T) {
+		service, mocks := setupWaitlistService()
+		entry := offeredEntry(restaurantID, tableID)
+		entry.Status = domain.WaitlistStatusBooked
+		mocks.waitlistRepo.On("GetByClaimToken", mock.Anything, "secret").Return(entry, nil)
+
+		_, err := service.Claim(context.Background(), "secret", entry.UserID)
+		assert.ErrorIs(t, err, ErrWaitlistOfferClaimed)
+	})
+
+	t.Run("already expired", func(t *testing.T) {
+		service, mocks := setupWaitlistService()
+		entry := offeredEntry(restaurantID, tableID)
+		entry.Status = domain.WaitlistStatusExpired
+		mocks.waitlistRepo.On("GetByClaimToken", mock.Anything, "secret").Return(entry, nil)
+
+		_, err := service.Claim(context.Background(), "secret", entry.UserID)
+		assert.ErrorIs(t, err, ErrWaitlistOfferExpired)
+		mocks.waitlistRepo.AssertNotCalled(t, "ExpireOffer", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWaitlistRun_ExpiresLapsedOffers(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	ctx := context.Background()
+	restaurant := waitlistRestaurant()
+	table := &domain.Table{ID: uuid.New(), RestaurantID: restaurant.ID, MinCapacity: 2, MaxCapacity: 4, IsActive: true}
+	lapsed, claimed := offeredEntry(restaurant.ID, table.ID), offeredEntry(restaurant.ID, table.ID)
+
+	mocks.waitlistRepo.On("GetLapsedOffers", ctx, waitlistNow, waitlistBatchSize).Return([]*domain.WaitlistEntry{lapsed, claimed}, nil)
+	mocks.waitlistRepo.On("ExpireOffer", ctx, lapsed.ID).Return(true, nil)
+	// Claimed after the batch was loaded.
+	mocks.waitlistRepo.On("ExpireOffer", ctx, claimed.ID).Return(false, nil)
+	mocks.tableRepo.On("GetByID", mock.Anything, table.ID).Return(table, nil)
+	mocks.restaurantRepo.On("GetByID", mock.Anything, restaurant.ID).Return(restaurant, nil)
+	mocks.waitlistRepo.On("ListWaiting", mock.Anything, restaurant.ID, lapsed.StartTime, lapsed.EndTime, 2, 4, waitlistNow, waitlistCandidates).
+		Return([]*domain.WaitlistEntry{}, nil).Once()
+
+	service.Run(ctx, waitlistNow)
+
+	mocks.waitlistRepo.AssertExpectations(t)
+	assert.Empty(t, mocks.sent)
+}
+
+func TestWaitlistRun_LoadFails(t *testing.T) {
+	service, mocks := setupWaitlistService()
+	mocks.waitlistRepo.On("GetLapsedOffers", mock.Anything, waitlistNow, waitlistBatchSize).Return([]*domain.WaitlistEntry(nil), errors.New("connection refused"))
+
+	service.Run(context.Background(), waitlistNow)
+
+	mocks.waitlistRepo.AssertNotCalled(t, "ExpireOffer", mock.Anything, mock.Anything)
+}
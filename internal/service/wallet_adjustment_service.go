@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"restaurant-booking/pkg/logger"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MinAdjustmentReasonLength is the shortest reason, in characters, a wallet
+// adjustment is accepted with.
+const MinAdjustmentReasonLength = 10
+
+var (
+	ErrInvalidAdjustmentDirection = errors.New("direction must be credit or debit")
+	ErrAdjustmentReasonTooShort   = errors.New("reason must be at least 10 characters")
+	ErrWalletAdjustmentNotFound   = errors.New("wallet adjustment not found")
+	ErrWalletAdjustmentDecided    = errors.New("wallet adjustment is no longer pending")
+	ErrWalletAdjustmentExpired    = errors.New("wallet adjustment has expired")
+	ErrSelfApproval               = errors.New("an adjustment must be approved by another admin")
+)
+
+type AdjustWalletRequest struct {
+	Amount    int
+	Direction domain.AdjustmentDirection
+	Reason    string
+}
+
+type WalletAdjustmentService interface {
+	// Adjust applies the adjustment to the user's wallet at once when its
+	// amount is within the approval threshold. Above it the adjustment is
+	// returned pending and the balance is left alone.
+	Adjust(ctx context.Context, adminID, userID uuid.UUID, req AdjustWalletRequest) (*domain.WalletAdjustment, error)
+	// Approve applies a pending adjustment. The admin who requested it
+	// cannot approve it.
+	Approve(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error)
+	// Reject drops a pending adjustment. The requester may withdraw their
+	// own.
+	Reject(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error)
+	ListPending(ctx context.Context, limit, offset int) ([]*domain.WalletAdjustment, error)
+}
+
+type walletAdjustmentService struct {
+	adjustmentRepo    repository.WalletAdjustmentRepository
+	audit             AuditRecorder
+	db                *gorm.DB
+	log               logger.Logger
+	approvalThreshold int
+	approvalTTL       time.Duration
+	now               func() time.Time
+}
+
+// NewWalletAdjustmentService returns a service that asks for a second
+// admin's approval of adjustments above approvalThreshold, which must come
+// within approvalTTL.
+func NewWalletAdjustmentService(
+	adjustmentRepo repository.WalletAdjustmentRepository,
+	audit AuditRecorder,
+	db *gorm.DB,
+	log logger.Logger,
+	approvalThreshold int,
+	approvalTTL time.Duration,
+) WalletAdjustmentService {
+	return &walletAdjustmentService{
+		adjustmentRepo:    adjustmentRepo,
+		audit:             audit,
+		db:                db,
+		log:               log,
+		approvalThreshold: approvalThreshold,
+		approvalTTL:       approvalTTL,
+		now:               time.Now,
+	}
+}
+
+func (s *walletAdjustmentService) Adjust(ctx context.Context, adminID, userID uuid.UUID, req AdjustWalletRequest) (*domain.WalletAdjustment, error) {
+	reason := strings.TrimSpace(req.Reason)
+	switch {
+	case req.Amount <= 0:
+		return nil, ErrInvalidAmount
+	case req.Direction != domain.AdjustmentCredit && req.Direction != domain.AdjustmentDebit:
+		return nil, ErrInvalidAdjustmentDirection
+	case utf8.RuneCountInString(reason) < MinAdjustmentReasonLength:
+		return nil, ErrAdjustmentReasonTooShort
+	}
+
+	now := s.now()
+	adjustment := &domain.WalletAdjustment{
+		UserID:      userID,
+		Amount:      req.Amount,
+		Direction:   req.Direction,
+		Reason:      reason,
+		Status:      domain.WalletAdjustmentPending,
+		RequestedBy: adminID,
+		CreatedAt:   now,
+	}
+
+	if req.Amount > s.approvalThreshold {
+		expiresAt := now.Add(s.approvalTTL)
+		adjustment.ExpiresAt = &expiresAt
+		if err := s.adjustmentRepo.Create(ctx, adjustment); err != nil {
+			return nil, err
+		}
+		s.auditAdjustment(ctx, adminID, AuditActionWalletAdjustRequest, adjustment)
+		return adjustment, nil
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.adjustmentRepo.WithTx(tx).Create(ctx, adjustment); err != nil {
+			return err
+		}
+		return s.apply(ctx, tx, adjustment, adminID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditAdjustment(ctx, adminID, AuditActionWalletAdjustApply, adjustment)
+	return adjustment, nil
+}
+
+func (s *walletAdjustmentService) Approve(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error) {
+	adjustment, err := s.decide(ctx, adminID, id, func(tx *gorm.DB, adjustment *domain.WalletAdjustment, now time.Time) error {
+		if adjustment.RequestedBy == adminID {
+			return ErrSelfApproval
+		}
+		return s.apply(ctx, tx, adjustment, adminID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditAdjustment(ctx, adminID, AuditActionWalletAdjustApply, adjustment)
+	return adjustment, nil
+}
+
+func (s *walletAdjustmentService) Reject(ctx context.Context, adminID, id uuid.UUID) (*domain.WalletAdjustment, error) {
+	adjustment, err := s.decide(ctx, adminID, id, func(tx *gorm.DB, adjustment *domain.WalletAdjustment, now time.Time) error {
+		adjustment.Status = domain.WalletAdjustmentRejected
+		adjustment.DecidedBy = &adminID
+		adjustment.DecidedAt = &now
+		return s.adjustmentRepo.WithTx(tx).Update(ctx, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditAdjustment(ctx, adminID, AuditActionWalletAdjustReject, adjustment)
+	return adjustment, nil
+}
+
+func (s *walletAdjustmentService) ListPending(ctx context.Context, limit, offset int) ([]*domain.WalletAdjustment, error) {
+	// Expiry is recorded lazily, so settle it before listing.
+	if _, err := s.adjustmentRepo.ExpirePending(ctx, s.now()); err != nil {
+		return nil, err
+	}
+	return s.adjustmentRepo.ListPending(ctx, limit, offset)
+}
+
+// decide locks the pending adjustment and runs fn on it in one transaction.
+// An adjustment found past its expiry is marked expired instead.
+func (s *walletAdjustmentService) decide(ctx context.Context, adminID, id uuid.UUID, fn func(tx *gorm.DB, adjustment *domain.WalletAdjustment, now time.Time) error) (*domain.WalletAdjustment, error) {
+	now := s.now()
+	var adjustment *domain.WalletAdjustment
+	expired := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		adjustments := s.adjustmentRepo.WithTx(tx)
+		var err error
+		adjustment, err = adjustments.GetForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletAdjustmentNotFound
+			}
+			return err
+		}
+
+		switch {
+		case adjustment.Status == domain.WalletAdjustmentExpired:
+			return ErrWalletAdjustmentExpired
+		case adjustment.Status != domain.WalletAdjustmentPending:
+			return ErrWalletAdjustmentDecided
+		case adjustment.ExpiresAt != nil && !now.Before(*adjustment.ExpiresAt):
+			// Committed, so the expiry sticks even though the call fails.
+			expired = true
+			adjustment.Status = domain.WalletAdjustmentExpired
+			return adjustments.Update(ctx, adjustment)
+		}
+		return fn(tx, adjustment, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrWalletAdjustmentExpired
+	}
+	return adjustment, nil
+}
+
+// apply changes the wallet balance under the same row lock as the other
+// wallet operations, records the transaction and marks the adjustment
+// applied by adminID.
+func (s *walletAdjustmentService) apply(ctx context.Context, tx *gorm.DB, adjustment *domain.WalletAdjustment, adminID uuid.UUID, now time.Time) error {
+	var wallet domain.Wallet
+	err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", adjustment.UserID).
+		First(&wallet).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if adjustment.Direction == domain.AdjustmentDebit {
+			return ErrInsufficientBalance
+		}
+		wallet = domain.Wallet{UserID: adjustment.UserID}
+		if err := tx.WithContext(ctx).Create(&wallet).Error; err != nil {
+			return err
+		}
+	}
+
+	if wallet.Balance+adjustment.SignedAmount() < 0 {
+		return ErrInsufficientBalance
+	}
+	wallet.Balance += adjustment.SignedAmount()
+	if err := tx.WithContext(ctx).Save(&wallet).Error; err != nil {
+		return err
+	}
+
+	transaction := &domain.WalletTransaction{
+		WalletID:    wallet.ID,
+		Amount:      adjustment.SignedAmount(),
+		Type:        domain.TransactionAdjustment,
+		Description: adjustment.Reason,
+	}
+	if err := tx.WithContext(ctx).Create(transaction).Error; err != nil {
+		return err
+	}
+
+	adjustment.Status = domain.WalletAdjustmentApplied
+	adjustment.TransactionID = &transaction.ID
+	adjustment.DecidedBy = &adminID
+	adjustment.DecidedAt = &now
+	return s.adjustmentRepo.WithTx(tx).Update(ctx, adjustment)
+}
+
+// auditAdjustment is called once the adjustment has committed.
+func (s *walletAdjustmentService) auditAdjustment(ctx context.Context, adminID uuid.UUID, action string, adjustment *domain.WalletAdjustment) {
+	metadata := map[string]interface{}{
+		"user_id":      adjustment.UserID.String(),
+		"amount":       adjustment.Amount,
+		"direction":    string(adjustment.Direction),
+		"reason":       adjustment.Reason,
+		"requested_by": adjustment.RequestedBy.String(),
+	}
+	if adjustment.TransactionID != nil {
+		metadata["transaction_id"] = adjustment.TransactionID.String()
+	}
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: "wallet_adjustment",
+		TargetID:   adjustment.ID,
+		Metadata:   metadata,
+	})
+}
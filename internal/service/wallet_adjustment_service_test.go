@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"restaurant-booking/internal/domain"
+	"restaurant-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type MockWalletAdjustmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletAdjustmentRepository) Create(ctx context.Context, adjustment *domain.WalletAdjustment) error {
+	args := m.Called(ctx, adjustment)
+	return args.Error(0)
+}
+
+func (m *MockWalletAdjustmentRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.WalletAdjustment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WalletAdjustment), args.Error(1)
+}
+
+func (m *MockWalletAdjustmentRepository) Update(ctx context.Context, adjustment *domain.WalletAdjustment) error {
+	args := m.Called(ctx, adjustment)
+	return args.Error(0)
+}
+
+func (m *MockWalletAdjustmentRepository) ListPending(ctx context.Context, limit, offset int) ([]*domain.WalletAdjustment, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]*domain.WalletAdjustment), args.Error(1)
+}
+
+func (m *MockWalletAdjustmentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWalletAdjustmentRepository) WithTx(tx *gorm.DB) repository.WalletAdjustmentRepository {
+	return m
+}
+
+const testAdjustmentThreshold = 10000
+
+var adjustmentNow = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func setupWalletAdjustmentService() (*walletAdjustmentService, *MockWalletAdjustmentRepository, *MockAuditRecorder, sqlmock.Sqlmock) {
+	repo := new(MockWalletAdjustmentRepository)
+	audit := new(MockAuditRecorder)
+	sqlDB, dbMock, _ := sqlmock.New()
+	db, _ := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, DriverName: "postgres"}), &gorm.Config{})
+
+	svc := NewWalletAdjustmentService(repo, audit, db, zap.NewNop(), testAdjustmentThreshold, 24*time.Hour).(*walletAdjustmentService)
+	svc.now = func() time.Time { return adjustmentNow }
+	return svc, repo, audit, dbMock
+}
+
+// expectWalletChange expects the locked read of the user's wallet, holding
+// balance, and the writes of an applied adjustment.
+func expectWalletChange(dbMock sqlmock.Sqlmock, userID uuid.UUID, balance int) {
+	dbMock.ExpectQuery(`SELECT .* FROM "wallets" WHERE user_id = .* FOR UPDATE`).
+		WithArgs(userID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(uuid.New(), userID, balance))
+	dbMock.ExpectExec(`UPDATE "wallets"`).WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectQuery(`INSERT INTO "wallet_transactions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+}
+
+func pendingAdjustment(requestedBy uuid.UUID, direction domain.AdjustmentDirection) *domain.WalletAdjustment {
+	expiresAt := adjustmentNow.Add(time.Hour)
+	return &domain.WalletAdjustment{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Amount:      25000,
+		Direction:   direction,
+		Reason:      "Goodwill for the cancelled event",
+		Status:      domain.WalletAdjustmentPending,
+		RequestedBy: requestedBy,
+		ExpiresAt:   &expiresAt,
+	}
+}
+
+func auditAction(action string) interface{} {
+	return mock.MatchedBy(func(entry AuditEntry) bool { return entry.Action == action })
+}
+
+func TestAdjustWallet_WithinThresholdAppliesAtOnce(t *testing.T) {
+	svc, repo, audit, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	adminID, userID := uuid.New(), uuid.New()
+
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.WalletAdjustment")).Return(nil)
+	repo.On("Update", ctx, mock.AnythingOfType("*domain.WalletAdjustment")).Return(nil)
+	audit.On("Record", ctx, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionWalletAdjustApply &&
+			entry.ActorID == adminID &&
+			entry.TargetType == "wallet_adjustment" &&
+			entry.Metadata["reason"] == "Goodwill credit"
+	})).Return(nil).Once()
+	dbMock.ExpectBegin()
+	expectWalletChange(dbMock, userID, 100)
+	dbMock.ExpectCommit()
+
+	adjustment, err := svc.Adjust(ctx, adminID, userID, AdjustWalletRequest{
+		Amount: testAdjustmentThreshold, Direction: domain.AdjustmentCredit, Reason: "  Goodwill credit  ",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletAdjustmentApplied, adjustment.Status)
+	assert.Equal(t, "Goodwill credit", adjustment.Reason)
+	assert.NotNil(t, adjustment.TransactionID)
+	assert.Equal(t, &adminID, adjustment.DecidedBy)
+	assert.Nil(t, adjustment.ExpiresAt)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	audit.AssertExpectations(t)
+}
+
+func TestAdjustWallet_AboveThresholdWaitsForApproval(t *testing.T) {
+	svc, repo, audit, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.WalletAdjustment")).Return(nil)
+	audit.On("Record", ctx, auditAction(AuditActionWalletAdjustRequest)).Return(nil).Once()
+
+	adjustment, err := svc.Adjust(ctx, uuid.New(), uuid.New(), AdjustWalletRequest{
+		Amount: testAdjustmentThreshold + 1, Direction: domain.AdjustmentDebit, Reason: "Duplicate refund reversal",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletAdjustmentPending, adjustment.Status)
+	require.NotNil(t, adjustment.ExpiresAt)
+	assert.Equal(t, adjustmentNow.Add(24*time.Hour), *adjustment.ExpiresAt)
+	assert.Nil(t, adjustment.TransactionID)
+	// The balance is not touched until approval.
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestAdjustWallet_InvalidInput(t *testing.T) {
+	cases := map[string]struct {
+		req  AdjustWalletRequest
+		want error
+	}{
+		"zero amount":       {AdjustWalletRequest{Amount: 0, Direction: domain.AdjustmentCredit, Reason: "Goodwill credit"}, ErrInvalidAmount},
+		"unknown direction": {AdjustWalletRequest{Amount: 10, Direction: "sideways", Reason: "Goodwill credit"}, ErrInvalidAdjustmentDirection},
+		"short reason":      {AdjustWalletRequest{Amount: 10, Direction: domain.AdjustmentCredit, Reason: "goodwill"}, ErrAdjustmentReasonTooShort},
+		"padded reason":     {AdjustWalletRequest{Amount: 10, Direction: domain.AdjustmentCredit, Reason: "  goodwill   "}, ErrAdjustmentReasonTooShort},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, repo, _, _ := setupWalletAdjustmentService()
+
+			_, err := svc.Adjust(context.Background(), uuid.New(), uuid.New(), tc.req)
+
+			assert.ErrorIs(t, err, tc.want)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAdjustWallet_ReasonLengthCountsCharacters(t *testing.T) {
+	svc, repo, audit, _ := setupWalletAdjustmentService()
+	ctx := context.Background()
+
+	repo.On("Create", ctx, mock.AnythingOfType("*domain.WalletAdjustment")).Return(nil)
+	audit.On("Record", ctx, auditAction(AuditActionWalletAdjustRequest)).Return(nil)
+
+	// Ten Cyrillic letters are twenty bytes.
+	_, err := svc.Adjust(ctx, uuid.New(), uuid.New(), AdjustWalletRequest{
+		Amount: testAdjustmentThreshold + 1, Direction: domain.AdjustmentCredit, Reason: "компенсаци",
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestApproveAdjustment_SecondAdminApplies(t *testing.T) {
+	svc, repo, audit, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	requester, approver := uuid.New(), uuid.New()
+	adjustment := pendingAdjustment(requester, domain.AdjustmentDebit)
+
+	repo.On("GetForUpdate", ctx, adjustment.ID).Return(adjustment, nil)
+	repo.On("Update", ctx, adjustment).Return(nil)
+	audit.On("Record", ctx, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionWalletAdjustApply &&
+			entry.ActorID == approver &&
+			entry.Metadata["requested_by"] == requester.String()
+	})).Return(nil).Once()
+	dbMock.ExpectBegin()
+	expectWalletChange(dbMock, adjustment.UserID, 30000)
+	dbMock.ExpectCommit()
+
+	approved, err := svc.Approve(ctx, approver, adjustment.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletAdjustmentApplied, approved.Status)
+	assert.Equal(t, &approver, approved.DecidedBy)
+	assert.Equal(t, adjustmentNow, *approved.DecidedAt)
+	assert.NotNil(t, approved.TransactionID)
+	assert.Equal(t, -25000, approved.SignedAmount())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	audit.AssertExpectations(t)
+}
+
+func TestApproveAdjustment_SelfApprovalRejected(t *testing.T) {
+	svc, repo, audit, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	requester := uuid.New()
+	adjustment := pendingAdjustment(requester, domain.AdjustmentCredit)
+
+	repo.On("GetForUpdate", ctx, adjustment.ID).Return(adjustment, nil)
+	dbMock.ExpectBegin()
+	dbMock.ExpectRollback()
+
+	_, err := svc.Approve(ctx, requester, adjustment.ID)
+
+	assert.ErrorIs(t, err, ErrSelfApproval)
+	assert.Equal(t, domain.WalletAdjustmentPending, adjustment.Status)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+	// The wallet is never read, let alone changed.
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestApproveAdjustment_InsufficientBalanceStaysPending(t *testing.T) {
+	svc, repo, _, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	adjustment := pendingAdjustment(uuid.New(), domain.AdjustmentDebit)
+
+	repo.On("GetForUpdate", ctx, adjustment.ID).Return(adjustment, nil)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT .* FROM "wallets" WHERE user_id = .* FOR UPDATE`).
+		WithArgs(adjustment.UserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(uuid.New(), adjustment.UserID, 24999))
+	dbMock.ExpectRollback()
+
+	_, err := svc.Approve(ctx, uuid.New(), adjustment.ID)
+
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestApproveAdjustment_Expired(t *testing.T) {
+	svc, repo, _, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	adjustment := pendingAdjustment(uuid.New(), domain.AdjustmentCredit)
+	*adjustment.ExpiresAt = adjustmentNow
+
+	repo.On("GetForUpdate", ctx, adjustment.ID).Return(adjustment, nil)
+	repo.On("Update", ctx, adjustment).Return(nil)
+	dbMock.ExpectBegin()
+	dbMock.ExpectCommit()
+
+	_, err := svc.Approve(ctx, uuid.New(), adjustment.ID)
+
+	assert.ErrorIs(t, err, ErrWalletAdjustmentExpired)
+	assert.Equal(t, domain.WalletAdjustmentExpired, adjustment.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestApproveAdjustment_AlreadyDecided(t *testing.T) {
+	for _, status := range []domain.WalletAdjustmentStatus{domain.WalletAdjustmentApplied, domain.WalletAdjustmentRejected} {
+		t.Run(string(status), func(t *testing.T) {
+			svc, repo, _, dbMock := setupWalletAdjustmentService()
+			adjustment := pendingAdjustment(uuid.New(), domain.AdjustmentCredit)
+			adjustment.Status = status
+
+			repo.On("GetForUpdate", mock.Anything, adjustment.ID).Return(adjustment, nil)
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+
+			_, err := svc.Approve(context.Background(), uuid.New(), adjustment.ID)
+
+			assert.ErrorIs(t, err, ErrWalletAdjustmentDecided)
+		})
+	}
+}
+
+func TestApproveAdjustment_NotFound(t *testing.T) {
+	svc, repo, _, dbMock := setupWalletAdjustmentService()
+	id := uuid.New()
+
+	repo.On("GetForUpdate", mock.Anything, id).Return(nil, gorm.ErrRecordNotFound)
+	dbMock.ExpectBegin()
+	dbMock.ExpectRollback()
+
+	_, err := svc.Approve(context.Background(), uuid.New(), id)
+
+	assert.ErrorIs(t, err, ErrWalletAdjustmentNotFound)
+}
+
+func TestRejectAdjustment_RequesterMayWithdraw(t *testing.T) {
+	svc, repo, audit, dbMock := setupWalletAdjustmentService()
+	ctx := context.Background()
+	requester := uuid.New()
+	adjustment := pendingAdjustment(requester, domain.AdjustmentCredit)
+
+	repo.On("GetForUpdate", ctx, adjustment.ID).Return(adjustment, nil)
+	repo.On("Update", ctx, adjustment).Return(nil)
+	audit.On("Record", ctx, auditAction(AuditActionWalletAdjustReject)).Return(nil).Once()
+	dbMock.ExpectBegin()
+	dbMock.ExpectCommit()
+
+	rejected, err := svc.Reject(ctx, requester, adjustment.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletAdjustmentRejected, rejected.Status)
+	assert.Equal(t, &requester, rejected.DecidedBy)
+	assert.Nil(t, rejected.TransactionID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestListPendingAdjustments_ExpiresFirst(t *testing.T) {
+	svc, repo, _, _ := setupWalletAdjustmentService()
+	ctx := context.Background()
+	pending := []*domain.WalletAdjustment{pendingAdjustment(uuid.New(), domain.AdjustmentCredit)}
+
+	repo.On("ExpirePending", ctx, adjustmentNow).Return(int64(2), nil).Once()
+	repo.On("ListPending", ctx, 20, 0).Return(pending, nil)
+
+	listed, err := svc.ListPending(ctx, 20, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, pending, listed)
+	repo.AssertExpectations(t)
+}
@@ -26,22 +26,36 @@ type WalletService interface {
 	ChargeForBooking(ctx context.Context, userID uuid.UUID, amount int, bookingID uuid.UUID) error
 	RefundBooking(ctx context.Context, userID uuid.UUID, amount int, bookingID uuid.UUID, reason string) error
 	GetTransactions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.WalletTransaction, error)
+	// WithTx returns a WalletService whose balance changes run inside tx,
+	// so they commit or roll back with the caller's other writes.
+	WithTx(tx *gorm.DB) WalletService
 }
 
 type walletService struct {
 	walletRepo repository.WalletRepository
+	audit      AuditRecorder
 	db         *gorm.DB
 	log        logger.Logger
 }
 
-func NewWalletService(walletRepo repository.WalletRepository, db *gorm.DB, log logger.Logger) WalletService {
+func NewWalletService(walletRepo repository.WalletRepository, audit AuditRecorder, db *gorm.DB, log logger.Logger) WalletService {
 	return &walletService{
 		walletRepo: walletRepo,
+		audit:      audit,
 		db:         db,
 		log:        log,
 	}
 }
 
+func (s *walletService) WithTx(tx *gorm.DB) WalletService {
+	return &walletService{
+		walletRepo: s.walletRepo,
+		audit:      s.audit,
+		db:         tx,
+		log:        s.log,
+	}
+}
+
 func (s *walletService) GetOrCreateWallet(ctx context.Context, userID uuid.UUID) (*domain.Wallet, error) {
 	wallet, err := s.walletRepo.GetByUserID(ctx, userID)
 	if err == nil {
@@ -80,7 +94,8 @@ func (s *walletService) Deposit(ctx context.Context, userID uuid.UUID, amount in
 		return ErrInvalidAmount
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var transaction *domain.WalletTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var wallet domain.Wallet
 		err := tx.WithContext(ctx).
 			Clauses(clause.Locking{Strength: "UPDATE"}).
@@ -107,7 +122,7 @@ func (s *walletService) Deposit(ctx context.Context, userID uuid.UUID, amount in
 			return err
 		}
 
-		transaction := &domain.WalletTransaction{
+		transaction = &domain.WalletTransaction{
 			WalletID:    wallet.ID,
 			Amount:      amount,
 			Type:        domain.TransactionDeposit,
@@ -116,6 +131,12 @@ func (s *walletService) Deposit(ctx context.Context, userID uuid.UUID, amount in
 
 		return tx.WithContext(ctx).Create(transaction).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	s.auditTransaction(ctx, userID, AuditActionDeposit, transaction)
+	return nil
 }
 
 func (s *walletService) Withdraw(ctx context.Context, userID uuid.UUID, amount int, description string) error {
@@ -123,7 +144,8 @@ func (s *walletService) Withdraw(ctx context.Context, userID uuid.UUID, amount i
 		return ErrInvalidAmount
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var transaction *domain.WalletTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var wallet domain.Wallet
 		err := tx.WithContext(ctx).
 			Clauses(clause.Locking{Strength: "UPDATE"}).
@@ -143,7 +165,7 @@ func (s *walletService) Withdraw(ctx context.Context, userID uuid.UUID, amount i
 			return err
 		}
 
-		transaction := &domain.WalletTransaction{
+		transaction = &domain.WalletTransaction{
 			WalletID:    wallet.ID,
 			Amount:      amount,
 			Type:        domain.TransactionWithdraw,
@@ -152,6 +174,12 @@ func (s *walletService) Withdraw(ctx context.Context, userID uuid.UUID, amount i
 
 		return tx.WithContext(ctx).Create(transaction).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	s.auditTransaction(ctx, userID, AuditActionWithdraw, transaction)
+	return nil
 }
 
 func (s *walletService) ChargeForBooking(ctx context.Context, userID uuid.UUID, amount int, bookingID uuid.UUID) error {
@@ -196,7 +224,8 @@ func (s *walletService) RefundBooking(ctx context.Context, userID uuid.UUID, amo
 		return ErrInvalidAmount
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var transaction *domain.WalletTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var wallet domain.Wallet
 		err := tx.WithContext(ctx).
 			Clauses(clause.Locking{Strength: "UPDATE"}).
@@ -222,7 +251,7 @@ func (s *walletService) RefundBooking(ctx context.Context, userID uuid.UUID, amo
 			return err
 		}
 
-		transaction := &domain.WalletTransaction{
+		transaction = &domain.WalletTransaction{
 			WalletID:    wallet.ID,
 			Amount:      amount,
 			Type:        domain.TransactionRefund,
@@ -232,6 +261,12 @@ func (s *walletService) RefundBooking(ctx context.Context, userID uuid.UUID, amo
 
 		return tx.WithContext(ctx).Create(transaction).Error
 	})
+	if err != nil {
+		return err
+	}
+
+	s.auditTransaction(ctx, userID, AuditActionWalletRefund, transaction)
+	return nil
 }
 
 func (s *walletService) GetTransactions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domain.WalletTransaction, error) {
@@ -242,3 +277,21 @@ func (s *walletService) GetTransactions(ctx context.Context, userID uuid.UUID, l
 
 	return s.walletRepo.GetTransactions(ctx, wallet.ID, limit, offset)
 }
+
+// auditTransaction is called once the transaction has committed.
+func (s *walletService) auditTransaction(ctx context.Context, userID uuid.UUID, action string, transaction *domain.WalletTransaction) {
+	metadata := map[string]interface{}{"amount": transaction.Amount}
+	if transaction.BookingID != nil {
+		metadata["booking_id"] = transaction.BookingID.String()
+	}
+	if transaction.Description != "" {
+		metadata["description"] = transaction.Description
+	}
+	recordAudit(ctx, s.audit, s.log, AuditEntry{
+		ActorID:    userID,
+		Action:     action,
+		TargetType: "wallet_transaction",
+		TargetID:   transaction.ID,
+		Metadata:   metadata,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -69,7 +70,9 @@ func setupWalletService() (*walletService, *MockWalletRepository, sqlmock.Sqlmoc
 
 	service := &walletService{
 		walletRepo: repo,
+		audit:      NewLogAuditRecorder(zap.NewNop()),
 		db:         db,
+		log:        zap.NewNop(),
 	}
 
 	return service, repo, dbMock
@@ -126,10 +129,20 @@ func TestDeposit_Success(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	dbMock.ExpectCommit()
 
+	audit := new(MockAuditRecorder)
+	service.audit = audit
+	audit.On("Record", ctx, mock.MatchedBy(func(entry AuditEntry) bool {
+		return entry.Action == AuditActionDeposit &&
+			entry.ActorID == userID &&
+			entry.TargetType == "wallet_transaction" &&
+			entry.Metadata["amount"] == 500
+	})).Return(nil).Once()
+
 	err := service.Deposit(ctx, userID, 500, "Deposit")
 
 	assert.NoError(t, err)
 	assert.NoError(t, dbMock.ExpectationsWereMet())
+	audit.AssertExpectations(t)
 }
 
 func TestWithdraw_InsufficientBalance(t *testing.T) {
@@ -145,10 +158,14 @@ func TestWithdraw_InsufficientBalance(t *testing.T) {
 
 	dbMock.ExpectRollback()
 
+	audit := new(MockAuditRecorder)
+	service.audit = audit
+
 	err := service.Withdraw(ctx, userID, 500, "Withdraw")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrInsufficientBalance, err)
+	audit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
 	assert.NoError(t, dbMock.ExpectationsWereMet())
 }
 
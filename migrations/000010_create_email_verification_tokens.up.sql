@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE email_verification_tokens (
+                                           id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                           user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                           token VARCHAR(255) UNIQUE NOT NULL,
+                                           expires_at TIMESTAMP NOT NULL,
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
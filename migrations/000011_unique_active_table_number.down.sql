@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tables_restaurant_table_number_active;
+
+ALTER TABLE tables ADD CONSTRAINT unique_restaurant_table_number UNIQUE(restaurant_id, table_number);
@@ -0,0 +1,3 @@
+ALTER TABLE tables DROP CONSTRAINT IF EXISTS unique_restaurant_table_number;
+
+CREATE UNIQUE INDEX idx_tables_restaurant_table_number_active ON tables(restaurant_id, table_number) WHERE is_active = true;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_users_phone_unique;
+
+ALTER TABLE users ADD CONSTRAINT users_phone_key UNIQUE(phone);
+
+DROP INDEX IF EXISTS idx_users_google_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
+ALTER TABLE users DROP COLUMN IF EXISTS auth_provider;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN auth_provider VARCHAR(20) NOT NULL DEFAULT 'password';
+ALTER TABLE users ADD COLUMN google_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_users_google_id ON users(google_id);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_phone;
+
+CREATE UNIQUE INDEX idx_users_phone_unique ON users(phone) WHERE phone <> '';
@@ -0,0 +1,11 @@
+CREATE TABLE restaurant_config_versions (
+                                            id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                            restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+                                            version INTEGER NOT NULL,
+                                            actor_id UUID NOT NULL REFERENCES users(id),
+                                            snapshot JSONB NOT NULL,
+                                            changes TEXT,
+                                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_restaurant_config_versions_version ON restaurant_config_versions(restaurant_id, version);
@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ADD COLUMN replaced_by UUID;
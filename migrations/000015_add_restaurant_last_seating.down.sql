@@ -0,0 +1 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS last_seating_offset_minutes;
@@ -0,0 +1 @@
+ALTER TABLE restaurants ADD COLUMN last_seating_offset_minutes INTEGER NOT NULL DEFAULT 60;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_secret;
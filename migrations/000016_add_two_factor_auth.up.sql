@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN two_factor_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN two_factor_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE two_factor_recovery_codes (
+                                           id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                           user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                           code_hash VARCHAR(255) NOT NULL,
+                                           used_at TIMESTAMP,
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes(user_id);
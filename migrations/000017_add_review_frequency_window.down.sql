@@ -0,0 +1,2 @@
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS excl_reviews_user_restaurant_window;
+ALTER TABLE reviews DROP COLUMN IF EXISTS window_ends_at;
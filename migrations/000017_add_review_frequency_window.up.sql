@@ -0,0 +1,20 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE reviews ADD COLUMN window_ends_at TIMESTAMPTZ;
+UPDATE reviews SET window_ends_at = created_at + INTERVAL '30 days';
+ALTER TABLE reviews ALTER COLUMN window_ends_at SET NOT NULL;
+
+-- Reviews written before the limit may be less than 30 days apart. Each
+-- window ends where the user's next review of the restaurant starts, so
+-- the history stays and no two windows overlap.
+UPDATE reviews SET window_ends_at = later.created_at
+FROM (
+    SELECT id, LEAD(created_at) OVER (PARTITION BY user_id, restaurant_id ORDER BY created_at, id) AS created_at
+    FROM reviews
+) later
+WHERE reviews.id = later.id AND later.created_at < reviews.window_ends_at;
+
+-- A rolling window cannot be a partial index predicate (now() is not
+-- immutable), so overlapping windows are excluded instead.
+ALTER TABLE reviews ADD CONSTRAINT excl_reviews_user_restaurant_window
+    EXCLUDE USING gist (user_id WITH =, restaurant_id WITH =, tstzrange(created_at, window_ends_at) WITH &&);
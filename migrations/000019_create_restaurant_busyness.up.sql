@@ -0,0 +1,6 @@
+CREATE TABLE restaurant_busyness (
+    restaurant_id UUID PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    occupancy JSONB NOT NULL,
+    booking_count INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL
+);
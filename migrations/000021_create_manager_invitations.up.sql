@@ -0,0 +1,17 @@
+CREATE TABLE manager_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    token VARCHAR(255) NOT NULL UNIQUE,
+    invited_by UUID NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_manager_invitations_restaurant_id ON manager_invitations(restaurant_id);
+CREATE UNIQUE INDEX idx_manager_invitations_pending_email
+    ON manager_invitations(restaurant_id, email)
+    WHERE status = 'pending';
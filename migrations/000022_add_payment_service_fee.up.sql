@@ -0,0 +1 @@
+ALTER TABLE payments ADD COLUMN service_fee_amount INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+CREATE TABLE rebooking_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    guests_count INTEGER NOT NULL,
+    options JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    accepted_booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_rebooking_offers_user_id ON rebooking_offers(user_id);
+CREATE INDEX idx_rebooking_offers_created_at ON rebooking_offers(created_at);
@@ -0,0 +1,15 @@
+CREATE TABLE request_samples (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    error TEXT,
+    body JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_request_samples_created_at ON request_samples(created_at);
+CREATE INDEX idx_request_samples_user_created ON request_samples(user_id, created_at);
+CREATE INDEX idx_request_samples_route_created ON request_samples(route, created_at);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN staff_pin_hash VARCHAR(255);
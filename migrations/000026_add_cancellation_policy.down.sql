@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS policy_version;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_policy;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS cancellation_policy;
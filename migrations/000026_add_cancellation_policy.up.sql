@@ -0,0 +1,4 @@
+ALTER TABLE restaurants ADD COLUMN cancellation_policy JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE bookings ADD COLUMN cancellation_policy JSONB;
+ALTER TABLE bookings ADD COLUMN policy_version VARCHAR(32);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_restaurant_images_main;
+ALTER TABLE restaurant_images DROP COLUMN IF EXISTS position;
@@ -0,0 +1,22 @@
+ALTER TABLE restaurant_images ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE restaurant_images
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY restaurant_id ORDER BY created_at, id) - 1 AS position
+    FROM restaurant_images
+) AS ordered
+WHERE restaurant_images.id = ordered.id;
+
+-- Restaurants with several main images keep the newest one.
+UPDATE restaurant_images
+SET is_main = false
+WHERE is_main
+  AND id NOT IN (
+    SELECT DISTINCT ON (restaurant_id) id
+    FROM restaurant_images
+    WHERE is_main
+    ORDER BY restaurant_id, created_at DESC
+  );
+
+CREATE UNIQUE INDEX idx_restaurant_images_main ON restaurant_images(restaurant_id) WHERE is_main;
@@ -0,0 +1,4 @@
+ALTER TABLE tables DROP COLUMN IF EXISTS slot_granularity_minutes;
+ALTER TABLE tables DROP COLUMN IF EXISTS min_duration_minutes;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS booking_rules;
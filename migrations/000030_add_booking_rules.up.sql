@@ -0,0 +1,4 @@
+ALTER TABLE restaurants ADD COLUMN booking_rules JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE tables ADD COLUMN min_duration_minutes INTEGER;
+ALTER TABLE tables ADD COLUMN slot_granularity_minutes INTEGER;
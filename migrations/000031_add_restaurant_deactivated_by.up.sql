@@ -0,0 +1 @@
+ALTER TABLE restaurants ADD COLUMN deactivated_by UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE restaurants ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Almaty';
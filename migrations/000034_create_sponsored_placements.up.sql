@@ -0,0 +1,20 @@
+CREATE TABLE sponsored_placements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    city VARCHAR(100),
+    cuisine_type cuisine_type,
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    budget INTEGER NOT NULL CHECK (budget > 0),
+    cost_per_click INTEGER NOT NULL CHECK (cost_per_click > 0),
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX idx_sponsored_placements_restaurant_id ON sponsored_placements(restaurant_id);
+CREATE INDEX idx_sponsored_placements_window ON sponsored_placements(starts_at, ends_at);
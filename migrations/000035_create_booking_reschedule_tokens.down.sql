@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS booking_reschedule_tokens;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS reschedule_count;
@@ -0,0 +1,3 @@
+-- Postgres cannot drop an enum value. Transactions of type 'adjustment' are
+-- left in place; the value stays in transaction_type.
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Kept apart from the table below: older Postgres versions refuse
+-- ADD VALUE inside a transaction.
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'adjustment' AFTER 'payment_to_restaurant';
@@ -0,0 +1,17 @@
+CREATE TABLE wallet_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('credit', 'debit')),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID NOT NULL REFERENCES users(id),
+    decided_by UUID REFERENCES users(id),
+    decided_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    transaction_id UUID REFERENCES wallet_transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_adjustments_user_id ON wallet_adjustments(user_id);
+CREATE INDEX idx_wallet_adjustments_status ON wallet_adjustments(status);
@@ -0,0 +1,18 @@
+CREATE TABLE background_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    cursor TEXT NOT NULL DEFAULT '',
+    processed BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_background_jobs_kind ON background_jobs(kind);
+CREATE INDEX idx_background_jobs_status ON background_jobs(status);
+-- Only one job of a kind runs at a time.
+CREATE UNIQUE INDEX idx_background_jobs_running_kind ON background_jobs(kind) WHERE status = 'running';
@@ -0,0 +1,11 @@
+CREATE TABLE restaurant_ownership_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    previous_owner_id UUID NOT NULL REFERENCES users(id),
+    new_owner_id UUID NOT NULL REFERENCES users(id),
+    transferred_by UUID NOT NULL REFERENCES users(id),
+    previous_owner_kept BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_restaurant_ownership_transfers_restaurant_id ON restaurant_ownership_transfers(restaurant_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_restaurants_status;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS status;
@@ -0,0 +1,9 @@
+-- Restaurants that already exist are live, so they start out approved; new
+-- ones wait for an admin.
+ALTER TABLE restaurants ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'approved';
+ALTER TABLE restaurants ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE restaurants ADD COLUMN rejection_reason TEXT;
+ALTER TABLE restaurants ADD COLUMN reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE restaurants ADD COLUMN reviewed_at TIMESTAMP;
+
+CREATE INDEX idx_restaurants_status ON restaurants(status);
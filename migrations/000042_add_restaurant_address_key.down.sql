@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_restaurants_address_key;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS address_key;
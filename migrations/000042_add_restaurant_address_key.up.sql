@@ -0,0 +1,7 @@
+ALTER TABLE restaurants ADD COLUMN address_key TEXT NOT NULL DEFAULT '';
+
+-- The service computes the key in Go; this approximates it for existing rows.
+UPDATE restaurants
+SET address_key = btrim(regexp_replace(replace(lower(address), 'ё', 'е'), '[^[:alnum:]]+', ' ', 'g'));
+
+CREATE INDEX idx_restaurants_address_key ON restaurants(address_key);
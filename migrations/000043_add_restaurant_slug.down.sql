@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_restaurants_slug;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,5 @@
+-- Existing restaurants get a slug from the assign_restaurant_slugs job
+-- (POST /api/admin/maintenance/assign-slugs), which transliterates names.
+ALTER TABLE restaurants ADD COLUMN slug VARCHAR(80);
+
+CREATE UNIQUE INDEX idx_restaurants_slug ON restaurants(slug);
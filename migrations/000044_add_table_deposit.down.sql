@@ -0,0 +1,2 @@
+ALTER TABLE tables DROP COLUMN IF EXISTS min_spend;
+ALTER TABLE tables DROP COLUMN IF EXISTS deposit_amount;
@@ -0,0 +1,3 @@
+-- Amounts are in KZT, like payments; 0 means the table has none.
+ALTER TABLE tables ADD COLUMN deposit_amount INTEGER NOT NULL DEFAULT 0 CHECK (deposit_amount >= 0);
+ALTER TABLE tables ADD COLUMN min_spend INTEGER NOT NULL DEFAULT 0 CHECK (min_spend >= 0);
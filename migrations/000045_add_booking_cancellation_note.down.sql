@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookings_pending_created_at;
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_note;
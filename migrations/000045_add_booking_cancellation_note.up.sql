@@ -0,0 +1,3 @@
+ALTER TABLE bookings ADD COLUMN cancellation_note TEXT;
+-- The pending expiry job looks for old pending bookings every minute.
+CREATE INDEX idx_bookings_pending_created_at ON bookings (created_at) WHERE status = 'pending';
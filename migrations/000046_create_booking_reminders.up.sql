@@ -0,0 +1,8 @@
+CREATE TABLE booking_reminders (
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    lead_minutes INTEGER NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (booking_id, lead_minutes)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_status_start_time ON bookings(status, start_time);
@@ -0,0 +1,3 @@
+-- Postgres cannot drop an enum value. Payments marked 'voided' are left in
+-- place; the value stays in payment_status.
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Kept in its own migration: older Postgres versions refuse ADD VALUE inside
+-- a transaction.
+ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'voided' AFTER 'refunded';
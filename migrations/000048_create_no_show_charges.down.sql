@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookings_user_no_show;
+DROP TABLE IF EXISTS no_show_charges;
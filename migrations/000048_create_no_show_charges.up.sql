@@ -0,0 +1,14 @@
+CREATE TABLE no_show_charges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_no_show_charges_booking_id ON no_show_charges (booking_id);
+CREATE INDEX idx_no_show_charges_user_id ON no_show_charges (user_id);
+-- No-shows are counted per customer.
+CREATE INDEX idx_bookings_user_no_show ON bookings (user_id) WHERE status = 'no_show';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_bookings_guest_phone;
+DELETE FROM bookings WHERE user_id IS NULL;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS chk_bookings_user_or_guest;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_phone;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_name;
+ALTER TABLE bookings DROP COLUMN IF EXISTS is_guest;
+ALTER TABLE bookings ALTER COLUMN user_id SET NOT NULL;
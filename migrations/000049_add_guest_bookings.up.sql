@@ -0,0 +1,9 @@
+-- Staff record phone reservations for guests without an account.
+ALTER TABLE bookings ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE bookings ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE bookings ADD COLUMN guest_name VARCHAR(100);
+ALTER TABLE bookings ADD COLUMN guest_phone VARCHAR(20);
+ALTER TABLE bookings ADD CONSTRAINT chk_bookings_user_or_guest CHECK (user_id IS NOT NULL OR is_guest);
+
+-- Guest bookings are claimed by the phone number they were made for.
+CREATE INDEX idx_bookings_guest_phone ON bookings (guest_phone) WHERE is_guest;
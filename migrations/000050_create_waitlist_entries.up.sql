@@ -0,0 +1,23 @@
+CREATE TABLE waitlist_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    guests_count INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    offer_table_id UUID REFERENCES tables(id) ON DELETE SET NULL,
+    claim_token TEXT,
+    offer_expires_at TIMESTAMP,
+    booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_waitlist_entries_period CHECK (end_time > start_time)
+);
+
+CREATE UNIQUE INDEX idx_waitlist_entries_claim_token ON waitlist_entries (claim_token);
+CREATE INDEX idx_waitlist_entries_user_id ON waitlist_entries (user_id);
+-- A freed table goes to the oldest waiting entry of its restaurant.
+CREATE INDEX idx_waitlist_entries_waiting ON waitlist_entries (restaurant_id, created_at) WHERE status = 'waiting';
+-- The waitlist job looks for offers that have lapsed.
+CREATE INDEX idx_waitlist_entries_offered ON waitlist_entries (offer_expires_at) WHERE status = 'offered';
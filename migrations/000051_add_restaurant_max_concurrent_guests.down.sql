@@ -0,0 +1 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS max_concurrent_guests;
@@ -0,0 +1 @@
+ALTER TABLE restaurants ADD COLUMN max_concurrent_guests INTEGER NOT NULL DEFAULT 0 CHECK (max_concurrent_guests >= 0);
@@ -0,0 +1,38 @@
+CREATE TABLE recurring_bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    table_id UUID NOT NULL REFERENCES tables(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_clock VARCHAR(5) NOT NULL,
+    end_clock VARCHAR(5) NOT NULL,
+    guests_count INTEGER NOT NULL CHECK (guests_count > 0),
+    starts_on DATE NOT NULL,
+    ends_on DATE,
+    occurrence_count INTEGER CHECK (occurrence_count > 0),
+    special_note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- A series ends on a date or after a number of weeks, not both.
+    CONSTRAINT chk_recurring_bookings_end CHECK ((ends_on IS NULL) <> (occurrence_count IS NULL))
+);
+
+CREATE INDEX idx_recurring_bookings_user_id ON recurring_bookings (user_id);
+-- The expansion job walks the active series.
+CREATE INDEX idx_recurring_bookings_active ON recurring_bookings (id) WHERE status = 'active';
+
+CREATE TABLE recurring_booking_occurrences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurring_booking_id UUID NOT NULL REFERENCES recurring_bookings(id) ON DELETE CASCADE,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    booking_id UUID REFERENCES bookings(id) ON DELETE SET NULL,
+    skip_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each occurrence is expanded once, however often the job runs.
+CREATE UNIQUE INDEX idx_recurring_booking_occurrences_start ON recurring_booking_occurrences (recurring_booking_id, start_time);
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS phone_code_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_code_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_code_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN phone_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN phone_code_hash VARCHAR(255);
+ALTER TABLE users ADD COLUMN phone_code_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN phone_code_attempts INTEGER NOT NULL DEFAULT 0;
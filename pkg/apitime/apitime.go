@@ -0,0 +1,73 @@
+package apitime
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Layout is the only timestamp format accepted from API clients. It requires
+// an explicit offset or "Z", so naive values like "2024-06-01 19:00" are rejected.
+const Layout = time.RFC3339
+
+// Example is shown to clients in validation errors.
+const Example = "2024-06-01T19:00:00Z"
+
+// Time wraps time.Time for request and response DTOs. It only unmarshals
+// RFC3339 timestamps with an explicit offset and always marshals as RFC3339 UTC.
+type Time struct {
+	time.Time
+}
+
+func New(t time.Time) Time {
+	return Time{Time: t}
+}
+
+func Parse(value string) (Time, error) {
+	t, err := time.Parse(Layout, value)
+	if err != nil {
+		return Time{}, err
+	}
+	return Time{Time: t}, nil
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(Layout))
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return typeError(string(data))
+	}
+
+	parsed, err := Parse(value)
+	if err != nil {
+		return typeError(value)
+	}
+
+	*t = parsed
+	return nil
+}
+
+// IsTypeError reports whether err is the decoding error produced by Time.
+// encoding/json fills in Field on the returned error so callers can name
+// the offending field in the response.
+func IsTypeError(err *json.UnmarshalTypeError) bool {
+	return err.Type == reflect.TypeOf(Time{})
+}
+
+func typeError(value string) error {
+	return &json.UnmarshalTypeError{
+		Value: "timestamp " + value,
+		Type:  reflect.TypeOf(Time{}),
+	}
+}
@@ -0,0 +1,60 @@
+package apitime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalJSON_AcceptsRFC3339WithOffset(t *testing.T) {
+	var got Time
+	err := json.Unmarshal([]byte(`"2024-06-01T19:00:00+03:00"`), &got)
+
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2024, 6, 1, 16, 0, 0, 0, time.UTC)))
+}
+
+func TestUnmarshalJSON_AcceptsUTC(t *testing.T) {
+	var got Time
+	err := json.Unmarshal([]byte(`"2024-06-01T19:00:00Z"`), &got)
+
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)))
+}
+
+func TestUnmarshalJSON_RejectsInvalidFormats(t *testing.T) {
+	inputs := []string{
+		`"2024-06-01 19:00"`,
+		`"2024-06-01T19:00:00"`,
+		`"2024-06-01"`,
+		`"tomorrow"`,
+		`1717268400`,
+	}
+
+	for _, input := range inputs {
+		var got Time
+		err := json.Unmarshal([]byte(input), &got)
+
+		var typeErr *json.UnmarshalTypeError
+		if assert.ErrorAs(t, err, &typeErr, input) {
+			assert.True(t, IsTypeError(typeErr), input)
+		}
+	}
+}
+
+func TestMarshalJSON_FormatsAsUTC(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	data, err := json.Marshal(New(time.Date(2024, 6, 1, 19, 0, 0, 0, loc)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `"2024-06-01T16:00:00Z"`, string(data))
+}
+
+func TestMarshalJSON_ZeroIsNull(t *testing.T) {
+	data, err := json.Marshal(Time{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+}
@@ -0,0 +1,152 @@
+package googleauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientID = "test-client.apps.googleusercontent.com"
+
+func setupVerifier(t *testing.T) (*verifier, *rsa.PrivateKey, *int32) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "key-1",
+				"kty": "RSA",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return newVerifier(testClientID, server.URL, server.Client()), key, &fetches
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            "https://accounts.google.com",
+		"aud":            testClientID,
+		"sub":            "1234567890",
+		"email":          "user@gmail.com",
+		"email_verified": true,
+		"given_name":     "Test",
+		"family_name":    "User",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+}
+
+func TestVerify_Success(t *testing.T) {
+	v, key, fetches := setupVerifier(t)
+
+	claims, err := v.Verify(context.Background(), signToken(t, key, "key-1", validClaims()))
+
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", claims.Subject)
+	assert.Equal(t, "user@gmail.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "Test", claims.GivenName)
+	assert.Equal(t, "User", claims.FamilyName)
+
+	_, err = v.Verify(context.Background(), signToken(t, key, "key-1", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fetches))
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	v, key, _ := setupVerifier(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"wrong audience", func() string {
+			claims := validClaims()
+			claims["aud"] = "someone-else"
+			return signToken(t, key, "key-1", claims)
+		}},
+		{"wrong issuer", func() string {
+			claims := validClaims()
+			claims["iss"] = "https://evil.example.com"
+			return signToken(t, key, "key-1", claims)
+		}},
+		{"expired", func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return signToken(t, key, "key-1", claims)
+		}},
+		{"missing email", func() string {
+			claims := validClaims()
+			delete(claims, "email")
+			return signToken(t, key, "key-1", claims)
+		}},
+		{"wrong signature", func() string {
+			return signToken(t, otherKey, "key-1", validClaims())
+		}},
+		{"unknown key", func() string {
+			return signToken(t, key, "key-2", validClaims())
+		}},
+		{"hmac token", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+			signed, _ := token.SignedString([]byte("secret"))
+			return signed
+		}},
+		{"garbage", func() string { return "not-a-token" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token())
+			assert.True(t, errors.Is(err, ErrInvalidToken), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestVerify_UnknownKeyDoesNotRefetchEveryRequest(t *testing.T) {
+	v, key, fetches := setupVerifier(t)
+
+	for i := 0; i < 5; i++ {
+		_, err := v.Verify(context.Background(), signToken(t, key, "rotated", validClaims()))
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(fetches))
+}
+
+func TestCacheTTL(t *testing.T) {
+	assert.Equal(t, 19800*time.Second, cacheTTL("public, max-age=19800, must-revalidate, no-transform"))
+	assert.Equal(t, defaultCertsTTL, cacheTTL(""))
+	assert.Equal(t, defaultCertsTTL, cacheTTL("max-age=abc"))
+}
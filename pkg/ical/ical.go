@@ -0,0 +1,125 @@
+// Package ical writes iCalendar (RFC 5545) feeds of events.
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Status is the STATUS of an event. Calendar clients drop or strike out
+// CANCELLED events they already have.
+type Status string
+
+const (
+	StatusTentative Status = "TENTATIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Event is one VEVENT. UID must stay the same for the life of the event so
+// clients update it in place. Times are written in UTC.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Status      Status
+	// Modified is when the event last changed, written as DTSTAMP and
+	// LAST-MODIFIED.
+	Modified time.Time
+}
+
+// Calendar is a VCALENDAR. ProdID names the product that made it.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// maxLineOctets is the longest content line RFC 5545 allows before it has
+// to be folded, not counting the CRLF.
+const maxLineOctets = 75
+
+// Write writes cal to w with CRLF line endings, folding long lines.
+func Write(w io.Writer, cal Calendar) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", cal.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", EscapeText(cal.Name))
+	}
+	for _, event := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", formatTime(event.Modified))
+		line("LAST-MODIFIED", formatTime(event.Modified))
+		line("DTSTART", formatTime(event.Start))
+		line("DTEND", formatTime(event.End))
+		line("SUMMARY", EscapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", EscapeText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", EscapeText(event.Location))
+		}
+		if event.Status != "" {
+			line("STATUS", string(event.Status))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// textEscaper escapes the characters RFC 5545 reserves in TEXT values.
+// Carriage returns are dropped so CRLF and LF both become one \n.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", "",
+)
+
+// EscapeText escapes s for use as a TEXT property value.
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// writeFolded writes content as one content line, continuing it on lines
+// that start with a space whenever it passes maxLineOctets. It never splits
+// a UTF-8 sequence.
+func writeFolded(b *strings.Builder, content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space counts towards the continuation line.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,76 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	almaty := time.FixedZone("Almaty", 5*60*60)
+	start := time.Date(2024, time.June, 4, 19, 0, 0, 0, almaty)
+
+	var b strings.Builder
+	err := Write(&b, Calendar{
+		ProdID: "-//Test//EN",
+		Events: []Event{{
+			UID:         "booking-1",
+			Summary:     "Dinner at Osteria",
+			Description: "2 guests, table T1\nWindow seat; please",
+			Location:    "Abay 10, Almaty",
+			Start:       start,
+			End:         start.Add(2 * time.Hour),
+			Status:      StatusCancelled,
+			Modified:    time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC),
+		}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Test//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:booking-1",
+		"DTSTAMP:20240601T100000Z",
+		"LAST-MODIFIED:20240601T100000Z",
+		"DTSTART:20240604T140000Z",
+		"DTEND:20240604T160000Z",
+		"SUMMARY:Dinner at Osteria",
+		`DESCRIPTION:2 guests\, table T1\nWindow seat\; please`,
+		`LOCATION:Abay 10\, Almaty`,
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), b.String())
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne\nf`, EscapeText("a\\b;c,d\r\ne\nf"))
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	summary := strings.Repeat("Ресторан ", 20)
+
+	var b strings.Builder
+	require.NoError(t, Write(&b, Calendar{ProdID: "-//Test//EN", Events: []Event{{UID: "1", Summary: summary}}}))
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, line)
+		assert.True(t, utf8.ValidString(line), line)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+			continue
+		}
+		unfolded.WriteString("\n" + line)
+	}
+	assert.Contains(t, unfolded.String(), "\nSUMMARY:"+summary+"\n")
+}
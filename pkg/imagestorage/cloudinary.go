@@ -0,0 +1,178 @@
+package imagestorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cloudinaryAPIURL  = "https://api.cloudinary.com/v1_1"
+	cloudinaryTimeout = 30 * time.Second
+)
+
+type cloudinary struct {
+	baseURL    string
+	cloudName  string
+	apiKey     string
+	apiSecret  string
+	folder     string
+	now        func() time.Time
+	httpClient *http.Client
+}
+
+// NewCloudinary uploads to the Cloudinary account with the given
+// credentials, into folder when it is not empty.
+func NewCloudinary(cloudName, apiKey, apiSecret, folder string) ImageStorage {
+	return newCloudinary(cloudinaryAPIURL, cloudName, apiKey, apiSecret, folder, &http.Client{Timeout: cloudinaryTimeout})
+}
+
+func newCloudinary(baseURL, cloudName, apiKey, apiSecret, folder string, httpClient *http.Client) *cloudinary {
+	return &cloudinary{
+		baseURL:    baseURL,
+		cloudName:  cloudName,
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		folder:     folder,
+		now:        time.Now,
+		httpClient: httpClient,
+	}
+}
+
+type cloudinaryResponse struct {
+	SecureURL string `json:"secure_url"`
+	PublicID  string `json:"public_id"`
+	Result    string `json:"result"`
+	Error     *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *cloudinary) Upload(ctx context.Context, file File) (string, string, error) {
+	params := map[string]string{"timestamp": c.timestamp()}
+	if c.folder != "" {
+		params["folder"] = c.folder
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range c.signed(params) {
+		if err := form.WriteField(key, value); err != nil {
+			return "", "", err
+		}
+	}
+	part, err := form.CreateFormFile("file", fileName(file.Name))
+	if err != nil {
+		return "", "", err
+	}
+	written, err := io.Copy(part, file.Content)
+	if err != nil {
+		return "", "", err
+	}
+	if written == 0 {
+		return "", "", ErrEmptyFile
+	}
+	if err := form.Close(); err != nil {
+		return "", "", err
+	}
+
+	resp, err := c.post(ctx, "upload", form.FormDataContentType(), &body)
+	if err != nil {
+		return "", "", err
+	}
+	if resp.SecureURL == "" || resp.PublicID == "" {
+		return "", "", fmt.Errorf("cloudinary upload: response without url or public id")
+	}
+	return resp.SecureURL, resp.PublicID, nil
+}
+
+func (c *cloudinary) Delete(ctx context.Context, publicID string) error {
+	form := url.Values{}
+	for key, value := range c.signed(map[string]string{"public_id": publicID, "timestamp": c.timestamp()}) {
+		form.Set(key, value)
+	}
+
+	resp, err := c.post(ctx, "destroy", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	if resp.Result != "ok" && resp.Result != "not found" {
+		return fmt.Errorf("cloudinary destroy: unexpected result %q", resp.Result)
+	}
+	return nil
+}
+
+func (c *cloudinary) post(ctx context.Context, action, contentType string, body io.Reader) (*cloudinaryResponse, error) {
+	endpoint := fmt.Sprintf("%s/%s/image/%s", c.baseURL, c.cloudName, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary %s: %w", action, err)
+	}
+	defer httpResp.Body.Close()
+
+	var resp cloudinaryResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("cloudinary %s: status %d: %w", action, httpResp.StatusCode, err)
+	}
+	if httpResp.StatusCode != http.StatusOK || resp.Error != nil {
+		message := http.StatusText(httpResp.StatusCode)
+		if resp.Error != nil {
+			message = resp.Error.Message
+		}
+		return nil, fmt.Errorf("cloudinary %s: status %d: %s", action, httpResp.StatusCode, message)
+	}
+	return &resp, nil
+}
+
+// signed adds the API key and the signature Cloudinary expects: the SHA-1
+// of the parameters sorted by name, joined as a query string, followed by
+// the API secret.
+func (c *cloudinary) signed(params map[string]string) map[string]string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + params[key]
+	}
+	sum := sha1.Sum([]byte(strings.Join(pairs, "&") + c.apiSecret))
+
+	out := make(map[string]string, len(params)+2)
+	for key, value := range params {
+		out[key] = value
+	}
+	out["api_key"] = c.apiKey
+	out["signature"] = hex.EncodeToString(sum[:])
+	return out
+}
+
+func (c *cloudinary) timestamp() string {
+	return strconv.FormatInt(c.now().Unix(), 10)
+}
+
+func fileName(name string) string {
+	if name == "" {
+		return "image"
+	}
+	return name
+}
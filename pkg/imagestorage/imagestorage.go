@@ -0,0 +1,27 @@
+// Package imagestorage keeps uploaded images outside the database. Rows only
+// store the URL and the public ID needed to delete the file again.
+package imagestorage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrEmptyFile = errors.New("image file is empty")
+
+// File is an uploaded image.
+type File struct {
+	// Name is the client's file name. Only its extension is kept.
+	Name    string
+	Content io.Reader
+}
+
+type ImageStorage interface {
+	// Upload stores the file and returns where it is served from and the ID
+	// to pass to Delete.
+	Upload(ctx context.Context, file File) (url, publicID string, err error)
+	// Delete removes a stored file. Deleting a file that is already gone is
+	// not an error.
+	Delete(ctx context.Context, publicID string) error
+}
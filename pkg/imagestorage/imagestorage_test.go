@@ -0,0 +1,140 @@
+package imagestorage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCloudinary(t *testing.T, handler http.HandlerFunc) *cloudinary {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := newCloudinary(server.URL, "demo", "key", "secret", "restaurants", server.Client())
+	c.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return c
+}
+
+func expectedSignature(params string) string {
+	sum := sha1.Sum([]byte(params + "secret"))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCloudinaryUpload_SignsRequest(t *testing.T) {
+	c := setupCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/demo/image/upload", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "key", r.FormValue("api_key"))
+		assert.Equal(t, "restaurants", r.FormValue("folder"))
+		assert.Equal(t, expectedSignature("folder=restaurants&timestamp=1700000000"), r.FormValue("signature"))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "photo.jpg", header.Filename)
+		assert.Equal(t, "jpeg bytes", string(content))
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"secure_url": "https://res.cloudinary.com/demo/image/upload/restaurants/abc.jpg",
+			"public_id":  "restaurants/abc",
+		})
+	})
+
+	url, publicID, err := c.Upload(context.Background(), File{Name: "photo.jpg", Content: strings.NewReader("jpeg bytes")})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://res.cloudinary.com/demo/image/upload/restaurants/abc.jpg", url)
+	assert.Equal(t, "restaurants/abc", publicID)
+}
+
+func TestCloudinaryUpload_ReportsAPIError(t *testing.T) {
+	c := setupCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Invalid Signature"}}`))
+	})
+
+	_, _, err := c.Upload(context.Background(), File{Name: "photo.jpg", Content: strings.NewReader("jpeg bytes")})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid Signature")
+}
+
+func TestCloudinaryUpload_RejectsEmptyFile(t *testing.T) {
+	c := setupCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("empty file must not be sent")
+	})
+
+	_, _, err := c.Upload(context.Background(), File{Name: "photo.jpg", Content: strings.NewReader("")})
+
+	assert.ErrorIs(t, err, ErrEmptyFile)
+}
+
+func TestCloudinaryDelete(t *testing.T) {
+	for _, result := range []string{"ok", "not found"} {
+		t.Run(result, func(t *testing.T) {
+			c := setupCloudinary(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/demo/image/destroy", r.URL.Path)
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "restaurants/abc", r.FormValue("public_id"))
+				assert.Equal(t, expectedSignature("public_id=restaurants/abc&timestamp=1700000000"), r.FormValue("signature"))
+				json.NewEncoder(w).Encode(map[string]string{"result": result})
+			})
+
+			assert.NoError(t, c.Delete(context.Background(), "restaurants/abc"))
+		})
+	}
+}
+
+func TestLocalDisk_UploadAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewLocalDisk(dir, "/uploads/")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	url, publicID, err := storage.Upload(ctx, File{Name: "Photo.JPG", Content: strings.NewReader("jpeg bytes")})
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/"+publicID, url)
+	assert.True(t, strings.HasSuffix(publicID, ".jpg"))
+
+	content, err := os.ReadFile(filepath.Join(dir, publicID))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg bytes", string(content))
+
+	require.NoError(t, storage.Delete(ctx, publicID))
+	_, err = os.Stat(filepath.Join(dir, publicID))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, storage.Delete(ctx, publicID), "deleting twice is not an error")
+}
+
+func TestLocalDisk_EmptyFileLeavesNothing(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewLocalDisk(dir, "/uploads")
+	require.NoError(t, err)
+
+	_, _, err = storage.Upload(context.Background(), File{Name: "photo.png", Content: strings.NewReader("")})
+
+	assert.ErrorIs(t, err, ErrEmptyFile)
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestLocalDisk_DeleteRejectsPaths(t *testing.T) {
+	storage, err := NewLocalDisk(t.TempDir(), "/uploads")
+	require.NoError(t, err)
+
+	assert.Error(t, storage.Delete(context.Background(), "../secret"))
+	assert.Error(t, storage.Delete(context.Background(), ""))
+}
@@ -0,0 +1,65 @@
+package imagestorage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localDisk struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalDisk stores images as files in dir, served under baseURL. It is
+// meant for development and tests.
+func NewLocalDisk(dir, baseURL string) (ImageStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &localDisk{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (l *localDisk) Upload(ctx context.Context, file File) (string, string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	publicID := hex.EncodeToString(id) + strings.ToLower(filepath.Ext(file.Name))
+
+	out, err := os.OpenFile(filepath.Join(l.dir, publicID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", "", err
+	}
+	written, err := io.Copy(out, file.Content)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written == 0 {
+		err = ErrEmptyFile
+	}
+	if err != nil {
+		os.Remove(filepath.Join(l.dir, publicID))
+		return "", "", err
+	}
+
+	return l.baseURL + "/" + publicID, publicID, nil
+}
+
+func (l *localDisk) Delete(ctx context.Context, publicID string) error {
+	// Public IDs are bare file names; anything else would reach outside dir.
+	if publicID == "" || filepath.Base(publicID) != publicID {
+		return fmt.Errorf("invalid public id %q", publicID)
+	}
+	err := os.Remove(filepath.Join(l.dir, publicID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
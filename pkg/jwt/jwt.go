@@ -17,9 +17,19 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+const (
+	// PurposeTwoFactorChallenge marks a token that only proves the password
+	// was checked; it must be exchanged for real tokens with a 2FA code.
+	PurposeTwoFactorChallenge = "2fa_challenge"
+
+	twoFactorChallengeExpire = 5 * time.Minute
+)
+
 type Claims struct {
 	UserID uuid.UUID       `json:"user_id"`
 	Role   domain.UserRole `json:"role"`
+	// Purpose is empty for access tokens.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -43,6 +53,8 @@ func (m *Manager) GenerateAccessToken(userID uuid.UUID, role domain.UserRole) (s
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			// The JTI lets a single access token be revoked before it expires.
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessExpire)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -53,6 +65,29 @@ func (m *Manager) GenerateAccessToken(userID uuid.UUID, role domain.UserRole) (s
 	return token.SignedString([]byte(m.secret))
 }
 
+// GenerateTwoFactorChallenge issues a short-lived token that can only be used
+// to finish a login with a 2FA code, never as an access token.
+func (m *Manager) GenerateTwoFactorChallenge(userID uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(twoFactorChallengeExpire)
+	claims := Claims{
+		UserID:  userID,
+		Purpose: PurposeTwoFactorChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
 func (m *Manager) GenerateRefreshToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -62,6 +97,28 @@ func (m *Manager) GenerateRefreshToken() (string, error) {
 }
 
 func (m *Manager) ValidateAccessToken(tokenString string) (*Claims, error) {
+	claims, err := m.validate(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *Manager) ValidateTwoFactorChallenge(tokenString string) (*Claims, error) {
+	claims, err := m.validate(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeTwoFactorChallenge {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *Manager) validate(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -98,6 +155,10 @@ func (m *Manager) ParseToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+func (m *Manager) GetAccessExpire() time.Duration {
+	return m.accessExpire
+}
+
 func (m *Manager) GetRefreshExpire() time.Duration {
 	return m.refreshExpire
 }
@@ -0,0 +1,81 @@
+// Package metrics exposes gauges in the Prometheus text format. Values are
+// read from callbacks when the endpoint is scraped, so nothing has to be
+// pushed from the code that owns them.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type gauge struct {
+	help  string
+	value func() float64
+}
+
+// Registry holds named gauges. Its zero value is not usable; call
+// NewRegistry.
+type Registry struct {
+	mu     sync.RWMutex
+	gauges map[string]gauge
+}
+
+func NewRegistry() *Registry {
+	return &Registry{gauges: make(map[string]gauge)}
+}
+
+// Gauge registers value under name, replacing any gauge of that name.
+func (r *Registry) Gauge(name, help string, value func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = gauge{help: help, value: value}
+}
+
+// WriteTo writes every gauge, sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.gauges))
+	for name := range r.gauges {
+		names = append(names, name)
+	}
+	gauges := make(map[string]gauge, len(r.gauges))
+	for name, g := range r.gauges {
+		gauges[name] = g
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+
+	var written int64
+	for _, name := range names {
+		g := gauges[name]
+		n, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, g.help, name, name, formatValue(g.value()))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
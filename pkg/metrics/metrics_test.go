@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WritesGaugesSortedByName(t *testing.T) {
+	r := NewRegistry()
+	depth := 3.0
+	r.Gauge("queue_depth", "Items waiting.", func() float64 { return depth })
+	r.Gauge("oldest_age_seconds", "Age of the oldest item.", func() float64 { return 1.5 })
+
+	depth = 7
+	var out strings.Builder
+	_, err := r.WriteTo(&out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# HELP oldest_age_seconds Age of the oldest item.\n"+
+		"# TYPE oldest_age_seconds gauge\n"+
+		"oldest_age_seconds 1.5\n"+
+		"# HELP queue_depth Items waiting.\n"+
+		"# TYPE queue_depth gauge\n"+
+		"queue_depth 7\n", out.String())
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("workers", "Running workers.", func() float64 { return 2 })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, w.Body.String(), "\nworkers 2\n")
+}
@@ -0,0 +1,65 @@
+// Package phone normalizes user-entered phone numbers to E.164.
+package phone
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// e164Pattern is a + followed by up to 15 digits, the first not zero. Real
+// numbers are never shorter than 8 digits including the country code.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// separators are characters people type between digit groups.
+var separators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// Normalize converts raw to E.164. Numbers without an international prefix
+// (+ or 00) are read as national numbers of defaultCountryCode, given as
+// digits without the +. A leading trunk 0, or 8 in the +7 zone, is dropped.
+func Normalize(raw, defaultCountryCode string) (string, error) {
+	digits := separators.Replace(strings.TrimSpace(raw))
+
+	var number string
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		number = digits
+	case strings.HasPrefix(digits, "00"):
+		number = "+" + digits[2:]
+	case defaultCountryCode == "":
+		return "", ErrInvalid
+	case defaultCountryCode == "7" && len(digits) == 11 && (digits[0] == '7' || digits[0] == '8'):
+		// 8 700 123 45 67 and 7 700 123 45 67 are both written for +7 700 123 45 67.
+		number = "+7" + digits[1:]
+	case strings.HasPrefix(digits, "0"):
+		number = "+" + defaultCountryCode + digits[1:]
+	default:
+		number = "+" + defaultCountryCode + digits
+	}
+
+	if !e164Pattern.MatchString(number) {
+		return "", ErrInvalid
+	}
+	// Kazakhstan and Russia share +7 with ten digit national numbers.
+	if strings.HasPrefix(number, "+7") && len(number) != 12 {
+		return "", ErrInvalid
+	}
+	return number, nil
+}
+
+// Plausible reports whether raw looks like a phone number without knowing the
+// default country: an optional + and 10 to 15 digits, ignoring separators.
+func Plausible(raw string) bool {
+	digits := strings.TrimPrefix(separators.Replace(strings.TrimSpace(raw)), "+")
+	if len(digits) < 10 || len(digits) > 15 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"+77001234567":        "+77001234567",
+		"+7 700 123 45 67":    "+77001234567",
+		"+7 (700) 123-45-67":  "+77001234567",
+		"8 700 123 45 67":     "+77001234567",
+		"8-700-123-45-67":     "+77001234567",
+		"87001234567":         "+77001234567",
+		"77001234567":         "+77001234567",
+		"7001234567":          "+77001234567",
+		"007 700 123 45 67":   "+77001234567",
+		"  +44 20 7946 0958 ": "+442079460958",
+		"0044 20 7946 0958":   "+442079460958",
+	}
+
+	for raw, want := range cases {
+		got, err := Normalize(raw, "7")
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+}
+
+func TestNormalize_OtherDefaultCountry(t *testing.T) {
+	got, err := Normalize("020 7946 0958", "44")
+	require.NoError(t, err)
+	assert.Equal(t, "+442079460958", got)
+}
+
+func TestNormalize_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"call me",
+		"12345",
+		"+7 700 123 45",
+		"+7 700 123 45 678",
+		"+0 123 456 789",
+		"+1234567890123456",
+		"8 700 123 4567 8",
+		"+7 700 ABC 45 67",
+	} {
+		_, err := Normalize(raw, "7")
+		assert.ErrorIs(t, err, ErrInvalid, raw)
+	}
+
+	_, err := Normalize("7001234567", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestPlausible(t *testing.T) {
+	assert.True(t, Plausible("+7 (700) 123-45-67"))
+	assert.True(t, Plausible("8 700 123 45 67"))
+	assert.False(t, Plausible("12345"))
+	assert.False(t, Plausible("call me maybe"))
+}
@@ -0,0 +1,86 @@
+// Package slug turns names into lowercase ASCII URL path segments.
+package slug
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxLength caps a slug made by Make, leaving room for a collision suffix.
+const MaxLength = 64
+
+// transliterations spells Russian and Kazakh Cyrillic and common accented
+// Latin letters in plain ASCII.
+var transliterations = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "ng", 'ө': "o", 'ұ': "u", 'ү': "u",
+	'һ': "h", 'і': "i",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i",
+	'ï': "i", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// Make transliterates name and joins its words with hyphens, so that
+// "Ла Пьяцца, Алматы" becomes "la-pyatstsa-almaty". Apostrophes are dropped
+// rather than splitting a word. It returns "" when nothing of name can be
+// spelled in ASCII letters and digits.
+func Make(name string) string {
+	var b strings.Builder
+	gap := false
+	for _, r := range strings.ToLower(name) {
+		var part string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			part = string(r)
+		case r == '\'' || r == '’':
+			continue
+		default:
+			var ok bool
+			if part, ok = transliterations[r]; !ok {
+				gap = true
+				continue
+			}
+			if part == "" {
+				continue
+			}
+		}
+		if gap && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		gap = false
+		b.WriteString(part)
+	}
+	return truncate(b.String())
+}
+
+// truncate cuts s to MaxLength, at the last hyphen when there is one, so
+// that words are not split.
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// Unique returns base, or base-2, base-3 and so on, whichever comes first
+// that is not in taken.
+func Unique(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+	return candidate
+}
@@ -0,0 +1,43 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	cases := map[string]string{
+		"La Piazza Almaty":     "la-piazza-almaty",
+		"  La   Piazza -- ":    "la-piazza",
+		"Ла Пьяцца, Алматы":    "la-pyatstsa-almaty",
+		"Щи да каша":           "shchi-da-kasha",
+		"Қазақ Әулеті":         "qazaq-auleti",
+		"Café Ёлка":            "cafe-elka",
+		"Tony's Pizza & Grill": "tonys-pizza-grill",
+		"Бар «Подъезд» №7":     "bar-podezd-7",
+		"24/7 Burger":          "24-7-burger",
+		"!!!":                  "",
+		"寿司":                   "",
+		"":                     "",
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, Make(name), name)
+	}
+}
+
+func TestMake_Truncates(t *testing.T) {
+	slug := Make(strings.Repeat("ресторан ", 20))
+
+	assert.LessOrEqual(t, len(slug), MaxLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "restoran"))
+}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, "navat", Unique("navat", nil))
+	assert.Equal(t, "navat", Unique("navat", []string{"navat-2", "navat-almaty"}))
+	assert.Equal(t, "navat-2", Unique("navat", []string{"navat", "navat-almaty"}))
+	assert.Equal(t, "navat-4", Unique("navat", []string{"navat-3", "navat", "navat-2"}))
+}
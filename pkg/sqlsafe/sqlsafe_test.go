@@ -0,0 +1,35 @@
+package sqlsafe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/clause"
+)
+
+var allowed = map[string]string{"rating": "rating", "price": "average_price"}
+
+func TestResolveSort(t *testing.T) {
+	sort, err := ResolveSort(allowed, "price")
+	require.NoError(t, err)
+	assert.Equal(t, Sort{Column: "average_price"}, sort)
+
+	sort, err = ResolveSort(allowed, "-rating")
+	require.NoError(t, err)
+	assert.Equal(t, Sort{Column: "rating", Desc: true}, sort)
+}
+
+func TestResolveSort_Unknown(t *testing.T) {
+	for _, input := range []string{"", "-", "average_price", "rating; DROP TABLE users", "--rating", "Rating"} {
+		_, err := ResolveSort(allowed, input)
+		assert.ErrorIs(t, err, ErrUnknownSort, input)
+	}
+}
+
+func TestSortClause(t *testing.T) {
+	sort := Sort{Column: "rating", Desc: true}
+
+	assert.Equal(t, clause.OrderByColumn{Column: clause.Column{Name: "rating"}, Desc: true}, sort.Clause())
+	assert.Equal(t, Sort{Column: "id", Desc: true}, sort.Then("id"))
+}
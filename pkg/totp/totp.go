@@ -0,0 +1,105 @@
+// Package totp implements time-based one-time passwords (RFC 6238) with the
+// parameters authenticator apps expect: HMAC-SHA1, 6 digits, 30 second steps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	digits     = 6
+	step       = 30 * time.Second
+	secretSize = 20
+	// skew is how many steps either side of now are accepted, to allow for
+	// clock drift between the server and the user's device.
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// URL builds the otpauth:// URL authenticator apps read from a QR code.
+func URL(issuer, account, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(digits))
+	values.Set("period", fmt.Sprint(int(step.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+// Code returns the code for secret at time t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, uint64(t.Unix()/int64(step.Seconds()))), nil
+}
+
+// Validate reports whether code is valid for secret at time t.
+func Validate(secret, code string, t time.Time) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != digits {
+		return false
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return false
+	}
+
+	counter := t.Unix() / int64(step.Seconds())
+	valid := false
+	for offset := int64(-skew); offset <= skew; offset++ {
+		candidate := generated(key, counter+offset)
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func generated(key []byte, counter int64) string {
+	if counter < 0 {
+		return ""
+	}
+	return code(key, uint64(counter))
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	return encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+}
+
+func code(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1000000)
+}
@@ -0,0 +1,61 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 lists 8 digit codes; the last 6 digits are the 6 digit code.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "time %d", unix)
+	}
+}
+
+func TestValidate_AllowsOneStepOfDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previous, _ := Code(rfcSecret, now.Add(-step))
+	next, _ := Code(rfcSecret, now.Add(step))
+	tooOld, _ := Code(rfcSecret, now.Add(-3*step))
+
+	assert.True(t, Validate(rfcSecret, previous, now))
+	assert.True(t, Validate(rfcSecret, next, now))
+	assert.False(t, Validate(rfcSecret, tooOld, now))
+	assert.False(t, Validate(rfcSecret, "12345", now))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	code, err := Code(secret, time.Now())
+	require.NoError(t, err)
+	assert.True(t, Validate(secret, code, time.Now()))
+}
+
+func TestURL(t *testing.T) {
+	got := URL("Restaurant Booking", "owner@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.Contains(t, got, "otpauth://totp/Restaurant%20Booking:owner@example.com?")
+	assert.Contains(t, got, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, got, "issuer=Restaurant+Booking")
+}
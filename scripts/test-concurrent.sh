@@ -18,12 +18,13 @@ echo -e "\n\n📊 Test 2: Notification Stats..."
 curl -s http://localhost:8080/api/demo/notification-stats | jq .
 
 echo -e "\n\n🪑 Test 3: Parallel Table Availability Check..."
-curl -s -X POST http://localhost:8080/api/demo/check-availability \
+curl -s -X POST http://localhost:8080/api/tables/check-availability \
   -H "Content-Type: application/json" \
   -d '{
-    "table_ids": ["550e8400-e29b-41d4-a716-446655440001", "550e8400-e29b-41d4-a716-446655440002"],
-    "start_time": "2025-12-20T18:00:00Z",
-    "end_time": "2025-12-20T20:00:00Z"
+    "checks": [
+      {"table_id": "550e8400-e29b-41d4-a716-446655440001", "start_time": "2025-12-20T18:00:00Z", "end_time": "2025-12-20T20:00:00Z"},
+      {"table_id": "550e8400-e29b-41d4-a716-446655440002", "start_time": "2025-12-20T19:00:00Z", "end_time": "2025-12-20T21:00:00Z"}
+    ]
   }' | jq .
 
 echo -e "\n\n📈 Test 4: Booking Stats..."